@@ -18,15 +18,14 @@
 package cache
 
 import (
-	"os"
-
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
 	"github.com/b3log/pipe/model"
 	"github.com/bluele/gcache"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 // Article cache.
 var Article = &articleCache{
@@ -56,3 +55,7 @@ func (cache *articleCache) Get(id uint) *model.Article {
 
 	return ret.(*model.Article)
 }
+
+func (cache *articleCache) Purge() {
+	cache.idHolder.Purge()
+}
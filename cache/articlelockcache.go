@@ -0,0 +1,82 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ArticleLock cache.
+var ArticleLock = &articleLockCache{}
+
+// ArticleEditLock represents the soft lock of an article being edited in console.
+type ArticleEditLock struct {
+	ArticleID   uint64
+	UserID      uint64
+	UserName    string
+	AvatarURL   string
+	LockedAt    time.Time
+	RefreshedAt time.Time
+}
+
+// articleLockCache holds locks of articles in the cache backend, so instances sharing a Redis backend see the same
+// locks. Locks expire if they are not refreshed in time.
+type articleLockCache struct {
+}
+
+func (cache *articleLockCache) key(articleID uint64) string {
+	return "article-lock-" + strconv.FormatUint(articleID, 10)
+}
+
+func (cache *articleLockCache) Put(lock *ArticleEditLock, expiration time.Duration) {
+	value, err := json.Marshal(lock)
+	if nil == err {
+		err = backend.Set(cache.key(lock.ArticleID), value, expiration)
+	}
+	if nil != err {
+		logger.Errorf("put lock of article [%d] into cache failed: %s", lock.ArticleID, err)
+	}
+}
+
+func (cache *articleLockCache) Get(articleID uint64) *ArticleEditLock {
+	value, err := backend.Get(cache.key(articleID))
+	if nil != err {
+		logger.Errorf("get lock of article [%d] from cache failed: %s", articleID, err)
+
+		return nil
+	}
+	if nil == value {
+		return nil
+	}
+
+	ret := &ArticleEditLock{}
+	if err = json.Unmarshal(value, ret); nil != err {
+		logger.Errorf("unmarshal lock of article [%d] failed: %s", articleID, err)
+
+		return nil
+	}
+
+	return ret
+}
+
+func (cache *articleLockCache) Remove(articleID uint64) {
+	if err := backend.Delete(cache.key(articleID)); nil != err {
+		logger.Errorf("remove lock of article [%d] from cache failed: %s", articleID, err)
+	}
+}
@@ -0,0 +1,216 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Backend represents the storage of shared caches.
+type Backend interface {
+	// Get returns the value of the specified key, returns nil if not found.
+	Get(key string) ([]byte, error)
+	// Set sets the value of the specified key, the value never expires if the specified expiration is 0.
+	Set(key string, value []byte, expiration time.Duration) error
+	// Incr increments the integer value of the specified key by one and returns the new value.
+	Incr(key string) (int64, error)
+	// Delete removes the specified key.
+	Delete(key string) error
+	// Purge removes all keys.
+	Purge() error
+}
+
+// backend is the current cache backend, defaults to memory.
+var backend Backend = newMemoryBackend(1024 * 4)
+
+// redisPool is the Redis connection pool, nil if Redis is not used.
+var redisPool *redis.Pool
+
+// UseRedis switches the cache backend to the Redis specified by the URL (redis://:password@host:6379/0).
+func UseRedis(url string) error {
+	pool := &redis.Pool{
+		MaxIdle:     16,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(url)
+		},
+		TestOnBorrow: func(conn redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Minute {
+				return nil
+			}
+			_, err := conn.Do("PING")
+
+			return err
+		},
+	}
+	conn := pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PING"); nil != err {
+		pool.Close()
+
+		return err
+	}
+
+	redisPool = pool
+	backend = &redisBackend{pool: pool, prefix: "pipe:"}
+
+	return nil
+}
+
+// RedisPool returns the Redis connection pool, returns nil if Redis is not used.
+func RedisPool() *redis.Pool {
+	return redisPool
+}
+
+type memoryBackend struct {
+	holder gcache.Cache
+	mutex  *sync.Mutex
+}
+
+func newMemoryBackend(size int) *memoryBackend {
+	return &memoryBackend{holder: gcache.New(size).LRU().Build(), mutex: &sync.Mutex{}}
+}
+
+func (b *memoryBackend) Get(key string) ([]byte, error) {
+	ret, err := b.holder.Get(key)
+	if gcache.KeyNotFoundError == err {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return ret.([]byte), nil
+}
+
+func (b *memoryBackend) Set(key string, value []byte, expiration time.Duration) error {
+	if 0 < expiration {
+		return b.holder.SetWithExpire(key, value, expiration)
+	}
+
+	return b.holder.Set(key, value)
+}
+
+func (b *memoryBackend) Incr(key string) (int64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	value, err := b.Get(key)
+	if nil != err {
+		return 0, err
+	}
+	var ret int64
+	if nil != value {
+		ret, _ = strconv.ParseInt(string(value), 10, 64)
+	}
+	ret++
+
+	return ret, b.holder.Set(key, []byte(strconv.FormatInt(ret, 10)))
+}
+
+func (b *memoryBackend) Delete(key string) error {
+	b.holder.Remove(key)
+
+	return nil
+}
+
+func (b *memoryBackend) Purge() error {
+	b.holder.Purge()
+
+	return nil
+}
+
+type redisBackend struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+func (b *redisBackend) Get(key string) ([]byte, error) {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	ret, err := redis.Bytes(conn.Do("GET", b.prefix+key))
+	if redis.ErrNil == err {
+		return nil, nil
+	}
+
+	return ret, err
+}
+
+func (b *redisBackend) Set(key string, value []byte, expiration time.Duration) error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	var err error
+	if 0 < expiration {
+		_, err = conn.Do("SET", b.prefix+key, value, "PX", int64(expiration/time.Millisecond))
+	} else {
+		_, err = conn.Do("SET", b.prefix+key, value)
+	}
+
+	return err
+}
+
+func (b *redisBackend) Incr(key string) (int64, error) {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	return redis.Int64(conn.Do("INCR", b.prefix+key))
+}
+
+func (b *redisBackend) Delete(key string) error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", b.prefix+key)
+
+	return err
+}
+
+func (b *redisBackend) Purge() error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", b.prefix+"*", "COUNT", 1000))
+		if nil != err {
+			return err
+		}
+		if cursor, err = redis.Int(values[0], nil); nil != err {
+			return err
+		}
+		keys, err := redis.Strings(values[1], nil)
+		if nil != err {
+			return err
+		}
+		if 0 < len(keys) {
+			args := redis.Args{}.AddFlat(keys)
+			if _, err = conn.Do("DEL", args...); nil != err {
+				return err
+			}
+		}
+		if 0 == cursor {
+			return nil
+		}
+	}
+}
@@ -49,3 +49,7 @@ func (cache *commentCache) Get(id uint) *model.Comment {
 
 	return ret.(*model.Comment)
 }
+
+func (cache *commentCache) Purge() {
+	cache.idHolder.Purge()
+}
@@ -0,0 +1,114 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Page cache.
+var Page = &pageCache{
+	expiration: 24 * time.Hour,
+}
+
+// CachedPage represents a rendered page.
+type CachedPage struct {
+	ContentType string
+	Body        []byte
+}
+
+// pageCache holds rendered pages keyed by blog and path in the cache backend. Purging a blog bumps its generation so
+// that all pages cached under the previous generation will never be hit again and get evicted eventually. Both pages
+// and generations are kept in the backend, so instances sharing a Redis backend see the same pages.
+type pageCache struct {
+	expiration time.Duration
+}
+
+func (cache *pageCache) generationKey(blogID uint64) string {
+	return fmt.Sprintf("page-gen-%d", blogID)
+}
+
+func (cache *pageCache) key(blogID uint64, path string) (string, error) {
+	value, err := backend.Get(cache.generationKey(blogID))
+	if nil != err {
+		return "", err
+	}
+	generation := "0"
+	if nil != value {
+		generation = string(value)
+	}
+
+	return fmt.Sprintf("page-%d-%s-%s", blogID, generation, path), nil
+}
+
+func (cache *pageCache) Put(blogID uint64, path string, page *CachedPage) {
+	key, err := cache.key(blogID, path)
+	if nil == err {
+		var value []byte
+		if value, err = json.Marshal(page); nil == err {
+			err = backend.Set(key, value, cache.expiration)
+		}
+	}
+	if nil != err {
+		logger.Errorf("put page [blogID=%d, path=%s] into cache failed: %s", blogID, path, err)
+	}
+}
+
+func (cache *pageCache) Get(blogID uint64, path string) *CachedPage {
+	key, err := cache.key(blogID, path)
+	if nil != err {
+		logger.Errorf("get page [blogID=%d, path=%s] from cache failed: %s", blogID, path, err)
+
+		return nil
+	}
+	value, err := backend.Get(key)
+	if nil != err {
+		logger.Errorf("get page [blogID=%d, path=%s] from cache failed: %s", blogID, path, err)
+
+		return nil
+	}
+	if nil == value {
+		return nil
+	}
+
+	ret := &CachedPage{}
+	if err = json.Unmarshal(value, ret); nil != err {
+		logger.Errorf("unmarshal page [blogID=%d, path=%s] failed: %s", blogID, path, err)
+
+		return nil
+	}
+
+	return ret
+}
+
+func (cache *pageCache) Purge(blogID uint64) {
+	generation, err := backend.Incr(cache.generationKey(blogID))
+	if nil != err {
+		logger.Errorf("purge pages of blog [%d] failed: %s", blogID, err)
+
+		return
+	}
+	logger.Debugf("purged pages of blog [%d], generation is [%d] now", blogID, generation)
+}
+
+func (cache *pageCache) PurgeAll() {
+	if err := backend.Purge(); nil != err {
+		logger.Errorf("purge pages failed: %s", err)
+	}
+}
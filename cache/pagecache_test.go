@@ -0,0 +1,62 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPagePurge(t *testing.T) {
+	page := &CachedPage{ContentType: "text/html", Body: []byte("page")}
+	Page.Put(1, "/blogs/pipe", page)
+	Page.Put(2, "/blogs/pipe2", page)
+	if cached := Page.Get(1, "/blogs/pipe"); nil == cached || "page" != string(cached.Body) {
+		t.Errorf("page should be cached")
+	}
+
+	Page.Purge(1)
+	if nil != Page.Get(1, "/blogs/pipe") {
+		t.Errorf("page of the purged blog should not be hit")
+	}
+	if nil == Page.Get(2, "/blogs/pipe2") {
+		t.Errorf("page of other blogs should be kept")
+	}
+
+	Page.Put(1, "/blogs/pipe", page)
+	if nil == Page.Get(1, "/blogs/pipe") {
+		t.Errorf("page should be cached under the new generation")
+	}
+
+	Page.PurgeAll()
+	if nil != Page.Get(2, "/blogs/pipe2") {
+		t.Errorf("all pages should be purged")
+	}
+}
+
+func TestPageExpiration(t *testing.T) {
+	pages := &pageCache{expiration: 50 * time.Millisecond}
+	pages.Put(3, "/blogs/pipe3", &CachedPage{ContentType: "text/html", Body: []byte("page")})
+	if nil == pages.Get(3, "/blogs/pipe3") {
+		t.Errorf("page should be cached")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if nil != pages.Get(3, "/blogs/pipe3") {
+		t.Errorf("expired page should not be hit")
+	}
+}
@@ -51,3 +51,7 @@ func (cache *settingCache) Get(category, name string, blogID uint64) *model.Sett
 
 	return ret.(*model.Setting)
 }
+
+func (cache *settingCache) Purge() {
+	cache.categoryNameHolder.Purge()
+}
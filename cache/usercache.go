@@ -49,3 +49,7 @@ func (cache *userCache) Get(id uint64) *model.User {
 
 	return ret.(*model.User)
 }
+
+func (cache *userCache) Purge() {
+	cache.idHolder.Purge()
+}
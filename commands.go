@@ -0,0 +1,219 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// userCmd handles command "pipe user [create|reset-password|reset-2fa]", which manages users directly against the
+// database so that operators can recover locked-out accounts without the console.
+func userCmd(args []string) {
+	defer service.DisconnectDB()
+
+	usage := "usage: pipe user create --name <name> --email <email> [--password <password>] [--reader]\n" +
+		"       pipe user reset-password --name <name> [--password <password>]\n" +
+		"       pipe user reset-2fa --name <name>"
+	if 1 > len(args) {
+		logger.Fatal(usage)
+	}
+
+	cmd, args := args[0], args[1:]
+	userFlags := flag.NewFlagSet("user "+cmd, flag.ExitOnError)
+	name := userFlags.String("name", "", "username")
+	email := userFlags.String("email", "", "email of the created user")
+	password := userFlags.String("password", "", "password, prompted if not specified")
+	reader := userFlags.Bool("reader", false, "creates a reader account without a blog")
+	userFlags.Parse(args)
+	if "" == *name {
+		logger.Fatal(usage)
+	}
+
+	switch cmd {
+	case "create":
+		if "" == *email {
+			logger.Fatal(usage)
+		}
+
+		register := service.LocalAuth.Register
+		if *reader {
+			register = service.LocalAuth.RegisterReader
+		}
+		user, err := register(*name, *email, readPassword(*password))
+		if nil != err {
+			logger.Fatal("create user failed: " + err.Error())
+		}
+		fmt.Printf("created user [%s], ID [%d]\n", user.Name, user.ID)
+	case "reset-password":
+		user := getUserByName(*name)
+		if err := service.LocalAuth.SetPassword(user.ID, readPassword(*password)); nil != err {
+			logger.Fatal("reset password failed: " + err.Error())
+		}
+		fmt.Printf("reset password of user [%s], the user has been signed out\n", user.Name)
+	case "reset-2fa":
+		user := getUserByName(*name)
+		if err := service.TOTP.ResetTOTP(user.ID); nil != err {
+			logger.Fatal("reset two-factor authentication failed: " + err.Error())
+		}
+		fmt.Printf("disabled two-factor authentication of user [%s]\n", user.Name)
+	default:
+		logger.Fatal(usage)
+	}
+}
+
+// articleCmd handles command "pipe article import --blog <username> [--author <username>] <path>...", which imports
+// markdown files or directories of markdown files into a blog.
+func articleCmd(args []string) {
+	defer service.DisconnectDB()
+
+	usage := "usage: pipe article import --blog <username> [--author <username>] <path>..."
+	if 1 > len(args) || "import" != args[0] {
+		logger.Fatal(usage)
+	}
+
+	importFlags := flag.NewFlagSet("article import", flag.ExitOnError)
+	blog := importFlags.String("blog", "", "username of the blog admin")
+	author := importFlags.String("author", "", "username of the author, defaults to the blog admin")
+	importFlags.Parse(args[1:])
+	if "" == *blog || 1 > importFlags.NArg() {
+		logger.Fatal(usage)
+	}
+
+	userBlog := service.User.GetOwnBlog(getUserByName(*blog).ID)
+	if nil == userBlog {
+		logger.Fatal("not found blog of user [" + *blog + "]")
+	}
+	authorID := userBlog.UserID
+	if "" != *author {
+		authorID = getUserByName(*author).ID
+	}
+
+	var mdFiles []*service.MarkdownFile
+	for _, path := range importFlags.Args() {
+		err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if nil != err {
+				return err
+			}
+			if ext := strings.ToLower(filepath.Ext(path)); info.IsDir() || (".md" != ext && ".markdown" != ext) {
+				return nil
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if nil != err {
+				return err
+			}
+			mdFiles = append(mdFiles, &service.MarkdownFile{
+				Name:    filepath.Base(path),
+				Path:    path,
+				Content: string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))),
+			})
+
+			return nil
+		})
+		if nil != err {
+			logger.Fatal("read markdown files failed: " + err.Error())
+		}
+	}
+
+	service.Import.ImportMarkdowns(mdFiles, authorID, userBlog.ID)
+	fmt.Printf("imported [%d] markdown files into blog [%s]\n", len(mdFiles), userBlog.Title)
+}
+
+// backupCmd handles command "pipe backup [--blog <username>] [--dir <dir>]", which writes backup archives of all
+// blogs or the specified one.
+func backupCmd(args []string) {
+	defer service.DisconnectDB()
+
+	backupFlags := flag.NewFlagSet("backup", flag.ExitOnError)
+	blog := backupFlags.String("blog", "", "username of the blog admin, backs up all blogs if not specified")
+	dir := backupFlags.String("dir", model.Conf.BackupDir, "directory of the backup archives, defaults to Conf.BackupDir")
+	backupFlags.Parse(args)
+	if "" == *dir {
+		*dir = "."
+	}
+
+	blogIDs := service.Backup.GetBlogIDs()
+	if "" != *blog {
+		userBlog := service.User.GetOwnBlog(getUserByName(*blog).ID)
+		if nil == userBlog {
+			logger.Fatal("not found blog of user [" + *blog + "]")
+		}
+		blogIDs = []uint64{userBlog.ID}
+	}
+	if err := os.MkdirAll(*dir, 0755); nil != err {
+		logger.Fatal("make backup dir [" + *dir + "] failed: " + err.Error())
+	}
+
+	for _, blogID := range blogIDs {
+		name, data, err := service.Backup.Archive(blogID)
+		if nil != err {
+			logger.Fatalf("backup blog [%d] failed: %s", blogID, err)
+		}
+
+		path := filepath.Join(*dir, name)
+		if err = ioutil.WriteFile(path, data, 0644); nil != err {
+			logger.Fatal("write backup [" + path + "] failed: " + err.Error())
+		}
+		fmt.Printf("backed up blog [%d] to [%s]\n", blogID, path)
+	}
+}
+
+// getUserByName returns the user specified by the name, exits if not found.
+func getUserByName(name string) *model.User {
+	ret := service.User.GetUserByName(name)
+	if nil == ret {
+		logger.Fatal("not found user [" + name + "]")
+	}
+
+	return ret
+}
+
+// readPassword returns the specified password, prompts for it if it's empty. Input is hidden on terminals.
+func readPassword(password string) string {
+	if "" != password {
+		return password
+	}
+
+	fmt.Print("password: ")
+	if fd := int(os.Stdin.Fd()); terminal.IsTerminal(fd) {
+		input, err := terminal.ReadPassword(fd)
+		fmt.Println()
+		if nil != err {
+			logger.Fatal("read password failed: " + err.Error())
+		}
+
+		return string(input)
+	}
+
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if nil != err && "" == input {
+		logger.Fatal("read password failed: " + err.Error())
+	}
+
+	return strings.TrimRight(input, "\r\n")
+}
@@ -7,8 +7,14 @@ export default function ({redirect, store, route}) {
   }
   const isLogin = store.state.role !== 0
   if (route.path.indexOf('/admin') > -1) {
-    if (!isLogin || store.state.role === 4) {
+    if (!isLogin || store.state.role === 5) {
       redirect('/')
+    } else if (store.state.totpRequired) {
+      redirect('/verify')
+    }
+  } else if (route.path === '/verify') {
+    if (!isLogin || !store.state.totpRequired) {
+      redirect('/admin')
     }
   } else if (route.path === '/start') {
     if (isLogin) {
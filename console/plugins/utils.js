@@ -42,35 +42,110 @@ export const LazyLoadImage = () => {
   }
 }
 
-// 1 - supre admin, 2 - blog admin, 3 - blog user, 4 - prohibit user, 0 - un login user
+// 1 - supre admin, 2 - blog admin, 3 - blog editor, 4 - blog author, 5 - prohibit user, 0 - un login user
+const socketURL = (path) => `${process.env.Server.replace(/^http/, 'ws')}/api${path}`
+
+/**
+ * @description 通过 WebSocket 接收控制台通知，连接断开后 10 秒重连
+ * @param {Function} onNotification 收到通知的回调，参数为通知
+ * @returns {{close: Function}}
+ */
+export const openNotificationSocket = (onNotification) => {
+  let socket = null
+  let closed = false
+  const connect = () => {
+    socket = new WebSocket(socketURL('/console/notifications/socket'))
+    socket.onmessage = (event) => {
+      onNotification(JSON.parse(event.data))
+    }
+    socket.onclose = () => {
+      if (!closed) {
+        setTimeout(connect, 10000)
+      }
+    }
+  }
+  if ('WebSocket' in window) {
+    connect()
+  }
+
+  return {
+    close () {
+      closed = true
+      if (socket) {
+        socket.close()
+      }
+    },
+  }
+}
+
+/**
+ * @description 通过 WebSocket 使用服务端 Markdown 渲染实时预览，仅回调最新一次输入的渲染结果
+ * @param {Function} onRender 渲染完成回调，参数为 HTML
+ * @returns {{render: Function, close: Function}} render 在连接不可用时返回 false
+ */
+export const openMarkdownSocket = (onRender) => {
+  let id = 0
+  let socket = null
+  if ('WebSocket' in window) {
+    socket = new WebSocket(socketURL('/console/markdown/socket'))
+    socket.onmessage = (event) => {
+      const data = JSON.parse(event.data)
+      if (data.id === id) {
+        onRender(data.html)
+      }
+    }
+  }
+
+  return {
+    render (markdownText) {
+      id++
+      if (!socket || socket.readyState !== WebSocket.OPEN) {
+        return false
+      }
+      socket.send(JSON.stringify({id, markdownText}))
+      return true
+    },
+    close () {
+      if (socket) {
+        socket.close()
+      }
+    },
+  }
+}
+
 export const genMenuData = (app, locale) => [
   {
     title: app.$t('home', locale),
     icon: 'home',
     link: '/admin/',
-    role: 3
+    role: 4
   },
   {
     title: app.$t('postArticle', locale),
     icon: 'add',
     link: '/admin/articles/post',
-    role: 3
+    role: 4
   },
   {
     title: app.$t('manage', locale),
     icon: 'manage',
     active: true,
-    role: 3,
+    role: 4,
     items: [
       {
         title: app.$t('articleList', locale),
         link: '/admin/articles',
-        role: 3
+        role: 4
+      },
+      {
+        title: app.$t('trash', locale),
+        link: '/admin/articles/trash',
+        role: 4
       },
       {
         title: app.$t('commentList', locale),
         link: '/admin/comments',
-        role: 3
+        role: 4
       },
       {
         title: app.$t('categoryList', locale),
@@ -82,21 +157,36 @@ export const genMenuData = (app, locale) => [
         link: '/admin/navigations',
         role: 2
       },
+      {
+        title: app.$t('pageList', locale),
+        link: '/admin/pages',
+        role: 3
+      },
       {
         title: app.$t('tagList', locale),
         link: '/admin/tags',
-        role: 2
+        role: 3
+      },
+      {
+        title: app.$t('reportList', locale),
+        link: '/admin/reports',
+        role: 1
+      },
+      {
+        title: app.$t('jobList', locale),
+        link: '/admin/jobs',
+        role: 1
       }
       /*,
       {
         title: app.$t('userList', locale),
         link: '/admin/users',
-        role: 3
+        role: 4
       },
       {
         title: app.$t('blogManage', locale),
         link: '/admin/blogs',
-        role: 3
+        role: 4
       } */
     ]
   },
@@ -104,7 +194,7 @@ export const genMenuData = (app, locale) => [
     title: app.$t('setting', locale),
     icon: 'setting',
     active: app.$route.path.indexOf('settings') > -1,
-    role: 2,
+    role: 4,
     items: [
       {
         title: app.$t('baseInfo', locale),
@@ -117,6 +207,11 @@ export const genMenuData = (app, locale) => [
         link: '/admin/themes',
         role: 2
       },
+      {
+        title: app.$t('appearance', locale),
+        link: '/admin/settings/appearance',
+        role: 2
+      },
       {
         title: app.$t('preference', locale),
         link: '/admin/settings/preference',
@@ -130,7 +225,12 @@ export const genMenuData = (app, locale) => [
       {
         title: app.$t('account', locale),
         link: '/admin/settings/account',
-        role: 2
+        role: 4
+      },
+      {
+        title: app.$t('security', locale),
+        link: '/admin/settings/security',
+        role: 4
       },
       {
         title: app.$t('internationalization', locale),
@@ -151,6 +251,31 @@ export const genMenuData = (app, locale) => [
         title: app.$t('ad', locale),
         link: '/admin/settings/ad',
         role: 2
+      },
+      {
+        title: app.$t('spam', locale),
+        link: '/admin/settings/spam',
+        role: 2
+      },
+      {
+        title: app.$t('newsletter', locale),
+        link: '/admin/settings/newsletter',
+        role: 2
+      },
+      {
+        title: app.$t('upload', locale),
+        link: '/admin/settings/upload',
+        role: 2
+      },
+      {
+        title: app.$t('storage', locale),
+        link: '/admin/settings/storage',
+        role: 2
+      },
+      {
+        title: app.$t('announcement', locale),
+        link: '/admin/settings/announcement',
+        role: 2
       }
     ]
   },
@@ -158,13 +283,13 @@ export const genMenuData = (app, locale) => [
     title: app.$t('others', locale),
     icon: 'inbox',
     link: '/admin/others',
-    role: 3
+    role: 4
   },
   {
     title: app.$t('about', locale),
     icon: 'info',
     link: '/admin/about',
-    role: 3
+    role: 4
   }
 ]
 
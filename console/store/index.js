@@ -4,12 +4,17 @@ export const state = () => ({
   locale: 'zh_CN',
   version: '1.0.0',
   isInit: false,
+  authMode: 'oauth',
+  registration: false,
+  authProviders: [],
   name: '',
   nickname: '',
   blogTitle: '',
   avatarURL: '',
   blogURL: '/',
-  role: 0, // 0-no login, 1-admin, 2-blog admin, 3-blog user, 4-visitor
+  role: 0, // 0-no login, 1-admin, 2-blog admin, 3-blog editor, 4-blog author, 5-visitor
+  totpRequired: false,
+  announcement: '',
   blogs: [{
     title: '',
     id: ''
@@ -36,13 +41,18 @@ export const mutations = {
     state.locale = data.locale
     state.version = data.version
     state.isInit = data.inited
+    state.authMode = data.authMode
+    state.registration = data.registration
+    state.authProviders = data.authProviders || []
     state.role = data.role
+    state.totpRequired = data.totpRequired
     state.name = data.name
     state.nickname = data.nickname
     state.blogTitle = data.blogTitle
     state.blogURL = data.blogURL
     state.blogs = data.blogs
     state.avatarURL = data.avatarURL
+    state.announcement = data.announcement
   },
   setLocale (state, locale) {
     state.locale = locale
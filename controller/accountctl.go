@@ -19,23 +19,20 @@ package controller
 import (
 	"net/http"
 
-	"github.com/b3log/gulu"
-	"github.com/gin-contrib/sessions"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
 	"github.com/gin-gonic/gin"
 )
 
 // logoutAction logout a user.
 func logoutAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
-	session := sessions.Default(c)
-	session.Options(sessions.Options{
-		Path:   "/",
-		MaxAge: -1,
-	})
-	session.Clear()
-	if err := session.Save(); nil != err {
-		logger.Errorf("saves session failed: " + err.Error())
+	if sid := util.GetSession(c).SID; "" != sid {
+		if err := service.UserSession.RemoveSessionBySID(sid); nil != err {
+			util.Log(c).Errorf("revoke session failed: %s", err)
+		}
 	}
+	clearSession(c)
 }
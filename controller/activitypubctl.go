@@ -0,0 +1,257 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboxSize is the max size of activities posted to inboxes.
+const maxInboxSize = 1024 * 1024
+
+// webFingerAction resolves acct:username@host to the ActivityPub actor of the user's blog, sees
+// https://tools.ietf.org/html/rfc7033 for more details.
+func webFingerAction(c *gin.Context) {
+	resource := c.Query("resource")
+	if !strings.HasPrefix(resource, "acct:") {
+		c.Status(http.StatusBadRequest)
+
+		return
+	}
+	username := strings.TrimPrefix(resource, "acct:")
+	if at := strings.Index(username, "@"); 0 < at {
+		username = username[:at]
+	}
+	user := service.User.GetUserByName(username)
+	if nil == user {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+	userBlog := service.User.GetOwnBlog(user.ID)
+	if nil == userBlog {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+
+	c.Header("Access-Control-Allow-Origin", "*")
+	writeJSON(c, "application/jrd+json; charset=utf-8", map[string]interface{}{
+		"subject": resource,
+		"aliases": []string{userBlog.URL},
+		"links": []map[string]interface{}{
+			{"rel": "self", "type": service.ActivityContentType, "href": service.ActivityPub.ActorID(userBlog.ID)},
+			{"rel": "http://webfinger.net/rel/profile-page", "type": "text/html", "href": userBlog.URL},
+		},
+	})
+}
+
+// routeActivityPub routes ActivityPub requests of a blog, for example /blogs/pipe/activitypub/actor.
+func routeActivityPub(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("path"), util.PathActivityPub) {
+	case "/actor":
+		showActorAction(c)
+	case "/outbox":
+		showOutboxAction(c)
+	case "/followers":
+		showFollowersAction(c)
+	case "/inbox":
+		if http.MethodPost != c.Request.Method {
+			c.Status(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		postInboxAction(c)
+	default:
+		notFound(c)
+	}
+}
+
+func showActorAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	user := service.User.GetUserByName(c.Param("username"))
+	publicKey, err := service.ActivityPub.GetPublicKey(blogID)
+	if nil != err {
+		util.Log(c).Errorf("get ActivityPub public key of blog [%d] failed: %s", blogID, err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	actorID := service.ActivityPub.ActorID(blogID)
+	blogTitleSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, blogID)
+	blogSubtitleSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogSubtitle, blogID)
+	writeActivityJSON(c, map[string]interface{}{
+		"@context":          []string{service.ActivityStreamsContext, "https://w3id.org/security/v1"},
+		"id":                actorID,
+		"type":              "Person",
+		"preferredUsername": user.Name,
+		"name":              blogTitleSetting.Value,
+		"summary":           blogSubtitleSetting.Value,
+		"url":               getBlogURL(c),
+		"inbox":             service.ActivityPub.InboxURL(blogID),
+		"outbox":            service.ActivityPub.OutboxURL(blogID),
+		"followers":         service.ActivityPub.FollowersURL(blogID),
+		"icon": map[string]interface{}{
+			"type": "Image",
+			"url":  user.AvatarURLWithSize(210),
+		},
+		"publicKey": map[string]interface{}{
+			"id":           actorID + "#main-key",
+			"owner":        actorID,
+			"publicKeyPem": publicKey,
+		},
+	})
+}
+
+func showOutboxAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	articles, pagination := service.Article.GetArticles("", 1, blogID)
+	items := []interface{}{}
+	for _, article := range articles {
+		article = service.Article.ConsoleGetArticle(article.ID) // the listed article misses fields like updated at
+		if nil == article {
+			continue
+		}
+
+		activity := service.ActivityPub.NewArticleActivity("Create", article)
+		delete(activity, "@context")
+		items = append(items, activity)
+	}
+
+	writeActivityJSON(c, map[string]interface{}{
+		"@context":     service.ActivityStreamsContext,
+		"id":           service.ActivityPub.OutboxURL(blogID),
+		"type":         "OrderedCollection",
+		"totalItems":   pagination.RecordCount,
+		"orderedItems": items,
+	})
+}
+
+func showFollowersAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	writeActivityJSON(c, map[string]interface{}{
+		"@context":   service.ActivityStreamsContext,
+		"id":         service.ActivityPub.FollowersURL(blogID),
+		"type":       "OrderedCollection",
+		"totalItems": service.ActivityPub.GetFollowerCount(blogID),
+	})
+}
+
+func postInboxAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxInboxSize))
+	if nil != err {
+		c.Status(http.StatusBadRequest)
+
+		return
+	}
+	actor, err := service.ActivityPub.VerifyRequest(c.Request, body)
+	if nil != err {
+		util.Log(c).Warnf("verify inbox request failed: %s", err)
+		c.Status(http.StatusUnauthorized)
+
+		return
+	}
+	activity := map[string]interface{}{}
+	if err = json.Unmarshal(body, &activity); nil != err {
+		c.Status(http.StatusBadRequest)
+
+		return
+	}
+	if actorID, _ := activity["actor"].(string); actor.ID != actorID {
+		util.Log(c).Warnf("activity actor [%s] mismatches the signing actor [%s]", actorID, actor.ID)
+		c.Status(http.StatusForbidden)
+
+		return
+	}
+
+	activityType, _ := activity["type"].(string)
+	object, _ := activity["object"].(map[string]interface{})
+	switch activityType {
+	case "Follow":
+		if objectID, _ := activity["object"].(string); service.ActivityPub.ActorID(blogID) != objectID {
+			break
+		}
+
+		follower := &model.Follower{ActorID: actor.ID, Inbox: actor.Inbox, SharedInbox: actor.Endpoints.SharedInbox, BlogID: blogID}
+		if err = service.ActivityPub.AddFollower(follower); nil != err {
+			util.Log(c).Errorf("add follower [%s] failed: %s", actor.ID, err)
+			c.Status(http.StatusInternalServerError)
+
+			return
+		}
+		service.ActivityPub.Accept(blogID, activity, actor.Inbox)
+		util.Log(c).Infof("actor [%s] followed blog [%d]", actor.ID, blogID)
+	case "Undo":
+		if nil == object || "Follow" != object["type"] {
+			break
+		}
+
+		if err = service.ActivityPub.RemoveFollower(actor.ID, blogID); nil != err {
+			util.Log(c).Errorf("remove follower [%s] failed: %s", actor.ID, err)
+			c.Status(http.StatusInternalServerError)
+
+			return
+		}
+		util.Log(c).Infof("actor [%s] unfollowed blog [%d]", actor.ID, blogID)
+	case "Create":
+		if nil == object || "Note" != object["type"] {
+			break
+		}
+
+		if err = service.ActivityPub.AddReplyComment(actor, object, blogID); nil != err {
+			util.Log(c).Warnf("add reply of actor [%s] as comment failed: %s", actor.ID, err)
+		}
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// wantsActivityJSON checks whether the specified request asks for ActivityStreams, for example Mastodon resolving an
+// article URL.
+func wantsActivityJSON(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+
+	return strings.Contains(accept, service.ActivityContentType) || strings.Contains(accept, "application/ld+json")
+}
+
+func writeActivityJSON(c *gin.Context, obj interface{}) {
+	writeJSON(c, service.ActivityContentType+"; charset=utf-8", obj)
+}
+
+func writeJSON(c *gin.Context, contentType string, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if nil != err {
+		util.Log(c).Errorf("marshal JSON failed: %s", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,37 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// recordAPIUsage records API requests per user or per IP for anonymous clients, throttled requests are recorded
+// as well.
+func recordAPIUsage(c *gin.Context) {
+	c.Next()
+
+	client := "ip:" + util.GetRemoteAddr(c)
+	if session := util.GetSession(c); 0 != session.UID {
+		client = "user:" + session.UName
+	}
+	service.APIUsage.Record(client, http.StatusTooManyRequests == c.Writer.Status())
+}
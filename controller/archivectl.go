@@ -34,11 +34,11 @@ func showArchivesAction(c *gin.Context) {
 	blogID := getBlogID(c)
 	locale := getLocale(c)
 	var themeArchives []*model.ThemeArchive
-	archiveModels := service.Archive.GetArchives(blogID)
+	archiveModels := service.Archive.GetGranularArchives(blogID)
 	for _, archiveModel := range archiveModels {
 		archive := &model.ThemeArchive{
-			Title:        i18n.GetMessagef(locale, "archiveYearMonth", archiveModel.Year, archiveModel.Month),
-			URL:          getBlogURL(c) + util.PathArchives + "/" + archiveModel.Year + "/" + archiveModel.Month,
+			Title:        archiveTitle(locale, archiveModel),
+			URL:          getBlogURL(c) + archivePath(archiveModel),
 			ArticleCount: archiveModel.ArticleCount,
 		}
 		themeArchives = append(themeArchives, archive)
@@ -56,16 +56,26 @@ func showArchiveArticlesAction(c *gin.Context) {
 	locale := getLocale(c)
 	session := util.GetSession(c)
 	date := strings.SplitAfter(c.Request.URL.Path, util.PathArchives+"/")[1]
-	year := strings.Split(date, "/")[0]
-	month := strings.Split(date, "/")[1]
-	archiveModel := service.Archive.GetArchive(year, month, blogID)
+	parts := strings.Split(strings.Trim(date, "/"), "/")
+	var archiveModel *model.Archive
+	if 1 == len(parts) {
+		archiveModel = service.Archive.GetYearArchive(parts[0], blogID)
+	} else {
+		archiveModel = service.Archive.GetArchive(parts[0], parts[1], blogID)
+	}
 	if nil == archiveModel {
 		notFound(c)
 
 		return
 	}
 	articleListStyleSetting := service.Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceArticleListStyle, blogID)
-	articleModels, pagination := service.Article.GetArchiveArticles(archiveModel.ID, util.GetPage(c), blogID)
+	var articleModels []*model.Article
+	var pagination *util.Pagination
+	if "" == archiveModel.Month {
+		articleModels, pagination = service.Article.GetYearArchiveArticles(archiveModel.Year, util.GetPage(c), blogID)
+	} else {
+		articleModels, pagination = service.Article.GetArchiveArticles(archiveModel.ID, util.GetPage(c), blogID)
+	}
 	var articles []*model.ThemeArticle
 	for _, articleModel := range articleModels {
 		var themeTags []*model.ThemeTag
@@ -80,7 +90,7 @@ func showArchiveArticlesAction(c *gin.Context) {
 
 		authorModel := service.User.GetUser(articleModel.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
 
 			continue
 		}
@@ -91,7 +101,7 @@ func showArchiveArticlesAction(c *gin.Context) {
 			AvatarURL: authorModel.AvatarURL,
 		}
 
-		mdResult := util.Markdown(articleModel.Content)
+		mdResult := markdown(articleModel.Content, blogID)
 		abstract := template.HTML("")
 		thumbnailURL := mdResult.ThumbURL
 		if strconv.Itoa(model.SettingPreferenceArticleListStyleValueTitleAbstract) == articleListStyleSetting.Value {
@@ -127,12 +137,30 @@ func showArchiveArticlesAction(c *gin.Context) {
 	dataModel["Articles"] = articles
 	dataModel["Pagination"] = pagination
 	dataModel["Archive"] = &model.ThemeArchive{
-		Title:        i18n.GetMessagef(locale, "archiveYearMonth", archiveModel.Year, archiveModel.Month),
-		URL:          getBlogURL(c) + util.PathArchives + "/" + archiveModel.Year + "/" + archiveModel.Month,
+		Title:        archiveTitle(locale, archiveModel),
+		URL:          getBlogURL(c) + archivePath(archiveModel),
 		ArticleCount: archiveModel.ArticleCount,
 	}
-	dataModel["Title"] = i18n.GetMessagef(locale, "archiveYearMonth", archiveModel.Year, archiveModel.Month) +
+	dataModel["Title"] = archiveTitle(locale, archiveModel) +
 		" - " + i18n.GetMessage(locale, "archives") + " - " + dataModel["Title"].(string)
 
 	c.HTML(http.StatusOK, getTheme(c)+"/archive-articles.html", dataModel)
 }
+
+// archivePath returns the path of the specified archive, archives without month are yearly archives.
+func archivePath(archive *model.Archive) string {
+	if "" == archive.Month {
+		return util.PathArchives + "/" + archive.Year
+	}
+
+	return util.PathArchives + "/" + archive.Year + "/" + archive.Month
+}
+
+// archiveTitle returns the title of the specified archive.
+func archiveTitle(locale string, archive *model.Archive) string {
+	if "" == archive.Month {
+		return i18n.GetMessagef(locale, "archiveYear", archive.Year)
+	}
+
+	return i18n.GetMessagef(locale, "archiveYearMonth", archive.Year, archive.Month)
+}
@@ -18,12 +18,12 @@ package controller
 
 import (
 	"bytes"
+	"html"
 	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/cron"
 	"github.com/b3log/pipe/model"
@@ -54,7 +54,7 @@ func showArticlesAction(c *gin.Context) {
 
 		authorModel := service.User.GetUser(articleModel.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
 
 			continue
 		}
@@ -65,7 +65,7 @@ func showArticlesAction(c *gin.Context) {
 			AvatarURL: authorModel.AvatarURL,
 		}
 
-		mdResult := util.Markdown(articleModel.Content)
+		mdResult := markdown(articleModel.Content, blogID)
 		abstract := template.HTML("")
 		thumbnailURL := mdResult.ThumbURL
 		if strconv.Itoa(model.SettingPreferenceArticleListStyleValueTitleAbstract) == articleListStyleSetting.Value {
@@ -111,6 +111,14 @@ func showArticleAction(c *gin.Context) {
 
 	a, _ := c.Get("article")
 	articleModel := a.(*model.Article)
+	if articleNotModified(c, articleModel) {
+		return
+	}
+	if articleLocked(c, articleModel) {
+		showArticlePasswordAction(c, articleModel)
+
+		return
+	}
 
 	var themeTags []*model.ThemeTag
 	tagStrs := strings.Split(articleModel.Tags, ",")
@@ -122,7 +130,7 @@ func showArticleAction(c *gin.Context) {
 		themeTags = append(themeTags, themeTag)
 	}
 
-	mdResult := util.Markdown(articleModel.Content)
+	mdResult := markdown(articleModel.Content, blogID)
 
 	gaSetting := service.Setting.GetSetting(model.SettingCategoryAd, model.SettingNameAdGoogleAdSenseArticleEmbed, blogID)
 	if nil != gaSetting && 0 < len(gaSetting.Value) {
@@ -142,10 +150,26 @@ func showArticleAction(c *gin.Context) {
 		}
 	}
 
+	contentHTML := highlightCode(mdResult.ContentHTML, blogID)
+	contentHTML = service.Glossary.LinkGlossaries(contentHTML, blogID)
+	contentHTML = service.Image.RewritePictures(contentHTML)
+
 	authorModel := service.User.GetUser(articleModel.AuthorID)
 	articleTitle := pangu.SpacingText(articleModel.Title)
 	articleURL := getBlogURL(c) + articleModel.Path
 	articleSignSetting := dataModel["Setting"].(map[string]interface{})[model.SettingNameArticleSign].(string)
+	authorSignName := model.SettingNameAuthorSign + strconv.FormatUint(authorModel.ID, 10)
+	if authorSignSetting := service.Setting.GetSetting(model.SettingCategorySign, authorSignName, blogID); nil != authorSignSetting &&
+		"" != strings.TrimSpace(authorSignSetting.Value) {
+		articleSignSetting = authorSignSetting.Value
+	}
+	layout := ""
+	if category := service.Category.GetArticleCategory(articleModel.Tags, blogID); nil != category {
+		layout = category.Layout
+		if "" != strings.TrimSpace(category.Sign) {
+			articleSignSetting = category.Sign
+		}
+	}
 	articleSignSetting = strings.Replace(articleSignSetting, "{title}", articleTitle, -1)
 	articleSignSetting = strings.Replace(articleSignSetting, "{author}", authorModel.Name, -1)
 	articleSignSetting = strings.Replace(articleSignSetting, "{url}", articleURL, -1)
@@ -153,7 +177,7 @@ func showArticleAction(c *gin.Context) {
 	articleSignSetting = strings.TrimPrefix(articleSignSetting, "<p>")
 	articleSignSetting = strings.TrimSuffix(articleSignSetting, "</p>")
 	articleSignSetting = strings.TrimSpace(articleSignSetting)
-	dataModel["Article"] = &model.ThemeArticle{
+	article := &model.ThemeArticle{
 		Author: &model.ThemeAuthor{
 			Name:      authorModel.Name,
 			URL:       getBlogURL(c) + util.PathAuthors + "/" + authorModel.Name,
@@ -161,6 +185,7 @@ func showArticleAction(c *gin.Context) {
 		},
 		ID:             articleModel.ID,
 		Abstract:       template.HTML(mdResult.AbstractText),
+		Description:    articleModel.Description,
 		CreatedAt:      articleModel.CreatedAt.Format("2006-01-02"),
 		CreatedAtYear:  articleModel.CreatedAt.Format("2006"),
 		CreatedAtMonth: articleModel.CreatedAt.Format("01"),
@@ -172,9 +197,19 @@ func showArticleAction(c *gin.Context) {
 		ViewCount:      articleModel.ViewCount,
 		CommentCount:   articleModel.CommentCount,
 		ThumbnailURL:   mdResult.ThumbURL,
-		Content:        template.HTML(mdResult.ContentHTML + "\n" + articleSignSetting),
+		Content:        template.HTML(contentHTML + "\n" + articleSignSetting),
+		TOC:            mdResult.TOC,
 		Editable:       session.UID == authorModel.ID,
+		Layout:         layout,
 	}
+	if service.ShareImage.Enabled() {
+		article.ShareImageURL = getBlogURL(c) + util.PathShareImages + "/" + strconv.FormatUint(articleModel.ID, 10) + ".png"
+	}
+	if "" == article.Description {
+		article.Description = html.UnescapeString(mdResult.AbstractText)
+	}
+	dataModel["MetaDescription"] = article.Description
+	dataModel["Article"] = article
 
 	page := util.GetPage(c)
 	commentModels, pagination := service.Comment.GetArticleComments(articleModel.ID, page, blogID)
@@ -193,10 +228,9 @@ func showArticleAction(c *gin.Context) {
 			author.AvatarURL = commentAuthor.AvatarURL
 		}
 
-		mdResult := util.Markdown(commentModel.Content)
 		comment := &model.ThemeComment{
 			ID:         commentModel.ID,
-			Content:    template.HTML(mdResult.ContentHTML),
+			Content:    commentContentHTML(commentModel.Content),
 			URL:        getBlogURL(c) + articleModel.Path + "?p=" + strconv.Itoa(page) + "#pipeComment" + strconv.Itoa(int(commentModel.ID)),
 			Author:     author,
 			CreatedAt:  commentModel.CreatedAt.Format("2006-01-02"),
@@ -240,14 +274,79 @@ func showArticleAction(c *gin.Context) {
 		recommendArticleSize = 7
 	}
 	dataModel["RecommendArticles"] = getRecommendArticles(recommendArticleSize)
+	dataModel["RelatedArticles"] = getRelatedArticles(c, articleModel)
 	fillPreviousArticle(c, articleModel, &dataModel)
 	fillNextArticle(c, articleModel, &dataModel)
-	dataModel["ToC"] = template.HTML(toc(dataModel["Article"].(*model.ThemeArticle)))
+	dataModel["ToC"] = template.HTML(toc(article.TOC))
 	dataModel["Title"] = articleTitle + " - " + dataModel["Title"].(string)
 
 	c.HTML(http.StatusOK, getTheme(c)+"/article.html", dataModel)
+}
+
+// articleUnlockCookieAge is the max age in seconds of the cookie which unlocks a password-protected article.
+const articleUnlockCookieAge = 60 * 60 * 24 * 7
+
+// articleUnlockCookieName returns the name of the cookie which unlocks the specified article.
+func articleUnlockCookieName(article *model.Article) string {
+	return "pipe-article-" + strconv.FormatUint(article.ID, 10)
+}
+
+// articleLocked checks whether the specified article is password-protected and not unlocked by the current request.
+// The author always reads the article unlocked.
+func articleLocked(c *gin.Context, article *model.Article) bool {
+	if model.ArticleVisibilityPassword != article.Visibility || util.GetSession(c).UID == article.AuthorID {
+		return false
+	}
+
+	token, _ := c.Cookie(articleUnlockCookieName(article))
+
+	return !service.Article.IsArticleUnlocked(article, token)
+}
+
+// showArticlePasswordAction renders the password form of the specified locked article, a posted correct password
+// unlocks the article and redirects back to it.
+func showArticlePasswordAction(c *gin.Context, articleModel *model.Article) {
+	dataModel := getDataModel(c)
+	articleURL := getBlogURL(c) + articleModel.Path
+	if http.MethodPost == c.Request.Method {
+		if !limitRate(c, authLimiter) {
+			return
+		}
+
+		if service.Article.VerifyArticlePassword(articleModel, c.PostForm("password")) {
+			c.SetCookie(articleUnlockCookieName(articleModel), service.Article.GetArticleUnlockToken(articleModel),
+				articleUnlockCookieAge, "/", "", false, true)
+			c.Redirect(http.StatusSeeOther, articleURL)
+
+			return
+		}
+
+		dataModel["ArticlePasswordWrong"] = true
+	}
+
+	authorModel := service.User.GetUser(articleModel.AuthorID)
+	articleTitle := pangu.SpacingText(articleModel.Title)
+	dataModel["Article"] = &model.ThemeArticle{
+		Author: &model.ThemeAuthor{
+			Name:      authorModel.Name,
+			URL:       getBlogURL(c) + util.PathAuthors + "/" + authorModel.Name,
+			AvatarURL: authorModel.AvatarURL,
+		},
+		ID:             articleModel.ID,
+		CreatedAt:      articleModel.CreatedAt.Format("2006-01-02"),
+		CreatedAtYear:  articleModel.CreatedAt.Format("2006"),
+		CreatedAtMonth: articleModel.CreatedAt.Format("01"),
+		CreatedAtDay:   articleModel.CreatedAt.Format("02"),
+		Title:          articleTitle,
+		URL:            articleURL,
+	}
+	dataModel["ArticleLocked"] = true
+	dataModel["Comments"] = []*model.ThemeComment{}
+	dataModel["RecommendArticles"] = []*model.ThemeArticle{}
+	dataModel["Title"] = articleTitle + " - " + dataModel["Title"].(string)
+	c.Header("Cache-Control", "no-store")
 
-	go service.Article.IncArticleViewCount(articleModel)
+	c.HTML(http.StatusOK, getTheme(c)+"/article.html", dataModel)
 }
 
 func fillPreviousArticle(c *gin.Context, article *model.Article, dataModel *DataModel) {
@@ -288,33 +387,92 @@ func fillNextArticle(c *gin.Context, article *model.Article, dataModel *DataMode
 	(*dataModel)["NextArticle"] = nextArticle
 }
 
-func toc(article *model.ThemeArticle) string {
-	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(string(article.Content)))
-	elements := doc.Find("h1, h2, h3, h4, h5")
-	if nil == elements || 3 > elements.Length() {
+// toc renders the specified table of contents as a flat list which themes style by heading levels, returns an empty
+// string if there are less than 3 headings.
+func toc(items []*util.TOCItem) string {
+	builder := bytes.Buffer{}
+	count := 0
+	var walk func(items []*util.TOCItem)
+	walk = func(items []*util.TOCItem) {
+		for _, item := range items {
+			count++
+			builder.WriteString("<li class='toc__h")
+			builder.WriteString(strconv.Itoa(item.Level))
+			builder.WriteString("'><a href=\"#")
+			builder.WriteString(item.ID)
+			builder.WriteString("\">")
+			builder.WriteString(html.EscapeString(item.Title))
+			builder.WriteString("</a></li>")
+			walk(item.Children)
+		}
+	}
+	walk(items)
+	if 3 > count {
 		return ""
 	}
 
-	builder := bytes.Buffer{}
-	builder.WriteString("<ul id=\"toc\" class=\"toc\">")
-	elements.Each(func(i int, element *goquery.Selection) {
-		tagName := goquery.NodeName(element)
-		id := "toc_" + tagName + "_" + strconv.Itoa(i)
-		element.SetAttr("id", id)
-		builder.WriteString("<li class='toc__")
-		builder.WriteString(tagName)
-		builder.WriteString("'><a href=\"#")
-		builder.WriteString(id)
-		builder.WriteString("\">")
-		builder.WriteString(element.Text())
-		builder.WriteString("</a></li>")
-	})
-	builder.WriteString("</ul>")
-
-	content, _ := doc.Find("body").Html()
-	article.Content = template.HTML(content)
-
-	return builder.String()
+	return "<ul id=\"toc\" class=\"toc\">" + builder.String() + "</ul>"
+}
+
+func showShareImageAction(c *gin.Context) {
+	if !service.ShareImage.Enabled() {
+		notFound(c)
+
+		return
+	}
+
+	idArg := strings.TrimSuffix(strings.TrimPrefix(c.Param("path"), util.PathShareImages+"/"), ".png")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		notFound(c)
+
+		return
+	}
+	article := service.Article.ConsoleGetArticle(id)
+	if nil == article || getBlogID(c) != article.BlogID || model.ArticleStatusOK != article.Status {
+		notFound(c)
+
+		return
+	}
+
+	url, err := service.ShareImage.GetURL(article)
+	if nil != err {
+		util.Log(c).Errorf("render share image of article [%d] failed: %s", id, err)
+		notFound(c)
+
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// getRelatedArticles gets articles related to the specified article, returns an empty list if related articles are
+// turned off by the blog.
+func getRelatedArticles(c *gin.Context, article *model.Article) []*model.ThemeArticle {
+	ret := []*model.ThemeArticle{}
+	size := service.RelatedArticle.GetRelatedArticleListSize(article.BlogID)
+	for _, related := range service.RelatedArticle.GetRelatedArticles(article.ID, size, article.BlogID) {
+		author := service.User.GetUser(related.AuthorID)
+		if nil == author {
+			continue
+		}
+
+		ret = append(ret, &model.ThemeArticle{
+			ID:           related.ID,
+			Title:        pangu.SpacingText(related.Title),
+			URL:          getBlogURL(c) + related.Path,
+			CreatedAt:    related.CreatedAt.Format("2006-01-02"),
+			ViewCount:    related.ViewCount,
+			CommentCount: related.CommentCount,
+			Author: &model.ThemeAuthor{
+				Name:      author.Name,
+				URL:       getBlogURL(c) + util.PathAuthors + "/" + author.Name,
+				AvatarURL: author.AvatarURL,
+			},
+		})
+	}
+
+	return ret
 }
 
 func getRecommendArticles(size int) []*model.ThemeArticle {
@@ -88,7 +88,7 @@ func showAuthorArticlesAction(c *gin.Context) {
 			AvatarURL: authorModel.AvatarURL,
 		}
 
-		mdResult := util.Markdown(articleModel.Content)
+		mdResult := markdown(articleModel.Content, blogID)
 		abstract := template.HTML("")
 		thumbnailURL := mdResult.ThumbURL
 		if strconv.Itoa(model.SettingPreferenceArticleListStyleValueTitleAbstract) == articleListStyleSetting.Value {
@@ -0,0 +1,104 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// authStateSessionKey is the session key of the pending login state, the value is provider name, state and referer
+// separated by spaces.
+const authStateSessionKey = "authState"
+
+// redirectAuthProviderAction redirects to the login page of the specified provider.
+func redirectAuthProviderAction(c *gin.Context) {
+	name := c.Param("provider")
+	state := gulu.Rand.String(16)
+	authCodeURL, err := service.AuthProvider.GetAuthCodeURL(name, state)
+	if nil != err {
+		util.Log(c).Warnf("get auth code URL of login provider [%s] failed: %s", name, err)
+		notFound(c)
+
+		return
+	}
+
+	referer := c.Query("referer")
+	if !strings.HasPrefix(referer, model.Conf.Server+"/") {
+		referer = model.Conf.Server + util.PathAdmin
+	}
+	session := sessions.Default(c)
+	session.Set(authStateSessionKey, name+" "+state+" "+referer)
+	if err := session.Save(); nil != err {
+		util.Log(c).Errorf("saves session failed: %s", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, authCodeURL)
+}
+
+// authProviderCallbackAction handles the authorization response of the specified provider and logins, the identity is
+// linked to the current user if signed in.
+func authProviderCallbackAction(c *gin.Context) {
+	name := c.Param("provider")
+	session := sessions.Default(c)
+	pending, _ := session.Get(authStateSessionKey).(string)
+	session.Delete(authStateSessionKey)
+	parts := strings.SplitN(pending, " ", 3)
+	if 3 != len(parts) || name != parts[0] || "" == c.Query("state") || parts[1] != c.Query("state") {
+		session.Save()
+		c.Status(http.StatusBadRequest)
+
+		return
+	}
+	referer := parts[2]
+
+	if "" != c.Query("error") {
+		util.Log(c).Warnf("login provider [%s] responded error [%s]", name, c.Query("error"))
+		session.Save()
+		c.Redirect(http.StatusSeeOther, model.Conf.Server+util.PathInit)
+
+		return
+	}
+
+	// a signed-in user links the identity to the account explicitly
+	user, err := service.AuthProvider.Login(name, c.Query("code"), util.GetSession(c).UID)
+	if nil != err {
+		util.Log(c).Errorf("login with provider [%s] failed: %s", name, err)
+		session.Save()
+		c.Status(http.StatusUnauthorized)
+
+		return
+	}
+
+	if err := saveLoginSession(c, user); nil != err {
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, referer)
+}
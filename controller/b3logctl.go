@@ -20,7 +20,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -29,7 +28,7 @@ import (
 
 // addSymCommentAction adds a comment come from Sym. Sees https://hacpai.com/article/1457158841475 for more details.
 func addSymCommentAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -40,7 +39,7 @@ func addSymCommentAction(c *gin.Context) {
 		return
 	}
 
-	logger.Infof("Add a comment from Sym: %+v", arg)
+	util.Log(c).Infof("Add a comment from Sym: %+v", arg)
 
 	client := arg["client"].(map[string]interface{})
 	b3Key := client["userB3Key"].(string)
@@ -94,6 +93,8 @@ func addSymCommentAction(c *gin.Context) {
 	if err := service.Comment.AddComment(comment); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+	} else {
+		service.Notification.NotifyComment(comment)
 	}
 
 	if err := service.Comment.UpdatePushedAt(comment); nil != err {
@@ -104,7 +105,7 @@ func addSymCommentAction(c *gin.Context) {
 
 // addSymArticleAction adds an article come from Sym. Sees https://hacpai.com/article/1457158841475 for more details.
 func addSymArticleAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -115,7 +116,7 @@ func addSymArticleAction(c *gin.Context) {
 		return
 	}
 
-	logger.Infof("Add an article from Sym: %+v", arg)
+	util.Log(c).Infof("Add an article from Sym: %+v", arg)
 
 	client := arg["client"].(map[string]interface{})
 	b3Key := client["userB3Key"].(string)
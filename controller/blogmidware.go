@@ -27,6 +27,7 @@ import (
 	"github.com/b3log/pipe/i18n"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/theme"
 	"github.com/b3log/pipe/util"
 	"github.com/dustin/go-humanize"
 	"github.com/gin-gonic/gin"
@@ -50,6 +51,7 @@ func fillUser(c *gin.Context) {
 	(*dataModel)["User"] = session
 
 	c.Next()
+	collectStaticExport(c)
 }
 
 func resolveBlog(c *gin.Context) {
@@ -62,7 +64,7 @@ func resolveBlog(c *gin.Context) {
 	}
 	user := service.User.GetUserByName(username)
 	if nil == user {
-		notFound(c)
+		redirectAlias(c, username)
 		c.Abort()
 
 		return
@@ -77,7 +79,6 @@ func resolveBlog(c *gin.Context) {
 	c.Set("userBlog", userBlog)
 
 	fillCommon(c)
-	go service.Statistic.IncViewCount(userBlog.ID)
 
 	path := strings.Split(c.Request.RequestURI, username)[1]
 	path = strings.TrimSpace(path)
@@ -85,17 +86,86 @@ func resolveBlog(c *gin.Context) {
 		path = path[:end]
 	}
 	article := service.Article.GetArticleByPath(path, userBlog.ID)
+	if nil != article && model.ArticleVisibilityPublic == article.Visibility && wantsActivityJSON(c) {
+		object := service.ActivityPub.NewArticleObject(article)
+		object["@context"] = service.ActivityStreamsContext
+		writeActivityJSON(c, object)
+		c.Abort()
+
+		return
+	}
+	countView(c, userBlog.ID, article)
+	if nil != article && articleNotModified(c, article) {
+		c.Abort()
+
+		return
+	}
+	if servePageCache(c, userBlog.ID) {
+		c.Abort()
+
+		return
+	}
 	if nil == article {
-		c.Next()
+		if page := getStaticPage(path, userBlog.ID); nil != page {
+			c.Set("page", page)
+			capturePage(c, userBlog.ID, func() { showPageAction(c) })
+			c.Abort()
+
+			return
+		}
+
+		capturePage(c, userBlog.ID, c.Next)
 
 		return
 	}
 
 	c.Set("article", article)
-	showArticleAction(c)
+	if model.ArticleVisibilityPassword == article.Visibility { // the page differs between locked and unlocked
+		showArticleAction(c)
+		c.Abort()
+
+		return
+	}
+	capturePage(c, userBlog.ID, func() { showArticleAction(c) })
 	c.Abort()
 }
 
+// redirectAlias redirects requests to the blog of a renamed user permanently to its new blog URL.
+func redirectAlias(c *gin.Context, alias string) {
+	user := service.User.GetUserByAlias(alias)
+	if nil == user {
+		notFound(c)
+
+		return
+	}
+	userBlog := service.User.GetOwnBlog(user.ID)
+	if nil == userBlog {
+		notFound(c)
+
+		return
+	}
+
+	path := strings.TrimPrefix(c.Request.RequestURI, util.PathBlogs+"/"+alias)
+	c.Redirect(http.StatusMovedPermanently, userBlog.URL+path)
+}
+
+// countView counts a view of the current page, views of bots and static exports are excluded.
+func countView(c *gin.Context, blogID uint64, article *model.Article) {
+	if http.MethodGet != c.Request.Method || nil != getStaticExport(c) || util.IsBot(c.Request.UserAgent()) {
+		return
+	}
+
+	visitor := util.GetRemoteAddr(c)
+	if session := util.GetSession(c); 0 < session.UID {
+		visitor = "user:" + strconv.FormatUint(session.UID, 10)
+	}
+	var articleID uint64
+	if nil != article {
+		articleID = article.ID
+	}
+	service.View.CountView(visitor, c.Request.URL.Path, blogID, articleID)
+}
+
 func fillCommon(c *gin.Context) {
 	if "dev" == model.Conf.RuntimeMode {
 		i18n.Load()
@@ -116,6 +186,7 @@ func fillCommon(c *gin.Context) {
 		i18nMap[key] = value
 	}
 	(*dataModel)["I18n"] = i18nMap
+	(*dataModel)["BlogID"] = blogID
 
 	settings := service.Setting.GetAllSettings(blogID)
 	settingMap := map[string]interface{}{}
@@ -133,18 +204,34 @@ func fillCommon(c *gin.Context) {
 		settingMap[strings.Title(setting.Name)] = v
 		settingMap[setting.Name] = v
 	}
+	if themeName, ok := settingMap[model.SettingNameThemeName].(string); ok {
+		themeName = theme.Resolve(themeName) // falls back to the default theme if the theme is incompatible
+		if previewThemeName := getPreviewTheme(c, blogID); "" != previewThemeName {
+			themeName = previewThemeName
+		}
+		settingMap[strings.Title(model.SettingNameThemeName)] = themeName
+		settingMap[model.SettingNameThemeName] = themeName
+	}
 	settingMap[strings.Title(model.SettingNameBasicHeader)] = template.HTML(settingMap[model.SettingNameBasicHeader].(string))
 	settingMap[strings.Title(model.SettingNameBasicFooter)] = template.HTML(settingMap[model.SettingNameBasicFooter].(string))
 	settingMap[strings.Title(model.SettingNameBasicNoticeBoard)] = template.HTML(settingMap[model.SettingNameBasicNoticeBoard].(string))
 	settingMap[strings.Title(model.SettingNameArticleSign)] = template.HTML(settingMap[model.SettingNameArticleSign].(string))
+	headCSS, _ := settingMap[model.SettingNameAppearanceHeadCSS].(string)
+	settingMap[strings.Title(model.SettingNameAppearanceHeadCSS)] = template.CSS(headCSS)
+	headJS, _ := settingMap[model.SettingNameAppearanceHeadJS].(string)
+	settingMap[strings.Title(model.SettingNameAppearanceHeadJS)] = scriptHTML(headJS)
+	footerJS, _ := settingMap[model.SettingNameAppearanceFooterJS].(string)
+	settingMap[strings.Title(model.SettingNameAppearanceFooterJS)] = scriptHTML(footerJS)
 	(*dataModel)["Setting"] = settingMap
+	themeName, _ := settingMap[model.SettingNameThemeName].(string)
+	(*dataModel)["ThemeOptions"] = service.Setting.GetThemeOptions(themeName, blogID)
 
 	statistics := service.Statistic.GetAllStatistics(blogID)
 	statisticMap := map[string]int{}
 	for _, statistic := range statistics {
 		count, err := strconv.Atoi(statistic.Value)
 		if nil != err {
-			logger.Errorf("statistic [%s] should be an integer, actual is [%v]", statistic.Name, statistic.Value)
+			util.Log(c).Errorf("statistic [%s] should be an integer, actual is [%v]", statistic.Name, statistic.Value)
 		}
 		statisticMap[strings.Title(statistic.Name)] = count
 		statisticMap[statistic.Name] = count
@@ -157,11 +244,20 @@ func fillCommon(c *gin.Context) {
 	(*dataModel)["MetaKeywords"] = settingMap[model.SettingNameBasicMetaKeywords]
 	(*dataModel)["MetaDescription"] = settingMap[model.SettingNameBasicMetaDescription]
 	(*dataModel)["Conf"] = model.Conf
+	(*dataModel)["CommentImageUpload"] = service.Media.Enabled()
+	captcha, captchaSiteKey := service.Captcha.GetCaptcha(blogID)
+	if session := util.GetSession(c); 0 != session.UID && model.UserRoleNoLogin != service.User.GetRole(session.UID, blogID) {
+		captcha = model.SettingSpamCaptchaValueNone
+	}
+	(*dataModel)["CommentCaptcha"] = captcha
+	(*dataModel)["CommentCaptchaSiteKey"] = captchaSiteKey
+	(*dataModel)["Newsletter"] = service.Newsletter.Enabled(blogID)
 	(*dataModel)["Year"] = time.Now().Year()
 	users, _ := service.User.GetBlogUsers(1, blogID)
 	(*dataModel)["UserCount"] = len(users)
 	(*dataModel)["BlogAdmin"] = service.User.GetBlogAdmin(blogID)
 	(*dataModel)["Navigations"] = service.Navigation.GetNavigations(blogID)
+	(*dataModel)["Announcement"] = getAnnouncement()
 
 	fillMostUseCategories(&settingMap, dataModel, blogID)
 	fillMostUseTags(&settingMap, dataModel, blogID)
@@ -172,6 +268,85 @@ func fillCommon(c *gin.Context) {
 	c.Set("dataModel", dataModel)
 }
 
+// previewThemeCookieName returns the name of the cookie which keeps the theme previewed in the specified blog.
+func previewThemeCookieName(blogID uint64) string {
+	return "pipe-preview-theme-" + strconv.FormatUint(blogID, 10)
+}
+
+// getPreviewTheme returns the theme previewed by the current user if the user administers the blog, returns ""
+// otherwise. Query "preview-theme" starts previewing the specified theme and keeps it in a session cookie so that
+// the preview goes on while browsing the blog, an empty "preview-theme" stops previewing. Previewing renders the
+// blog with the theme for the previewing user only, pages of signed-in users are never put into the page cache.
+func getPreviewTheme(c *gin.Context, blogID uint64) string {
+	cookieName := previewThemeCookieName(blogID)
+	name, fromQuery := c.GetQuery("preview-theme")
+	if !fromQuery {
+		name, _ = c.Cookie(cookieName)
+	}
+	if "" == name || !canPreviewTheme(c, blogID, name) {
+		if fromQuery {
+			c.SetCookie(cookieName, "", -1, "/", "", false, true)
+		}
+
+		return ""
+	}
+	if fromQuery {
+		c.SetCookie(cookieName, name, 0, "/", "", false, true)
+	}
+
+	return name
+}
+
+// canPreviewTheme checks whether the current user could preview the specified theme in the specified blog.
+func canPreviewTheme(c *gin.Context, blogID uint64, name string) bool {
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		return false
+	}
+	role := service.User.GetRole(session.UID, blogID)
+	if model.UserRoleNoLogin == role || model.UserRoleBlogAdmin < role {
+		return false
+	}
+
+	return theme.IsCompatible(name)
+}
+
+// scriptHTML returns the specified custom snippet as HTML, plain JavaScript code is wrapped in a <script> element.
+func scriptHTML(snippet string) template.HTML {
+	snippet = strings.TrimSpace(snippet)
+	if "" == snippet || strings.HasPrefix(snippet, "<") {
+		return template.HTML(snippet)
+	}
+
+	return template.HTML("<script>\n" + snippet + "\n</script>")
+}
+
+// getAnnouncement returns the rendered platform announcement if it is in its time window.
+func getAnnouncement() template.HTML {
+	content := service.Announcement.GetActiveAnnouncement(time.Now())
+	if "" == content {
+		return ""
+	}
+
+	return template.HTML(util.Markdown(content).ContentHTML)
+}
+
+// markdown renders the specified markdown text of articles or pages with the markdown options of the specified blog.
+func markdown(mdText string, blogID uint64) *util.MarkdownResult {
+	return util.MarkdownWithOptions(mdText, service.Setting.GetMarkdownOptions(blogID))
+}
+
+// highlightCode highlights code blocks in the specified content HTML with the code highlighting style of the specified
+// blog.
+func highlightCode(contentHTML string, blogID uint64) string {
+	style := model.SettingPreferenceCodeHighlightStyleDefault
+	if setting := service.Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceCodeHighlightStyle, blogID); nil != setting {
+		style = setting.Value
+	}
+
+	return util.HighlightCode(contentHTML, style)
+}
+
 func fillMostUseCategories(settingMap *map[string]interface{}, dataModel *DataModel, blogID uint64) {
 	categories := service.Category.GetCategories(math.MaxInt8, blogID)
 	var themeCategories []*model.ThemeCategory
@@ -207,7 +382,7 @@ func fillMostUseTags(settingMap *map[string]interface{}, dataModel *DataModel, b
 func fillMostViewArticles(c *gin.Context, settingMap *map[string]interface{}, dataModel *DataModel, blogID uint64) {
 	mostViewArticleSize, err := strconv.Atoi((*settingMap)[model.SettingNamePreferenceMostViewArticleListSize].(string))
 	if nil != err {
-		logger.Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceMostViewArticleListSize,
+		util.Log(c).Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceMostViewArticleListSize,
 			(*settingMap)[model.SettingNamePreferenceMostViewArticleListSize])
 		mostViewArticleSize = model.SettingPreferenceMostViewArticleListSizeDefault
 	}
@@ -216,7 +391,7 @@ func fillMostViewArticles(c *gin.Context, settingMap *map[string]interface{}, da
 	for _, article := range mostViewArticles {
 		authorModel := service.User.GetUser(article.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", article.ID, article.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", article.ID, article.AuthorID)
 
 			continue
 		}
@@ -240,7 +415,7 @@ func fillMostViewArticles(c *gin.Context, settingMap *map[string]interface{}, da
 func fillRecentComments(c *gin.Context, settingMap *map[string]interface{}, dataModel *DataModel, blogID uint64) {
 	recentCommentSize, err := strconv.Atoi((*settingMap)[model.SettingNamePreferenceRecentCommentListSize].(string))
 	if nil != err {
-		logger.Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceRecentCommentListSize,
+		util.Log(c).Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceRecentCommentListSize,
 			(*settingMap)[model.SettingNamePreferenceRecentCommentListSize])
 		recentCommentSize = model.SettingPreferenceRecentCommentListSizeDefault
 	}
@@ -282,7 +457,7 @@ func fillRecentComments(c *gin.Context, settingMap *map[string]interface{}, data
 func fillMostCommentArticles(c *gin.Context, settingMap *map[string]interface{}, dataModel *DataModel, blogID uint64) {
 	mostCommentArticleSize, err := strconv.Atoi((*settingMap)[model.SettingNamePreferenceMostCommentArticleListSize].(string))
 	if nil != err {
-		logger.Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceMostCommentArticleListSize,
+		util.Log(c).Errorf("setting [%s] should be an integer, actual is [%v]", model.SettingNamePreferenceMostCommentArticleListSize,
 			(*settingMap)[model.SettingNamePreferenceMostCommentArticleListSize])
 		mostCommentArticleSize = model.SettingPreferenceMostCommentArticleListSizeDefault
 	}
@@ -291,7 +466,7 @@ func fillMostCommentArticles(c *gin.Context, settingMap *map[string]interface{},
 	for _, article := range mostCommentArticles {
 		authorModel := service.User.GetUser(article.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", article.ID, article.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", article.ID, article.AuthorID)
 
 			continue
 		}
@@ -345,7 +520,7 @@ func getLocale(c *gin.Context) string {
 func notFound(c *gin.Context) {
 	t, err := template.ParseFiles("console/dist/start/index.html")
 	if nil != err {
-		logger.Errorf("load 404 page failed: " + err.Error())
+		util.Log(c).Errorf("load 404 page failed: %s", err)
 		c.String(http.StatusNotFound, "load 404 page failed")
 
 		return
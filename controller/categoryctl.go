@@ -93,7 +93,7 @@ func showCategoryArticlesArticlesAction(c *gin.Context) {
 
 		authorModel := service.User.GetUser(articleModel.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
 
 			continue
 		}
@@ -104,7 +104,7 @@ func showCategoryArticlesArticlesAction(c *gin.Context) {
 			AvatarURL: authorModel.AvatarURL,
 		}
 
-		mdResult := util.Markdown(articleModel.Content)
+		mdResult := markdown(articleModel.Content, blogID)
 		abstract := template.HTML("")
 		thumbnailURL := mdResult.ThumbURL
 		if strconv.Itoa(model.SettingPreferenceArticleListStyleValueTitleAbstract) == articleListStyleSetting.Value {
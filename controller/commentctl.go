@@ -19,11 +19,14 @@ package controller
 import (
 	"bytes"
 	"html/template"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/i18n"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -31,7 +34,7 @@ import (
 )
 
 func getRepliesAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	blogID := getBlogID(c)
@@ -44,7 +47,7 @@ func getRepliesAction(c *gin.Context) {
 	for _, replyComment := range replyComments {
 		commentAuthor := service.User.GetUser(replyComment.AuthorID)
 		if nil == commentAuthor {
-			logger.Errorf("not found comment author [userID=%d]", replyComment.AuthorID)
+			util.Log(c).Errorf("not found comment author [userID=%d]", replyComment.AuthorID)
 
 			continue
 		}
@@ -59,7 +62,7 @@ func getRepliesAction(c *gin.Context) {
 
 		reply := &model.ThemeReply{
 			ID:        replyComment.ID,
-			Content:   template.HTML(util.Markdown(replyComment.Content).ContentHTML),
+			Content:   commentContentHTML(replyComment.Content),
 			Author:    author,
 			CreatedAt: replyComment.CreatedAt.Format("2006-01-02"),
 		}
@@ -69,8 +72,175 @@ func getRepliesAction(c *gin.Context) {
 	result.Data = replies
 }
 
+// getCommentChallengeAction returns a proof-of-work challenge which should be solved before commenting.
+func getCommentChallengeAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	result.Data = map[string]interface{}{
+		"challenge":  service.Captcha.NewChallenge(),
+		"difficulty": service.ProofOfWorkDifficulty,
+	}
+}
+
+// maxCommentPreviewLength is the max length of comment content to preview.
+const maxCommentPreviewLength = 64 * 1024
+
+// previewCommentAction renders the comment content exactly as the published comment.
+func previewCommentAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	if ok, _ := contentAPILimiter.allow(util.GetRemoteAddr(c)); !ok {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeRateLimited
+		result.Msg = "too many requests"
+
+		return
+	}
+
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses preview comment request failed"
+
+		return
+	}
+	content, _ := arg["content"].(string)
+	if maxCommentPreviewLength < len(content) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "comment content is too long"
+
+		return
+	}
+
+	result.Data = commentContentHTML(content)
+}
+
+// maxCommentImageDimension is the max width or height of comment images.
+const maxCommentImageDimension = 4096
+
+// uploadCommentImageAction uploads an image attached to a comment. The image is checked against the upload
+// constraints of the blog, scanned and re-encoded before stored in the media storage, the response is in format of
+// the Vditor editor upload.
+func uploadCommentImageAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeUnauthenticated
+		result.Msg = "please login before upload"
+
+		return
+	}
+	if !service.Media.Enabled() {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "media storage is not configured"
+
+		return
+	}
+	if ok, _ := contentAPILimiter.allow(util.GetRemoteAddr(c)); !ok {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeRateLimited
+		result.Msg = "too many requests"
+
+		return
+	}
+
+	blogID := getBlogID(c)
+	maxSize := service.Upload.GetMaxSize(blogID)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+64*1024)
+	form, err := c.MultipartForm()
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "image size exceeds " + strconv.FormatInt(maxSize/1024, 10) + "KB"
+
+		return
+	}
+	files := form.File["file[]"]
+	if 1 > len(files) {
+		files = form.File["file"]
+	}
+	if 1 != len(files) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "please attach one image"
+
+		return
+	}
+
+	file := files[0]
+	if maxSize < file.Size {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "image size exceeds " + strconv.FormatInt(maxSize/1024, 10) + "KB"
+
+		return
+	}
+	f, err := file.Open()
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "open upload file failed"
+
+		return
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "read upload file failed"
+
+		return
+	}
+
+	if _, err := service.Upload.Check(data, blogID); nil != err {
+		util.Log(c).Warnf("rejected comment image of user [%s]: %s", session.UName, err)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+
+	data, ext, err := util.ReencodeImage(data, maxCommentImageDimension)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	key := "comments/" + time.Now().Format("200601") + "/" + strconv.FormatUint(util.CurrentMillisecond(), 10) +
+		"-" + gulu.Rand.String(8) + ext
+	url, err := service.Media.Put(key, data)
+	if nil != err {
+		util.Log(c).Errorf("store comment image [%s] failed: %s", key, err)
+		result.Code = util.CodeErr
+		result.Msg = "store image failed"
+
+		return
+	}
+	util.Log(c).Infof("user [%s] uploaded comment image [%s]", session.UName, url)
+
+	result.Data = map[string]interface{}{
+		"errFiles": []string{},
+		"succMap":  map[string]string{file.Filename: url},
+	}
+}
+
+// commentContentHTML renders the specified comment content to sanitized HTML.
+func commentContentHTML(content string) template.HTML {
+	return template.HTML(util.Markdown(content).ContentHTML)
+}
+
 func addCommentAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	blogID := getBlogID(c)
@@ -86,37 +256,98 @@ func addCommentAction(c *gin.Context) {
 		AuthorID: session.UID,
 		BlogID:   blogID,
 	}
-	if err := c.BindJSON(comment); nil != err {
+	arg := &struct {
+		*model.Comment
+		Captcha string `json:"captcha"`
+	}{Comment: comment}
+	if err := c.BindJSON(arg); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = "parses add comment request failed"
 
 		return
 	}
 
-	comment.IP = util.GetRemoteAddr(c)
+	article := service.Article.ConsoleGetArticle(comment.ArticleID)
+	if nil == article {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found the commented article"
 
-	if err := service.Comment.AddComment(comment); nil != err {
+		return
+	}
+	if articleLocked(c, article) {
 		result.Code = util.CodeErr
-		result.Msg = err.Error()
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "the commented article is locked"
+
+		return
 	}
 
-	dataModel := getDataModel(c)
+	comment.IP = util.GetRemoteAddr(c)
+	if service.Report.IsBannedIP(comment.IP) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "your IP is banned"
+
+		return
+	}
+	if model.UserRoleNoLogin == service.User.GetRole(session.UID, blogID) { // blog members are trusted
+		if err := service.Captcha.Verify(arg.Captcha, comment.IP, blogID); nil != err {
+			util.Log(c).Infof("verify captcha of user [%s] failed: %s", session.UName, err)
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeForbidden
+			result.Msg = i18n.GetMessage(getLocale(c), "captchaFailed")
+
+			return
+		}
+	}
+	comment.UserAgent = c.Request.UserAgent()
+	if 255 < len(comment.UserAgent) {
+		comment.UserAgent = comment.UserAgent[:255]
+	}
 
 	commentAuthorURL := util.HacPaiURL + "/member/" + session.UName
 	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
 	if nil != blogURLSetting {
 		commentAuthorURL = blogURLSetting.Value + util.PathAuthors + "/" + session.UName
 	}
+
+	service.Comment.ModerateComment(comment, &service.SpamContext{
+		UserAgent:  c.Request.UserAgent(),
+		Referrer:   c.Request.Referer(),
+		Permalink:  getBlogURL(c) + article.Path,
+		AuthorName: session.UName,
+		AuthorURL:  commentAuthorURL,
+	})
+	if model.CommentStatusSpam == comment.Status {
+		util.Log(c).Infof("comment of user [%s] on article [%d] is flagged as spam", session.UName, comment.ArticleID)
+	}
+
+	if err := service.Comment.AddComment(comment); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	service.Notification.NotifyComment(comment)
+
+	if model.CommentStatusSpam == comment.Status {
+		// the comment is held for moderation, returns a notice instead of the rendered comment
+		result.Msg = i18n.GetMessage(getLocale(c), "commentPendingModeration")
+
+		return
+	}
+
+	dataModel := getDataModel(c)
 	author := &model.ThemeAuthor{
 		Name:      session.UName,
 		URL:       commentAuthorURL,
 		AvatarURL: session.UAvatar,
 	}
 	page := service.Comment.GetCommentPage(comment.ArticleID, comment.ID, comment.BlogID)
-	article := service.Article.ConsoleGetArticle(comment.ArticleID)
 	themeComment := &model.ThemeComment{
 		ID:        comment.ID,
-		Content:   template.HTML(util.Markdown(comment.Content).ContentHTML),
+		Content:   commentContentHTML(comment.Content),
 		URL:       getBlogURL(c) + article.Path + "?p=" + strconv.Itoa(page) + "#pipeComment" + strconv.Itoa(int(comment.ID)),
 		Author:    author,
 		CreatedAt: comment.CreatedAt.Format("2006-01-02"),
@@ -147,7 +378,7 @@ func addCommentAction(c *gin.Context) {
 
 	htmlBuilder := bytes.Buffer{}
 	if err := t.ExecuteTemplate(&htmlBuilder, "comment/comment", dataModel); nil != err {
-		logger.Errorf("execute comment template failed: " + err.Error())
+		util.Log(c).Errorf("execute comment template failed: %s", err)
 
 		return
 	}
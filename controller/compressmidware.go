@@ -0,0 +1,222 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/b3log/pipe/model"
+	"github.com/gin-gonic/gin"
+)
+
+// compressibleTypes are the content types of responses to compress.
+var compressibleTypes = []string{
+	"text/html", "text/css", "text/plain", "text/xml", "text/javascript", "application/javascript",
+	"application/json", "application/feed+json", "application/xml", "application/atom+xml", "application/rss+xml", "image/svg+xml",
+}
+
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+var brotliWriters = sync.Pool{New: func() interface{} { return brotli.NewWriterLevel(nil, 5) }}
+
+// compress returns a middleware which compresses responses with the encodings configured by Conf.Compression.
+func compress() gin.HandlerFunc {
+	var encodings []string
+	for _, encoding := range strings.Split(model.Conf.Compression, ",") {
+		if encoding = strings.TrimSpace(encoding); "br" == encoding || "gzip" == encoding {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), encodings)
+		if "" == encoding || http.MethodHead == c.Request.Method || "" != c.GetHeader("Range") ||
+			strings.EqualFold("websocket", c.GetHeader("Upgrade")) {
+			c.Next()
+
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: model.Conf.CompressionMinSize}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Header("Vary", "Accept-Encoding")
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding returns the first of the specified encodings accepted by the specified Accept-Encoding.
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+				q, _ = strconv.ParseFloat(param[2:], 64)
+			}
+		}
+		accepted[name] = 0 < q
+	}
+	for _, encoding := range encodings {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+
+	return ""
+}
+
+// compressWriter buffers the beginning of a response to decide whether to compress it by its size and content type.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	minSize    int
+	buf        bytes.Buffer
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if nil != w.compressor {
+			return w.compressor.Write(data)
+		}
+
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() < w.minSize {
+		return len(data), nil
+	}
+	w.decide()
+	if err := w.flushBuf(); nil != err {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.ResponseWriter.WriteHeaderNow()
+	if !w.decided {
+		// headers have been sent, it's too late to compress
+		w.decided = true
+		w.flushBuf()
+	}
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+		w.flushBuf()
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) decide() {
+	w.decided = true
+
+	status := w.Status()
+	if http.StatusOK > status || http.StatusNoContent == status || http.StatusPartialContent == status ||
+		http.StatusNotModified == status {
+		return
+	}
+	header := w.Header()
+	if "" != header.Get("Content-Encoding") || !compressible(header.Get("Content-Type")) {
+		return
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if "br" == w.encoding {
+		writer := brotliWriters.Get().(*brotli.Writer)
+		writer.Reset(w.ResponseWriter)
+		w.compressor = writer
+	} else {
+		writer := gzipWriters.Get().(*gzip.Writer)
+		writer.Reset(w.ResponseWriter)
+		w.compressor = writer
+	}
+}
+
+func (w *compressWriter) flushBuf() error {
+	if 0 == w.buf.Len() {
+		return nil
+	}
+
+	var err error
+	if nil != w.compressor {
+		_, err = w.compressor.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+
+	return err
+}
+
+// close writes the buffered small response as is or finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decided = true
+		w.flushBuf()
+
+		return
+	}
+	if nil == w.compressor {
+		return
+	}
+
+	w.compressor.Close()
+	switch writer := w.compressor.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(writer)
+	case *brotli.Writer:
+		brotliWriters.Put(writer)
+	}
+}
+
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, compressibleType := range compressibleTypes {
+		if strings.HasPrefix(contentType, compressibleType) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// notModified emits the specified ETag and Last-Modified validators and responds 304 if the request preconditions
+// If-None-Match or If-Modified-Since are satisfied. Returns true if responded.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if http.MethodGet != c.Request.Method && http.MethodHead != c.Request.Method {
+		return false
+	}
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); "" != ifNoneMatch {
+		// If-None-Match takes precedence over If-Modified-Since
+		if !etagMatch(ifNoneMatch, etag) {
+			return false
+		}
+	} else {
+		ifModifiedSince, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+		if nil != err || lastModified.IsZero() || lastModified.After(ifModifiedSince) {
+			return false
+		}
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+
+	return true
+}
+
+// etagMatch checks whether the specified If-None-Match header value matches the specified ETag with weak comparison.
+func etagMatch(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if "*" == tag || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// articleNotModified handles conditional requests of the specified article, the article page changes if the article
+// is updated or commented, and differs between users and themes.
+func articleNotModified(c *gin.Context, article *model.Article) bool {
+	if model.ArticleVisibilityPassword == article.Visibility { // the page differs between locked and unlocked
+		return false
+	}
+
+	lastModified := article.UpdatedAt
+	if lastComment := service.Comment.GetArticleLastComment(article.ID, article.BlogID); nil != lastComment &&
+		lastComment.CreatedAt.After(lastModified) {
+		lastModified = lastComment.CreatedAt
+	}
+	etag := `W/"` + strconv.FormatUint(article.ID, 10) + "-" + strconv.FormatInt(lastModified.UnixNano(), 36) + "-" +
+		strconv.Itoa(article.CommentCount) + "-" + strconv.FormatUint(util.GetSession(c).UID, 10) + "-" + getTheme(c) + `"`
+
+	return notModified(c, etag, lastModified)
+}
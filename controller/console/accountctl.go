@@ -18,8 +18,9 @@ package console
 
 import (
 	"net/http"
+	"strings"
 
-	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/i18n"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
 	"github.com/gin-gonic/gin"
@@ -27,7 +28,7 @@ import (
 
 // UpdateAccountAction updates an account.
 func UpdateAccountAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -58,7 +59,7 @@ func UpdateAccountAction(c *gin.Context) {
 
 // GetAccountAction gets an account.
 func GetAccountAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -66,6 +67,84 @@ func GetAccountAction(c *gin.Context) {
 	data["name"] = session.UName
 	data["avatarURL"] = session.UAvatar
 	data["b3Key"] = session.UB3Key
+	data["locale"] = util.RequestLocale(c)
+	data["locales"] = i18n.GetLocalesNames()
 
 	result.Data = data
 }
+
+// RenameAccountAction renames the current account, the old blog paths are redirected to the new ones.
+func RenameAccountAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses rename account request failed"
+
+		return
+	}
+
+	name, _ := arg["name"].(string)
+	name = strings.TrimSpace(name)
+	session := util.GetSession(c)
+	if err := service.User.RenameUser(session.UID, name); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		switch err {
+		case service.ErrInvalidUsername:
+			result.ErrCode = util.ErrCodeBadRequest
+		case service.ErrUsernameTaken, service.ErrReservedName:
+			result.ErrCode = util.ErrCodeConflict
+		}
+
+		return
+	}
+	util.Log(c).Infof("user [%s] renamed to [%s]", session.UName, name)
+
+	session.UName = name
+	session.Save(c)
+}
+
+// UpdateAccountLocaleAction updates the console language of the current account.
+func UpdateAccountLocaleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update account locale request failed"
+
+		return
+	}
+
+	locale, _ := arg["locale"].(string)
+	if !util.IsLocale(locale) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "unsupported locale [" + locale + "]"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	user := service.User.GetUser(session.UID)
+	if nil == user {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "user not found"
+
+		return
+	}
+	user.Locale = locale
+	if err := service.User.UpdateUser(user); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	session.ULocale = locale
+	session.Save(c)
+}
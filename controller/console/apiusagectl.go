@@ -0,0 +1,49 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAPIUsagesAction gets API usages of the most active clients, only the platform admin could see them.
+func GetAPIUsagesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if model.UserRolePlatformAdmin != session.URole {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see API usages"
+
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "50"))
+	if nil != err || 1 > size {
+		size = 50
+	}
+
+	result.Data = service.APIUsage.GetTopUsages(size)
+}
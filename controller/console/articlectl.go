@@ -20,13 +20,14 @@ package console
 import (
 	"crypto/tls"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/log"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -36,11 +37,11 @@ import (
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 // PushArticle2RhyAction pushes an article to community.
 func PushArticle2RhyAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -63,7 +64,7 @@ func PushArticle2RhyAction(c *gin.Context) {
 
 // MarkdownAction handles markdown text to HTML.
 func MarkdownAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -75,18 +76,28 @@ func MarkdownAction(c *gin.Context) {
 	}
 
 	mdText := arg["markdownText"].(string)
-	mdResult := util.Markdown(mdText)
+	mdResult := util.MarkdownWithOptions(mdText, service.Setting.GetMarkdownOptions(util.GetSession(c).BID))
 	result.Data = mdResult.ContentHTML
 }
 
 var uploadTokenCheckTime, uploadTokenTime int64
 var uploadToken, uploadURL = "", "https://hacpai.com/upload/client"
 
-// UploadTokenAction gets a upload token.
+// UploadTokenAction gets a upload token. Uploads are stored in the media storage if it is configured, the upload URL
+// is the local upload endpoint without token then.
 func UploadTokenAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
+	if service.Media.Enabled() {
+		result.Data = map[string]interface{}{
+			"uploadToken": "",
+			"uploadURL":   model.Conf.Server + util.PathAPI + "/console/upload",
+		}
+
+		return
+	}
+
 	session := util.GetSession(c)
 	if "" == session.UB3Key {
 		result.Code = util.CodeErr
@@ -123,14 +134,14 @@ func UploadTokenAction(c *gin.Context) {
 	uploadTokenCheckTime = now
 	if nil != errs {
 		result.Code = util.CodeErr
-		logger.Errorf("get upload token failed: %s", errs)
+		util.Log(c).Errorf("get upload token failed: %s", errs)
 
 		return
 	}
 	if util.CodeOk != requestResult.Code {
 		result.Code = util.CodeErr
 		result.Msg = requestResult.Msg
-		logger.Errorf(requestResult.Msg)
+		util.Log(c).Errorf(requestResult.Msg)
 
 		return
 	}
@@ -144,7 +155,7 @@ func UploadTokenAction(c *gin.Context) {
 
 // AddArticleAction adds a new article.
 func AddArticleAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -170,32 +181,73 @@ func AddArticleAction(c *gin.Context) {
 	session := util.GetSession(c)
 
 	article := &model.Article{
-		Title:       arg["title"].(string),
-		Abstract:    arg["abstract"].(string),
-		Content:     arg["content"].(string),
-		Path:        arg["path"].(string),
-		Tags:        arg["tags"].(string),
-		Commentable: arg["commentable"].(bool),
-		Topped:      arg["topped"].(bool),
-		IP:          util.GetRemoteAddr(c),
-		BlogID:      session.BID,
-		AuthorID:    session.UID,
+		Title:    arg["title"].(string),
+		Abstract: arg["abstract"].(string),
+		Content:  arg["content"].(string),
+		Path:     arg["path"].(string),
+		Tags:     arg["tags"].(string),
+		Topped:   arg["topped"].(bool),
+		IP:       util.GetRemoteAddr(c),
+		BlogID:   session.BID,
+		AuthorID: session.UID,
 	}
 	article.CreatedAt = createdAt
+	if toppedOrder, ok := arg["toppedOrder"].(float64); ok {
+		article.ToppedOrder = int(toppedOrder)
+	}
+	if description, ok := arg["description"].(string); ok {
+		article.Description = description
+	}
+
+	if commentable, ok := arg["commentable"].(bool); ok {
+		article.Commentable = commentable
+	} else {
+		article.Commentable = service.Category.GetArticleCommentable(article.Tags, session.BID)
+	}
 
 	if !arg["syncToCommunity"].(bool) {
 		article.PushedAt = article.CreatedAt
 	}
+	if status, ok := arg["status"].(float64); ok {
+		article.Status = int(status)
+	}
+	if model.UserRoleBlogEditor < session.URole { // authors write drafts, which are published by editors
+		article.Status = model.ArticleStatusDraft
+	}
+	if repostOptOut, ok := arg["repostOptOut"].(bool); ok {
+		article.RepostOptOut = repostOptOut
+	}
+	if err := parseArticleVisibility(arg, article); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
 
 	if err := service.Article.AddArticle(article); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	if model.ArticleStatusOK == article.Status {
+		audit(c, model.AuditActionArticlePublish, article.Title)
+	}
+	if model.ArticleStatusOK == article.Status && model.ArticleVisibilityPublic == article.Visibility {
+		service.WebSub.EnqueuePublish(session.BID)
+		if published := service.Article.ConsoleGetArticle(article.ID); nil != published {
+			go service.ActivityPub.PublishArticle("Create", published)
+			go service.LinkSnapshot.QueueArticleLinks(published)
+			go service.RelatedArticle.RelateArticle(published)
+		}
 	}
 }
 
 // GetArticleAction gets an article.
 func GetArticleAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -219,13 +271,29 @@ func GetArticleAction(c *gin.Context) {
 	result.Data = data
 }
 
-// GetArticlesAction gets articles.
+// GetArticlesAction gets articles filtered by the query params key, status (draft, published or scheduled), tag,
+// categoryID, authorID, from and to (yyyy-MM-dd), together with article counts of each facet.
 func GetArticlesAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
-	articleModels, pagination := service.Article.ConsoleGetArticles(c.Query("key"), util.GetPage(c), session.BID)
+	filter := &service.ArticleFilter{
+		Keyword: strings.TrimSpace(c.Query("key")),
+		Status:  c.Query("status"),
+		Tag:     strings.TrimSpace(c.Query("tag")),
+	}
+	filter.CategoryID, _ = strconv.ParseUint(c.Query("categoryID"), 10, 64)
+	filter.AuthorID, _ = strconv.ParseUint(c.Query("authorID"), 10, 64)
+	if from := c.Query("from"); "" != from {
+		filter.From, _ = time.ParseInLocation("2006-01-02", from, time.Local)
+	}
+	if to := c.Query("to"); "" != to {
+		if t, err := time.ParseInLocation("2006-01-02", to, time.Local); nil == err {
+			filter.To = t.AddDate(0, 0, 1) // inclusive
+		}
+	}
+	articleModels, pagination := service.Article.ConsoleFilterArticles(filter, util.GetPage(c), session.BID)
 	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
 
 	var articles []*ConsoleArticle
@@ -254,10 +322,16 @@ func GetArticlesAction(c *gin.Context) {
 			Title:        articleModel.Title,
 			Tags:         consoleTags,
 			URL:          blogURLSetting.Value + articleModel.Path,
+			Status:       articleModel.Status,
 			Topped:       articleModel.Topped,
+			ToppedOrder:  articleModel.ToppedOrder,
+			Visibility:   articleModel.Visibility,
 			ViewCount:    articleModel.ViewCount,
 			CommentCount: articleModel.CommentCount,
 		}
+		if lock := service.ArticleLock.GetLock(articleModel.ID); nil != lock {
+			article.Lock = newConsoleLock(lock, session.UID)
+		}
 
 		articles = append(articles, article)
 	}
@@ -265,12 +339,53 @@ func GetArticlesAction(c *gin.Context) {
 	data := map[string]interface{}{}
 	data["articles"] = articles
 	data["pagination"] = pagination
+	data["facets"] = service.Article.ConsoleGetArticleFacets(filter, session.BID)
 	result.Data = data
 }
 
-// RemoveArticleAction removes an article.
+// TopArticleAction tops or untops an article with the order among topped articles.
+func TopArticleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	arg := &struct {
+		Topped      bool `json:"topped"`
+		ToppedOrder int  `json:"toppedOrder"`
+	}{}
+	if err := c.BindJSON(arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses top article request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	article := service.Article.ConsoleGetArticle(id)
+	if nil == article || session.BID != article.BlogID {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found article"
+
+		return
+	}
+	if err := service.Article.TopArticle(id, arg.Topped, arg.ToppedOrder, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// RemoveArticleAction moves an article into the trash.
 func RemoveArticleAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -284,16 +399,33 @@ func RemoveArticleAction(c *gin.Context) {
 
 	session := util.GetSession(c)
 	blogID := session.BID
+	article := service.Article.ConsoleGetArticle(id)
+	if nil != article && !canEditArticle(session, article) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "authors can only remove their own drafts"
+
+		return
+	}
 
-	if err := service.Article.RemoveArticle(id, blogID); nil != err {
+	if err := service.Article.TrashArticle(id, blogID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	if nil != article {
+		audit(c, model.AuditActionArticleRemove, article.Title)
+		if isFederated(article) {
+			go service.ActivityPub.DeleteArticle(article)
+		}
 	}
 }
 
-// RemoveArticlesAction removes articles.
+// RemoveArticlesAction moves articles into the trash.
 func RemoveArticlesAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -308,16 +440,112 @@ func RemoveArticlesAction(c *gin.Context) {
 	blogID := session.BID
 
 	ids := arg["ids"].([]interface{})
-	for _, id := range ids {
-		if err := service.Article.RemoveArticle(uint64(id.(float64)), blogID); nil != err {
-			logger.Errorf("remove article failed: " + err.Error())
+	for _, idArg := range ids {
+		id := uint64(idArg.(float64))
+		article := service.Article.ConsoleGetArticle(id)
+		if nil != article && !canEditArticle(session, article) {
+			util.Log(c).Warnf("user [%s] is not allowed to remove article [%d]", session.UName, id)
+
+			continue
+		}
+		if err := service.Article.TrashArticle(id, blogID); nil != err {
+			util.Log(c).Errorf("remove article failed: %s", err)
+
+			continue
+		}
+		if nil != article {
+			audit(c, model.AuditActionArticleRemove, article.Title)
+			if isFederated(article) {
+				go service.ActivityPub.DeleteArticle(article)
+			}
 		}
 	}
 }
 
+// maxBatchUpdateArticles is the max count of articles updated by one batch update request.
+const maxBatchUpdateArticles = 500
+
+// BatchUpdateArticlesAction applies a change set (add/remove tags, set category, publish/unpublish) to articles.
+func BatchUpdateArticlesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg := &struct {
+		IDs []uint64 `json:"ids"`
+		service.ArticleChangeSet
+	}{}
+	if err := c.BindJSON(arg); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses batch update articles request failed"
+
+		return
+	}
+	if 1 > len(arg.IDs) || maxBatchUpdateArticles < len(arg.IDs) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "count of articles should be in [1, " + strconv.Itoa(maxBatchUpdateArticles) + "]"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	published := map[uint64]bool{}
+	for _, id := range arg.IDs {
+		if article := service.Article.ConsoleGetArticle(id); nil != article && model.ArticleStatusOK == article.Status {
+			published[id] = true
+		}
+	}
+
+	articles, err := service.Article.BatchUpdateArticles(arg.IDs, &arg.ArticleChangeSet, session.BID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	publishing := false
+	for _, article := range articles {
+		if model.ArticleStatusOK != article.Status {
+			if published[article.ID] && model.ArticleVisibilityPublic == article.Visibility {
+				go service.ActivityPub.DeleteArticle(article)
+			}
+
+			continue
+		}
+
+		activityType := "Update"
+		if !published[article.ID] {
+			activityType = "Create"
+			audit(c, model.AuditActionArticlePublish, article.Title)
+		}
+		if model.ArticleVisibilityPublic != article.Visibility {
+			continue
+		}
+
+		publishing = true
+		go service.ActivityPub.PublishArticle(activityType, article)
+		go service.LinkSnapshot.QueueArticleLinks(article)
+		go service.RelatedArticle.RelateArticle(article)
+	}
+	if publishing {
+		service.WebSub.EnqueuePublish(session.BID)
+	}
+
+	result.Data = map[string]interface{}{
+		"count": len(articles),
+	}
+}
+
+// isFederated checks whether the specified article is delivered to followers over ActivityPub, that is published and
+// public.
+func isFederated(article *model.Article) bool {
+	return model.ArticleStatusOK == article.Status && model.ArticleVisibilityPublic == article.Visibility
+}
+
 // UpdateArticleAction updates an article.
 func UpdateArticleAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -360,35 +588,279 @@ func UpdateArticleAction(c *gin.Context) {
 		BlogID:      session.BID,
 		AuthorID:    session.UID,
 	}
+	if toppedOrder, ok := arg["toppedOrder"].(float64); ok {
+		article.ToppedOrder = int(toppedOrder)
+	}
+
+	article.Status = service.ArticleStatusUnchanged
+	if status, ok := arg["status"].(float64); ok {
+		article.Status = int(status)
+	}
 
 	oldArticle := service.Article.ConsoleGetArticle(id)
-	if nil == oldArticle {
+	if nil == oldArticle || session.BID != oldArticle.BlogID {
 		result.Code = util.CodeErr
-		result.Msg = err.Error()
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found article"
+
+		return
+	}
+	if !canEditArticle(session, oldArticle) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "authors can only edit their own drafts"
 
 		return
 	}
+	if model.UserRoleBlogEditor < session.URole {
+		article.Status = model.ArticleStatusDraft
+	}
 
 	if !arg["syncToCommunity"].(bool) {
 		article.PushedAt = oldArticle.PushedAt
 	}
+	if repostOptOut, ok := arg["repostOptOut"].(bool); ok {
+		article.RepostOptOut = repostOptOut
+	} else {
+		article.RepostOptOut = oldArticle.RepostOptOut
+	}
+	if description, ok := arg["description"].(string); ok {
+		article.Description = description
+	} else {
+		article.Description = oldArticle.Description
+	}
+	version, ok := arg["version"].(float64)
+	if !ok { // updates without the version would overwrite changes of others silently
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "version of the updated article is required"
+
+		return
+	}
+	article.Version = int(version)
+	article.Visibility = oldArticle.Visibility
+	if err := parseArticleVisibility(arg, article); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
 
 	if err := service.Article.UpdateArticle(article); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+		if service.ErrArticleConflict == err {
+			// returns the competing version for merge
+			result.ErrCode = util.ErrCodeConflict
+			result.Data = service.Article.ConsoleGetArticle(id)
+		}
+
+		return
+	}
+
+	if model.ArticleStatusOK == article.Status && model.ArticleStatusOK != oldArticle.Status {
+		audit(c, model.AuditActionArticlePublish, article.Title)
+	}
+	if isFederated(article) {
+		service.WebSub.EnqueuePublish(session.BID)
+		activityType := "Update"
+		if !isFederated(oldArticle) {
+			activityType = "Create"
+		}
+		if published := service.Article.ConsoleGetArticle(id); nil != published {
+			go service.ActivityPub.PublishArticle(activityType, published)
+			go service.LinkSnapshot.QueueArticleLinks(published)
+			go service.RelatedArticle.RelateArticle(published)
+		}
+	} else if isFederated(oldArticle) { // unpublished, unlisted or password protected
+		go service.ActivityPub.DeleteArticle(oldArticle)
+	}
+
+	result.Data = map[string]interface{}{
+		"version": article.Version,
+	}
+}
+
+// GetArticleRevisionsAction gets revisions of an article, the latest first.
+func GetArticleRevisionsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	article := getConsoleArticle(c, result)
+	if nil == article {
+		return
+	}
+
+	var revisions []*ConsoleRevision
+	for _, revision := range service.Revision.GetRevisions(article.ID) {
+		consoleRevision := &ConsoleRevision{
+			Version:   revision.Version,
+			Title:     revision.Title,
+			CreatedAt: revision.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if editor := service.User.GetUser(revision.EditorID); nil != editor {
+			consoleRevision.Editor = editor.Name
+		}
+		revisions = append(revisions, consoleRevision)
+	}
+
+	result.Data = revisions
+}
+
+// GetArticleDiffAction gets the word-level diff of title and content between two revisions of an article. The
+// revisions are specified by versions in query arguments from and to, to defaults to the current version and from
+// defaults to the previous version of to.
+func GetArticleDiffAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	article := getConsoleArticle(c, result)
+	if nil == article {
+		return
+	}
+
+	to := article.Version
+	if toArg := c.Query("to"); "" != toArg {
+		version, err := strconv.Atoi(toArg)
+		if nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid version [" + toArg + "]"
+
+			return
+		}
+		to = version
+	}
+	from := to - 1
+	if fromArg := c.Query("from"); "" != fromArg {
+		version, err := strconv.Atoi(fromArg)
+		if nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid version [" + fromArg + "]"
+
+			return
+		}
+		from = version
+	}
+
+	fromRevision := getRevision(article, from)
+	toRevision := getRevision(article, to)
+	if nil == fromRevision || nil == toRevision {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found revision"
+
+		return
+	}
+
+	result.Data = map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"title":   util.DiffWords(fromRevision.Title, toRevision.Title),
+		"content": util.DiffWords(fromRevision.Content, toRevision.Content),
+	}
+}
+
+// getConsoleArticle gets the article specified by the path parameter id in the current blog, sets the error into the
+// specified result and returns nil if not found.
+// LockArticleAction acquires or refreshes the soft lock of an article for the current user, the editor polls it while
+// the article is open. The lock held by another user is returned if the article is being edited by someone else.
+func LockArticleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	article := getConsoleArticle(c, result)
+	if nil == article {
+		return
+	}
+
+	session := util.GetSession(c)
+	user := service.User.GetUser(session.UID)
+	if nil == user {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "user not found"
+
+		return
+	}
+
+	lock, _ := service.ArticleLock.Lock(article.ID, user)
+	result.Data = newConsoleLock(lock, session.UID)
+}
+
+// UnlockArticleAction releases the soft lock of an article held by the current user.
+func UnlockArticleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	article := getConsoleArticle(c, result)
+	if nil == article {
+		return
+	}
+
+	session := util.GetSession(c)
+	service.ArticleLock.Unlock(article.ID, session.UID)
+}
+
+func newConsoleLock(lock *cache.ArticleEditLock, uid uint64) *ConsoleLock {
+	return &ConsoleLock{
+		Acquired:  uid == lock.UserID,
+		UserName:  lock.UserName,
+		AvatarURL: lock.AvatarURL,
+		LockedAt:  lock.LockedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func getConsoleArticle(c *gin.Context, result *util.Result) *model.Article {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return nil
+	}
+
+	session := util.GetSession(c)
+	article := service.Article.ConsoleGetArticle(id)
+	if nil == article || session.BID != article.BlogID {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found article"
+
+		return nil
+	}
+
+	return article
+}
+
+// getRevision gets the revision of the specified article at the specified version. Articles saved before revisions
+// were recorded have no revision of the current version, which is built from the article itself.
+func getRevision(article *model.Article, version int) *model.Revision {
+	if ret := service.Revision.GetRevision(article.ID, version); nil != ret {
+		return ret
+	}
+	if version != article.Version {
+		return nil
+	}
+
+	return &model.Revision{
+		ArticleID: article.ID,
+		Version:   article.Version,
+		Title:     article.Title,
+		Content:   article.Content,
+		EditorID:  article.AuthorID,
+		BlogID:    article.BlogID,
 	}
 }
 
 // GetArticleThumbsAction gets article thumbnails.
 func GetArticleThumbsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	n, _ := strconv.Atoi(c.Query("n"))
-	urls := util.RandImages(n)
-
-	// original: 1920*1080
-
 	w, _ := strconv.Atoi(c.Query("w"))
 	if w < 1 {
 		w = 768
@@ -398,10 +870,39 @@ func GetArticleThumbsAction(c *gin.Context) {
 		h = 432
 	}
 
+	// images uploaded to the blog are resized by the /images route, falls back to the Bing images (1920*1080) resized by
+	// Qiniu if there is not enough of them
 	var styledURLs []string
-	for _, url := range urls {
+	session := util.GetSession(c)
+	for _, image := range service.Image.GetRandomImages(n, session.BID) {
+		styledURLs = append(styledURLs, model.Conf.Server+util.PathImages+"/"+image.Hash+"?w="+strconv.Itoa(w)+"&h="+strconv.Itoa(h))
+	}
+	for _, url := range util.RandImages(n - len(styledURLs)) {
 		styledURLs = append(styledURLs, util.ImageSize(url, w, h))
 	}
 
 	result.Data = styledURLs
 }
+
+// parseArticleVisibility fills the visibility and the access password of the specified article with the specified
+// request arguments, an empty password keeps the current one.
+func parseArticleVisibility(arg map[string]interface{}, article *model.Article) error {
+	if visibility, ok := arg["visibility"].(float64); ok {
+		article.Visibility = int(visibility)
+	}
+	if password, ok := arg["password"].(string); ok && "" != password && model.ArticleVisibilityPassword == article.Visibility {
+		return service.Article.SetArticlePassword(article, password)
+	}
+
+	return nil
+}
+
+// canEditArticle checks whether the user of the specified session can edit or remove the specified article. Editors
+// and admins can edit all articles of the blog, authors can only edit their own drafts.
+func canEditArticle(session *util.SessionData, article *model.Article) bool {
+	if model.UserRoleBlogEditor >= session.URole {
+		return true
+	}
+
+	return session.UID == article.AuthorID && model.ArticleStatusDraft == article.Status
+}
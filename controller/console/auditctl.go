@@ -0,0 +1,115 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLogsAction gets audit logs of the current blog. Logs can be filtered by user name, action and date range
+// (from and to are both inclusive dates in format yyyy-MM-dd).
+func GetAuditLogsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	query := &service.AuditLogQuery{Action: c.Query("action")}
+	if name := c.Query("user"); "" != name {
+		user := service.User.GetUserByName(name)
+		if nil == user {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeNotFound
+			result.Msg = "not found user [" + name + "]"
+
+			return
+		}
+		query.UserID = user.ID
+	}
+	if from := c.Query("from"); "" != from {
+		date, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid date [" + from + "]"
+
+			return
+		}
+		query.From = date
+	}
+	if to := c.Query("to"); "" != to {
+		date, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid date [" + to + "]"
+
+			return
+		}
+		query.To = date.AddDate(0, 0, 1)
+	}
+
+	session := util.GetSession(c)
+	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
+	logModels, pagination := service.AuditLog.GetAuditLogs(query, util.GetPage(c), session.BID)
+	logs := []*ConsoleAuditLog{}
+	for _, logModel := range logModels {
+		user := &ConsoleAuthor{}
+		if userModel := service.User.GetUser(logModel.UserID); nil != userModel {
+			user.Name = userModel.Name
+			user.URL = blogURLSetting.Value + util.PathAuthors + "/" + userModel.Name
+			user.AvatarURL = userModel.AvatarURL
+		}
+
+		logs = append(logs, &ConsoleAuditLog{
+			ID:        logModel.ID,
+			User:      user,
+			Action:    logModel.Action,
+			Target:    logModel.Target,
+			IP:        logModel.IP,
+			CreatedAt: logModel.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	result.Data = map[string]interface{}{
+		"logs":       logs,
+		"pagination": pagination,
+	}
+}
+
+// audit records the specified action on the specified target done by the user of the current session.
+func audit(c *gin.Context, action, target string) {
+	if runes := []rune(target); 255 < len(runes) {
+		target = string(runes[:255])
+	}
+
+	session := util.GetSession(c)
+	log := &model.AuditLog{
+		UserID: session.UID,
+		Action: action,
+		Target: target,
+		IP:     util.GetRemoteAddr(c),
+		BlogID: session.BID,
+	}
+	if err := service.AuditLog.AddAuditLog(log); nil != err {
+		util.Log(c).Errorf("add audit log failed: %s", err)
+	}
+}
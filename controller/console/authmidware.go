@@ -18,17 +18,23 @@ package console
 
 import (
 	"net/http"
+	"strings"
 
-	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
 	"github.com/gin-gonic/gin"
 )
 
-// LoginCheck checks login or not.
+// totpVerifyPath is the path to verify the second factor, which is reachable before the verification.
+const totpVerifyPath = "/console/settings/security/verify"
+
+// LoginCheck checks login or not. Users enabled two-factor authentication have to verify the second factor in the
+// session as well. The role in the session is refreshed as it may have been changed by the blog admin.
 func LoginCheck(c *gin.Context) {
 	session := util.GetSession(c)
 	if 0 == session.UID {
-		result := gulu.Ret.NewResult()
+		result := util.NewResult(c)
 		result.Code = util.CodeAuthErr
 		result.Msg = "unauthenticated request"
 		c.AbortWithStatusJSON(http.StatusOK, result)
@@ -36,5 +42,53 @@ func LoginCheck(c *gin.Context) {
 		return
 	}
 
+	if !session.UTOTP && !strings.HasSuffix(c.Request.URL.Path, totpVerifyPath) {
+		if user := service.User.GetUser(session.UID); nil != user && user.TOTPEnabled {
+			result := util.NewResult(c)
+			result.Code = util.CodeAuthErr
+			result.ErrCode = util.ErrCodeTOTPRequired
+			result.Msg = "two-factor authentication required"
+			c.AbortWithStatusJSON(http.StatusOK, result)
+
+			return
+		}
+	}
+
+	role := service.User.GetRole(session.UID, session.BID)
+	if model.UserRoleNoLogin == role {
+		result := util.NewResult(c)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "not a member of the current blog"
+		c.AbortWithStatusJSON(http.StatusOK, result)
+
+		return
+	}
+	if role != session.URole {
+		session.URole = role
+		if err := session.Save(c); nil != err {
+			util.Log(c).Errorf("saves session failed: %s", err)
+		}
+	}
+
 	c.Next()
 }
+
+// RoleCheck returns a handler which rejects users whose role in the current blog has less permissions than the
+// specified role.
+func RoleCheck(role int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := util.GetSession(c)
+		if role < session.URole {
+			result := util.NewResult(c)
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeForbidden
+			result.Msg = "permission denied"
+			c.AbortWithStatusJSON(http.StatusOK, result)
+
+			return
+		}
+
+		c.Next()
+	}
+}
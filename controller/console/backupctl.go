@@ -0,0 +1,112 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// BackupAction downloads a full backup archive of the current blog.
+func BackupAction(c *gin.Context) {
+	session := util.GetSession(c)
+	if model.UserRoleBlogAdmin < session.URole {
+		result := util.NewResult(c)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only blog admin can backup"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+
+	backup, err := service.Backup.Backup(session.BID)
+	if nil != err {
+		util.Log(c).Errorf("backup blog [%d] failed: %s", session.BID, err)
+		result := util.NewResult(c)
+		result.Code = util.CodeErr
+		result.Msg = "backup failed"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+
+	filename := "pipe-backup-" + strconv.FormatUint(session.BID, 10) + "-" + backup.CreatedAt.Format("20060102150405") + ".zip"
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", "application/zip")
+	if err := service.Backup.WriteArchive(backup, c.Writer); nil != err {
+		util.Log(c).Errorf("write backup archive failed: %s", err)
+	}
+}
+
+// RestoreAction restores the current blog from an uploaded backup archive.
+func RestoreAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if model.UserRoleBlogAdmin < session.URole {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only blog admin can restore"
+
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parse upload file header failed"
+
+		return
+	}
+	f, err := file.Open()
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "open upload file failed"
+
+		return
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "read upload file failed"
+
+		return
+	}
+
+	backup, err := service.Backup.ReadArchive(data)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "read backup archive failed: " + err.Error()
+
+		return
+	}
+
+	if err = service.Backup.Restore(backup, session.BID); nil != err {
+		util.Log(c).Errorf("restore blog [%d] failed: %s", session.BID, err)
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -32,7 +31,7 @@ import (
 
 // BlogSwitchAction switches blog.
 func BlogSwitchAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -79,7 +78,7 @@ func BlogSwitchAction(c *gin.Context) {
 
 // CheckVersionAction checks version.
 func CheckVersionAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	rhyResult := map[string]interface{}{}
@@ -20,7 +20,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -29,7 +28,7 @@ import (
 
 // UpdateCategoryAction updates a category.
 func UpdateCategoryAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -60,7 +59,7 @@ func UpdateCategoryAction(c *gin.Context) {
 
 // GetCategoryAction gets a category.
 func GetCategoryAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -84,7 +83,7 @@ func GetCategoryAction(c *gin.Context) {
 
 // GetCategoriesAction gets categories.
 func GetCategoriesAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -111,7 +110,7 @@ func GetCategoriesAction(c *gin.Context) {
 
 // AddCategoryAction adds a category.
 func AddCategoryAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -133,7 +132,7 @@ func AddCategoryAction(c *gin.Context) {
 
 // RemoveCategoryAction removes a category.
 func RemoveCategoryAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -152,3 +151,24 @@ func RemoveCategoryAction(c *gin.Context) {
 		result.Msg = err.Error()
 	}
 }
+
+// GetCategoryDefaultsAction gets defaults of a new article with the specified tags.
+func GetCategoryDefaultsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	tags := c.Query("tags")
+	data := map[string]interface{}{
+		"category":    "",
+		"layout":      "",
+		"sign":        "",
+		"commentable": service.Category.GetArticleCommentable(tags, session.BID),
+	}
+	if category := service.Category.GetArticleCategory(tags, session.BID); nil != category {
+		data["category"] = category.Title
+		data["layout"] = category.Layout
+		data["sign"] = category.Sign
+	}
+	result.Data = data
+}
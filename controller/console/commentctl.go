@@ -21,7 +21,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -30,11 +29,17 @@ import (
 
 // GetCommentsAction gets comments
 func GetCommentsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
-	commentModels, pagination := service.Comment.ConsoleGetComments(c.Query("key"), util.GetPage(c), session.BID)
+	var commentModels []*model.Comment
+	var pagination *util.Pagination
+	if "spam" == c.Query("status") {
+		commentModels, pagination = service.Comment.ConsoleGetSpamComments(c.Query("key"), util.GetPage(c), session.BID)
+	} else {
+		commentModels, pagination = service.Comment.ConsoleGetComments(c.Query("key"), util.GetPage(c), session.BID)
+	}
 	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
 
 	var comments []*ConsoleComment
@@ -70,6 +75,7 @@ func GetCommentsAction(c *gin.Context) {
 			Title:         article.Title,
 			Content:       template.HTML(mdResult.ContentHTML),
 			URL:           blogURLSetting.Value + article.Path + "?p=" + strconv.Itoa(page) + "#pipeComment" + strconv.Itoa(int(commentModel.ID)),
+			Spam:          model.CommentStatusSpam == commentModel.Status,
 		}
 
 		comments = append(comments, comment)
@@ -83,7 +89,7 @@ func GetCommentsAction(c *gin.Context) {
 
 // RemoveCommentAction removes a comment.
 func RemoveCommentAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -97,6 +103,13 @@ func RemoveCommentAction(c *gin.Context) {
 
 	session := util.GetSession(c)
 	blogID := session.BID
+	if !canRemoveComment(session, id) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "authors can only remove comments of their own articles"
+
+		return
+	}
 
 	if err := service.Comment.RemoveComment(id, blogID); nil != err {
 		result.Code = util.CodeErr
@@ -106,7 +119,7 @@ func RemoveCommentAction(c *gin.Context) {
 
 // RemoveCommentsAction removes comments.
 func RemoveCommentsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -120,9 +133,76 @@ func RemoveCommentsAction(c *gin.Context) {
 	session := util.GetSession(c)
 	blogID := session.BID
 	ids := arg["ids"].([]interface{})
-	for _, id := range ids {
-		if err := service.Comment.RemoveComment(uint64(id.(float64)), blogID); nil != err {
-			logger.Errorf("remove comment failed: " + err.Error())
+	for _, idArg := range ids {
+		id := uint64(idArg.(float64))
+		if !canRemoveComment(session, id) {
+			util.Log(c).Warnf("user [%s] is not allowed to remove comment [%d]", session.UName, id)
+
+			continue
 		}
+		if err := service.Comment.RemoveComment(id, blogID); nil != err {
+			util.Log(c).Errorf("remove comment failed: %s", err)
+		}
+	}
+}
+
+// ApproveCommentAction publishes a comment which is flagged as spam.
+func ApproveCommentAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	comment := service.Comment.GetComment(id)
+	if nil == comment || session.BID != comment.BlogID {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found comment"
+
+		return
+	}
+	if model.CommentStatusSpam != comment.Status {
+		return
 	}
+
+	if err := service.Comment.ApproveComment(id, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	spamCtx := &service.SpamContext{UserAgent: comment.UserAgent}
+	if article := service.Article.ConsoleGetArticle(comment.ArticleID); nil != article {
+		blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
+		spamCtx.Permalink = blogURLSetting.Value + article.Path
+	}
+	if author := service.User.GetUser(comment.AuthorID); nil != author {
+		spamCtx.AuthorName = author.Name
+	}
+	go service.Spam.SubmitHam(comment, spamCtx)
+}
+
+// canRemoveComment checks whether the user of the specified session can remove the specified comment. Editors and
+// admins moderate all comments of the blog, authors can only remove comments of their own articles.
+func canRemoveComment(session *util.SessionData, commentID uint64) bool {
+	if model.UserRoleBlogEditor >= session.URole {
+		return true
+	}
+
+	comment := service.Comment.GetComment(commentID)
+	if nil == comment {
+		return true // nothing to remove
+	}
+	article := service.Article.ConsoleGetArticle(comment.ArticleID)
+
+	return nil != article && session.UID == article.AuthorID
 }
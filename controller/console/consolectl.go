@@ -19,6 +19,7 @@ package console
 import (
 	"net/http"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/b3log/pipe/model"
@@ -37,11 +38,12 @@ func ShowAdminPagesAction(c *gin.Context) {
 
 	t, err := template.ParseFiles(filepath.Join("console/dist/admin" + c.Param("path") + "/index.html"))
 	if nil != err {
-		logger.Errorf("load console page [" + c.Param("path") + "] failed: " + err.Error())
+		util.Log(c).Errorf("load console page [%s] failed: %s", c.Param("path"), err)
 		c.String(http.StatusNotFound, "load console page failed")
 
 		return
 	}
 
+	c.Header("Content-Language", strings.Replace(util.RequestLocale(c), "_", "-", -1))
 	t.Execute(c.Writer, nil)
 }
@@ -30,16 +30,74 @@ type ConsoleArticle struct {
 	Title        string         `json:"title"`
 	Tags         []*ConsoleTag  `json:"tags"`
 	URL          string         `json:"url"`
+	Status       int            `json:"status"`
 	Topped       bool           `json:"topped"`
+	ToppedOrder  int            `json:"toppedOrder"`
+	Visibility   int            `json:"visibility"`
 	ViewCount    int            `json:"viewCount"`
 	CommentCount int            `json:"commentCount"`
+	Lock         *ConsoleLock   `json:"lock,omitempty"` // the article is being edited by someone
+}
+
+// ConsoleTrashedArticle represents console article in the trash.
+type ConsoleTrashedArticle struct {
+	ID           uint64         `json:"id"`
+	Author       *ConsoleAuthor `json:"author"`
+	CreatedAt    string         `json:"createdAt"`
+	TrashedAt    string         `json:"trashedAt"`
+	Title        string         `json:"title"`
+	Path         string         `json:"path"`
+	Status       int            `json:"status"`
+	CommentCount int            `json:"commentCount"`
+}
+
+// ConsoleReport represents console report of an article or a comment.
+type ConsoleReport struct {
+	ID         uint64 `json:"id"`
+	CreatedAt  string `json:"createdAt"`
+	TargetType int    `json:"targetType"`
+	TargetID   uint64 `json:"targetID"`
+	Target     string `json:"target"` // title of the article or content of the comment
+	URL        string `json:"url"`
+	Reason     string `json:"reason"`
+	Detail     string `json:"detail"`
+	IP         string `json:"ip"`
+	Status     int    `json:"status"`
+	Resolution string `json:"resolution"`
+}
+
+// ConsolePage represents console static page.
+type ConsolePage struct {
+	ID        uint64 `json:"id"`
+	Title     string `json:"title"`
+	Path      string `json:"path"`
+	URL       string `json:"url"`
+	Status    int    `json:"status"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ConsoleLock represents console article lock.
+type ConsoleLock struct {
+	Acquired  bool   `json:"acquired"` // whether the lock is held by the current user
+	UserName  string `json:"userName"`
+	AvatarURL string `json:"avatarURL"`
+	LockedAt  string `json:"lockedAt"`
+}
+
+// ConsoleRevision represents console article revision.
+type ConsoleRevision struct {
+	Version   int    `json:"version"`
+	Title     string `json:"title"`
+	Editor    string `json:"editor"`
+	CreatedAt string `json:"createdAt"`
 }
 
 // ConsoleTag represents console tag.
 type ConsoleTag struct {
-	ID    uint64 `json:"id"`
-	Title string `json:"title"`
-	URL   string `json:"url,omitempty"`
+	ID           uint64 `json:"id"`
+	Title        string `json:"title"`
+	URL          string `json:"url,omitempty"`
+	ArticleCount int    `json:"articleCount,omitempty"`
 }
 
 // ConsoleAuthor represents console author.
@@ -73,6 +131,16 @@ type ConsoleComment struct {
 	Title         string         `json:"title"`
 	Content       template.HTML  `json:"content"`
 	URL           string         `json:"url"`
+	Spam          bool           `json:"spam"`
+}
+
+// ConsoleInvitation represents console invitation.
+type ConsoleInvitation struct {
+	ID        uint64 `json:"id"`
+	URL       string `json:"url"`
+	Email     string `json:"email"`
+	Role      int    `json:"role"`
+	ExpiredAt string `json:"expiredAt"`
 }
 
 // ConsoleNavigation represents console navigation.
@@ -89,6 +157,9 @@ type ConsoleNavigation struct {
 type ConsoleTheme struct {
 	Name         string `json:"name"`
 	ThumbnailURL string `json:"thumbnailURL"`
+	Version      string `json:"version"`
+	TemplateAPI  int    `json:"templateAPI"`
+	Compatible   bool   `json:"compatible"`
 }
 
 // ConsoleUser represents console user.
@@ -101,3 +172,44 @@ type ConsoleUser struct {
 	AvatarURL    string `json:"avatarURL"`
 	ArticleCount int    `json:"articleCount"`
 }
+
+// ConsoleAuditLog represents console audit log.
+type ConsoleAuditLog struct {
+	ID        uint64         `json:"id"`
+	User      *ConsoleAuthor `json:"user"`
+	Action    string         `json:"action"`
+	Target    string         `json:"target"`
+	IP        string         `json:"ip"`
+	CreatedAt string         `json:"createdAt"`
+}
+
+// ConsoleSession represents console login session.
+type ConsoleSession struct {
+	ID         uint64 `json:"id"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"userAgent"`
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt"`
+	Current    bool   `json:"current"`
+}
+
+// ConsoleMediaFile represents console media file.
+type ConsoleMediaFile struct {
+	ID         uint64                   `json:"id"`
+	Name       string                   `json:"name"`
+	URL        string                   `json:"url"`
+	MIMEType   string                   `json:"mimeType"`
+	Size       int64                    `json:"size"`
+	Uploader   *ConsoleAuthor           `json:"uploader"`
+	CreatedAt  string                   `json:"createdAt"`
+	Usages     []*ConsoleMediaFileUsage `json:"usages"`     // articles referencing the file
+	Manageable bool                     `json:"manageable"` // whether the current user can remove or replace the file
+}
+
+// ConsoleMediaFileUsage represents an article referencing a console media file.
+type ConsoleMediaFileUsage struct {
+	ID     uint64 `json:"id"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
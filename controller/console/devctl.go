@@ -40,7 +40,7 @@ func GenArticlesAction(c *gin.Context) {
 			BlogID:   session.BID,
 		}
 		if err := service.Article.AddArticle(article); nil != err {
-			logger.Errorf("generate article failed: " + err.Error())
+			util.Log(c).Errorf("generate article failed: %s", err)
 		}
 	}
 
@@ -29,9 +29,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// ExportMarkdownAction exports articles as markdown zip file.
+// ExportMarkdownAction exports articles as markdown zip file with the attached images.
 func ExportMarkdownAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -43,11 +43,11 @@ func ExportMarkdownAction(c *gin.Context) {
 	}
 
 	tempDir := os.TempDir()
-	logger.Trace("temp dir path is [" + tempDir + "]")
+	util.Log(c).Tracef("temp dir path is [%s]", tempDir)
 	zipFilePath := filepath.Join(tempDir, session.UName+"-export-md.zip")
 	zipFile, err := gulu.Zip.Create(zipFilePath)
 	if nil != err {
-		logger.Errorf("create zip file [" + zipFilePath + "] failed: " + err.Error())
+		util.Log(c).Errorf("create zip file [%s] failed: %s", zipFilePath, err)
 		result.Code = util.CodeErr
 		result.Msg = "create zip file failed"
 
@@ -62,7 +62,7 @@ func ExportMarkdownAction(c *gin.Context) {
 		zipFile.Close()
 		file, err := os.Open(zipFilePath)
 		if nil != err {
-			logger.Errorf("open zip file [" + zipFilePath + " failed: " + err.Error())
+			util.Log(c).Errorf("open zip file [%s failed: %s", zipFilePath, err)
 			result.Code = util.CodeErr
 			result.Msg = "open zip file failed"
 
@@ -77,29 +77,46 @@ func ExportMarkdownAction(c *gin.Context) {
 
 	zipPath := filepath.Join(tempDir, session.UName+"-export-md")
 	if err = os.RemoveAll(zipPath); nil != err {
-		logger.Errorf("remove temp dir [" + zipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("remove temp dir [%s] failed: %s", zipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "remove temp dir failed"
 
 		return
 	}
 	if err = os.Mkdir(zipPath, 0755); nil != err {
-		logger.Errorf("make temp dir [" + zipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("make temp dir [%s] failed: %s", zipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "make temp dir failed"
 
 		return
 	}
+	images := service.Export.ExportImages(mdFiles)
+	if 0 < len(images) {
+		imagesPath := filepath.Join(zipPath, "images")
+		if err = os.Mkdir(imagesPath, 0755); nil != err {
+			util.Log(c).Errorf("make temp dir [%s] failed: %s", imagesPath, err)
+			result.Code = util.CodeErr
+			result.Msg = "make temp dir failed"
+
+			return
+		}
+		for _, image := range images {
+			filename := filepath.Join(imagesPath, image.Name)
+			if err := ioutil.WriteFile(filename, image.Data, 0644); nil != err {
+				util.Log(c).Errorf("write file [%s] failed: %s", filename, err)
+			}
+		}
+	}
 	for _, mdFile := range mdFiles {
 		filename := filepath.Join(zipPath, mdFile.Name+".md")
 		if err := ioutil.WriteFile(filename, []byte(mdFile.Content), 0644); nil != err {
-			logger.Errorf("write file [" + filename + "] failed: " + err.Error())
+			util.Log(c).Errorf("write file [%s] failed: %s", filename, err)
 		}
 	}
 
 	zipFile.AddDirectory(session.UName+"-export-md", zipPath)
 	if err := zipFile.Close(); nil != err {
-		logger.Errorf("zip failed: " + err.Error())
+		util.Log(c).Errorf("zip failed: %s", err)
 		result.Code = util.CodeErr
 		result.Msg = "zip failed"
 
@@ -107,7 +124,7 @@ func ExportMarkdownAction(c *gin.Context) {
 	}
 	file, err := os.Open(zipFilePath)
 	if nil != err {
-		logger.Errorf("open zip file [" + zipFilePath + " failed: " + err.Error())
+		util.Log(c).Errorf("open zip file [%s failed: %s", zipFilePath, err)
 		result.Code = util.CodeErr
 		result.Msg = "open zip file failed"
 
@@ -0,0 +1,137 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetGlossariesAction gets glossaries.
+func GetGlossariesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	glossaries, pagination := service.Glossary.ConsoleGetGlossaries(util.GetPage(c), session.BID)
+
+	data := map[string]interface{}{}
+	data["glossaries"] = glossaries
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// GetGlossaryAction gets a glossary.
+func GetGlossaryAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+
+		return
+	}
+
+	data := service.Glossary.ConsoleGetGlossary(id)
+	if nil == data {
+		result.Code = util.CodeErr
+
+		return
+	}
+
+	result.Data = data
+}
+
+// RemoveGlossaryAction removes a glossary.
+func RemoveGlossaryAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.Glossary.RemoveGlossary(id, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// UpdateGlossaryAction updates a glossary.
+func UpdateGlossaryAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	glossary := &model.Glossary{Model: model.Model{ID: id}}
+	if err := c.BindJSON(glossary); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update glossary request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	glossary.BlogID = session.BID
+
+	if err := service.Glossary.UpdateGlossary(glossary); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// AddGlossaryAction adds a glossary.
+func AddGlossaryAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	glossary := &model.Glossary{}
+	if err := c.BindJSON(glossary); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses add glossary request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	glossary.BlogID = session.BID
+	if err := service.Glossary.AddGlossary(glossary); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/service"
@@ -31,7 +32,7 @@ import (
 
 // ImportMarkdownAction imports markdown zip file as articles.
 func ImportMarkdownAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -45,7 +46,7 @@ func ImportMarkdownAction(c *gin.Context) {
 	form, err := c.MultipartForm()
 	if nil != err {
 		msg := "parse upload file header failed"
-		logger.Errorf(msg + ": " + err.Error())
+		util.Log(c).Errorf("%s: %s", msg, err)
 		result.Code = util.CodeErr
 		result.Msg = msg
 
@@ -56,7 +57,7 @@ func ImportMarkdownAction(c *gin.Context) {
 	f, err := file.Open()
 	if nil != err {
 		msg := "open upload file failed"
-		logger.Errorf(msg + ": " + err.Error())
+		util.Log(c).Errorf("%s: %s", msg, err)
 		result.Code = util.CodeErr
 		result.Msg = msg
 
@@ -65,11 +66,11 @@ func ImportMarkdownAction(c *gin.Context) {
 	defer f.Close()
 
 	tempDir := os.TempDir()
-	logger.Trace("temp dir path is [" + tempDir + "]")
+	util.Log(c).Tracef("temp dir path is [%s]", tempDir)
 	zipFilePath := filepath.Join(tempDir, session.UName+"-import-md.zip")
 	zipFile, err := os.Create(zipFilePath)
 	if nil != err {
-		logger.Errorf("create temp file [" + zipFilePath + "] failed: " + err.Error())
+		util.Log(c).Errorf("create temp file [%s] failed: %s", zipFilePath, err)
 		result.Code = util.CodeErr
 		result.Msg = "create temp file failed"
 
@@ -77,7 +78,7 @@ func ImportMarkdownAction(c *gin.Context) {
 	}
 	_, err = io.Copy(zipFile, f)
 	if nil != err {
-		logger.Errorf("write temp file [" + zipFilePath + "] failed: " + err.Error())
+		util.Log(c).Errorf("write temp file [%s] failed: %s", zipFilePath, err)
 		result.Code = util.CodeErr
 		result.Msg = "write temp file failed"
 
@@ -87,28 +88,28 @@ func ImportMarkdownAction(c *gin.Context) {
 
 	unzipPath := filepath.Join(tempDir, session.UName+"-import-md")
 	if err = os.RemoveAll(unzipPath); nil != err {
-		logger.Errorf("remove temp dir [" + unzipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("remove temp dir [%s] failed: %s", unzipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "remove temp dir failed"
 
 		return
 	}
 	if err = os.Mkdir(unzipPath, 0755); nil != err {
-		logger.Errorf("make temp dir [" + unzipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("make temp dir [%s] failed: %s", unzipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "make temp dir failed"
 
 		return
 	}
 	if err = gulu.Zip.Unzip(zipFilePath, unzipPath); nil != err {
-		logger.Errorf("unzip [" + zipFilePath + "] to [" + unzipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("unzip [%s] to [%s] failed: %s", zipFilePath, unzipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "unzip failed"
 
 		return
 	}
 
-	logger.Info("importing markdowns [zipFilePath=" + zipFilePath + ", unzipPath=" + unzipPath + "]")
+	util.Log(c).Infof("importing markdowns [zipFilePath=%s, unzipPath=%s]", zipFilePath, unzipPath)
 
 	var filePaths []string
 	err = filepath.Walk(unzipPath, func(path string, f os.FileInfo, err error) error {
@@ -119,7 +120,7 @@ func ImportMarkdownAction(c *gin.Context) {
 		return err
 	})
 	if nil != err {
-		logger.Errorf("read dir [" + unzipPath + "] failed: " + err.Error())
+		util.Log(c).Errorf("read dir [%s] failed: %s", unzipPath, err)
 		result.Code = util.CodeErr
 		result.Msg = "read dir failed"
 
@@ -133,9 +134,13 @@ func ImportMarkdownAction(c *gin.Context) {
 		bom2 = 0xbf
 	)
 	for _, filePath := range filePaths {
+		if ext := strings.ToLower(filepath.Ext(filePath)); ".md" != ext && ".markdown" != ext {
+			continue
+		}
+
 		data, err := ioutil.ReadFile(filePath)
 		if nil != err {
-			logger.Errorf("read file [" + filePath + "] failed")
+			util.Log(c).Errorf("read file [%s] failed", filePath)
 
 			continue
 		}
@@ -155,3 +160,52 @@ func ImportMarkdownAction(c *gin.Context) {
 
 	service.Import.ImportMarkdowns(mdFiles, session.UID, session.BID)
 }
+
+// ImportWordPressAction imports a WordPress eXtended RSS (WXR) export file.
+func ImportWordPressAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		result.Code = util.CodeErr
+		result.Msg = "please login before import"
+
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if nil != err {
+		msg := "parse upload file header failed"
+		util.Log(c).Errorf("%s: %s", msg, err)
+		result.Code = util.CodeErr
+		result.Msg = msg
+
+		return
+	}
+
+	f, err := file.Open()
+	if nil != err {
+		msg := "open upload file failed"
+		util.Log(c).Errorf("%s: %s", msg, err)
+		result.Code = util.CodeErr
+		result.Msg = msg
+
+		return
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if nil != err {
+		msg := "read upload file failed"
+		util.Log(c).Errorf("%s: %s", msg, err)
+		result.Code = util.CodeErr
+		result.Msg = msg
+
+		return
+	}
+
+	util.Log(c).Infof("importing WordPress WXR [%s]", file.Filename)
+
+	result.Data = service.Import.ImportWordPress(data, session.UID, session.BID)
+}
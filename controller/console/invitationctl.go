@@ -0,0 +1,140 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetInvitationsAction gets pending invitations.
+func GetInvitationsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	var invitations []*ConsoleInvitation
+	for _, invitationModel := range service.Invitation.GetPendingInvitations(session.BID) {
+		invitations = append(invitations, newConsoleInvitation(invitationModel))
+	}
+
+	result.Data = invitations
+}
+
+// AddInvitationAction adds an invitation, the invitation link is mailed to the invitee if the email is specified and
+// the SMTP server is configured.
+func AddInvitationAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses add invitation request failed"
+
+		return
+	}
+
+	role := model.UserRoleBlogUser
+	if roleArg, ok := arg["role"].(float64); ok {
+		role = int(roleArg)
+	}
+	if model.UserRoleBlogEditor != role && model.UserRoleBlogUser != role { // a blog has only one admin, who owns it
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "invalid role [" + strconv.Itoa(role) + "]"
+
+		return
+	}
+	email, _ := arg["email"].(string)
+	email = strings.TrimSpace(email)
+	if "" != email {
+		if _, err := mail.ParseAddress(email); nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid email [" + email + "]"
+
+			return
+		}
+	}
+
+	invitation := &model.Invitation{
+		Role:      role,
+		Email:     email,
+		CreatorID: session.UID,
+		BlogID:    session.BID,
+	}
+	if err := service.Invitation.AddInvitation(invitation); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	if "" != email && service.Mail.Enabled() {
+		go sendInvitationMail(invitation, session.UName)
+	}
+
+	result.Data = newConsoleInvitation(invitation)
+}
+
+// RemoveInvitationAction removes an invitation.
+func RemoveInvitationAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.Invitation.RemoveInvitation(id, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+func newConsoleInvitation(invitation *model.Invitation) *ConsoleInvitation {
+	return &ConsoleInvitation{
+		ID:        invitation.ID,
+		URL:       service.Invitation.GetInvitationURL(invitation.Token),
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiredAt: invitation.ExpiredAt.Format("2006-01-02 15:04"),
+	}
+}
+
+func sendInvitationMail(invitation *model.Invitation, inviter string) {
+	locale := service.Setting.GetSetting(model.SettingCategoryI18n, model.SettingNameI18nLocale, invitation.BlogID).Value
+	blogTitle := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, invitation.BlogID).Value
+	url := service.Invitation.GetInvitationURL(invitation.Token)
+	subject := i18n.GetMessagef(locale, "invitationMailSubject", inviter, blogTitle)
+	body := i18n.GetMessagef(locale, "invitationMailBody", inviter, blogTitle, url, url)
+	service.Mail.Send([]string{invitation.Email}, subject, body)
+}
@@ -0,0 +1,89 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobsAction gets the background jobs with their run statistics.
+func GetJobsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see jobs"
+
+		return
+	}
+
+	result.Data = service.Job.GetJobs()
+}
+
+// GetJobRunsAction gets failed runs of the job specified by the name query, runs of all jobs if not specified.
+func GetJobRunsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see jobs"
+
+		return
+	}
+
+	runs, pagination := service.Job.ConsoleGetJobRuns(c.Query("name"), util.GetPage(c))
+	data := map[string]interface{}{}
+	data["runs"] = runs
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// RunJobAction runs the specified job now.
+func RunJobAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can run jobs"
+
+		return
+	}
+
+	name := c.Param("name")
+	if err := service.Job.Run(name); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionJobRun, name)
+}
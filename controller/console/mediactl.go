@@ -0,0 +1,201 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMediaFilesAction gets files uploaded to the current blog, the optional query parameter "key" filters them by name.
+// Each file contains the articles referencing it.
+func GetMediaFilesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	mediaFileModels, pagination := service.MediaFile.ConsoleGetMediaFiles(c.Query("key"), util.GetPage(c), session.BID)
+	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
+
+	mediaFiles := []*ConsoleMediaFile{}
+	for _, mediaFileModel := range mediaFileModels {
+		mediaFile := &ConsoleMediaFile{
+			ID:         mediaFileModel.ID,
+			Name:       mediaFileModel.Name,
+			URL:        mediaFileModel.URL,
+			MIMEType:   mediaFileModel.MIMEType,
+			Size:       mediaFileModel.Size,
+			CreatedAt:  mediaFileModel.CreatedAt.Format("2006-01-02 15:04:05"),
+			Usages:     []*ConsoleMediaFileUsage{},
+			Manageable: canManageMediaFile(session, mediaFileModel),
+		}
+		if uploader := service.User.GetUser(mediaFileModel.UploaderID); nil != uploader {
+			mediaFile.Uploader = &ConsoleAuthor{
+				Name:      uploader.Name,
+				URL:       blogURLSetting.Value + util.PathAuthors + "/" + uploader.Name,
+				AvatarURL: uploader.AvatarURL,
+			}
+		}
+		for _, article := range service.MediaFile.GetMediaFileUsages(mediaFileModel) {
+			mediaFile.Usages = append(mediaFile.Usages, &ConsoleMediaFileUsage{
+				ID:     article.ID,
+				Title:  article.Title,
+				URL:    blogURLSetting.Value + article.Path,
+				Status: article.Status,
+			})
+		}
+
+		mediaFiles = append(mediaFiles, mediaFile)
+	}
+
+	data := map[string]interface{}{}
+	data["mediaFiles"] = mediaFiles
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// RemoveMediaFileAction removes a media file. Files referenced by articles are removed only if the query parameter
+// "force" is true.
+func RemoveMediaFileAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	mediaFile := getConsoleMediaFile(c, result)
+	if nil == mediaFile {
+		return
+	}
+
+	force := "true" == c.Query("force")
+	if err := service.MediaFile.RemoveMediaFile(mediaFile, force); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrMediaFileInUse == err {
+			result.ErrCode = util.ErrCodeConflict
+		}
+
+		return
+	}
+	audit(c, model.AuditActionMediaRemove, mediaFile.URL)
+}
+
+// ReplaceMediaFileAction replaces the content of a media file with the file in multipart form field "file". The URL of
+// the media file does not change, so the new file must be of the same type.
+func ReplaceMediaFileAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	mediaFile := getConsoleMediaFile(c, result)
+	if nil == mediaFile {
+		return
+	}
+
+	session := util.GetSession(c)
+	maxSize := service.Upload.GetMaxSize(session.BID)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+64*1024)
+	file, err := c.FormFile("file")
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses replace media file request failed"
+
+		return
+	}
+	if maxSize < file.Size {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "file size exceeds " + strconv.FormatInt(maxSize/1024, 10) + "KB"
+
+		return
+	}
+	data, err := readUploadFile(file)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	data, mimeType, _, err := checkUpload(data, session.BID)
+	if nil != err {
+		util.Log(c).Warnf("rejected replacement [%s] of media file [%d] of user [%s]: %s", file.Filename, mediaFile.ID, session.UName, err)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+
+	if err := service.MediaFile.ReplaceMediaFile(mediaFile, mimeType, data); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		switch err {
+		case service.ErrMediaFileTypeMismatch:
+			result.ErrCode = util.ErrCodeBadRequest
+		case service.ErrMediaFileShared:
+			result.ErrCode = util.ErrCodeConflict
+		}
+
+		return
+	}
+	audit(c, model.AuditActionMediaReplace, mediaFile.URL)
+}
+
+// getConsoleMediaFile returns the media file of path parameter "id" which can be managed by the current user, sets the
+// result and returns nil if not found or not manageable.
+func getConsoleMediaFile(c *gin.Context, result *util.Result) *model.MediaFile {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return nil
+	}
+
+	session := util.GetSession(c)
+	ret := service.MediaFile.GetMediaFile(id, session.BID)
+	if nil == ret {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found media file"
+
+		return nil
+	}
+	if !canManageMediaFile(session, ret) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "authors can only manage files uploaded by themselves"
+
+		return nil
+	}
+
+	return ret
+}
+
+// canManageMediaFile checks whether the specified session can remove or replace the specified media file, editors and
+// admins can manage all files of the blog while authors can manage only their own uploads.
+func canManageMediaFile(session *util.SessionData, mediaFile *model.MediaFile) bool {
+	if model.UserRoleBlogEditor >= session.URole {
+		return true
+	}
+
+	return session.UID == mediaFile.UploaderID
+}
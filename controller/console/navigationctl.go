@@ -20,7 +20,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -29,7 +28,7 @@ import (
 
 // GetNavigationsAction gets navigations.
 func GetNavigationsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -57,7 +56,7 @@ func GetNavigationsAction(c *gin.Context) {
 
 // GetNavigationAction gets a navigation.
 func GetNavigationAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -80,7 +79,7 @@ func GetNavigationAction(c *gin.Context) {
 
 // RemoveNavigationAction remove a navigation.
 func RemoveNavigationAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -103,7 +102,7 @@ func RemoveNavigationAction(c *gin.Context) {
 
 // UpdateNavigationAction updates a navigation.
 func UpdateNavigationAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -134,7 +133,7 @@ func UpdateNavigationAction(c *gin.Context) {
 
 // AddNavigationAction adds a navigation.
 func AddNavigationAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -0,0 +1,66 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// NotificationSocketAction pushes notifications of the current blog to the console over WebSocket, such as comments
+// waiting for moderation, replies and finished imports. Notifications of moderation are pushed to editors and admins
+// only.
+func NotificationSocketAction(c *gin.Context) {
+	session := util.GetSession(c)
+	server := websocket.Server{
+		Handshake: checkSocketOrigin,
+		Handler: func(conn *websocket.Conn) {
+			notifications := service.Notification.Subscribe(session.UID, session.BID)
+			defer service.Notification.Unsubscribe(notifications, session.BID)
+
+			// the console sends nothing, reading returns once the console goes away
+			closed := make(chan bool)
+			go func() {
+				io.Copy(ioutil.Discard, conn)
+				close(closed)
+			}()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case notification := <-notifications:
+					if model.NotificationTypeCommentPending == notification.Type && model.UserRoleBlogEditor < session.URole {
+						continue
+					}
+					if err := websocket.JSON.Send(conn, notification); nil != err {
+						logger.Debugf("sends notification failed: %s", err)
+
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,163 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPagesAction gets static pages.
+func GetPagesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	pageModels, pagination := service.Page.ConsoleGetPages(util.GetPage(c), session.BID)
+	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
+
+	var pages []*ConsolePage
+	for _, pageModel := range pageModels {
+		page := &ConsolePage{
+			ID:        pageModel.ID,
+			Title:     pageModel.Title,
+			Path:      pageModel.Path,
+			URL:       blogURLSetting.Value + pageModel.Path,
+			Status:    pageModel.Status,
+			CreatedAt: pageModel.CreatedAt.Format("2006-01-02"),
+		}
+
+		pages = append(pages, page)
+	}
+
+	data := map[string]interface{}{}
+	data["pages"] = pages
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// GetPageAction gets a static page.
+func GetPageAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+
+		return
+	}
+
+	session := util.GetSession(c)
+	data := service.Page.ConsoleGetPage(id)
+	if nil == data || session.BID != data.BlogID {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found page"
+
+		return
+	}
+
+	result.Data = data
+}
+
+// RemovePageAction removes a static page.
+func RemovePageAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.Page.RemovePage(id, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// UpdatePageAction updates a static page.
+func UpdatePageAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	page := &model.Page{}
+	if err := c.BindJSON(page); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update page request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	page.ID = id
+	page.BlogID = session.BID
+	if err := service.Page.UpdatePage(page); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrReservedPath == err {
+			result.ErrCode = util.ErrCodeConflict
+		}
+	}
+}
+
+// AddPageAction adds a static page.
+func AddPageAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	page := &model.Page{}
+	if err := c.BindJSON(page); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses add page request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	page.ID = 0
+	page.AuthorID = session.UID
+	page.BlogID = session.BID
+	if err := service.Page.AddPage(page); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrReservedPath == err {
+			result.ErrCode = util.ErrCodeConflict
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// markdownSocketMaxPayload is the max size of a markdown text sent over the preview socket.
+const markdownSocketMaxPayload = 1024 * 1024
+
+// markdownSocketMessage is a message of the preview socket. The editor sends markdown text with an increasing ID and
+// receives the rendered HTML with the same ID, so stale results could be dropped.
+type markdownSocketMessage struct {
+	ID           int64  `json:"id"`
+	MarkdownText string `json:"markdownText,omitempty"`
+	HTML         string `json:"html,omitempty"`
+}
+
+// MarkdownSocketAction streams rendered HTML of the markdown text sent by the editor over WebSocket. It renders with
+// the markdown pipeline of published articles so the preview never diverges from the output. Only the latest text is
+// rendered if the author types faster than rendering.
+func MarkdownSocketAction(c *gin.Context) {
+	options := service.Setting.GetMarkdownOptions(util.GetSession(c).BID)
+	server := websocket.Server{
+		Handshake: checkSocketOrigin,
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = markdownSocketMaxPayload
+			pending := make(chan *markdownSocketMessage, 1)
+			go func() {
+				defer close(pending)
+
+				for {
+					msg := &markdownSocketMessage{}
+					if err := websocket.JSON.Receive(conn, msg); nil != err {
+						return
+					}
+
+					select {
+					case <-pending: // drops the text not rendered yet
+					default:
+					}
+					pending <- msg
+				}
+			}()
+
+			for msg := range pending {
+				html := util.MarkdownWithOptions(msg.MarkdownText, options).ContentHTML
+				if err := websocket.JSON.Send(conn, &markdownSocketMessage{ID: msg.ID, HTML: html}); nil != err {
+					logger.Debugf("sends markdown preview failed: %s", err)
+
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkSocketOrigin rejects WebSocket handshakes from pages of other sites, which could otherwise open sockets with
+// cookies of signed-in users.
+func checkSocketOrigin(config *websocket.Config, req *http.Request) (err error) {
+	if config.Origin, err = websocket.Origin(config, req); nil != err {
+		return err
+	}
+	if nil == config.Origin {
+		return errors.New("null origin")
+	}
+
+	host := config.Origin.Host
+	if strings.EqualFold(host, req.Host) {
+		return nil
+	}
+	if server, err := url.Parse(model.Conf.Server); nil == err && strings.EqualFold(host, server.Host) {
+		return nil
+	}
+
+	return errors.New("cross origin WebSocket handshake from [" + config.Origin.String() + "]")
+}
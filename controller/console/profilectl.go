@@ -0,0 +1,99 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportSettingsProfileAction exports settings and navigations of the current blog as a settings profile.
+func ExportSettingsProfileAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	result.Data = service.Profile.ExportProfile(session.BID)
+}
+
+// ImportSettingsProfileAction imports a settings profile into the current blog.
+func ImportSettingsProfileAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	profile := &service.SettingsProfile{}
+	if err := c.BindJSON(profile); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses import settings profile request failed"
+
+		return
+	}
+	for name, value := range profile.Settings[model.SettingCategoryPreference] {
+		if !isValidPreference(name, value) {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid value of preference [" + name + "]"
+
+			return
+		}
+	}
+	for _, navigation := range profile.Navigations {
+		if "" == navigation.Title || "" == navigation.URL {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "title and URL of navigations should not be empty"
+
+			return
+		}
+	}
+
+	session := util.GetSession(c)
+	oldPermalink := strconv.Itoa(model.SettingPreferencePermalinkDefault)
+	if permalinkSetting := service.Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferencePermalink, session.BID); nil != permalinkSetting {
+		oldPermalink = permalinkSetting.Value
+	}
+	if err := service.Profile.ImportProfile(profile, session.BID); nil != err {
+		result.Code = util.CodeErr
+		if service.ErrProfileVersion == err {
+			result.ErrCode = util.ErrCodeBadRequest
+		}
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, "profile")
+
+	permalink, ok := profile.Settings[model.SettingCategoryPreference][model.SettingNamePreferencePermalink]
+	if !ok || oldPermalink == permalink {
+		return
+	}
+	count, err := service.Article.ApplyPermalink(session.BID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	util.Log(c).Infof("moved [%d] articles of blog [%d] to the new permalink", count, session.BID)
+}
@@ -0,0 +1,92 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRedirectsAction gets redirects.
+func GetRedirectsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	result.Data = service.Redirect.GetRedirects(session.BID)
+}
+
+// AddRedirectAction adds a redirect.
+func AddRedirectAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	redirect := &model.Redirect{}
+	if err := c.BindJSON(redirect); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses add redirect request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	redirect.BlogID = session.BID
+	if err := service.Redirect.AddRedirect(redirect); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// RemoveRedirectAction removes a redirect.
+func RemoveRedirectAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	idArg := c.Param("id")
+	id, err := strconv.ParseUint(idArg, 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.Redirect.RemoveRedirect(id, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// GetUnknownPathsAction gets the most requested paths which can't be handled, owners may add redirects for them.
+func GetUnknownPathsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if nil != err || 1 > size {
+		size = 20
+	}
+
+	session := util.GetSession(c)
+	result.Data = service.Redirect.GetTopUnknownPaths(size, session.BID)
+}
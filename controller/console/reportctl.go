@@ -0,0 +1,207 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetReportsAction gets reports of all blogs, pending reports by default.
+func GetReportsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can review reports"
+
+		return
+	}
+
+	status, _ := strconv.Atoi(c.Query("status"))
+	reportModels, pagination := service.Report.ConsoleGetReports(status, util.GetPage(c))
+
+	var reports []*ConsoleReport
+	for _, reportModel := range reportModels {
+		report := &ConsoleReport{
+			ID:         reportModel.ID,
+			CreatedAt:  reportModel.CreatedAt.Format("2006-01-02 15:04"),
+			TargetType: reportModel.TargetType,
+			TargetID:   reportModel.TargetID,
+			Reason:     reportModel.Reason,
+			Detail:     reportModel.Detail,
+			IP:         reportModel.IP,
+			Status:     reportModel.Status,
+			Resolution: reportModel.Resolution,
+		}
+		blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, reportModel.BlogID)
+		switch reportModel.TargetType {
+		case model.ReportTargetArticle:
+			if article := service.Article.ConsoleGetArticle(reportModel.TargetID); nil != article {
+				report.Target = article.Title
+				if nil != blogURLSetting {
+					report.URL = blogURLSetting.Value + article.Path
+				}
+			}
+		case model.ReportTargetComment:
+			if comment := service.Comment.GetComment(reportModel.TargetID); nil != comment {
+				report.Target = comment.Content
+				if article := service.Article.ConsoleGetArticle(comment.ArticleID); nil != article && nil != blogURLSetting {
+					report.URL = blogURLSetting.Value + article.Path + "?p=" +
+						strconv.Itoa(service.Comment.GetCommentPage(article.ID, comment.ID, comment.BlogID)) +
+						"#pipeComment" + strconv.FormatUint(comment.ID, 10)
+				}
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	data := map[string]interface{}{}
+	data["reports"] = reports
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// ResolveReportAction resolves a report with the specified actions, hides the reported content and/or bans its IP.
+func ResolveReportAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	report := getPendingReport(c, result, session)
+	if nil == report {
+		return
+	}
+
+	arg := &struct {
+		Actions []string `json:"actions"`
+	}{}
+	if err := c.BindJSON(arg); nil != err || 1 > len(arg.Actions) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses resolve report request failed"
+
+		return
+	}
+
+	if err := service.Report.ResolveReport(report, arg.Actions, session.UID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionReportResolve, strconv.FormatUint(report.ID, 10))
+}
+
+// DismissReportAction dismisses a report without any action.
+func DismissReportAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	report := getPendingReport(c, result, session)
+	if nil == report {
+		return
+	}
+
+	if err := service.Report.ResolveReport(report, nil, session.UID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionReportDismiss, strconv.FormatUint(report.ID, 10))
+}
+
+// GetBannedIPsAction gets all banned IPs.
+func GetBannedIPsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see banned IPs"
+
+		return
+	}
+
+	result.Data = service.Report.GetBannedIPs()
+}
+
+// RemoveBannedIPAction lifts the ban of an IP.
+func RemoveBannedIPAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can unban IPs"
+
+		return
+	}
+
+	if err := service.Report.UnbanIP(c.Param("ip")); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// getPendingReport gets the pending report specified by the id param, sets the result and returns nil if the user is
+// not a platform admin or the report is not found.
+func getPendingReport(c *gin.Context, result *util.Result, session *util.SessionData) *model.Report {
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can review reports"
+
+		return nil
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return nil
+	}
+	report := service.Report.GetReport(id)
+	if nil == report || model.ReportStatusPending != report.Status {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found the pending report"
+
+		return nil
+	}
+
+	return report
+}
@@ -0,0 +1,191 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSecuritySettingsAction gets the two-factor authentication status of the current user.
+func GetSecuritySettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	user := service.User.GetUser(session.UID)
+	if nil == user {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "user not found"
+
+		return
+	}
+
+	data := map[string]interface{}{}
+	data["totpEnabled"] = user.TOTPEnabled
+	data["backupCodeCount"] = service.TOTP.GetBackupCodeCount(user)
+
+	result.Data = data
+}
+
+// StartTOTPEnrollmentAction generates a TOTP secret for the current user to scan by an authenticator app.
+func StartTOTPEnrollmentAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	secret, uri, err := service.TOTP.StartEnrollment(session.UID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrTOTPEnabled == err {
+			result.ErrCode = util.ErrCodeConflict
+		}
+
+		return
+	}
+
+	data := map[string]interface{}{}
+	data["secret"] = secret
+	data["uri"] = uri
+
+	result.Data = data
+}
+
+// EnableTOTPAction confirms the enrollment with a TOTP code and returns the backup codes.
+func EnableTOTPAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	code, ok := bindTOTPCode(c, result)
+	if !ok {
+		return
+	}
+
+	session := util.GetSession(c)
+	codes, err := service.TOTP.EnableTOTP(session.UID, code)
+	if nil != err {
+		setTOTPError(result, err)
+
+		return
+	}
+	util.Log(c).Infof("user [%s] enabled two-factor authentication", session.UName)
+
+	session.UTOTP = true
+	session.Save(c)
+
+	result.Data = map[string]interface{}{"backupCodes": codes}
+}
+
+// DisableTOTPAction disables two-factor authentication of the current user with a TOTP code or a backup code.
+func DisableTOTPAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	code, ok := bindTOTPCode(c, result)
+	if !ok {
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.TOTP.DisableTOTP(session.UID, code); nil != err {
+		setTOTPError(result, err)
+
+		return
+	}
+	util.Log(c).Infof("user [%s] disabled two-factor authentication", session.UName)
+
+	result.Data = map[string]interface{}{"totpEnabled": false}
+}
+
+// RegenerateBackupCodesAction replaces the backup codes of the current user.
+func RegenerateBackupCodesAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	code, ok := bindTOTPCode(c, result)
+	if !ok {
+		return
+	}
+
+	session := util.GetSession(c)
+	codes, err := service.TOTP.RegenerateBackupCodes(session.UID, code)
+	if nil != err {
+		setTOTPError(result, err)
+
+		return
+	}
+
+	result.Data = map[string]interface{}{"backupCodes": codes}
+}
+
+// VerifyTOTPAction verifies the second factor of the current session with a TOTP code or a backup code.
+func VerifyTOTPAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	code, ok := bindTOTPCode(c, result)
+	if !ok {
+		return
+	}
+
+	session := util.GetSession(c)
+	if !service.TOTP.Verify(session.UID, code) {
+		util.Log(c).Warnf("user [%s] failed to verify the second factor", session.UName)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = service.ErrInvalidTOTPCode.Error()
+
+		return
+	}
+
+	session.UTOTP = true
+	if err := session.Save(c); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "saves session failed: " + err.Error()
+	}
+}
+
+func bindTOTPCode(c *gin.Context, result *util.Result) (string, bool) {
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses verification code request failed"
+
+		return "", false
+	}
+
+	code, _ := arg["code"].(string)
+
+	return code, true
+}
+
+func setTOTPError(result *util.Result, err error) {
+	result.Code = util.CodeErr
+	result.Msg = err.Error()
+	switch err {
+	case service.ErrInvalidTOTPCode, service.ErrTOTPNotEnrolled, service.ErrTOTPNotEnabled:
+		result.ErrCode = util.ErrCodeBadRequest
+	case service.ErrTOTPEnabled:
+		result.ErrCode = util.ErrCodeConflict
+	}
+}
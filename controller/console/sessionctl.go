@@ -0,0 +1,75 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessionsAction gets active login sessions of the current user.
+func GetSessionsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	var currentID uint64
+	if current := service.UserSession.GetSession(session.SID); nil != current {
+		currentID = current.ID
+	}
+
+	sessions := []*ConsoleSession{}
+	for _, sessionModel := range service.UserSession.GetSessions(session.UID) {
+		sessions = append(sessions, &ConsoleSession{
+			ID:         sessionModel.ID,
+			IP:         sessionModel.IP,
+			UserAgent:  sessionModel.UserAgent,
+			CreatedAt:  sessionModel.CreatedAt.Format("2006-01-02 15:04"),
+			LastSeenAt: sessionModel.LastSeenAt.Format("2006-01-02 15:04"),
+			Current:    currentID == sessionModel.ID,
+		})
+	}
+
+	result.Data = sessions
+}
+
+// RemoveSessionAction revokes a login session of the current user, the device of the session has to login again.
+func RemoveSessionAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.UserSession.RemoveSession(id, session.UID); nil != err {
+		result.Code = util.CodeErr
+		if service.ErrSessionNotFound == err {
+			result.ErrCode = util.ErrCodeNotFound
+		}
+		result.Msg = err.Error()
+	}
+}
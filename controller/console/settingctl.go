@@ -23,8 +23,8 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -33,7 +33,7 @@ import (
 
 // GetBasicSettingsAction gets basic settings.
 func GetBasicSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -43,7 +43,7 @@ func GetBasicSettingsAction(c *gin.Context) {
 		if model.SettingNameBasicCommentable == setting.Name {
 			v, err := strconv.ParseBool(setting.Value)
 			if nil != err {
-				logger.Errorf("value of basic setting [name=%s] must be \"true\" or \"false\"", setting.Name)
+				util.Log(c).Errorf("value of basic setting [name=%s] must be \"true\" or \"false\"", setting.Name)
 				data[setting.Name] = true
 			} else {
 				data[setting.Name] = v
@@ -58,7 +58,7 @@ func GetBasicSettingsAction(c *gin.Context) {
 
 // UpdateBasicSettingsAction updates basic settings.
 func UpdateBasicSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -113,22 +113,26 @@ func UpdateBasicSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryBasic, basics, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryBasic)
 }
 
 // GetPreferenceSettingsAction gets preference settings.
 func GetPreferenceSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
 	settings := service.Setting.GetCategorySettings(model.SettingCategoryPreference, session.BID)
 	data := map[string]interface{}{}
 	for _, setting := range settings {
-		if model.SettingNamePreferenceArticleListStyle != setting.Name {
+		if !isEnumPreference(setting.Name) {
 			v, err := strconv.ParseInt(setting.Value, 10, 64)
 			if nil != err {
-				logger.Errorf("value of preference setting [name=%s] must be an integer", setting.Name)
+				util.Log(c).Errorf("value of preference setting [name=%s] must be an integer", setting.Name)
 				data[setting.Name] = 10
 			} else {
 				data[setting.Name] = v
@@ -138,12 +142,51 @@ func GetPreferenceSettingsAction(c *gin.Context) {
 		}
 	}
 
+	if _, ok := data[model.SettingNamePreferenceArticleListOrder]; !ok {
+		data[model.SettingNamePreferenceArticleListOrder] = strconv.Itoa(model.SettingPreferenceArticleListOrderDefault)
+	}
+	if _, ok := data[model.SettingNamePreferencePermalink]; !ok {
+		data[model.SettingNamePreferencePermalink] = strconv.Itoa(model.SettingPreferencePermalinkDefault)
+	}
+	if _, ok := data[model.SettingNamePreferenceArchiveGranularity]; !ok {
+		data[model.SettingNamePreferenceArchiveGranularity] = strconv.Itoa(model.SettingPreferenceArchiveGranularityDefault)
+	}
+	if _, ok := data[model.SettingNamePreferenceRelatedArticleListSize]; !ok {
+		data[model.SettingNamePreferenceRelatedArticleListSize] = model.SettingPreferenceRelatedArticleListSizeDefault
+	}
+	if _, ok := data[model.SettingNamePreferenceCodeHighlightStyle]; !ok {
+		data[model.SettingNamePreferenceCodeHighlightStyle] = model.SettingPreferenceCodeHighlightStyleDefault
+	}
+	if _, ok := data[model.SettingNamePreferenceEmbedProviders]; !ok {
+		data[model.SettingNamePreferenceEmbedProviders] = model.SettingPreferenceEmbedProvidersDefault
+	}
+	for name, value := range model.SettingPreferenceSwitchDefaults {
+		if _, ok := data[name]; !ok {
+			data[name] = strconv.Itoa(value)
+		}
+	}
+	data["codeHighlightStyles"] = util.HighlightStyles()
+	data["embedProviders"] = util.EmbedProviders()
+
 	result.Data = data
 }
 
+// isEnumPreference checks whether the preference setting of the specified name holds an option rather than a size.
+func isEnumPreference(name string) bool {
+	switch name {
+	case model.SettingNamePreferenceArticleListStyle, model.SettingNamePreferenceArticleListOrder,
+		model.SettingNamePreferencePermalink, model.SettingNamePreferenceArchiveGranularity,
+		model.SettingNamePreferenceCodeHighlightStyle, model.SettingNamePreferenceEmbedProviders:
+		return true
+	}
+	_, ok := model.SettingPreferenceSwitchDefaults[name]
+
+	return ok
+}
+
 // UpdatePreferenceSettingsAction updates preference settings.
 func UpdatePreferenceSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -155,6 +198,10 @@ func UpdatePreferenceSettingsAction(c *gin.Context) {
 	}
 
 	session := util.GetSession(c)
+	oldPermalink := strconv.Itoa(model.SettingPreferencePermalinkDefault)
+	if permalinkSetting := service.Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferencePermalink, session.BID); nil != permalinkSetting {
+		oldPermalink = permalinkSetting.Value
+	}
 	var prefs []*model.Setting
 	for k, v := range args {
 		var value interface{}
@@ -164,6 +211,13 @@ func UpdatePreferenceSettingsAction(c *gin.Context) {
 		default:
 			value = v.(string)
 		}
+		if !isValidPreference(k, value.(string)) {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid value of preference [" + k + "]"
+
+			return
+		}
 
 		pref := &model.Setting{
 			Category: model.SettingCategoryPreference,
@@ -177,12 +231,64 @@ func UpdatePreferenceSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryPreference, prefs, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryPreference)
+
+	permalink, ok := args[model.SettingNamePreferencePermalink].(string)
+	if !ok || oldPermalink == permalink {
+		return
+	}
+	count, err := service.Article.ApplyPermalink(session.BID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+	util.Log(c).Infof("moved [%d] articles of blog [%d] to the new permalink", count, session.BID)
+}
+
+// isValidPreference checks whether the specified value is a valid option of the preference setting of the specified
+// name.
+func isValidPreference(name, value string) bool {
+	if model.SettingNamePreferenceCodeHighlightStyle == name {
+		return "" == value || util.IsHighlightStyle(value)
+	}
+	if model.SettingNamePreferenceEmbedProviders == name {
+		for _, provider := range strings.Split(value, ",") {
+			if "" != provider && !util.IsEmbedProvider(provider) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	v, err := strconv.Atoi(value)
+	if nil != err {
+		return !isEnumPreference(name)
+	}
+
+	if _, ok := model.SettingPreferenceSwitchDefaults[name]; ok {
+		return model.SettingPreferenceSwitchValueOff == v || model.SettingPreferenceSwitchValueOn == v
+	}
+
+	switch name {
+	case model.SettingNamePreferencePermalink:
+		return model.SettingPreferencePermalinkValueDate <= v && model.SettingPreferencePermalinkValueSlug >= v
+	case model.SettingNamePreferenceArchiveGranularity:
+		return model.SettingPreferenceArchiveGranularityValueMonth <= v && model.SettingPreferenceArchiveGranularityValueYear >= v
 	}
+
+	return true
 }
 
 // GetSignSettingsAction gets sign settings.
 func GetSignSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -192,7 +298,7 @@ func GetSignSettingsAction(c *gin.Context) {
 
 // UpdateSignSettingsAction updates sign settings.
 func UpdateSignSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -216,12 +322,62 @@ func UpdateSignSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategorySign, signs, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategorySign)
+}
+
+// GetAuthorSignSettingsAction gets sign of the current author.
+func GetAuthorSignSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	result.Data = ""
+	name := model.SettingNameAuthorSign + strconv.FormatUint(session.UID, 10)
+	if signSetting := service.Setting.GetSetting(model.SettingCategorySign, name, session.BID); nil != signSetting {
+		result.Data = signSetting.Value
+	}
+}
+
+// UpdateAuthorSignSettingsAction updates sign of the current author.
+func UpdateAuthorSignSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update author sign settings request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	var signs []*model.Setting
+	sign := &model.Setting{
+		Category: model.SettingCategorySign,
+		BlogID:   session.BID,
+		Name:     model.SettingNameAuthorSign + strconv.FormatUint(session.UID, 10),
+		Value:    args["sign"].(string),
+	}
+	signs = append(signs, sign)
+
+	if err := service.Setting.UpdateSettings(model.SettingCategorySign, signs, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategorySign)
 }
 
 // GetI18nSettingsAction gets i18n settings.
 func GetI18nSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -235,7 +391,7 @@ func GetI18nSettingsAction(c *gin.Context) {
 
 // UpdateI18nSettingsAction updates i18n settings.
 func UpdateI18nSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -261,12 +417,16 @@ func UpdateI18nSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryI18n, i18ns, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryI18n)
 }
 
 // GetFeedSettingsAction gets feed settings.
 func GetFeedSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -276,7 +436,7 @@ func GetFeedSettingsAction(c *gin.Context) {
 		if model.SettingNameFeedOutputMode == setting.Name {
 			v, err := strconv.ParseInt(setting.Value, 10, 64)
 			if nil != err {
-				logger.Errorf("value of feed setting [name=%s] must be an integer", setting.Name)
+				util.Log(c).Errorf("value of feed setting [name=%s] must be an integer", setting.Name)
 				data[setting.Name] = 20
 			} else {
 				data[setting.Name] = v
@@ -290,7 +450,7 @@ func GetFeedSettingsAction(c *gin.Context) {
 
 // UpdateFeedSettingsAction updates feed settings.
 func UpdateFeedSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -324,12 +484,16 @@ func UpdateFeedSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryFeed, feeds, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryFeed)
 }
 
 // GetThirdStatisticSettingsAction gets third statistic settings.
 func GetThirdStatisticSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -342,7 +506,7 @@ func GetThirdStatisticSettingsAction(c *gin.Context) {
 
 // UpdateThirdStatisticSettingsAction updates third statistic settings.
 func UpdateThirdStatisticSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -366,12 +530,74 @@ func UpdateThirdStatisticSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryThirdStatistic, thridStatistics, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryThirdStatistic)
+}
+
+// appearanceSettingNames are names of the appearance settings.
+var appearanceSettingNames = []string{
+	model.SettingNameAppearanceHeadCSS,
+	model.SettingNameAppearanceHeadJS,
+	model.SettingNameAppearanceFooterJS,
+}
+
+// GetAppearanceSettingsAction gets appearance settings.
+func GetAppearanceSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	data := map[string]string{}
+	for _, name := range appearanceSettingNames {
+		data[name] = ""
+	}
+	for _, setting := range service.Setting.GetCategorySettings(model.SettingCategoryAppearance, session.BID) {
+		data[setting.Name] = setting.Value
+	}
+	result.Data = data
+}
+
+// UpdateAppearanceSettingsAction updates appearance settings.
+func UpdateAppearanceSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update appearance settings request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	var appearances []*model.Setting
+	for _, name := range appearanceSettingNames {
+		value, _ := args[name].(string)
+		appearances = append(appearances, &model.Setting{
+			Category: model.SettingCategoryAppearance,
+			BlogID:   session.BID,
+			Name:     name,
+			Value:    strings.TrimSpace(value),
+		})
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategoryAppearance, appearances, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryAppearance)
 }
 
 // GetAdSettingsAction get advertisement settings.
 func GetAdSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -384,7 +610,7 @@ func GetAdSettingsAction(c *gin.Context) {
 
 // UpdateAdSettingsAction update third statistic settings.
 func UpdateAdSettingsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	args := map[string]interface{}{}
@@ -417,5 +643,515 @@ func UpdateAdSettingsAction(c *gin.Context) {
 	if err := service.Setting.UpdateSettings(model.SettingCategoryAd, ads, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryAd)
+}
+
+// GetRepostSettingsAction gets repost settings.
+func GetRepostSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	data := map[string]interface{}{
+		model.SettingNameRepostEnabled:          false,
+		model.SettingNameRepostInterval:         model.SettingRepostIntervalDefault,
+		model.SettingNameRepostMinAge:           model.SettingRepostMinAgeDefault,
+		model.SettingNameRepostTelegramBotToken: "",
+		model.SettingNameRepostTelegramChatID:   "",
+		model.SettingNameRepostTwitterWebhook:   "",
+	}
+	settings := service.Setting.GetCategorySettings(model.SettingCategoryRepost, session.BID)
+	for _, setting := range settings {
+		switch setting.Name {
+		case model.SettingNameRepostEnabled:
+			data[setting.Name] = "true" == setting.Value
+		case model.SettingNameRepostInterval, model.SettingNameRepostMinAge:
+			if v, err := strconv.Atoi(setting.Value); nil == err {
+				data[setting.Name] = v
+			}
+		default:
+			data[setting.Name] = setting.Value
+		}
 	}
+	result.Data = data
+}
+
+// UpdateRepostSettingsAction updates repost settings.
+func UpdateRepostSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update repost settings request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	var reposts []*model.Setting
+	for _, name := range []string{model.SettingNameRepostEnabled, model.SettingNameRepostInterval, model.SettingNameRepostMinAge,
+		model.SettingNameRepostTelegramBotToken, model.SettingNameRepostTelegramChatID, model.SettingNameRepostTwitterWebhook} {
+		v, ok := args[name]
+		if !ok {
+			continue
+		}
+
+		var value string
+		switch v.(type) {
+		case bool:
+			value = strconv.FormatBool(v.(bool))
+		case float64:
+			value = strconv.FormatFloat(v.(float64), 'f', 0, 64)
+		case string:
+			value = strings.TrimSpace(v.(string))
+		}
+
+		repost := &model.Setting{
+			Category: model.SettingCategoryRepost,
+			BlogID:   session.BID,
+			Name:     name,
+			Value:    value,
+		}
+		reposts = append(reposts, repost)
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategoryRepost, reposts, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryRepost)
+}
+
+// GetSpamSettingsAction gets spam settings.
+func GetSpamSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	data := map[string]string{
+		model.SettingNameSpamAkismetKey:     "",
+		model.SettingNameSpamCaptcha:        model.SettingSpamCaptchaValueNone,
+		model.SettingNameSpamCaptchaSiteKey: "",
+		model.SettingNameSpamCaptchaSecret:  "",
+		model.SettingNameSpamTrustedCount:   "0",
+	}
+	for name := range data {
+		if setting := service.Setting.GetSetting(model.SettingCategorySpam, name, session.BID); nil != setting {
+			data[name] = setting.Value
+		}
+	}
+	result.Data = data
+}
+
+// UpdateSpamSettingsAction updates spam settings.
+func UpdateSpamSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update spam settings request failed"
+
+		return
+	}
+
+	akismetKey, _ := args[model.SettingNameSpamAkismetKey].(string)
+	captcha, _ := args[model.SettingNameSpamCaptcha].(string)
+	captchaSiteKey, _ := args[model.SettingNameSpamCaptchaSiteKey].(string)
+	captchaSecret, _ := args[model.SettingNameSpamCaptchaSecret].(string)
+	trustedCount, _ := args[model.SettingNameSpamTrustedCount].(float64)
+	if 0 > trustedCount {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "trusted comment count must not be negative"
+
+		return
+	}
+	switch captcha {
+	case model.SettingSpamCaptchaValueNone, model.SettingSpamCaptchaValueProofOfWork:
+	case model.SettingSpamCaptchaValueHCaptcha, model.SettingSpamCaptchaValueReCaptcha:
+		if "" == strings.TrimSpace(captchaSiteKey) || "" == strings.TrimSpace(captchaSecret) {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "site key and secret of captcha [" + captcha + "] are required"
+
+			return
+		}
+	default:
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "unknown captcha [" + captcha + "]"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	spams := []*model.Setting{
+		{
+			Category: model.SettingCategorySpam,
+			BlogID:   session.BID,
+			Name:     model.SettingNameSpamAkismetKey,
+			Value:    strings.TrimSpace(akismetKey),
+		},
+		{
+			Category: model.SettingCategorySpam,
+			BlogID:   session.BID,
+			Name:     model.SettingNameSpamCaptcha,
+			Value:    captcha,
+		},
+		{
+			Category: model.SettingCategorySpam,
+			BlogID:   session.BID,
+			Name:     model.SettingNameSpamCaptchaSiteKey,
+			Value:    strings.TrimSpace(captchaSiteKey),
+		},
+		{
+			Category: model.SettingCategorySpam,
+			BlogID:   session.BID,
+			Name:     model.SettingNameSpamCaptchaSecret,
+			Value:    strings.TrimSpace(captchaSecret),
+		},
+		{
+			Category: model.SettingCategorySpam,
+			BlogID:   session.BID,
+			Name:     model.SettingNameSpamTrustedCount,
+			Value:    strconv.Itoa(int(trustedCount)),
+		},
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategorySpam, spams, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategorySpam)
+}
+
+// GetNewsletterSettingsAction gets newsletter settings.
+func GetNewsletterSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	result.Data = map[string]interface{}{
+		model.SettingNameNewsletterMode: service.Newsletter.GetMode(session.BID),
+		"mailEnabled":                   service.Mail.Enabled(),
+		"subscriberCount":               service.Newsletter.GetSubscriberCount(session.BID),
+	}
+}
+
+// UpdateNewsletterSettingsAction updates newsletter settings.
+func UpdateNewsletterSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update newsletter settings request failed"
+
+		return
+	}
+
+	mode, _ := args[model.SettingNameNewsletterMode].(string)
+	switch mode {
+	case model.SettingNewsletterModeOff, model.SettingNewsletterModeNew, model.SettingNewsletterModeWeekly:
+	default:
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "invalid newsletter mode [" + mode + "]"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	newsletters := []*model.Setting{
+		{
+			Category: model.SettingCategoryNewsletter,
+			BlogID:   session.BID,
+			Name:     model.SettingNameNewsletterMode,
+			Value:    mode,
+		},
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategoryNewsletter, newsletters, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryNewsletter)
+}
+
+// GetUploadSettingsAction gets upload settings.
+func GetUploadSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	result.Data = map[string]interface{}{
+		model.SettingNameUploadMaxSize:   service.Upload.GetMaxSize(session.BID) / 1024,
+		model.SettingNameUploadMIMETypes: strings.Join(service.Upload.GetMIMETypes(session.BID), ","),
+		"scannerEnabled":                 "" != model.Conf.UploadScanner,
+	}
+}
+
+// UpdateUploadSettingsAction updates upload settings.
+func UpdateUploadSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update upload settings request failed"
+
+		return
+	}
+
+	var maxSize int
+	switch v := args[model.SettingNameUploadMaxSize].(type) {
+	case float64:
+		maxSize = int(v)
+	case string:
+		maxSize, _ = strconv.Atoi(strings.TrimSpace(v))
+	}
+	if 1 > maxSize {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "max upload size should be a positive integer"
+
+		return
+	}
+	mimeTypes, _ := args[model.SettingNameUploadMIMETypes].(string)
+	var types []string
+	for _, mimeType := range strings.Split(mimeTypes, ",") {
+		if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); "" != mimeType {
+			types = append(types, mimeType)
+		}
+	}
+
+	session := util.GetSession(c)
+	uploads := []*model.Setting{
+		{
+			Category: model.SettingCategoryUpload,
+			BlogID:   session.BID,
+			Name:     model.SettingNameUploadMaxSize,
+			Value:    strconv.Itoa(maxSize),
+		},
+		{
+			Category: model.SettingCategoryUpload,
+			BlogID:   session.BID,
+			Name:     model.SettingNameUploadMIMETypes,
+			Value:    strings.Join(types, ","),
+		},
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategoryUpload, uploads, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryUpload)
+}
+
+// storageSettingNames are names of the platform storage settings.
+var storageSettingNames = []string{
+	model.SettingNameStorageType, model.SettingNameStorageEndpoint, model.SettingNameStorageBucket,
+	model.SettingNameStorageRegion, model.SettingNameStorageAccessKey, model.SettingNameStorageSecretKey,
+	model.SettingNameStorageURL,
+}
+
+// GetStorageSettingsAction gets platform media storage settings, only the platform admin could see them.
+func GetStorageSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see storage settings"
+
+		return
+	}
+
+	data := map[string]interface{}{}
+	for _, name := range storageSettingNames {
+		data[name] = ""
+	}
+	for _, setting := range service.Setting.GetCategorySettings(model.SettingCategoryStorage, service.PlatformBlogID) {
+		data[setting.Name] = setting.Value
+	}
+	data[model.SettingNameStorageSecretKey] = "" // never send the secret back, keeps it if not changed
+	data["mediaDir"] = model.Conf.MediaDir
+	data["mediaS3"] = "" != model.Conf.MediaS3
+	result.Data = data
+}
+
+// UpdateStorageSettingsAction updates platform media storage settings, only the platform admin could update them.
+func UpdateStorageSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can update storage settings"
+
+		return
+	}
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update storage settings request failed"
+
+		return
+	}
+
+	storageType, _ := args[model.SettingNameStorageType].(string)
+	switch storageType {
+	case model.SettingStorageTypeValueConf, model.SettingStorageTypeValueS3, model.SettingStorageTypeValueOSS:
+	default:
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "unsupported storage type [" + storageType + "]"
+
+		return
+	}
+	endpoint, _ := args[model.SettingNameStorageEndpoint].(string)
+	bucket, _ := args[model.SettingNameStorageBucket].(string)
+	if model.SettingStorageTypeValueConf != storageType &&
+		("" == strings.TrimSpace(endpoint) || "" == strings.TrimSpace(bucket)) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "endpoint and bucket are required"
+
+		return
+	}
+
+	var storages []*model.Setting
+	for _, name := range storageSettingNames {
+		value, _ := args[name].(string)
+		value = strings.TrimSpace(value)
+		if model.SettingNameStorageSecretKey == name && "" == value {
+			continue
+		}
+
+		storages = append(storages, &model.Setting{
+			Category: model.SettingCategoryStorage,
+			BlogID:   service.PlatformBlogID,
+			Name:     name,
+			Value:    value,
+		})
+	}
+
+	if err := service.Setting.UpdateSettings(model.SettingCategoryStorage, storages, service.PlatformBlogID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryStorage)
+}
+
+// GetAnnouncementSettingsAction gets the platform announcement, only the platform admin could see it.
+func GetAnnouncementSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can see the announcement"
+
+		return
+	}
+
+	content, start, end := service.Announcement.GetAnnouncement()
+	data := map[string]interface{}{}
+	data[model.SettingNameAnnouncementContent] = content
+	data[model.SettingNameAnnouncementStart] = ""
+	if !start.IsZero() {
+		data[model.SettingNameAnnouncementStart] = start.Format(time.RFC3339)
+	}
+	data[model.SettingNameAnnouncementEnd] = ""
+	if !end.IsZero() {
+		data[model.SettingNameAnnouncementEnd] = end.Format(time.RFC3339)
+	}
+	result.Data = data
+}
+
+// UpdateAnnouncementSettingsAction updates the platform announcement shown on all blogs and the console during its
+// time window, only the platform admin could update it.
+func UpdateAnnouncementSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can update the announcement"
+
+		return
+	}
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update announcement request failed"
+
+		return
+	}
+
+	content, _ := args[model.SettingNameAnnouncementContent].(string)
+	var window [2]time.Time
+	for i, name := range []string{model.SettingNameAnnouncementStart, model.SettingNameAnnouncementEnd} {
+		value, _ := args[name].(string)
+		if value = strings.TrimSpace(value); "" == value {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "invalid time [" + value + "]"
+
+			return
+		}
+		window[i] = t
+	}
+
+	if err := service.Announcement.UpdateAnnouncement(content, window[0], window[1]); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrAnnouncementWindow == err {
+			result.ErrCode = util.ErrCodeBadRequest
+		}
+
+		return
+	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryAnnouncement)
 }
@@ -20,8 +20,8 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -30,7 +30,7 @@ import (
 
 // GetTagsAction gets tags.
 func GetTagsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -50,7 +50,7 @@ func GetTagsAction(c *gin.Context) {
 
 // GetTagsAction gets tags with pagination.
 func GetTagsPageAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -60,9 +60,10 @@ func GetTagsPageAction(c *gin.Context) {
 	var tags []*ConsoleTag
 	for _, tagModel := range tagModels {
 		item := &ConsoleTag{
-			ID:    tagModel.ID,
-			Title: tagModel.Title,
-			URL:   blogURLSetting.Value + util.PathTags + "/" + tagModel.Title,
+			ID:           tagModel.ID,
+			Title:        tagModel.Title,
+			URL:          blogURLSetting.Value + util.PathTags + "/" + tagModel.Title,
+			ArticleCount: tagModel.ArticleCount,
 		}
 		tags = append(tags, item)
 	}
@@ -72,9 +73,9 @@ func GetTagsPageAction(c *gin.Context) {
 	result.Data = data
 }
 
-// RemoveTagsAction remove tags that have no articles.
+// RemoveTagsAction removes a tag, the tag is removed from its articles as well.
 func RemoveTagsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	idArg := c.Param("id")
@@ -94,3 +95,82 @@ func RemoveTagsAction(c *gin.Context) {
 	}
 
 }
+
+// UpdateTagAction renames a tag.
+func UpdateTagAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	arg := &struct {
+		Title string `json:"title"`
+	}{}
+	if err := c.BindJSON(arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses update tag request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	if err := service.Tag.RenameTag(id, arg.Title, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		switch err {
+		case service.ErrTagExists:
+			result.ErrCode = util.ErrCodeConflict
+		case service.ErrInvalidTag:
+			result.ErrCode = util.ErrCodeBadRequest
+		}
+	}
+}
+
+// MergeTagAction moves articles of a tag to the target tag specified by its title, then removes the tag.
+func MergeTagAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	arg := &struct {
+		Target string `json:"target"`
+	}{}
+	if err := c.BindJSON(arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses merge tag request failed"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	target := service.Tag.GetTagByTitle(strings.TrimSpace(arg.Target), session.BID)
+	if nil == target {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found the target tag"
+
+		return
+	}
+	if err := service.Tag.MergeTag(id, target.ID, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrInvalidTag == err {
+			result.ErrCode = util.ErrCodeBadRequest
+		}
+	}
+}
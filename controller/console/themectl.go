@@ -17,9 +17,13 @@
 package console
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/theme"
@@ -29,29 +33,183 @@ import (
 
 // UpdateThemeAction updates theme.
 func UpdateThemeAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
-	theme := c.Param("id")
+	themeName := c.Param("id")
+	if !theme.IsCompatible(themeName) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeConflict
+		result.Msg = "theme [" + themeName + "] is not compatible with the template API of this Pipe"
+
+		return
+	}
 	session := util.GetSession(c)
 
 	settings := []*model.Setting{
 		{
 			Category: model.SettingCategoryTheme,
 			Name:     model.SettingNameThemeName,
-			Value:    theme,
+			Value:    themeName,
 			BlogID:   session.BID,
 		},
 	}
 	if err := service.Setting.UpdateSettings(model.SettingCategoryTheme, settings, session.BID); nil != err {
 		result.Code = util.CodeErr
 		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionThemeSwitch, themeName)
+}
+
+// maxThemeZipSize is the max size of uploaded theme zips.
+const maxThemeZipSize = 32 * 1024 * 1024
+
+// InstallThemeAction installs a theme from an uploaded zip (multipart field "file") or from a zip or Git URL (JSON
+// {"url": "..."}), only the platform admin could install themes as they are shared by all blogs.
+func InstallThemeAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if !service.User.IsPlatformAdmin(session.UID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "only platform admin can install themes"
+
+		return
+	}
+
+	var themeName string
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		data, readErr := readThemeZip(c)
+		if nil != readErr {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = readErr.Error()
+
+			return
+		}
+
+		themeName, err = theme.InstallZip("theme/x", data)
+	} else {
+		arg := map[string]interface{}{}
+		if err := c.BindJSON(&arg); nil != err {
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeBadRequest
+			result.Msg = "parses install theme request failed"
+
+			return
+		}
+		url, _ := arg["url"].(string)
+		themeName, err = theme.InstallURL("theme/x", strings.TrimSpace(url))
+	}
+	if nil != err {
+		util.Log(c).Errorf("install theme failed: %s", err)
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "install theme failed: " + err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionThemeInstall, themeName)
+	result.Data = themeName
+}
+
+// readThemeZip reads the theme zip uploaded with the specified request.
+func readThemeZip(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxThemeZipSize+64*1024)
+	file, err := c.FormFile("file")
+	if nil != err {
+		return nil, errors.New("parse upload file header failed")
+	}
+	f, err := file.Open()
+	if nil != err {
+		return nil, errors.New("open upload file failed")
+	}
+	defer f.Close()
+
+	return ioutil.ReadAll(f)
+}
+
+// GetThemeSettingsAction gets options declared by the specified theme and their values of the current blog.
+func GetThemeSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	themeName := c.Param("id")
+	if nil == theme.Manifests[themeName] {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found theme [" + themeName + "]"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	options := theme.GetOptions(themeName)
+	if nil == options {
+		options = []*theme.Option{}
+	}
+	result.Data = map[string]interface{}{
+		"options": options,
+		"values":  service.Setting.GetThemeOptions(themeName, session.BID),
+	}
+}
+
+// UpdateThemeSettingsAction updates values of options of the specified theme of the current blog.
+func UpdateThemeSettingsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	themeName := c.Param("id")
+	if nil == theme.Manifests[themeName] {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found theme [" + themeName + "]"
+
+		return
+	}
+
+	args := map[string]interface{}{}
+	if err := c.BindJSON(&args); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses update theme settings request failed"
+
+		return
+	}
+	values := map[string]string{}
+	for name, arg := range args {
+		switch v := arg.(type) {
+		case float64:
+			values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+
+	session := util.GetSession(c)
+	if err := service.Setting.UpdateThemeOptions(themeName, values, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
 	}
+
+	audit(c, model.AuditActionSettingsUpdate, model.SettingCategoryThemeOption)
 }
 
-// GetThemesAction gets themes.
+// GetThemesAction gets themes, warns if the current theme falls back to the default theme for incompatibility.
 func GetThemesAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
@@ -59,7 +217,7 @@ func GetThemesAction(c *gin.Context) {
 	currentID := theme.Themes[0]
 	themeNameSetting := service.Setting.GetSetting(model.SettingCategoryTheme, model.SettingNameThemeName, session.BID)
 	if nil == themeNameSetting {
-		logger.Errorf("not found theme name setting")
+		util.Log(c).Errorf("not found theme name setting")
 	} else {
 		currentID = themeNameSetting.Value
 	}
@@ -69,13 +227,29 @@ func GetThemesAction(c *gin.Context) {
 		consoleTheme := &ConsoleTheme{
 			Name:         themeName,
 			ThumbnailURL: model.Conf.Server + "/theme/x/" + themeName + "/thumbnail.jpg",
+			Compatible:   theme.IsCompatible(themeName),
+		}
+		if manifest := theme.Manifests[themeName]; nil != manifest {
+			consoleTheme.Version = manifest.Version
+			consoleTheme.TemplateAPI = manifest.TemplateAPI
 		}
 
 		themes = append(themes, consoleTheme)
 	}
 
 	result.Data = map[string]interface{}{
-		"currentId": currentID,
-		"themes":    themes,
+		"currentId":   currentID,
+		"themes":      themes,
+		"templateAPI": theme.TemplateAPIVersion,
+		"fallback":    theme.Resolve(currentID) != currentID, // the current theme is rendered with the default theme
+		"fallbackId":  theme.Resolve(currentID),
 	}
 }
+
+// GetTemplateErrorsAction gets recent theme template render errors.
+func GetTemplateErrorsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	result.Data = theme.GetRenderErrors()
+}
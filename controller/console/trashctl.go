@@ -0,0 +1,144 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrashAction gets articles in the trash.
+func GetTrashAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	articleModels, pagination := service.Article.ConsoleGetTrashedArticles(util.GetPage(c), session.BID)
+	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, session.BID)
+
+	var articles []*ConsoleTrashedArticle
+	for _, articleModel := range articleModels {
+		article := &ConsoleTrashedArticle{
+			ID:           articleModel.ID,
+			CreatedAt:    articleModel.CreatedAt.Format("2006-01-02"),
+			TrashedAt:    articleModel.DeletedAt.Format("2006-01-02 15:04"),
+			Title:        articleModel.Title,
+			Path:         articleModel.Path,
+			Status:       articleModel.Status,
+			CommentCount: articleModel.CommentCount,
+		}
+		if authorModel := service.User.GetUser(articleModel.AuthorID); nil != authorModel {
+			article.Author = &ConsoleAuthor{
+				Name:      authorModel.Name,
+				URL:       blogURLSetting.Value + util.PathAuthors + "/" + authorModel.Name,
+				AvatarURL: authorModel.AvatarURL,
+			}
+		}
+
+		articles = append(articles, article)
+	}
+
+	data := map[string]interface{}{}
+	data["articles"] = articles
+	data["pagination"] = pagination
+	result.Data = data
+}
+
+// RestoreArticleAction restores an article from the trash.
+func RestoreArticleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	article := getTrashedArticle(c, result, session)
+	if nil == article {
+		return
+	}
+
+	if err := service.Article.RestoreArticle(article.ID, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		if service.ErrReservedPath == err {
+			result.ErrCode = util.ErrCodeConflict
+		}
+
+		return
+	}
+
+	audit(c, model.AuditActionArticleRestore, article.Title)
+	// the article was deleted from followers when trashed
+	if restored := service.Article.ConsoleGetArticle(article.ID); nil != restored && isFederated(restored) {
+		go service.ActivityPub.PublishArticle("Create", restored)
+	}
+}
+
+// PurgeArticleAction removes an article from the trash permanently.
+func PurgeArticleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	article := getTrashedArticle(c, result, session)
+	if nil == article {
+		return
+	}
+
+	if err := service.Article.PurgeArticle(article.ID, session.BID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	audit(c, model.AuditActionArticlePurge, article.Title)
+}
+
+// getTrashedArticle gets the trashed article specified by the path parameter "id", returns nil and sets the result if
+// not found or not allowed.
+func getTrashedArticle(c *gin.Context, result *util.Result, session *util.SessionData) *model.Article {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return nil
+	}
+
+	ret := service.Article.ConsoleGetTrashedArticle(id, session.BID)
+	if nil == ret {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = service.ErrArticleNotInTrash.Error()
+
+		return nil
+	}
+	if !canEditArticle(session, ret) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "authors can only restore their own drafts"
+
+		return nil
+	}
+
+	return ret
+}
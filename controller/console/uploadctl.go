@@ -0,0 +1,247 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// maxPasteImageDimension is the max width or height of images pasted or dropped into the editor.
+const maxPasteImageDimension = 8192
+
+// PasteUploadAction uploads a file pasted or dropped into the editor. The request body is the raw file content and
+// the optional query parameter "name" is the original file name. JPEG and PNG images are re-encoded to strip
+// metadata, the response contains the URL and a Markdown snippet to insert into the editor.
+func PasteUploadAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	if !service.Media.Enabled() {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "media storage is not configured"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	maxSize := service.Upload.GetMaxSize(session.BID)
+	data, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSize))
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "file size exceeds " + strconv.FormatInt(maxSize/1024, 10) + "KB"
+
+		return
+	}
+	if 1 > len(data) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "empty file"
+
+		return
+	}
+
+	name := c.Query("name")
+	item, err := storeUpload(c, session, name, data)
+	if nil != err {
+		result.Code = util.CodeErr
+		if service.ErrUploadStore != err {
+			result.ErrCode = util.ErrCodeBadRequest
+		}
+		result.Msg = err.Error()
+
+		return
+	}
+	result.Data = item
+}
+
+// UploadAction uploads files selected in the editor. The request is a multipart form with files in field "file[]",
+// the response is in format of the Vditor editor upload and also contains a Markdown snippet of all stored files.
+func UploadAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	if !service.Media.Enabled() {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "media storage is not configured"
+
+		return
+	}
+
+	session := util.GetSession(c)
+	maxSize := service.Upload.GetMaxSize(session.BID)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadFiles*(maxSize+64*1024))
+	form, err := c.MultipartForm()
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses upload request failed"
+
+		return
+	}
+	files := form.File["file[]"]
+	if 1 > len(files) {
+		files = form.File["file"]
+	}
+	if 1 > len(files) || maxUploadFiles < len(files) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "please attach 1 to " + strconv.Itoa(maxUploadFiles) + " files"
+
+		return
+	}
+
+	errFiles := []string{}
+	succMap := map[string]string{}
+	var markdowns []string
+	for _, file := range files {
+		if maxSize < file.Size {
+			util.Log(c).Warnf("rejected upload [%s] of user [%s]: %s", file.Filename, session.UName, service.ErrUploadTooLarge)
+			errFiles = append(errFiles, file.Filename)
+
+			continue
+		}
+		data, err := readUploadFile(file)
+		if nil != err {
+			util.Log(c).Errorf("read upload [%s] failed: %s", file.Filename, err)
+			errFiles = append(errFiles, file.Filename)
+
+			continue
+		}
+		item, err := storeUpload(c, session, file.Filename, data)
+		if nil != err {
+			errFiles = append(errFiles, file.Filename)
+
+			continue
+		}
+		succMap[file.Filename] = item["url"].(string)
+		markdowns = append(markdowns, item["markdown"].(string))
+	}
+
+	result.Data = map[string]interface{}{
+		"errFiles": errFiles,
+		"succMap":  succMap,
+		"markdown": strings.Join(markdowns, "\n"),
+	}
+}
+
+// maxUploadFiles is the max count of files uploaded in one request.
+const maxUploadFiles = 10
+
+func readUploadFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ioutil.ReadAll(f)
+}
+
+// storeUpload checks and stores the specified uploaded file of the specified original name, returns the name, URL and
+// a Markdown snippet of the stored file. JPEG and PNG images are re-encoded to strip metadata, files are named after
+// the hash of their content so that uploading the same file twice stores it only once.
+func storeUpload(c *gin.Context, session *util.SessionData, name string, data []byte) (map[string]interface{}, error) {
+	data, mimeType, ext, err := checkUpload(data, session.BID)
+	if nil != err {
+		util.Log(c).Warnf("rejected upload [%s] of user [%s]: %s", name, session.UName, err)
+
+		return nil, err
+	}
+
+	name = name[strings.LastIndexAny(name, "/\\")+1:]
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = strings.TrimSpace(strings.NewReplacer("[", "", "]", "", "(", "", ")", "").Replace(name))
+	if "" == name {
+		name = strings.Split(mimeType, "/")[0]
+	}
+
+	hash := sha256.Sum256(data)
+	hashStr := hex.EncodeToString(hash[:16])
+	key := "articles/" + time.Now().Format("200601") + "/" + hashStr + ext
+	url, err := service.Media.Put(key, data)
+	if nil != err {
+		util.Log(c).Errorf("store upload [%s] failed: %s", key, err)
+
+		return nil, service.ErrUploadStore
+	}
+	util.Log(c).Infof("user [%s] uploaded file [%s]", session.UName, url)
+	mediaFile := &model.MediaFile{
+		Name:       name,
+		Key:        key,
+		URL:        url,
+		MIMEType:   mimeType,
+		Size:       int64(len(data)),
+		Hash:       hashStr,
+		UploaderID: session.UID,
+		BlogID:     session.BID,
+	}
+	if err := service.MediaFile.AddMediaFile(mediaFile); nil != err {
+		util.Log(c).Errorf("add media file [%s] failed: %s", key, err)
+	}
+	if "image/jpeg" == mimeType || "image/png" == mimeType || "image/gif" == mimeType {
+		if err := service.Image.AddImage(hashStr, key, url, mimeType, data, session.BID); nil != err {
+			util.Log(c).Errorf("add image [%s] failed: %s", key, err)
+		}
+	}
+
+	markdown := "[" + name + "](" + url + ")"
+	if strings.HasPrefix(mimeType, "image/") {
+		markdown = "!" + markdown
+	}
+
+	return map[string]interface{}{
+		"name":     name,
+		"url":      url,
+		"markdown": markdown,
+	}, nil
+}
+
+// checkUpload checks the specified uploaded data against the upload settings of the specified blog, returns the data
+// to store, its MIME type and file extension. JPEG and PNG images are re-encoded to strip metadata.
+func checkUpload(data []byte, blogID uint64) (ret []byte, mimeType, ext string, err error) {
+	mimeType, err = service.Upload.Check(data, blogID)
+	if nil != err {
+		return
+	}
+
+	if exts, _ := mime.ExtensionsByType(mimeType); 0 < len(exts) {
+		ext = exts[0]
+	}
+	ret = data
+	if "image/jpeg" == mimeType || "image/png" == mimeType {
+		ret, ext, err = util.ReencodeImage(data, maxPasteImageDimension)
+	}
+
+	return
+}
@@ -18,8 +18,8 @@ package console
 
 import (
 	"net/http"
+	"strconv"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -28,7 +28,7 @@ import (
 
 // AddUserAction adds a user.
 func AddUserAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	arg := map[string]interface{}{}
@@ -55,11 +55,49 @@ func AddUserAction(c *gin.Context) {
 
 		return
 	}
+
+	audit(c, model.AuditActionUserAdd, user.Name)
+}
+
+// UpdateUserRoleAction changes the role of a user in the current blog.
+func UpdateUserRoleAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = "parses update user role request failed"
+
+		return
+	}
+	role, _ := arg["role"].(float64)
+
+	session := util.GetSession(c)
+	if err := service.User.UpdateRole(id, session.BID, int(role)); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+
+	if user := service.User.GetUser(id); nil != user {
+		audit(c, model.AuditActionUserRole, user.Name)
+	}
 }
 
 // GetUsersAction gets users.
 func GetUsersAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	session := util.GetSession(c)
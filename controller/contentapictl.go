@@ -0,0 +1,219 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// contentArticle represents an article of the public content API.
+type contentArticle struct {
+	ID           uint64    `json:"id"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	Abstract     string    `json:"abstract"`
+	Content      string    `json:"content,omitempty"`
+	Tags         []string  `json:"tags"`
+	Author       string    `json:"author"`
+	BlogTitle    string    `json:"blogTitle,omitempty"` // title of the blog of the article in timelines
+	CommentCount int       `json:"commentCount"`
+	ViewCount    int       `json:"viewCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// contentAPILimiter limits requests of the public content API per client IP softly, bursts are allowed.
+var contentAPILimiter = &rateLimiter{
+	capacity: 60,
+	rate:     1,
+	buckets:  map[string]*rateBucket{},
+	mutex:    &sync.Mutex{},
+}
+
+// resolveContentAPI resolves the blog of the public content API and serves cached responses.
+func resolveContentAPI(c *gin.Context) {
+	if ok, wait := contentAPILimiter.allow(util.GetRemoteAddr(c)); !ok {
+		abortRateLimited(c, wait)
+
+		return
+	}
+
+	user := service.User.GetUserByName(c.Param("username"))
+	if nil == user {
+		user = service.User.GetUserByAlias(c.Param("username"))
+	}
+	if nil == user {
+		abortContentAPINotFound(c)
+
+		return
+	}
+	userBlog := service.User.GetOwnBlog(user.ID)
+	if nil == userBlog {
+		abortContentAPINotFound(c)
+
+		return
+	}
+	c.Set("userBlog", userBlog)
+
+	if page := cache.Page.Get(userBlog.ID, contentAPICacheKey(c)); nil != page {
+		c.Header("X-Pipe-Cache", "HIT")
+		c.Data(http.StatusOK, page.ContentType, page.Body)
+		c.Abort()
+
+		return
+	}
+
+	c.Next()
+}
+
+// contentAPICacheKey returns the cache key of the current content API request, which is the page cache key of it so
+// that unaccepted query parameters don't fragment the cache.
+func contentAPICacheKey(c *gin.Context) string {
+	return "api:" + pageCacheKey(c)
+}
+
+func abortContentAPINotFound(c *gin.Context) {
+	result := util.NewResult(c)
+	result.Code = util.CodeErr
+	result.ErrCode = util.ErrCodeNotFound
+	result.Msg = "not found blog"
+	c.AbortWithStatusJSON(http.StatusNotFound, result)
+}
+
+// writeContentAPI writes the specified data and caches it until the blog content changes.
+func writeContentAPI(c *gin.Context, data interface{}) {
+	result := util.NewResult(c)
+	result.Data = data
+	body, err := json.Marshal(result)
+	if nil != err {
+		util.Log(c).Errorf("marshal content API result failed: %s", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	cache.Page.Put(getBlogID(c), contentAPICacheKey(c), &cache.CachedPage{ContentType: contentType, Body: body})
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func contentBlogURL(blogID uint64) string {
+	return service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID).Value
+}
+
+func newContentArticle(article *model.Article, blogURL string) *contentArticle {
+	ret := &contentArticle{
+		ID:           article.ID,
+		Title:        article.Title,
+		URL:          blogURL + article.Path,
+		Abstract:     article.Abstract,
+		CommentCount: article.CommentCount,
+		ViewCount:    article.ViewCount,
+		CreatedAt:    article.CreatedAt,
+		UpdatedAt:    article.UpdatedAt,
+		Tags:         []string{},
+	}
+	for _, tag := range strings.Split(article.Tags, ",") {
+		if tag = strings.TrimSpace(tag); "" != tag {
+			ret.Tags = append(ret.Tags, tag)
+		}
+	}
+	if author := service.User.GetUser(article.AuthorID); nil != author {
+		ret.Author = author.Name
+	}
+
+	return ret
+}
+
+func getContentArticlesAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	blogURL := contentBlogURL(blogID)
+	articleModels, pagination := service.Article.GetArticles("", util.GetPage(c), blogID)
+	articles := []*contentArticle{}
+	for _, articleModel := range articleModels {
+		articles = append(articles, newContentArticle(articleModel, blogURL))
+	}
+
+	writeContentAPI(c, map[string]interface{}{
+		"articles":   articles,
+		"pagination": pagination,
+	})
+}
+
+func getContentArticleAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if nil != err {
+		abortContentAPINotFound(c)
+
+		return
+	}
+	articleModel := service.Article.ConsoleGetArticle(id)
+	if nil == articleModel || blogID != articleModel.BlogID || model.ArticleStatusOK != articleModel.Status ||
+		model.ArticleVisibilityPassword == articleModel.Visibility {
+		abortContentAPINotFound(c)
+
+		return
+	}
+
+	article := newContentArticle(articleModel, contentBlogURL(blogID))
+	article.Content = service.Glossary.LinkGlossaries(highlightCode(markdown(articleModel.Content, blogID).ContentHTML, blogID), blogID)
+	writeContentAPI(c, article)
+}
+
+func getContentTagsAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	blogURL := contentBlogURL(blogID)
+	tags := []map[string]interface{}{}
+	for _, tag := range service.Tag.GetTags(math.MaxInt64, blogID) {
+		tags = append(tags, map[string]interface{}{
+			"title":        tag.Title,
+			"url":          blogURL + util.PathTags + "/" + tag.Title,
+			"articleCount": tag.ArticleCount,
+		})
+	}
+
+	writeContentAPI(c, tags)
+}
+
+func getContentArchivesAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	blogURL := contentBlogURL(blogID)
+	archives := []map[string]interface{}{}
+	for _, archive := range service.Archive.GetGranularArchives(blogID) {
+		archives = append(archives, map[string]interface{}{
+			"year":         archive.Year,
+			"month":        archive.Month,
+			"url":          blogURL + archivePath(archive),
+			"articleCount": archive.ArticleCount,
+		})
+	}
+
+	writeContentAPI(c, archives)
+}
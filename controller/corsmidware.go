@@ -0,0 +1,105 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// corsAllowHeaders are the request headers allowed in cross-origin requests.
+const corsAllowHeaders = "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID"
+
+// cors returns a middleware which applies the CORS policies of Conf.CORS to the API path groups. It's used globally
+// so that preflight requests of paths without OPTIONS routes are answered as well.
+func cors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if "" == origin {
+			c.Next()
+
+			return
+		}
+
+		policy := getCORSPolicy(c.Request.URL.Path)
+		if nil == policy || !corsOriginAllowed(policy, origin) {
+			c.Next()
+
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+		} else if corsOriginAllowed(policy, "*") {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		header.Set("Access-Control-Expose-Headers", "X-Request-ID")
+		if http.MethodOptions != c.Request.Method || "" == c.GetHeader("Access-Control-Request-Method") {
+			c.Next()
+
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowMethods, ", "))
+		header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+		if 0 < policy.MaxAge {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// getCORSPolicy returns the CORS policy of the longest path group matching the specified request path, returns nil
+// if no policy applies.
+func getCORSPolicy(path string) (ret *model.CORSPolicy) {
+	if !strings.HasPrefix(path, util.PathAPI+"/") {
+		return
+	}
+
+	path = strings.TrimPrefix(path, util.PathAPI)
+	for _, policy := range model.Conf.CORS {
+		if path != policy.Path && !strings.HasPrefix(path, policy.Path+"/") && "/" != policy.Path {
+			continue
+		}
+		if nil == ret || len(ret.Path) < len(policy.Path) {
+			ret = policy
+		}
+	}
+
+	return
+}
+
+// corsOriginAllowed checks whether the specified origin is allowed by the specified CORS policy.
+func corsOriginAllowed(policy *model.CORSPolicy, origin string) bool {
+	for _, allowed := range policy.AllowOrigins {
+		if "*" == allowed || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
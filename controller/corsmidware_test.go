@@ -0,0 +1,141 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+	"github.com/gin-gonic/gin"
+)
+
+// setCORSPolicies sets the specified CORS policies and returns a function restoring the configuration.
+func setCORSPolicies(policies ...*model.CORSPolicy) func() {
+	conf := model.Conf
+	model.Conf = &model.Configuration{CORS: policies}
+
+	return func() { model.Conf = conf }
+}
+
+func TestGetCORSPolicy(t *testing.T) {
+	root := &model.CORSPolicy{Path: "/", AllowOrigins: []string{"*"}}
+	content := &model.CORSPolicy{Path: "/content", AllowOrigins: []string{"*"}}
+	console := &model.CORSPolicy{Path: "/console", AllowOrigins: []string{"https://b3log.org"}}
+	defer setCORSPolicies(root, content, console)()
+
+	cases := map[string]*model.CORSPolicy{
+		"/api/content":            content,
+		"/api/content/articles/1": content,
+		"/api/console/articles":   console,
+		"/api/consoles":           root,
+		"/api/status":             root,
+		"/blogs/pipe/content":     nil,
+		"/apis/content":           nil,
+	}
+	for path, expected := range cases {
+		if policy := getCORSPolicy(path); expected != policy {
+			t.Errorf("path [%s] expected is [%+v], actual is [%+v]", path, expected, policy)
+		}
+	}
+}
+
+func TestCORSOriginAllowed(t *testing.T) {
+	policy := &model.CORSPolicy{AllowOrigins: []string{"https://b3log.org/", "http://localhost:3000"}}
+	cases := map[string]bool{
+		"https://b3log.org":      true,
+		"HTTPS://B3LOG.ORG":      true,
+		"http://localhost:3000":  true,
+		"http://b3log.org":       false,
+		"https://b3log.org.evil": false,
+		"http://localhost:3001":  false,
+	}
+	for origin, expected := range cases {
+		if allowed := corsOriginAllowed(policy, origin); expected != allowed {
+			t.Errorf("origin [%s] expected is [%v], actual is [%v]", origin, expected, allowed)
+		}
+	}
+
+	if !corsOriginAllowed(&model.CORSPolicy{AllowOrigins: []string{"*"}}, "https://any.org") {
+		t.Error("any origin should be allowed by *")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	defer setCORSPolicies(
+		&model.CORSPolicy{Path: "/content", AllowOrigins: []string{"*"}, AllowMethods: []string{"GET", "OPTIONS"}, MaxAge: 600},
+		&model.CORSPolicy{Path: "/console", AllowOrigins: []string{"https://b3log.org"}, AllowMethods: []string{"GET", "PUT"},
+			AllowCredentials: true},
+	)()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(cors())
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	engine.GET("/api/content/articles", ok)
+	engine.PUT("/api/console/articles", ok)
+
+	serve := func(method, path, origin, requestMethod string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if "" != origin {
+			req.Header.Set("Origin", origin)
+		}
+		if "" != requestMethod {
+			req.Header.Set("Access-Control-Request-Method", requestMethod)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	// preflight of a path without OPTIONS routes
+	recorder := serve(http.MethodOptions, "/api/content/articles", "https://any.org", http.MethodGet)
+	header := recorder.Header()
+	if http.StatusNoContent != recorder.Code || "*" != header.Get("Access-Control-Allow-Origin") ||
+		"GET, OPTIONS" != header.Get("Access-Control-Allow-Methods") || "600" != header.Get("Access-Control-Max-Age") ||
+		"" != header.Get("Access-Control-Allow-Credentials") {
+		t.Errorf("unexpected preflight response [%d, %v]", recorder.Code, header)
+	}
+
+	// credentials echo the origin instead of *
+	recorder = serve(http.MethodOptions, "/api/console/articles", "https://b3log.org", http.MethodPut)
+	header = recorder.Header()
+	if http.StatusNoContent != recorder.Code || "https://b3log.org" != header.Get("Access-Control-Allow-Origin") ||
+		"true" != header.Get("Access-Control-Allow-Credentials") || "GET, PUT" != header.Get("Access-Control-Allow-Methods") {
+		t.Errorf("unexpected preflight response [%d, %v]", recorder.Code, header)
+	}
+	recorder = serve(http.MethodPut, "/api/console/articles", "https://b3log.org", "")
+	header = recorder.Header()
+	if http.StatusOK != recorder.Code || "https://b3log.org" != header.Get("Access-Control-Allow-Origin") ||
+		"true" != header.Get("Access-Control-Allow-Credentials") || "" != header.Get("Access-Control-Allow-Methods") {
+		t.Errorf("unexpected response [%d, %v]", recorder.Code, header)
+	}
+
+	// origins not allowed and requests without origins get no CORS headers
+	for _, recorder := range []*httptest.ResponseRecorder{
+		serve(http.MethodOptions, "/api/console/articles", "https://evil.org", http.MethodPut),
+		serve(http.MethodPut, "/api/console/articles", "https://evil.org", ""),
+		serve(http.MethodGet, "/api/content/articles", "", ""),
+		serve(http.MethodGet, "/blogs/pipe", "https://any.org", ""),
+	} {
+		if "" != recorder.Header().Get("Access-Control-Allow-Origin") {
+			t.Errorf("unexpected response headers [%v]", recorder.Header())
+		}
+	}
+}
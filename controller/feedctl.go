@@ -17,7 +17,15 @@
 package controller
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
@@ -26,19 +34,335 @@ import (
 	"github.com/gorilla/feeds"
 )
 
-func outputAtomAction(c *gin.Context) {
-	feed := generateFeed(c)
+// feedDiscussion represents comment activity of a feed entry.
+type feedDiscussion struct {
+	URL           string
+	Count         int
+	LastCommentAt time.Time
+}
+
+// feedRepliesLink represents a replies link of the Atom threading extension (RFC 4685).
+type feedRepliesLink struct {
+	XMLName xml.Name
+	Rel     string `xml:"rel,attr"`
+	Type    string `xml:"type,attr"`
+	Href    string `xml:"href,attr"`
+	Count   int    `xml:"thr:count,attr"`
+	Updated string `xml:"thr:updated,attr,omitempty"`
+}
 
-	feed.WriteAtom(c.Writer)
+// feedLink represents a link of the feed, used to advertise the WebSub hub and the topic.
+type feedLink struct {
+	XMLName xml.Name
+	Rel     string `xml:"rel,attr"`
+	Href    string `xml:"href,attr"`
+}
+
+type atomFeed struct {
+	*feeds.AtomFeed
+	ThrNamespace string `xml:"xmlns:thr,attr"`
+	HubLink      *feedLink
+	SelfLink     *feedLink
+	Entries      []*atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	*feeds.AtomEntry
+	Replies *feedRepliesLink
+	Total   int `xml:"thr:total"`
+}
+
+func (feed *atomFeed) FeedXml() interface{} {
+	return feed
+}
+
+type rssFeedXML struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr"`
+	SlashNamespace   string   `xml:"xmlns:slash,attr"`
+	ThrNamespace     string   `xml:"xmlns:thr,attr"`
+	Channel          *rssFeed
+}
+
+type rssFeed struct {
+	*feeds.RssFeed
+	HubLink  *feedLink
+	SelfLink *feedLink
+	Items    []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	*feeds.RssItem
+	Comments      string `xml:"comments,omitempty"`
+	SlashComments int    `xml:"slash:comments"`
+	Replies       *feedRepliesLink
+}
+
+func (feed *rssFeedXML) FeedXml() interface{} {
+	return feed
+}
+
+const (
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	slashNamespace   = "http://purl.org/rss/1.0/modules/slash/"
+	thrNamespace     = "http://purl.org/syndication/thread/1.0"
+)
+
+func outputAtomAction(c *gin.Context) {
+	feed, discussions := generateFeed(c)
+	writeAtomFeed(c, feed, discussions, feed.Link.Href+util.PathAtom)
 }
 
 func outputRSSAction(c *gin.Context) {
-	feed := generateFeed(c)
+	feed, discussions := generateFeed(c)
+	writeRSSFeed(c, feed, discussions, feed.Link.Href+util.PathRSS)
+}
+
+// outputTopicFeedAction outputs Atom or RSS feed of a tag or a category, for example /tags/Pipe/atom and
+// /categories/pipe/rss.
+func outputTopicFeedAction(c *gin.Context) {
+	blogID := getBlogID(c)
+	blogURLSetting := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+	path := c.Request.URL.Path
+	format := util.PathAtom
+	if strings.HasSuffix(path, util.PathRSS) {
+		format = util.PathRSS
+	}
+	path = strings.TrimSuffix(path, format)
+
+	var articles []*model.Article
+	var topicTitle, topicURL string
+	if strings.Contains(path, util.PathTags+"/") {
+		tagTitle := strings.SplitAfter(path, util.PathTags+"/")[1]
+		tag := service.Tag.GetTagByTitle(tagTitle, blogID)
+		if nil == tag {
+			notFound(c)
+
+			return
+		}
+		articles, _ = service.Article.GetTagArticles(tag.ID, 1, blogID)
+		topicTitle = tag.Title
+		topicURL = blogURLSetting.Value + util.PathTags + "/" + tag.Title
+	} else {
+		categoryPath := strings.SplitAfter(path, util.PathCategories)[1]
+		category := service.Category.GetCategoryByPath(categoryPath, blogID)
+		if nil == category {
+			notFound(c)
+
+			return
+		}
+		articles, _ = service.Article.GetCategoryArticles(category.ID, 1, blogID)
+		topicTitle = category.Title
+		topicURL = blogURLSetting.Value + util.PathCategories + category.Path
+	}
+
+	feed, discussions := generateArticlesFeed(c, articles)
+	feed.Title += " - " + topicTitle
+	feed.Link = &feeds.Link{Href: topicURL}
+	if util.PathRSS == format {
+		writeRSSFeed(c, feed, discussions, "")
+	} else {
+		writeAtomFeed(c, feed, discussions, "")
+	}
+}
+
+// writeAtomFeed writes the specified feed as Atom, the WebSub hub is advertised if the topic URL is specified.
+func writeAtomFeed(c *gin.Context, feed *feeds.Feed, discussions []*feedDiscussion, topicURL string) {
+	atom := (&feeds.Atom{Feed: feed}).FeedXml().(*feeds.AtomFeed)
+	ret := &atomFeed{AtomFeed: atom, ThrNamespace: thrNamespace}
+	ret.HubLink, ret.SelfLink = newWebSubLinks(c, "link", topicURL)
+	for i, entry := range atom.Entries {
+		discussion := discussions[i]
+		ret.Entries = append(ret.Entries, &atomEntry{
+			AtomEntry: entry,
+			Replies:   newFeedRepliesLink("link", discussion),
+			Total:     discussion.Count,
+		})
+	}
+
+	writeXMLFeed(c, "application/atom+xml; charset=utf-8", ret, feed, discussions)
+}
+
+// writeRSSFeed writes the specified feed as RSS, the WebSub hub is advertised if the topic URL is specified.
+func writeRSSFeed(c *gin.Context, feed *feeds.Feed, discussions []*feedDiscussion, topicURL string) {
+	rss := (&feeds.Rss{Feed: feed}).RssFeed()
+	channel := &rssFeed{RssFeed: rss}
+	channel.HubLink, channel.SelfLink = newWebSubLinks(c, "atom:link", topicURL)
+	for i, item := range rss.Items {
+		discussion := discussions[i]
+		channel.Items = append(channel.Items, &rssItem{
+			RssItem:       item,
+			Comments:      discussion.URL,
+			SlashComments: discussion.Count,
+			Replies:       newFeedRepliesLink("atom:link", discussion),
+		})
+	}
+	ret := &rssFeedXML{
+		Version:          "2.0",
+		ContentNamespace: contentNamespace,
+		AtomNamespace:    atomNamespace,
+		SlashNamespace:   slashNamespace,
+		ThrNamespace:     thrNamespace,
+		Channel:          channel,
+	}
+
+	writeXMLFeed(c, "application/rss+xml; charset=utf-8", ret, feed, discussions)
+}
+
+// jsonFeed represents a JSON Feed 1.1 (https://jsonfeed.org/version/1.1).
+type jsonFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url"`
+	FeedURL     string          `json:"feed_url"`
+	Description string          `json:"description,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Items       []*jsonFeedItem `json:"items"`
+}
 
-	feed.WriteRss(c.Writer)
+type jsonFeedItem struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Title         string            `json:"title"`
+	ContentHTML   string            `json:"content_html,omitempty"`
+	ContentText   string            `json:"content_text,omitempty"`
+	DatePublished string            `json:"date_published"`
+	DateModified  string            `json:"date_modified,omitempty"`
+	Authors       []*jsonFeedAuthor `json:"authors,omitempty"`
 }
 
-func generateFeed(c *gin.Context) *feeds.Feed {
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func outputJSONFeedAction(c *gin.Context) {
+	feed, discussions := generateFeed(c)
+
+	blogID := getBlogID(c)
+	feedOutputModeSetting := service.Setting.GetSetting(model.SettingCategoryFeed, model.SettingNameFeedOutputMode, blogID)
+	fullContent := strconv.Itoa(model.SettingFeedOutputModeValueFull) == feedOutputModeSetting.Value
+	localeSetting := service.Setting.GetSetting(model.SettingCategoryI18n, model.SettingNameI18nLocale, blogID)
+	ret := &jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link.Href,
+		FeedURL:     feed.Link.Href + util.PathJSONFeed,
+		Description: feed.Description,
+		Language:    strings.Replace(localeSetting.Value, "_", "-", -1),
+		Items:       []*jsonFeedItem{},
+	}
+	for _, item := range feed.Items {
+		jsonItem := &jsonFeedItem{
+			ID:            item.Link.Href,
+			URL:           item.Link.Href,
+			Title:         item.Title,
+			DatePublished: item.Created.Format(time.RFC3339),
+		}
+		if fullContent {
+			jsonItem.ContentHTML = item.Description
+		} else {
+			jsonItem.ContentText = item.Description
+		}
+		if !item.Updated.IsZero() {
+			jsonItem.DateModified = item.Updated.Format(time.RFC3339)
+		}
+		if nil != item.Author {
+			jsonItem.Authors = []*jsonFeedAuthor{{Name: item.Author.Name}}
+		}
+		ret.Items = append(ret.Items, jsonItem)
+	}
+
+	body, err := json.Marshal(ret)
+	if nil != err {
+		util.Log(c).Errorf("marshal JSON feed failed: %s", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+	writeFeed(c, "application/feed+json; charset=utf-8", body, feed, discussions)
+}
+
+// writeXMLFeed writes the specified feed XML.
+func writeXMLFeed(c *gin.Context, contentType string, feedXML feeds.XmlFeed, feed *feeds.Feed,
+	discussions []*feedDiscussion) {
+	buf := &bytes.Buffer{}
+	if err := feeds.WriteXML(feedXML, buf); nil != err {
+		util.Log(c).Errorf("write feed failed: %s", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	writeFeed(c, contentType, buf.Bytes(), feed, discussions)
+}
+
+// writeFeed writes the specified feed body, responds 304 if the feed reader has the same feed already.
+func writeFeed(c *gin.Context, contentType string, body []byte, feed *feeds.Feed, discussions []*feedDiscussion) {
+	var lastModified time.Time
+	for _, item := range feed.Items {
+		if item.Updated.After(lastModified) {
+			lastModified = item.Updated
+		}
+		if item.Created.After(lastModified) {
+			lastModified = item.Created
+		}
+	}
+	for _, discussion := range discussions {
+		if discussion.LastCommentAt.After(lastModified) {
+			lastModified = discussion.LastCommentAt
+		}
+	}
+	etag := fmt.Sprintf(`"%x"`, md5.Sum(body))
+	if notModified(c, etag, lastModified) {
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func newFeedRepliesLink(name string, discussion *feedDiscussion) *feedRepliesLink {
+	ret := &feedRepliesLink{
+		XMLName: xml.Name{Local: name},
+		Rel:     "replies",
+		Type:    "text/html",
+		Href:    discussion.URL,
+		Count:   discussion.Count,
+	}
+	if !discussion.LastCommentAt.IsZero() {
+		ret.Updated = discussion.LastCommentAt.Format(time.RFC3339)
+	}
+
+	return ret
+}
+
+// newWebSubLinks creates the hub and self links advertising the configured WebSub hub, returns nils if the hub is
+// not configured.
+func newWebSubLinks(c *gin.Context, name, topicURL string) (hub, self *feedLink) {
+	if "" == topicURL {
+		return
+	}
+	hubURL := service.WebSub.GetHub(getBlogID(c))
+	if "" == hubURL {
+		return
+	}
+
+	hub = &feedLink{XMLName: xml.Name{Local: name}, Rel: "hub", Href: hubURL}
+	self = &feedLink{XMLName: xml.Name{Local: name}, Rel: "self", Href: topicURL}
+
+	return
+}
+
+func generateFeed(c *gin.Context) (*feeds.Feed, []*feedDiscussion) {
+	articles, _ := service.Article.GetArticles("", 1, getBlogID(c))
+
+	return generateArticlesFeed(c, articles)
+}
+
+// generateArticlesFeed generates a feed of the specified articles.
+func generateArticlesFeed(c *gin.Context, articles []*model.Article) (*feeds.Feed, []*feedDiscussion) {
 	blogID := getBlogID(c)
 
 	feedOutputModeSetting := service.Setting.GetSetting(model.SettingCategoryFeed, model.SettingNameFeedOutputMode, blogID)
@@ -52,12 +376,12 @@ func generateFeed(c *gin.Context) *feeds.Feed {
 	}
 
 	var items []*feeds.Item
-	articles, _ := service.Article.GetArticles("", 1, blogID)
+	var discussions []*feedDiscussion
 	for _, article := range articles {
-		mdResult := util.Markdown(article.Content)
+		mdResult := markdown(article.Content, blogID)
 		description := mdResult.AbstractText
 		if strconv.Itoa(model.SettingFeedOutputModeValueFull) == feedOutputModeSetting.Value {
-			description = mdResult.ContentHTML
+			description = highlightCode(mdResult.ContentHTML, blogID)
 		}
 		user := service.User.GetUser(article.AuthorID)
 		items = append(items, &feeds.Item{
@@ -66,9 +390,19 @@ func generateFeed(c *gin.Context) *feeds.Feed {
 			Description: description,
 			Author:      &feeds.Author{Name: user.Name},
 			Created:     article.CreatedAt,
+			Updated:     article.UpdatedAt,
 		})
+
+		discussion := &feedDiscussion{
+			URL:   blogURLSetting.Value + article.Path + "#pipeComments",
+			Count: article.CommentCount,
+		}
+		if lastComment := service.Comment.GetArticleLastComment(article.ID, blogID); nil != lastComment {
+			discussion.LastCommentAt = lastComment.CreatedAt
+		}
+		discussions = append(discussions, discussion)
 	}
 	ret.Items = items
 
-	return ret
+	return ret, discussions
 }
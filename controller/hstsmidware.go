@@ -0,0 +1,33 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"strconv"
+
+	"github.com/b3log/pipe/model"
+	"github.com/gin-gonic/gin"
+)
+
+// hsts returns a middleware which tells browsers to access the server over HTTPS only for Conf.HSTSMaxAge seconds.
+func hsts() gin.HandlerFunc {
+	value := "max-age=" + strconv.Itoa(model.Conf.HSTSMaxAge)
+
+	return func(c *gin.Context) {
+		c.Header("Strict-Transport-Security", value)
+	}
+}
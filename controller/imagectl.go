@@ -0,0 +1,50 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// showImageAction redirects to the uploaded image of the specified hash resized to the width (w) and height (h)
+// specified by the query, the original image is redirected to if no width is specified.
+func showImageAction(c *gin.Context) {
+	image := service.Image.GetImage(c.Param("hash"))
+	if nil == image {
+		notFound(c)
+
+		return
+	}
+
+	width, _ := strconv.Atoi(c.Query("w"))
+	height, _ := strconv.Atoi(c.Query("h"))
+	url, err := service.Image.GetURL(image, width, height)
+	if nil != err {
+		util.Log(c).Errorf("resize image [%s] failed: %s", image.Hash, err)
+		notFound(c)
+
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Redirect(http.StatusFound, url)
+}
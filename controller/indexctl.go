@@ -23,16 +23,30 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
 	"github.com/gin-gonic/gin"
 )
 
 func showIndexAction(c *gin.Context) {
+	switch model.Conf.IndexMode {
+	case model.IndexModeRedirect:
+		if blogURL := getIndexBlogURL(); "" != blogURL {
+			c.Redirect(http.StatusFound, blogURL)
+
+			return
+		}
+		util.Log(c).Warnf("not found home page blog [%s], shows the blogs index instead", model.Conf.IndexBlog)
+	case model.IndexModeLanding:
+		showLandingPage(c)
+
+		return
+	}
+
 	t, err := template.ParseFiles("console/dist/index.html")
 	if nil != err {
-		logger.Errorf("load index page failed: " + err.Error())
+		util.Log(c).Errorf("load index page failed: %s", err)
 		c.String(http.StatusNotFound, "load index page failed")
 
 		return
@@ -41,10 +55,46 @@ func showIndexAction(c *gin.Context) {
 	t.Execute(c.Writer, nil)
 }
 
+// getIndexBlogURL gets the URL of the blog specified by Conf.IndexBlog, returns "" if not found.
+func getIndexBlogURL() string {
+	user := service.User.GetUserByName(model.Conf.IndexBlog)
+	if nil == user {
+		return ""
+	}
+	blog := service.User.GetOwnBlog(user.ID)
+	if nil == blog {
+		return ""
+	}
+
+	return blog.URL
+}
+
+// showLandingPage renders the custom landing template specified by Conf.IndexTemplate.
+func showLandingPage(c *gin.Context) {
+	t, err := template.ParseFiles(model.Conf.IndexTemplate)
+	if nil != err {
+		util.Log(c).Errorf("load landing page [%s] failed: %s", model.Conf.IndexTemplate, err)
+		c.String(http.StatusNotFound, "load landing page failed")
+
+		return
+	}
+
+	dataModel := map[string]interface{}{
+		"Server":            model.Conf.Server,
+		"StaticServer":      model.Conf.StaticServer,
+		"StaticResourceVer": model.Conf.StaticResourceVersion,
+		"Version":           model.Version,
+		"Blogs":             service.User.GetTopBlogs(10),
+	}
+	if err := t.Execute(c.Writer, dataModel); nil != err {
+		util.Log(c).Errorf("execute landing page [%s] failed: %s", model.Conf.IndexTemplate, err)
+	}
+}
+
 func showStartPageAction(c *gin.Context) {
 	t, err := template.ParseFiles("console/dist/start/index.html")
 	if nil != err {
-		logger.Errorf("load start page failed: " + err.Error())
+		util.Log(c).Errorf("load start page failed: %s", err)
 		c.String(http.StatusNotFound, "load start page failed")
 
 		return
@@ -54,7 +104,7 @@ func showStartPageAction(c *gin.Context) {
 }
 
 func showPlatInfoAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	data := map[string]interface{}{}
@@ -69,7 +119,7 @@ func showPlatInfoAction(c *gin.Context) {
 }
 
 func showTopBlogsAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	blogs := service.User.GetTopBlogs(10)
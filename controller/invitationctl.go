@@ -0,0 +1,80 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// acceptInvitationAction accepts an invitation. Visitors are redirected to login first, then join the invited blog
+// and land on its admin console.
+func acceptInvitationAction(c *gin.Context) {
+	token := c.Param("token")
+	session := util.GetSession(c)
+	// the invitation has been accepted on registration
+	invitation := service.Invitation.GetAcceptedInvitation(token, session.UID)
+	if nil == invitation && nil == service.Invitation.GetInvitation(token) {
+		notFound(c)
+
+		return
+	}
+
+	if 0 == session.UID {
+		referer := service.Invitation.GetInvitationURL(token)
+		if model.AuthModeLocal == model.Conf.AuthMode {
+			c.Redirect(http.StatusSeeOther, model.Conf.Server+util.PathInit+"?invitation="+url.QueryEscape(token)+
+				"&referer="+url.QueryEscape(referer))
+
+			return
+		}
+		c.Redirect(http.StatusSeeOther, model.Conf.Server+util.PathAPI+"/oauth/github/redirect?referer="+url.QueryEscape(referer))
+
+		return
+	}
+
+	if nil == invitation {
+		var err error
+		if invitation, err = service.Invitation.AcceptInvitation(token, session.UID); nil != err {
+			util.Log(c).Errorf("accept invitation failed: %s", err)
+			notFound(c)
+
+			return
+		}
+	}
+	userBlog := service.User.GetUserBlog(session.UID, invitation.BlogID)
+	if nil == userBlog {
+		notFound(c)
+
+		return
+	}
+	util.Log(c).Infof("user [%s] joined blog [%d] by invitation", session.UName, invitation.BlogID)
+
+	session.URole = userBlog.UserRole
+	session.BID = userBlog.ID
+	session.BURL = userBlog.URL
+	if err := session.Save(c); nil != err {
+		util.Log(c).Errorf("saves session failed: %s", err)
+	}
+
+	c.Redirect(http.StatusSeeOther, model.Conf.Server+util.PathAdmin)
+}
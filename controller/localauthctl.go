@@ -0,0 +1,238 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// loginAction logins with username (or email) and password in authentication mode "local".
+func loginAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg, ok := bindLocalAuthArg(c, result)
+	if !ok {
+		return
+	}
+
+	user, err := service.LocalAuth.Authenticate(arg["name"], arg["password"])
+	if nil != err {
+		util.Log(c).Warnf("user [%s] failed to login", arg["name"])
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeUnauthenticated
+		result.Msg = err.Error()
+
+		return
+	}
+
+	if err := saveLoginSession(c, user); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// registerAction registers a local account with a blog of its own. The first account is always allowed to register
+// to init the platform, others need registration to be open or a valid invitation.
+func registerAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg, ok := bindLocalAuthArg(c, result)
+	if !ok {
+		return
+	}
+	invitation := arg["invitation"]
+	if service.Init.Inited() && !model.Conf.Registration && "" == invitation {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = service.ErrRegistrationClosed.Error()
+
+		return
+	}
+
+	register := service.LocalAuth.Register
+	if service.Init.Inited() && "" != invitation {
+		register = func(name, email, password string) (*model.User, error) {
+			return service.LocalAuth.RegisterInvited(name, email, password, invitation)
+		}
+	}
+	registerLocalAccount(c, result, arg, register)
+}
+
+// registerReaderAction registers a local reader account which follows blogs without having a blog of its own, reader
+// registration needs to be open.
+func registerReaderAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg, ok := bindLocalAuthArg(c, result)
+	if !ok {
+		return
+	}
+	if service.Init.Inited() && !model.Conf.ReaderRegistration {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = service.ErrRegistrationClosed.Error()
+
+		return
+	}
+
+	registerLocalAccount(c, result, arg, service.LocalAuth.RegisterReader)
+}
+
+// registerLocalAccount registers a local account with the specified arguments and register function then logs it in.
+func registerLocalAccount(c *gin.Context, result *util.Result, arg map[string]string,
+	register func(name, email, password string) (*model.User, error)) {
+	user, err := register(strings.TrimSpace(arg["name"]), arg["email"], arg["password"])
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+		switch err {
+		case service.ErrInvalidUsername, service.ErrInvalidEmail, service.ErrInvalidPassword:
+			result.ErrCode = util.ErrCodeBadRequest
+		case service.ErrUsernameTaken, service.ErrReservedName, service.ErrRegistrationFailed:
+			result.ErrCode = util.ErrCodeConflict
+		case service.ErrInvalidInvitation:
+			result.ErrCode = util.ErrCodeForbidden
+		}
+
+		return
+	}
+	util.Log(c).Infof("user [%s] registered", user.Name)
+
+	if err := saveLoginSession(c, user); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// forgotPasswordAction mails a password reset link. It succeeds even if the email is not registered.
+func forgotPasswordAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg, ok := bindLocalAuthArg(c, result)
+	if !ok {
+		return
+	}
+	if !service.Mail.Enabled() {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeUnavailable
+		result.Msg = "SMTP server is not configured"
+
+		return
+	}
+
+	if err := service.LocalAuth.SendPasswordReset(arg["email"]); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeInternal
+		result.Msg = "sends password reset mail failed"
+	}
+}
+
+// resetPasswordAction resets the password with the token mailed by forgotPasswordAction and logins.
+func resetPasswordAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	arg, ok := bindLocalAuthArg(c, result)
+	if !ok {
+		return
+	}
+
+	user, err := service.LocalAuth.ResetPassword(arg["token"], arg["password"])
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	util.Log(c).Infof("user [%s] reset password", user.Name)
+
+	if err := saveLoginSession(c, user); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// bindLocalAuthArg checks authentication mode, then binds the string arguments of the request.
+func bindLocalAuthArg(c *gin.Context, result *util.Result) (map[string]string, bool) {
+	if model.AuthModeLocal != model.Conf.AuthMode {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = service.ErrLocalAuthNotEnabled.Error()
+
+		return nil, false
+	}
+
+	arg := map[string]string{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses request failed"
+
+		return nil, false
+	}
+
+	return arg, true
+}
+
+// saveLoginSession saves the session of the specified logged in user with its own blog, or the first blog it's a
+// member of. Readers who are not a member of any blog have no blog in the session.
+func saveLoginSession(c *gin.Context, user *model.User) error {
+	ownBlog := service.User.GetOwnBlog(user.ID)
+	if nil == ownBlog {
+		if userBlogs := service.User.GetUserBlogs(user.ID); 0 < len(userBlogs) {
+			ownBlog = userBlogs[0]
+		} else {
+			ownBlog = &service.UserBlog{UserID: user.ID, UserRole: model.UserRoleNoLogin}
+		}
+	}
+	sid, err := service.UserSession.AddSession(user.ID, util.GetRemoteAddr(c), c.Request.UserAgent())
+	if nil != err {
+		util.Log(c).Errorf("register session failed: %s", err)
+
+		return err
+	}
+
+	session := &util.SessionData{
+		UID:     user.ID,
+		UName:   user.Name,
+		UB3Key:  user.B3Key,
+		UAvatar: user.AvatarURL,
+		URole:   ownBlog.UserRole,
+		BID:     ownBlog.ID,
+		BURL:    ownBlog.URL,
+		SID:     sid,
+		ULocale: user.Locale,
+	}
+	if err := session.Save(c); nil != err {
+		util.Log(c).Errorf("saves session failed: %s", err)
+
+		return err
+	}
+
+	return nil
+}
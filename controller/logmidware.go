@@ -0,0 +1,65 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/b3log/pipe/util"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// accessLog logs method, path, status, latency and user of requests with their request IDs, server errors are logged
+// at error level. They are fields of JSON objects if the log format is JSON.
+func accessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	userName := ""
+	if _, ok := c.Get(sessions.DefaultKey); ok {
+		userName = util.GetSession(c).UName
+	}
+	l := util.Log(c).WithField("method", c.Request.Method).WithField("path", util.RedactedURI(c.Request.URL)).
+		WithField("status", status).WithField("latency", time.Since(start).String()).WithField("user", userName)
+	if http.StatusInternalServerError <= status {
+		l.Errorf("access")
+
+		return
+	}
+	l.Infof("access")
+}
+
+// recovery recovers from panics and responds the request ID for reporting.
+func recovery(c *gin.Context) {
+	defer func() {
+		if err := recover(); nil != err {
+			util.Log(c).Errorf("recovered from panic: %v\n%s", err, debug.Stack())
+
+			result := util.NewResult(c)
+			result.Code = util.CodeErr
+			result.ErrCode = util.ErrCodeInternal
+			result.Msg = "internal server error"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, result)
+		}
+	}()
+
+	c.Next()
+}
@@ -34,18 +34,24 @@ var states = map[string]string{}
 
 // redirectGitHubLoginAction redirects to GitHub auth page.
 func redirectGitHubLoginAction(c *gin.Context) {
+	if model.AuthModeOAuth != model.Conf.AuthMode {
+		notFound(c)
+
+		return
+	}
+
 	requestResult := gulu.Ret.NewResult()
 	_, _, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
 		Get(util.HacPaiURL+"/oauth/pipe/client2").
 		Set("user-agent", model.UserAgent).Timeout(10 * time.Second).EndStruct(requestResult)
 	if nil != errs {
-		logger.Errorf("get oauth client id failed: %+v", errs)
+		util.Log(c).Errorf("get oauth client id failed: %+v", errs)
 		c.Status(http.StatusNotFound)
 
 		return
 	}
 	if util.CodeOk != requestResult.Code {
-		logger.Errorf("get oauth client id failed [code=%d, msg=%s]", requestResult.Code, requestResult.Msg)
+		util.Log(c).Errorf("get oauth client id failed [code=%d, msg=%s]", requestResult.Code, requestResult.Msg)
 		c.Status(http.StatusNotFound)
 
 		return
@@ -65,13 +71,19 @@ func redirectGitHubLoginAction(c *gin.Context) {
 	states[state] = state
 	path := loginAuthURL + "?client_id=" + clientId + "&state=" + state + "&scope=public_repo,read:user,user:follow"
 
-	logger.Infof("redirect to github [" + path + "]")
+	util.Log(c).Infof("redirect to github [%s]", path)
 
 	c.Redirect(http.StatusSeeOther, path)
 }
 
 func githubCallbackAction(c *gin.Context) {
-	logger.Infof("github callback [" + c.Request.URL.String() + "]")
+	if model.AuthModeOAuth != model.Conf.AuthMode {
+		notFound(c)
+
+		return
+	}
+
+	util.Log(c).Infof("github callback [%s]", c.Request.URL.String())
 
 	state := c.Query("state")
 	if _, exist := states[state]; !exist {
@@ -88,7 +100,7 @@ func githubCallbackAction(c *gin.Context) {
 	accessToken := c.Query("ak")
 	githubUser := util.GitHubUserInfo(accessToken)
 	if nil == githubUser {
-		logger.Warnf("can not get user info with token [" + accessToken + "]")
+		util.Log(c).Warnf("can not get user info with token [%s]", accessToken)
 		c.Status(http.StatusUnauthorized)
 
 		return
@@ -107,7 +119,7 @@ func githubCallbackAction(c *gin.Context) {
 			}
 
 			if err := service.Init.InitPlatform(user); nil != err {
-				logger.Errorf("init platform via github login failed: " + err.Error())
+				util.Log(c).Errorf("init platform via github login failed: %s", err)
 				c.Status(http.StatusInternalServerError)
 
 				return
@@ -123,7 +135,7 @@ func githubCallbackAction(c *gin.Context) {
 				}
 
 				if err := service.Init.InitBlog(user); nil != err {
-					logger.Errorf("init blog via github login failed: " + err.Error())
+					util.Log(c).Errorf("init blog via github login failed: %s", err)
 					c.Status(http.StatusInternalServerError)
 
 					return
@@ -136,27 +148,11 @@ func githubCallbackAction(c *gin.Context) {
 		}
 	}
 
-	ownBlog := service.User.GetOwnBlog(user.ID)
-	if nil == ownBlog {
-		logger.Warnf("can not get user by name [" + userName + "]")
-		c.Status(http.StatusNotFound)
+	if err := saveLoginSession(c, user); nil != err {
+		c.Status(http.StatusInternalServerError)
 
 		return
 	}
 
-	session := &util.SessionData{
-		UID:     user.ID,
-		UName:   user.Name,
-		UB3Key:  user.B3Key,
-		UAvatar: user.AvatarURL,
-		URole:   ownBlog.UserRole,
-		BID:     ownBlog.ID,
-		BURL:    ownBlog.URL,
-	}
-	if err := session.Save(c); nil != err {
-		logger.Errorf("saves session failed: " + err.Error())
-		c.Status(http.StatusInternalServerError)
-	}
-
 	c.Redirect(http.StatusSeeOther, referer)
 }
@@ -0,0 +1,121 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// pageCacheWriter captures the rendered page while writing it through.
+type pageCacheWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *pageCacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *pageCacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+// pjaxContainerRegexp matches the pjax containers of the themes, e.g. #pjax.
+var pjaxContainerRegexp = regexp.MustCompile(`^[#.]?[A-Za-z0-9_-]{1,32}$`)
+
+// pageCacheable checks whether the current request could be served from or stored into the page cache. Only GET
+// requests from visitors are cached since signed-in users see personalized pages. Requests with query arguments other
+// than the page number p are not cached, so clients can't fill the cache with arbitrary keys.
+func pageCacheable(c *gin.Context) bool {
+	if "dev" == model.Conf.RuntimeMode || http.MethodGet != c.Request.Method || nil != getStaticExport(c) {
+		return false
+	}
+	for name := range c.Request.URL.Query() {
+		if "p" != name {
+			return false
+		}
+	}
+	if isPJAX(c) && !pjaxContainerRegexp.MatchString(c.Request.Header.Get("X-PJAX-Container")) {
+		return false
+	}
+
+	return 0 == util.GetSession(c).UID
+}
+
+// pageCacheKey returns the cache key of the current request, which is the path with the normalized page number. pjax
+// requests render differently.
+func pageCacheKey(c *gin.Context) string {
+	ret := c.Request.URL.Path
+	if page, err := strconv.Atoi(c.Query("p")); nil == err && 1 < page {
+		ret += "?p=" + strconv.Itoa(page)
+	}
+	if isPJAX(c) {
+		ret += "#pjax=" + c.Request.Header.Get("X-PJAX-Container")
+	}
+
+	return ret
+}
+
+// servePageCache writes the cached page of the current request, returns false if not cached.
+func servePageCache(c *gin.Context, blogID uint64) bool {
+	if !pageCacheable(c) {
+		return false
+	}
+
+	page := cache.Page.Get(blogID, pageCacheKey(c))
+	if nil == page {
+		return false
+	}
+
+	c.Header("X-Pipe-Cache", "HIT")
+	c.Data(http.StatusOK, page.ContentType, page.Body)
+
+	return true
+}
+
+// capturePage records the page rendered by handle into the page cache.
+func capturePage(c *gin.Context, blogID uint64, handle func()) {
+	if !pageCacheable(c) {
+		handle()
+
+		return
+	}
+
+	writer := &pageCacheWriter{c.Writer, &bytes.Buffer{}}
+	c.Writer = writer
+	handle()
+	c.Writer = writer.ResponseWriter
+
+	contentType := writer.Header().Get("Content-Type")
+	if http.StatusOK != writer.Status() || !strings.HasPrefix(contentType, "text/html") || 0 == writer.body.Len() {
+		return
+	}
+	cache.Page.Put(blogID, pageCacheKey(c), &cache.CachedPage{ContentType: contentType, Body: writer.body.Bytes()})
+}
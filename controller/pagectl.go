@@ -0,0 +1,75 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+	"github.com/vinta/pangu"
+)
+
+// getStaticPage returns the published page of the specified blog at the specified path, returns nil if the path is not
+// a top-level path which could be used by pages.
+func getStaticPage(path string, blogID uint64) *model.Page {
+	if "/" == path || strings.Count(path, "/") > 1 || util.IsReservedPath(path) {
+		return nil
+	}
+
+	return service.Page.GetPageByPath(path, blogID)
+}
+
+func showPageAction(c *gin.Context) {
+	p, _ := c.Get("page")
+	pageModel := p.(*model.Page)
+	dataModel := getDataModel(c)
+	blogID := getBlogID(c)
+	session := util.GetSession(c)
+
+	mdResult := markdown(pageModel.Content, blogID)
+	contentHTML := highlightCode(mdResult.ContentHTML, blogID)
+	contentHTML = service.Glossary.LinkGlossaries(contentHTML, blogID)
+	contentHTML = service.Image.RewritePictures(contentHTML)
+	pageTitle := pangu.SpacingText(pageModel.Title)
+	page := &model.ThemePage{
+		ID:        pageModel.ID,
+		Title:     pageTitle,
+		URL:       getBlogURL(c) + pageModel.Path,
+		Content:   template.HTML(contentHTML),
+		UpdatedAt: pageModel.UpdatedAt.Format("2006-01-02"),
+		Author:    &model.ThemeAuthor{},
+		Editable:  session.UID == pageModel.AuthorID || (0 < session.UID && model.UserRoleBlogEditor >= service.User.GetRole(session.UID, blogID)),
+	}
+	if author := service.User.GetUser(pageModel.AuthorID); nil != author {
+		page.Author = &model.ThemeAuthor{
+			Name:      author.Name,
+			URL:       getBlogURL(c) + util.PathAuthors + "/" + author.Name,
+			AvatarURL: author.AvatarURL,
+		}
+	}
+	dataModel["Page"] = page
+	dataModel["MetaDescription"] = html.UnescapeString(mdResult.AbstractText)
+	dataModel["Title"] = pageTitle + " - " + dataModel["Title"].(string)
+
+	c.HTML(http.StatusOK, getTheme(c)+"/page.html", dataModel)
+}
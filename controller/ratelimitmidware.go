@@ -0,0 +1,142 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limiters of routes prone to floods and brute-force attempts, configured by MapRoutes. A nil limiter means
+// unlimited.
+var (
+	authLimiter    *rateLimiter // login, registration and password reset
+	commentLimiter *rateLimiter // comment submissions
+	searchLimiter  *rateLimiter // searches
+)
+
+// initRateLimiters creates rate limiters with the limits of the configuration.
+func initRateLimiters() {
+	authLimiter = newRateLimiter(model.Conf.LoginRateLimit)
+	commentLimiter = newRateLimiter(model.Conf.CommentRateLimit)
+	searchLimiter = newRateLimiter(model.Conf.SearchRateLimit)
+}
+
+// rateLimiter is a token bucket rate limiter.
+type rateLimiter struct {
+	capacity float64 // max tokens of a bucket
+	rate     float64 // tokens refilled per second
+	buckets  map[string]*rateBucket
+	mutex    *sync.Mutex
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a rate limiter which allows the specified count of requests per minute, bursts of the
+// whole count are allowed. Returns nil if the count is not positive.
+func newRateLimiter(perMinute int) *rateLimiter {
+	if 1 > perMinute {
+		return nil
+	}
+
+	return &rateLimiter{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		buckets:  map[string]*rateBucket{},
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// allow takes a token of the specified key, returns the duration to wait if no token left.
+func (limiter *rateLimiter) allow(key string) (bool, time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	bucket := limiter.buckets[key]
+	if nil == bucket {
+		if 10000 <= len(limiter.buckets) {
+			limiter.sweep(now)
+		}
+		bucket = &rateBucket{tokens: limiter.capacity, last: now}
+		limiter.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(limiter.capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*limiter.rate)
+	bucket.last = now
+	if 1 > bucket.tokens {
+		return false, time.Duration((1 - bucket.tokens) / limiter.rate * float64(time.Second))
+	}
+	bucket.tokens--
+
+	return true, 0
+}
+
+// sweep removes buckets which have been refilled fully.
+func (limiter *rateLimiter) sweep(now time.Time) {
+	for key, bucket := range limiter.buckets {
+		if limiter.capacity <= bucket.tokens+now.Sub(bucket.last).Seconds()*limiter.rate {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// rateLimit returns a middleware which limits requests with the specified limiter.
+func rateLimit(limiter *rateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limitRate(c, limiter)
+	}
+}
+
+// limitRate takes a token of the request from the specified limiter, aborts the request with 429 and returns false
+// if the limit is exceeded. Logged-in users are limited per session and visitors per client IP.
+func limitRate(c *gin.Context, limiter *rateLimiter) bool {
+	if nil == limiter {
+		return true
+	}
+
+	key := "ip:" + util.GetRemoteAddr(c)
+	if session := util.GetSession(c); "" != session.SID {
+		key = "session:" + session.SID
+	}
+	if ok, wait := limiter.allow(key); !ok {
+		abortRateLimited(c, wait)
+
+		return false
+	}
+
+	return true
+}
+
+// abortRateLimited aborts the request with 429 and tells the client how long to wait in the Retry-After header.
+func abortRateLimited(c *gin.Context, wait time.Duration) {
+	result := util.NewResult(c)
+	result.Code = util.CodeErr
+	result.ErrCode = util.ErrCodeRateLimited
+	result.Msg = "too many requests"
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, result)
+}
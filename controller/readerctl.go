@@ -0,0 +1,188 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/feeds"
+)
+
+// followBlogAction makes the current user follow the blog of path parameter "username".
+func followBlogAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session, blog := getFollowArgs(c, result)
+	if nil == blog {
+		return
+	}
+
+	if err := service.Reader.Follow(session.UID, blog.ID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// unfollowBlogAction makes the current user unfollow the blog of path parameter "username".
+func unfollowBlogAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session, blog := getFollowArgs(c, result)
+	if nil == blog {
+		return
+	}
+
+	if err := service.Reader.Unfollow(session.UID, blog.ID); nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+	}
+}
+
+// getFollowsAction gets the blogs followed by the current user.
+func getFollowsAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		result.Code = util.CodeAuthErr
+		result.Msg = "unauthenticated request"
+
+		return
+	}
+
+	blogs := []map[string]interface{}{}
+	for _, blog := range service.Reader.GetFollowingBlogs(session.UID) {
+		blogs = append(blogs, map[string]interface{}{
+			"title": blog.Title,
+			"url":   blog.URL,
+		})
+	}
+	result.Data = blogs
+}
+
+// getTimelineAction gets the new articles of the blogs followed by the current user, the response contains the URL of
+// the Atom feed of the timeline as well.
+func getTimelineAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		result.Code = util.CodeAuthErr
+		result.Msg = "unauthenticated request"
+
+		return
+	}
+
+	token, err := service.Reader.GetFeedToken(session.UID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.Msg = err.Error()
+
+		return
+	}
+
+	articleModels, pagination := service.Reader.GetTimeline(session.UID, util.GetPage(c))
+	articles := []*contentArticle{}
+	for _, articleModel := range articleModels {
+		article := newContentArticle(articleModel, contentBlogURL(articleModel.BlogID))
+		article.BlogTitle = service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, articleModel.BlogID).Value
+		articles = append(articles, article)
+	}
+
+	result.Data = map[string]interface{}{
+		"articles":   articles,
+		"pagination": pagination,
+		"feedURL":    model.Conf.Server + util.PathAPI + "/timeline/atom?token=" + url.QueryEscape(token),
+	}
+}
+
+// outputTimelineFeedAction outputs Atom feed of the timeline of the user of query parameter "token", feed readers can
+// not carry the login session.
+func outputTimelineFeedAction(c *gin.Context) {
+	user := service.Reader.GetUserByFeedToken(c.Query("token"))
+	if nil == user {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+
+	articles, _ := service.Reader.GetTimeline(user.ID, 1)
+	feed := &feeds.Feed{
+		Title:       user.Name + " - Timeline",
+		Link:        &feeds.Link{Href: model.Conf.Server},
+		Description: "New articles of blogs followed by " + user.Name,
+	}
+	var discussions []*feedDiscussion
+	for _, article := range articles {
+		blogTitle := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, article.BlogID).Value
+		blogURL := contentBlogURL(article.BlogID)
+		item := &feeds.Item{
+			Title:       article.Title + " - " + blogTitle,
+			Link:        &feeds.Link{Href: blogURL + article.Path},
+			Description: util.Markdown(article.Content).AbstractText,
+			Created:     article.CreatedAt,
+			Updated:     article.UpdatedAt,
+		}
+		if author := service.User.GetUser(article.AuthorID); nil != author {
+			item.Author = &feeds.Author{Name: author.Name}
+		}
+		feed.Items = append(feed.Items, item)
+		discussions = append(discussions, &feedDiscussion{
+			URL:   blogURL + article.Path + "#pipeComments",
+			Count: article.CommentCount,
+		})
+	}
+
+	writeAtomFeed(c, feed, discussions, "")
+}
+
+// getFollowArgs returns the current user and the blog of path parameter "username" to follow, sets the result and
+// returns nil if not logged in or the blog not found.
+func getFollowArgs(c *gin.Context, result *util.Result) (*util.SessionData, *service.UserBlog) {
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		result.Code = util.CodeAuthErr
+		result.Msg = "unauthenticated request"
+
+		return session, nil
+	}
+
+	user := service.User.GetUserByName(c.Param("username"))
+	if nil == user {
+		user = service.User.GetUserByAlias(c.Param("username"))
+	}
+	var ret *service.UserBlog
+	if nil != user {
+		ret = service.User.GetOwnBlog(user.ID)
+	}
+	if nil == ret {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeNotFound
+		result.Msg = "not found blog"
+	}
+
+	return session, ret
+}
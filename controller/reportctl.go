@@ -0,0 +1,62 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// addReportAction queues a report of an article or a comment for review by the platform admins.
+func addReportAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	report := &model.Report{}
+	if err := c.BindJSON(report); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses add report request failed"
+
+		return
+	}
+
+	report.BlogID = getBlogID(c)
+	report.IP = util.GetRemoteAddr(c)
+	report.ReporterID = util.GetSession(c).UID
+	if err := service.Report.AddReport(report); nil != err {
+		result.Code = util.CodeErr
+		switch err {
+		case service.ErrReportTargetNotFound:
+			result.ErrCode = util.ErrCodeNotFound
+		case service.ErrReportInvalid:
+			result.ErrCode = util.ErrCodeBadRequest
+		case service.ErrIPBanned:
+			result.ErrCode = util.ErrCodeForbidden
+		}
+		result.Msg = err.Error()
+
+		return
+	}
+
+	result.Msg = i18n.GetMessage(getLocale(c), "reportSuccess")
+}
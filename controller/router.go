@@ -19,23 +19,26 @@ package controller
 import (
 	"errors"
 	"html/template"
-	"os"
-	"path/filepath"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/controller/console"
+	"github.com/b3log/pipe/log"
 	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/theme"
 	"github.com/b3log/pipe/util"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-contrib/sessions/redis"
 	"github.com/gin-gonic/gin"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 // MapRoutes returns a gin engine and binds controllers with request URLs.
 func MapRoutes() *gin.Engine {
@@ -60,73 +63,168 @@ func MapRoutes() *gin.Engine {
 		"noescape": func(s string) template.HTML { return template.HTML(s) },
 	})
 
-	if "dev" == model.Conf.RuntimeMode {
-		ret.Use(gin.Logger())
+	ret.Use(util.RequestID(), accessLog)
+	if 0 < len(model.Conf.TLSDomains) && 0 < model.Conf.HSTSMaxAge {
+		ret.Use(hsts())
+	}
+	if "" != model.Conf.Compression {
+		ret.Use(compress())
+	}
+	ret.Use(recovery)
+	if 0 < len(model.Conf.CORS) {
+		ret.Use(cors())
 	}
-	ret.Use(gin.Recovery())
 
-	store := cookie.NewStore([]byte(model.Conf.SessionSecret))
+	store := newSessionStore()
 	store.Options(sessions.Options{
 		Path:     "/",
 		MaxAge:   model.Conf.SessionMaxAge,
 		Secure:   strings.HasPrefix(model.Conf.Server, "https"),
 		HttpOnly: true,
 	})
-	ret.Use(sessions.Sessions("pipe", store))
+	ret.Use(sessions.Sessions("pipe", store), secureSession)
 	ret.GET(util.PathPlatInfo, showPlatInfoAction)
 	ret.GET(util.PathSitemap, outputSitemapAction)
 
+	initRateLimiters()
+	authLimit := rateLimit(authLimiter)
+
 	api := ret.Group(util.PathAPI)
+	api.Use(recordAPIUsage, checkSession)
 	api.POST("/logout", logoutAction)
 	api.Any("/hp/*apis", util.HacPaiAPI())
 	api.GET("/status", getStatusAction)
+	api.POST("/comments/preview", previewCommentAction)
 	api.GET("/check-version", console.CheckVersionAction)
 	api.GET("/blogs/top", showTopBlogsAction)
 	api.GET("/oauth/github/redirect", redirectGitHubLoginAction)
 	api.GET("/oauth/github/callback", githubCallbackAction)
+	api.GET("/auth/:provider/login", redirectAuthProviderAction)
+	api.GET("/auth/:provider/callback", authProviderCallbackAction)
+	api.POST("/login", authLimit, loginAction)
+	api.POST("/register", authLimit, registerAction)
+	api.POST("/register/reader", authLimit, registerReaderAction)
+	api.GET("/follows", getFollowsAction)
+	api.POST("/follows/:username", followBlogAction)
+	api.DELETE("/follows/:username", unfollowBlogAction)
+	api.GET("/timeline", getTimelineAction)
+	api.GET("/timeline/atom", outputTimelineFeedAction)
+	api.POST("/password/forgot", authLimit, forgotPasswordAction)
+	api.POST("/password/reset", authLimit, resetPasswordAction)
+
+	contentGroup := api.Group("/content/:username")
+	contentGroup.Use(resolveContentAPI)
+	contentGroup.GET("/articles", getContentArticlesAction)
+	contentGroup.GET("/articles/:id", getContentArticleAction)
+	contentGroup.GET("/tags", getContentTagsAction)
+	contentGroup.GET("/archives", getContentArchivesAction)
 
 	consoleGroup := api.Group("/console")
 	consoleGroup.Use(console.LoginCheck)
+	adminOnly := console.RoleCheck(model.UserRoleBlogAdmin)
+	editorOnly := console.RoleCheck(model.UserRoleBlogEditor)
 
 	if "dev" == model.Conf.RuntimeMode {
 		consoleGroup.GET("/dev/articles/gen", console.GenArticlesAction)
 	}
 
+	consoleGroup.GET("/status", console.GetStatusAction)
+	consoleGroup.GET("/api-usages", console.GetAPIUsagesAction)
 	consoleGroup.GET("/themes", console.GetThemesAction)
-	consoleGroup.PUT("/themes/:id", console.UpdateThemeAction)
+	consoleGroup.PUT("/themes/:id", adminOnly, console.UpdateThemeAction)
+	consoleGroup.POST("/themes/install", console.InstallThemeAction)
+	consoleGroup.GET("/themes/:id/settings", adminOnly, console.GetThemeSettingsAction)
+	consoleGroup.PUT("/themes/:id/settings", adminOnly, console.UpdateThemeSettingsAction)
 	consoleGroup.GET("/tags", console.GetTagsAction)
 	consoleGroup.GET("/taglist", console.GetTagsPageAction)
-	consoleGroup.DELETE("/tags/:id", console.RemoveTagsAction)
+	consoleGroup.DELETE("/tags/:id", editorOnly, console.RemoveTagsAction)
+	consoleGroup.PUT("/tags/:id", editorOnly, console.UpdateTagAction)
+	consoleGroup.POST("/tags/:id/merge", editorOnly, console.MergeTagAction)
 	consoleGroup.POST("/articles", console.AddArticleAction)
 	consoleGroup.GET("/upload/token", console.UploadTokenAction)
+	consoleGroup.POST("/upload/paste", console.PasteUploadAction)
+	consoleGroup.POST("/upload", console.UploadAction)
+	consoleGroup.GET("/media", console.GetMediaFilesAction)
+	consoleGroup.DELETE("/media/:id", console.RemoveMediaFileAction)
+	consoleGroup.PUT("/media/:id", console.ReplaceMediaFileAction)
 	consoleGroup.POST("/articles/batch-delete", console.RemoveArticlesAction)
+	consoleGroup.POST("/articles/batch-update", editorOnly, console.BatchUpdateArticlesAction)
 	consoleGroup.GET("/articles", console.GetArticlesAction)
 	consoleGroup.GET("/articles/:id", console.GetArticleAction)
 	consoleGroup.GET("/articles/:id/push", console.PushArticle2RhyAction)
+	consoleGroup.GET("/articles/:id/revisions", console.GetArticleRevisionsAction)
+	consoleGroup.GET("/articles/:id/diff", console.GetArticleDiffAction)
+	consoleGroup.POST("/articles/:id/lock", console.LockArticleAction)
+	consoleGroup.DELETE("/articles/:id/lock", console.UnlockArticleAction)
+	consoleGroup.PUT("/articles/:id/top", editorOnly, console.TopArticleAction)
 	consoleGroup.DELETE("/articles/:id", console.RemoveArticleAction)
 	consoleGroup.PUT("/articles/:id", console.UpdateArticleAction)
+	consoleGroup.GET("/trash", console.GetTrashAction)
+	consoleGroup.POST("/trash/:id/restore", console.RestoreArticleAction)
+	consoleGroup.DELETE("/trash/:id", editorOnly, console.PurgeArticleAction)
 	consoleGroup.GET("/comments", console.GetCommentsAction)
 	consoleGroup.POST("/comments/batch-delete", console.RemoveCommentsAction)
 	consoleGroup.DELETE("/comments/:id", console.RemoveCommentAction)
+	consoleGroup.PUT("/comments/:id/approve", editorOnly, console.ApproveCommentAction)
 	consoleGroup.GET("/categories", console.GetCategoriesAction)
-	consoleGroup.POST("/categories", console.AddCategoryAction)
-	consoleGroup.DELETE("/categories/:id", console.RemoveCategoryAction)
+	consoleGroup.POST("/categories", adminOnly, console.AddCategoryAction)
+	consoleGroup.DELETE("/categories/:id", adminOnly, console.RemoveCategoryAction)
 	consoleGroup.GET("/categories/:id", console.GetCategoryAction)
-	consoleGroup.PUT("/categories/:id", console.UpdateCategoryAction)
+	consoleGroup.PUT("/categories/:id", adminOnly, console.UpdateCategoryAction)
+	consoleGroup.GET("/category-defaults", console.GetCategoryDefaultsAction)
+	consoleGroup.GET("/pages", console.GetPagesAction)
+	consoleGroup.GET("/pages/:id", console.GetPageAction)
+	consoleGroup.PUT("/pages/:id", editorOnly, console.UpdatePageAction)
+	consoleGroup.POST("/pages", editorOnly, console.AddPageAction)
+	consoleGroup.DELETE("/pages/:id", editorOnly, console.RemovePageAction)
 	consoleGroup.GET("/navigations", console.GetNavigationsAction)
 	consoleGroup.GET("/navigations/:id", console.GetNavigationAction)
-	consoleGroup.PUT("/navigations/:id", console.UpdateNavigationAction)
-	consoleGroup.POST("/navigations", console.AddNavigationAction)
-	consoleGroup.DELETE("/navigations/:id", console.RemoveNavigationAction)
-	consoleGroup.GET("/users", console.GetUsersAction)
-	consoleGroup.POST("/users", console.AddUserAction)
+	consoleGroup.PUT("/navigations/:id", adminOnly, console.UpdateNavigationAction)
+	consoleGroup.POST("/navigations", adminOnly, console.AddNavigationAction)
+	consoleGroup.DELETE("/navigations/:id", adminOnly, console.RemoveNavigationAction)
+	consoleGroup.GET("/glossaries", console.GetGlossariesAction)
+	consoleGroup.GET("/glossaries/:id", console.GetGlossaryAction)
+	consoleGroup.PUT("/glossaries/:id", adminOnly, console.UpdateGlossaryAction)
+	consoleGroup.POST("/glossaries", adminOnly, console.AddGlossaryAction)
+	consoleGroup.DELETE("/glossaries/:id", adminOnly, console.RemoveGlossaryAction)
+
+	consoleGroup.GET("/redirects", adminOnly, console.GetRedirectsAction)
+	consoleGroup.POST("/redirects", adminOnly, console.AddRedirectAction)
+	consoleGroup.DELETE("/redirects/:id", adminOnly, console.RemoveRedirectAction)
+	consoleGroup.GET("/unknown-paths", adminOnly, console.GetUnknownPathsAction)
+	consoleGroup.GET("/audit", adminOnly, console.GetAuditLogsAction)
+	consoleGroup.GET("/reports", adminOnly, console.GetReportsAction)
+	consoleGroup.PUT("/reports/:id/resolve", adminOnly, console.ResolveReportAction)
+	consoleGroup.PUT("/reports/:id/dismiss", adminOnly, console.DismissReportAction)
+	consoleGroup.GET("/banned-ips", adminOnly, console.GetBannedIPsAction)
+	consoleGroup.DELETE("/banned-ips/:ip", adminOnly, console.RemoveBannedIPAction)
+	consoleGroup.GET("/jobs", adminOnly, console.GetJobsAction)
+	consoleGroup.GET("/jobs/runs", adminOnly, console.GetJobRunsAction)
+	consoleGroup.POST("/jobs/:name/run", adminOnly, console.RunJobAction)
+	consoleGroup.GET("/users", adminOnly, console.GetUsersAction)
+	consoleGroup.POST("/users", adminOnly, console.AddUserAction)
+	consoleGroup.PUT("/users/:id/role", adminOnly, console.UpdateUserRoleAction)
+	consoleGroup.GET("/invitations", adminOnly, console.GetInvitationsAction)
+	consoleGroup.POST("/invitations", adminOnly, console.AddInvitationAction)
+	consoleGroup.DELETE("/invitations/:id", adminOnly, console.RemoveInvitationAction)
+	consoleGroup.GET("/sessions", console.GetSessionsAction)
+	consoleGroup.DELETE("/sessions/:id", console.RemoveSessionAction)
 	consoleGroup.GET("/thumbs", console.GetArticleThumbsAction)
 	consoleGroup.POST("/markdown", console.MarkdownAction)
-	consoleGroup.POST("/import/md", console.ImportMarkdownAction)
+	consoleGroup.GET("/markdown/socket", console.MarkdownSocketAction)
+	consoleGroup.GET("/notifications/socket", console.NotificationSocketAction)
+	consoleGroup.GET("/diagnostics/templates", adminOnly, console.GetTemplateErrorsAction)
+	consoleGroup.POST("/import/md", editorOnly, console.ImportMarkdownAction)
+	consoleGroup.POST("/import/markdown", editorOnly, console.ImportMarkdownAction)
+	consoleGroup.POST("/import/wordpress", editorOnly, console.ImportWordPressAction)
 	consoleGroup.GET("/export/md", console.ExportMarkdownAction)
+	consoleGroup.GET("/export/markdown", console.ExportMarkdownAction)
+	consoleGroup.POST("/backup", adminOnly, console.BackupAction)
+	consoleGroup.POST("/restore", adminOnly, console.RestoreAction)
+	consoleGroup.POST("/export/static", adminOnly, exportStaticAction)
 	// consoleGroup.POST("/blogs/switch/:id", console.BlogSwitchAction)
 
-	consoleSettingsGroup := consoleGroup.Group("/settings")
+	consoleSettingsGroup := consoleGroup.Group("/settings", adminOnly)
 	consoleSettingsGroup.GET("/basic", console.GetBasicSettingsAction)
 	consoleSettingsGroup.PUT("/basic", console.UpdateBasicSettingsAction)
 	consoleSettingsGroup.GET("/preference", console.GetPreferenceSettingsAction)
@@ -139,10 +237,39 @@ func MapRoutes() *gin.Engine {
 	consoleSettingsGroup.PUT("/feed", console.UpdateFeedSettingsAction)
 	consoleSettingsGroup.GET("/third-stat", console.GetThirdStatisticSettingsAction)
 	consoleSettingsGroup.PUT("/third-stat", console.UpdateThirdStatisticSettingsAction)
+	consoleSettingsGroup.GET("/appearance", console.GetAppearanceSettingsAction)
+	consoleSettingsGroup.PUT("/appearance", console.UpdateAppearanceSettingsAction)
 	consoleSettingsGroup.GET("/ad", console.GetAdSettingsAction)
 	consoleSettingsGroup.PUT("/ad", console.UpdateAdSettingsAction)
-	consoleSettingsGroup.GET("/account", console.GetAccountAction)
-	consoleSettingsGroup.PUT("/account", console.UpdateAccountAction)
+	consoleSettingsGroup.GET("/repost", console.GetRepostSettingsAction)
+	consoleSettingsGroup.PUT("/repost", console.UpdateRepostSettingsAction)
+	consoleSettingsGroup.GET("/spam", console.GetSpamSettingsAction)
+	consoleSettingsGroup.PUT("/spam", console.UpdateSpamSettingsAction)
+	consoleSettingsGroup.GET("/newsletter", console.GetNewsletterSettingsAction)
+	consoleSettingsGroup.GET("/upload", console.GetUploadSettingsAction)
+	consoleSettingsGroup.PUT("/upload", console.UpdateUploadSettingsAction)
+	consoleSettingsGroup.PUT("/newsletter", console.UpdateNewsletterSettingsAction)
+	consoleSettingsGroup.GET("/storage", console.GetStorageSettingsAction)
+	consoleSettingsGroup.PUT("/storage", console.UpdateStorageSettingsAction)
+	consoleSettingsGroup.GET("/announcement", console.GetAnnouncementSettingsAction)
+	consoleSettingsGroup.PUT("/announcement", console.UpdateAnnouncementSettingsAction)
+	consoleSettingsGroup.GET("/profile", console.ExportSettingsProfileAction)
+	consoleSettingsGroup.POST("/profile", console.ImportSettingsProfileAction)
+
+	// settings of the current user, which are available to all roles
+	consoleAccountGroup := consoleGroup.Group("/settings")
+	consoleAccountGroup.GET("/sign/author", console.GetAuthorSignSettingsAction)
+	consoleAccountGroup.PUT("/sign/author", console.UpdateAuthorSignSettingsAction)
+	consoleAccountGroup.GET("/account", console.GetAccountAction)
+	consoleAccountGroup.PUT("/account", console.UpdateAccountAction)
+	consoleAccountGroup.PUT("/account/name", console.RenameAccountAction)
+	consoleAccountGroup.PUT("/account/locale", console.UpdateAccountLocaleAction)
+	consoleAccountGroup.GET("/security", console.GetSecuritySettingsAction)
+	consoleAccountGroup.POST("/security/totp", console.StartTOTPEnrollmentAction)
+	consoleAccountGroup.PUT("/security/totp", authLimit, console.EnableTOTPAction)
+	consoleAccountGroup.DELETE("/security/totp", authLimit, console.DisableTOTPAction)
+	consoleAccountGroup.POST("/security/backup-codes", authLimit, console.RegenerateBackupCodesAction)
+	consoleAccountGroup.POST("/security/verify", authLimit, console.VerifyTOTPAction)
 
 	ret.StaticFile(util.PathFavicon, "console/static/favicon.ico")
 	ret.StaticFile(util.PathManifest, "console/static/manifest.json")
@@ -153,53 +280,79 @@ func MapRoutes() *gin.Engine {
 	ret.StaticFile("/sw.min.js", "theme/sw.min.js")
 	ret.StaticFile("/halt.html", "theme/halt.html")
 
-	for _, theme := range theme.Themes {
-		themePath := "theme/x/" + theme
-		ret.Static("/theme/x/"+theme+"/css", themePath+"/css")
-		ret.Static("/theme/x/"+theme+"/js", themePath+"/js")
-		ret.Static("/theme/x/"+theme+"/images", themePath+"/images")
-		ret.StaticFile("/theme/x/"+theme+"/thumbnail.jpg", themePath+"/thumbnail.jpg")
-	}
-	themeTemplates, err := filepath.Glob("theme/x/*/*.html")
+	ret.GET("/theme/x/:theme/*path", serveThemeResourceAction)
+	ret.HEAD("/theme/x/:theme/*path", serveThemeResourceAction)
+	templates, err := themeTemplateFiles()
 	if nil != err {
 		logger.Fatal("load theme templates failed: " + err.Error())
 	}
-	themeTemplates = append(themeTemplates, "theme/search/index.html")
-	commentTemplates, err := filepath.Glob("theme/comment/*.html")
+	overrides, err := themeTemplateOverrides()
 	if nil != err {
-		logger.Fatal("load comment templates failed: " + err.Error())
+		logger.Fatal("load theme template overrides failed: " + err.Error())
 	}
-	headTemplates, err := filepath.Glob("theme/head/*.html")
-	if nil != err {
-		logger.Fatal("load head templates failed: " + err.Error())
+	htmlRender := newIsolatedHTMLRender(ret.FuncMap, templates, overrides)
+	ret.HTMLRender = htmlRender
+	theme.ReloadTemplates = func() error {
+		files, err := themeTemplateFiles()
+		if nil != err {
+			return err
+		}
+		overrides, err := themeTemplateOverrides()
+		if nil != err {
+			return err
+		}
+
+		return htmlRender.reload(files, overrides)
+	}
+	if "dev" == model.Conf.RuntimeMode {
+		go watchThemes()
 	}
-	templates := append(themeTemplates, commentTemplates...)
-	templates = append(templates, headTemplates...)
-	ret.LoadHTMLFiles(templates...)
 	themeGroup := ret.Group(util.PathBlogs + "/:username")
-	themeGroup.Use(fillUser, pjax, resolveBlog)
+	themeGroup.Use(checkSession, fillUser, pjax, resolveBlog)
 	themeGroup.GET("", showArticlesAction)
 	themeGroup.Any("/*path", routePath)
 
 	adminPagesGroup := ret.Group(util.PathAdmin)
-	adminPagesGroup.Use(fillUser)
+	adminPagesGroup.Use(checkSession, fillUser)
 	adminPagesGroup.GET("", console.ShowAdminPagesAction)
 	adminPagesGroup.GET("/*path", console.ShowAdminPagesAction)
 
 	indexGroup := ret.Group("")
-	indexGroup.Use(fillUser)
+	indexGroup.Use(checkSession, fillUser)
 	indexGroup.GET("", showIndexAction)
 
 	initGroup := ret.Group(util.PathInit)
-	initGroup.Use(fillUser)
+	initGroup.Use(checkSession, fillUser)
 	initGroup.GET("", showStartPageAction)
 
 	ret.Static(util.PathConsoleDist, "console/dist")
 	ret.StaticFile(util.PathChangelogs, "changelogs.html")
 	ret.StaticFile(util.PathRobots, "theme/robots.txt")
+	if "" != model.Conf.MediaDir && "" == model.Conf.MediaS3 {
+		ret.Static(util.PathMedia, model.Conf.MediaDir)
+	}
+	ret.GET(util.PathWebFinger, webFingerAction)
+	ret.GET(util.PathImages+"/:hash", showImageAction)
+	ret.GET(util.PathInvitations+"/:token", acceptInvitationAction)
 	ret.NoRoute(func(c *gin.Context) {
 		notFound(c)
 	})
+	staticRouter = ret
+
+	return ret
+}
+
+// newSessionStore returns a Redis session store if Redis is used, returns a cookie session store otherwise.
+func newSessionStore() sessions.Store {
+	pool := cache.RedisPool()
+	if nil == pool {
+		return cookie.NewStore([]byte(model.Conf.SessionSecret))
+	}
+
+	ret, err := redis.NewStoreWithPool(pool, []byte(model.Conf.SessionSecret))
+	if nil != err {
+		logger.Fatal("create Redis session store failed: " + err.Error())
+	}
 
 	return ret
 }
@@ -229,7 +382,15 @@ func routePath(c *gin.Context) {
 
 		return
 	case util.PathComments:
-		addCommentAction(c)
+		if limitRate(c, commentLimiter) {
+			addCommentAction(c)
+		}
+
+		return
+	case util.PathReports:
+		if limitRate(c, commentLimiter) {
+			addReportAction(c)
+		}
 
 		return
 	case util.PathAtom:
@@ -239,9 +400,15 @@ func routePath(c *gin.Context) {
 	case util.PathRSS:
 		outputRSSAction(c)
 
+		return
+	case util.PathJSONFeed:
+		outputJSONFeedAction(c)
+
 		return
 	case util.PathSearch:
-		searchAction(c)
+		if limitRate(c, searchLimiter) {
+			searchAction(c)
+		}
 
 		return
 	case util.PathOpensearch:
@@ -267,9 +434,45 @@ func routePath(c *gin.Context) {
 	case "/api/markdown":
 		console.MarkdownAction(c)
 
+		return
+	case "/api/comments/images":
+		uploadCommentImageAction(c)
+
+		return
+	case "/api/comments/challenge":
+		getCommentChallengeAction(c)
+
+		return
+	case util.PathSubscribe:
+		subscribeAction(c)
+
+		return
+	case util.PathSubscribe + "/confirm":
+		confirmSubscriptionAction(c)
+
+		return
+	case util.PathUnsubscribe:
+		unsubscribeAction(c)
+
+		return
+	}
+
+	if strings.HasPrefix(path, util.PathActivityPub+"/") {
+		routeActivityPub(c)
+
 		return
 	}
+	if strings.HasPrefix(path, util.PathShareImages+"/") {
+		showShareImageAction(c)
 
+		return
+	}
+	if (strings.Contains(path, util.PathTags+"/") || strings.Contains(path, util.PathCategories+"/")) &&
+		(strings.HasSuffix(path, util.PathAtom) || strings.HasSuffix(path, util.PathRSS)) {
+		outputTopicFeedAction(c)
+
+		return
+	}
 	if strings.Contains(path, util.PathArchives+"/") {
 		showArchiveArticlesAction(c)
 
@@ -307,21 +510,18 @@ func routePath(c *gin.Context) {
 		return
 	}
 
-	logger.Tracef("can't handle path [" + path + "]")
-	notFound(c)
-}
-
-func cors() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+	blogID := getBlogID(c)
+	if redirect := service.Redirect.GetRedirect(path, blogID); nil != redirect {
+		target := redirect.TargetURL
+		if strings.HasPrefix(target, "/") {
+			target = getBlogURL(c) + target
 		}
+		c.Redirect(http.StatusMovedPermanently, target)
 
-		c.Next()
+		return
 	}
+
+	service.Redirect.AddUnknownPath(path, blogID)
+	util.Log(c).Infof("can't handle path [%s]", path)
+	notFound(c)
 }
\ No newline at end of file
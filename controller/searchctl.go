@@ -72,12 +72,12 @@ func searchAction(c *gin.Context) {
 
 		authorModel := service.User.GetUser(articleModel.AuthorID)
 		if nil == authorModel {
-			logger.Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
+			util.Log(c).Errorf("not found author of article [id=%d, authorID=%d]", articleModel.ID, articleModel.AuthorID)
 
 			continue
 		}
 
-		mdResult := util.Markdown(articleModel.Content)
+		mdResult := markdown(articleModel.Content, blogID)
 		article := &model.ThemeArticle{
 			Title:    pangu.SpacingText(articleModel.Title),
 			Abstract: template.HTML(mdResult.AbstractText),
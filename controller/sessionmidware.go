@@ -0,0 +1,83 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// checkSession checks the session of logged-in requests against the session registry, sessions revoked or expired are
+// cleared so that the requests are handled as visitors. Sessions saved before the registry are registered on their
+// first request.
+func checkSession(c *gin.Context) {
+	session := util.GetSession(c)
+	if 0 == session.UID {
+		c.Next()
+
+		return
+	}
+
+	ip := util.GetRemoteAddr(c)
+	if "" == session.SID {
+		sid, err := service.UserSession.AddSession(session.UID, ip, c.Request.UserAgent())
+		if nil != err {
+			util.Log(c).Errorf("register session failed: %s", err)
+		} else {
+			session.SID = sid
+			if err := session.Save(c); nil != err {
+				util.Log(c).Errorf("saves session failed: %s", err)
+			}
+		}
+	} else if !service.UserSession.TouchSession(session.SID, session.UID, ip) {
+		util.Log(c).Infof("session of user [%s] has been revoked or expired", session.UName)
+		clearSession(c)
+	}
+
+	c.Next()
+}
+
+// secureSession marks the session cookie secure if the client requests over HTTPS, which is detected through the
+// forwarded headers of trusted reverse proxies as well.
+func secureSession(c *gin.Context) {
+	if "https" == util.GetScheme(c) {
+		sessions.Default(c).Options(sessions.Options{
+			Path:     "/",
+			MaxAge:   model.Conf.SessionMaxAge,
+			Secure:   true,
+			HttpOnly: true,
+		})
+	}
+
+	c.Next()
+}
+
+// clearSession clears the session of the specified context and expires the session cookie.
+func clearSession(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Options(sessions.Options{
+		Path:   "/",
+		MaxAge: -1,
+	})
+	session.Clear()
+	if err := session.Save(); nil != err {
+		util.Log(c).Errorf("saves session failed: %s", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+// setupTestDB connects to a migrated SQLite database in a temp directory and returns a function tearing it down.
+func setupTestDB(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "pipe-controller-")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	conf := model.Conf
+	model.Conf = &model.Configuration{SQLite: filepath.Join(dir, "pipe.db")}
+	service.ConnectDB()
+	service.Migrate()
+
+	return func() {
+		service.DisconnectDB()
+		os.RemoveAll(dir)
+		model.Conf = conf
+	}
+}
+
+func TestCheckSession(t *testing.T) {
+	defer setupTestDB(t)()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(sessions.Sessions("pipe", cookie.NewStore([]byte("secret"))))
+	engine.GET("/login", func(c *gin.Context) {
+		if err := (&util.SessionData{UID: 42, UName: "pipe"}).Save(c); nil != err {
+			t.Fatal(err)
+		}
+	})
+	engine.GET("/uid", checkSession, func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatUint(util.GetSession(c).UID, 10))
+	})
+
+	var cookies []*http.Cookie
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		if resp := recorder.Result(); 0 < len(resp.Cookies()) {
+			cookies = resp.Cookies()
+		}
+
+		return recorder
+	}
+
+	serve("/login")
+	// the first request registers the session, the following ones must find it
+	for i := 0; i < 3; i++ {
+		if recorder := serve("/uid"); "42" != recorder.Body.String() {
+			t.Fatalf("request [%d] should be logged in, actual user is [%s]", i, recorder.Body.String())
+		}
+	}
+
+	if 1 != len(service.UserSession.GetSessions(42)) {
+		t.Fatal("session should be registered once")
+	}
+	if err := service.UserSession.RemoveUserSessions(42); nil != err {
+		t.Fatal(err)
+	}
+	if recorder := serve("/uid"); "0" != recorder.Body.String() {
+		t.Errorf("revoked session should be cleared, actual user is [%s]", recorder.Body.String())
+	}
+}
@@ -0,0 +1,253 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/theme"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// staticExportKey is the request context key of the *staticExport of requests issued by the static site exporter. It
+// can't be set by clients, such requests are not counted as views and bypass the page cache.
+type staticExportKey struct{}
+
+// staticExport collects the rendering result of a page for the static site exporter.
+type staticExport struct {
+	PageCount int // page count of the paginated list rendered, 0 if the page is not paginated
+}
+
+// getStaticExport returns the static export of the current request, returns nil if the request is not issued by the
+// static site exporter.
+func getStaticExport(c *gin.Context) *staticExport {
+	ret, _ := c.Request.Context().Value(staticExportKey{}).(*staticExport)
+
+	return ret
+}
+
+// collectStaticExport collects the pagination of the rendered page for the static site exporter.
+func collectStaticExport(c *gin.Context) {
+	export := getStaticExport(c)
+	if nil == export {
+		return
+	}
+
+	dataModelVal, _ := c.Get("dataModel")
+	if dataModel, ok := dataModelVal.(*DataModel); ok {
+		if pagination, ok := (*dataModel)["Pagination"].(*util.Pagination); ok {
+			export.PageCount = pagination.PageCount
+		}
+	}
+}
+
+// pageLinkRegexp matches the links of the paginated pages, e.g. href="?p=2".
+var pageLinkRegexp = regexp.MustCompile(`href="\?p=(\d+)"`)
+
+// staticRouter is the router used to render pages of static site export.
+var staticRouter http.Handler
+
+// ExportStatic renders all public pages of the specified blog through the theme templates into static files under the
+// specified directory. Absolute URLs of the blog and static resources are rewritten to the specified base URL if it's
+// not empty. Returns the count of exported pages.
+func ExportStatic(router http.Handler, blogID uint64, dir, baseURL string) (count int, err error) {
+	admin := service.User.GetBlogAdmin(blogID)
+	if nil == admin {
+		return 0, errors.New("not found admin of blog [" + strconv.FormatUint(blogID, 10) + "]")
+	}
+	blogURL := service.Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID).Value
+	themeName := theme.Resolve(service.Setting.GetSetting(model.SettingCategoryTheme, model.SettingNameThemeName, blogID).Value)
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	paths := []string{"", util.PathArchives, util.PathAuthors, util.PathCategories, util.PathTags, util.PathAtom, util.PathRSS}
+	paths = append(paths, service.Article.GetPublishedArticlePaths(blogID)...)
+	for _, archive := range service.Archive.GetGranularArchives(blogID) {
+		paths = append(paths, archivePath(archive))
+	}
+	for _, tag := range service.Tag.GetTags(math.MaxInt32, blogID) {
+		paths = append(paths, util.PathTags+"/"+tag.Title)
+	}
+	for _, category := range service.Category.GetCategories(math.MaxInt32, blogID) {
+		paths = append(paths, util.PathCategories+category.Path)
+	}
+	for page, pageCount := 1, 1; page <= pageCount; page++ {
+		users, pagination := service.User.GetBlogUsers(page, blogID)
+		for _, user := range users {
+			paths = append(paths, util.PathAuthors+"/"+user.Name)
+		}
+		pageCount = pagination.PageCount
+	}
+
+	for _, path := range paths {
+		// paginated pages are exported to {path}/p/{page}/index.html
+		for page, pageCount := 1, 1; page <= pageCount; page++ {
+			requestURI := util.PathBlogs + "/" + admin.Name + (&url.URL{Path: path}).EscapedPath()
+			if 1 < page {
+				requestURI += "?p=" + strconv.Itoa(page)
+			}
+			export := &staticExport{}
+			request, _ := http.NewRequest(http.MethodGet, requestURI, nil)
+			request = request.WithContext(context.WithValue(request.Context(), staticExportKey{}, export))
+			request.RequestURI = requestURI
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if http.StatusOK != recorder.Code {
+				logger.Warnf("export page [%s] failed: status code [%d]", requestURI, recorder.Code)
+
+				break
+			}
+			pageCount = export.PageCount
+
+			content := recorder.Body.String()
+			content = pageLinkRegexp.ReplaceAllStringFunc(content, func(link string) string {
+				num := pageLinkRegexp.FindStringSubmatch(link)[1]
+				if "1" == num {
+					return `href="` + blogURL + path + `/"`
+				}
+
+				return `href="` + blogURL + path + "/p/" + num + `/"`
+			})
+			if "" != baseURL {
+				content = strings.Replace(content, blogURL, baseURL, -1)
+				content = strings.Replace(content, model.Conf.StaticServer+util.PathTheme, baseURL+util.PathTheme, -1)
+			}
+
+			filename := filepath.Join(dir, filepath.FromSlash(path), "index.html")
+			if 1 < page {
+				filename = filepath.Join(dir, filepath.FromSlash(path), "p", strconv.Itoa(page), "index.html")
+			}
+			switch path {
+			case util.PathAtom:
+				filename = filepath.Join(dir, "atom.xml")
+			case util.PathRSS:
+				filename = filepath.Join(dir, "rss.xml")
+			}
+			if err = os.MkdirAll(filepath.Dir(filename), 0755); nil != err {
+				return
+			}
+			if err = ioutil.WriteFile(filename, []byte(content), 0644); nil != err {
+				return
+			}
+			count++
+		}
+	}
+
+	// copies static resources of the theme
+	for _, resource := range []string{"theme/js", "theme/images", "theme/x/" + themeName + "/css",
+		"theme/x/" + themeName + "/js", "theme/x/" + themeName + "/images"} {
+		if !gulu.File.IsExist(resource) {
+			continue
+		}
+		if err = copyDir(resource, filepath.Join(dir, filepath.FromSlash(resource))); nil != err {
+			return
+		}
+	}
+
+	return
+}
+
+func copyDir(source, dest string) error {
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if nil != err {
+			return err
+		}
+
+		target := filepath.Join(dest, strings.TrimPrefix(path, source))
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if nil != err {
+			return err
+		}
+
+		return ioutil.WriteFile(target, data, 0644)
+	})
+}
+
+func exportStaticAction(c *gin.Context) {
+	result := util.NewResult(c)
+
+	session := util.GetSession(c)
+	if model.UserRoleBlogAdmin < session.URole {
+		result.Code = util.CodeErr
+		result.Msg = "only blog admin can export static site"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+
+	tempDir := os.TempDir()
+	exportPath := filepath.Join(tempDir, session.UName+"-export-static")
+	if err := os.RemoveAll(exportPath); nil != err {
+		util.Log(c).Errorf("remove temp dir [%s] failed: %s", exportPath, err)
+		result.Code = util.CodeErr
+		result.Msg = "remove temp dir failed"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+	count, err := ExportStatic(staticRouter, session.BID, exportPath, c.Query("base"))
+	if nil != err {
+		util.Log(c).Errorf("export static site failed: %s", err)
+		result.Code = util.CodeErr
+		result.Msg = "export static site failed"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+	util.Log(c).Infof("exported [%d] pages of blog [%d]", count, session.BID)
+
+	zipFilePath := exportPath + ".zip"
+	zipFile, err := gulu.Zip.Create(zipFilePath)
+	if nil != err {
+		util.Log(c).Errorf("create zip file [%s] failed: %s", zipFilePath, err)
+		result.Code = util.CodeErr
+		result.Msg = "create zip file failed"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+	zipFile.AddDirectory(session.UName+"-export-static", exportPath)
+	if err := zipFile.Close(); nil != err {
+		util.Log(c).Errorf("zip failed: %s", err)
+		result.Code = util.CodeErr
+		result.Msg = "zip failed"
+		c.JSON(http.StatusOK, result)
+
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+session.UName+"-export-static.zip")
+	c.File(zipFilePath)
+}
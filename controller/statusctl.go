@@ -19,7 +19,7 @@ package controller
 import (
 	"net/http"
 
-	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/i18n"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
@@ -38,10 +38,14 @@ type Status struct {
 	BlogURL   string              `json:"blogURL"`
 	Role      int                 `json:"role"`
 	Blogs     []*service.UserBlog `json:"blogs"`
+
+	TOTPRequired bool `json:"totpRequired"` // the second factor of the session has not been verified
+
+	Announcement string `json:"announcement"` // rendered platform announcement, empty if not in its time window
 }
 
 func getStatusAction(c *gin.Context) {
-	result := gulu.Ret.NewResult()
+	result := util.NewResult(c)
 	defer c.JSON(http.StatusOK, result)
 
 	platformStatus, err := service.Init.Status()
@@ -52,8 +56,12 @@ func getStatusAction(c *gin.Context) {
 		return
 	}
 
+	if locale := util.NegotiateLocale(c.GetHeader("Accept-Language"), i18n.GetLocalesNames()); "" != locale {
+		platformStatus.Locale = locale
+	}
 	data := &Status{
 		PlatformStatus: platformStatus,
+		Announcement:   string(getAnnouncement()),
 	}
 
 	session := util.GetSession(c)
@@ -67,16 +75,20 @@ func getStatusAction(c *gin.Context) {
 			})
 			session.Clear()
 			if err := session.Save(); nil != err {
-				logger.Errorf("saves session failed: " + err.Error())
+				util.Log(c).Errorf("saves session failed: %s", err)
 			}
 
 			return
 		}
 
+		if util.IsLocale(user.Locale) {
+			data.Locale = user.Locale
+		}
 		data.Name = user.Name
 		data.Nickname = user.Nickname
 		data.AvatarURL = user.AvatarURL
 		data.Role = model.UserRoleBlogAdmin
+		data.TOTPRequired = user.TOTPEnabled && !session.UTOTP
 
 		if model.UserRoleNoLogin != session.URole && platformStatus.Inited {
 			ownBlog := service.User.GetOwnBlog(user.ID)
@@ -0,0 +1,101 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+	"github.com/gin-gonic/gin"
+)
+
+// subscribeAction subscribes the blog newsletter, the confirmation link is mailed to the subscriber for double opt-in.
+func subscribeAction(c *gin.Context) {
+	result := util.NewResult(c)
+	defer c.JSON(http.StatusOK, result)
+
+	if http.MethodPost != c.Request.Method {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "method not allowed"
+
+		return
+	}
+	if ok, _ := contentAPILimiter.allow(util.GetRemoteAddr(c)); !ok {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeRateLimited
+		result.Msg = "too many requests"
+
+		return
+	}
+	blogID := getBlogID(c)
+	if !service.Newsletter.Enabled(blogID) {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeForbidden
+		result.Msg = "newsletter is disabled"
+
+		return
+	}
+
+	arg := map[string]interface{}{}
+	if err := c.BindJSON(&arg); nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = "parses subscribe request failed"
+
+		return
+	}
+	email, _ := arg["email"].(string)
+	subscriber, err := service.Newsletter.Subscribe(email, blogID)
+	if nil != err {
+		result.Code = util.CodeErr
+		result.ErrCode = util.ErrCodeBadRequest
+		result.Msg = err.Error()
+
+		return
+	}
+	if !subscriber.Confirmed {
+		go service.Newsletter.SendConfirmation(subscriber)
+	}
+
+	// confirmed subscribers get the same notice so that subscriptions are not disclosed
+	result.Msg = i18n.GetMessage(getLocale(c), "subscribeConfirmationSent")
+}
+
+// confirmSubscriptionAction confirms a subscription by the token in the confirmation link.
+func confirmSubscriptionAction(c *gin.Context) {
+	if _, err := service.Newsletter.Confirm(c.Query("token")); nil != err {
+		c.String(http.StatusNotFound, i18n.GetMessage(getLocale(c), "subscriptionInvalid"))
+
+		return
+	}
+
+	c.String(http.StatusOK, i18n.GetMessage(getLocale(c), "subscriptionConfirmed"))
+}
+
+// unsubscribeAction cancels a subscription by the token in the unsubscribe link of newsletters.
+func unsubscribeAction(c *gin.Context) {
+	if err := service.Newsletter.Unsubscribe(c.Query("token")); nil != err {
+		c.String(http.StatusNotFound, i18n.GetMessage(getLocale(c), "subscriptionInvalid"))
+
+		return
+	}
+
+	c.String(http.StatusOK, i18n.GetMessage(getLocale(c), "subscriptionCancelled"))
+}
@@ -0,0 +1,230 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
+	"github.com/gin-gonic/gin/render"
+)
+
+// isolatedHTMLRender renders theme templates into a buffer first, so a template error renders a visible placeholder
+// instead of a blank 500 page. Templates are reloaded by the theme watcher on changes in dev mode. Blogs with theme
+// template overrides are rendered with their own templates, which are chosen by the BlogID of the data model.
+type isolatedHTMLRender struct {
+	files   []string
+	funcMap template.FuncMap
+
+	template      *template.Template
+	blogTemplates map[uint64]*template.Template // blog ID -> templates with the overrides of the blog
+	lock          *sync.RWMutex
+}
+
+func newIsolatedHTMLRender(funcMap template.FuncMap, files []string, overrides map[uint64][]string) *isolatedHTMLRender {
+	ret := &isolatedHTMLRender{funcMap: funcMap, lock: &sync.RWMutex{}}
+	if err := ret.reload(files, overrides); nil != err {
+		panic(err)
+	}
+
+	return ret
+}
+
+func (r *isolatedHTMLRender) load(files []string) (*template.Template, error) {
+	var ret *template.Template
+	funcMap := template.FuncMap{}
+	for name, f := range r.funcMap {
+		funcMap[name] = f
+	}
+	// include renders the specified template in isolation: {{include "define-footer" .}}
+	funcMap["include"] = func(name string, data interface{}) template.HTML {
+		buf := &bytes.Buffer{}
+		if err := ret.ExecuteTemplate(buf, name, data); nil != err {
+			theme.AddRenderError(name, err)
+
+			return renderErrorPlaceholder(name, err)
+		}
+
+		return template.HTML(buf.String())
+	}
+
+	ret, err := template.New("").Funcs(funcMap).ParseFiles(files...)
+
+	return ret, err
+}
+
+// reload reloads templates from the specified files and the specified template overrides of blogs, the loaded
+// templates are kept if any of the files fails to parse. A blog whose overrides fail to parse is rendered with the
+// stock templates.
+func (r *isolatedHTMLRender) reload(files []string, overrides map[uint64][]string) error {
+	tpl, err := r.load(files)
+	if nil != err {
+		return err
+	}
+
+	blogTemplates := map[uint64]*template.Template{}
+	for blogID, blogOverrides := range overrides {
+		blogTemplate, err := r.load(overrideThemeTemplates(files, blogOverrides))
+		if nil != err {
+			theme.AddRenderError("template overrides of blog ["+strconv.FormatUint(blogID, 10)+"]", err)
+
+			continue
+		}
+		blogTemplates[blogID] = blogTemplate
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.files = files
+	r.template = tpl
+	r.blogTemplates = blogTemplates
+
+	return nil
+}
+
+func (r *isolatedHTMLRender) Instance(name string, data interface{}) render.Render {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	tpl := r.template
+	if dataModel, ok := data.(DataModel); ok {
+		if blogTemplate := r.blogTemplates[getDataModelBlogID(dataModel)]; nil != blogTemplate {
+			tpl = blogTemplate
+		}
+	}
+
+	return &isolatedHTML{template: tpl, name: name, data: data}
+}
+
+func getDataModelBlogID(dataModel DataModel) uint64 {
+	blogID, _ := dataModel["BlogID"].(uint64)
+
+	return blogID
+}
+
+type isolatedHTML struct {
+	template *template.Template
+	name     string
+	data     interface{}
+}
+
+func (r *isolatedHTML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	buf := &bytes.Buffer{}
+	if err := r.template.ExecuteTemplate(buf, r.name, r.data); nil != err {
+		theme.AddRenderError(r.name, err)
+		buf.WriteString(string(renderErrorPlaceholder(r.name, err)))
+	}
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+func (r *isolatedHTML) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{"text/html; charset=utf-8"}
+	}
+}
+
+func renderErrorPlaceholder(name string, err error) template.HTML {
+	msg := "Failed to render [" + name + "]"
+	if "dev" == model.Conf.RuntimeMode {
+		msg += ": " + err.Error()
+	}
+
+	return template.HTML(`<div class="pipe-render-error" style="padding:8px;border:1px dashed #d23f31;color:#d23f31">` +
+		template.HTMLEscapeString(msg) + `</div>`)
+}
+
+// themeTemplateFiles returns template files of all themes and the templates they share.
+func themeTemplateFiles() (ret []string, err error) {
+	for _, pattern := range []string{"theme/x/*/*.html", "theme/comment/*.html", "theme/subscribe/*.html", "theme/article/*.html", "theme/head/*.html"} {
+		files, err := filepath.Glob(pattern)
+		if nil != err {
+			return nil, err
+		}
+		if "theme/x/*/*.html" == pattern {
+			files = append(files, "theme/search/index.html")
+		}
+		ret = append(ret, files...)
+	}
+
+	return
+}
+
+// themeTemplateOverrides returns theme template overrides of blogs, which are under theme/custom/{blogID}/{theme}/.
+// Overrides of themes not installed are skipped.
+func themeTemplateOverrides() (ret map[uint64][]string, err error) {
+	files, err := filepath.Glob("theme/custom/*/*/*.html")
+	if nil != err {
+		return nil, err
+	}
+
+	ret = map[uint64][]string{}
+	for _, file := range files {
+		themeDir := filepath.Dir(file)
+		blogID, parseErr := strconv.ParseUint(filepath.Base(filepath.Dir(themeDir)), 10, 64)
+		if nil != parseErr || !isTheme(filepath.Base(themeDir)) {
+			continue
+		}
+		ret[blogID] = append(ret[blogID], file)
+	}
+
+	return
+}
+
+// overrideThemeTemplates replaces the specified template files with the specified overrides of the same theme and
+// name ({theme}/{template}), so users can customize a few templates of a theme and still upgrade the theme. Overrides
+// which only exist in the override directory are appended.
+func overrideThemeTemplates(files, overrides []string) (ret []string) {
+	overrideFiles := map[string]string{}
+	for _, override := range overrides {
+		overrideFiles[themeTemplateName(override)] = override
+	}
+
+	overridden := map[string]bool{}
+	for _, file := range files {
+		name := themeTemplateName(file)
+		if override, ok := overrideFiles[name]; ok {
+			logger.Debugf("template [%s] is overridden by [%s]", file, override)
+			file = override
+			overridden[name] = true
+		}
+		ret = append(ret, file)
+	}
+	for _, override := range overrides {
+		if !overridden[themeTemplateName(override)] {
+			ret = append(ret, override)
+		}
+	}
+
+	return
+}
+
+// themeTemplateName returns {theme}/{template} of the specified template file.
+func themeTemplateName(file string) string {
+	return filepath.Base(filepath.Dir(file)) + "/" + filepath.Base(file)
+}
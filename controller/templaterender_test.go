@@ -0,0 +1,83 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"html/template"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOverrideThemeTemplates(t *testing.T) {
+	files := []string{"theme/x/Gina/index.html", "theme/x/Gina/article.html", "theme/x/Littlewin/index.html", "theme/comment/comment.html"}
+	overrides := []string{"theme/custom/1/Gina/index.html", "theme/custom/1/Gina/footer.html"}
+
+	expected := []string{"theme/custom/1/Gina/index.html", "theme/x/Gina/article.html", "theme/x/Littlewin/index.html",
+		"theme/comment/comment.html", "theme/custom/1/Gina/footer.html"}
+	if ret := overrideThemeTemplates(files, overrides); !reflect.DeepEqual(expected, ret) {
+		t.Errorf("expected is [%v], actual is [%v]", expected, ret)
+	}
+	if ret := overrideThemeTemplates(files, nil); !reflect.DeepEqual(files, ret) {
+		t.Errorf("expected is [%v], actual is [%v]", files, ret)
+	}
+}
+
+func TestIsolatedHTMLRenderOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipe-templates-")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); nil != err {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); nil != err {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+	files := []string{write("x/Gina/index.html", `{{define "Gina/index.html"}}stock{{end}}`)}
+	overrides := map[uint64][]string{1: {write("custom/1/Gina/index.html", `{{define "Gina/index.html"}}custom{{end}}`)}}
+
+	htmlRender := newIsolatedHTMLRender(template.FuncMap{}, files, overrides)
+	cases := []struct {
+		data     interface{}
+		expected string
+	}{
+		{DataModel{"BlogID": uint64(1)}, "custom"},
+		{DataModel{"BlogID": uint64(2)}, "stock"},
+		{DataModel{}, "stock"},
+		{nil, "stock"},
+	}
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		if err := htmlRender.Instance("Gina/index.html", c.data).Render(recorder); nil != err {
+			t.Fatal(err)
+		}
+		if c.expected != recorder.Body.String() {
+			t.Errorf("data [%v] expected is [%s], actual is [%s]", c.data, c.expected, recorder.Body.String())
+		}
+	}
+}
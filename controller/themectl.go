@@ -0,0 +1,53 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/b3log/pipe/theme"
+	"github.com/gin-gonic/gin"
+)
+
+// serveThemeResourceAction serves styles, scripts, images and the thumbnail of themes, including themes installed
+// after start. Templates and other files of themes are not served.
+func serveThemeResourceAction(c *gin.Context) {
+	themeName := c.Param("theme")
+	resource := path.Clean(c.Param("path"))
+	if !isTheme(themeName) || ("/thumbnail.jpg" != resource && !strings.HasPrefix(resource, "/css/") &&
+		!strings.HasPrefix(resource, "/js/") && !strings.HasPrefix(resource, "/images/")) {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+
+	c.File(filepath.Join("theme", "x", themeName, filepath.FromSlash(resource)))
+}
+
+// isTheme checks whether the specified name is a loaded theme.
+func isTheme(name string) bool {
+	for _, themeName := range theme.Themes {
+		if name == themeName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
+	"github.com/b3log/pipe/util"
+)
+
+// themeWatchInterval is the interval of polling theme files for changes in dev mode.
+const themeWatchInterval = time.Second
+
+// themeFilesStamp identifies a state of a group of theme files, it changes if any of the files is added, removed or
+// modified.
+type themeFilesStamp struct {
+	count   int
+	modTime int64
+	size    int64
+}
+
+func (stamp *themeFilesStamp) add(info os.FileInfo) {
+	stamp.count++
+	stamp.size += info.Size()
+	if modTime := info.ModTime().UnixNano(); stamp.modTime < modTime {
+		stamp.modTime = modTime
+	}
+}
+
+// watchThemes polls theme files in dev mode and applies changes without restarting the server. Templates and
+// shortcodes of themes are reloaded and the static resource version is bumped so that browsers fetch changed assets.
+func watchThemes() {
+	templates, shortcodes, assets := stampThemeFiles()
+	for range time.Tick(themeWatchInterval) {
+		newTemplates, newShortcodes, newAssets := stampThemeFiles()
+		if templates != newTemplates {
+			templates = newTemplates
+			if err := theme.ReloadTemplates(); nil != err {
+				theme.AddRenderError("theme templates", err)
+			} else {
+				logger.Infof("reloaded theme templates")
+			}
+		}
+		if shortcodes != newShortcodes {
+			shortcodes = newShortcodes
+			theme.LoadShortcodes()
+			util.PurgeMarkdownCache()
+			logger.Infof("reloaded theme shortcodes")
+		}
+		if assets != newAssets {
+			assets = newAssets
+			model.Conf.StaticResourceVersion = strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+			logger.Infof("theme assets changed, static resource version is [%s] now", model.Conf.StaticResourceVersion)
+		}
+	}
+}
+
+// stampThemeFiles stamps templates, shortcodes and assets (styles, scripts and images) under the theme directory.
+func stampThemeFiles() (templates, shortcodes, assets themeFilesStamp) {
+	filepath.Walk("theme", func(path string, info os.FileInfo, err error) error {
+		if nil != err {
+			return nil
+		}
+		if info.IsDir() {
+			if "node_modules" == info.Name() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		path = filepath.ToSlash(path)
+		switch {
+		case strings.Contains(path, "/shortcodes/"):
+			shortcodes.add(info)
+		case strings.HasSuffix(path, ".html"):
+			templates.add(info)
+		case strings.Contains(path, "/css/") || strings.Contains(path, "/js/") || strings.Contains(path, "/images/") ||
+			strings.HasPrefix(path, "theme/scss/"):
+			assets.add(info)
+		}
+
+		return nil
+	})
+
+	return
+}
@@ -0,0 +1,73 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+)
+
+// WarmUp pre-renders the platform index, the blog indexes and the most viewed articles (specified by
+// Conf.WarmupSize) through the specified router, so that the page, article and template caches are populated before
+// visitors come after restarts. Warmup requests carry no user agent, so they are not counted as views.
+func WarmUp(router http.Handler) {
+	defer gulu.Panic.Recover(nil)
+
+	if 1 > model.Conf.WarmupSize || !service.Init.Inited() {
+		return
+	}
+
+	start := time.Now()
+	paths := []string{"/"}
+	blogPaths := map[uint64]string{}
+	for _, article := range service.Article.GetPlatformMostViewArticles(model.Conf.WarmupSize) {
+		blogPath, ok := blogPaths[article.BlogID]
+		if !ok {
+			if admin := service.User.GetBlogAdmin(article.BlogID); nil != admin {
+				blogPath = util.PathBlogs + "/" + admin.Name
+				paths = append(paths, blogPath)
+			}
+			blogPaths[article.BlogID] = blogPath
+		}
+		if "" == blogPath {
+			continue
+		}
+		paths = append(paths, blogPath+(&url.URL{Path: article.Path}).EscapedPath())
+	}
+
+	count := 0
+	for _, path := range paths {
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		request.RequestURI = path
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if http.StatusOK != recorder.Code {
+			logger.Debugf("warm up page [%s] failed: status code [%d]", path, recorder.Code)
+
+			continue
+		}
+		count++
+	}
+	logger.Infof("warmed up [%d/%d] pages in [%s]", count, len(paths), time.Since(start))
+}
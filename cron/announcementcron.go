@@ -0,0 +1,39 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/service"
+)
+
+// newAnnouncementRefresher returns a job which purges cached pages once the platform announcement starts or ends, so
+// that visitors see it during its time window.
+func newAnnouncementRefresher() func() error {
+	active := service.Announcement.GetActiveAnnouncement(time.Now())
+
+	return func() error {
+		if current := service.Announcement.GetActiveAnnouncement(time.Now()); current != active {
+			active = current
+			cache.Page.PurgeAll()
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+	"github.com/b3log/pipe/util"
+)
+
+// backupBlogs backs up all blogs, returns the last error so that the job is retried.
+func backupBlogs() (err error) {
+	for _, blogID := range service.Backup.GetBlogIDs() {
+		name, data, e := service.Backup.Archive(blogID)
+		if nil != e {
+			err = e
+			logger.Errorf("backup blog [%d] failed: %s", blogID, err)
+
+			continue
+		}
+
+		if "" != model.Conf.BackupDir {
+			if e = os.MkdirAll(model.Conf.BackupDir, 0755); nil != e {
+				err = e
+				logger.Errorf("make backup dir [%s] failed: %s", model.Conf.BackupDir, err)
+			} else if e = ioutil.WriteFile(filepath.Join(model.Conf.BackupDir, name), data, 0644); nil != e {
+				err = e
+				logger.Errorf("write backup [%s] failed: %s", name, err)
+			}
+		}
+		if "" != model.Conf.BackupS3 {
+			if e = util.S3PutObject(model.Conf.BackupS3, name, data); nil != e {
+				err = e
+				logger.Errorf("upload backup [%s] to S3 failed: %s", name, err)
+			}
+		}
+
+		logger.Infof("backed up blog [%d] to [%s]", blogID, name)
+	}
+
+	return
+}
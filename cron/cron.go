@@ -18,17 +18,38 @@
 package cron
 
 import (
-	"os"
+	"time"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
-// Start starts all cron tasks.
+// Start registers all cron tasks as jobs and starts scheduling them.
 func Start() {
-	refreshRecommendArticlesPeriodically()
-	pushArticlesPeriodically()
-	pushCommentsPeriodically()
+	service.Job.Register(&service.JobSpec{Name: "refresh-recommend-articles", Interval: 30 * time.Minute, RunAtStart: true, Run: refreshRecommendArticles})
+	service.Job.Register(&service.JobSpec{Name: "push-articles", Interval: 30 * time.Second, RunAtStart: true, Run: pushArticles})
+	service.Job.Register(&service.JobSpec{Name: "push-comments", Interval: 30 * time.Second, RunAtStart: true, Run: pushComments})
+	service.Job.Register(&service.JobSpec{Name: "repost-articles", Interval: 10 * time.Minute, RunAtStart: true, Run: repostArticles})
+	service.Job.Register(&service.JobSpec{Name: "publish-scheduled-articles", Interval: time.Minute, Run: newScheduledArticlesPublisher()})
+	service.Job.Register(&service.JobSpec{Name: "flush-views", Interval: time.Minute, Run: flushViews})
+	service.Job.Register(&service.JobSpec{Name: "send-newsletters", Interval: time.Hour, Run: sendNewsletters})
+	service.Job.Register(&service.JobSpec{Name: "archive-links", Interval: 10 * time.Minute, Run: archiveLinks})
+	service.Job.Register(&service.JobSpec{Name: "refresh-announcement", Interval: time.Minute, Run: newAnnouncementRefresher()})
+	if "" != model.Conf.BackupDir || "" != model.Conf.BackupS3 {
+		interval := model.Conf.BackupInterval
+		if 1 > interval {
+			interval = 24
+		}
+		service.Job.Register(&service.JobSpec{Name: "backup-blogs", Interval: time.Duration(interval) * time.Hour, Retries: 2, Run: backupBlogs})
+	}
+	if 0 < model.Conf.IPRetention || 0 < model.Conf.AnalyticsRetention || 0 < model.Conf.TrashRetention {
+		service.Job.Register(&service.JobSpec{Name: "apply-retention", Interval: 6 * time.Hour, RunAtStart: true, Run: applyRetention})
+	}
+
+	service.Job.Start()
 }
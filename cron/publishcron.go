@@ -0,0 +1,51 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/service"
+)
+
+// newScheduledArticlesPublisher returns a job which announces the scheduled articles published since its last run:
+// purges the cached pages and pings the WebSub hubs of their blogs.
+func newScheduledArticlesPublisher() func() error {
+	last := time.Now()
+
+	return func() error {
+		now := time.Now()
+		articles := service.Article.GetScheduledArticles(last, now)
+		last = now
+		if 1 > len(articles) {
+			return nil
+		}
+
+		cache.Page.PurgeAll()
+		blogIDs := map[uint64]bool{}
+		for _, article := range articles {
+			if !blogIDs[article.BlogID] {
+				blogIDs[article.BlogID] = true
+				service.WebSub.EnqueuePublish(article.BlogID)
+			}
+		}
+		logger.Infof("published [%d] scheduled articles", len(articles))
+
+		return nil
+	}
+}
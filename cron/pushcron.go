@@ -21,53 +21,30 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/util"
 	"github.com/parnurzeal/gorequest"
 )
 
-func pushArticlesPeriodically() {
-	go pushArticles()
-
-	go func() {
-		for range time.Tick(time.Second * 30) {
-			pushArticles()
-		}
-	}()
-}
-
-func pushArticles() {
-	defer gulu.Panic.Recover(nil)
-
+func pushArticles() error {
 	server, _ := url.Parse(model.Conf.Server)
 	if !util.IsDomain(server.Hostname()) {
-		return
+		return nil
 	}
 
 	articles := service.Article.GetUnpushedArticles()
 	for _, article := range articles {
 		service.Article.ConsolePushArticle(article)
 	}
-}
-
-func pushCommentsPeriodically() {
-	go pushComments()
 
-	go func() {
-		for range time.Tick(time.Second * 30) {
-			pushComments()
-		}
-	}()
+	return nil
 }
 
-func pushComments() {
-	defer gulu.Panic.Recover(nil)
-
+func pushComments() error {
 	server, _ := url.Parse(model.Conf.Server)
 	if !util.IsDomain(server.Hostname()) {
-		return
+		return nil
 	}
 
 	comments := service.Comment.GetUnpushedComments()
@@ -104,7 +81,7 @@ func pushComments() {
 			Set("user-agent", model.UserAgent).Timeout(30*time.Second).
 			Retry(3, 5*time.Second).EndStruct(result)
 		if nil != errs {
-			logger.Errorf("push a comment to Rhy failed: " + errs[0].Error())
+			logger.Errorf("push a comment to Rhy failed: %s", errs[0])
 		} else {
 			logger.Infof("push a comment to Rhy result: %+v", result)
 		}
@@ -112,4 +89,6 @@ func pushComments() {
 
 		service.Comment.UpdatePushedAt(comment)
 	}
+
+	return nil
 }
@@ -17,8 +17,6 @@
 package cron
 
 import (
-	"time"
-
 	"github.com/b3log/gulu"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
@@ -29,19 +27,7 @@ import (
 // RecommendArticles saves all recommend articles.
 var RecommendArticles []*model.ThemeArticle
 
-func refreshRecommendArticlesPeriodically() {
-	go refreshRecommendArticles()
-
-	go func() {
-		for range time.Tick(time.Minute * 30) {
-			refreshRecommendArticles()
-		}
-	}()
-}
-
-func refreshRecommendArticles() {
-	defer gulu.Panic.Recover(nil)
-
+func refreshRecommendArticles() error {
 	size := 7
 	articles := service.Article.GetPlatMostViewArticles(size)
 	size = len(articles)
@@ -78,4 +64,6 @@ func refreshRecommendArticles() {
 	}
 
 	RecommendArticles = recommendations
+
+	return nil
 }
@@ -0,0 +1,45 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service"
+)
+
+func applyRetention() error {
+	now := time.Now()
+	if 0 < model.Conf.AnalyticsRetention {
+		service.Redirect.ExpireUnknownPaths(now.AddDate(0, 0, -model.Conf.AnalyticsRetention))
+	}
+	if 0 < model.Conf.IPRetention {
+		count := service.Retention.AnonymizeIPs(now.AddDate(0, 0, -model.Conf.IPRetention),
+			model.Conf.IPAnonymization, model.Conf.SessionSecret)
+		if 0 < count {
+			logger.Infof("anonymized IPs of [%d] records", count)
+		}
+	}
+	if 0 < model.Conf.TrashRetention {
+		if count := service.Article.PurgeTrash(now.AddDate(0, 0, -model.Conf.TrashRetention)); 0 < count {
+			logger.Infof("purged [%d] articles from the trash", count)
+		}
+	}
+
+	return nil
+}
@@ -26,10 +26,11 @@ import (
 	"strings"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 type locale struct {
 	Name     string
@@ -0,0 +1,244 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package log defines the output of loggers, which outputs lines of gulu loggers as is or JSON objects.
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Log formats.
+const (
+	FormatText = "text" // lines of gulu loggers, the default
+	FormatJSON = "json" // one JSON object per line with time, level, caller, message and fields
+)
+
+// Log levels ordered by severity.
+const (
+	LevelTrace = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+// levelNames are names of the log levels indexed by level.
+var levelNames = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// Writer is the output of all loggers. It writes through in text format and rewrites log lines as JSON objects in JSON
+// format.
+var Writer = &writer{out: os.Stdout, level: LevelDebug, mutex: &sync.Mutex{}}
+
+type writer struct {
+	out   io.Writer
+	json  bool
+	level int
+	mutex *sync.Mutex
+}
+
+// Set sets the format and the level of the output.
+func Set(format, level string) {
+	Writer.mutex.Lock()
+	defer Writer.mutex.Unlock()
+
+	Writer.json = FormatJSON == strings.ToLower(format)
+	for i, name := range levelNames {
+		if strings.EqualFold(name, level) {
+			Writer.level = i
+		}
+	}
+}
+
+// gulu loggers prefix lines with the level initial, the date time and the caller: "I 2019/10/01 12:00:00 file.go:42: msg"
+var lineRegexp = regexp.MustCompile(`(?s)^([TDIWEF]) (\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) (\S+?:\d+): (.*?)\n?$`)
+
+var levelInitials = map[string]string{"T": "trace", "D": "debug", "I": "info", "W": "warn", "E": "error", "F": "fatal"}
+
+func (w *writer) Write(p []byte) (int, error) {
+	requestID := currentRequestID()
+	if !w.json {
+		line := p
+		if "" != requestID {
+			line = prefixRequestID(p, requestID)
+		}
+
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		if _, err := w.out.Write(line); nil != err {
+			return 0, err
+		}
+
+		return len(p), nil
+	}
+
+	entry := map[string]interface{}{}
+	if groups := lineRegexp.FindStringSubmatch(string(p)); nil != groups {
+		entry["level"] = levelInitials[groups[1]]
+		entry["caller"] = groups[3]
+		entry["msg"] = groups[4]
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", groups[2], time.Local); nil == err {
+			entry["time"] = t.Format(time.RFC3339)
+		}
+	} else {
+		entry["msg"] = strings.TrimSuffix(string(p), "\n")
+	}
+	if "" != requestID {
+		entry["requestID"] = requestID
+	}
+	if err := w.writeEntry(entry); nil != err {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *writer) writeEntry(entry map[string]interface{}) error {
+	if _, ok := entry["time"]; !ok {
+		entry["time"] = time.Now().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(entry)
+	if nil != err {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, err = w.out.Write(append(data, '\n'))
+
+	return err
+}
+
+// Output writes the specified message with fields as a JSON object, calldepth is the count of frames to skip to find
+// the caller like log.Output. Returns false if the output is not in JSON format, the caller should log in text then.
+func Output(calldepth, level int, fields map[string]interface{}, msg string) bool {
+	if !Writer.json {
+		return false
+	}
+	if level < Writer.level {
+		return true
+	}
+
+	entry := map[string]interface{}{}
+	if requestID := currentRequestID(); "" != requestID {
+		entry["requestID"] = requestID
+	}
+	for name, value := range fields {
+		entry[name] = value
+	}
+	entry["level"] = levelNames[level]
+	entry["msg"] = msg
+	if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+		entry["caller"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	Writer.writeEntry(entry)
+
+	return true
+}
+
+// FormatFields formats the specified fields as " name=value" pairs sorted by name for text format.
+func FormatFields(fields map[string]interface{}) string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &strings.Builder{}
+	for _, name := range names {
+		buf.WriteString(fmt.Sprintf(" %s=%v", name, fields[name]))
+	}
+
+	return buf.String()
+}
+
+// requestIDs holds the request IDs bound to the goroutines serving requests, goroutine ID -> request ID.
+var requestIDs = &sync.Map{}
+
+// boundRequestIDs is the count of the bound request IDs, looking up the current goroutine is skipped if it's 0.
+var boundRequestIDs int64
+
+// BindRequestID binds the specified request ID to the current goroutine, lines logged by the goroutine carry the
+// request ID until UnbindRequestID is called. So lines logged by services and caches serving a request could be
+// correlated without passing the request ID down.
+func BindRequestID(requestID string) {
+	requestIDs.Store(goroutineID(), requestID)
+	atomic.AddInt64(&boundRequestIDs, 1)
+}
+
+// UnbindRequestID unbinds the request ID bound to the current goroutine.
+func UnbindRequestID() {
+	requestIDs.Delete(goroutineID())
+	atomic.AddInt64(&boundRequestIDs, -1)
+}
+
+func currentRequestID() string {
+	if 1 > atomic.LoadInt64(&boundRequestIDs) {
+		return ""
+	}
+
+	ret, _ := requestIDs.Load(goroutineID())
+	requestID, _ := ret.(string)
+
+	return requestID
+}
+
+// goroutineID returns the ID of the current goroutine from the header of its stack: "goroutine 42 [running]:".
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = bytes.TrimPrefix(buf[:runtime.Stack(buf, false)], []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); 0 < i {
+		buf = buf[:i]
+	}
+	ret, _ := strconv.ParseUint(string(buf), 10, 64)
+
+	return ret
+}
+
+// prefixRequestID prefixes the message of the specified log line with the specified request ID, the line is returned
+// as is if the message has been prefixed by a request logger.
+func prefixRequestID(line []byte, requestID string) []byte {
+	prefix := "[" + requestID + "] "
+	indexes := lineRegexp.FindSubmatchIndex(line)
+	if nil == indexes {
+		return line
+	}
+	msgStart := indexes[8]
+	if bytes.HasPrefix(line[msgStart:], []byte(prefix)) {
+		return line
+	}
+
+	ret := make([]byte, 0, len(line)+len(prefix))
+	ret = append(ret, line[:msgStart]...)
+	ret = append(ret, prefix...)
+
+	return append(ret, line[msgStart:]...)
+}
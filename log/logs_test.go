@@ -0,0 +1,118 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := Writer.out
+	Writer.out = buf
+	defer func() {
+		Writer.out = out
+		Set(FormatText, "debug")
+	}()
+
+	Set(FormatJSON, "info")
+	Writer.Write([]byte("W 2019/10/01 12:00:00 themes.go:42: theme [Gina] is incompatible\n"))
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); nil != err {
+		t.Fatalf("unmarshal [%s] failed: %s", buf.String(), err)
+	}
+	if "warn" != entry["level"] || "themes.go:42" != entry["caller"] || "theme [Gina] is incompatible" != entry["msg"] {
+		t.Errorf("unexpected entry [%+v]", entry)
+	}
+
+	buf.Reset()
+	if !Output(1, LevelDebug, nil, "ignored") || 0 != buf.Len() {
+		t.Errorf("debug logs should be skipped at info level, actual is [%s]", buf.String())
+	}
+	Output(1, LevelInfo, map[string]interface{}{"status": 200}, "access")
+	entry = map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); nil != err {
+		t.Fatalf("unmarshal [%s] failed: %s", buf.String(), err)
+	}
+	if "info" != entry["level"] || "access" != entry["msg"] || float64(200) != entry["status"] {
+		t.Errorf("unexpected entry [%+v]", entry)
+	}
+
+	Set(FormatText, "debug")
+	if Output(1, LevelInfo, nil, "access") {
+		t.Error("text format should be logged by the caller")
+	}
+}
+
+func TestFormatFields(t *testing.T) {
+	if fields := FormatFields(map[string]interface{}{"status": 200, "method": "GET"}); " method=GET status=200" != fields {
+		t.Errorf("expected is [%s], actual is [%s]", " method=GET status=200", fields)
+	}
+}
+
+func TestBindRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := Writer.out
+	Writer.out = buf
+	defer func() {
+		Writer.out = out
+		Set(FormatText, "debug")
+	}()
+
+	line := []byte("E 2019/10/01 12:00:00 articlecache.go:42: get article [id=1] from cache failed\n")
+	Writer.Write(line)
+	if string(line) != buf.String() {
+		t.Errorf("expected is [%s], actual is [%s]", line, buf.String())
+	}
+
+	BindRequestID("42")
+	buf.Reset()
+	Writer.Write(line)
+	expected := "E 2019/10/01 12:00:00 articlecache.go:42: [42] get article [id=1] from cache failed\n"
+	if expected != buf.String() {
+		t.Errorf("expected is [%s], actual is [%s]", expected, buf.String())
+	}
+	buf.Reset()
+	Writer.Write([]byte(expected))
+	if expected != buf.String() {
+		t.Errorf("expected is [%s], actual is [%s]", expected, buf.String())
+	}
+
+	Set(FormatJSON, "debug")
+	buf.Reset()
+	Writer.Write(line)
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); nil != err {
+		t.Fatalf("unmarshal [%s] failed: %s", buf.String(), err)
+	}
+	if "42" != entry["requestID"] {
+		t.Errorf("unexpected entry [%+v]", entry)
+	}
+
+	done := make(chan string)
+	go func() { done <- currentRequestID() }()
+	if requestID := <-done; "" != requestID {
+		t.Errorf("request ID should be bound to the current goroutine only, actual is [%s]", requestID)
+	}
+
+	UnbindRequestID()
+	if requestID := currentRequestID(); "" != requestID {
+		t.Errorf("request ID should be unbound, actual is [%s]", requestID)
+	}
+}
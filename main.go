@@ -17,6 +17,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
@@ -29,13 +31,16 @@ import (
 	"time"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/controller"
 	"github.com/b3log/pipe/cron"
 	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/log"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/service"
 	"github.com/b3log/pipe/theme"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 // Logger
@@ -46,12 +51,17 @@ func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	gulu.Log.SetLevel("warn")
-	logger = gulu.Log.NewLogger(os.Stdout)
+	logger = gulu.Log.NewLogger(log.Writer)
 
 	model.LoadConf()
 	i18n.Load()
 	theme.Load()
 	replaceServerConf()
+	if "" != model.Conf.Redis {
+		if err := cache.UseRedis(model.Conf.Redis); nil != err {
+			logger.Fatal("connect to Redis failed: " + err.Error())
+		}
+	}
 
 	if "dev" == model.Conf.RuntimeMode {
 		gin.SetMode(gin.DebugMode)
@@ -63,11 +73,53 @@ func init() {
 
 // Entry point.
 func main() {
+	if "theme" == flag.Arg(0) {
+		themeCmd(flag.Args()[1:])
+
+		return
+	}
+	if "config" == flag.Arg(0) {
+		configCmd(flag.Args()[1:])
+
+		return
+	}
+
 	service.ConnectDB()
+	if "migrate" == flag.Arg(0) {
+		migrate(flag.Args()[1:])
+
+		return
+	}
+	service.Migrate()
 	service.Upgrade.Perform()
+	if "export" == flag.Arg(0) {
+		exportStatic(flag.Args()[1:])
+
+		return
+	}
+	if "user" == flag.Arg(0) {
+		userCmd(flag.Args()[1:])
+
+		return
+	}
+	if "article" == flag.Arg(0) {
+		articleCmd(flag.Args()[1:])
+
+		return
+	}
+	if "backup" == flag.Arg(0) {
+		backupCmd(flag.Args()[1:])
+
+		return
+	}
 	cron.Start()
 
 	router := controller.MapRoutes()
+	service.Job.Register(&service.JobSpec{Name: "warm-up", RunAtStart: true, Run: func() error {
+		controller.WarmUp(router)
+
+		return nil
+	}})
 	server := &http.Server{
 		Addr:    "0.0.0.0:" + model.Conf.Port,
 		Handler: router,
@@ -76,8 +128,140 @@ func main() {
 	handleSignal(server)
 
 	logger.Infof("Pipe (v%s) is running [%s]", model.Version, model.Conf.Server)
+	if 0 < len(model.Conf.TLSDomains) {
+		if err := listenAndServeAutocert(server); nil != err {
+			logger.Fatalf("listen and serve TLS failed: %s", err)
+		}
+
+		return
+	}
 	if err := server.ListenAndServe(); nil != err {
-		logger.Fatalf("listen and serve failed: " + err.Error())
+		logger.Fatalf("listen and serve failed: %s", err)
+	}
+}
+
+// listenAndServeAutocert serves HTTPS on port 443 with certificates of Conf.TLSDomains, which are obtained and renewed
+// via ACME (Let's Encrypt) automatically. HTTP requests on port 80 answer ACME challenges or are redirected to HTTPS.
+func listenAndServeAutocert(server *http.Server) error {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(model.Conf.TLSDomains...),
+		Cache:      autocert.DirCache(model.Conf.TLSCacheDir),
+		Email:      model.Conf.TLSEmail,
+	}
+	go func() {
+		if err := http.ListenAndServe("0.0.0.0:80", manager.HTTPHandler(nil)); nil != err {
+			logger.Errorf("listen and serve HTTP redirections failed: %s", err)
+		}
+	}()
+
+	server.Addr = "0.0.0.0:443"
+	server.TLSConfig = manager.TLSConfig()
+	logger.Infof("serving HTTPS for domains %v with certificates cached in [%s]", model.Conf.TLSDomains, model.Conf.TLSCacheDir)
+
+	return server.ListenAndServeTLS("", "")
+}
+
+// exportStatic handles command "pipe export --static ./out [--blog username] [--base https://example.com]".
+func exportStatic(args []string) {
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := exportFlags.String("static", "", "directory of the exported static site")
+	blog := exportFlags.String("blog", "", "username of the blog admin, defaults to the platform admin")
+	base := exportFlags.String("base", "", "base URL of the static site, rewrites the blog URL if specified")
+	exportFlags.Parse(args)
+	if "" == *dir {
+		logger.Fatal("please specify --static")
+	}
+
+	var user *model.User
+	if "" == *blog {
+		user = service.User.GetPlatformAdmin()
+	} else {
+		user = service.User.GetUserByName(*blog)
+	}
+	if nil == user {
+		logger.Fatal("not found blog")
+	}
+	userBlog := service.User.GetOwnBlog(user.ID)
+	if nil == userBlog {
+		logger.Fatal("not found blog of user [" + user.Name + "]")
+	}
+
+	router := controller.MapRoutes()
+	count, err := controller.ExportStatic(router, userBlog.ID, *dir, *base)
+	if nil != err {
+		logger.Fatal("export static site failed: " + err.Error())
+	}
+
+	service.DisconnectDB()
+	logger.Infof("exported [%d] pages into [%s]", count, *dir)
+}
+
+// configCmd handles command "pipe config check", which validates the configurations loaded from the configuration
+// file, environment variables and command-line arguments.
+func configCmd(args []string) {
+	if 1 != len(args) || "check" != args[0] {
+		logger.Fatal("usage: pipe config check")
+	}
+
+	problems := model.ConfProblems()
+	if 1 > len(problems) {
+		fmt.Println("configuration is valid")
+
+		return
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	fmt.Printf("found [%d] problems in configuration\n", len(problems))
+	os.Exit(1)
+}
+
+// themeCmd handles command "pipe theme new <name>".
+func themeCmd(args []string) {
+	if 2 != len(args) || "new" != args[0] {
+		logger.Fatal("usage: pipe theme new <name>")
+	}
+
+	dir, err := theme.Scaffold("theme/x", args[1])
+	if nil != err {
+		logger.Fatal("create theme failed: " + err.Error())
+	}
+	fmt.Printf("created theme [%s] in [%s], run pipe with -runtime_mode dev to reload templates while developing\n",
+		args[1], dir)
+}
+
+// migrate handles command "pipe migrate [status|up|down --to version]".
+func migrate(args []string) {
+	defer service.DisconnectDB()
+
+	cmd := "status"
+	if 0 < len(args) {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "up":
+		service.Migrate()
+	case "down":
+		downFlags := flag.NewFlagSet("down", flag.ExitOnError)
+		to := downFlags.Uint64("to", 0, "version to revert to, migrations newer than it will be reverted")
+		downFlags.Parse(args)
+		if err := service.RevertMigrations(*to); nil != err {
+			logger.Fatal(err.Error())
+		}
+	case "status":
+	default:
+		logger.Fatal("unknown migrate command [" + cmd + "]")
+	}
+
+	version, pending, err := service.MigrationStatus()
+	if nil != err {
+		logger.Fatal("get migration status failed: " + err.Error())
+	}
+	fmt.Printf("current migration version [%d], [%d] pending\n", version, len(pending))
+	for _, migration := range pending {
+		fmt.Printf("pending migration [%d %s]\n", migration.Version, migration.Name)
 	}
 }
 
@@ -90,9 +274,10 @@ func handleSignal(server *http.Server) {
 		s := <-c
 		logger.Infof("got signal [%s], exiting pipe now", s)
 		if err := server.Close(); nil != err {
-			logger.Errorf("server close failed: " + err.Error())
+			logger.Errorf("server close failed: %s", err)
 		}
 
+		service.View.Flush()
 		service.DisconnectDB()
 
 		logger.Infof("Pipe exited")
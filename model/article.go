@@ -26,18 +26,25 @@ type Article struct {
 
 	AuthorID     uint64    `json:"authorID" structs:"authorID"`
 	Title        string    `gorm:"size:128" json:"title" structs:"title"`
-	Abstract     string    `gorm:"type:mediumtext" json:"abstract" structs:"abstract"`
+	Abstract     string    `gorm:"size:16777215" json:"abstract" structs:"abstract"`
+	Description  string    `gorm:"size:255" json:"description" structs:"description"` // meta description, the abstract is used if empty
 	Tags         string    `gorm:"type:text" json:"tags" structs:"tags"`
-	Content      string    `gorm:"type:mediumtext" json:"content" structs:"content"`
+	Content      string    `gorm:"size:16777215" json:"content" structs:"content"`
 	Path         string    `sql:"index" gorm:"size:255" json:"path" structs:"path"`
 	Status       int       `sql:"index" json:"status" structs:"status"`
 	Topped       bool      `json:"topped" structs:"topped"`
+	ToppedOrder  int       `json:"toppedOrder" structs:"toppedOrder"` // order among topped articles, ascending
 	Commentable  bool      `json:"commentable" structs:"commentable"`
 	ViewCount    int       `json:"viewCount" structs:"viewCount"`
 	CommentCount int       `json:"commentCount" structs:"commentCount"`
 	IP           string    `gorm:"size:128" json:"ip" structs:"ip"`
 	UserAgent    string    `gorm:"size:255" json:"userAgent" structs:"userAgent"`
 	PushedAt     time.Time `json:"pushedAt" structs:"pushedAt"`
+	RepostOptOut bool      `json:"repostOptOut" structs:"repostOptOut"`
+	RepostedAt   time.Time `json:"repostedAt" structs:"repostedAt"`
+	Version      int       `json:"version" structs:"version"` // optimistic locking version, increased on each update
+	Visibility   int       `sql:"index" json:"visibility" structs:"visibility"`
+	PasswordHash string    `gorm:"size:255" json:"-" structs:"-"` // bcrypt hash of the access password, used with ArticleVisibilityPassword
 
 	BlogID uint64 `sql:"index" json:"blogID" structs:"blogID"`
 }
@@ -45,4 +52,12 @@ type Article struct {
 // Article statuses.
 const (
 	ArticleStatusOK = iota
+	ArticleStatusDraft
+)
+
+// Article visibilities.
+const (
+	ArticleVisibilityPublic   = iota // listed everywhere
+	ArticleVisibilityUnlisted        // reachable by its URL only, excluded from listings, feeds and the sitemap
+	ArticleVisibilityPassword        // unlisted and requires the access password to read
 )
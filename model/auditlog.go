@@ -0,0 +1,47 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Audit log actions.
+const (
+	AuditActionArticlePublish = "article.publish"
+	AuditActionArticleRemove  = "article.remove"
+	AuditActionArticleRestore = "article.restore"
+	AuditActionArticlePurge   = "article.purge"
+	AuditActionSettingsUpdate = "settings.update"
+	AuditActionUserAdd        = "user.add"
+	AuditActionUserRole       = "user.role"
+	AuditActionThemeSwitch    = "theme.switch"
+	AuditActionThemeInstall   = "theme.install"
+	AuditActionMediaRemove    = "media.remove"
+	AuditActionMediaReplace   = "media.replace"
+	AuditActionReportResolve  = "report.resolve"
+	AuditActionReportDismiss  = "report.dismiss"
+	AuditActionJobRun         = "job.run"
+)
+
+// AuditLog model, records who did what and when in the admin console.
+type AuditLog struct {
+	Model
+
+	UserID uint64 `sql:"index" json:"userID"`
+	Action string `sql:"index" gorm:"size:32" json:"action"`
+	Target string `gorm:"size:255" json:"target"` // title of the article, category of the settings, name of the user, etc.
+	IP     string `gorm:"size:128" json:"ip"`
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -26,7 +26,17 @@ type Category struct {
 	MetaKeywords    string `gorm:"size:255" json:"metaKeywords"`
 	MetaDescription string `gorm:"type:text" json:"metaDescription"`
 	Tags            string `gorm:"type:text" json:"tags"`
-	Number          int    `json:"number"` // for sorting
+	Number          int    `json:"number"`                // for sorting
+	Layout          string `gorm:"size:64" json:"layout"` // template/layout hint of articles
+	Commentable     int    `json:"commentable"`           // default commentable of new articles
+	Sign            string `gorm:"type:text" json:"sign"` // signature block of articles, overrides the blog sign if not empty
 
 	BlogID uint64 `sql:"index" json:"blogID"`
 }
+
+// Category commentable defaults of new articles.
+const (
+	CategoryCommentableInherit = iota
+	CategoryCommentableOn
+	CategoryCommentableOff
+)
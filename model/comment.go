@@ -32,6 +32,7 @@ type Comment struct {
 	IP              string    `gorm:"size:128" json:"ip"`
 	UserAgent       string    `gorm:"size:255" json:"userAgent"`
 	PushedAt        time.Time `json:"pushedAt"`
+	Status          int       `sql:"index" json:"status"`
 
 	AuthorName      string `gorm:"size:32" json:"authorName"`       // exist if this comment sync from Sym, https://github.com/b3log/pipe/issues/98
 	AuthorAvatarURL string `gorm:"size:255" json:"authorAvatarURL"` // exist if this comment sync from Sym, https://github.com/b3log/pipe/issues/98
@@ -40,5 +41,11 @@ type Comment struct {
 	BlogID uint64 `sql:"index" json:"blogID"`
 }
 
+// Comment statuses.
+const (
+	CommentStatusOK   = iota // published
+	CommentStatusSpam        // flagged as spam, waiting for moderation
+)
+
 // SyncCommentAuthorID is the id of sync comment bot.
 const SyncCommentAuthorID = math.MaxInt32
@@ -0,0 +1,50 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCORSPolicies(t *testing.T) {
+	policies := normalizeCORSPolicies([]*CORSPolicy{
+		{Path: "content/", AllowOrigins: []string{"*"}, AllowCredentials: true},
+		{Path: "/console", AllowOrigins: []string{"https://b3log.org"}, AllowMethods: []string{"get", "put"}, AllowCredentials: true},
+		{Path: "", AllowOrigins: []string{"*"}},
+		{Path: "/blogs"},
+	})
+	if 2 != len(policies) {
+		t.Fatalf("misconfigured policies should be ignored, actual policies are [%+v]", policies)
+	}
+
+	content := policies[0]
+	if "/content" != content.Path || content.AllowCredentials {
+		t.Errorf("credentials should be disabled for any origin, actual policy is [%+v]", content)
+	}
+	if expected := []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}; !reflect.DeepEqual(expected, content.AllowMethods) {
+		t.Errorf("expected is [%v], actual is [%v]", expected, content.AllowMethods)
+	}
+
+	console := policies[1]
+	if "/console" != console.Path || !console.AllowCredentials {
+		t.Errorf("credentials should be kept for explicit origins, actual policy is [%+v]", console)
+	}
+	if expected := []string{"GET", "PUT"}; !reflect.DeepEqual(expected, console.AllowMethods) {
+		t.Errorf("expected is [%v], actual is [%v]", expected, console.AllowMethods)
+	}
+}
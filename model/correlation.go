@@ -22,6 +22,8 @@ const (
 	CorrelationArticleTag
 	CorrelationBlogUser
 	CorrelationArticleArchive
+	CorrelationBlogReader
+	CorrelationArticleRelated
 )
 
 // Correlation model.
@@ -29,6 +31,8 @@ const (
 //   id1(article_id) - id2(tag_id)
 //   id1(blog_id) - id2(user_id) - int1(role) - int2(article_count)
 //   id1(article_id) - id2(archive_id)
+//   id1(blog_id) - id2(user_id), the user follows the blog
+//   id1(article_id) - id2(related_article_id) - int1(score * 1000)
 type Correlation struct {
 	Model
 
@@ -0,0 +1,28 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Follower model, a fediverse actor following a blog via ActivityPub.
+type Follower struct {
+	Model
+
+	ActorID     string `sql:"index" gorm:"size:255" json:"actorID"` // actor URL, e.g. https://mastodon.social/users/pipe
+	Inbox       string `gorm:"size:255" json:"inbox"`
+	SharedInbox string `gorm:"size:255" json:"sharedInbox"` // shared inbox of the actor's server, may be empty
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
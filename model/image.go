@@ -0,0 +1,33 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Image model, an uploaded image whose thumbnails and resized variants are served at /images/:hash.
+type Image struct {
+	Model
+
+	Hash     string `sql:"index" gorm:"size:32" json:"hash"` // hex of the first 16 bytes of SHA-256 of the content
+	Key      string `gorm:"size:255" json:"key"`             // media key of the original image
+	URL      string `gorm:"type:text" json:"url"`            // media URL of the original image
+	MIMEType string `gorm:"size:64" json:"mimeType"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	WebPURL  string `gorm:"column:webp_url;type:text" json:"webpURL"` // media URL of the WebP variant, empty if not transcoded
+	AVIFURL  string `gorm:"column:avif_url;type:text" json:"avifURL"` // media URL of the AVIF variant, empty if not transcoded
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -0,0 +1,33 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// Invitation model, a new author accepts an invitation to join the blog with the preset role.
+type Invitation struct {
+	Model
+
+	Token     string    `sql:"index" gorm:"size:64" json:"token"`
+	Role      int       `json:"role"`
+	Email     string    `gorm:"size:255" json:"email"`
+	CreatorID uint64    `json:"creatorID"`
+	ExpiredAt time.Time `json:"expiredAt"`
+	UsedByID  uint64    `json:"usedByID"` // ID of the user accepted this invitation, 0 if not accepted yet
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -0,0 +1,50 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// JobRun model, a failed run of a background job, including the retried attempts. Succeeded runs are counted by
+// JobStat only, so frequent jobs don't flood the table.
+type JobRun struct {
+	Model
+
+	Name     string     `gorm:"size:64" sql:"index" json:"name"`
+	Status   int        `json:"status"`                   // JobRunStatusRunning, JobRunStatusSucceeded or JobRunStatusFailed
+	Attempts int        `json:"attempts"`                 // count of attempts, more than 1 if retried
+	Message  string     `gorm:"size:1024" json:"message"` // error of the last failed attempt
+	EndedAt  *time.Time `json:"endedAt"`
+}
+
+// JobStat model, the run statistics of a background job.
+type JobStat struct {
+	Model
+
+	Name          string     `gorm:"size:64;unique_index" json:"name"`
+	Succeeded     int        `json:"succeeded"`  // count of succeeded runs
+	Failed        int        `json:"failed"`     // count of failed runs
+	LastStatus    int        `json:"lastStatus"` // JobRunStatusSucceeded or JobRunStatusFailed
+	LastStartedAt *time.Time `json:"lastStartedAt"`
+	LastEndedAt   *time.Time `json:"lastEndedAt"`
+}
+
+// Job run statuses.
+const (
+	JobRunStatusRunning = iota
+	JobRunStatusSucceeded
+	JobRunStatusFailed
+)
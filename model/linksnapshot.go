@@ -0,0 +1,34 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// LinkSnapshot model, an archived snapshot of an external link found in published articles, which is the fallback of
+// the link once it is dead.
+type LinkSnapshot struct {
+	Model
+
+	URL         string    `gorm:"type:text" json:"url"`
+	URLHash     string    `sql:"index" gorm:"size:64" json:"urlHash"` // SHA-256 of the URL for lookup
+	ArchivedURL string    `gorm:"type:text" json:"archivedURL"`       // empty if not archived yet
+	ArchivedAt  time.Time `json:"archivedAt"`
+	Attempts    int       `json:"attempts"`  // count of failed archiving attempts
+	ArticleID   uint64    `json:"articleID"` // ID of the article the link was first found in
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -0,0 +1,32 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// MediaFile model, a file uploaded to media storage, listed in the media library of admin console.
+type MediaFile struct {
+	Model
+
+	Name       string `gorm:"size:255" json:"name"` // original file name without extension
+	Key        string `sql:"index" gorm:"size:255" json:"key"`
+	URL        string `gorm:"type:text" json:"url"`
+	MIMEType   string `gorm:"size:64" json:"mimeType"`
+	Size       int64  `json:"size"`
+	Hash       string `gorm:"size:32" json:"hash"` // hex of the first 16 bytes of SHA-256 of the content
+	UploaderID uint64 `json:"uploaderID"`
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -0,0 +1,33 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Notification types.
+const (
+	NotificationTypeCommentPending = "comment.pending" // a comment is held for moderation
+	NotificationTypeCommentNew     = "comment.new"     // a comment on an article of the user
+	NotificationTypeCommentReply   = "comment.reply"   // a reply to a comment of the user
+	NotificationTypeImportFinished = "import.finished"
+)
+
+// Notification is an event pushed to consoles in real time, it's not persisted.
+type Notification struct {
+	Type   string                 `json:"type"`
+	BlogID uint64                 `json:"blogID"`
+	UserID uint64                 `json:"-"` // receiver of the notification, 0 for all users of the blog
+	Data   map[string]interface{} `json:"data"`
+}
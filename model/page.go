@@ -0,0 +1,30 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Page model, a static page such as "about" served at a top-level path of the blog, not listed with articles.
+type Page struct {
+	Model
+
+	AuthorID uint64 `json:"authorID"`
+	Title    string `gorm:"size:128" json:"title"`
+	Content  string `gorm:"size:16777215" json:"content"`
+	Path     string `sql:"index" gorm:"size:255" json:"path"` // top-level path, e.g. /about
+	Status   int    `json:"status"`                           // ArticleStatusOK or ArticleStatusDraft
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
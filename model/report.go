@@ -0,0 +1,64 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// Report model, a flag of an article or a comment raised by a visitor for platform admin review.
+type Report struct {
+	Model
+
+	TargetType int    `json:"targetType"`                // ReportTargetArticle or ReportTargetComment
+	TargetID   uint64 `sql:"index" json:"targetID"`      // ID of the reported article or comment
+	Reason     string `gorm:"size:32" json:"reason"`     // one of ReportReasons
+	Detail     string `gorm:"size:512" json:"detail"`    // description written by the reporter
+	IP         string `gorm:"size:128" json:"ip"`        // IP of the reporter
+	ReporterID uint64 `json:"reporterID"`                // 0 if the reporter did not sign in
+	Status     int    `sql:"index" json:"status"`        // ReportStatusPending, ReportStatusResolved or ReportStatusDismissed
+	Resolution string `gorm:"size:32" json:"resolution"` // actions taken to resolve, e.g. "hide,banIP"
+	ResolverID uint64 `json:"resolverID"`                // the platform admin who resolved or dismissed it
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
+
+// Report target types.
+const (
+	ReportTargetArticle = iota
+	ReportTargetComment
+)
+
+// Report statuses.
+const (
+	ReportStatusPending = iota
+	ReportStatusResolved
+	ReportStatusDismissed
+)
+
+// Report resolution actions.
+const (
+	ReportActionHide  = "hide"  // trashes the article or holds the comment for moderation
+	ReportActionBanIP = "banIP" // bans the IP of the reported content from commenting and reporting
+)
+
+// ReportReasons are reasons visitors could choose when reporting.
+var ReportReasons = []string{"spam", "abuse", "illegal", "copyright", "other"}
+
+// BannedIP model, an IP banned by the platform admin from commenting and reporting.
+type BannedIP struct {
+	Model
+
+	IP     string `gorm:"size:128;unique_index" json:"ip"`
+	Reason string `gorm:"size:255" json:"reason"`
+}
@@ -41,6 +41,11 @@ const (
 	SettingNameThemeName = "themeName"
 )
 
+// Setting category of theme options, a setting is named by the theme and the option like Gina/accentColor.
+const (
+	SettingCategoryThemeOption = "themeOption"
+)
+
 // Setting names of category "basic".
 const (
 	SettingCategoryBasic = "basic"
@@ -72,11 +77,22 @@ const (
 	SettingNamePreferenceArticleListPageSize        = "preferenceArticleListPageSize"
 	SettingNamePreferenceArticleListWindowSize      = "preferenceArticleListWindowSize"
 	SettingNamePreferenceArticleListStyle           = "preferenceArticleListStyle"
+	SettingNamePreferenceArticleListOrder           = "preferenceArticleListOrder"
 	SettingNamePreferenceMostCommentArticleListSize = "preferenceMostCommentArticleListSize"
 	SettingNamePreferenceMostUseTagListSize         = "preferenceMostUseTagListSize"
 	SettingNamePreferenceMostViewArticleListSize    = "preferenceMostViewArticleListSize"
 	SettingNamePreferenceRecentCommentListSize      = "preferenceRecentCommentListSize"
 	SettingNamePreferenceRecommendArticleListSize   = "preferenceRecommendArticleListSize"
+	SettingNamePreferencePermalink                  = "preferencePermalink"
+	SettingNamePreferenceArchiveGranularity         = "preferenceArchiveGranularity"
+	SettingNamePreferenceRelatedArticleListSize     = "preferenceRelatedArticleListSize"
+	SettingNamePreferenceCodeHighlightStyle         = "preferenceCodeHighlightStyle"
+	SettingNamePreferenceMath                       = "preferenceMath"
+	SettingNamePreferenceFootnotes                  = "preferenceFootnotes"
+	SettingNamePreferenceDefinitionLists            = "preferenceDefinitionLists"
+	SettingNamePreferenceTaskLists                  = "preferenceTaskLists"
+	SettingNamePreferenceTables                     = "preferenceTables"
+	SettingNamePreferenceEmbedProviders             = "preferenceEmbedProviders"
 )
 
 // Setting values of category "preference".
@@ -85,21 +101,57 @@ const (
 	SettingPreferenceArticleListStyleValueTitleAbstract = 1
 	SettingPreferenceArticleListStyleValueTitleContent  = 2
 
+	SettingPreferenceArticleListOrderValueCreated = 0 // orders by publish date
+	SettingPreferenceArticleListOrderValueUpdated = 1 // orders by update date
+	SettingPreferenceArticleListOrderValueView    = 2 // orders by view count
+
+	SettingPreferencePermalinkValueDate      = 0 // /articles/:year/:month/:day/:id
+	SettingPreferencePermalinkValueMonthSlug = 1 // /:year/:month/:slug
+	SettingPreferencePermalinkValueID        = 2 // /:id
+	SettingPreferencePermalinkValueSlug      = 3 // /:slug
+
+	SettingPreferenceArchiveGranularityValueMonth = 0 // archives by month
+	SettingPreferenceArchiveGranularityValueYear  = 1 // archives by year
+
+	SettingPreferenceSwitchValueOff = 0
+	SettingPreferenceSwitchValueOn  = 1
+
 	SettingPreferenceArticleListPageSizeDefault        = 20
 	SettingPreferenceArticleListWindowSizeDefault      = 7
 	SettingPreferenceArticleListStyleDefault           = SettingPreferenceArticleListStyleValueTitleAbstract
+	SettingPreferenceArticleListOrderDefault           = SettingPreferenceArticleListOrderValueCreated
 	SettingPreferenceMostCommentArticleListSizeDefault = 7
 	SettingPreferenceMostUseTagListSizeDefault         = 15
 	SettingPreferenceMostViewArticleListSizeDefault    = 15
 	SettingPreferenceRecentCommentListSizeDefault      = 7
 	SettingPreferenceRecommendArticleListSizeDefault   = 1
+	SettingPreferencePermalinkDefault                  = SettingPreferencePermalinkValueDate
+	SettingPreferenceArchiveGranularityDefault         = SettingPreferenceArchiveGranularityValueMonth
+	SettingPreferenceRelatedArticleListSizeDefault     = 5        // 0 turns related articles off
+	SettingPreferenceCodeHighlightStyleDefault         = "github" // empty turns server-side highlighting off
+	SettingPreferenceMathDefault                       = SettingPreferenceSwitchValueOff
+	SettingPreferenceFootnotesDefault                  = SettingPreferenceSwitchValueOn
+	SettingPreferenceDefinitionListsDefault            = SettingPreferenceSwitchValueOff
+	SettingPreferenceTaskListsDefault                  = SettingPreferenceSwitchValueOn
+	SettingPreferenceTablesDefault                     = SettingPreferenceSwitchValueOn
+	SettingPreferenceEmbedProvidersDefault             = "bilibili,gist,twitter,vimeo,youtube" // comma separated, empty turns embeds off
 )
 
+// SettingPreferenceSwitchDefaults holds default values of preferences which turn a feature on or off.
+var SettingPreferenceSwitchDefaults = map[string]int{
+	SettingNamePreferenceMath:            SettingPreferenceMathDefault,
+	SettingNamePreferenceFootnotes:       SettingPreferenceFootnotesDefault,
+	SettingNamePreferenceDefinitionLists: SettingPreferenceDefinitionListsDefault,
+	SettingNamePreferenceTaskLists:       SettingPreferenceTaskListsDefault,
+	SettingNamePreferenceTables:          SettingPreferenceTablesDefault,
+}
+
 // Setting names of category "sign".
 const (
 	SettingCategorySign = "sign"
 
 	SettingNameArticleSign = "signArticle"
+	SettingNameAuthorSign  = "signAuthor" // per-author sign, the full name is suffixed with the author ID like "signAuthor1"
 )
 
 // Setting values of category "sign".
@@ -120,6 +172,7 @@ const (
 	SettingCategoryFeed = "feed"
 
 	SettingNameFeedOutputMode = "feedOutputMode"
+	SettingNameFeedWebSubHub  = "feedWebSubHub" // WebSub (PubSubHubbub) hub URL, disabled if empty
 )
 
 // Setting values of category "feed".
@@ -128,6 +181,14 @@ const (
 	SettingFeedOutputModeValueFull     = 1
 )
 
+// Setting names of category "activityPub".
+const (
+	SettingCategoryActivityPub = "activityPub"
+
+	SettingNameActivityPubPrivateKey = "activityPubPrivateKey" // PEM encoded RSA private key signing deliveries
+	SettingNameActivityPubPublicKey  = "activityPubPublicKey"
+)
+
 // Setting names of category "thirdStatistic".
 const (
 	SettingCategoryThirdStatistic = "thirdStatistic"
@@ -135,6 +196,15 @@ const (
 	SettingNameThirdStatisticBaidu = "thirdStatisticBaidu"
 )
 
+// Setting names of category "appearance", which are created lazily on the first update.
+const (
+	SettingCategoryAppearance = "appearance"
+
+	SettingNameAppearanceHeadCSS  = "appearanceHeadCSS"  // style sheet appended to <head> of every themed page
+	SettingNameAppearanceHeadJS   = "appearanceHeadJS"   // script or HTML snippet appended to <head>
+	SettingNameAppearanceFooterJS = "appearanceFooterJS" // script or HTML snippet appended before </body>
+)
+
 // Setting names of category "statistic".
 const (
 	SettingCategoryStatistic = "statistic"
@@ -150,3 +220,99 @@ const (
 
 	SettingNameAdGoogleAdSenseArticleEmbed = "adGoogleAdSenseArticleEmbed"
 )
+
+// Setting names of category "repost".
+const (
+	SettingCategoryRepost = "repost"
+
+	SettingNameRepostEnabled          = "repostEnabled"
+	SettingNameRepostInterval         = "repostInterval"
+	SettingNameRepostMinAge           = "repostMinAge"
+	SettingNameRepostTelegramBotToken = "repostTelegramBotToken"
+	SettingNameRepostTelegramChatID   = "repostTelegramChatID"
+	SettingNameRepostTwitterWebhook   = "repostTwitterWebhook"
+)
+
+// Setting names of category "spam".
+const (
+	SettingCategorySpam = "spam"
+
+	SettingNameSpamAkismetKey     = "spamAkismetKey"
+	SettingNameSpamCaptcha        = "spamCaptcha"
+	SettingNameSpamCaptchaSiteKey = "spamCaptchaSiteKey"
+	SettingNameSpamCaptchaSecret  = "spamCaptchaSecret"
+	SettingNameSpamTrustedCount   = "spamTrustedCount" // count of approved comments to trust a commenter, 0 to disable
+)
+
+// Setting values of category "spam".
+const (
+	SettingSpamCaptchaValueNone        = ""          // no challenge
+	SettingSpamCaptchaValueHCaptcha    = "hcaptcha"  // hCaptcha, https://www.hcaptcha.com
+	SettingSpamCaptchaValueReCaptcha   = "recaptcha" // Google reCAPTCHA v2, https://www.google.com/recaptcha
+	SettingSpamCaptchaValueProofOfWork = "pow"       // built-in proof-of-work challenge solved by browsers
+)
+
+// Setting names of category "upload".
+const (
+	SettingCategoryUpload = "upload"
+
+	SettingNameUploadMaxSize   = "uploadMaxSize"   // in KB
+	SettingNameUploadMIMETypes = "uploadMIMETypes" // comma separated
+)
+
+// Setting values of category "upload".
+const (
+	SettingUploadMaxSizeDefault   = 2 * 1024
+	SettingUploadMIMETypesDefault = "image/jpeg,image/png,image/gif"
+)
+
+// Setting names of category "storage", which are settings of the platform blog since media storage is shared by all
+// blogs.
+const (
+	SettingCategoryStorage = "storage"
+
+	SettingNameStorageType      = "storageType"
+	SettingNameStorageEndpoint  = "storageEndpoint" // e.g. https://s3.amazonaws.com, http://minio:9000
+	SettingNameStorageBucket    = "storageBucket"
+	SettingNameStorageRegion    = "storageRegion" // S3 region
+	SettingNameStorageAccessKey = "storageAccessKey"
+	SettingNameStorageSecretKey = "storageSecretKey"
+	SettingNameStorageURL       = "storageURL" // public URL prefix of the bucket, e.g. CDN domain
+)
+
+// Setting values of category "storage".
+const (
+	SettingStorageTypeValueConf = ""    // media directory or S3 bucket of pipe.json
+	SettingStorageTypeValueS3   = "s3"  // Amazon S3 or S3 compatible storage such as MinIO
+	SettingStorageTypeValueOSS  = "oss" // Aliyun OSS
+)
+
+// Setting names of category "announcement", which are settings of the platform blog since the announcement is shown on
+// all blogs.
+const (
+	SettingCategoryAnnouncement = "announcement"
+
+	SettingNameAnnouncementContent = "announcementContent" // markdown
+	SettingNameAnnouncementStart   = "announcementStart"   // RFC3339, shown at once if empty
+	SettingNameAnnouncementEnd     = "announcementEnd"     // RFC3339, shown until removed if empty
+)
+
+// Setting names of category "newsletter".
+const (
+	SettingCategoryNewsletter = "newsletter"
+
+	SettingNameNewsletterMode = "newsletterMode"
+)
+
+// Setting values of category "newsletter".
+const (
+	SettingNewsletterModeOff    = "off"    // newsletter disabled
+	SettingNewsletterModeNew    = "new"    // mails new articles hourly
+	SettingNewsletterModeWeekly = "weekly" // mails a weekly digest of new articles
+)
+
+// Setting values of category "repost".
+const (
+	SettingRepostIntervalDefault = 24 // hours
+	SettingRepostMinAgeDefault   = 30 // days
+)
@@ -23,11 +23,11 @@ import (
 func sqlite2MySQL(sqliteDataFilePath, mysqlConn string) {
 	sqlite, err := gorm.Open("sqlite3", Conf.SQLite)
 	if nil != err {
-		logger.Fatalf("opens SQLite database failed: " + err.Error())
+		logger.Fatalf("opens SQLite database failed: %s", err)
 	}
 	mysql, err := gorm.Open("mysql", Conf.MySQL)
 	if nil != err {
-		logger.Fatalf("opens MySQL database failed: " + err.Error())
+		logger.Fatalf("opens MySQL database failed: %s", err)
 	}
 	if err = mysql.AutoMigrate(Models...).Error; nil != err {
 		logger.Fatal("auto migrate tables failed: " + err.Error())
@@ -46,11 +46,11 @@ func sqlite2MySQL(sqliteDataFilePath, mysqlConn string) {
 
 func importArchives(sqlite, mysql *gorm.DB, models []*Archive) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] archives", len(models))
@@ -58,14 +58,17 @@ func importArchives(sqlite, mysql *gorm.DB, models []*Archive) {
 
 func importArticles(sqlite, mysql *gorm.DB, models []*Article) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if model.PushedAt.Before(ZeroPushTime) {
 			model.PushedAt = ZeroPushTime
 		}
+		if model.RepostedAt.Before(ZeroPushTime) {
+			model.RepostedAt = ZeroPushTime
+		}
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: "+err.Error()+": %+v", model)
+			logger.Fatalf("saves data failed: %s: %+v", err, model)
 		}
 	}
 	logger.Infof("imported [%d] articles", len(models))
@@ -73,11 +76,11 @@ func importArticles(sqlite, mysql *gorm.DB, models []*Article) {
 
 func importCategories(sqlite, mysql *gorm.DB, models []*Category) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] categories", len(models))
@@ -85,11 +88,11 @@ func importCategories(sqlite, mysql *gorm.DB, models []*Category) {
 
 func importComments(sqlite, mysql *gorm.DB, models []*Comment) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] comments", len(models))
@@ -97,11 +100,11 @@ func importComments(sqlite, mysql *gorm.DB, models []*Comment) {
 
 func importCorrelations(sqlite, mysql *gorm.DB, models []*Correlation) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] correlations", len(models))
@@ -109,11 +112,11 @@ func importCorrelations(sqlite, mysql *gorm.DB, models []*Correlation) {
 
 func importNavigations(sqlite, mysql *gorm.DB, models []*Navigation) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] navigations", len(models))
@@ -121,11 +124,11 @@ func importNavigations(sqlite, mysql *gorm.DB, models []*Navigation) {
 
 func importSettings(sqlite, mysql *gorm.DB, models []*Setting) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] settings", len(models))
@@ -133,11 +136,11 @@ func importSettings(sqlite, mysql *gorm.DB, models []*Setting) {
 
 func importTags(sqlite, mysql *gorm.DB, models []*Tag) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] tags", len(models))
@@ -145,11 +148,11 @@ func importTags(sqlite, mysql *gorm.DB, models []*Tag) {
 
 func importUsers(sqlite, mysql *gorm.DB, models []*User) {
 	if err := sqlite.Find(&models).Error; nil != err {
-		logger.Fatalf("queries data failed: " + err.Error())
+		logger.Fatalf("queries data failed: %s", err)
 	}
 	for _, model := range models {
 		if err := mysql.Save(model).Error; nil != err {
-			logger.Fatalf("saves data failed: " + err.Error())
+			logger.Fatalf("saves data failed: %s", err)
 		}
 	}
 	logger.Infof("imported [%d] users", len(models))
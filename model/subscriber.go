@@ -0,0 +1,31 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// Subscriber model, email subscribers of the blog newsletter.
+type Subscriber struct {
+	Model
+
+	Email     string    `sql:"index" gorm:"size:255" json:"email"`
+	Token     string    `sql:"index" gorm:"size:64" json:"token"` // token of confirming and unsubscribing
+	Confirmed bool      `json:"confirmed"`                        // double opt-in confirmed or not
+	SentAt    time.Time `json:"sentAt"`                           // articles created before this time have been mailed
+
+	BlogID uint64 `sql:"index" json:"blogID"`
+}
@@ -24,22 +24,37 @@ import (
 
 // ThemeArticle represents theme article.
 type ThemeArticle struct {
-	ID             uint64        `json:",omitempty"`
-	Abstract       template.HTML `json:"abstract"`
-	Author         *ThemeAuthor  `json:",omitempty"`
-	CreatedAt      string        `json:",omitempty"`
-	CreatedAtYear  string        `json:",omitempty"`
-	CreatedAtMonth string        `json:",omitempty"`
-	CreatedAtDay   string        `json:",omitempty"`
-	Title          string        `json:"title"`
-	Tags           []*ThemeTag   `json:"tags"`
-	URL            string        `json:"url"`
-	Topped         bool          `json:",omitempty"`
-	ViewCount      int           `json:",omitempty"`
-	CommentCount   int           `json:",omitempty"`
-	ThumbnailURL   string        `json:",omitempty"`
-	Content        template.HTML `json:",omitempty"`
-	Editable       bool          `json:",omitempty"`
+	ID             uint64          `json:",omitempty"`
+	Abstract       template.HTML   `json:"abstract"`
+	Description    string          `json:",omitempty"`
+	Author         *ThemeAuthor    `json:",omitempty"`
+	CreatedAt      string          `json:",omitempty"`
+	CreatedAtYear  string          `json:",omitempty"`
+	CreatedAtMonth string          `json:",omitempty"`
+	CreatedAtDay   string          `json:",omitempty"`
+	Title          string          `json:"title"`
+	Tags           []*ThemeTag     `json:"tags"`
+	URL            string          `json:"url"`
+	Topped         bool            `json:",omitempty"`
+	ViewCount      int             `json:",omitempty"`
+	CommentCount   int             `json:",omitempty"`
+	ThumbnailURL   string          `json:",omitempty"`
+	ShareImageURL  string          `json:",omitempty"`
+	Content        template.HTML   `json:",omitempty"`
+	TOC            []*util.TOCItem `json:",omitempty"` // nested headings of the content, anchored by their IDs
+	Editable       bool            `json:",omitempty"`
+	Layout         string          `json:",omitempty"`
+}
+
+// ThemePage represents theme static page.
+type ThemePage struct {
+	ID        uint64
+	Title     string
+	URL       string
+	Content   template.HTML
+	Author    *ThemeAuthor
+	UpdatedAt string
+	Editable  bool
 }
 
 // ThemeTag represents theme tag.
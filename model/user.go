@@ -31,14 +31,24 @@ type User struct {
 	Locale            string `gorm:"size:32" json:"locale"`
 	TotalArticleCount int    `json:"totalArticleCount"`
 	GithubId          string `gorm:"255" json:"githubId"`
+	Email             string `gorm:"size:255" json:"-"`
+	Password          string `gorm:"size:255" json:"-"` // bcrypt hash of the password in authentication mode "local"
+	ResetToken        string `gorm:"size:64" json:"-"`  // SHA-256 hash of the password reset token
+	ResetExpiredAt    uint64 `json:"-"`                 // expiration (in millisecond) of the password reset token
+	TOTPSecret        string `gorm:"size:64" json:"-"`
+	TOTPEnabled       bool   `json:"-"`
+	TOTPBackupCodes   string `gorm:"type:text" json:"-"` // comma separated SHA-256 hashes of the unused backup codes
+	TOTPLastStep      int64  `json:"-"`                  // time step of the last accepted TOTP code, older codes are rejected
+	FeedToken         string `gorm:"size:32" json:"-"`   // token of the feed of the timeline of followed blogs
 }
 
-// User roles.
+// User roles, a smaller role has more permissions.
 const (
 	UserRoleNoLogin = iota
 	UserRolePlatformAdmin
-	UserRoleBlogAdmin
-	UserRoleBlogUser
+	UserRoleBlogAdmin  // manages settings and users of the blog
+	UserRoleBlogEditor // edits and publishes all articles of the blog
+	UserRoleBlogUser   // author, edits own drafts only
 )
 
 // AvatarURLWithSize returns avatar URL with the specified size.
@@ -0,0 +1,32 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// UserSession model, a login session of a user. Sessions are registered server-side so that users can list and revoke
+// them.
+type UserSession struct {
+	Model
+
+	SIDHash    string    `sql:"index" gorm:"column:sid_hash;size:64" json:"-"` // SHA-256 of the session ID kept in the session cookie
+	UserID     uint64    `sql:"index" json:"userID"`
+	IP         string    `gorm:"size:128" json:"ip"`
+	UserAgent  string    `gorm:"size:255" json:"userAgent"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiredAt  time.Time `json:"expiredAt"`
+}
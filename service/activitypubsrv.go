@@ -0,0 +1,498 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// ActivityPub service, federates blogs to the fediverse (Mastodon, Pleroma, etc.).
+var ActivityPub = &activityPubService{
+	mutex:      &sync.Mutex{},
+	deliveries: make(chan *apDelivery, 1024),
+	httpClient: util.NewPublicHTTPClient(30 * time.Second), // actors and inboxes are specified by remote servers
+}
+
+type activityPubService struct {
+	mutex      *sync.Mutex
+	deliveries chan *apDelivery
+	workerOnce sync.Once
+	httpClient *http.Client
+}
+
+// ActivityPub vocabulary.
+const (
+	ActivityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	ActivityStreamsPublic  = "https://www.w3.org/ns/activitystreams#Public"
+	ActivityContentType    = "application/activity+json"
+)
+
+// maxDeliveryAttempts is the max count of attempts delivering an activity to an inbox.
+const maxDeliveryAttempts = 5
+
+// maxActivitySize is the max size of activities and actors fetched from remote servers.
+const maxActivitySize = 1024 * 1024
+
+// apDelivery represents an activity pending delivery to a remote inbox.
+type apDelivery struct {
+	BlogID   uint64
+	Inbox    string
+	Activity map[string]interface{}
+	Attempts int
+}
+
+// RemoteActor represents an actor of a remote server.
+type RemoteActor struct {
+	ID                string `json:"id"`
+	Type              string `json:"type"`
+	PreferredUsername string `json:"preferredUsername"`
+	Name              string `json:"name"`
+	URL               string `json:"url"`
+	Inbox             string `json:"inbox"`
+	Endpoints         struct {
+		SharedInbox string `json:"sharedInbox"`
+	} `json:"endpoints"`
+	Icon struct {
+		URL string `json:"url"`
+	} `json:"icon"`
+	PublicKey struct {
+		ID           string `json:"id"`
+		Owner        string `json:"owner"`
+		PublicKeyPem string `json:"publicKeyPem"`
+	} `json:"publicKey"`
+}
+
+// ActorID returns the actor ID of the specified blog.
+func (srv *activityPubService) ActorID(blogID uint64) string {
+	return srv.blogURL(blogID) + util.PathActivityPub + "/actor"
+}
+
+// InboxURL returns the inbox URL of the specified blog.
+func (srv *activityPubService) InboxURL(blogID uint64) string {
+	return srv.blogURL(blogID) + util.PathActivityPub + "/inbox"
+}
+
+// OutboxURL returns the outbox URL of the specified blog.
+func (srv *activityPubService) OutboxURL(blogID uint64) string {
+	return srv.blogURL(blogID) + util.PathActivityPub + "/outbox"
+}
+
+// FollowersURL returns the followers collection URL of the specified blog.
+func (srv *activityPubService) FollowersURL(blogID uint64) string {
+	return srv.blogURL(blogID) + util.PathActivityPub + "/followers"
+}
+
+// GetPublicKey gets the PEM encoded public key of the specified blog, the key pair is generated if not exists.
+func (srv *activityPubService) GetPublicKey(blogID uint64) (string, error) {
+	_, ret, err := srv.getKeyPair(blogID)
+
+	return ret, err
+}
+
+func (srv *activityPubService) getKeyPair(blogID uint64) (privateKey *rsa.PrivateKey, publicKeyPEM string, err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	privateKeySetting := Setting.GetSetting(model.SettingCategoryActivityPub, model.SettingNameActivityPubPrivateKey, blogID)
+	publicKeySetting := Setting.GetSetting(model.SettingCategoryActivityPub, model.SettingNameActivityPubPublicKey, blogID)
+	if nil != privateKeySetting && nil != publicKeySetting {
+		privateKey, err = util.ParsePrivateKey(privateKeySetting.Value)
+
+		return privateKey, publicKeySetting.Value, err
+	}
+
+	privateKeyPEM, publicKeyPEM, err := util.GenerateKeyPair()
+	if nil != err {
+		return
+	}
+	if err = Setting.UpdateSettings(model.SettingCategoryActivityPub, []*model.Setting{
+		{Name: model.SettingNameActivityPubPrivateKey, Value: privateKeyPEM},
+		{Name: model.SettingNameActivityPubPublicKey, Value: publicKeyPEM},
+	}, blogID); nil != err {
+		return
+	}
+	logger.Infof("generated ActivityPub key pair of blog [%d]", blogID)
+	privateKey, err = util.ParsePrivateKey(privateKeyPEM)
+
+	return
+}
+
+// GetFollowerCount gets the follower count of the specified blog.
+func (srv *activityPubService) GetFollowerCount(blogID uint64) (ret int) {
+	if err := db.Model(&model.Follower{}).Where("`blog_id` = ?", blogID).Count(&ret).Error; nil != err {
+		logger.Errorf("count followers failed: %s", err)
+	}
+
+	return
+}
+
+// AddFollower adds the specified follower, updates the inboxes if the actor is following already.
+func (srv *activityPubService) AddFollower(follower *model.Follower) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	existing := &model.Follower{}
+	if err := db.Where("`actor_id` = ? AND `blog_id` = ?", follower.ActorID, follower.BlogID).First(existing).Error; nil == err {
+		follower.ID = existing.ID
+		follower.CreatedAt = existing.CreatedAt
+	}
+
+	return db.Save(follower).Error
+}
+
+// RemoveFollower removes the follower specified by the actor ID.
+func (srv *activityPubService) RemoveFollower(actorID string, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Where("`actor_id` = ? AND `blog_id` = ?", actorID, blogID).Delete(&model.Follower{}).Error
+}
+
+// FetchActor fetches the remote actor specified by the actor ID or key ID. The fetched actor must claim the fetched
+// URL as its ID, so a server can't speak for actors of other servers.
+func (srv *activityPubService) FetchActor(id string) (*RemoteActor, error) {
+	if end := strings.Index(id, "#"); 0 < end {
+		id = id[:end]
+	}
+	if !util.IsPublicHTTPSURL(id) {
+		return nil, errors.New("invalid actor [" + id + "]")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, id, nil)
+	if nil != err {
+		return nil, err
+	}
+	req.Header.Set("Accept", ActivityContentType)
+	req.Header.Set("User-Agent", model.UserAgent)
+	response, err := srv.httpClient.Do(req)
+	if nil != err {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if http.StatusOK != response.StatusCode {
+		return nil, errors.New("fetch actor [" + id + "] failed: " + response.Status)
+	}
+
+	ret := &RemoteActor{}
+	if err = json.NewDecoder(io.LimitReader(response.Body, maxActivitySize)).Decode(ret); nil != err {
+		return nil, err
+	}
+	if id != ret.ID {
+		return nil, errors.New("actor [" + ret.ID + "] mismatches its URL [" + id + "]")
+	}
+	if !util.IsPublicHTTPSURL(ret.Inbox) || ("" != ret.Endpoints.SharedInbox && !util.IsPublicHTTPSURL(ret.Endpoints.SharedInbox)) {
+		return nil, errors.New("invalid inbox of actor [" + id + "]")
+	}
+
+	return ret, nil
+}
+
+// VerifyRequest verifies the HTTP signature of the specified inbox request, returns the signing actor.
+func (srv *activityPubService) VerifyRequest(req *http.Request, body []byte) (*RemoteActor, error) {
+	keyID := util.GetSignatureKeyID(req)
+	if "" == keyID {
+		return nil, errors.New("request is not signed")
+	}
+	actor, err := srv.FetchActor(keyID)
+	if nil != err {
+		return nil, err
+	}
+	if keyID != actor.PublicKey.ID || actor.ID != actor.PublicKey.Owner {
+		return nil, errors.New("key [" + keyID + "] is not owned by actor [" + actor.ID + "]")
+	}
+	publicKey, err := util.ParsePublicKey(actor.PublicKey.PublicKeyPem)
+	if nil != err {
+		return nil, err
+	}
+	if err = util.VerifyRequest(req, body, publicKey); nil != err {
+		return nil, err
+	}
+
+	return actor, nil
+}
+
+// NewArticleObject creates an ActivityStreams Article object of the specified article.
+func (srv *activityPubService) NewArticleObject(article *model.Article) map[string]interface{} {
+	blogURL := srv.blogURL(article.BlogID)
+	mdResult := util.Markdown(article.Content)
+	summary := article.Abstract
+	if "" == summary {
+		summary = mdResult.AbstractText
+	}
+	var tags []map[string]interface{}
+	for _, tag := range strings.Split(article.Tags, ",") {
+		if tag = strings.TrimSpace(tag); "" == tag {
+			continue
+		}
+
+		tags = append(tags, map[string]interface{}{
+			"type": "Hashtag",
+			"href": blogURL + util.PathTags + "/" + tag,
+			"name": "#" + tag,
+		})
+	}
+
+	return map[string]interface{}{
+		"id":           blogURL + article.Path,
+		"type":         "Article",
+		"name":         article.Title,
+		"summary":      summary,
+		"content":      mdResult.ContentHTML,
+		"url":          blogURL + article.Path,
+		"attributedTo": srv.ActorID(article.BlogID),
+		"to":           []string{ActivityStreamsPublic},
+		"cc":           []string{srv.FollowersURL(article.BlogID)},
+		"published":    article.CreatedAt.Format(time.RFC3339),
+		"updated":      article.UpdatedAt.Format(time.RFC3339),
+		"tag":          tags,
+	}
+}
+
+// NewArticleActivity creates an activity of the specified type (Create or Update) wrapping the specified article.
+func (srv *activityPubService) NewArticleActivity(activityType string, article *model.Article) map[string]interface{} {
+	object := srv.NewArticleObject(article)
+	id := object["id"].(string) + "#" + strings.ToLower(activityType)
+	if "Update" == activityType {
+		id += "-" + article.UpdatedAt.Format("20060102150405")
+	}
+
+	return map[string]interface{}{
+		"@context":  ActivityStreamsContext,
+		"id":        id,
+		"type":      activityType,
+		"actor":     object["attributedTo"],
+		"published": object["published"],
+		"to":        object["to"],
+		"cc":        object["cc"],
+		"object":    object,
+	}
+}
+
+// PublishArticle delivers the specified article to followers of its blog.
+func (srv *activityPubService) PublishArticle(activityType string, article *model.Article) {
+	srv.deliverToFollowers(article.BlogID, srv.NewArticleActivity(activityType, article))
+}
+
+// DeleteArticle delivers a Delete activity of the specified article to followers of its blog, it's used when a
+// federated article is no longer public, for example unpublished, unlisted, password protected or trashed.
+func (srv *activityPubService) DeleteArticle(article *model.Article) {
+	actorID := srv.ActorID(article.BlogID)
+	objectID := srv.blogURL(article.BlogID) + article.Path
+	srv.deliverToFollowers(article.BlogID, map[string]interface{}{
+		"@context": ActivityStreamsContext,
+		"id":       objectID + "#delete-" + strconv.FormatUint(util.CurrentMillisecond(), 10),
+		"type":     "Delete",
+		"actor":    actorID,
+		"to":       []string{ActivityStreamsPublic},
+		"object": map[string]interface{}{
+			"id":   objectID,
+			"type": "Tombstone",
+		},
+	})
+}
+
+// deliverToFollowers delivers the specified activity to followers of the specified blog, followers sharing an inbox
+// receive it once.
+func (srv *activityPubService) deliverToFollowers(blogID uint64, activity map[string]interface{}) {
+	var followers []*model.Follower
+	if err := db.Where("`blog_id` = ?", blogID).Find(&followers).Error; nil != err {
+		logger.Errorf("get followers failed: %s", err)
+
+		return
+	}
+	inboxes := map[string]bool{}
+	for _, follower := range followers {
+		inbox := follower.SharedInbox
+		if "" == inbox {
+			inbox = follower.Inbox
+		}
+		if inboxes[inbox] {
+			continue
+		}
+
+		inboxes[inbox] = true
+		srv.Deliver(blogID, inbox, activity)
+	}
+}
+
+// Accept accepts the specified follow activity.
+func (srv *activityPubService) Accept(blogID uint64, follow map[string]interface{}, inbox string) {
+	actorID := srv.ActorID(blogID)
+	followID, _ := follow["id"].(string)
+	srv.Deliver(blogID, inbox, map[string]interface{}{
+		"@context": ActivityStreamsContext,
+		"id":       actorID + "#accepts/" + strconv.FormatUint(util.CurrentMillisecond(), 10),
+		"type":     "Accept",
+		"actor":    actorID,
+		"object": map[string]interface{}{
+			"id":     followID,
+			"type":   "Follow",
+			"actor":  follow["actor"],
+			"object": actorID,
+		},
+	})
+}
+
+// AddReplyComment adds the specified note replying an article as a comment of the article.
+func (srv *activityPubService) AddReplyComment(actor *RemoteActor, note map[string]interface{}, blogID uint64) error {
+	inReplyTo, _ := note["inReplyTo"].(string)
+	blogURL := srv.blogURL(blogID)
+	if !strings.HasPrefix(inReplyTo, blogURL+"/") {
+		return nil // not a reply to this blog
+	}
+	article := Article.GetArticleByPath(strings.TrimPrefix(inReplyTo, blogURL), blogID)
+	if nil == article {
+		return errors.New("not found article [" + inReplyTo + "]")
+	}
+	if model.ArticleVisibilityPassword == article.Visibility {
+		return errors.New("the commented article is locked")
+	}
+	commentableSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicCommentable, blogID)
+	if nil == commentableSetting || "true" != commentableSetting.Value || !article.Commentable {
+		return errors.New("not allow comment")
+	}
+
+	content, _ := note["content"].(string)
+	if "" == strings.TrimSpace(content) {
+		return errors.New("empty reply")
+	}
+	authorName := actor.PreferredUsername
+	if "" == authorName {
+		authorName = actor.Name
+	}
+	if runes := []rune(authorName); 32 < len(runes) {
+		authorName = string(runes[:32])
+	}
+	authorURL := actor.URL
+	if "" == authorURL {
+		authorURL = actor.ID
+	}
+
+	comment := &model.Comment{
+		BlogID:          blogID,
+		ArticleID:       article.ID,
+		AuthorID:        model.SyncCommentAuthorID,
+		Content:         content,
+		AuthorName:      authorName,
+		AuthorURL:       authorURL,
+		AuthorAvatarURL: actor.Icon.URL,
+	}
+	Comment.ModerateComment(comment, &SpamContext{
+		Permalink:  inReplyTo,
+		AuthorName: authorName,
+		AuthorURL:  authorURL,
+	})
+	if err := Comment.AddComment(comment); nil != err {
+		return err
+	}
+	Notification.NotifyComment(comment)
+
+	// federated comments should not be pushed to the community
+	return Comment.UpdatePushedAt(comment)
+}
+
+// Deliver queues the specified activity for delivery to the specified inbox.
+func (srv *activityPubService) Deliver(blogID uint64, inbox string, activity map[string]interface{}) {
+	srv.workerOnce.Do(func() {
+		go srv.deliverLoop()
+	})
+
+	select {
+	case srv.deliveries <- &apDelivery{BlogID: blogID, Inbox: inbox, Activity: activity}:
+	default:
+		logger.Warnf("ActivityPub delivery queue is full, dropped activity [%v] to inbox [%s]", activity["id"], inbox)
+	}
+}
+
+func (srv *activityPubService) deliverLoop() {
+	for delivery := range srv.deliveries {
+		err := srv.deliver(delivery)
+		if nil == err {
+			continue
+		}
+
+		delivery.Attempts++
+		if maxDeliveryAttempts <= delivery.Attempts {
+			logger.Errorf("deliver activity [%v] to inbox [%s] failed, gave up after [%d] attempts: %s",
+				delivery.Activity["id"], delivery.Inbox, delivery.Attempts, err)
+
+			continue
+		}
+
+		// retries with exponential backoff: 1m, 2m, 4m, 8m
+		backoff := time.Minute << uint(delivery.Attempts-1)
+		logger.Warnf("deliver activity [%v] to inbox [%s] failed, retries in %s: %s",
+			delivery.Activity["id"], delivery.Inbox, backoff, err)
+		retry := delivery
+		time.AfterFunc(backoff, func() {
+			srv.deliveries <- retry
+		})
+	}
+}
+
+func (srv *activityPubService) deliver(delivery *apDelivery) error {
+	body, err := json.Marshal(delivery.Activity)
+	if nil != err {
+		return err
+	}
+	privateKey, _, err := srv.getKeyPair(delivery.BlogID)
+	if nil != err {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.Inbox, bytes.NewReader(body))
+	if nil != err {
+		return err
+	}
+	req.Header.Set("Content-Type", ActivityContentType)
+	req.Header.Set("User-Agent", model.UserAgent)
+	if err = util.SignRequest(req, body, srv.ActorID(delivery.BlogID)+"#main-key", privateKey); nil != err {
+		return err
+	}
+
+	response, err := srv.httpClient.Do(req)
+	if nil != err {
+		return err
+	}
+	defer response.Body.Close()
+	if http.StatusOK > response.StatusCode || http.StatusMultipleChoices <= response.StatusCode {
+		data, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+
+		return errors.New("status code [" + response.Status + "], response [" + string(data) + "]")
+	}
+
+	return nil
+}
+
+func (srv *activityPubService) blogURL(blogID uint64) string {
+	blogURLSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+
+	return blogURLSetting.Value
+}
@@ -0,0 +1,56 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestAddFollower(t *testing.T) {
+	actorID := "https://mastodon.social/users/pipe"
+	for i := 0; i < 2; i++ {
+		follower := &model.Follower{ActorID: actorID, Inbox: actorID + "/inbox", BlogID: 99}
+		if err := ActivityPub.AddFollower(follower); nil != err {
+			t.Error(err)
+
+			return
+		}
+	}
+	if count := ActivityPub.GetFollowerCount(99); 1 != count {
+		t.Errorf("expected is [%d], actual is [%d]", 1, count)
+	}
+
+	if err := ActivityPub.RemoveFollower(actorID, 99); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if count := ActivityPub.GetFollowerCount(99); 0 != count {
+		t.Errorf("expected is [%d], actual is [%d]", 0, count)
+	}
+}
+
+func TestFetchActor(t *testing.T) {
+	for _, id := range []string{"http://mastodon.social/users/pipe", "https://127.0.0.1/users/pipe#main-key",
+		"https://10.0.0.1/users/pipe", "file:///etc/passwd"} {
+		if _, err := ActivityPub.FetchActor(id); nil == err {
+			t.Errorf("fetch actor [%s] should fail", id)
+		}
+	}
+}
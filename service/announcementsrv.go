@@ -0,0 +1,106 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+)
+
+// Announcement service.
+var Announcement = &announcementService{}
+
+type announcementService struct {
+}
+
+// ErrAnnouncementWindow is returned if the end of an announcement is not after its start.
+var ErrAnnouncementWindow = errors.New("end of the announcement should be after its start")
+
+// GetAnnouncement returns the content and the time window of the platform announcement, the start or the end is zero
+// if not specified.
+func (srv *announcementService) GetAnnouncement() (content string, start, end time.Time) {
+	if setting := Setting.GetSetting(model.SettingCategoryAnnouncement, model.SettingNameAnnouncementContent, PlatformBlogID); nil != setting {
+		content = setting.Value
+	}
+	start = srv.getTime(model.SettingNameAnnouncementStart)
+	end = srv.getTime(model.SettingNameAnnouncementEnd)
+
+	return
+}
+
+// GetActiveAnnouncement returns the content of the platform announcement if the specified time is in its time window,
+// returns "" otherwise.
+func (srv *announcementService) GetActiveAnnouncement(now time.Time) string {
+	content, start, end := srv.GetAnnouncement()
+	if "" == content || (!start.IsZero() && now.Before(start)) || (!end.IsZero() && !now.Before(end)) {
+		return ""
+	}
+
+	return content
+}
+
+// UpdateAnnouncement updates the platform announcement, an empty content removes it.
+func (srv *announcementService) UpdateAnnouncement(content string, start, end time.Time) error {
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return ErrAnnouncementWindow
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(time.RFC3339)
+	}
+	values := map[string]string{
+		model.SettingNameAnnouncementContent: strings.TrimSpace(content),
+		model.SettingNameAnnouncementStart:   formatTime(start),
+		model.SettingNameAnnouncementEnd:     formatTime(end),
+	}
+	var settings []*model.Setting
+	for name, value := range values {
+		settings = append(settings, &model.Setting{Category: model.SettingCategoryAnnouncement, Name: name, Value: value,
+			BlogID: PlatformBlogID})
+	}
+
+	if err := Setting.UpdateSettings(model.SettingCategoryAnnouncement, settings, PlatformBlogID); nil != err {
+		return err
+	}
+	cache.Page.PurgeAll()
+
+	return nil
+}
+
+func (srv *announcementService) getTime(name string) time.Time {
+	setting := Setting.GetSetting(model.SettingCategoryAnnouncement, name, PlatformBlogID)
+	if nil == setting || "" == setting.Value {
+		return time.Time{}
+	}
+
+	ret, err := time.Parse(time.RFC3339, setting.Value)
+	if nil != err {
+		logger.Warnf("announcement setting [%s] is not a RFC3339 time [%s]", name, setting.Value)
+
+		return time.Time{}
+	}
+
+	return ret
+}
@@ -0,0 +1,54 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestGetActiveAnnouncement(t *testing.T) {
+	defer removeSettings(model.SettingCategoryAnnouncement, PlatformBlogID)
+
+	now := time.Now()
+	if err := Announcement.UpdateAnnouncement("Maintenance tonight", now.Add(time.Hour), now); ErrAnnouncementWindow != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrAnnouncementWindow, err)
+	}
+
+	if err := Announcement.UpdateAnnouncement(" Maintenance tonight ", now.Add(-time.Hour), now.Add(time.Hour)); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if content := Announcement.GetActiveAnnouncement(now); "Maintenance tonight" != content {
+		t.Errorf("expected is [%s], actual is [%s]", "Maintenance tonight", content)
+	}
+	if content := Announcement.GetActiveAnnouncement(now.Add(2 * time.Hour)); "" != content {
+		t.Errorf("expected is [%s], actual is [%s]", "", content)
+	}
+
+	if err := Announcement.UpdateAnnouncement("", time.Time{}, time.Time{}); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if content := Announcement.GetActiveAnnouncement(now); "" != content {
+		t.Errorf("expected is [%s], actual is [%s]", "", content)
+	}
+}
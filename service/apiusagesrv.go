@@ -0,0 +1,134 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIUsage service.
+var APIUsage = &apiUsageService{
+	mutex:  &sync.Mutex{},
+	usages: map[string]*apiUsage{},
+}
+
+type apiUsageService struct {
+	mutex  *sync.Mutex
+	usages map[string]*apiUsage // client key -> usage
+}
+
+// apiUsageWindow is the count of minutes of recent request counting.
+const apiUsageWindow = 5
+
+// maxAPIUsageClients is the max count of tracked clients, the least recently active ones are dropped if exceeded.
+const maxAPIUsageClients = 4096
+
+type apiUsage struct {
+	key         string
+	count       int64
+	throttled   int64
+	lastAt      time.Time
+	minutes     [apiUsageWindow]int64 // request counts of recent minutes, indexed by minute % apiUsageWindow
+	minuteMarks [apiUsageWindow]int64 // minutes of the counts
+}
+
+// APIUsageStat represents API usage of a client.
+type APIUsageStat struct {
+	Client          string    `json:"client"`
+	Count           int64     `json:"count"`
+	RecentCount     int64     `json:"recentCount"` // requests in recent minutes
+	ThrottledCount  int64     `json:"throttledCount"`
+	LastRequestedAt time.Time `json:"lastRequestedAt"`
+}
+
+// Record records an API request of the specified client, a client is a user (user:name) or an IP (ip:addr).
+func (srv *apiUsageService) Record(client string, throttled bool) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	now := time.Now()
+	usage := srv.usages[client]
+	if nil == usage {
+		if maxAPIUsageClients <= len(srv.usages) {
+			srv.evict()
+		}
+		usage = &apiUsage{key: client}
+		srv.usages[client] = usage
+	}
+
+	usage.count++
+	if throttled {
+		usage.throttled++
+	}
+	usage.lastAt = now
+	minute := now.Unix() / 60
+	i := minute % apiUsageWindow
+	if usage.minuteMarks[i] != minute {
+		usage.minuteMarks[i] = minute
+		usage.minutes[i] = 0
+	}
+	usage.minutes[i]++
+}
+
+// evict drops the least recently active client.
+func (srv *apiUsageService) evict() {
+	var oldest *apiUsage
+	for _, usage := range srv.usages {
+		if nil == oldest || usage.lastAt.Before(oldest.lastAt) {
+			oldest = usage
+		}
+	}
+	if nil != oldest {
+		delete(srv.usages, oldest.key)
+	}
+}
+
+// GetTopUsages gets API usages of the most active clients in recent minutes.
+func (srv *apiUsageService) GetTopUsages(size int) (ret []*APIUsageStat) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	minute := time.Now().Unix() / 60
+	for _, usage := range srv.usages {
+		stat := &APIUsageStat{
+			Client:          usage.key,
+			Count:           usage.count,
+			ThrottledCount:  usage.throttled,
+			LastRequestedAt: usage.lastAt,
+		}
+		for i, mark := range usage.minuteMarks {
+			if minute-mark < apiUsageWindow {
+				stat.RecentCount += usage.minutes[i]
+			}
+		}
+		ret = append(ret, stat)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].RecentCount == ret[j].RecentCount {
+			return ret[i].Count > ret[j].Count
+		}
+
+		return ret[i].RecentCount > ret[j].RecentCount
+	})
+	if size < len(ret) {
+		ret = ret[:size]
+	}
+
+	return
+}
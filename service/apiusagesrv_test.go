@@ -0,0 +1,38 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+)
+
+func TestGetTopUsages(t *testing.T) {
+	APIUsage.Record("ip:127.0.0.1", false)
+	APIUsage.Record("user:pipe", false)
+	APIUsage.Record("user:pipe", true)
+
+	usages := APIUsage.GetTopUsages(1)
+	if 1 != len(usages) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(usages))
+
+		return
+	}
+	usage := usages[0]
+	if "user:pipe" != usage.Client || 2 != usage.Count || 2 != usage.RecentCount || 1 != usage.ThrottledCount {
+		t.Errorf("unexpected usage [%+v]", usage)
+	}
+}
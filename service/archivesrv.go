@@ -18,6 +18,7 @@
 package service
 
 import (
+	"strconv"
 	"sync"
 
 	"github.com/b3log/pipe/model"
@@ -42,6 +43,31 @@ func (srv *archiveService) GetArchives(blogID uint64) []*model.Archive {
 	return ret
 }
 
+// GetYearArchives gets yearly archives of the specified blog, months of the returned archives are empty.
+func (srv *archiveService) GetYearArchives(blogID uint64) (ret []*model.Archive) {
+	for _, archive := range srv.GetArchives(blogID) {
+		if 0 < len(ret) && ret[len(ret)-1].Year == archive.Year {
+			ret[len(ret)-1].ArticleCount += archive.ArticleCount
+
+			continue
+		}
+
+		ret = append(ret, &model.Archive{Year: archive.Year, ArticleCount: archive.ArticleCount, BlogID: blogID})
+	}
+
+	return
+}
+
+// GetGranularArchives gets archives of the specified blog with the archive granularity configured by the blog.
+func (srv *archiveService) GetGranularArchives(blogID uint64) []*model.Archive {
+	granularitySetting := Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceArchiveGranularity, blogID)
+	if nil != granularitySetting && strconv.Itoa(model.SettingPreferenceArchiveGranularityValueYear) == granularitySetting.Value {
+		return srv.GetYearArchives(blogID)
+	}
+
+	return srv.GetArchives(blogID)
+}
+
 func (srv *archiveService) UnArchiveArticleWithoutTx(tx *gorm.DB, article *model.Article) error {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
@@ -102,6 +128,21 @@ func (srv *archiveService) ArchiveArticleWithoutTx(tx *gorm.DB, article *model.A
 	return nil
 }
 
+// GetYearArchive gets the yearly archive of the specified year, returns nil if not found.
+func (srv *archiveService) GetYearArchive(year string, blogID uint64) *model.Archive {
+	var archives []*model.Archive
+	if err := db.Where("`year` = ? AND `blog_id` = ? AND `article_count` > 0", year, blogID).Find(&archives).Error; nil != err || 1 > len(archives) {
+		return nil
+	}
+
+	ret := &model.Archive{Year: year, BlogID: blogID}
+	for _, archive := range archives {
+		ret.ArticleCount += archive.ArticleCount
+	}
+
+	return ret
+}
+
 func (srv *archiveService) GetArchive(year, month string, blogID uint64) *model.Archive {
 	ret := &model.Archive{}
 	if err := db.Where("`year` = ? AND `month` = ? AND `blog_id` = ?",
@@ -0,0 +1,84 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+)
+
+// ArticleLock service, soft locks of articles being edited in console. A lock is held by the user who opened the
+// article for editing first, and expires if the editor of the user stops refreshing it.
+var ArticleLock = &articleLockService{
+	mutex: &sync.Mutex{},
+}
+
+type articleLockService struct {
+	mutex *sync.Mutex
+}
+
+// ArticleLockTTL is the time to live of article locks, editors refresh locks they hold within it.
+const ArticleLockTTL = time.Minute
+
+// Lock acquires or refreshes the lock of the specified article for the specified user. Returns the lock and whether
+// it's held by the user, the lock is held by another user if not.
+func (srv *articleLockService) Lock(articleID uint64, user *model.User) (lock *cache.ArticleEditLock, acquired bool) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	now := time.Now()
+	lock = cache.ArticleLock.Get(articleID)
+	if nil != lock && user.ID != lock.UserID && now.Sub(lock.RefreshedAt) < ArticleLockTTL {
+		return lock, false
+	}
+	if nil == lock || user.ID != lock.UserID {
+		lock = &cache.ArticleEditLock{
+			ArticleID: articleID,
+			UserID:    user.ID,
+			UserName:  user.Name,
+			AvatarURL: user.AvatarURL,
+			LockedAt:  now,
+		}
+	}
+	lock.RefreshedAt = now
+	cache.ArticleLock.Put(lock, ArticleLockTTL)
+
+	return lock, true
+}
+
+// Unlock releases the lock of the specified article if it's held by the specified user.
+func (srv *articleLockService) Unlock(articleID, userID uint64) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if lock := cache.ArticleLock.Get(articleID); nil != lock && userID == lock.UserID {
+		cache.ArticleLock.Remove(articleID)
+	}
+}
+
+// GetLock returns the unexpired lock of the specified article, returns nil if the article is not locked.
+func (srv *articleLockService) GetLock(articleID uint64) *cache.ArticleEditLock {
+	lock := cache.ArticleLock.Get(articleID)
+	if nil == lock || time.Since(lock.RefreshedAt) >= ArticleLockTTL {
+		return nil
+	}
+
+	return lock
+}
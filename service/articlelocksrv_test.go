@@ -0,0 +1,50 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestArticleLock(t *testing.T) {
+	alice := &model.User{Model: model.Model{ID: 101}, Name: "alice"}
+	bob := &model.User{Model: model.Model{ID: 102}, Name: "bob"}
+
+	if lock, acquired := ArticleLock.Lock(1, alice); !acquired || alice.ID != lock.UserID {
+		t.Errorf("alice should acquire the lock")
+
+		return
+	}
+	if lock, acquired := ArticleLock.Lock(1, bob); acquired || alice.ID != lock.UserID {
+		t.Errorf("the lock should be held by alice")
+	}
+	if lock, acquired := ArticleLock.Lock(1, alice); !acquired || alice.ID != lock.UserID {
+		t.Errorf("alice should refresh the lock")
+	}
+
+	ArticleLock.Unlock(1, bob.ID)
+	if lock := ArticleLock.GetLock(1); nil == lock || alice.ID != lock.UserID {
+		t.Errorf("only the holder could release the lock")
+	}
+	ArticleLock.Unlock(1, alice.ID)
+	if lock, acquired := ArticleLock.Lock(1, bob); !acquired || bob.ID != lock.UserID {
+		t.Errorf("bob should acquire the released lock")
+	}
+	ArticleLock.Unlock(1, bob.ID)
+}
@@ -17,21 +17,26 @@
 package service
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
 	"github.com/jinzhu/gorm"
 	"github.com/parnurzeal/gorequest"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Article service.
@@ -43,6 +48,28 @@ type articleService struct {
 	mutex *sync.Mutex
 }
 
+// ErrArticleConflict is returned when updating an article which has been updated by others.
+var ErrArticleConflict = errors.New("article has been updated by others")
+
+// ErrInvalidArticleStatus is returned if the status of an article is neither ArticleStatusOK nor ArticleStatusDraft.
+var ErrInvalidArticleStatus = errors.New("invalid article status")
+
+// ArticleStatusUnchanged keeps the status of the article in UpdateArticle, used by clients which don't send it.
+const ArticleStatusUnchanged = -1
+
+// ErrReservedPath is returned if the path of an article or a category collides with a reserved path of the router.
+var ErrReservedPath = errors.New("path is reserved")
+
+// ErrArticlePassword is returned if a password-protected article misses its access password or the password is too
+// long.
+var ErrArticlePassword = errors.New("invalid article password")
+
+// articlePasswordMaxLength is the max length of article access passwords, bcrypt ignores the bytes after.
+const articlePasswordMaxLength = 72
+
+// ErrArticleNotInTrash is returned if the article to restore or purge is not in the trash.
+var ErrArticleNotInTrash = errors.New("article is not in the trash")
+
 // Article pagination arguments of admin console.
 const (
 	adminConsoleArticleListPageSize   = 15
@@ -51,29 +78,54 @@ const (
 
 func (srv *articleService) GetPlatMostViewArticles(size int) (ret []*model.Article) {
 	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `path`, `view_count`, `comment_count`, `blog_id`").
-		Where("`status` = ?", model.ArticleStatusOK).
+		Where("`status` = ? AND `visibility` = ?", model.ArticleStatusOK, model.ArticleVisibilityPublic).
 		Order("`view_count` DESC, `created_at` DESC").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get platform most view articles failed: " + err.Error())
+		logger.Errorf("get platform most view articles failed: %s", err)
 	}
 
 	return
 }
 
 func (srv *articleService) GetUnpushedArticles() (ret []*model.Article) {
-	if err := db.Where("`pushed_at` <= ?", model.ZeroPushTime).Find(&ret).Error; nil != err {
+	if err := db.Where("`pushed_at` <= ? AND `status` = ? AND `visibility` = ?", model.ZeroPushTime, model.ArticleStatusOK, model.ArticleVisibilityPublic).Find(&ret).Error; nil != err {
 		return
 	}
 
 	return
 }
 
+// GetScheduledArticles gets the scheduled articles which become published in the specified time range (from, to].
+func (srv *articleService) GetScheduledArticles(from, to time.Time) (ret []*model.Article) {
+	if err := db.Where("`status` = ? AND `created_at` > ? AND `created_at` <= ? AND `updated_at` < `created_at`",
+		model.ArticleStatusOK, from, to).Find(&ret).Error; nil != err {
+		logger.Errorf("get scheduled articles failed: %s", err)
+	}
+
+	return
+}
+
 func (srv *articleService) GetArchiveArticles(archiveID uint64, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
+	return getArchivesArticles([]uint64{archiveID}, page, blogID)
+}
+
+// GetYearArchiveArticles gets published articles archived in the specified year.
+func (srv *articleService) GetYearArchiveArticles(year string, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
+	var archiveIDs []uint64
+	if err := db.Model(&model.Archive{}).Where("`year` = ? AND `blog_id` = ?", year, blogID).
+		Pluck("`id`", &archiveIDs).Error; nil != err {
+		logger.Errorf("get year archives failed: %s", err)
+	}
+
+	return getArchivesArticles(archiveIDs, page, blogID)
+}
+
+func getArchivesArticles(archiveIDs []uint64, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
 	pageSize, windowSize := getPageWindowSize(blogID)
 	offset := (page - 1) * pageSize
 	count := 0
 
 	var rels []*model.Correlation
-	if err := db.Where("`id2` = ? AND `type` = ? AND `blog_id` = ?", archiveID, model.CorrelationArticleArchive, blogID).
+	if err := db.Where("`id2` IN (?) AND `type` = ? AND `blog_id` = ?", archiveIDs, model.CorrelationArticleArchive, blogID).
 		Find(&rels).Error; nil != err {
 		return
 	}
@@ -84,11 +136,11 @@ func (srv *articleService) GetArchiveArticles(archiveID uint64, page int, blogID
 	}
 
 	if err := db.Model(&model.Article{}).
-		Where("`id` IN (?) AND `status` = ? AND `blog_id` = ?", articleIDs, model.ArticleStatusOK, blogID).
-		Order("`topped` DESC, `created_at` DESC").Count(&count).
+		Where("`id` IN (?) AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", articleIDs, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
+		Order(getArticleListOrder(blogID)).Count(&count).
 		Offset(offset).Limit(pageSize).
 		Find(&ret).Error; nil != err {
-		logger.Errorf("get archive articles failed: " + err.Error())
+		logger.Errorf("get archive articles failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, pageSize, windowSize, count)
@@ -98,7 +150,7 @@ func (srv *articleService) GetArchiveArticles(archiveID uint64, page int, blogID
 
 func (srv *articleService) GetPreviousArticle(id uint64, blogID uint64) *model.Article {
 	ret := &model.Article{}
-	if err := db.Where("`id` < ? AND `blog_id` = ?", id, blogID).Order("`created_at` DESC").Limit(1).Find(ret).Error; nil != err {
+	if err := db.Where("`id` < ? AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", id, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).Order("`created_at` DESC").Limit(1).Find(ret).Error; nil != err {
 		return nil
 	}
 
@@ -107,7 +159,7 @@ func (srv *articleService) GetPreviousArticle(id uint64, blogID uint64) *model.A
 
 func (srv *articleService) GetNextArticle(id uint64, blogID uint64) *model.Article {
 	ret := &model.Article{}
-	if err := db.Where("`id` > ? AND `blog_id` = ?", id, blogID).Limit(1).Find(ret).Error; nil != err {
+	if err := db.Where("`id` > ? AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", id, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).Limit(1).Find(ret).Error; nil != err {
 		return nil
 	}
 
@@ -122,7 +174,7 @@ func (srv *articleService) GetArticleByPath(path string, blogID uint64) *model.A
 	path, _ = url.PathUnescape(path)
 
 	ret := &model.Article{}
-	if err := db.Where("`path` = ? AND `blog_id` = ?", path, blogID).Find(ret).Error; nil != err {
+	if err := db.Where("`path` = ? AND `status` = ? AND `blog_id` = ?", path, model.ArticleStatusOK, blogID).Find(ret).Error; nil != err {
 		return nil
 	}
 
@@ -139,6 +191,9 @@ func (srv *articleService) AddArticle(article *model.Article) (err error) {
 	if article.CreatedAt != article.PushedAt {
 		article.PushedAt = model.ZeroPushTime
 	}
+	if article.RepostedAt.IsZero() {
+		article.RepostedAt = model.ZeroPushTime
+	}
 	if err := normalizeArticle(article); nil != err {
 		return err
 	}
@@ -147,6 +202,7 @@ func (srv *articleService) AddArticle(article *model.Article) (err error) {
 	defer func() {
 		if err == nil {
 			tx.Commit()
+			cache.Page.Purge(article.BlogID)
 		} else {
 			tx.Rollback()
 		}
@@ -154,6 +210,9 @@ func (srv *articleService) AddArticle(article *model.Article) (err error) {
 	if err = tx.Create(article).Error; nil != err {
 		return
 	}
+	if err = addRevisionWithoutTx(tx, article, article.AuthorID); nil != err {
+		return
+	}
 	if err = tagArticle(tx, article); nil != err {
 		return
 	}
@@ -184,22 +243,58 @@ func (srv *articleService) AddArticle(article *model.Article) (err error) {
 	return nil // triger commit in the defer
 }
 
+// Console article list status filters.
+const (
+	ArticleFilterStatusDraft     = "draft"
+	ArticleFilterStatusPublished = "published"
+	ArticleFilterStatusScheduled = "scheduled" // published with a future creation time
+)
+
+// ArticleFilter holds conditions of the admin console article list, zero values match all.
+type ArticleFilter struct {
+	Keyword    string    // matches title or content
+	Status     string    // ArticleFilterStatusDraft, ArticleFilterStatusPublished or ArticleFilterStatusScheduled
+	Tag        string    // tag title
+	CategoryID uint64    // articles having any tag of the category
+	AuthorID   uint64    // author user ID
+	From       time.Time // created at or after
+	To         time.Time // created before
+}
+
+// ArticleFacet is an article count of a value of a facet.
+type ArticleFacet struct {
+	ID    uint64 `json:"id"`
+	Title string `json:"title"`
+	Count int    `json:"count"`
+}
+
+// ArticleFacets holds article counts of each facet of the admin console article list. Counts of a facet are
+// filtered by conditions of the other facets.
+type ArticleFacets struct {
+	Statuses   map[string]int  `json:"statuses"`
+	Tags       []*ArticleFacet `json:"tags"`
+	Categories []*ArticleFacet `json:"categories"`
+	Authors    []*ArticleFacet `json:"authors"`
+}
+
+// maxArticleTagFacets is the max number of tags returned in article facets.
+const maxArticleTagFacets = 30
+
 func (srv *articleService) ConsoleGetArticles(keyword string, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
+	return srv.ConsoleFilterArticles(&ArticleFilter{Keyword: keyword}, page, blogID)
+}
+
+// ConsoleFilterArticles gets articles of the specified blog matching the specified filter.
+func (srv *articleService) ConsoleFilterArticles(filter *ArticleFilter, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
 	offset := (page - 1) * adminConsoleArticleListPageSize
 	count := 0
 
-	where := "`status` = ? AND `blog_id` = ?"
-	whereArgs := []interface{}{model.ArticleStatusOK, blogID}
-	if "" != keyword {
-		where += " AND `title` LIKE ?"
-		whereArgs = append(whereArgs, "%"+keyword+"%")
-	}
-
-	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `tags`, `path`, `topped`, `view_count`, `comment_count`").
+	where, whereArgs := articleFilterWhere(filter, "", blogID)
+	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `tags`, `path`, `status`, `topped`, `topped_order`, `view_count`, `comment_count`").
 		Where(where, whereArgs...).
-		Order("`topped` DESC, `created_at` DESC").Count(&count).
+		Order("`topped` DESC, `topped_order` ASC, `created_at` DESC").Count(&count).
 		Offset(offset).Limit(adminConsoleArticleListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get articles failed: " + err.Error())
+		logger.Errorf("get articles failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, adminConsoleArticleListPageSize, adminConsoleArticleListWindowSize, count)
@@ -207,24 +302,145 @@ func (srv *articleService) ConsoleGetArticles(keyword string, page int, blogID u
 	return
 }
 
+// ConsoleGetArticleFacets gets article counts of statuses, tags, categories and authors of the specified blog.
+func (srv *articleService) ConsoleGetArticleFacets(filter *ArticleFilter, blogID uint64) *ArticleFacets {
+	ret := &ArticleFacets{Statuses: map[string]int{}}
+
+	where, whereArgs := articleFilterWhere(filter, "status", blogID)
+	for _, status := range []string{ArticleFilterStatusDraft, ArticleFilterStatusPublished, ArticleFilterStatusScheduled} {
+		statusWhere, statusArgs := articleStatusWhere(status)
+		count := 0
+		if err := db.Model(&model.Article{}).Where(where+" AND "+statusWhere, append(whereArgs, statusArgs...)...).
+			Count(&count).Error; nil != err {
+			logger.Errorf("count articles failed: %s", err)
+		}
+		ret.Statuses[status] = count
+	}
+
+	where, whereArgs = articleFilterWhere(filter, "author", blogID)
+	if err := db.Model(&model.Article{}).Select("`author_id` AS `id`, COUNT(*) AS `count`").Where(where, whereArgs...).
+		Group("`author_id`").Order("`count` DESC").Scan(&ret.Authors).Error; nil != err {
+		logger.Errorf("count articles of authors failed: %s", err)
+	}
+	for _, author := range ret.Authors {
+		if user := User.GetUser(author.ID); nil != user {
+			author.Title = user.Name
+		}
+	}
+
+	where, whereArgs = articleFilterWhere(filter, "tag", blogID)
+	articles := db.NewScope(&model.Article{}).TableName()
+	if err := db.Table(db.NewScope(&model.Correlation{}).TableName()).Select("`id2` AS `id`, COUNT(*) AS `count`").
+		Where("`type` = ? AND `blog_id` = ? AND `deleted_at` IS NULL AND `id1` IN (SELECT `id` FROM `"+articles+"` WHERE "+where+")",
+			append([]interface{}{model.CorrelationArticleTag, blogID}, whereArgs...)...).
+		Group("`id2`").Order("`count` DESC").Limit(maxArticleTagFacets).Scan(&ret.Tags).Error; nil != err {
+		logger.Errorf("count articles of tags failed: %s", err)
+	}
+	for _, tag := range ret.Tags {
+		tagModel := &model.Tag{}
+		if err := db.Select("`title`").First(tagModel, tag.ID).Error; nil == err {
+			tag.Title = tagModel.Title
+		}
+	}
+
+	where, whereArgs = articleFilterWhere(filter, "category", blogID)
+	var categories []*model.Category
+	if err := db.Select("`id`, `title`").Where("`blog_id` = ?", blogID).Order("`number` ASC, `id` DESC").Find(&categories).Error; nil != err {
+		logger.Errorf("get categories failed: %s", err)
+	}
+	for _, category := range categories {
+		categoryWhere, categoryArgs := articleCategoryWhere(category.ID, blogID)
+		count := 0
+		if err := db.Model(&model.Article{}).Where(where+" AND "+categoryWhere, append(whereArgs, categoryArgs...)...).
+			Count(&count).Error; nil != err {
+			logger.Errorf("count articles of category failed: %s", err)
+		}
+		ret.Categories = append(ret.Categories, &ArticleFacet{ID: category.ID, Title: category.Title, Count: count})
+	}
+
+	return ret
+}
+
+// articleFilterWhere builds the where clause of the specified filter on the articles table, conditions of the
+// specified facet ("status", "tag", "category" or "author") are skipped.
+func articleFilterWhere(filter *ArticleFilter, skip string, blogID uint64) (where string, whereArgs []interface{}) {
+	where = "`blog_id` = ? AND `deleted_at` IS NULL"
+	whereArgs = []interface{}{blogID}
+	if "status" != skip {
+		statusWhere, statusArgs := articleStatusWhere(filter.Status)
+		where += " AND " + statusWhere
+		whereArgs = append(whereArgs, statusArgs...)
+	}
+	if "" != filter.Keyword {
+		where += " AND (`title` LIKE ? OR `content` LIKE ?)"
+		whereArgs = append(whereArgs, "%"+filter.Keyword+"%", "%"+filter.Keyword+"%")
+	}
+	if "tag" != skip && "" != filter.Tag {
+		correlations, tags := db.NewScope(&model.Correlation{}).TableName(), db.NewScope(&model.Tag{}).TableName()
+		where += " AND `id` IN (SELECT `id1` FROM `" + correlations + "` WHERE `type` = ? AND `blog_id` = ? AND `deleted_at` IS NULL" +
+			" AND `id2` IN (SELECT `id` FROM `" + tags + "` WHERE `title` = ? AND `blog_id` = ? AND `deleted_at` IS NULL))"
+		whereArgs = append(whereArgs, model.CorrelationArticleTag, blogID, filter.Tag, blogID)
+	}
+	if "category" != skip && 0 != filter.CategoryID {
+		categoryWhere, categoryArgs := articleCategoryWhere(filter.CategoryID, blogID)
+		where += " AND " + categoryWhere
+		whereArgs = append(whereArgs, categoryArgs...)
+	}
+	if "author" != skip && 0 != filter.AuthorID {
+		where += " AND `author_id` = ?"
+		whereArgs = append(whereArgs, filter.AuthorID)
+	}
+	if !filter.From.IsZero() {
+		where += " AND `created_at` >= ?"
+		whereArgs = append(whereArgs, filter.From)
+	}
+	if !filter.To.IsZero() {
+		where += " AND `created_at` < ?"
+		whereArgs = append(whereArgs, filter.To)
+	}
+
+	return
+}
+
+func articleStatusWhere(status string) (string, []interface{}) {
+	switch status {
+	case ArticleFilterStatusDraft:
+		return "`status` = ?", []interface{}{model.ArticleStatusDraft}
+	case ArticleFilterStatusPublished:
+		return "`status` = ? AND `created_at` <= ?", []interface{}{model.ArticleStatusOK, time.Now()}
+	case ArticleFilterStatusScheduled:
+		return "`status` = ? AND `created_at` > ?", []interface{}{model.ArticleStatusOK, time.Now()}
+	default:
+		return "`status` IN (?)", []interface{}{[]int{model.ArticleStatusOK, model.ArticleStatusDraft}}
+	}
+}
+
+func articleCategoryWhere(categoryID, blogID uint64) (string, []interface{}) {
+	correlations := db.NewScope(&model.Correlation{}).TableName()
+
+	return "`id` IN (SELECT `id1` FROM `" + correlations + "` WHERE `type` = ? AND `blog_id` = ? AND `deleted_at` IS NULL" +
+			" AND `id2` IN (SELECT `id2` FROM `" + correlations + "` WHERE `id1` = ? AND `type` = ? AND `blog_id` = ? AND `deleted_at` IS NULL))",
+		[]interface{}{model.CorrelationArticleTag, blogID, categoryID, model.CorrelationCategoryTag, blogID}
+}
+
 func (srv *articleService) GetArticles(keyword string, page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
 	pageSize, windowSize := getPageWindowSize(blogID)
 	offset := (page - 1) * pageSize
 	count := 0
 
-	where := "`status` = ? AND `blog_id` = ?"
-	whereArgs := []interface{}{model.ArticleStatusOK, blogID}
+	where := "`status` = ? AND `visibility` = ? AND `blog_id` = ?"
+	whereArgs := []interface{}{model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID}
 	if "" != keyword {
 		where += " AND `title` LIKE ?"
 		whereArgs = append(whereArgs, "%"+keyword+"%")
 	}
 
-	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `abstract`, `content`, `tags`, `path`, `topped`, `view_count`, `comment_count`").
+	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `abstract`, `content`, `tags`, `path`, `topped`, `topped_order`, `view_count`, `comment_count`").
 		Where(where, whereArgs...).
-		Order("`topped` DESC, `created_at` DESC").Count(&count).
+		Order(getArticleListOrder(blogID)).Count(&count).
 		Offset(offset).Limit(pageSize).
 		Find(&ret).Error; nil != err {
-		logger.Errorf("get articles failed: " + err.Error())
+		logger.Errorf("get articles failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, pageSize, windowSize, count)
@@ -262,7 +478,8 @@ func (srv *articleService) GetCategoryArticles(categoryID uint64, page int, blog
 		articleIDs = append(articleIDs, articleTagRel.ID1)
 	}
 
-	if err := db.Where("`id` IN (?) AND `blog_id` = ?", articleIDs, blogID).Find(&ret).Error; nil != err {
+	if err := db.Where("`id` IN (?) AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", articleIDs, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
+		Order(getArticleListOrder(blogID)).Find(&ret).Error; nil != err {
 		return
 	}
 
@@ -286,10 +503,10 @@ func (srv *articleService) GetTagArticles(tagID uint64, page int, blogID uint64)
 	}
 
 	if err := db.Model(&model.Article{}).
-		Where("`id` IN (?) AND `status` = ? AND `blog_id` = ?", articleIDs, model.ArticleStatusOK, blogID).
-		Order("`topped` DESC, `created_at` DESC").Count(&count).Offset(offset).Limit(pageSize).
+		Where("`id` IN (?) AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", articleIDs, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
+		Order(getArticleListOrder(blogID)).Count(&count).Offset(offset).Limit(pageSize).
 		Find(&ret).Error; nil != err {
-		logger.Errorf("get tag articles failed: " + err.Error())
+		logger.Errorf("get tag articles failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, pageSize, windowSize, count)
@@ -303,11 +520,11 @@ func (srv *articleService) GetAuthorArticles(authorID uint64, page int, blogID u
 	count := 0
 
 	if err := db.Model(&model.Article{}).
-		Where("`author_id` = ? AND `status` = ? AND `blog_id` = ?", authorID, model.ArticleStatusOK, blogID).
-		Order("`topped` DESC, `created_at` DESC").Count(&count).
+		Where("`author_id` = ? AND `status` = ? AND `visibility` = ? AND `blog_id` = ?", authorID, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
+		Order(getArticleListOrder(blogID)).Count(&count).
 		Offset(offset).Limit(pageSize).
 		Find(&ret).Error; nil != err {
-		logger.Errorf("get author articles failed: " + err.Error())
+		logger.Errorf("get author articles failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, pageSize, windowSize, count)
@@ -315,11 +532,38 @@ func (srv *articleService) GetAuthorArticles(authorID uint64, page int, blogID u
 	return
 }
 
+func (srv *articleService) GetPublishedArticlePaths(blogID uint64) (ret []string) {
+	var articles []*model.Article
+	if err := db.Model(&model.Article{}).Select("`id`, `path`").
+		Where("`status` = ? AND `visibility` <> ? AND `blog_id` = ?", model.ArticleStatusOK, model.ArticleVisibilityPassword, blogID).Find(&articles).Error; nil != err {
+		logger.Errorf("get published articles failed: %s", err)
+
+		return
+	}
+
+	for _, article := range articles {
+		ret = append(ret, article.Path)
+	}
+
+	return
+}
+
+// GetPlatformMostViewArticles gets the most viewed published articles of all blogs.
+func (srv *articleService) GetPlatformMostViewArticles(size int) (ret []*model.Article) {
+	if err := db.Model(&model.Article{}).Select("`id`, `path`, `blog_id`").
+		Where("`status` = ? AND `visibility` = ?", model.ArticleStatusOK, model.ArticleVisibilityPublic).
+		Order("`view_count` DESC, `created_at` DESC").Limit(size).Find(&ret).Error; nil != err {
+		logger.Errorf("get platform most view articles failed: %s", err)
+	}
+
+	return
+}
+
 func (srv *articleService) GetMostViewArticles(size int, blogID uint64) (ret []*model.Article) {
 	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `path`").
-		Where("`status` = ? AND `blog_id` = ?", model.ArticleStatusOK, blogID).
+		Where("`status` = ? AND `visibility` = ? AND `blog_id` = ?", model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
 		Order("`view_count` DESC, `created_at` DESC").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get most view articles failed: " + err.Error())
+		logger.Errorf("get most view articles failed: %s", err)
 	}
 
 	return
@@ -327,9 +571,9 @@ func (srv *articleService) GetMostViewArticles(size int, blogID uint64) (ret []*
 
 func (srv *articleService) GetMostCommentArticles(size int, blogID uint64) (ret []*model.Article) {
 	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `path`").
-		Where("`status` = ? AND `blog_id` = ?", model.ArticleStatusOK, blogID).
+		Where("`status` = ? AND `visibility` = ? AND `blog_id` = ?", model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).
 		Order("`comment_count` DESC, `id` DESC").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get most comment articles failed: " + err.Error())
+		logger.Errorf("get most comment articles failed: %s", err)
 	}
 
 	return
@@ -372,7 +616,7 @@ func (srv *articleService) ConsolePushArticle(article *model.Article) {
 		Set("user-agent", model.UserAgent).Timeout(30*time.Second).
 		Retry(3, 5*time.Second).EndStruct(result)
 	if nil != errs {
-		logger.Debugf("push an article to Rhy failed: " + errs[0].Error())
+		logger.Debugf("push an article to Rhy failed: %s", errs[0])
 	} else {
 		logger.Infof("push an article to Rhy result: %+v", result)
 	}
@@ -390,6 +634,7 @@ func (srv *articleService) ConsoleGetArticle(id uint64) *model.Article {
 	return ret
 }
 
+// RemoveArticle removes the specified article permanently.
 func (srv *articleService) RemoveArticle(id, blogID uint64) (err error) {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
@@ -400,6 +645,7 @@ func (srv *articleService) RemoveArticle(id, blogID uint64) (err error) {
 	defer func() {
 		if nil == err {
 			tx.Commit()
+			cache.Page.Purge(blogID)
 		} else {
 			tx.Rollback()
 		}
@@ -407,6 +653,188 @@ func (srv *articleService) RemoveArticle(id, blogID uint64) (err error) {
 	if err = tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(article).Error; nil != err {
 		return
 	}
+	if err = trashArticleWithoutTx(tx, article); nil != err {
+		return
+	}
+	if err = purgeArticleWithoutTx(tx, article); nil != err {
+		return
+	}
+	return nil // trigger commit in the defer
+}
+
+// TrashArticle moves the specified article into the trash, where it could be restored until it is purged.
+func (srv *articleService) TrashArticle(id, blogID uint64) (err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	article := &model.Article{}
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+	if err = tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(article).Error; nil != err {
+		return
+	}
+	if err = trashArticleWithoutTx(tx, article); nil != err {
+		return
+	}
+	return nil // trigger commit in the defer
+}
+
+// RestoreArticle restores the specified article from the trash with its tags and comments.
+func (srv *articleService) RestoreArticle(id, blogID uint64) (err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	article := srv.ConsoleGetTrashedArticle(id, blogID)
+	if nil == article {
+		return ErrArticleNotInTrash
+	}
+	if err = normalizeArticlePath(article); nil != err { // the path may be taken after the article was trashed
+		return
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+	trashedAt := *article.DeletedAt
+	if err = tx.Unscoped().Model(&model.Article{}).Where("`id` = ?", article.ID).
+		Update("deleted_at", gorm.Expr("NULL")).Error; nil != err {
+		return
+	}
+	article.DeletedAt = nil
+	author := &model.User{}
+	if err = tx.First(author, article.AuthorID).Error; nil != err {
+		return
+	}
+	author.TotalArticleCount += 1
+	if err = tx.Model(author).Updates(author).Error; nil != err {
+		return
+	}
+	blogUserRel := &model.Correlation{}
+	if err = tx.Where("`id1` = ? AND `id2` = ? AND `type` = ? AND `blog_id` = ?",
+		article.BlogID, author.ID, model.CorrelationBlogUser, article.BlogID).First(blogUserRel).Error; nil != err {
+		return
+	}
+	blogUserRel.Int2 += 1
+	if err = tx.Model(blogUserRel).Updates(blogUserRel).Error; nil != err {
+		return
+	}
+	if "" != article.Tags {
+		if err = tagArticle(tx, article); nil != err {
+			return
+		}
+	}
+	if err = Archive.ArchiveArticleWithoutTx(tx, article); nil != err {
+		return
+	}
+	if err = Statistic.IncArticleCountWithoutTx(tx, article.BlogID); nil != err {
+		return
+	}
+	var comments []*model.Comment // comments removed along with the article
+	if err = tx.Unscoped().Where("`article_id` = ? AND `blog_id` = ? AND `deleted_at` >= ?", id, article.BlogID, trashedAt).
+		Find(&comments).Error; nil != err {
+		return
+	}
+	if 0 < len(comments) {
+		if err = tx.Unscoped().Model(&model.Comment{}).Where("`article_id` = ? AND `blog_id` = ? AND `deleted_at` >= ?", id, article.BlogID, trashedAt).
+			Update("deleted_at", gorm.Expr("NULL")).Error; nil != err {
+			return
+		}
+		for _, comment := range comments {
+			if model.CommentStatusOK == comment.Status {
+				Statistic.IncCommentCountWithoutTx(tx, article.BlogID)
+			}
+		}
+	}
+	return nil // trigger commit in the defer
+}
+
+// PurgeArticle removes the specified article from the trash permanently.
+func (srv *articleService) PurgeArticle(id, blogID uint64) (err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	article := srv.ConsoleGetTrashedArticle(id, blogID)
+	if nil == article {
+		return ErrArticleNotInTrash
+	}
+
+	tx := db.Begin()
+	if err = purgeArticleWithoutTx(tx, article); nil != err {
+		tx.Rollback()
+
+		return
+	}
+	tx.Commit()
+
+	return
+}
+
+// PurgeTrash removes articles trashed before the specified time permanently, returns the count of purged articles.
+func (srv *articleService) PurgeTrash(before time.Time) (ret int) {
+	var articles []*model.Article
+	if err := db.Unscoped().Select("`id`, `blog_id`").Where("`deleted_at` IS NOT NULL AND `deleted_at` < ?", before).
+		Find(&articles).Error; nil != err {
+		logger.Errorf("get trashed articles failed: %s", err)
+
+		return
+	}
+
+	for _, article := range articles {
+		if err := srv.PurgeArticle(article.ID, article.BlogID); nil != err {
+			logger.Errorf("purge article [%d] failed: %s", article.ID, err)
+
+			continue
+		}
+		ret++
+	}
+
+	return
+}
+
+// ConsoleGetTrashedArticles gets articles in the trash of the specified blog, the latest trashed first.
+func (srv *articleService) ConsoleGetTrashedArticles(page int, blogID uint64) (ret []*model.Article, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleArticleListPageSize
+	count := 0
+
+	if err := db.Unscoped().Model(&model.Article{}).Select("`id`, `created_at`, `deleted_at`, `author_id`, `title`, `tags`, `path`, `status`, `comment_count`").
+		Where("`deleted_at` IS NOT NULL AND `blog_id` = ?", blogID).
+		Order("`deleted_at` DESC").Count(&count).
+		Offset(offset).Limit(adminConsoleArticleListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get trashed articles failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleArticleListPageSize, adminConsoleArticleListWindowSize, count)
+
+	return
+}
+
+// ConsoleGetTrashedArticle gets the specified article in the trash of the specified blog, returns nil if not found.
+func (srv *articleService) ConsoleGetTrashedArticle(id, blogID uint64) *model.Article {
+	ret := &model.Article{}
+	if err := db.Unscoped().Where("`id` = ? AND `blog_id` = ? AND `deleted_at` IS NOT NULL", id, blogID).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// trashArticleWithoutTx soft deletes the specified article with its comments, and takes it off counts, archives and
+// tags. Revisions are kept for restoring.
+func trashArticleWithoutTx(tx *gorm.DB, article *model.Article) (err error) {
 	author := &model.User{}
 	if err = tx.First(author, article.AuthorID).Error; nil != err {
 		return
@@ -437,18 +865,41 @@ func (srv *articleService) RemoveArticle(id, blogID uint64) (err error) {
 		return
 	}
 	var comments []*model.Comment
-	if err = tx.Model(&model.Comment{}).Where("`article_id` = ? AND `blog_id` = ?", id, article.BlogID).Find(&comments).Error; nil != err {
+	if err = tx.Model(&model.Comment{}).Where("`article_id` = ? AND `blog_id` = ?", article.ID, article.BlogID).Find(&comments).Error; nil != err {
 		return
 	}
 	if 0 < len(comments) {
-		if err = tx.Where("`article_id` = ? AND `blog_id` = ?", id, article.BlogID).Delete(&model.Comment{}).Error; nil != err {
+		if err = tx.Where("`article_id` = ? AND `blog_id` = ?", article.ID, article.BlogID).Delete(&model.Comment{}).Error; nil != err {
 			return
 		}
-		for range comments {
-			Statistic.DecCommentCountWithoutTx(tx, article.BlogID)
+		for _, comment := range comments {
+			if model.CommentStatusOK == comment.Status {
+				Statistic.DecCommentCountWithoutTx(tx, article.BlogID)
+			}
 		}
 	}
-	return nil // trigger commit in the defer
+
+	return
+}
+
+// purgeArticleWithoutTx removes the specified trashed article with its revisions and comments permanently.
+func purgeArticleWithoutTx(tx *gorm.DB, article *model.Article) (err error) {
+	if err = removeRevisionsWithoutTx(tx, article.ID); nil != err {
+		return
+	}
+	if err = tx.Unscoped().Where("`article_id` = ? AND `blog_id` = ?", article.ID, article.BlogID).Delete(&model.Comment{}).Error; nil != err {
+		return
+	}
+	if err = tx.Unscoped().Where("`id1` = ? AND `type` IN (?) AND `blog_id` = ?", article.ID,
+		[]int{model.CorrelationArticleTag, model.CorrelationArticleArchive, model.CorrelationArticleRelated}, article.BlogID).Delete(&model.Correlation{}).Error; nil != err {
+		return
+	}
+	if err = tx.Unscoped().Where("`id2` = ? AND `type` = ? AND `blog_id` = ?", article.ID, model.CorrelationArticleRelated, article.BlogID).
+		Delete(&model.Correlation{}).Error; nil != err {
+		return
+	}
+
+	return tx.Unscoped().Where("`id` = ?", article.ID).Delete(&model.Article{}).Error
 }
 
 func (srv *articleService) UpdatePushedAt(article *model.Article) error {
@@ -472,12 +923,36 @@ func (srv *articleService) UpdateArticle(article *model.Article) (err error) {
 		Find(oldArticle).Error; nil != err {
 		return
 	}
+	if oldArticle.Version != article.Version {
+		return ErrArticleConflict
+	}
+	if ArticleStatusUnchanged == article.Status {
+		article.Status = oldArticle.Status
+	}
+	if model.ArticleStatusOK != article.Status && model.ArticleStatusDraft != article.Status {
+		return ErrInvalidArticleStatus
+	}
 
 	oldArticle.Title = strings.TrimSpace(article.Title)
 	oldArticle.Abstract = strings.TrimSpace(article.Abstract)
+	if err = normalizeArticleDescription(article); nil != err {
+		return
+	}
+	oldArticle.Description = article.Description
 	oldArticle.Content = strings.TrimSpace(article.Content)
 	oldArticle.Commentable = article.Commentable
 	oldArticle.Topped = article.Topped
+	oldArticle.ToppedOrder = article.ToppedOrder
+	oldArticle.Status = article.Status
+	oldArticle.RepostOptOut = article.RepostOptOut
+	oldArticle.Visibility = article.Visibility
+	if "" != article.PasswordHash {
+		oldArticle.PasswordHash = article.PasswordHash
+	}
+	if err = normalizeArticleVisibility(oldArticle); nil != err {
+		return
+	}
+	oldArticle.Version++
 	now := time.Now()
 	oldArticle.UpdatedAt = now
 
@@ -497,6 +972,7 @@ func (srv *articleService) UpdateArticle(article *model.Article) (err error) {
 	defer func() {
 		if err == nil {
 			tx.Commit()
+			cache.Page.Purge(article.BlogID)
 		} else {
 			tx.Rollback()
 		}
@@ -511,19 +987,186 @@ func (srv *articleService) UpdateArticle(article *model.Article) (err error) {
 			return
 		}
 	}
+	// claims the version with a conditional update, so only one of the concurrent updates of a version succeeds
+	claim := tx.Model(&model.Article{}).Where("`id` = ? AND `version` = ?", oldArticle.ID, article.Version).
+		UpdateColumn("version", oldArticle.Version)
+	if err = claim.Error; nil != err {
+		return
+	}
+	if 1 != claim.RowsAffected {
+		err = ErrArticleConflict
+
+		return
+	}
 	if err = tx.Save(oldArticle).Error; nil != err {
 		return
 	}
+	if err = addRevisionWithoutTx(tx, oldArticle, article.AuthorID); nil != err { // the author of the update request
+		return
+	}
 	if err = removeTagArticleRels(tx, article); nil != err {
 		return
 	}
 	if err = tagArticle(tx, article); nil != err {
 		return
 	}
+	article.Version = oldArticle.Version
+
+	return nil
+}
+
+// ArticleChangeSet represents changes applied to articles in batch.
+type ArticleChangeSet struct {
+	AddTags    []string `json:"addTags"`
+	RemoveTags []string `json:"removeTags"`
+	CategoryID uint64   `json:"categoryID"` // moves articles into the category, 0 to keep
+	Status     *int     `json:"status"`     // ArticleStatusOK to publish, ArticleStatusDraft to unpublish, nil to keep
+}
+
+// BatchUpdateArticles applies the specified change set to the specified articles in one transaction, returns the
+// updated articles. Categories are defined by tags, so moving an article into a category removes tags only belonging
+// to other categories and adds the first tag of the category if the article has none of its tags.
+func (srv *articleService) BatchUpdateArticles(ids []uint64, changes *ArticleChangeSet, blogID uint64) (ret []*model.Article, err error) {
+	var categoryTags, otherCategoryTags []string
+	if 0 != changes.CategoryID {
+		category := Category.ConsoleGetCategory(changes.CategoryID)
+		if nil == category || blogID != category.BlogID {
+			return nil, errors.New("not found category")
+		}
+		categoryTags = splitTags(category.Tags)
+		if 1 > len(categoryTags) {
+			return nil, errors.New("category [" + category.Title + "] has no tags")
+		}
+		var categories []*model.Category
+		if err = db.Where("`id` != ? AND `blog_id` = ?", category.ID, blogID).Find(&categories).Error; nil != err {
+			return
+		}
+		for _, other := range categories {
+			for _, tag := range splitTags(other.Tags) {
+				if !contains(categoryTags, tag) {
+					otherCategoryTags = append(otherCategoryTags, tag)
+				}
+			}
+		}
+	}
+	if nil != changes.Status && model.ArticleStatusOK != *changes.Status && model.ArticleStatusDraft != *changes.Status {
+		return nil, errors.New("invalid article status")
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+			ret = nil
+		}
+	}()
+	for _, id := range ids {
+		article := &model.Article{}
+		if err = tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(article).Error; nil != err {
+			if gorm.ErrRecordNotFound == err {
+				err = nil
+
+				continue
+			}
+
+			return
+		}
+
+		var tags []string
+		for _, tag := range splitTags(article.Tags) {
+			if !contains(changes.RemoveTags, tag) && !contains(otherCategoryTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range changes.AddTags {
+			if tag = strings.TrimSpace(tag); "" != tag && !contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if 0 < len(categoryTags) {
+			inCategory := false
+			for _, tag := range categoryTags {
+				if contains(tags, tag) {
+					inCategory = true
+
+					break
+				}
+			}
+			if !inCategory {
+				tags = append(tags, categoryTags[0])
+			}
+		}
+		tagStr := normalizeTagStr(strings.Join(tags, ","))
+		status := article.Status
+		if nil != changes.Status {
+			status = *changes.Status
+		}
+		if tagStr == article.Tags && status == article.Status {
+			continue
+		}
+
+		if tagStr != article.Tags {
+			if err = removeTagArticleRels(tx, article); nil != err {
+				return
+			}
+			article.Tags = tagStr
+			if err = tagArticle(tx, article); nil != err {
+				return
+			}
+		}
+		article.Status = status
+		article.Version++
+		if err = tx.Model(&model.Article{}).Where("`id` = ?", article.ID).Updates(map[string]interface{}{
+			"tags":       article.Tags,
+			"status":     article.Status,
+			"version":    article.Version,
+			"updated_at": time.Now(),
+		}).Error; nil != err {
+			return
+		}
+		ret = append(ret, article)
+	}
+
+	return ret, nil // trigger commit in the defer
+}
+
+// TopArticle tops or untops the specified article, topped articles are listed first and ordered by the specified
+// order ascending.
+func (srv *articleService) TopArticle(id uint64, topped bool, order int, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if !topped {
+		order = 0
+	}
+	if err := db.Model(&model.Article{}).Where("`id` = ? AND `blog_id` = ?", id, blogID).Updates(map[string]interface{}{
+		"topped":       topped,
+		"topped_order": order,
+		"version":      gorm.Expr("`version` + 1"),
+	}).Error; nil != err {
+		return err
+	}
+	cache.Page.Purge(blogID)
 
 	return nil
 }
 
+func splitTags(tagStr string) (ret []string) {
+	for _, tag := range strings.Split(tagStr, ",") {
+		if tag = strings.TrimSpace(tag); "" != tag {
+			ret = append(ret, tag)
+		}
+	}
+
+	return
+}
+
 func (srv *articleService) IncArticleViewCount(article *model.Article) error {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
@@ -536,6 +1179,19 @@ func (srv *articleService) IncArticleViewCount(article *model.Article) error {
 	return nil
 }
 
+// maxArticleDescriptionLength is the max length (in runes) of an article meta description.
+const maxArticleDescriptionLength = 255
+
+func normalizeArticleDescription(article *model.Article) error {
+	description := strings.Join(strings.Fields(article.Description), " ")
+	if maxArticleDescriptionLength < utf8.RuneCountInString(description) {
+		return errors.New("description is too long")
+	}
+	article.Description = description
+
+	return nil
+}
+
 func normalizeArticle(article *model.Article) error {
 	title := strings.TrimSpace(article.Title)
 	if "" == title {
@@ -555,13 +1211,17 @@ func normalizeArticle(article *model.Article) error {
 	}
 	article.Content = content
 
-	if util.IsReservedPath(article.Path) {
-		return errors.New("invalid path [" + article.Path + "]")
+	if err := normalizeArticleDescription(article); nil != err {
+		return err
 	}
 
 	tagStr := normalizeTagStr(article.Tags)
 	article.Tags = tagStr
 
+	if err := normalizeArticleVisibility(article); nil != err {
+		return err
+	}
+
 	if 1 > article.ID {
 		article.ID = util.CurrentMillisecond()
 	}
@@ -573,6 +1233,69 @@ func normalizeArticle(article *model.Article) error {
 	return nil
 }
 
+// normalizeArticleVisibility checks the visibility of the specified article, the access password is kept only if the
+// article is password-protected.
+func normalizeArticleVisibility(article *model.Article) error {
+	switch article.Visibility {
+	case model.ArticleVisibilityPublic, model.ArticleVisibilityUnlisted:
+		article.PasswordHash = ""
+	case model.ArticleVisibilityPassword:
+		if "" == article.PasswordHash {
+			return ErrArticlePassword
+		}
+	default:
+		return errors.New("visibility [" + strconv.Itoa(article.Visibility) + "] is invalid")
+	}
+
+	return nil
+}
+
+// SetArticlePassword hashes the specified access password into the specified article, the article is not saved.
+func (srv *articleService) SetArticlePassword(article *model.Article, password string) error {
+	if "" == password || articlePasswordMaxLength < len(password) {
+		return ErrArticlePassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if nil != err {
+		return err
+	}
+	article.PasswordHash = string(hash)
+
+	return nil
+}
+
+// VerifyArticlePassword checks the specified access password against the password of the specified article.
+func (srv *articleService) VerifyArticlePassword(article *model.Article, password string) bool {
+	if model.ArticleVisibilityPassword != article.Visibility || "" == article.PasswordHash {
+		return false
+	}
+
+	return nil == bcrypt.CompareHashAndPassword([]byte(article.PasswordHash), []byte(password))
+}
+
+// GetArticleUnlockToken returns the token which proves the access password of the specified article has been
+// verified. The token is derived from the password hash so changing the password revokes it.
+func (srv *articleService) GetArticleUnlockToken(article *model.Article) string {
+	if model.ArticleVisibilityPassword != article.Visibility || "" == article.PasswordHash {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(strconv.FormatUint(article.ID, 10) + ":" + article.PasswordHash))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// IsArticleUnlocked checks whether the specified token unlocks the specified article.
+func (srv *articleService) IsArticleUnlocked(article *model.Article, token string) bool {
+	expected := srv.GetArticleUnlockToken(article)
+	if "" == expected {
+		return false
+	}
+
+	return 1 == subtle.ConstantTimeCompare([]byte(expected), []byte(token))
+}
+
 func normalizeTagStr(tagStr string) string {
 	reg := regexp.MustCompile(`\s+`)
 	ret := reg.ReplaceAllString(tagStr, "")
@@ -674,23 +1397,183 @@ func contains(strs []string, str string) bool {
 func normalizeArticlePath(article *model.Article) error {
 	path := strings.TrimSpace(article.Path)
 	if "" == path {
-		path = util.PathArticles + article.CreatedAt.Format("/2006/01/02/") +
-			fmt.Sprintf("%d", article.ID)
+		path = generateArticlePath(article, getPermalink(article.BlogID))
 	}
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
+	if util.IsReservedPath(path) {
+		return ErrReservedPath
+	}
 
 	count := 0
 	if db.Model(&model.Article{}).Where("`path` = ? AND `id` != ? AND `blog_id` = ?", path, article.ID, article.BlogID).Count(&count); 0 < count {
 		return errors.New("path [" + path + "] is reduplicated")
 	}
+	if db.Model(&model.Category{}).Where("`path` = ? AND `blog_id` = ?", path, article.BlogID).Count(&count); 0 < count {
+		return errors.New("path [" + path + "] is used by a category")
+	}
+	if db.Model(&model.Page{}).Where("`path` = ? AND `blog_id` = ?", path, article.BlogID).Count(&count); 0 < count {
+		return errors.New("path [" + path + "] is used by a page")
+	}
 
 	article.Path = path
 
 	return nil
 }
 
+// articleSlugMaxLength is the max length of slugs generated from article titles.
+const articleSlugMaxLength = 64
+
+// getPermalink returns the permalink pattern configured by the specified blog.
+func getPermalink(blogID uint64) int {
+	permalinkSetting := Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferencePermalink, blogID)
+	if nil == permalinkSetting { // blogs initialized before the setting introduced
+		return model.SettingPreferencePermalinkDefault
+	}
+
+	ret, err := strconv.Atoi(permalinkSetting.Value)
+	if nil != err {
+		logger.Errorf("value of setting [%s] is not an integer, actual is [%v]", model.SettingNamePreferencePermalink, permalinkSetting.Value)
+
+		return model.SettingPreferencePermalinkDefault
+	}
+
+	return ret
+}
+
+// generateArticlePath generates path of the specified article with the specified permalink pattern. The article ID is
+// appended to the slug if the path is reserved or used by another article, a category or a page.
+func generateArticlePath(article *model.Article, permalink int) string {
+	id := strconv.FormatUint(article.ID, 10)
+	var ret string
+	switch permalink {
+	case model.SettingPreferencePermalinkValueMonthSlug:
+		ret = article.CreatedAt.Format("/2006/01/") + articleSlug(article)
+	case model.SettingPreferencePermalinkValueID:
+		return "/" + id
+	case model.SettingPreferencePermalinkValueSlug:
+		ret = "/" + articleSlug(article)
+		if util.IsReservedPath(ret) {
+			ret = util.PathArticles + ret
+		}
+	default:
+		return util.PathArticles + article.CreatedAt.Format("/2006/01/02/") + id
+	}
+
+	if strings.HasSuffix(ret, "/"+id) {
+		return ret
+	}
+	articleCount, categoryCount, pageCount := 0, 0, 0
+	db.Model(&model.Article{}).Where("`path` = ? AND `id` != ? AND `blog_id` = ?", ret, article.ID, article.BlogID).Count(&articleCount)
+	db.Model(&model.Category{}).Where("`path` = ? AND `blog_id` = ?", ret, article.BlogID).Count(&categoryCount)
+	db.Model(&model.Page{}).Where("`path` = ? AND `blog_id` = ?", ret, article.BlogID).Count(&pageCount)
+	if 0 < articleCount || 0 < categoryCount || 0 < pageCount {
+		ret += "-" + id
+	}
+
+	return ret
+}
+
+// articleSlug returns the lowercase ASCII words of the title of the specified article joined by "-", returns the
+// article ID if there is no such word.
+func articleSlug(article *model.Article) string {
+	var words []string
+	word := &strings.Builder{}
+	for _, r := range strings.ToLower(article.Title) + " " {
+		if ('a' <= r && 'z' >= r) || ('0' <= r && '9' >= r) {
+			word.WriteRune(r)
+
+			continue
+		}
+		if 0 < word.Len() {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	ret := strings.Join(words, "-")
+	if articleSlugMaxLength < len(ret) {
+		ret = strings.TrimRight(ret[:articleSlugMaxLength], "-")
+	}
+	if "" == ret {
+		return strconv.FormatUint(article.ID, 10)
+	}
+
+	return ret
+}
+
+// isGeneratedArticlePath checks whether the path of the specified article is generated by any permalink pattern.
+func isGeneratedArticlePath(article *model.Article) bool {
+	id := strconv.FormatUint(article.ID, 10)
+	for _, permalink := range []int{model.SettingPreferencePermalinkValueDate, model.SettingPreferencePermalinkValueMonthSlug,
+		model.SettingPreferencePermalinkValueID, model.SettingPreferencePermalinkValueSlug} {
+		path := generateArticlePath(article, permalink)
+		if path == article.Path || path+"-"+id == article.Path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplyPermalink moves articles of the specified blog whose paths are generated by a permalink pattern to the
+// pattern currently configured, and redirects their previous paths permanently. Articles with custom paths are
+// left untouched. All articles are moved in a transaction, none of them is moved if any fails. Returns the count of
+// moved articles.
+func (srv *articleService) ApplyPermalink(blogID uint64) (count int, err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	var articles []*model.Article
+	if err = db.Select("`id`, `title`, `path`, `created_at`, `blog_id`").Where("`blog_id` = ?", blogID).
+		Find(&articles).Error; nil != err {
+		return
+	}
+
+	var oldPaths []string
+	tx := db.Begin()
+	defer func() {
+		if nil != err {
+			tx.Rollback()
+			count = 0
+
+			return
+		}
+
+		tx.Commit()
+		if 0 < count {
+			Redirect.forgetUnknownPaths(blogID, oldPaths...)
+			cache.Page.Purge(blogID)
+		}
+	}()
+
+	permalink := getPermalink(blogID)
+	for _, article := range articles {
+		if !isGeneratedArticlePath(article) {
+			continue
+		}
+		path := generateArticlePath(article, permalink)
+		if path == article.Path {
+			continue
+		}
+
+		oldPath := article.Path
+		if err = tx.Model(article).UpdateColumns(map[string]interface{}{"Path": path}).Error; nil != err {
+			return
+		}
+		article.Path = path
+		count++
+
+		if err = addRedirectWithoutTx(tx, &model.Redirect{Path: oldPath, TargetURL: path, BlogID: blogID}); nil != err {
+			return
+		}
+		oldPaths = append(oldPaths, oldPath)
+	}
+
+	return
+}
+
 func getPageWindowSize(blogID uint64) (pageSize, windowSize int) {
 	pageSizeSetting := Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceArticleListPageSize, blogID)
 	pageSize, err := strconv.Atoi(pageSizeSetting.Value)
@@ -708,3 +1591,21 @@ func getPageWindowSize(blogID uint64) (pageSize, windowSize int) {
 
 	return
 }
+
+// getArticleListOrder returns the order clause of article lists configured by the specified blog. Topped articles
+// always come first in their order.
+func getArticleListOrder(blogID uint64) string {
+	orderSetting := Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceArticleListOrder, blogID)
+	if nil == orderSetting { // blogs initialized before the setting introduced
+		return "`topped` DESC, `topped_order` ASC, `created_at` DESC"
+	}
+
+	switch orderSetting.Value {
+	case strconv.Itoa(model.SettingPreferenceArticleListOrderValueUpdated):
+		return "`topped` DESC, `topped_order` ASC, `updated_at` DESC"
+	case strconv.Itoa(model.SettingPreferenceArticleListOrderValueView):
+		return "`topped` DESC, `topped_order` ASC, `view_count` DESC, `created_at` DESC"
+	default:
+		return "`topped` DESC, `topped_order` ASC, `created_at` DESC"
+	}
+}
@@ -18,7 +18,9 @@ package service
 
 import (
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/b3log/pipe/model"
 )
@@ -91,6 +93,46 @@ func TestConsoleGetArticles(t *testing.T) {
 	}
 }
 
+func TestConsoleFilterArticles(t *testing.T) {
+	article := &model.Article{
+		AuthorID: 1,
+		Title:    "Filter article",
+		Tags:     "FilterTag",
+		Content:  "Filter article needle",
+		Path:     "/filter-article",
+		Status:   model.ArticleStatusDraft,
+		BlogID:   1,
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer Article.RemoveArticle(article.ID, 1)
+
+	filter := &ArticleFilter{Keyword: "needle", Status: ArticleFilterStatusDraft, Tag: "FilterTag", AuthorID: 1}
+	if articles, _ := Article.ConsoleFilterArticles(filter, 1, 1); 1 != len(articles) || article.ID != articles[0].ID {
+		t.Error("should filter the draft article")
+	}
+	filter.Status = ArticleFilterStatusPublished
+	if articles, _ := Article.ConsoleFilterArticles(filter, 1, 1); 0 != len(articles) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(articles))
+	}
+
+	facets := Article.ConsoleGetArticleFacets(filter, 1) // the status facet is counted regardless of the status filter
+	if 1 != facets.Statuses[ArticleFilterStatusDraft] || 0 != facets.Statuses[ArticleFilterStatusPublished] {
+		t.Errorf("unexpected status facets %v", facets.Statuses)
+	}
+	if 0 != len(facets.Tags) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(facets.Tags))
+	}
+	filter.Status = ArticleFilterStatusDraft
+	facets = Article.ConsoleGetArticleFacets(filter, 1)
+	if 1 != len(facets.Tags) || "FilterTag" != facets.Tags[0].Title || 1 != facets.Tags[0].Count {
+		t.Error("unexpected tag facets")
+	}
+}
+
 func TestGetArticles(t *testing.T) {
 	articles, pagination := Article.GetArticles("", 1, 1)
 	if 20 != len(articles) {
@@ -110,6 +152,18 @@ func TestGetMostViewArticles(t *testing.T) {
 	}
 }
 
+func TestGetPlatformMostViewArticles(t *testing.T) {
+	articles := Article.GetPlatformMostViewArticles(10)
+	if 10 != len(articles) {
+		t.Errorf("expected is [%d], actual is [%d]", 10, len(articles))
+
+		return
+	}
+	if 0 == articles[0].BlogID || "" == articles[0].Path {
+		t.Errorf("blog ID and path of articles should be selected")
+	}
+}
+
 func TestGetMostCommentArticles(t *testing.T) {
 	articles := Article.GetMostCommentArticles(10, 1)
 	if 10 != len(articles) {
@@ -145,6 +199,54 @@ func TestUpdateArticle(t *testing.T) {
 	}
 }
 
+func TestUpdateArticleConflict(t *testing.T) {
+	article := Article.ConsoleGetArticle(lastArticleID)
+	stale := Article.ConsoleGetArticle(lastArticleID)
+	article.Title = "Updated title by one"
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Errorf("update article failed: " + err.Error())
+
+		return
+	}
+
+	stale.Title = "Updated title by another"
+	if err := Article.UpdateArticle(stale); ErrArticleConflict != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrArticleConflict, err)
+	}
+}
+
+func TestUpdateArticleStatus(t *testing.T) {
+	article := Article.ConsoleGetArticle(lastArticleID)
+	article.Status = model.ArticleStatusDraft
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Errorf("update article failed: " + err.Error())
+
+		return
+	}
+
+	article = Article.ConsoleGetArticle(lastArticleID)
+	article.Title = "Updated title of the draft"
+	article.Status = ArticleStatusUnchanged
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Errorf("update article failed: " + err.Error())
+
+		return
+	}
+	if article = Article.ConsoleGetArticle(lastArticleID); model.ArticleStatusDraft != article.Status {
+		t.Errorf("expected is [%d], actual is [%d]", model.ArticleStatusDraft, article.Status)
+	}
+
+	article.Status = 7
+	if err := Article.UpdateArticle(article); ErrInvalidArticleStatus != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidArticleStatus, err)
+	}
+
+	article.Status = model.ArticleStatusOK
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Errorf("update article failed: " + err.Error())
+	}
+}
+
 func TestIncArticleViewCount(t *testing.T) {
 	article := Article.ConsoleGetArticle(lastArticleID)
 	oldCnt := article.ViewCount
@@ -171,6 +273,23 @@ func TestNormalizeTagStr(t *testing.T) {
 	}
 }
 
+func TestNormalizeArticleDescription(t *testing.T) {
+	article := &model.Article{Description: "  A small and\n beautiful   blogging platform "}
+	if err := normalizeArticleDescription(article); nil != err {
+		t.Errorf("normalize article description failed: " + err.Error())
+
+		return
+	}
+	if "A small and beautiful blogging platform" != article.Description {
+		t.Errorf("expected is [%s], actual is [%s]", "A small and beautiful blogging platform", article.Description)
+	}
+
+	article.Description = strings.Repeat("描", maxArticleDescriptionLength+1)
+	if err := normalizeArticleDescription(article); nil == err {
+		t.Error("expected description too long error")
+	}
+}
+
 func TestTagArticle(t *testing.T) {
 	article := Article.ConsoleGetArticle(lastArticleID)
 
@@ -227,3 +346,220 @@ func TestNormalizeArticlePath(t *testing.T) {
 		t.Errorf(article.Path)
 	}
 }
+
+func TestGenerateArticlePath(t *testing.T) {
+	article := &model.Article{Title: "Hello, Pipe 2!"}
+	article.ID = 1
+	article.CreatedAt = time.Date(2019, 5, 1, 0, 0, 0, 0, time.Local)
+
+	cases := map[int]string{
+		model.SettingPreferencePermalinkValueDate:      "/articles/2019/05/01/1",
+		model.SettingPreferencePermalinkValueMonthSlug: "/2019/05/hello-pipe-2",
+		model.SettingPreferencePermalinkValueID:        "/1",
+		model.SettingPreferencePermalinkValueSlug:      "/hello-pipe-2",
+	}
+	for permalink, expected := range cases {
+		if path := generateArticlePath(article, permalink); expected != path {
+			t.Errorf("expected is [%s], actual is [%s]", expected, path)
+		}
+	}
+
+	article.Title = "你好"
+	if path := generateArticlePath(article, model.SettingPreferencePermalinkValueSlug); "/1" != path {
+		t.Errorf("expected is [%s], actual is [%s]", "/1", path)
+	}
+	article.Title = "Tags"
+	if path := generateArticlePath(article, model.SettingPreferencePermalinkValueSlug); "/articles/tags" != path {
+		t.Errorf("expected is [%s], actual is [%s]", "/articles/tags", path)
+	}
+}
+
+func TestTrashArticle(t *testing.T) {
+	article := &model.Article{
+		AuthorID: 1,
+		Title:    "Trash article",
+		Tags:     "Pipe",
+		Content:  "Trash article content",
+		Path:     "/trash-article",
+		BlogID:   1,
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	if err := Article.TrashArticle(article.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if nil != Article.ConsoleGetArticle(article.ID) {
+		t.Error("trashed article should not be got")
+	}
+	if trashed, _ := Article.ConsoleGetTrashedArticles(1, 1); 1 > len(trashed) || article.ID != trashed[0].ID {
+		t.Error("trashed article should be listed in the trash")
+	}
+
+	if err := Article.RestoreArticle(article.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if restored := Article.ConsoleGetArticle(article.ID); nil == restored || "/trash-article" != restored.Path {
+		t.Error("restore article failed")
+	}
+
+	if err := Article.TrashArticle(article.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if count := Article.PurgeTrash(time.Now().Add(time.Minute)); 1 > count {
+		t.Errorf("expected is [%d], actual is [%d]", 1, count)
+	}
+	if nil != Article.ConsoleGetTrashedArticle(article.ID, 1) {
+		t.Error("purged article should not be in the trash")
+	}
+}
+
+func TestBatchUpdateArticles(t *testing.T) {
+	article := &model.Article{
+		AuthorID: 1,
+		Title:    "Batch update article",
+		Tags:     "Pipe,Imported",
+		Content:  "Batch update article content",
+		Path:     "/batch-update-article",
+		Status:   model.ArticleStatusDraft,
+		BlogID:   1,
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	status := model.ArticleStatusOK
+	changes := &ArticleChangeSet{AddTags: []string{"Go"}, RemoveTags: []string{"Imported"}, Status: &status}
+	articles, err := Article.BatchUpdateArticles([]uint64{article.ID, 0}, changes, 1)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if 1 != len(articles) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(articles))
+
+		return
+	}
+	updated := Article.ConsoleGetArticle(article.ID)
+	if "Pipe,Go" != updated.Tags || model.ArticleStatusOK != updated.Status || article.Version+1 != updated.Version {
+		t.Errorf("unexpected updated article [tags=%s, status=%d, version=%d]", updated.Tags, updated.Status, updated.Version)
+	}
+
+	if articles, _ = Article.BatchUpdateArticles([]uint64{article.ID}, changes, 1); 0 != len(articles) {
+		t.Errorf("unchanged articles should not be updated")
+	}
+
+	if err := Article.RemoveArticle(article.ID, 1); nil != err {
+		t.Error(err)
+	}
+}
+
+func TestTopArticle(t *testing.T) {
+	var ids []uint64
+	for i := 0; i < 2; i++ {
+		article := &model.Article{
+			AuthorID: 1,
+			Title:    "Topped article " + strconv.Itoa(i),
+			Tags:     "Pipe",
+			Content:  "Topped article content",
+			Path:     "/topped-article-" + strconv.Itoa(i),
+			BlogID:   1,
+		}
+		if err := Article.AddArticle(article); nil != err {
+			t.Error(err)
+
+			return
+		}
+		defer Article.RemoveArticle(article.ID, 1)
+		ids = append(ids, article.ID)
+	}
+
+	if err := Article.TopArticle(ids[0], true, 2, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := Article.TopArticle(ids[1], true, 1, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	articles, _ := Article.GetArticles("", 1, 1)
+	if ids[1] != articles[0].ID || ids[0] != articles[1].ID {
+		t.Error("topped articles should be listed first in their order")
+	}
+
+	if err := Article.TopArticle(ids[1], false, 1, 1); nil != err {
+		t.Error(err)
+	}
+	if article := Article.ConsoleGetArticle(ids[1]); article.Topped || 0 != article.ToppedOrder {
+		t.Error("article should be untopped")
+	}
+}
+
+func TestArticleVisibility(t *testing.T) {
+	article := &model.Article{
+		AuthorID:   1,
+		Title:      "Protected article",
+		Tags:       "Pipe",
+		Content:    "Protected article content",
+		Path:       "/protected-article",
+		Visibility: model.ArticleVisibilityPassword,
+		BlogID:     1,
+	}
+	if err := Article.AddArticle(article); ErrArticlePassword != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrArticlePassword, err)
+
+		return
+	}
+	if err := Article.SetArticlePassword(article, "secret"); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer Article.RemoveArticle(article.ID, 1)
+
+	articles, _ := Article.GetArticles("Protected", 1, 1)
+	if 0 != len(articles) {
+		t.Error("protected article should not be listed")
+	}
+	if nil == Article.GetArticleByPath("/protected-article", 1) {
+		t.Error("protected article should be reachable by its path")
+	}
+
+	article = Article.ConsoleGetArticle(article.ID)
+	if Article.VerifyArticlePassword(article, "wrong") || !Article.VerifyArticlePassword(article, "secret") {
+		t.Error("verify article password failed")
+	}
+	token := Article.GetArticleUnlockToken(article)
+	if !Article.IsArticleUnlocked(article, token) || Article.IsArticleUnlocked(article, "") {
+		t.Error("check article unlock token failed")
+	}
+
+	article.Visibility = model.ArticleVisibilityUnlisted
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if article = Article.ConsoleGetArticle(article.ID); "" != article.PasswordHash {
+		t.Error("password should be cleared if the article is not protected")
+	}
+}
@@ -0,0 +1,91 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Audit log service.
+var AuditLog = &auditLogService{
+	mutex: &sync.Mutex{},
+}
+
+type auditLogService struct {
+	mutex *sync.Mutex
+}
+
+// Audit log pagination arguments of admin console.
+const (
+	adminConsoleAuditLogListPageSize   = 30
+	adminConsoleAuditLogListWindowSize = 20
+)
+
+// AuditLogQuery represents the filters of querying audit logs, zero values are not filtered.
+type AuditLogQuery struct {
+	UserID uint64
+	Action string
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+}
+
+// AddAuditLog adds the specified audit log.
+func (srv *auditLogService) AddAuditLog(log *model.AuditLog) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Create(log).Error
+}
+
+// GetAuditLogs gets audit logs of the specified blog matched the specified query, the latest first.
+func (srv *auditLogService) GetAuditLogs(query *AuditLogQuery, page int, blogID uint64) (ret []*model.AuditLog, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleAuditLogListPageSize
+	count := 0
+
+	where := "`blog_id` = ?"
+	whereArgs := []interface{}{blogID}
+	if 0 != query.UserID {
+		where += " AND `user_id` = ?"
+		whereArgs = append(whereArgs, query.UserID)
+	}
+	if "" != query.Action {
+		where += " AND `action` = ?"
+		whereArgs = append(whereArgs, query.Action)
+	}
+	if !query.From.IsZero() {
+		where += " AND `created_at` >= ?"
+		whereArgs = append(whereArgs, query.From)
+	}
+	if !query.To.IsZero() {
+		where += " AND `created_at` < ?"
+		whereArgs = append(whereArgs, query.To)
+	}
+
+	if err := db.Model(&model.AuditLog{}).Where(where, whereArgs...).
+		Order("`id` DESC").Count(&count).
+		Offset(offset).Limit(adminConsoleAuditLogListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get audit logs failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleAuditLogListPageSize, adminConsoleAuditLogListWindowSize, count)
+
+	return
+}
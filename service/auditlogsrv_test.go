@@ -0,0 +1,67 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestGetAuditLogs(t *testing.T) {
+	for _, log := range []*model.AuditLog{
+		{UserID: 1, Action: model.AuditActionArticlePublish, Target: "Hello", BlogID: 1},
+		{UserID: 1, Action: model.AuditActionSettingsUpdate, Target: model.SettingCategoryBasic, BlogID: 1},
+		{UserID: 2, Action: model.AuditActionArticleRemove, Target: "Hello", BlogID: 1},
+	} {
+		if err := AuditLog.AddAuditLog(log); nil != err {
+			t.Error(err)
+
+			return
+		}
+	}
+
+	logs, pagination := AuditLog.GetAuditLogs(&AuditLogQuery{}, 1, 1)
+	if 3 != len(logs) || 3 != pagination.RecordCount {
+		t.Errorf("expected is [%d], actual is [%d]", 3, len(logs))
+
+		return
+	}
+	if model.AuditActionArticleRemove != logs[0].Action {
+		t.Errorf("expected is [%s], actual is [%s]", model.AuditActionArticleRemove, logs[0].Action)
+	}
+
+	logs, _ = AuditLog.GetAuditLogs(&AuditLogQuery{UserID: 1}, 1, 1)
+	if 2 != len(logs) {
+		t.Errorf("expected is [%d], actual is [%d]", 2, len(logs))
+	}
+	logs, _ = AuditLog.GetAuditLogs(&AuditLogQuery{UserID: 1, Action: model.AuditActionSettingsUpdate}, 1, 1)
+	if 1 != len(logs) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(logs))
+	}
+
+	now := time.Now()
+	logs, _ = AuditLog.GetAuditLogs(&AuditLogQuery{From: now.Add(-time.Hour), To: now.Add(time.Hour)}, 1, 1)
+	if 3 != len(logs) {
+		t.Errorf("expected is [%d], actual is [%d]", 3, len(logs))
+	}
+	logs, _ = AuditLog.GetAuditLogs(&AuditLogQuery{To: now.Add(-time.Hour)}, 1, 1)
+	if 0 != len(logs) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(logs))
+	}
+}
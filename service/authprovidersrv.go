@@ -0,0 +1,257 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// AuthProvider service, signs in users with the OAuth2 or OpenID Connect login providers configured in
+// Conf.AuthProviders.
+var AuthProvider = &authProviderService{
+	mutex:     &sync.Mutex{},
+	endpoints: map[string]*util.OAuthEndpoints{},
+}
+
+type authProviderService struct {
+	mutex     *sync.Mutex
+	endpoints map[string]*util.OAuthEndpoints // discovered endpoints, key is the provider name
+}
+
+// Default issuers of the OpenID Connect provider types.
+var authProviderIssuers = map[string]string{
+	model.AuthProviderTypeGoogle: "https://accounts.google.com",
+	model.AuthProviderTypeGitLab: "https://gitlab.com",
+}
+
+// githubEndpoints are the OAuth2 endpoints of GitHub, which doesn't support OpenID Connect.
+var githubEndpoints = &util.OAuthEndpoints{
+	AuthURL:     "https://github.com/login/oauth/authorize",
+	TokenURL:    "https://github.com/login/oauth/access_token",
+	UserInfoURL: "https://api.github.com/user",
+}
+
+// ErrUnknownAuthProvider means the login provider is not configured.
+var ErrUnknownAuthProvider = errors.New("unknown login provider")
+
+// ErrIdentityEmailTaken means the email of a new identity belongs to an existing user, the user should sign in and link
+// the identity explicitly.
+var ErrIdentityEmailTaken = errors.New("email of the identity is used by another user, please sign in and link it")
+
+// ErrIdentityLinked means the identity has been linked to another user.
+var ErrIdentityLinked = errors.New("identity has been linked to another user")
+
+var invalidUsernameCharsRegexp = regexp.MustCompile("[^a-zA-Z0-9_-]+")
+
+// GetProvider gets the login provider specified by the name, returns nil if not found.
+func (srv *authProviderService) GetProvider(name string) *model.AuthProvider {
+	for _, provider := range model.Conf.AuthProviders {
+		if name == provider.Name {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+// GetCallbackURL gets the redirect URL of the specified login provider, which should be registered in the provider.
+func (srv *authProviderService) GetCallbackURL(name string) string {
+	return model.Conf.Server + util.PathAPI + "/auth/" + name + "/callback"
+}
+
+// GetAuthCodeURL gets the URL to redirect users to the specified login provider for authorization.
+func (srv *authProviderService) GetAuthCodeURL(name, state string) (string, error) {
+	provider := srv.GetProvider(name)
+	if nil == provider {
+		return "", ErrUnknownAuthProvider
+	}
+	endpoints, err := srv.getEndpoints(provider)
+	if nil != err {
+		return "", err
+	}
+
+	scopes := provider.Scopes
+	if "" == scopes {
+		scopes = "openid profile email"
+		if model.AuthProviderTypeGitHub == provider.Type {
+			scopes = "read:user user:email"
+		}
+	}
+
+	return util.OAuthAuthCodeURL(endpoints, provider.ClientID, srv.GetCallbackURL(name), scopes, state), nil
+}
+
+// Login exchanges the authorization code from the specified login provider, returns the user mapped to the
+// identity. The identity is linked to the user specified by the link user id if it isn't 0, otherwise a user with a blog
+// of its own is created on first sign-in. Identities are never linked to existing users by email, local emails are not
+// verified.
+func (srv *authProviderService) Login(name, code string, linkUserID uint64) (*model.User, error) {
+	provider := srv.GetProvider(name)
+	if nil == provider {
+		return nil, ErrUnknownAuthProvider
+	}
+	endpoints, err := srv.getEndpoints(provider)
+	if nil != err {
+		return nil, err
+	}
+
+	accessToken, err := util.OAuthExchange(endpoints, provider.ClientID, provider.ClientSecret, srv.GetCallbackURL(name), code)
+	if nil != err {
+		return nil, err
+	}
+	claims, err := util.OAuthUserInfo(endpoints, accessToken)
+	if nil != err {
+		return nil, err
+	}
+
+	return srv.mapIdentity(provider.Name, claims, linkUserID)
+}
+
+func (srv *authProviderService) getEndpoints(provider *model.AuthProvider) (*util.OAuthEndpoints, error) {
+	if model.AuthProviderTypeGitHub == provider.Type {
+		return githubEndpoints, nil
+	}
+
+	srv.mutex.Lock()
+	ret := srv.endpoints[provider.Name]
+	srv.mutex.Unlock()
+	if nil != ret {
+		return ret, nil
+	}
+
+	issuer := provider.Issuer
+	if "" == issuer {
+		issuer = authProviderIssuers[provider.Type]
+	}
+	ret, err := util.DiscoverOIDC(issuer)
+	if nil != err {
+		logger.Errorf("discover login provider [%s] failed: %s", provider.Name, err)
+
+		return nil, err
+	}
+
+	srv.mutex.Lock()
+	srv.endpoints[provider.Name] = ret
+	srv.mutex.Unlock()
+
+	return ret, nil
+}
+
+// mapIdentity returns the user of the identity specified by the provider name and the user info claims.
+func (srv *authProviderService) mapIdentity(providerName string, claims map[string]interface{}, linkUserID uint64) (*model.User, error) {
+	subject := claimString(claims, "sub", "id")
+	if "" == subject {
+		return nil, errors.New("subject is missing in the user info")
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	identity := &model.Identity{}
+	if err := db.Where("`provider` = ? AND `subject` = ?", providerName, subject).First(identity).Error; nil == err {
+		if 0 != linkUserID && linkUserID != identity.UserID {
+			return nil, ErrIdentityLinked
+		}
+		if user := User.GetUser(identity.UserID); nil != user {
+			return user, nil
+		}
+
+		return nil, errors.New("user of identity [" + providerName + ", " + subject + "] not found")
+	}
+
+	var user *model.User
+	if 0 != linkUserID {
+		if user = User.GetUser(linkUserID); nil == user {
+			return nil, errors.New("user [" + strconv.FormatUint(linkUserID, 10) + "] not found")
+		}
+	} else {
+		email := ""
+		if verified, _ := claims["email_verified"].(bool); verified {
+			email = strings.ToLower(claimString(claims, "email"))
+		}
+		if nil != LocalAuth.getUserByEmail(email) {
+			return nil, ErrIdentityEmailTaken
+		}
+
+		user = &model.User{
+			Name:      srv.availableName(claimString(claims, "preferred_username", "login", "nickname", "name")),
+			Email:     email,
+			AvatarURL: claimString(claims, "picture", "avatar_url"),
+		}
+		if "" == user.AvatarURL {
+			user.AvatarURL = gravatarURL(providerName + subject)
+		}
+
+		var err error
+		if !Init.Inited() {
+			err = Init.InitPlatform(user)
+		} else {
+			err = Init.InitBlog(user)
+		}
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	identity = &model.Identity{Provider: providerName, Subject: subject, UserID: user.ID}
+	if err := db.Create(identity).Error; nil != err {
+		return nil, err
+	}
+	logger.Infof("mapped identity [%s, %s] to user [%s]", providerName, subject, user.Name)
+
+	return user, nil
+}
+
+// availableName returns a valid username not taken based on the specified name.
+func (srv *authProviderService) availableName(name string) string {
+	name = invalidUsernameCharsRegexp.ReplaceAllString(name, "")
+	if 27 < len(name) {
+		name = name[:27]
+	}
+	if "" == name {
+		name = "user"
+	}
+
+	ret := name
+	for nil != User.GetUserByName(ret) || nil != User.GetUserByAlias(ret) {
+		ret = name + "_" + gulu.Rand.String(4)
+	}
+
+	return ret
+}
+
+// claimString returns the first non-empty claim of the specified keys as a string.
+func claimString(claims map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := claims[key]; ok && nil != value {
+			if ret := strings.TrimSpace(fmt.Sprint(value)); "" != ret {
+				return ret
+			}
+		}
+	}
+
+	return ""
+}
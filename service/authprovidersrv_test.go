@@ -0,0 +1,97 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMapIdentity(t *testing.T) {
+	claims := map[string]interface{}{
+		"id":    json.Number("10086"),
+		"login": testPlatformAdminName,
+	}
+	user, err := AuthProvider.mapIdentity("github", claims, 0)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if testPlatformAdminName == user.Name {
+		t.Errorf("name of the new user should not be taken")
+	}
+	if nil == User.GetOwnBlog(user.ID) {
+		t.Errorf("blog of the new user should be initialized")
+	}
+
+	mapped, err := AuthProvider.mapIdentity("github", claims, 0)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if user.ID != mapped.ID {
+		t.Errorf("expected is [%d], actual is [%d]", user.ID, mapped.ID)
+	}
+
+	if _, err := AuthProvider.mapIdentity("github", map[string]interface{}{}, 0); nil == err {
+		t.Errorf("identity without subject should not be mapped")
+	}
+	if _, err := AuthProvider.mapIdentity("github", claims, user.ID+1); ErrIdentityLinked != err {
+		t.Errorf("identity linked to another user should not be linked again")
+	}
+}
+
+func TestMapIdentityEmail(t *testing.T) {
+	admin := User.GetUserByName(testPlatformAdminName)
+	email := admin.Email
+	if err := db.Model(admin).Update("email", "admin@b3log.org").Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer db.Model(admin).Update("email", email)
+
+	claims := map[string]interface{}{
+		"sub":            "oidc-admin",
+		"email":          "admin@b3log.org",
+		"email_verified": true,
+	}
+	if _, err := AuthProvider.mapIdentity("google", claims, 0); ErrIdentityEmailTaken != err {
+		t.Errorf("identity should not be linked to an existing user by email")
+	}
+
+	user, err := AuthProvider.mapIdentity("google", claims, admin.ID)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if admin.ID != user.ID {
+		t.Errorf("expected is [%d], actual is [%d]", admin.ID, user.ID)
+	}
+}
+
+func TestAvailableName(t *testing.T) {
+	if name := AuthProvider.availableName("Pipe User!"); "PipeUser" != name {
+		t.Errorf("expected is [PipeUser], actual is [%s]", name)
+	}
+	if name := AuthProvider.availableName("中文"); "user" != name {
+		t.Errorf("expected is [user], actual is [%s]", name)
+	}
+}
@@ -0,0 +1,447 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
+)
+
+// Backup service.
+var Backup = &backupService{
+	mutex: &sync.Mutex{},
+}
+
+type backupService struct {
+	mutex *sync.Mutex
+}
+
+// backupDataFilename is the name of the data file in a backup archive.
+const backupDataFilename = "backup.json"
+
+// Directories of the files in a backup archive.
+const (
+	backupMediaDir       = "media/"        // media files, named by their media keys
+	backupThemeCustomDir = "theme/custom/" // theme template overrides, named by {theme}/{template}
+)
+
+// BlogBackup represents a full backup of a blog.
+type BlogBackup struct {
+	Version       string                `json:"version"`
+	BlogID        uint64                `json:"blogID"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	Users         []*model.User         `json:"users"`
+	Articles      []*model.Article      `json:"articles"`
+	Comments      []*model.Comment      `json:"comments"`
+	Navigations   []*model.Navigation   `json:"navigations"`
+	Tags          []*model.Tag          `json:"tags"`
+	Categories    []*model.Category     `json:"categories"`
+	Archives      []*model.Archive      `json:"archives"`
+	Settings      []*model.Setting      `json:"settings"`
+	Correlations  []*model.Correlation  `json:"correlations"`
+	Glossaries    []*model.Glossary     `json:"glossaries"`
+	Redirects     []*model.Redirect     `json:"redirects"`
+	Pages         []*model.Page         `json:"pages"`
+	Revisions     []*model.Revision     `json:"revisions"`
+	MediaFiles    []*model.MediaFile    `json:"mediaFiles"`
+	Aliases       []*model.Alias        `json:"aliases"`
+	Subscribers   []*model.Subscriber   `json:"subscribers"`
+	Invitations   []*model.Invitation   `json:"invitations"`
+	Followers     []*model.Follower     `json:"followers"`
+	Reports       []*model.Report       `json:"reports"`
+	LinkSnapshots []*model.LinkSnapshot `json:"linkSnapshots"`
+
+	// ArticlePasswords holds the access password hashes of the password protected articles, key is the article ID.
+	// Article.PasswordHash is never serialized to keep it out of the API responses.
+	ArticlePasswords map[uint64]string `json:"articlePasswords"`
+
+	MediaData  map[string][]byte `json:"-"` // media key -> data, stored as files in the archive
+	ThemeFiles map[string][]byte `json:"-"` // {theme}/{template} -> data of the theme template overrides
+}
+
+// backupRows returns pointers to the slices of the rows belonging to the blog in the specified backup.
+func backupRows(backup *BlogBackup) []interface{} {
+	return []interface{}{&backup.Articles, &backup.Comments, &backup.Navigations, &backup.Tags, &backup.Categories,
+		&backup.Archives, &backup.Settings, &backup.Correlations, &backup.Glossaries, &backup.Redirects, &backup.Pages,
+		&backup.Revisions, &backup.MediaFiles, &backup.Subscribers, &backup.Invitations, &backup.Followers,
+		&backup.Reports, &backup.LinkSnapshots}
+}
+
+// backupModels are the models of the rows belonging to a blog, in the same order of backupRows.
+var backupModels = []interface{}{&model.Article{}, &model.Comment{}, &model.Navigation{}, &model.Tag{},
+	&model.Category{}, &model.Archive{}, &model.Setting{}, &model.Correlation{}, &model.Glossary{}, &model.Redirect{},
+	&model.Page{}, &model.Revision{}, &model.MediaFile{}, &model.Subscriber{}, &model.Invitation{}, &model.Follower{},
+	&model.Report{}, &model.LinkSnapshot{}}
+
+// GetBlogIDs gets IDs of all blogs.
+func (srv *backupService) GetBlogIDs() (ret []uint64) {
+	var settings []*model.Setting
+	if err := db.Where("`category` = ? AND `name` = ?", model.SettingCategorySystem, model.SettingNameSystemVer).
+		Find(&settings).Error; nil != err {
+		logger.Errorf("get blogs failed: %s", err)
+
+		return
+	}
+
+	for _, setting := range settings {
+		ret = append(ret, setting.BlogID)
+	}
+
+	return
+}
+
+// Backup dumps all data of the specified blog.
+func (srv *backupService) Backup(blogID uint64) (ret *BlogBackup, err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ret = &BlogBackup{Version: model.Version, BlogID: blogID, CreatedAt: time.Now(), ArticlePasswords: map[uint64]string{},
+		MediaData: map[string][]byte{}, ThemeFiles: map[string][]byte{}}
+	for _, rows := range backupRows(ret) {
+		if err = db.Where("`blog_id` = ?", blogID).Find(rows).Error; nil != err {
+			return nil, err
+		}
+	}
+	for _, article := range ret.Articles {
+		if "" != article.PasswordHash {
+			ret.ArticlePasswords[article.ID] = article.PasswordHash
+		}
+	}
+
+	var userIDs []uint64
+	for _, correlation := range ret.Correlations {
+		if model.CorrelationBlogUser == correlation.Type {
+			userIDs = append(userIDs, correlation.ID2)
+		}
+	}
+	if 0 < len(userIDs) {
+		if err = db.Where("`id` IN (?)", userIDs).Find(&ret.Users).Error; nil != err {
+			return nil, err
+		}
+		if err = db.Where("`user_id` IN (?)", userIDs).Find(&ret.Aliases).Error; nil != err {
+			return nil, err
+		}
+	}
+
+	if Media.Enabled() {
+		for _, mediaFile := range ret.MediaFiles {
+			data, err := Media.Get(mediaFile.Key)
+			if nil != err {
+				logger.Errorf("backup media file [%s] failed: %s", mediaFile.Key, err)
+
+				continue
+			}
+			ret.MediaData[mediaFile.Key] = data
+		}
+	}
+
+	overrides, err := filepath.Glob(filepath.Join(theme.CustomDir(blogID), "*", "*.html"))
+	if nil != err {
+		return nil, err
+	}
+	for _, override := range overrides {
+		data, err := ioutil.ReadFile(override)
+		if nil != err {
+			return nil, err
+		}
+		ret.ThemeFiles[filepath.Base(filepath.Dir(override))+"/"+filepath.Base(override)] = data
+	}
+
+	return ret, nil
+}
+
+// Archive backs up the specified blog into a zip archive, returns the archive name and data.
+func (srv *backupService) Archive(blogID uint64) (name string, data []byte, err error) {
+	backup, err := srv.Backup(blogID)
+	if nil != err {
+		return "", nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = srv.WriteArchive(backup, buf); nil != err {
+		return "", nil, err
+	}
+	name = "pipe-backup-" + strconv.FormatUint(blogID, 10) + "-" + backup.CreatedAt.Format("20060102150405") + ".zip"
+
+	return name, buf.Bytes(), nil
+}
+
+// Restore replaces all data of the specified blog with the specified backup. Users not existing will be created,
+// existing users are kept unchanged.
+func (srv *backupService) Restore(backup *BlogBackup, blogID uint64) (err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if backup.BlogID != blogID {
+		return errors.New("the backup belongs to another blog")
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			srv.restoreFiles(backup)
+			cache.Article.Purge()
+			cache.Comment.Purge()
+			cache.User.Purge()
+			cache.Setting.Purge()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	for _, m := range backupModels {
+		if err = tx.Unscoped().Where("`blog_id` = ?", blogID).Delete(m).Error; nil != err {
+			return
+		}
+	}
+
+	for _, user := range backup.Users {
+		count := 0
+		if err = tx.Model(&model.User{}).Where("`id` = ?", user.ID).Count(&count).Error; nil != err {
+			return
+		}
+		if 0 < count {
+			continue
+		}
+		if err = tx.Create(user).Error; nil != err {
+			return
+		}
+	}
+	for _, alias := range backup.Aliases {
+		count := 0
+		if err = tx.Model(&model.Alias{}).Where("`name` = ?", alias.Name).Count(&count).Error; nil != err {
+			return
+		}
+		if 0 < count {
+			continue
+		}
+		if err = tx.Create(alias).Error; nil != err {
+			return
+		}
+	}
+
+	var rows []interface{}
+	for _, article := range backup.Articles {
+		article.PasswordHash = backup.ArticlePasswords[article.ID]
+		rows = append(rows, article)
+	}
+	for _, comment := range backup.Comments {
+		rows = append(rows, comment)
+	}
+	for _, navigation := range backup.Navigations {
+		rows = append(rows, navigation)
+	}
+	for _, tag := range backup.Tags {
+		rows = append(rows, tag)
+	}
+	for _, category := range backup.Categories {
+		rows = append(rows, category)
+	}
+	for _, archive := range backup.Archives {
+		rows = append(rows, archive)
+	}
+	for _, setting := range backup.Settings {
+		rows = append(rows, setting)
+	}
+	for _, correlation := range backup.Correlations {
+		rows = append(rows, correlation)
+	}
+	for _, glossary := range backup.Glossaries {
+		rows = append(rows, glossary)
+	}
+	for _, redirect := range backup.Redirects {
+		rows = append(rows, redirect)
+	}
+	for _, page := range backup.Pages {
+		rows = append(rows, page)
+	}
+	for _, revision := range backup.Revisions {
+		rows = append(rows, revision)
+	}
+	for _, mediaFile := range backup.MediaFiles {
+		rows = append(rows, mediaFile)
+	}
+	for _, subscriber := range backup.Subscribers {
+		rows = append(rows, subscriber)
+	}
+	for _, invitation := range backup.Invitations {
+		rows = append(rows, invitation)
+	}
+	for _, follower := range backup.Followers {
+		rows = append(rows, follower)
+	}
+	for _, report := range backup.Reports {
+		rows = append(rows, report)
+	}
+	for _, linkSnapshot := range backup.LinkSnapshots {
+		rows = append(rows, linkSnapshot)
+	}
+	for _, row := range rows {
+		if err = tx.Create(row).Error; nil != err {
+			return
+		}
+	}
+
+	return nil // triger commit in the defer
+}
+
+// restoreFiles restores the media files and theme template overrides of the specified backup. Failures are logged
+// since the data has been restored.
+func (srv *backupService) restoreFiles(backup *BlogBackup) {
+	if 0 < len(backup.MediaData) && !Media.Enabled() {
+		logger.Warnf("media storage is not configured, skipped restoring [%d] media files", len(backup.MediaData))
+	} else {
+		for key, data := range backup.MediaData {
+			if _, err := Media.Put(key, data); nil != err {
+				logger.Errorf("restore media file [%s] failed: %s", key, err)
+			}
+		}
+	}
+
+	for name, data := range backup.ThemeFiles {
+		if !isBackupThemeFile(name) {
+			logger.Warnf("skipped restoring invalid theme template override [%s]", name)
+
+			continue
+		}
+
+		parts := strings.Split(name, "/")
+		dir, file := filepath.Join(theme.CustomDir(backup.BlogID), parts[0]), parts[1]
+		if err := os.MkdirAll(dir, 0755); nil != err {
+			logger.Errorf("restore theme template override [%s] failed: %s", name, err)
+
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, file), data, 0644); nil != err {
+			logger.Errorf("restore theme template override [%s] failed: %s", name, err)
+		}
+	}
+	if 0 < len(backup.ThemeFiles) {
+		if err := theme.ReloadTemplates(); nil != err {
+			logger.Errorf("reload templates failed: %s", err)
+		}
+	}
+}
+
+// isBackupThemeFile checks whether the specified name of a theme file in a backup archive is {theme}/{template}.html.
+func isBackupThemeFile(name string) bool {
+	parts := strings.Split(name, "/")
+
+	return 2 == len(parts) && "" != parts[0] && "." != parts[0] && ".." != parts[0] &&
+		!strings.ContainsAny(parts[0], "\\:") && !strings.ContainsAny(parts[1], "\\:") &&
+		strings.HasSuffix(parts[1], ".html") && ".html" != parts[1]
+}
+
+// WriteArchive writes the specified backup into a zip archive.
+func (srv *backupService) WriteArchive(backup *BlogBackup, writer io.Writer) error {
+	data, err := json.MarshalIndent(backup, "", "\t")
+	if nil != err {
+		return err
+	}
+
+	archive := zip.NewWriter(writer)
+	write := func(name string, data []byte) error {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetModTime(backup.CreatedAt)
+		file, err := archive.CreateHeader(header)
+		if nil != err {
+			return err
+		}
+		_, err = file.Write(data)
+
+		return err
+	}
+
+	if err = write(backupDataFilename, data); nil != err {
+		return err
+	}
+	for key, data := range backup.MediaData {
+		if err = write(backupMediaDir+key, data); nil != err {
+			return err
+		}
+	}
+	for name, data := range backup.ThemeFiles {
+		if err = write(backupThemeCustomDir+name, data); nil != err {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// ReadArchive reads a backup from the specified zip archive data.
+func (srv *backupService) ReadArchive(data []byte) (*BlogBackup, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if nil != err {
+		return nil, err
+	}
+
+	var ret *BlogBackup
+	mediaData, themeFiles := map[string][]byte{}, map[string][]byte{}
+	for _, file := range archive.File {
+		isData := backupDataFilename == file.Name
+		isMedia := strings.HasPrefix(file.Name, backupMediaDir) && !strings.HasSuffix(file.Name, "/")
+		isTheme := strings.HasPrefix(file.Name, backupThemeCustomDir) && !strings.HasSuffix(file.Name, "/")
+		if !isData && !isMedia && !isTheme {
+			continue
+		}
+
+		reader, err := file.Open()
+		if nil != err {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if nil != err {
+			return nil, err
+		}
+
+		switch {
+		case isData:
+			ret = &BlogBackup{}
+			if err = json.Unmarshal(data, ret); nil != err {
+				return nil, err
+			}
+		case isMedia:
+			mediaData[strings.TrimPrefix(file.Name, backupMediaDir)] = data
+		case isTheme:
+			themeFiles[strings.TrimPrefix(file.Name, backupThemeCustomDir)] = data
+		}
+	}
+	if nil == ret {
+		return nil, errors.New("not found " + backupDataFilename + " in the backup archive")
+	}
+	ret.MediaData, ret.ThemeFiles = mediaData, themeFiles
+
+	return ret, nil
+}
@@ -0,0 +1,142 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestBackupRestore(t *testing.T) {
+	page := &model.Page{AuthorID: 1, Title: "Backup", Content: "backup", Path: "/backup-page", BlogID: 1}
+	if err := db.Create(page).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer db.Unscoped().Delete(page)
+	articles, _ := Article.GetArticles("", 1, 1)
+	protected := articles[0]
+	if err := db.Model(protected).UpdateColumn("password_hash", "hash").Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer db.Model(protected).UpdateColumn("password_hash", "")
+
+	backup, err := Backup.Backup(1)
+	if nil != err {
+		t.Errorf("backup failed: " + err.Error())
+
+		return
+	}
+	if 1 > len(backup.Articles) || 1 > len(backup.Settings) || 1 > len(backup.Users) {
+		t.Errorf("backup is incomplete [articles=%d, settings=%d, users=%d]", len(backup.Articles), len(backup.Settings), len(backup.Users))
+
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err = Backup.WriteArchive(backup, buf); nil != err {
+		t.Errorf("write backup archive failed: " + err.Error())
+
+		return
+	}
+	restored, err := Backup.ReadArchive(buf.Bytes())
+	if nil != err {
+		t.Errorf("read backup archive failed: " + err.Error())
+
+		return
+	}
+	if err = Backup.Restore(restored, 1); nil != err {
+		t.Errorf("restore failed: " + err.Error())
+
+		return
+	}
+
+	backup2, _ := Backup.Backup(1)
+	if len(backup.Articles) != len(backup2.Articles) || len(backup.Settings) != len(backup2.Settings) {
+		t.Errorf("expected is [%d, %d], actual is [%d, %d]", len(backup.Articles), len(backup.Settings),
+			len(backup2.Articles), len(backup2.Settings))
+	}
+	if 1 != len(backup2.Pages) || page.Path != backup2.Pages[0].Path {
+		t.Errorf("pages should be restored")
+	}
+	restoredArticle := &model.Article{}
+	db.First(restoredArticle, protected.ID)
+	if "hash" != restoredArticle.PasswordHash {
+		t.Errorf("expected is [hash], actual is [%s]", restoredArticle.PasswordHash)
+	}
+	if err = Backup.Restore(restored, 2); nil == err {
+		t.Errorf("restore a backup of another blog should fail")
+	}
+}
+
+func TestArchive(t *testing.T) {
+	name, data, err := Backup.Archive(1)
+	if nil != err {
+		t.Errorf("archive failed: " + err.Error())
+
+		return
+	}
+	if !strings.HasPrefix(name, "pipe-backup-1-") || !strings.HasSuffix(name, ".zip") {
+		t.Errorf("unexpected archive name [%s]", name)
+	}
+	if _, err = Backup.ReadArchive(data); nil != err {
+		t.Errorf("read archive failed: " + err.Error())
+	}
+}
+
+func TestReadArchiveFiles(t *testing.T) {
+	backup := &BlogBackup{
+		BlogID:     1,
+		MediaData:  map[string][]byte{"media/201901/a.png": []byte("png")},
+		ThemeFiles: map[string][]byte{"Littlewin/index.html": []byte("index")},
+	}
+	buf := &bytes.Buffer{}
+	if err := Backup.WriteArchive(backup, buf); nil != err {
+		t.Error(err)
+
+		return
+	}
+	restored, err := Backup.ReadArchive(buf.Bytes())
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "png" != string(restored.MediaData["media/201901/a.png"]) || "index" != string(restored.ThemeFiles["Littlewin/index.html"]) {
+		t.Errorf("files of the backup should be read")
+	}
+}
+
+func TestIsBackupThemeFile(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"Littlewin/index.html":    true,
+		"../index.html":           false,
+		"Littlewin/../index.html": false,
+		"Littlewin/style.css":     false,
+		"index.html":              false,
+	} {
+		if expected != isBackupThemeFile(name) {
+			t.Errorf("expected is [%v], actual is [%v] for [%s]", expected, !expected, name)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/parnurzeal/gorequest"
+)
+
+// Captcha service.
+var Captcha = &captchaService{
+	mutex:  &sync.Mutex{},
+	solved: map[string]time.Time{},
+}
+
+type captchaService struct {
+	mutex  *sync.Mutex
+	solved map[string]time.Time // solved challenge -> expiration time, rejects replayed solutions
+}
+
+// ErrCaptchaFailed means the captcha of a request is missing or not solved.
+var ErrCaptchaFailed = errors.New("captcha verification failed")
+
+// ProofOfWorkDifficulty is the count of leading zero bits required in the SHA-256 hash of proof-of-work solutions.
+const ProofOfWorkDifficulty = 16
+
+// challengeTTL is the duration a proof-of-work challenge stays valid.
+const challengeTTL = 10 * time.Minute
+
+// captchaVerifyURLs maps third-party captcha providers to their verification endpoints.
+var captchaVerifyURLs = map[string]string{
+	model.SettingSpamCaptchaValueHCaptcha:  "https://hcaptcha.com/siteverify",
+	model.SettingSpamCaptchaValueReCaptcha: "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// GetCaptcha returns the captcha provider and site key configured by the specified blog, the provider is empty if
+// captcha is disabled.
+func (srv *captchaService) GetCaptcha(blogID uint64) (provider, siteKey string) {
+	providerSetting := Setting.GetSetting(model.SettingCategorySpam, model.SettingNameSpamCaptcha, blogID)
+	if nil == providerSetting {
+		return
+	}
+	provider = providerSetting.Value
+	if siteKeySetting := Setting.GetSetting(model.SettingCategorySpam, model.SettingNameSpamCaptchaSiteKey, blogID); nil != siteKeySetting {
+		siteKey = siteKeySetting.Value
+	}
+
+	return
+}
+
+// Verify verifies the specified captcha token submitted from the specified IP against the captcha configured by the
+// specified blog. Returns nil if captcha is disabled.
+func (srv *captchaService) Verify(token, ip string, blogID uint64) error {
+	provider, _ := srv.GetCaptcha(blogID)
+	switch provider {
+	case model.SettingSpamCaptchaValueNone:
+		return nil
+	case model.SettingSpamCaptchaValueProofOfWork:
+		return srv.verifyProofOfWork(token)
+	}
+
+	verifyURL := captchaVerifyURLs[provider]
+	if "" == verifyURL {
+		logger.Warnf("unknown captcha provider [%s] of blog [%d]", provider, blogID)
+
+		return nil
+	}
+	if "" == token {
+		return ErrCaptchaFailed
+	}
+	secretSetting := Setting.GetSetting(model.SettingCategorySpam, model.SettingNameSpamCaptchaSecret, blogID)
+	if nil == secretSetting || "" == secretSetting.Value {
+		logger.Warnf("captcha secret of blog [%d] is not configured", blogID)
+
+		return nil
+	}
+
+	response, data, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		Post(verifyURL).Type("form").SendMap(map[string]interface{}{
+		"secret":   secretSetting.Value,
+		"response": token,
+		"remoteip": ip,
+	}).Set("user-agent", model.UserAgent).Timeout(10 * time.Second).End()
+	if nil != errs {
+		return errs[0]
+	}
+	if http.StatusOK != response.StatusCode {
+		return errors.New(provider + " responded status code [" + response.Status + "]")
+	}
+	ret := struct {
+		Success bool `json:"success"`
+	}{}
+	if err := json.Unmarshal([]byte(data), &ret); nil != err {
+		return err
+	}
+	if !ret.Success {
+		return ErrCaptchaFailed
+	}
+
+	return nil
+}
+
+// NewChallenge returns a new proof-of-work challenge in format of "timestamp.nonce.signature".
+func (srv *captchaService) NewChallenge() string {
+	nonce := make([]byte, 12)
+	rand.Read(nonce)
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + hex.EncodeToString(nonce)
+
+	return payload + "." + signChallenge(payload)
+}
+
+// verifyProofOfWork verifies the specified proof-of-work solution in format of "challenge:answer", the SHA-256 hash of
+// the solution must have ProofOfWorkDifficulty leading zero bits. Each challenge could be solved only once.
+func (srv *captchaService) verifyProofOfWork(solution string) error {
+	idx := strings.LastIndex(solution, ":")
+	if 0 > idx {
+		return ErrCaptchaFailed
+	}
+	challenge := solution[:idx]
+	parts := strings.Split(challenge, ".")
+	if 3 != len(parts) || !hmac.Equal([]byte(parts[2]), []byte(signChallenge(parts[0]+"."+parts[1]))) {
+		return ErrCaptchaFailed
+	}
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if nil != err {
+		return ErrCaptchaFailed
+	}
+	expiredAt := time.Unix(issued, 0).Add(challengeTTL)
+	now := time.Now()
+	if now.After(expiredAt) {
+		return ErrCaptchaFailed
+	}
+
+	hash := sha256.Sum256([]byte(solution))
+	zeros := 0
+	for _, b := range hash {
+		zeros += bits.LeadingZeros8(b)
+		if 0 != b {
+			break
+		}
+	}
+	if ProofOfWorkDifficulty > zeros {
+		return ErrCaptchaFailed
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if _, ok := srv.solved[challenge]; ok {
+		return ErrCaptchaFailed
+	}
+	for c, expiration := range srv.solved {
+		if now.After(expiration) {
+			delete(srv.solved, c)
+		}
+	}
+	srv.solved[challenge] = expiredAt
+
+	return nil
+}
+
+// signChallenge signs the specified challenge payload with the session secret.
+func signChallenge(payload string) string {
+	mac := hmac.New(sha256.New, []byte(model.Conf.SessionSecret))
+	mac.Write([]byte(payload))
+
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
@@ -0,0 +1,80 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"strconv"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestVerifyProofOfWork(t *testing.T) {
+	blogID := uint64(98)
+	if err := Setting.UpdateSettings(model.SettingCategorySpam, []*model.Setting{
+		{Name: model.SettingNameSpamCaptcha, Value: model.SettingSpamCaptchaValueProofOfWork},
+	}, blogID); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	challenge := Captcha.NewChallenge()
+	i := 0
+	for ; solves(challenge + ":" + strconv.Itoa(i)); i++ {
+	}
+	if err := Captcha.Verify(challenge+":"+strconv.Itoa(i), "127.0.0.1", blogID); ErrCaptchaFailed != err {
+		t.Error("unsolved challenge should be rejected")
+	}
+
+	solution := ""
+	for i = 0; ; i++ {
+		solution = challenge + ":" + strconv.Itoa(i)
+		if solves(solution) {
+			break
+		}
+	}
+	if err := Captcha.Verify(solution, "127.0.0.1", blogID); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := Captcha.Verify(solution, "127.0.0.1", blogID); ErrCaptchaFailed != err {
+		t.Error("replayed solution should be rejected")
+	}
+	if err := Captcha.Verify("1.2.3:4", "127.0.0.1", blogID); ErrCaptchaFailed != err {
+		t.Error("forged challenge should be rejected")
+	}
+	if err := Captcha.Verify("", "127.0.0.1", 97); nil != err {
+		t.Error("captcha should be disabled by default")
+	}
+}
+
+func solves(solution string) bool {
+	hash := sha256.Sum256([]byte(solution))
+	zeros := 0
+	for _, b := range hash {
+		zeros += bits.LeadingZeros8(b)
+		if 0 != b {
+			break
+		}
+	}
+
+	return ProofOfWorkDifficulty <= zeros
+}
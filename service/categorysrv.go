@@ -23,6 +23,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
 	"github.com/jinzhu/gorm"
@@ -66,11 +67,11 @@ AND b3_pipe_correlations.id2 IN (
 	AND b3_pipe_correlations.blog_id = ?
 )`
 	if rows, err := db.DB().Query(sql, blogID, categoryID, blogID); nil != err {
-		logger.Errorf("get category article count failed: " + err.Error())
+		logger.Errorf("get category article count failed: %s", err)
 	} else {
 		rows.Next()
 		if err = rows.Scan(&ret); nil != err {
-			logger.Errorf("get category article count failed: " + err.Error())
+			logger.Errorf("get category article count failed: %s", err)
 		}
 	}
 
@@ -115,6 +116,15 @@ func (srv *categoryService) UpdateCategory(category *model.Category) error {
 
 		return err
 	}
+	if err := tx.Model(category).Updates(map[string]interface{}{ // zero values are skipped by struct updates
+		"layout":      category.Layout,
+		"commentable": category.Commentable,
+		"sign":        category.Sign,
+	}).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
 	if err := tx.Where("`id1` = ? AND `type` = ? AND `blog_id` = ?",
 		category.ID, model.CorrelationCategoryTag, category.BlogID).Delete(model.Correlation{}).Error; nil != err {
 		tx.Rollback()
@@ -127,6 +137,7 @@ func (srv *categoryService) UpdateCategory(category *model.Category) error {
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(category.BlogID)
 
 	return nil
 }
@@ -163,25 +174,71 @@ func (srv *categoryService) AddCategory(category *model.Category) error {
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(category.BlogID)
 
 	return nil
 }
 
 func (srv *categoryService) GetCategoriesByTag(tagTitle string, blogID uint64) (ret []*model.Category) {
 	if err := db.Where("`blog_id` = ? AND `tags` LIKE ?", blogID, tagTitle).Find(&ret).Error; nil != err {
-		logger.Errorf("get categories failed: " + err.Error())
+		logger.Errorf("get categories failed: %s", err)
 	}
 
 	return
 }
 
+// GetArticleCategory gets the first category (ordered by number) which contains any of the specified article tags.
+func (srv *categoryService) GetArticleCategory(articleTags string, blogID uint64) *model.Category {
+	tags := map[string]bool{}
+	for _, tag := range strings.Split(articleTags, ",") {
+		if tag = strings.TrimSpace(tag); "" != tag {
+			tags[tag] = true
+		}
+	}
+	if 1 > len(tags) {
+		return nil
+	}
+
+	var categories []*model.Category
+	if err := db.Where("`blog_id` = ?", blogID).Order("`number` ASC, `id` ASC").Find(&categories).Error; nil != err {
+		logger.Errorf("get categories failed: %s", err)
+
+		return nil
+	}
+	for _, category := range categories {
+		for _, tag := range strings.Split(category.Tags, ",") {
+			if tags[strings.TrimSpace(tag)] {
+				return category
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetArticleCommentable gets the default commentable of a new article with the specified tags.
+func (srv *categoryService) GetArticleCommentable(articleTags string, blogID uint64) bool {
+	if category := srv.GetArticleCategory(articleTags, blogID); nil != category {
+		switch category.Commentable {
+		case model.CategoryCommentableOn:
+			return true
+		case model.CategoryCommentableOff:
+			return false
+		}
+	}
+
+	commentableSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicCommentable, blogID)
+
+	return nil == commentableSetting || "true" == commentableSetting.Value
+}
+
 func (srv *categoryService) ConsoleGetCategories(page int, blogID uint64) (ret []*model.Category, pagination *util.Pagination) {
 	offset := (page - 1) * adminConsoleCategoryListPageSize
 	count := 0
 	if err := db.Model(&model.Category{}).Order("`number` ASC, `id` DESC").
 		Where("`blog_id` = ?", blogID).
 		Count(&count).Offset(offset).Limit(adminConsoleCategoryListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get categories failed: " + err.Error())
+		logger.Errorf("get categories failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, adminConsoleCategoryListPageSize, adminConsoleCategoryListWindowSize, count)
@@ -191,7 +248,7 @@ func (srv *categoryService) ConsoleGetCategories(page int, blogID uint64) (ret [
 
 func (srv *categoryService) GetCategories(size int, blogID uint64) (ret []*model.Category) {
 	if err := db.Where("`blog_id` = ?", blogID).Order("`number` asc").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get categories failed: " + err.Error())
+		logger.Errorf("get categories failed: %s", err)
 	}
 
 	return
@@ -220,6 +277,7 @@ func (srv *categoryService) RemoveCategory(id, blogID uint64) error {
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(blogID)
 
 	return nil
 }
@@ -233,11 +291,17 @@ func normalizeCategoryPath(category *model.Category) error {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
+	if util.IsReservedPath(path) {
+		return ErrReservedPath
+	}
 
 	count := 0
 	if db.Model(&model.Category{}).Where("`path` = ? AND `id` != ? AND `blog_id` = ?", path, category.ID, category.BlogID).Count(&count); 0 < count {
 		return errors.New("path is reduplicated")
 	}
+	if db.Model(&model.Article{}).Where("`path` = ? AND `blog_id` = ?", path, category.BlogID).Count(&count); 0 < count {
+		return errors.New("path is used by an article")
+	}
 
 	category.Path = path
 
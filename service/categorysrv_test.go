@@ -39,6 +39,11 @@ func TestAddCategory(t *testing.T) {
 
 		return
 	}
+
+	reserved := &model.Category{Title: "Tags", Path: "/tags/pipe", Tags: "tag1", BlogID: 1}
+	if err := Category.AddCategory(reserved); ErrReservedPath != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrReservedPath, err)
+	}
 }
 
 func TestConsoleGetCategories(t *testing.T) {
@@ -63,6 +68,28 @@ func TestGetGetCategories(t *testing.T) {
 	}
 }
 
+func TestGetArticleCategory(t *testing.T) {
+	category := Category.ConsoleGetCategory(1)
+	category.Commentable = model.CategoryCommentableOff
+	category.Sign = "分类签名"
+	if err := Category.UpdateCategory(category); nil != err {
+		t.Errorf("update category failed: " + err.Error())
+
+		return
+	}
+
+	category = Category.GetArticleCategory("tag0,tag2", 1)
+	if nil == category || "分类签名" != category.Sign {
+		t.Errorf("expected is [%s], actual is [%+v]", "分类签名", category)
+	}
+	if Category.GetArticleCommentable("tag2", 1) {
+		t.Errorf("expected is [%v], actual is [%v]", false, true)
+	}
+	if nil != Category.GetArticleCategory("tag0", 1) {
+		t.Errorf("category should be nil")
+	}
+}
+
 func TestRemoveCategory(t *testing.T) {
 	if err := Category.RemoveCategory(1, 1); nil != err {
 		t.Errorf("remove category failed: " + err.Error())
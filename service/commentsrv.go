@@ -17,10 +17,13 @@
 package service
 
 import (
+	"strconv"
 	"sync"
 
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
 )
 
 // Comment service.
@@ -57,7 +60,7 @@ func (srv *commentService) UpdatePushedAt(comment *model.Comment) error {
 }
 
 func (srv *commentService) GetUnpushedComments() (ret []*model.Comment) {
-	if err := db.Where("`pushed_at` <= ?", model.ZeroPushTime).Find(&ret).Error; nil != err {
+	if err := db.Where("`pushed_at` <= ? AND `status` = ?", model.ZeroPushTime, model.CommentStatusOK).Find(&ret).Error; nil != err {
 		return
 	}
 
@@ -75,7 +78,7 @@ func (srv *commentService) GetComment(commentID uint64) *model.Comment {
 
 func (srv *commentService) GetCommentPage(articleID, commentID uint64, blogID uint64) int {
 	count := 0
-	if err := db.Model(&model.Comment{}).Where("`article_id` = ? AND `id` < ? AND `blog_id` = ?", articleID, commentID, blogID).
+	if err := db.Model(&model.Comment{}).Where("`article_id` = ? AND `id` < ? AND `blog_id` = ? AND `status` = ?", articleID, commentID, blogID, model.CommentStatusOK).
 		Count(&count).Error; nil != err {
 		return 1
 	}
@@ -85,27 +88,86 @@ func (srv *commentService) GetCommentPage(articleID, commentID uint64, blogID ui
 
 func (srv *commentService) GetRepliesCount(parentCommentID uint64, blogID uint64) int {
 	ret := 0
-	if err := db.Model(&model.Comment{}).Where("`parent_comment_id` = ? AND `blog_id` = ?", parentCommentID, blogID).Count(&ret).Error; nil != err {
-		logger.Errorf("count comment [id=%d]'s replies failed: "+err.Error(), parentCommentID)
+	if err := db.Model(&model.Comment{}).Where("`parent_comment_id` = ? AND `blog_id` = ? AND `status` = ?", parentCommentID, blogID, model.CommentStatusOK).Count(&ret).Error; nil != err {
+		logger.Errorf("count comment [id=%d]'s replies failed: %s", parentCommentID, err)
 	}
 
 	return ret
 }
 
 func (srv *commentService) GetReplies(parentCommentID uint64, blogID uint64) (ret []*model.Comment) {
-	if err := db.Where("`parent_comment_id` = ? AND `blog_id` = ?", parentCommentID, blogID).Find(&ret).Error; nil != err {
-		logger.Errorf("get comment [id=%d]'s replies failed: "+err.Error(), parentCommentID)
+	if err := db.Where("`parent_comment_id` = ? AND `blog_id` = ? AND `status` = ?", parentCommentID, blogID, model.CommentStatusOK).Find(&ret).Error; nil != err {
+		logger.Errorf("get comment [id=%d]'s replies failed: %s", parentCommentID, err)
 	}
 
 	return
 }
 
+// GetApprovedCommentCount gets the count of published comments of the specified author in the specified blog.
+func (srv *commentService) GetApprovedCommentCount(authorID, blogID uint64) int {
+	ret := 0
+	if err := db.Model(&model.Comment{}).Where("`author_id` = ? AND `blog_id` = ? AND `status` = ?", authorID, blogID, model.CommentStatusOK).
+		Count(&ret).Error; nil != err {
+		logger.Errorf("count approved comments of author [%d] failed: %s", authorID, err)
+	}
+
+	return ret
+}
+
+// IsTrustedCommenter checks whether the specified author has enough approved comments in the specified blog to bypass
+// the moderation queue. The threshold is configured by the blog, 0 disables it.
+func (srv *commentService) IsTrustedCommenter(authorID, blogID uint64) bool {
+	thresholdSetting := Setting.GetSetting(model.SettingCategorySpam, model.SettingNameSpamTrustedCount, blogID)
+	if nil == thresholdSetting {
+		return false
+	}
+	threshold, err := strconv.Atoi(thresholdSetting.Value)
+	if nil != err || 1 > threshold {
+		return false
+	}
+
+	return threshold <= srv.GetApprovedCommentCount(authorID, blogID)
+}
+
+// ModerateComment sets the status of the specified new comment. Comments of trusted commenters are published directly,
+// the others are checked by the spam checkers of the blog. Federated comments can't be verified by a captcha, so they
+// are held for moderation if the blog has no spam checker configured.
+func (srv *commentService) ModerateComment(comment *model.Comment, ctx *SpamContext) {
+	comment.Status = model.CommentStatusOK
+	federated := model.SyncCommentAuthorID == comment.AuthorID
+	if !federated && srv.IsTrustedCommenter(comment.AuthorID, comment.BlogID) {
+		return
+	}
+
+	if federated && !Spam.HasCheckers(comment.BlogID) {
+		comment.Status = model.CommentStatusSpam
+
+		return
+	}
+	if Spam.IsSpam(comment, ctx) {
+		comment.Status = model.CommentStatusSpam
+	}
+}
+
 func (srv *commentService) ConsoleGetComments(keyword string, page int, blogID uint64) (ret []*model.Comment, pagination *util.Pagination) {
+	return srv.consoleGetComments(keyword, -1, page, blogID)
+}
+
+// ConsoleGetSpamComments gets the comments flagged as spam which are waiting for moderation.
+func (srv *commentService) ConsoleGetSpamComments(keyword string, page int, blogID uint64) (ret []*model.Comment, pagination *util.Pagination) {
+	return srv.consoleGetComments(keyword, model.CommentStatusSpam, page, blogID)
+}
+
+func (srv *commentService) consoleGetComments(keyword string, status, page int, blogID uint64) (ret []*model.Comment, pagination *util.Pagination) {
 	offset := (page - 1) * adminConsoleCommentListPageSize
 	count := 0
 
 	where := "`blog_id` = ?"
 	whereArgs := []interface{}{blogID}
+	if 0 <= status {
+		where += " AND `status` = ?"
+		whereArgs = append(whereArgs, status)
+	}
 	if "" != keyword {
 		where += " AND `content` LIKE ?"
 		whereArgs = append(whereArgs, "%"+keyword+"%")
@@ -114,7 +176,7 @@ func (srv *commentService) ConsoleGetComments(keyword string, page int, blogID u
 	if err := db.Model(&model.Comment{}).
 		Where(where, whereArgs...).Order("`created_at` DESC").
 		Count(&count).Offset(offset).Limit(adminConsoleCommentListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get comments failed: " + err.Error())
+		logger.Errorf("get comments failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, adminConsoleCommentListPageSize, adminConsoleCommentListWindowSize, count)
@@ -124,21 +186,31 @@ func (srv *commentService) ConsoleGetComments(keyword string, page int, blogID u
 
 func (srv *commentService) GetRecentComments(size int, blogID uint64) (ret []*model.Comment) {
 	if err := db.Model(&model.Comment{}).Select("`id`, `created_at`, `content`, `author_id`, `article_id`, `author_name`, `author_avatar_url`, `author_url`").
-		Where("`blog_id` = ?", blogID).
+		Where("`blog_id` = ? AND `status` = ?", blogID, model.CommentStatusOK).
 		Order("`created_at` DESC, `id` DESC").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get recent comments failed: " + err.Error())
+		logger.Errorf("get recent comments failed: %s", err)
 	}
 
 	return
 }
 
+func (srv *commentService) GetArticleLastComment(articleID uint64, blogID uint64) *model.Comment {
+	ret := &model.Comment{}
+	if err := db.Select("`id`, `created_at`").Where("`article_id` = ? AND `blog_id` = ? AND `status` = ?", articleID, blogID, model.CommentStatusOK).
+		Order("`created_at` DESC, `id` DESC").First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
 func (srv *commentService) GetArticleComments(articleID uint64, page int, blogID uint64) (ret []*model.Comment, pagination *util.Pagination) {
 	offset := (page - 1) * themeCommentListPageSize
 	count := 0
 	if err := db.Model(&model.Comment{}).Order("`id` ASC").
-		Where("`article_id` = ? AND `blog_id` = ?", articleID, blogID).
+		Where("`article_id` = ? AND `blog_id` = ? AND `status` = ?", articleID, blogID, model.CommentStatusOK).
 		Count(&count).Offset(offset).Limit(themeCommentListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get comments failed: " + err.Error())
+		logger.Errorf("get comments failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, themeCommentListPageSize, themeCommentListWindowSize, count)
@@ -158,19 +230,106 @@ func (srv *commentService) AddComment(comment *model.Comment) error {
 
 		return err
 	}
+	if model.CommentStatusOK != comment.Status {
+		// comments waiting for moderation are not counted until approved
+		tx.Commit()
+
+		return nil
+	}
+	if err := incCommentCountWithoutTx(tx, comment); nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	cache.Page.Purge(comment.BlogID)
+
+	return nil
+}
+
+// ApproveComment publishes the specified comment which is waiting for moderation.
+func (srv *commentService) ApproveComment(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	comment := &model.Comment{}
+
+	tx := db.Begin()
+	if err := tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(comment).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if model.CommentStatusOK == comment.Status {
+		tx.Rollback()
+
+		return nil
+	}
+	if err := tx.Model(comment).Update("status", model.CommentStatusOK).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if err := incCommentCountWithoutTx(tx, comment); nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	cache.Page.Purge(blogID)
+
+	return nil
+}
+
+func incCommentCountWithoutTx(tx *gorm.DB, comment *model.Comment) error {
 	article := &model.Article{}
 	if err := tx.First(article, comment.ArticleID).Error; nil != err {
+		return err
+	}
+	if err := tx.Model(article).Update("comment_count", article.CommentCount+1).Error; nil != err {
+		return err
+	}
+
+	return Statistic.IncCommentCountWithoutTx(tx, comment.BlogID)
+}
+
+// HideComment hides the specified published comment by marking it as spam, it could be approved again later.
+func (srv *commentService) HideComment(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	comment := &model.Comment{}
+
+	tx := db.Begin()
+	if err := tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(comment).Error; nil != err {
 		tx.Rollback()
 
 		return err
 	}
-	if err := tx.Model(article).Update("comment_count", article.CommentCount+1).Error; nil != err {
+	if model.CommentStatusOK != comment.Status {
+		tx.Rollback()
+
+		return nil
+	}
+	if err := tx.Model(comment).Update("status", model.CommentStatusSpam).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	article := &model.Article{}
+	if err := tx.First(article, comment.ArticleID).Error; nil != err {
 		tx.Rollback()
 
 		return err
 	}
-	Statistic.IncCommentCountWithoutTx(tx, comment.BlogID)
+	if err := tx.Model(article).Update("comment_count", article.CommentCount-1).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	Statistic.DecCommentCountWithoutTx(tx, comment.BlogID)
 	tx.Commit()
+	cache.Page.Purge(blogID)
 
 	return nil
 }
@@ -192,6 +351,11 @@ func (srv *commentService) RemoveComment(id, blogID uint64) error {
 
 		return err
 	}
+	if model.CommentStatusOK != comment.Status {
+		tx.Commit()
+
+		return nil
+	}
 	article := &model.Article{}
 	if err := tx.First(article, comment.ArticleID).Error; nil != err {
 		tx.Rollback()
@@ -205,6 +369,7 @@ func (srv *commentService) RemoveComment(id, blogID uint64) error {
 	}
 	Statistic.DecCommentCountWithoutTx(tx, comment.BlogID)
 	tx.Commit()
+	cache.Page.Purge(blogID)
 
 	return nil
 }
@@ -17,6 +17,7 @@
 package service
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/b3log/pipe/model"
@@ -80,3 +81,96 @@ func TestRemoveComment(t *testing.T) {
 		t.Error("remove comment failed")
 	}
 }
+
+func TestApproveComment(t *testing.T) {
+	articles, _ := Article.GetArticles("", 1, 1)
+	articleID := articles[0].ID
+	commentCount := Article.ConsoleGetArticle(articleID).CommentCount
+	comment := &model.Comment{
+		ArticleID: articleID,
+		AuthorID:  1,
+		Content:   "spam",
+		Status:    model.CommentStatusSpam,
+		BlogID:    1,
+	}
+	if err := Comment.AddComment(comment); nil != err {
+		t.Errorf("add comment failed: " + err.Error())
+
+		return
+	}
+	if commentCount != Article.ConsoleGetArticle(articleID).CommentCount {
+		t.Error("spam comment should not be counted")
+	}
+	comments, _ := Comment.ConsoleGetSpamComments("", 1, 1)
+	if 1 != len(comments) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(comments))
+
+		return
+	}
+
+	if err := Comment.ApproveComment(comment.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if commentCount+1 != Article.ConsoleGetArticle(articleID).CommentCount {
+		t.Error("approved comment should be counted")
+	}
+	comments, _ = Comment.ConsoleGetSpamComments("", 1, 1)
+	if 0 != len(comments) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(comments))
+	}
+
+	if err := Comment.RemoveComment(comment.ID, 1); nil != err {
+		t.Error(err)
+	}
+}
+
+func TestIsTrustedCommenter(t *testing.T) {
+	if Comment.IsTrustedCommenter(1, 1) {
+		t.Error("trusted commenters should be disabled by default")
+	}
+
+	articles, _ := Article.GetArticles("", 1, 1)
+	comment := &model.Comment{
+		ArticleID: articles[0].ID,
+		AuthorID:  1,
+		Content:   "karma",
+		BlogID:    1,
+	}
+	if err := Comment.AddComment(comment); nil != err {
+		t.Errorf("add comment failed: " + err.Error())
+
+		return
+	}
+	defer Comment.RemoveComment(comment.ID, 1)
+	defer removeSettings(model.SettingCategorySpam, 1)
+
+	count := Comment.GetApprovedCommentCount(1, 1)
+	for threshold, expected := range map[int]bool{count: true, count + 1: false} {
+		if err := Setting.UpdateSettings(model.SettingCategorySpam, []*model.Setting{
+			{Name: model.SettingNameSpamTrustedCount, Value: strconv.Itoa(threshold)},
+		}, 1); nil != err {
+			t.Error(err)
+
+			return
+		}
+		if expected != Comment.IsTrustedCommenter(1, 1) {
+			t.Errorf("expected is [%v], actual is [%v] with threshold [%d]", expected, !expected, threshold)
+		}
+	}
+}
+
+func TestModerateComment(t *testing.T) {
+	comment := &model.Comment{AuthorID: 1, BlogID: 1, Content: "local"}
+	Comment.ModerateComment(comment, &SpamContext{})
+	if model.CommentStatusOK != comment.Status {
+		t.Errorf("expected is [%d], actual is [%d]", model.CommentStatusOK, comment.Status)
+	}
+
+	comment = &model.Comment{AuthorID: model.SyncCommentAuthorID, BlogID: 1, Content: "federated"}
+	Comment.ModerateComment(comment, &SpamContext{})
+	if model.CommentStatusSpam != comment.Status {
+		t.Errorf("expected is [%d], actual is [%d]", model.CommentStatusSpam, comment.Status)
+	}
+}
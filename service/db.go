@@ -17,50 +17,86 @@
 package service
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
 	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/service/migrations"
 	"github.com/jinzhu/gorm"
-	_ "github.com/jinzhu/gorm/dialects/mysql"  // mysql
-	_ "github.com/jinzhu/gorm/dialects/sqlite" // sqlite
+	_ "github.com/jinzhu/gorm/dialects/mysql"    // mysql
+	_ "github.com/jinzhu/gorm/dialects/postgres" // postgres
+	_ "github.com/jinzhu/gorm/dialects/sqlite"   // sqlite
+	"github.com/lib/pq"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 var db *gorm.DB
-var useSQLite bool
+var database string
+
+// postgresDriverName is the name of the PostgreSQL driver which quotes identifiers compatibly.
+const postgresDriverName = "pipe-postgres"
+
+func init() {
+	sql.Register(postgresDriverName, &postgresDriver{})
+}
+
+// postgresDriver wraps the PostgreSQL driver to convert MySQL style quoted identifiers (`name`) used in queries to
+// standard SQL quoted identifiers ("name").
+type postgresDriver struct {
+	pq.Driver
+}
+
+func (d *postgresDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if nil != err {
+		return nil, err
+	}
+
+	return &postgresConn{conn}, nil
+}
+
+type postgresConn struct {
+	driver.Conn
+}
+
+func (conn *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return conn.Conn.Prepare(postgresQuery(query))
+}
+
+func postgresQuery(query string) string {
+	return strings.Replace(query, "`", "\"", -1)
+}
 
 // ConnectDB connects to the database.
 func ConnectDB() {
 	var err error
-	useSQLite = false
 	if "" != model.Conf.SQLite {
+		if err = os.MkdirAll(filepath.Dir(model.Conf.SQLite), 0755); nil != err {
+			logger.Fatalf("makes database directory failed: %s", err)
+		}
 		db, err = gorm.Open("sqlite3", model.Conf.SQLite)
-		useSQLite = true
+		database = "SQLite"
 	} else if "" != model.Conf.MySQL {
 		db, err = gorm.Open("mysql", model.Conf.MySQL)
+		database = "MySQL"
+	} else if "" != model.Conf.Postgres {
+		db, err = gorm.Open("postgres", postgresDriverName, model.Conf.Postgres)
+		database = "PostgreSQL"
 	} else {
 		logger.Fatal("please specify database")
 	}
 	if nil != err {
-		logger.Fatalf("opens database failed: " + err.Error())
-	}
-	if useSQLite {
-		logger.Debug("used [SQLite] as underlying database")
-	} else {
-		logger.Debug("used [MySQL] as underlying database")
-	}
-
-	if err = db.AutoMigrate(model.Models...).Error; nil != err {
-		logger.Fatal("auto migrate tables failed: " + err.Error())
-	}
-
-	if err = db.Model(&model.Article{}).AddIndex("idx_b3_pipe_articles_created_at", "created_at").Error; nil != err {
-		logger.Fatal("adds index failed: " + err.Error())
+		logger.Fatalf("opens database failed: %s", err)
 	}
+	logger.Debug("used [" + database + "] as underlying database")
 
 	db.DB().SetMaxIdleConns(10)
 	db.DB().SetMaxOpenConns(50)
@@ -68,18 +104,36 @@ func ConnectDB() {
 	db.LogMode(model.Conf.ShowSQL)
 }
 
+// Migrate applies pending database migrations.
+func Migrate() {
+	if err := migrations.Up(db); nil != err {
+		logger.Fatal("migrate database failed: " + err.Error())
+	}
+}
+
+// RevertMigrations reverts the applied database migrations newer than the specified version.
+func RevertMigrations(version uint64) error {
+	return migrations.Down(db, version)
+}
+
+// MigrationStatus returns the latest applied migration version and the pending migrations.
+func MigrationStatus() (version uint64, pending []*migrations.Migration, err error) {
+	if version, err = migrations.Version(db); nil != err {
+		return
+	}
+	pending, err = migrations.Pending(db)
+
+	return
+}
+
 // DisconnectDB disconnects from the database.
 func DisconnectDB() {
 	if err := db.Close(); nil != err {
-		logger.Errorf("Disconnect from database failed: " + err.Error())
+		logger.Errorf("Disconnect from database failed: %s", err)
 	}
 }
 
 // Database returns the underlying database name.
 func Database() string {
-	if useSQLite {
-		return "SQLite"
-	}
-
-	return "MySQL"
+	return database
 }
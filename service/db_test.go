@@ -0,0 +1,44 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+)
+
+func TestPostgresQuery(t *testing.T) {
+	query := postgresQuery("SELECT * FROM `b3_pipe_articles` WHERE `blog_id` = $1 ORDER BY `created_at` DESC")
+	expected := `SELECT * FROM "b3_pipe_articles" WHERE "blog_id" = $1 ORDER BY "created_at" DESC`
+	if expected != query {
+		t.Errorf("expected is [%s], actual is [%s]", expected, query)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	version, pending, err := MigrationStatus()
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if 1 > version {
+		t.Errorf("expected is [>= %d], actual is [%d]", 1, version)
+	}
+	if 0 != len(pending) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(pending))
+	}
+}
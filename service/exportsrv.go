@@ -17,10 +17,18 @@
 package service
 
 import (
+	"crypto/md5"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/b3log/pipe/model"
+	"github.com/parnurzeal/gorequest"
 	"gopkg.in/yaml.v2"
 )
 
@@ -30,10 +38,19 @@ var Export = &exportService{}
 type exportService struct {
 }
 
+// MarkdownImage represents an image attached to an exported markdown file.
+type MarkdownImage struct {
+	Name string
+	Data []byte
+}
+
+// markdownImage matches image references like ![alt](url) and <img src="url">.
+var markdownImage = regexp.MustCompile(`!\[[^\]]*\]\((https?://[^)\s]+)|<img[^>]+src=["'](https?://[^"']+)["']`)
+
 func (srv *exportService) ExportMarkdowns(blogID uint64) (ret []*MarkdownFile) {
 	var articles []*model.Article
 	if err := db.Where("`blog_id` = ?", blogID).Find(&articles).Error; nil != err {
-		logger.Errorf("export markdowns failed: " + err.Error())
+		logger.Errorf("export markdowns failed: %s", err)
 
 		return
 	}
@@ -41,6 +58,7 @@ func (srv *exportService) ExportMarkdowns(blogID uint64) (ret []*MarkdownFile) {
 		return
 	}
 
+	names := map[string]bool{}
 	for _, article := range articles {
 		front := struct {
 			Title     string   `yaml:"title"`
@@ -48,22 +66,30 @@ func (srv *exportService) ExportMarkdowns(blogID uint64) (ret []*MarkdownFile) {
 			Updated   string   `yaml:"updated"`
 			Tags      []string `yaml:"tags"`
 			Permalink string   `yaml:"permalink"`
+			Draft     bool     `yaml:"draft,omitempty"`
 		}{
 			article.Title,
 			article.CreatedAt.Format("2006-01-02 15:04:05"),
 			article.UpdatedAt.Format("2006-01-02 15:04:05"),
 			strings.Split(article.Tags, ","),
 			article.Path,
+			model.ArticleStatusDraft == article.Status,
 		}
 		frontData, err := yaml.Marshal(front)
 		if nil != err {
-			logger.Errorf("marshal front matter failed: " + err.Error())
+			logger.Errorf("marshal front matter failed: %s", err)
 
 			continue
 		}
 
+		name := sanitizeFilename(article.Title)
+		if names[name] {
+			name += "_" + strconv.FormatUint(article.ID, 10)
+		}
+		names[name] = true
+
 		mdFile := &MarkdownFile{
-			Name:    sanitizeFilename(article.Title),
+			Name:    name,
 			Content: string(frontData) + "---\n" + article.Content,
 		}
 
@@ -73,6 +99,58 @@ func (srv *exportService) ExportMarkdowns(blogID uint64) (ret []*MarkdownFile) {
 	return ret
 }
 
+// ExportImages downloads images referenced by the specified markdown files and rewrites the references to the
+// relative path "images/{name}".
+func (srv *exportService) ExportImages(mdFiles []*MarkdownFile) (ret []*MarkdownImage) {
+	downloaded := map[string]string{}
+	for _, mdFile := range mdFiles {
+		mdFile.Content = markdownImage.ReplaceAllStringFunc(mdFile.Content, func(ref string) string {
+			groups := markdownImage.FindStringSubmatch(ref)
+			url := groups[1]
+			if "" == url {
+				url = groups[2]
+			}
+
+			name, ok := downloaded[url]
+			if !ok {
+				data := downloadImage(url)
+				if nil == data {
+					return ref
+				}
+
+				name = fmt.Sprintf("%x", md5.Sum([]byte(url)))
+				if ext := path.Ext(strings.Split(url, "?")[0]); "" != ext && 6 > len(ext) {
+					name += ext
+				}
+				downloaded[url] = name
+				ret = append(ret, &MarkdownImage{Name: name, Data: data})
+			}
+
+			return strings.Replace(ref, url, "images/"+name, 1)
+		})
+	}
+
+	return ret
+}
+
+func downloadImage(url string) []byte {
+	response, data, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		Get(url).Set("user-agent", model.UserAgent).Timeout(30 * time.Second).EndBytes()
+	if nil != errs {
+		logger.Warnf("download image [%s] failed: %s", url, errs[0])
+
+		return nil
+	}
+	if http.StatusOK != response.StatusCode || !strings.HasPrefix(response.Header.Get("Content-Type"), "image/") {
+		logger.Warnf("download image [%s] failed: status code [%d], content type [%s]", url, response.StatusCode,
+			response.Header.Get("Content-Type"))
+
+		return nil
+	}
+
+	return data
+}
+
 func sanitizeFilename(unsanitized string) string {
 	unsanitized = regexp.MustCompile("[\\?\\\\/:|<>\\*]").ReplaceAllString(unsanitized, " ") // filter out ? \ / : | < > *
 
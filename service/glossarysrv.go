@@ -0,0 +1,184 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Glossary service.
+var Glossary = &glossaryService{
+	mutex: &sync.Mutex{},
+}
+
+type glossaryService struct {
+	mutex *sync.Mutex
+}
+
+// Glossary pagination arguments of admin console.
+const (
+	adminConsoleGlossaryListPageSize   = 15
+	adminConsoleGlossaryListWindowSize = 20
+)
+
+func (srv *glossaryService) AddGlossary(glossary *model.Glossary) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if err := normalizeGlossary(glossary); nil != err {
+		return err
+	}
+
+	tx := db.Begin()
+	if err := tx.Create(glossary).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	cache.Page.Purge(glossary.BlogID)
+
+	return nil
+}
+
+func (srv *glossaryService) RemoveGlossary(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	glossary := &model.Glossary{}
+
+	tx := db.Begin()
+	if err := tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(glossary).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if err := tx.Delete(glossary).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	cache.Page.Purge(blogID)
+
+	return nil
+}
+
+func (srv *glossaryService) UpdateGlossary(glossary *model.Glossary) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	count := 0
+	if db.Model(&model.Glossary{}).Where("`id` = ? AND `blog_id` = ?", glossary.ID, glossary.BlogID).
+		Count(&count); 1 > count {
+		return fmt.Errorf("not found glossary [id=%d] to update", glossary.ID)
+	}
+	if err := normalizeGlossary(glossary); nil != err {
+		return err
+	}
+
+	tx := db.Begin()
+	if err := tx.Model(glossary).Updates(map[string]interface{}{
+		"Term":       glossary.Term,
+		"Definition": glossary.Definition,
+		"URL":        glossary.URL}).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	cache.Page.Purge(glossary.BlogID)
+
+	return nil
+}
+
+func (srv *glossaryService) ConsoleGetGlossaries(page int, blogID uint64) (ret []*model.Glossary, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleGlossaryListPageSize
+	count := 0
+	if err := db.Model(&model.Glossary{}).Order("`term` ASC").
+		Where("`blog_id` = ?", blogID).
+		Count(&count).Offset(offset).Limit(adminConsoleGlossaryListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get glossaries failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleGlossaryListPageSize, adminConsoleGlossaryListWindowSize, count)
+
+	return
+}
+
+func (srv *glossaryService) ConsoleGetGlossary(id uint64) *model.Glossary {
+	ret := &model.Glossary{}
+	if err := db.First(ret, id).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+func (srv *glossaryService) GetGlossaries(blogID uint64) (ret []*model.Glossary) {
+	if err := db.Where("`blog_id` = ?", blogID).Find(&ret).Error; nil != err {
+		logger.Errorf("get glossaries failed: %s", err)
+	}
+
+	return
+}
+
+// LinkGlossaries wraps the first occurrence of each glossary term of the specified blog in the specified content HTML.
+func (srv *glossaryService) LinkGlossaries(contentHTML string, blogID uint64) string {
+	glossaries := srv.GetGlossaries(blogID)
+	if 1 > len(glossaries) {
+		return contentHTML
+	}
+
+	var terms []*util.GlossaryTerm
+	for _, glossary := range glossaries {
+		terms = append(terms, &util.GlossaryTerm{
+			Term:       glossary.Term,
+			Definition: glossary.Definition,
+			URL:        glossary.URL,
+		})
+	}
+
+	return util.LinkGlossary(contentHTML, terms)
+}
+
+func normalizeGlossary(glossary *model.Glossary) error {
+	glossary.Term = strings.TrimSpace(glossary.Term)
+	if "" == glossary.Term {
+		return errors.New("term can not be empty")
+	}
+	glossary.Definition = strings.TrimSpace(glossary.Definition)
+	glossary.URL = strings.TrimSpace(glossary.URL)
+	if "" != glossary.URL && !util.IsGlossaryURL(glossary.URL) {
+		return errors.New("URL [" + glossary.URL + "] is invalid, only HTTP(S) and relative URLs are allowed")
+	}
+
+	count := 0
+	if db.Model(&model.Glossary{}).Where("`term` = ? AND `id` != ? AND `blog_id` = ?", glossary.Term, glossary.ID, glossary.BlogID).
+		Count(&count); 0 < count {
+		return errors.New("term [" + glossary.Term + "] is reduplicated")
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
+)
+
+// Image service, records uploaded images and serves their thumbnails and resized variants.
+var Image = &imageService{
+	mutex:    &sync.Mutex{},
+	variants: map[string]string{},
+}
+
+type imageService struct {
+	mutex    *sync.Mutex
+	variants map[string]string // media key -> media URL of resized variants
+}
+
+// Image resizing limits.
+const (
+	// imageResizeStep is the step of widths and heights of resized variants which are not thumbnails, it limits the
+	// count of variants stored for each image.
+	imageResizeStep = 64
+	// imageResizeMax is the max width and height of resized variants.
+	imageResizeMax = 2048
+)
+
+// ErrImageNotFound is returned if there is no image of the specified hash.
+var ErrImageNotFound = errors.New("image not found")
+
+// AddImage records the specified uploaded image stored with the specified media key and URL, then generates the
+// configured thumbnails in background. It does nothing if the image has been recorded.
+func (srv *imageService) AddImage(hash, key, url, mimeType string, data []byte, blogID uint64) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if nil != err {
+		return err
+	}
+
+	count := 0
+	if err := db.Model(&model.Image{}).Where("`hash` = ?", hash).Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return nil
+	}
+
+	img := &model.Image{
+		Hash:     hash,
+		Key:      key,
+		URL:      url,
+		MIMEType: mimeType,
+		Width:    config.Width,
+		Height:   config.Height,
+		BlogID:   blogID,
+	}
+	if err := db.Create(img).Error; nil != err {
+		return err
+	}
+
+	go srv.generateVariants(img, data)
+
+	return nil
+}
+
+// ReplaceImage replaces the content of the image stored with the specified media key by the specified data, drops
+// its resized and transcoded variants then generates the configured thumbnails in background. The hash of the image
+// is kept so that /images/:hash keeps serving it. It does nothing if there is no image stored with the key.
+func (srv *imageService) ReplaceImage(key string, data []byte) error {
+	img := &model.Image{}
+	if err := db.Where("`key` = ?", key).First(img).Error; nil != err {
+		if gorm.ErrRecordNotFound == err {
+			return nil
+		}
+
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if nil != err {
+		return err
+	}
+	img.Width = config.Width
+	img.Height = config.Height
+	img.WebPURL = ""
+	img.AVIFURL = ""
+	if err := db.Model(img).Updates(map[string]interface{}{"Width": img.Width, "Height": img.Height,
+		"WebPURL": "", "AVIFURL": ""}).Error; nil != err {
+		return err
+	}
+
+	srv.mutex.Lock()
+	for variantKey := range srv.variants {
+		if strings.HasPrefix(variantKey, "images/"+img.Hash+"/") {
+			delete(srv.variants, variantKey)
+		}
+	}
+	srv.mutex.Unlock()
+
+	go srv.generateVariants(img, data)
+
+	return nil
+}
+
+// generateVariants generates the configured thumbnails and the transcoded variants of the specified image.
+func (srv *imageService) generateVariants(img *model.Image, data []byte) {
+	for _, width := range model.Conf.ThumbnailSizes {
+		if _, err := srv.resize(img, data, width, 0); nil != err {
+			logger.Errorf("generate thumbnail [%d] of image [%s] failed: %s", width, img.Hash, err)
+		}
+	}
+	srv.transcode(img, data)
+}
+
+// transcode transcodes the specified JPEG or PNG image into WebP and AVIF with the configured encoders, then records
+// URLs of the variants.
+func (srv *imageService) transcode(img *model.Image, data []byte) {
+	if "image/jpeg" != img.MIMEType && "image/png" != img.MIMEType {
+		return
+	}
+
+	encoders := map[string]string{util.ImageFormatWebP: model.Conf.WebPEncoder, util.ImageFormatAVIF: model.Conf.AVIFEncoder}
+	urls := map[string]string{}
+	for format, encoder := range encoders {
+		if "" == encoder {
+			continue
+		}
+
+		variant, err := util.TranscodeImage(encoder, format, data)
+		if nil != err {
+			logger.Errorf("transcode image [%s] into [%s] failed: %s", img.Hash, format, err)
+
+			continue
+		}
+		if len(variant) >= len(data) { // useless if not smaller
+			continue
+		}
+		url, err := Media.Put("images/"+img.Hash+"/original."+format, variant)
+		if nil != err {
+			logger.Errorf("store [%s] variant of image [%s] failed: %s", format, img.Hash, err)
+
+			continue
+		}
+		urls[format] = url
+	}
+	if 1 > len(urls) {
+		return
+	}
+
+	if err := db.Model(img).Updates(map[string]interface{}{"WebPURL": urls[util.ImageFormatWebP],
+		"AVIFURL": urls[util.ImageFormatAVIF]}).Error; nil != err {
+		logger.Errorf("update variants of image [%s] failed: %s", img.Hash, err)
+	}
+}
+
+// RewritePictures wraps <img> tags of uploaded images in the specified HTML with <picture> offering their WebP and
+// AVIF variants.
+func (srv *imageService) RewritePictures(content string) string {
+	srcs := util.ImageSources(content)
+	if 1 > len(srcs) {
+		return content
+	}
+
+	var images []*model.Image
+	if err := db.Where("`url` IN (?) AND (`webp_url` != '' OR `avif_url` != '')", srcs).Find(&images).Error; nil != err {
+		logger.Errorf("get image variants failed: %s", err)
+
+		return content
+	}
+	if 1 > len(images) {
+		return content
+	}
+
+	variants := map[string]map[string]string{}
+	for _, img := range images {
+		urls := map[string]string{}
+		if "" != img.WebPURL {
+			urls[util.ImageFormatWebP] = img.WebPURL
+		}
+		if "" != img.AVIFURL {
+			urls[util.ImageFormatAVIF] = img.AVIFURL
+		}
+		variants[img.URL] = urls
+	}
+
+	return util.RewritePictures(content, func(src string) map[string]string {
+		return variants[src]
+	})
+}
+
+// GetImage returns the image of the specified hash, returns nil if not found.
+func (srv *imageService) GetImage(hash string) *model.Image {
+	ret := &model.Image{}
+	if err := db.Where("`hash` = ?", hash).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// GetRandomImages returns at most n images uploaded to the specified blog randomly.
+func (srv *imageService) GetRandomImages(n int, blogID uint64) (ret []*model.Image) {
+	if 1 > n {
+		return
+	}
+
+	var images []*model.Image
+	if err := db.Where("`blog_id` = ?", blogID).Order("`id` DESC").Limit(256).Find(&images).Error; nil != err {
+		logger.Errorf("get images failed: %s", err)
+
+		return
+	}
+
+	if n > len(images) {
+		n = len(images)
+	}
+	for _, i := range gulu.Rand.Ints(0, len(images), n) {
+		ret = append(ret, images[i])
+	}
+
+	return
+}
+
+// GetURL returns the media URL of the variant of the specified image resized to the specified width and height,
+// renders and stores the variant if not exists. The original URL is returned if the width is not positive or not less
+// than the width of the image. Widths other than the configured thumbnail sizes and heights are rounded up to multiples
+// of 64.
+func (srv *imageService) GetURL(img *model.Image, width, height int) (string, error) {
+	if 1 > width || width >= img.Width {
+		return img.URL, nil
+	}
+	width = normalizeImageSize(width, true)
+	if 0 < height {
+		height = normalizeImageSize(height, false)
+	}
+	if width >= img.Width {
+		return img.URL, nil
+	}
+
+	srv.mutex.Lock()
+	url, ok := srv.variants[imageVariantKey(img, width, height)]
+	srv.mutex.Unlock()
+	if ok {
+		return url, nil
+	}
+
+	data, err := Media.Get(img.Key)
+	if nil != err {
+		return "", err
+	}
+
+	return srv.resize(img, data, width, height)
+}
+
+// resize renders the variant of the specified image data with the specified width and height, stores it in media
+// storage and returns its URL.
+func (srv *imageService) resize(img *model.Image, data []byte, width, height int) (string, error) {
+	if width >= img.Width {
+		return img.URL, nil
+	}
+
+	variant, _, err := util.ResizeImage(data, width, height)
+	if nil != err {
+		return "", err
+	}
+	key := imageVariantKey(img, width, height)
+	url, err := Media.Put(key, variant)
+	if nil != err {
+		return "", err
+	}
+
+	srv.mutex.Lock()
+	srv.variants[key] = url
+	srv.mutex.Unlock()
+
+	return url, nil
+}
+
+// imageVariantKey returns the media key of the variant of the specified image with the specified width and height.
+func imageVariantKey(img *model.Image, width, height int) string {
+	ext := ".jpg"
+	if "image/png" == img.MIMEType || "image/gif" == img.MIMEType {
+		ext = ".png"
+	}
+
+	return "images/" + img.Hash + "/" + strconv.Itoa(width) + "x" + strconv.Itoa(height) + ext
+}
+
+// normalizeImageSize rounds the specified size up to the nearest multiple of 64 unless it's a configured thumbnail
+// width, and limits it to 2048.
+func normalizeImageSize(size int, isWidth bool) int {
+	if isWidth {
+		for _, thumbnailSize := range model.Conf.ThumbnailSizes {
+			if size == thumbnailSize {
+				return size
+			}
+		}
+	}
+
+	if rem := size % imageResizeStep; 0 < rem {
+		size += imageResizeStep - rem
+	}
+	if imageResizeMax < size {
+		size = imageResizeMax
+	}
+
+	return size
+}
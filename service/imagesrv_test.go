@@ -0,0 +1,91 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestAddImage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 16, 8))); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	url := "http://localhost/media/articles/201901/0123456789abcdef.png"
+	if err := Image.AddImage("0123456789abcdef", "articles/201901/0123456789abcdef.png", url, "image/png", buf.Bytes(), 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	img := Image.GetImage("0123456789abcdef")
+	if nil == img || 16 != img.Width || 8 != img.Height {
+		t.Errorf("unexpected image [%+v]", img)
+
+		return
+	}
+	if ret, err := Image.GetURL(img, 800, 0); nil != err || url != ret {
+		t.Errorf("expected is [%s], actual is [%s, %v]", url, ret, err)
+	}
+	if images := Image.GetRandomImages(5, 1); 1 != len(images) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(images))
+	}
+}
+
+func TestNormalizeImageSize(t *testing.T) {
+	model.Conf.ThumbnailSizes = []int{320, 768}
+	cases := []struct {
+		size     int
+		isWidth  bool
+		expected int
+	}{
+		{320, true, 320},
+		{320, false, 320},
+		{300, true, 320},
+		{700, true, 704},
+		{768, false, 768},
+		{4000, true, 2048},
+	}
+	for _, c := range cases {
+		if actual := normalizeImageSize(c.size, c.isWidth); c.expected != actual {
+			t.Errorf("expected is [%d], actual is [%d]", c.expected, actual)
+		}
+	}
+}
+
+func TestRewritePictures(t *testing.T) {
+	img := &model.Image{Hash: "fedcba9876543210", URL: "http://localhost/media/a.jpg", MIMEType: "image/jpeg",
+		WebPURL: "http://localhost/media/a.webp", BlogID: 1}
+	if err := db.Create(img).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	content := `<p><img src="http://localhost/media/a.jpg" alt="a"/></p>`
+	expected := `<p><picture><source type="image/webp" srcset="http://localhost/media/a.webp"><img src="http://localhost/media/a.jpg" alt="a"/></picture></p>`
+	if ret := Image.RewritePictures(content); expected != ret {
+		t.Errorf("expected is [%s], actual is [%s]", expected, ret)
+	}
+}
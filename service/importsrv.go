@@ -17,7 +17,9 @@
 package service
 
 import (
+	"fmt"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -62,6 +64,12 @@ func (srv *importService) ImportMarkdowns(mdFiles []*MarkdownFile, authorID, blo
 	var articles importArticles
 	for _, mdFile := range mdFiles {
 		article := parseArticle(mdFile)
+		if nil == article {
+			failCnt++
+			fails = append(fails, mdFile.Name)
+
+			continue
+		}
 		article.AuthorID = authorID
 		article.BlogID = blogID
 
@@ -78,7 +86,7 @@ func (srv *importService) ImportMarkdowns(mdFiles []*MarkdownFile, authorID, blo
 		if err := Article.AddArticle(article); nil != err {
 			failCnt++
 			fails = append(fails, article.Title)
-			logger.Errorf("import article [" + article.Title + "] failed: " + err.Error())
+			logger.Errorf("import article [%s] failed: %s", article.Title, err)
 
 			continue
 		}
@@ -89,6 +97,7 @@ func (srv *importService) ImportMarkdowns(mdFiles []*MarkdownFile, authorID, blo
 	if 0 == succCnt && 0 == failCnt {
 		return
 	}
+	Notification.NotifyImportFinished(succCnt, failCnt, authorID, blogID)
 
 	logBuilder := "[" + strconv.Itoa(succCnt) + "] imported, [" + strconv.Itoa(failCnt) + "] failed"
 	if 0 < failCnt {
@@ -129,8 +138,8 @@ func parseArticle(mdFile *MarkdownFile) *model.Article {
 
 	ext := filepath.Ext(mdFile.Name)
 	title := strings.Split(mdFile.Name, ext)[0]
-	if t, ok := m["title"]; ok {
-		title = strings.TrimSpace(t.(string))
+	if t := strings.TrimSpace(frontMatterString(m["title"])); "" != t {
+		title = t
 	}
 	ret.Title = title
 
@@ -141,29 +150,68 @@ func parseArticle(mdFile *MarkdownFile) *model.Article {
 	}
 	ret.Content = content
 
-	permalink := ""
-	if p, ok := m["permalink"]; ok {
-		permalink = strings.TrimSpace(p.(string))
-	}
-	ret.Path = permalink
-
-	tags := parseTags(&m)
-	ret.Tags = tags
-	ret.CreatedAt = parseDate(&m)
+	ret.Path = parsePath(&m, mdFile.Name)
+	ret.Tags = parseTags(&m)
+	ret.CreatedAt = parseDate(&m, "date", mdFile.Name)
 	ret.UpdatedAt = ret.CreatedAt
+	if _, ok := m["updated"]; ok {
+		if updated := parseDate(&m, "updated", ""); updated.After(ret.CreatedAt) {
+			ret.UpdatedAt = updated
+		}
+	}
 	ret.PushedAt = ret.CreatedAt
 	ret.Commentable = true
+	if isDraft(&m, mdFile.Path) {
+		ret.Status = model.ArticleStatusDraft
+	}
 
 	return ret
 }
 
-func parseDate(m *map[string]interface{}) time.Time {
+// jekyllFilename matches Jekyll post filename like "2019-10-01-hello-world.md".
+var jekyllFilename = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+func parsePath(m *map[string]interface{}, filename string) string {
 	frontMatter := *m
-	date := frontMatter["date"]
-	if nil == date {
-		return time.Now()
+	if permalink := strings.TrimSpace(frontMatterString(frontMatter["permalink"])); "" != permalink {
+		return permalink
+	}
+
+	slug := strings.TrimSpace(frontMatterString(frontMatter["slug"]))
+	if "" == slug {
+		name := strings.TrimSuffix(filename, filepath.Ext(filename))
+		if groups := jekyllFilename.FindStringSubmatch(name); nil != groups {
+			slug = groups[2]
+		}
+	}
+	if "" == slug {
+		return ""
+	}
+
+	return "/" + strings.Trim(slug, "/")
+}
+
+func isDraft(m *map[string]interface{}, path string) bool {
+	frontMatter := *m
+	if draft, ok := frontMatter["draft"].(bool); ok && draft {
+		return true
+	}
+	if published, ok := frontMatter["published"].(bool); ok && !published {
+		return true
+	}
+
+	return strings.Contains(filepath.ToSlash(path), "/_drafts/")
+}
+
+func parseDate(m *map[string]interface{}, key, filename string) time.Time {
+	frontMatter := *m
+	dateStr := strings.TrimSpace(frontMatterString(frontMatter[key]))
+	if "" == dateStr {
+		name := strings.TrimSuffix(filename, filepath.Ext(filename))
+		if groups := jekyllFilename.FindStringSubmatch(name); nil != groups {
+			dateStr = groups[1]
+		}
 	}
-	dateStr := strings.TrimSpace(date.(string))
 	if "" == dateStr {
 		return time.Now()
 	}
@@ -178,6 +226,19 @@ func parseDate(m *map[string]interface{}) time.Time {
 	return ret
 }
 
+func frontMatterString(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value.(string)
+	case time.Time:
+		return value.(time.Time).Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
 func parseTags(m *map[string]interface{}) string {
 	frontMatter := *m
 	tags := frontMatter["tags"]
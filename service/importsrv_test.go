@@ -0,0 +1,61 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestParseArticle(t *testing.T) {
+	article := parseArticle(&MarkdownFile{
+		Name:    "hexo.md",
+		Path:    "/source/_posts/hexo.md",
+		Content: "---\ntitle: Hexo 文章\nslug: hexo-article\ndate: 2019-01-02 03:04:05\nupdated: 2019-02-02 03:04:05\ntags: [Hexo, Pipe]\n---\nHello Hexo",
+	})
+	if "Hexo 文章" != article.Title {
+		t.Errorf("expected is [%s], actual is [%s]", "Hexo 文章", article.Title)
+	}
+	if "/hexo-article" != article.Path {
+		t.Errorf("expected is [%s], actual is [%s]", "/hexo-article", article.Path)
+	}
+	if "Hexo,Pipe" != article.Tags {
+		t.Errorf("expected is [%s], actual is [%s]", "Hexo,Pipe", article.Tags)
+	}
+	if 2019 != article.CreatedAt.Year() || 2 != article.UpdatedAt.Month() {
+		t.Errorf("unexpected dates [%s, %s]", article.CreatedAt, article.UpdatedAt)
+	}
+	if model.ArticleStatusOK != article.Status {
+		t.Errorf("expected is [%d], actual is [%d]", model.ArticleStatusOK, article.Status)
+	}
+
+	article = parseArticle(&MarkdownFile{
+		Name:    "2019-03-04-jekyll-post.md",
+		Path:    "/site/_drafts/2019-03-04-jekyll-post.md",
+		Content: "---\ntitle: Jekyll\n---\nHello Jekyll",
+	})
+	if "/jekyll-post" != article.Path {
+		t.Errorf("expected is [%s], actual is [%s]", "/jekyll-post", article.Path)
+	}
+	if 3 != article.CreatedAt.Month() || 4 != article.CreatedAt.Day() {
+		t.Errorf("unexpected created at [%s]", article.CreatedAt)
+	}
+	if model.ArticleStatusDraft != article.Status {
+		t.Errorf("expected is [%d], actual is [%d]", model.ArticleStatusDraft, article.Status)
+	}
+}
@@ -40,9 +40,18 @@ type initService struct {
 
 // PlatformStatus represents platform status.
 type PlatformStatus struct {
-	Version string `json:"version"`
-	Locale  string `json:"locale"`
-	Inited  bool   `json:"inited"`
+	Version       string              `json:"version"`
+	Locale        string              `json:"locale"`
+	Inited        bool                `json:"inited"`
+	AuthMode      string              `json:"authMode"`
+	Registration  bool                `json:"registration"`
+	AuthProviders []*AuthProviderInfo `json:"authProviders"`
+}
+
+// AuthProviderInfo represents a login provider shown on the login page.
+type AuthProviderInfo struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
 }
 
 func (srv *initService) Inited() bool {
@@ -60,8 +69,17 @@ func (srv *initService) Inited() bool {
 
 func (srv *initService) Status() (platformStatus *PlatformStatus, err error) {
 	platformStatus = &PlatformStatus{
-		Version: model.Version,
-		Locale:  "zh_CN",
+		Version:      model.Version,
+		Locale:       "zh_CN",
+		AuthMode:     model.Conf.AuthMode,
+		Registration: model.Conf.Registration,
+	}
+	for _, provider := range model.Conf.AuthProviders {
+		title := provider.Title
+		if "" == title {
+			title = provider.Name
+		}
+		platformStatus.AuthProviders = append(platformStatus.AuthProviders, &AuthProviderInfo{Name: provider.Name, Title: title})
 	}
 
 	localeSetting := &model.Setting{}
@@ -126,6 +144,12 @@ func (srv *initService) initBlog(tx *gorm.DB, admin *model.User, blogID uint64)
 }
 
 func (srv *initService) InitBlog(blogAdmin *model.User) error {
+	return srv.initBlogWith(blogAdmin, nil)
+}
+
+// initBlogWith inits a blog for the specified admin, the specified function is called in the same transaction after
+// the blog is inited if it isn't nil.
+func (srv *initService) initBlogWith(blogAdmin *model.User, fn func(tx *gorm.DB) error) error {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
 
@@ -141,6 +165,13 @@ func (srv *initService) InitBlog(blogAdmin *model.User) error {
 
 		return err
 	}
+	if nil != fn {
+		if err := fn(tx); nil != err {
+			tx.Rollback()
+
+			return err
+		}
+	}
 	tx.Commit()
 
 	return nil
@@ -409,6 +440,57 @@ func initPreferenceSettings(tx *gorm.DB, blogID uint64) error {
 		BlogID:   blogID}).Error; nil != err {
 		return err
 	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferenceArticleListOrder,
+		Value:    strconv.Itoa(model.SettingPreferenceArticleListOrderDefault),
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferencePermalink,
+		Value:    strconv.Itoa(model.SettingPreferencePermalinkDefault),
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferenceArchiveGranularity,
+		Value:    strconv.Itoa(model.SettingPreferenceArchiveGranularityDefault),
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferenceRelatedArticleListSize,
+		Value:    strconv.Itoa(model.SettingPreferenceRelatedArticleListSizeDefault),
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferenceCodeHighlightStyle,
+		Value:    model.SettingPreferenceCodeHighlightStyleDefault,
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	if err := tx.Create(&model.Setting{
+		Category: model.SettingCategoryPreference,
+		Name:     model.SettingNamePreferenceEmbedProviders,
+		Value:    model.SettingPreferenceEmbedProvidersDefault,
+		BlogID:   blogID}).Error; nil != err {
+		return err
+	}
+	for name, value := range model.SettingPreferenceSwitchDefaults {
+		if err := tx.Create(&model.Setting{
+			Category: model.SettingCategoryPreference,
+			Name:     name,
+			Value:    strconv.Itoa(value),
+			BlogID:   blogID}).Error; nil != err {
+			return err
+		}
+	}
 	if err := tx.Create(&model.Setting{
 		Category: model.SettingCategoryPreference,
 		Name:     model.SettingNamePreferenceMostCommentArticleListSize,
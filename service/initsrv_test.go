@@ -43,13 +43,23 @@ func setup() {
 	}
 
 	model.Conf = &model.Configuration{}
-	model.Conf.SQLite = home + "/pipe.test.db"
+	if postgres := os.Getenv("PIPE_TEST_POSTGRES"); "" != postgres {
+		model.Conf.Postgres = postgres
 
-	if gulu.File.IsExist(model.Conf.SQLite) {
-		os.Remove(model.Conf.SQLite)
+		// starts from empty tables
+		ConnectDB()
+		db.DropTableIfExists(model.Models...)
+		DisconnectDB()
+	} else {
+		model.Conf.SQLite = home + "/pipe.test.db"
+
+		if gulu.File.IsExist(model.Conf.SQLite) {
+			os.Remove(model.Conf.SQLite)
+		}
 	}
 
 	ConnectDB()
+	Migrate()
 
 	Init.InitPlatform(&model.User{
 		Name:   testPlatformAdminName,
@@ -0,0 +1,167 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
+)
+
+// Invitation service.
+var Invitation = &invitationService{
+	mutex: &sync.Mutex{},
+}
+
+type invitationService struct {
+	mutex *sync.Mutex
+}
+
+// invitationExpiration is the valid duration of an invitation.
+const invitationExpiration = 7 * 24 * time.Hour
+
+// ErrInvalidInvitation means the invitation is not found, has been accepted or has expired.
+var ErrInvalidInvitation = errors.New("invitation is invalid or has expired")
+
+// AddInvitation adds the specified invitation with a generated token.
+func (srv *invitationService) AddInvitation(invitation *model.Invitation) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	invitation.Token = gulu.Rand.String(32)
+	invitation.ExpiredAt = time.Now().Add(invitationExpiration)
+	invitation.UsedByID = 0
+
+	return db.Create(invitation).Error
+}
+
+// GetInvitationURL gets the URL to accept the invitation with the specified token.
+func (srv *invitationService) GetInvitationURL(token string) string {
+	return model.Conf.Server + util.PathInvitations + "/" + token
+}
+
+// GetInvitation gets the pending invitation with the specified token, returns nil if not found or expired.
+func (srv *invitationService) GetInvitation(token string) *model.Invitation {
+	ret := &model.Invitation{}
+	if err := db.Where("`token` = ? AND `used_by_id` = ? AND `expired_at` > ?", token, 0, time.Now()).
+		First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// GetPendingInvitations gets the invitations of the specified blog which are neither accepted nor expired.
+func (srv *invitationService) GetPendingInvitations(blogID uint64) (ret []*model.Invitation) {
+	if err := db.Where("`blog_id` = ? AND `used_by_id` = ? AND `expired_at` > ?", blogID, 0, time.Now()).
+		Order("`created_at` DESC").Find(&ret).Error; nil != err {
+		logger.Errorf("get invitations failed: %s", err)
+	}
+
+	return
+}
+
+// RemoveInvitation removes the specified invitation.
+func (srv *invitationService) RemoveInvitation(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Where("`id` = ? AND `blog_id` = ?", id, blogID).Delete(&model.Invitation{}).Error
+}
+
+// AcceptInvitation accepts the invitation with the specified token, the specified user joins the blog with the role
+// preset in the invitation.
+func (srv *invitationService) AcceptInvitation(token string, userID uint64) (*model.Invitation, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	tx := db.Begin()
+	invitation, err := srv.acceptInvitation(tx, token, "", userID)
+	if nil != err {
+		tx.Rollback()
+
+		return nil, err
+	}
+	tx.Commit()
+
+	return invitation, nil
+}
+
+// GetAcceptedInvitation gets the invitation with the specified token accepted by the specified user, returns nil if
+// not found.
+func (srv *invitationService) GetAcceptedInvitation(token string, userID uint64) *model.Invitation {
+	if 0 == userID {
+		return nil
+	}
+
+	ret := &model.Invitation{}
+	if err := db.Where("`token` = ? AND `used_by_id` = ?", token, userID).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// acceptInvitation accepts the pending invitation with the specified token in the specified transaction. The
+// invitation must be sent to the specified email if it isn't empty. The invitation is claimed by a conditional update,
+// so concurrent accepting succeeds only once.
+func (srv *invitationService) acceptInvitation(tx *gorm.DB, token, email string, userID uint64) (*model.Invitation, error) {
+	invitation := &model.Invitation{}
+	if err := tx.Where("`token` = ? AND `used_by_id` = ? AND `expired_at` > ?", token, 0, time.Now()).
+		First(invitation).Error; nil != err {
+		return nil, ErrInvalidInvitation
+	}
+	if "" != email && !strings.EqualFold(email, invitation.Email) {
+		return nil, ErrInvalidInvitation
+	}
+	claim := tx.Model(&model.Invitation{}).Where("`id` = ? AND `used_by_id` = ?", invitation.ID, 0).
+		Update("used_by_id", userID)
+	if nil != claim.Error {
+		return nil, claim.Error
+	}
+	if 1 != claim.RowsAffected {
+		return nil, ErrInvalidInvitation
+	}
+	invitation.UsedByID = userID
+
+	count := 0
+	if err := tx.Model(&model.Correlation{}).Where("`id1` = ? AND `id2` = ? AND `type` = ?",
+		invitation.BlogID, userID, model.CorrelationBlogUser).Count(&count).Error; nil != err {
+		return nil, err
+	}
+	if 1 > count {
+		blogUser := &model.Correlation{
+			ID1:    invitation.BlogID,
+			ID2:    userID,
+			Type:   model.CorrelationBlogUser,
+			Int1:   invitation.Role,
+			Int2:   0,
+			BlogID: invitation.BlogID,
+		}
+		if err := tx.Create(blogUser).Error; nil != err {
+			return nil, err
+		}
+	}
+
+	return invitation, nil
+}
@@ -0,0 +1,62 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestAcceptInvitation(t *testing.T) {
+	invitation := &model.Invitation{
+		Role:      model.UserRoleBlogUser,
+		CreatorID: 1,
+		BlogID:    1,
+	}
+	if err := Invitation.AddInvitation(invitation); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if 32 != len(invitation.Token) {
+		t.Errorf("invalid token [%s]", invitation.Token)
+	}
+	if 1 != len(Invitation.GetPendingInvitations(1)) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(Invitation.GetPendingInvitations(1)))
+	}
+	if nil == Invitation.GetInvitation(invitation.Token) {
+		t.Error("invitation should be pending")
+
+		return
+	}
+
+	if _, err := Invitation.AcceptInvitation(invitation.Token, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if nil != Invitation.GetInvitation(invitation.Token) {
+		t.Error("invitation should have been accepted")
+	}
+	if _, err := Invitation.AcceptInvitation(invitation.Token, 1); ErrInvalidInvitation != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidInvitation, err)
+	}
+	if 0 != len(Invitation.GetPendingInvitations(1)) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(Invitation.GetPendingInvitations(1)))
+	}
+}
@@ -0,0 +1,330 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
+)
+
+// Job service, schedules the background jobs, retries their failed attempts, counts their runs and records the failed
+// ones.
+var Job = &jobService{
+	mutex: &sync.Mutex{},
+	jobs:  map[string]*job{},
+}
+
+type jobService struct {
+	mutex   *sync.Mutex
+	jobs    map[string]*job
+	started bool
+}
+
+// JobSpec specifies a background job.
+type JobSpec struct {
+	Name       string        // unique name, e.g. backup-blogs
+	Interval   time.Duration // interval between runs, 0 to run only at start or on demand
+	Retries    int           // max retries of a failed run
+	RunAtStart bool          // whether to run at start, otherwise the first run is scheduled after the last run
+	Run        func() error  // the job, a returned error or panic fails the attempt
+}
+
+// JobInfo represents a registered job and its run statistics.
+type JobInfo struct {
+	Name     string         `json:"name"`
+	Interval int64          `json:"interval"` // in second
+	Retries  int            `json:"retries"`
+	Running  bool           `json:"running"`
+	Stat     *model.JobStat `json:"stat"`
+}
+
+type job struct {
+	spec    *JobSpec
+	running bool
+}
+
+// Job arguments.
+const (
+	jobRunsKeptPerJob            = 100 // failed runs kept per job
+	adminConsoleJobRunPageSize   = 20
+	adminConsoleJobRunWindowSize = 20
+)
+
+// jobRetryDelay is the delay before the first retry of a failed attempt, later retries wait longer linearly.
+var jobRetryDelay = 30 * time.Second
+
+// Errors of jobs.
+var (
+	ErrJobNotFound = errors.New("not found job")
+	ErrJobRunning  = errors.New("job is running")
+)
+
+// Register registers the specified job, which is scheduled once the service is started.
+func (srv *jobService) Register(spec *JobSpec) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if _, ok := srv.jobs[spec.Name]; ok {
+		logger.Warnf("job [%s] has been registered", spec.Name)
+
+		return
+	}
+
+	j := &job{spec: spec}
+	srv.jobs[spec.Name] = j
+	if srv.started {
+		go srv.schedule(j)
+	}
+}
+
+// Start starts scheduling the registered jobs, jobs registered later are scheduled at registration. Runs left running
+// by the previous process are marked as failed.
+func (srv *jobService) Start() {
+	srv.failInterruptedRuns()
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.started = true
+	for _, j := range srv.jobs {
+		go srv.schedule(j)
+	}
+}
+
+// Run runs the specified registered job on demand.
+func (srv *jobService) Run(name string) error {
+	srv.mutex.Lock()
+	j := srv.jobs[name]
+	srv.mutex.Unlock()
+	if nil == j {
+		return ErrJobNotFound
+	}
+
+	if !srv.tryRun(j) {
+		return ErrJobRunning
+	}
+
+	return nil
+}
+
+// Enqueue runs the specified one-off job, such as a feed ping, in background with retries. Its runs are counted and
+// recorded under the specified name as well.
+func (srv *jobService) Enqueue(name string, retries int, run func() error) {
+	go srv.run(name, retries, run)
+}
+
+// GetJobs gets the registered jobs with their run statistics, ordered by name.
+func (srv *jobService) GetJobs() (ret []*JobInfo) {
+	srv.mutex.Lock()
+	for _, j := range srv.jobs {
+		ret = append(ret, &JobInfo{
+			Name:     j.spec.Name,
+			Interval: int64(j.spec.Interval / time.Second),
+			Retries:  j.spec.Retries,
+			Running:  j.running,
+		})
+	}
+	srv.mutex.Unlock()
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	for _, info := range ret {
+		info.Stat = srv.getStat(info.Name)
+	}
+
+	return
+}
+
+// ConsoleGetJobRuns gets failed runs of the specified job, runs of all jobs if the name is empty, the latest first.
+func (srv *jobService) ConsoleGetJobRuns(name string, page int) (ret []*model.JobRun, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleJobRunPageSize
+	count := 0
+	query := db.Model(&model.JobRun{})
+	if "" != name {
+		query = query.Where("`name` = ?", name)
+	}
+	if err := query.Order("`id` DESC").Count(&count).Offset(offset).Limit(adminConsoleJobRunPageSize).
+		Find(&ret).Error; nil != err {
+		logger.Errorf("get job runs failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleJobRunPageSize, adminConsoleJobRunWindowSize, count)
+
+	return
+}
+
+// schedule runs the specified job at start or after its interval since the last run counted, then every interval.
+func (srv *jobService) schedule(j *job) {
+	delay := time.Duration(0)
+	if !j.spec.RunAtStart {
+		if 1 > j.spec.Interval {
+			return
+		}
+
+		delay = j.spec.Interval
+		if stat := srv.getStat(j.spec.Name); nil != stat && nil != stat.LastStartedAt {
+			delay -= time.Since(*stat.LastStartedAt)
+		}
+	}
+	if 0 < delay {
+		time.Sleep(delay)
+	}
+	srv.tryRun(j)
+	if 1 > j.spec.Interval {
+		return
+	}
+
+	for range time.Tick(j.spec.Interval) {
+		srv.tryRun(j)
+	}
+}
+
+// tryRun runs the specified job in background unless it's running, returns false if it's running.
+func (srv *jobService) tryRun(j *job) bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if j.running {
+		return false
+	}
+
+	j.running = true
+	go func() {
+		srv.run(j.spec.Name, j.spec.Retries, j.spec.Run)
+
+		srv.mutex.Lock()
+		j.running = false
+		srv.mutex.Unlock()
+	}()
+
+	return true
+}
+
+// run runs the specified job, retries the failed attempts, counts the run and records it if failed.
+func (srv *jobService) run(name string, retries int, run func() error) *model.JobRun {
+	jobRun := &model.JobRun{Name: name, Status: model.JobRunStatusRunning}
+	jobRun.CreatedAt = time.Now()
+
+	var err error
+	for jobRun.Attempts = 1; ; jobRun.Attempts++ {
+		if err = callJob(run); nil == err || jobRun.Attempts > retries {
+			break
+		}
+
+		logger.Warnf("attempt [%d] of job [%s] failed, retries later: %s", jobRun.Attempts, name, err)
+		time.Sleep(jobRetryDelay * time.Duration(jobRun.Attempts))
+	}
+
+	now := time.Now()
+	jobRun.EndedAt = &now
+	jobRun.Status = model.JobRunStatusSucceeded
+	if nil != err {
+		logger.Errorf("job [%s] failed after [%d] attempts: %s", name, jobRun.Attempts, err)
+		jobRun.Status = model.JobRunStatusFailed
+		jobRun.Message = err.Error()
+		if 1024 < len(jobRun.Message) {
+			jobRun.Message = jobRun.Message[:1024]
+		}
+	}
+	srv.count(jobRun)
+	if model.JobRunStatusFailed == jobRun.Status {
+		if err := db.Create(jobRun).Error; nil != err {
+			logger.Errorf("record run of job [%s] failed: %s", name, err)
+		}
+		srv.pruneRuns(name)
+	}
+
+	return jobRun
+}
+
+// count counts the specified ended run into the statistics of its job.
+func (srv *jobService) count(jobRun *model.JobRun) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	stat := srv.getStat(jobRun.Name)
+	if nil == stat {
+		stat = &model.JobStat{Name: jobRun.Name}
+		if err := db.Create(stat).Error; nil != err {
+			logger.Errorf("count run of job [%s] failed: %s", jobRun.Name, err)
+
+			return
+		}
+	}
+
+	counter := "succeeded"
+	if model.JobRunStatusFailed == jobRun.Status {
+		counter = "failed"
+	}
+	if err := db.Model(stat).UpdateColumns(map[string]interface{}{
+		counter:           gorm.Expr("`" + counter + "` + 1"),
+		"last_status":     jobRun.Status,
+		"last_started_at": jobRun.CreatedAt,
+		"last_ended_at":   jobRun.EndedAt,
+	}).Error; nil != err {
+		logger.Errorf("count run of job [%s] failed: %s", jobRun.Name, err)
+	}
+}
+
+// failInterruptedRuns marks the runs left running, which were interrupted by a crash or a restart, as failed.
+func (srv *jobService) failInterruptedRuns() {
+	if err := db.Model(&model.JobRun{}).Where("`status` = ?", model.JobRunStatusRunning).UpdateColumns(map[string]interface{}{
+		"status":  model.JobRunStatusFailed,
+		"message": "interrupted",
+	}).Error; nil != err {
+		logger.Errorf("fail interrupted job runs failed: %s", err)
+	}
+}
+
+// callJob calls the specified job, a panic is returned as an error.
+func callJob(run func() error) (err error) {
+	defer func() {
+		if e := recover(); nil != e {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+
+	return run()
+}
+
+// pruneRuns removes the failed runs of the specified job except the latest jobRunsKeptPerJob ones.
+func (srv *jobService) pruneRuns(name string) {
+	var runs []*model.JobRun
+	if err := db.Where("`name` = ?", name).Order("`id` DESC").Offset(jobRunsKeptPerJob).Limit(1).
+		Find(&runs).Error; nil != err || 1 > len(runs) {
+		return
+	}
+
+	if err := db.Unscoped().Where("`name` = ? AND `id` <= ?", name, runs[0].ID).Delete(&model.JobRun{}).Error; nil != err {
+		logger.Errorf("prune runs of job [%s] failed: %s", name, err)
+	}
+}
+
+func (srv *jobService) getStat(name string) *model.JobStat {
+	ret := &model.JobStat{}
+	if err := db.Where("`name` = ?", name).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
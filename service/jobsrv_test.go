@@ -0,0 +1,93 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestJobRun(t *testing.T) {
+	jobRetryDelay = 0
+
+	attempts := 0
+	jobRun := Job.run("test-retry", 2, func() error {
+		if attempts++; 2 > attempts {
+			return errors.New("temporary failure")
+		}
+
+		return nil
+	})
+	if model.JobRunStatusSucceeded != jobRun.Status || 2 != jobRun.Attempts {
+		t.Errorf("expected is [%d, %d], actual is [%d, %d]", model.JobRunStatusSucceeded, 2, jobRun.Status, jobRun.Attempts)
+	}
+
+	jobRun = Job.run("test-panic", 1, func() error {
+		panic("oops")
+	})
+	if model.JobRunStatusFailed != jobRun.Status || 2 != jobRun.Attempts || "panic: oops" != jobRun.Message {
+		t.Errorf("unexpected run [status=%d, attempts=%d, message=%s]", jobRun.Status, jobRun.Attempts, jobRun.Message)
+	}
+
+	runs, pagination := Job.ConsoleGetJobRuns("test-panic", 1)
+	if 1 != len(runs) || 1 != pagination.RecordCount {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(runs))
+	}
+	if runs, _ := Job.ConsoleGetJobRuns("test-retry", 1); 0 != len(runs) {
+		t.Errorf("succeeded runs should not be recorded, actual runs are [%+v]", runs)
+	}
+	if stat := Job.getStat("test-retry"); nil == stat || 1 != stat.Succeeded || 0 != stat.Failed ||
+		model.JobRunStatusSucceeded != stat.LastStatus || nil == stat.LastStartedAt {
+		t.Errorf("unexpected stat [%+v] of job [test-retry]", stat)
+	}
+
+	Job.run("test-retry", 0, func() error { return errors.New("failure") })
+	if stat := Job.getStat("test-retry"); nil == stat || 1 != stat.Succeeded || 1 != stat.Failed ||
+		model.JobRunStatusFailed != stat.LastStatus {
+		t.Errorf("unexpected stat [%+v] of job [test-retry]", stat)
+	}
+}
+
+func TestJobFailInterruptedRuns(t *testing.T) {
+	jobRun := &model.JobRun{Name: "test-interrupted", Status: model.JobRunStatusRunning}
+	if err := db.Create(jobRun).Error; nil != err {
+		t.Fatal(err)
+	}
+
+	Job.failInterruptedRuns()
+	runs, _ := Job.ConsoleGetJobRuns("test-interrupted", 1)
+	if 1 != len(runs) || model.JobRunStatusFailed != runs[0].Status || "interrupted" != runs[0].Message {
+		t.Errorf("unexpected runs [%+v]", runs)
+	}
+}
+
+func TestJobRegister(t *testing.T) {
+	Job.Register(&JobSpec{Name: "test-register", Run: func() error { return nil }})
+	if err := Job.Run("test-not-found"); ErrJobNotFound != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrJobNotFound, err)
+	}
+
+	found := false
+	for _, info := range Job.GetJobs() {
+		found = found || "test-register" == info.Name
+	}
+	if !found {
+		t.Errorf("job [test-register] should be registered")
+	}
+}
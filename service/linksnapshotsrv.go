@@ -0,0 +1,151 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Link snapshot service.
+var LinkSnapshot = &linkSnapshotService{
+	mutex: &sync.Mutex{},
+}
+
+type linkSnapshotService struct {
+	mutex *sync.Mutex
+}
+
+// maxLinkArchiveAttempts is the max count of attempts archiving a link, the link is given up after that.
+const maxLinkArchiveAttempts = 3
+
+// QueueArticleLinks queues external links of the specified published article for archiving, links queued already in
+// the blog are skipped. Does nothing if the link archiver is not configured.
+func (srv *linkSnapshotService) QueueArticleLinks(article *model.Article) {
+	if "" == model.Conf.LinkArchiver || model.ArticleStatusOK != article.Status {
+		return
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for _, link := range util.ExtractLinks(article.Content) {
+		if !isExternalLink(link) {
+			continue
+		}
+
+		hash := hashLink(link)
+		count := 0
+		if err := db.Model(&model.LinkSnapshot{}).Where("`url_hash` = ? AND `blog_id` = ?", hash, article.BlogID).
+			Count(&count).Error; nil != err {
+			logger.Errorf("count link snapshots failed: %s", err)
+
+			return
+		}
+		if 0 < count {
+			continue
+		}
+
+		snapshot := &model.LinkSnapshot{
+			URL:       link,
+			URLHash:   hash,
+			ArticleID: article.ID,
+			BlogID:    article.BlogID,
+		}
+		if err := db.Create(snapshot).Error; nil != err {
+			logger.Errorf("queue link [%s] for archiving failed: %s", link, err)
+		}
+	}
+}
+
+// ArchivePendingLinks submits at most the specified size of queued links to the link archiver, returns the count of
+// links archived successfully.
+func (srv *linkSnapshotService) ArchivePendingLinks(size int) (ret int) {
+	if "" == model.Conf.LinkArchiver {
+		return
+	}
+
+	var snapshots []*model.LinkSnapshot
+	if err := db.Where("`archived_url` = ? AND `attempts` < ?", "", maxLinkArchiveAttempts).
+		Order("`id` ASC").Limit(size).Find(&snapshots).Error; nil != err {
+		logger.Errorf("get pending link snapshots failed: %s", err)
+
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		columns := map[string]interface{}{}
+		archivedURL, err := util.ArchiveLink(model.Conf.LinkArchiver, snapshot.URL)
+		if nil != err {
+			logger.Warnf("archive link [%s] failed: %s", snapshot.URL, err)
+			columns["Attempts"] = snapshot.Attempts + 1
+		} else {
+			columns["ArchivedURL"] = archivedURL
+			columns["ArchivedAt"] = time.Now()
+			ret++
+		}
+
+		srv.mutex.Lock()
+		if err := db.Model(snapshot).UpdateColumns(columns).Error; nil != err {
+			logger.Errorf("update link snapshot [%d] failed: %s", snapshot.ID, err)
+		}
+		srv.mutex.Unlock()
+	}
+
+	return
+}
+
+// GetArchivedURL returns the archived snapshot URL of the specified link in the specified blog, which is the fallback
+// of the link once it is found dead. Returns "" if the link has not been archived.
+func (srv *linkSnapshotService) GetArchivedURL(link string, blogID uint64) string {
+	snapshot := &model.LinkSnapshot{}
+	if err := db.Where("`url_hash` = ? AND `blog_id` = ? AND `archived_url` <> ?", hashLink(link), blogID, "").
+		First(snapshot).Error; nil != err {
+		return ""
+	}
+
+	return snapshot.ArchivedURL
+}
+
+// isExternalLink checks whether the specified link points to neither this server nor the link archiver.
+func isExternalLink(link string) bool {
+	u, err := url.Parse(link)
+	if nil != err {
+		return false
+	}
+	for _, internal := range []string{model.Conf.Server, model.Conf.StaticServer, model.Conf.LinkArchiver} {
+		if internalURL, err := url.Parse(internal); nil == err && "" != internalURL.Host &&
+			strings.EqualFold(internalURL.Host, u.Host) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hashLink(link string) string {
+	sum := sha256.Sum256([]byte(link))
+
+	return hex.EncodeToString(sum[:])
+}
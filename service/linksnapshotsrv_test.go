@@ -0,0 +1,77 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestArchivePendingLinks(t *testing.T) {
+	archiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "dead.com") {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+		w.Header().Set("Content-Location", "/web/20200101000000/"+strings.TrimPrefix(r.URL.Path, "/save/"))
+	}))
+	defer archiver.Close()
+	defer func(server, linkArchiver string) {
+		model.Conf.Server = server
+		model.Conf.LinkArchiver = linkArchiver
+	}(model.Conf.Server, model.Conf.LinkArchiver)
+	model.Conf.Server = "http://pipe.test"
+	model.Conf.LinkArchiver = archiver.URL + "/save/"
+
+	article := &model.Article{
+		Model:   model.Model{ID: 1},
+		Content: "[Pipe](https://github.com/b3log/pipe) https://dead.com/page " + model.Conf.Server + "/blogs/pipe",
+		Status:  model.ArticleStatusOK,
+		BlogID:  1,
+	}
+	LinkSnapshot.QueueArticleLinks(article)
+	LinkSnapshot.QueueArticleLinks(article)
+	count := 0
+	db.Model(&model.LinkSnapshot{}).Where("`blog_id` = ?", 1).Count(&count)
+	if 2 != count {
+		t.Errorf("expected is [%d], actual is [%d]", 2, count)
+	}
+
+	if archived := LinkSnapshot.ArchivePendingLinks(10); 1 != archived {
+		t.Errorf("expected is [%d], actual is [%d]", 1, archived)
+	}
+	expected := archiver.URL + "/web/20200101000000/https://github.com/b3log/pipe"
+	if archivedURL := LinkSnapshot.GetArchivedURL("https://github.com/b3log/pipe", 1); expected != archivedURL {
+		t.Errorf("expected is [%s], actual is [%s]", expected, archivedURL)
+	}
+	if archivedURL := LinkSnapshot.GetArchivedURL("https://dead.com/page", 1); "" != archivedURL {
+		t.Errorf("dead link should not be archived")
+	}
+
+	for i := 1; i < maxLinkArchiveAttempts; i++ {
+		LinkSnapshot.ArchivePendingLinks(10)
+	}
+	db.Model(&model.LinkSnapshot{}).Where("`archived_url` = ? AND `attempts` < ?", "", maxLinkArchiveAttempts).Count(&count)
+	if 0 != count {
+		t.Errorf("dead link should be given up after [%d] attempts", maxLinkArchiveAttempts)
+	}
+}
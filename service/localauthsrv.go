@@ -0,0 +1,287 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Local authentication service, signs in users with username and password in authentication mode "local".
+var LocalAuth = &localAuthService{
+	mutex: &sync.Mutex{},
+}
+
+type localAuthService struct {
+	mutex *sync.Mutex
+}
+
+// Password arguments.
+const (
+	passwordMinLength     = 8
+	passwordMaxLength     = 72 // bcrypt ignores the bytes after
+	passwordResetDuration = time.Hour
+)
+
+// Errors of local authentication.
+var (
+	ErrInvalidCredentials  = errors.New("username or password is incorrect")
+	ErrInvalidPassword     = errors.New("password should be 8-72 characters")
+	ErrRegistrationFailed  = errors.New("can not register with the specified email, please sign in or reset the password")
+	ErrInvalidResetToken   = errors.New("password reset link is invalid or has expired")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrLocalAuthNotEnabled = errors.New("local authentication is not enabled")
+)
+
+// Register registers a user with a blog of its own, the first registered user becomes the platform admin.
+func (srv *localAuthService) Register(name, email, password string) (*model.User, error) {
+	return srv.register(name, email, password, false, "")
+}
+
+// RegisterInvited registers an account with the invitation specified by the token, which must be pending and sent to
+// the specified email. The invitation is accepted in the same transaction creating the account, so it can't be used
+// twice.
+func (srv *localAuthService) RegisterInvited(name, email, password, invitationToken string) (*model.User, error) {
+	if "" == invitationToken {
+		return nil, ErrInvalidInvitation
+	}
+
+	return srv.register(name, email, password, false, invitationToken)
+}
+
+// RegisterReader registers a reader account which follows blogs without having a blog of its own. The first account
+// still inits the platform.
+func (srv *localAuthService) RegisterReader(name, email, password string) (*model.User, error) {
+	return srv.register(name, email, password, true, "")
+}
+
+func (srv *localAuthService) register(name, email, password string, reader bool, invitationToken string) (*model.User, error) {
+	if !usernameRegexp.MatchString(name) {
+		return nil, ErrInvalidUsername
+	}
+	if util.IsReservedName(name) {
+		return nil, ErrReservedName
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if nil != err {
+		return nil, ErrInvalidEmail
+	}
+	email = strings.ToLower(address.Address)
+	hash, err := hashPassword(password)
+	if nil != err {
+		return nil, err
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if nil != User.GetUserByName(name) || nil != User.GetUserByAlias(name) {
+		return nil, ErrUsernameTaken
+	}
+	if nil != srv.getUserByEmail(email) {
+		return nil, ErrRegistrationFailed // doesn't tell whether the email has been registered
+	}
+
+	user := &model.User{
+		Name:      name,
+		Email:     email,
+		Password:  hash,
+		AvatarURL: gravatarURL(email),
+	}
+	if !Init.Inited() {
+		err = Init.InitPlatform(user)
+	} else if reader {
+		err = db.Create(user).Error
+	} else if "" != invitationToken {
+		err = Init.initBlogWith(user, func(tx *gorm.DB) error {
+			_, err := Invitation.acceptInvitation(tx, invitationToken, email, user.ID)
+
+			return err
+		})
+	} else {
+		err = Init.InitBlog(user)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Authenticate checks the password of the user specified by the name or email.
+func (srv *localAuthService) Authenticate(nameOrEmail, password string) (*model.User, error) {
+	nameOrEmail = strings.TrimSpace(nameOrEmail)
+	var user *model.User
+	if strings.Contains(nameOrEmail, "@") {
+		user = srv.getUserByEmail(strings.ToLower(nameOrEmail))
+	} else {
+		user = User.GetUserByName(nameOrEmail)
+	}
+	if nil == user || "" == user.Password {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); nil != err {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// SendPasswordReset mails a password reset link to the user registered with the specified email. Nothing is sent if
+// no user is found, so that callers can't probe the registered emails.
+func (srv *localAuthService) SendPasswordReset(email string) error {
+	user := srv.getUserByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if nil == user {
+		return nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); nil != err {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+	if err := db.Model(user).UpdateColumns(map[string]interface{}{
+		"ResetToken":     hashResetToken(token),
+		"ResetExpiredAt": util.CurrentMillisecond() + uint64(passwordResetDuration/time.Millisecond),
+	}).Error; nil != err {
+		return err
+	}
+	cache.User.Put(user)
+
+	locale := "zh_CN"
+	if ownBlog := User.GetOwnBlog(user.ID); nil != ownBlog {
+		locale = Setting.GetSetting(model.SettingCategoryI18n, model.SettingNameI18nLocale, ownBlog.ID).Value
+	}
+	resetURL := model.Conf.Server + util.PathInit + "?reset=" + token
+	subject := i18n.GetMessage(locale, "passwordResetMailSubject")
+	body := i18n.GetMessagef(locale, "passwordResetMailBody", user.Name, resetURL, resetURL)
+
+	return Mail.Send([]string{user.Email}, subject, body)
+}
+
+// ResetPassword sets the password of the user specified by the reset token, the token is invalidated.
+func (srv *localAuthService) ResetPassword(token, password string) (*model.User, error) {
+	hash, err := hashPassword(password)
+	if nil != err {
+		return nil, err
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user := &model.User{}
+	if "" == token || nil != db.Where("`reset_token` = ? AND `reset_expired_at` > ?", hashResetToken(token),
+		util.CurrentMillisecond()).First(user).Error {
+		return nil, ErrInvalidResetToken
+	}
+	if err := db.Model(user).UpdateColumns(map[string]interface{}{
+		"Password":       hash,
+		"ResetToken":     "",
+		"ResetExpiredAt": uint64(0),
+	}).Error; nil != err {
+		return nil, err
+	}
+	cache.User.Put(user)
+	if err := UserSession.RemoveUserSessions(user.ID); nil != err { // sessions logged in with the old password
+		logger.Errorf("revoke sessions of user [%d] failed: %s", user.ID, err)
+	}
+
+	return user, nil
+}
+
+func (srv *localAuthService) getUserByEmail(email string) *model.User {
+	if "" == email {
+		return nil
+	}
+
+	ret := &model.User{}
+	if err := db.Where("`email` = ?", email).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// gravatarURL returns the Gravatar URL of the specified email, an identicon is generated if not found.
+func gravatarURL(email string) string {
+	sum := md5.Sum([]byte(email))
+
+	return "https://secure.gravatar.com/avatar/" + hex.EncodeToString(sum[:]) + "?d=identicon"
+}
+
+// SetPassword sets the password of the specified user without verification and revokes its sessions, it's used by
+// operators to recover locked-out accounts.
+func (srv *localAuthService) SetPassword(userID uint64, password string) error {
+	hash, err := hashPassword(password)
+	if nil != err {
+		return err
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user := &model.User{}
+	if err := db.First(user, userID).Error; nil != err {
+		return err
+	}
+	if err := db.Model(user).UpdateColumns(map[string]interface{}{
+		"Password":       hash,
+		"ResetToken":     "",
+		"ResetExpiredAt": uint64(0),
+	}).Error; nil != err {
+		return err
+	}
+	cache.User.Put(user)
+	if err := UserSession.RemoveUserSessions(user.ID); nil != err {
+		logger.Errorf("revoke sessions of user [%d] failed: %s", user.ID, err)
+	}
+
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if passwordMinLength > len(password) || passwordMaxLength < len(password) {
+		return "", ErrInvalidPassword
+	}
+
+	ret, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if nil != err {
+		return "", err
+	}
+
+	return string(ret), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+func TestLocalAuth(t *testing.T) {
+	if _, err := LocalAuth.Register("localuser", "local@b3log.org", "short"); ErrInvalidPassword != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidPassword, err)
+	}
+
+	user, err := LocalAuth.Register("localuser", "Local@b3log.org", "password")
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if nil == User.GetOwnBlog(user.ID) {
+		t.Errorf("blog of the registered user should be initialized")
+	}
+	if _, err := LocalAuth.Register("localuser2", "local@b3log.org", "password"); ErrRegistrationFailed != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrRegistrationFailed, err)
+	}
+
+	if _, err := LocalAuth.Authenticate("localuser", "incorrect"); ErrInvalidCredentials != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidCredentials, err)
+	}
+	if _, err := LocalAuth.Authenticate("local@b3log.org", "password"); nil != err {
+		t.Error(err)
+	}
+	if _, err := LocalAuth.Authenticate(testPlatformAdminName, ""); ErrInvalidCredentials != err {
+		t.Errorf("users without password should not login")
+	}
+
+	if _, err := LocalAuth.ResetPassword("invalid", "password2"); ErrInvalidResetToken != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidResetToken, err)
+	}
+	db.Model(&model.User{}).Where("`id` = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"reset_token":      hashResetToken("token"),
+		"reset_expired_at": util.CurrentMillisecond() + 60*1000,
+	})
+	if _, err := LocalAuth.ResetPassword("token", "password2"); nil != err {
+		t.Error(err)
+	}
+	if _, err := LocalAuth.Authenticate("localuser", "password2"); nil != err {
+		t.Error(err)
+	}
+	if _, err := LocalAuth.ResetPassword("token", "password3"); ErrInvalidResetToken != err {
+		t.Errorf("reset token should be invalidated")
+	}
+
+	if err := LocalAuth.SetPassword(user.ID, "short"); ErrInvalidPassword != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidPassword, err)
+	}
+	if err := LocalAuth.SetPassword(user.ID, "password4"); nil != err {
+		t.Error(err)
+	}
+	if _, err := LocalAuth.Authenticate("localuser", "password4"); nil != err {
+		t.Error(err)
+	}
+}
+
+func TestRegisterInvited(t *testing.T) {
+	invitation := &model.Invitation{
+		Role:      model.UserRoleBlogEditor,
+		Email:     "invited@b3log.org",
+		CreatorID: 1,
+		BlogID:    1,
+	}
+	if err := Invitation.AddInvitation(invitation); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	if _, err := LocalAuth.RegisterInvited("inviteduser", "other@b3log.org", "password", invitation.Token); ErrInvalidInvitation != err {
+		t.Errorf("invitation should be used by the invited email only")
+	}
+	user, err := LocalAuth.RegisterInvited("inviteduser", "Invited@b3log.org", "password", invitation.Token)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if model.UserRoleBlogEditor != User.GetRole(user.ID, 1) {
+		t.Errorf("expected is [%d], actual is [%d]", model.UserRoleBlogEditor, User.GetRole(user.ID, 1))
+	}
+	if nil == Invitation.GetAcceptedInvitation(invitation.Token, user.ID) {
+		t.Errorf("invitation should be accepted on registration")
+	}
+
+	db.Model(invitation).Update("email", "invited2@b3log.org")
+	if _, err := LocalAuth.RegisterInvited("inviteduser2", "invited2@b3log.org", "password", invitation.Token); ErrInvalidInvitation != err {
+		t.Errorf("used invitation should be rejected")
+	}
+}
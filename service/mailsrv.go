@@ -0,0 +1,51 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Mail service, sends mails with the configured SMTP server.
+var Mail = &mailService{}
+
+type mailService struct {
+}
+
+// Enabled checks whether the SMTP server is configured.
+func (srv *mailService) Enabled() bool {
+	return "" != model.Conf.SMTP
+}
+
+// Send sends an HTML mail to the specified recipients.
+func (srv *mailService) Send(to []string, subject, htmlBody string) error {
+	if !srv.Enabled() {
+		return errors.New("SMTP server is not configured")
+	}
+
+	if err := util.SendMail(model.Conf.SMTP, to, subject, htmlBody); nil != err {
+		logger.Errorf("send mail [%s] to %v failed: %s", subject, to, err)
+
+		return err
+	}
+	logger.Debugf("sent mail [%s] to %v", subject, to)
+
+	return nil
+}
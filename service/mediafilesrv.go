@@ -0,0 +1,183 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// MediaFile service, records files uploaded to media storage for the media library of admin console.
+var MediaFile = &mediaFileService{
+	mutex: &sync.Mutex{},
+}
+
+type mediaFileService struct {
+	mutex *sync.Mutex
+}
+
+// Media file pagination arguments of admin console.
+const (
+	adminConsoleMediaFileListPageSize   = 15
+	adminConsoleMediaFileListWindowSize = 20
+)
+
+// ErrMediaFileInUse is returned when removing a media file which is referenced by articles.
+var ErrMediaFileInUse = errors.New("media file is referenced by articles")
+
+// ErrMediaFileShared is returned when replacing a media file whose content has also been uploaded to other blogs.
+var ErrMediaFileShared = errors.New("media file is shared with other blogs")
+
+// ErrMediaFileTypeMismatch is returned when replacing a media file with a file of another type.
+var ErrMediaFileTypeMismatch = errors.New("media file can only be replaced with a file of the same type")
+
+// AddMediaFile records the specified uploaded file. It does nothing if the blog has uploaded a file stored with the
+// same key, which happens when uploading the same content twice.
+func (srv *mediaFileService) AddMediaFile(file *model.MediaFile) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	count := 0
+	if err := db.Model(&model.MediaFile{}).Where("`key` = ? AND `blog_id` = ?", file.Key, file.BlogID).
+		Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return nil
+	}
+
+	return db.Create(file).Error
+}
+
+// GetMediaFile returns the media file of the specified ID uploaded to the specified blog, returns nil if not found.
+func (srv *mediaFileService) GetMediaFile(id, blogID uint64) *model.MediaFile {
+	ret := &model.MediaFile{}
+	if err := db.Where("`id` = ? AND `blog_id` = ?", id, blogID).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// ConsoleGetMediaFiles returns the media files uploaded to the specified blog whose names contain the specified
+// keyword, the latest first.
+func (srv *mediaFileService) ConsoleGetMediaFiles(keyword string, page int, blogID uint64) (ret []*model.MediaFile, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleMediaFileListPageSize
+	count := 0
+
+	where := "`blog_id` = ?"
+	whereArgs := []interface{}{blogID}
+	if "" != keyword {
+		where += " AND `name` LIKE ?"
+		whereArgs = append(whereArgs, "%"+keyword+"%")
+	}
+
+	if err := db.Model(&model.MediaFile{}).Where(where, whereArgs...).
+		Order("`id` DESC").Count(&count).
+		Offset(offset).Limit(adminConsoleMediaFileListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get media files failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleMediaFileListPageSize, adminConsoleMediaFileListWindowSize, count)
+
+	return
+}
+
+// GetMediaFileUsages returns the articles of the blog of the specified media file which reference it, either by its
+// media URL or by its resized variants at /images/:hash, both of which contain the hash of the file.
+func (srv *mediaFileService) GetMediaFileUsages(file *model.MediaFile) (ret []*model.Article) {
+	pattern := "%" + file.Key + "%"
+	if "" != file.Hash {
+		pattern = "%" + file.Hash + "%"
+	}
+
+	if err := db.Model(&model.Article{}).Select("`id`, `title`, `path`, `status`").
+		Where("`blog_id` = ? AND (`content` LIKE ? OR `abstract` LIKE ?)", file.BlogID, pattern, pattern).
+		Order("`id` DESC").Find(&ret).Error; nil != err {
+		logger.Errorf("get usages of media file [%d] failed: %s", file.ID, err)
+	}
+
+	return
+}
+
+// RemoveMediaFile removes the specified media file. It returns ErrMediaFileInUse if the file is referenced by articles
+// unless force is true. The stored content is kept if other blogs have uploaded the same file.
+func (srv *mediaFileService) RemoveMediaFile(file *model.MediaFile, force bool) error {
+	if !force && 0 < len(srv.GetMediaFileUsages(file)) {
+		return ErrMediaFileInUse
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if err := db.Delete(file).Error; nil != err {
+		return err
+	}
+	cache.Page.Purge(file.BlogID)
+
+	count := 0
+	if err := db.Model(&model.MediaFile{}).Where("`key` = ?", file.Key).Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return nil
+	}
+	if err := db.Where("`key` = ?", file.Key).Delete(&model.Image{}).Error; nil != err {
+		return err
+	}
+
+	return Media.Remove(file.Key)
+}
+
+// ReplaceMediaFile replaces the content of the specified media file by the specified data of the specified MIME type.
+// The file is stored with the same key so that articles referencing it show the new content without being edited,
+// hence files shared with other blogs can not be replaced.
+func (srv *mediaFileService) ReplaceMediaFile(file *model.MediaFile, mimeType string, data []byte) error {
+	if file.MIMEType != mimeType {
+		return ErrMediaFileTypeMismatch
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	count := 0
+	if err := db.Model(&model.MediaFile{}).Where("`key` = ? AND `blog_id` != ?", file.Key, file.BlogID).
+		Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return ErrMediaFileShared
+	}
+
+	if _, err := Media.Put(file.Key, data); nil != err {
+		return err
+	}
+	file.Size = int64(len(data))
+	if err := db.Model(file).Updates(map[string]interface{}{"Size": file.Size}).Error; nil != err {
+		return err
+	}
+	if err := Image.ReplaceImage(file.Key, data); nil != err {
+		logger.Errorf("replace image [%s] failed: %s", file.Key, err)
+	}
+	cache.Page.Purge(file.BlogID)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestMediaFileUsages(t *testing.T) {
+	const blogID = uint64(99)
+	mediaFile := &model.MediaFile{
+		Name:     "pipe",
+		Key:      "articles/201901/fedcba9876543210.png",
+		URL:      "http://localhost/media/articles/201901/fedcba9876543210.png",
+		MIMEType: "image/png",
+		Hash:     "fedcba9876543210",
+		BlogID:   blogID,
+	}
+	if err := MediaFile.AddMediaFile(mediaFile); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := MediaFile.AddMediaFile(&model.MediaFile{Key: mediaFile.Key, BlogID: blogID}); nil != err {
+		t.Error(err)
+
+		return
+	}
+	mediaFiles, _ := MediaFile.ConsoleGetMediaFiles("pip", 1, blogID)
+	if 1 != len(mediaFiles) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(mediaFiles))
+
+		return
+	}
+
+	article := &model.Article{Title: "Media usage", Content: "![](/images/fedcba9876543210?w=960&h=540)", BlogID: blogID}
+	if err := db.Create(article).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	if usages := MediaFile.GetMediaFileUsages(mediaFiles[0]); 1 != len(usages) || article.ID != usages[0].ID {
+		t.Errorf("unexpected usages [%+v]", usages)
+	}
+	if err := MediaFile.RemoveMediaFile(mediaFiles[0], false); ErrMediaFileInUse != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrMediaFileInUse, err)
+	}
+	if err := MediaFile.ReplaceMediaFile(mediaFiles[0], "image/jpeg", []byte("jpeg")); ErrMediaFileTypeMismatch != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrMediaFileTypeMismatch, err)
+	}
+}
@@ -0,0 +1,289 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/parnurzeal/gorequest"
+)
+
+// PlatformBlogID is the ID of the blog of the platform admin, which holds the platform wide settings such as storage.
+const PlatformBlogID = uint64(1)
+
+// Media service, stores uploaded media in the storage selected in the platform storage settings, falls back to the
+// media directory or the media S3 bucket of the configuration.
+var Media = &mediaService{}
+
+type mediaService struct {
+}
+
+// mediaStorage stores media objects.
+type mediaStorage interface {
+	// put stores the specified data with the specified key, returns the public URL.
+	put(key string, data []byte) (string, error)
+	// get returns the data stored with the specified key.
+	get(key string) ([]byte, error)
+	// remove removes the data stored with the specified key.
+	remove(key string) error
+}
+
+// localStorage stores media in a local directory served at /media.
+type localStorage struct {
+	dir string
+}
+
+func (s *localStorage) put(key string, data []byte) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); nil != err {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); nil != err {
+		return "", err
+	}
+
+	return model.Conf.Server + util.PathMedia + "/" + key, nil
+}
+
+func (s *localStorage) get(key string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+}
+
+func (s *localStorage) remove(key string) error {
+	if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key))); nil != err && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// s3Storage stores media in an Amazon S3 or S3 compatible (e.g. MinIO) bucket.
+type s3Storage struct {
+	bucketURL string // s3://accessKey:secretKey@endpoint/bucket?region=us-east-1
+	publicURL string
+}
+
+func (s *s3Storage) put(key string, data []byte) (string, error) {
+	if "" == s.publicURL {
+		return "", errors.New("media URL is required for storing media in S3")
+	}
+	if err := util.S3PutObject(s.bucketURL, key, data); nil != err {
+		return "", err
+	}
+
+	return strings.TrimSuffix(s.publicURL, "/") + "/" + key, nil
+}
+
+func (s *s3Storage) get(key string) ([]byte, error) {
+	return getPublicObject(s.publicURL, key)
+}
+
+func (s *s3Storage) remove(key string) error {
+	return util.S3DeleteObject(s.bucketURL, key)
+}
+
+// ossStorage stores media in an Aliyun OSS bucket.
+type ossStorage struct {
+	endpoint  string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+}
+
+func (s *ossStorage) put(key string, data []byte) (string, error) {
+	if err := util.OSSPutObject(s.endpoint, s.bucket, s.accessKey, s.secretKey, key, data); nil != err {
+		return "", err
+	}
+
+	publicURL := s.publicURL
+	if "" == publicURL {
+		u, err := url.Parse(s.endpoint)
+		if nil != err {
+			return "", err
+		}
+		publicURL = "https://" + s.bucket + "." + u.Host
+	}
+
+	return strings.TrimSuffix(publicURL, "/") + "/" + key, nil
+}
+
+func (s *ossStorage) get(key string) ([]byte, error) {
+	publicURL := s.publicURL
+	if "" == publicURL {
+		u, err := url.Parse(s.endpoint)
+		if nil != err {
+			return nil, err
+		}
+		publicURL = "https://" + s.bucket + "." + u.Host
+	}
+
+	return getPublicObject(publicURL, key)
+}
+
+func (s *ossStorage) remove(key string) error {
+	return util.OSSDeleteObject(s.endpoint, s.bucket, s.accessKey, s.secretKey, key)
+}
+
+// getPublicObject gets the object with the specified key from the bucket served at the specified public URL.
+func getPublicObject(publicURL, key string) ([]byte, error) {
+	if "" == publicURL {
+		return nil, errors.New("media URL is required for getting media")
+	}
+
+	response, data, errs := gorequest.New().Get(strings.TrimSuffix(publicURL, "/")+"/"+key).
+		Set("User-Agent", model.UserAgent).Timeout(30 * time.Second).EndBytes()
+	if nil != errs {
+		return nil, errs[0]
+	}
+	if http.StatusOK != response.StatusCode {
+		return nil, errors.New("get media [" + key + "] failed: " + response.Status)
+	}
+
+	return data, nil
+}
+
+// Enabled checks whether media storage is configured.
+func (srv *mediaService) Enabled() bool {
+	if storageType := Setting.GetSetting(model.SettingCategoryStorage, model.SettingNameStorageType, PlatformBlogID); nil != storageType &&
+		model.SettingStorageTypeValueConf != storageType.Value {
+		return true
+	}
+
+	return "" != model.Conf.MediaS3 || "" != model.Conf.MediaDir
+}
+
+// Put stores the specified data with the specified key (e.g. comments/201901/xxx.png), returns the public URL.
+func (srv *mediaService) Put(key string, data []byte) (string, error) {
+	if strings.Contains(key, "..") {
+		return "", errors.New("invalid media key [" + key + "]")
+	}
+
+	storage, err := srv.storage()
+	if nil != err {
+		return "", err
+	}
+
+	return storage.put(key, data)
+}
+
+// Get returns the data stored with the specified key.
+func (srv *mediaService) Get(key string) ([]byte, error) {
+	if strings.Contains(key, "..") {
+		return nil, errors.New("invalid media key [" + key + "]")
+	}
+
+	storage, err := srv.storage()
+	if nil != err {
+		return nil, err
+	}
+
+	return storage.get(key)
+}
+
+// Remove removes the data stored with the specified key.
+func (srv *mediaService) Remove(key string) error {
+	if strings.Contains(key, "..") {
+		return errors.New("invalid media key [" + key + "]")
+	}
+
+	storage, err := srv.storage()
+	if nil != err {
+		return err
+	}
+
+	return storage.remove(key)
+}
+
+// storage returns the media storage selected in the platform storage settings or configured in the configuration.
+func (srv *mediaService) storage() (mediaStorage, error) {
+	settings := Setting.GetSettings(model.SettingCategoryStorage, []string{
+		model.SettingNameStorageType, model.SettingNameStorageEndpoint, model.SettingNameStorageBucket,
+		model.SettingNameStorageRegion, model.SettingNameStorageAccessKey, model.SettingNameStorageSecretKey,
+		model.SettingNameStorageURL}, PlatformBlogID)
+	value := func(name string) string {
+		if setting := settings[name]; nil != setting {
+			return strings.TrimSpace(setting.Value)
+		}
+
+		return ""
+	}
+
+	switch value(model.SettingNameStorageType) {
+	case model.SettingStorageTypeValueS3:
+		bucketURL, err := s3BucketURL(value(model.SettingNameStorageEndpoint), value(model.SettingNameStorageBucket),
+			value(model.SettingNameStorageRegion), value(model.SettingNameStorageAccessKey), value(model.SettingNameStorageSecretKey))
+		if nil != err {
+			return nil, err
+		}
+		publicURL := value(model.SettingNameStorageURL)
+		if "" == publicURL {
+			publicURL = strings.TrimSuffix(value(model.SettingNameStorageEndpoint), "/") + "/" + value(model.SettingNameStorageBucket)
+		}
+
+		return &s3Storage{bucketURL: bucketURL, publicURL: publicURL}, nil
+	case model.SettingStorageTypeValueOSS:
+		if "" == value(model.SettingNameStorageEndpoint) || "" == value(model.SettingNameStorageBucket) {
+			return nil, errors.New("endpoint and bucket are required for storing media in OSS")
+		}
+
+		return &ossStorage{endpoint: value(model.SettingNameStorageEndpoint), bucket: value(model.SettingNameStorageBucket),
+			accessKey: value(model.SettingNameStorageAccessKey), secretKey: value(model.SettingNameStorageSecretKey),
+			publicURL: value(model.SettingNameStorageURL)}, nil
+	}
+
+	if "" != model.Conf.MediaS3 {
+		return &s3Storage{bucketURL: model.Conf.MediaS3, publicURL: model.Conf.MediaURL}, nil
+	}
+	if "" != model.Conf.MediaDir {
+		return &localStorage{dir: model.Conf.MediaDir}, nil
+	}
+
+	return nil, errors.New("media storage is not configured")
+}
+
+// s3BucketURL builds the bucket URL accepted by util.S3PutObject with the specified endpoint (e.g. http://minio:9000)
+// and credentials.
+func s3BucketURL(endpoint, bucket, region, accessKey, secretKey string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if nil != err {
+		return "", err
+	}
+	if "" == u.Host || "" == bucket {
+		return "", errors.New("endpoint and bucket are required for storing media in S3")
+	}
+
+	scheme := "s3"
+	if "http" == u.Scheme {
+		scheme = "http"
+	}
+	ret := &url.URL{Scheme: scheme, User: url.UserPassword(accessKey, secretKey), Host: u.Host, Path: "/" + bucket}
+	if "" != region {
+		ret.RawQuery = url.Values{"region": {region}}.Encode()
+	}
+
+	return ret.String(), nil
+}
@@ -0,0 +1,58 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestMediaStorage(t *testing.T) {
+	settings := []*model.Setting{
+		{Name: model.SettingNameStorageType, Value: model.SettingStorageTypeValueS3},
+		{Name: model.SettingNameStorageEndpoint, Value: "http://minio:9000"},
+		{Name: model.SettingNameStorageBucket, Value: "pipe"},
+		{Name: model.SettingNameStorageAccessKey, Value: "ak"},
+		{Name: model.SettingNameStorageSecretKey, Value: "sk"},
+	}
+	if err := Setting.UpdateSettings(model.SettingCategoryStorage, settings, PlatformBlogID); nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer removeSettings(model.SettingCategoryStorage, PlatformBlogID)
+
+	if !Media.Enabled() {
+		t.Error("media storage should be enabled")
+	}
+	storage, err := Media.storage()
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	s3, ok := storage.(*s3Storage)
+	if !ok {
+		t.Errorf("expected is S3 storage, actual is [%T]", storage)
+
+		return
+	}
+	if "http://ak:sk@minio:9000/pipe" != s3.bucketURL || "http://minio:9000/pipe" != s3.publicURL {
+		t.Errorf("unexpected S3 storage [%+v]", s3)
+	}
+}
@@ -0,0 +1,166 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package migrations manages versioned database schema migrations.
+package migrations
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// Logger
+var logger = gulu.Log.NewLogger(log.Writer)
+
+// Migration represents a versioned database migration.
+type Migration struct {
+	Version uint64               `json:"version"`
+	Name    string               `json:"name"`
+	Up      func(*gorm.DB) error `json:"-"`
+	Down    func(*gorm.DB) error `json:"-"`
+}
+
+// migrations holds all registered migrations.
+var migrations []*Migration
+
+func register(migration *Migration) {
+	migrations = append(migrations, migration)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+}
+
+// All returns all registered migrations in version order.
+func All() []*Migration {
+	return migrations
+}
+
+// Applied returns the applied migrations in version order.
+func Applied(db *gorm.DB) (ret []*model.Migration, err error) {
+	if err = db.AutoMigrate(&model.Migration{}).Error; nil != err {
+		return
+	}
+	err = db.Order("`version` ASC").Find(&ret).Error
+
+	return
+}
+
+// Version returns the latest applied migration version, 0 if none applied.
+func Version(db *gorm.DB) (uint64, error) {
+	applied, err := Applied(db)
+	if nil != err || 0 == len(applied) {
+		return 0, err
+	}
+
+	return applied[len(applied)-1].Version, nil
+}
+
+// Pending returns the migrations not applied yet.
+func Pending(db *gorm.DB) (ret []*Migration, err error) {
+	applied, err := Applied(db)
+	if nil != err {
+		return
+	}
+	versions := map[uint64]bool{}
+	for _, migration := range applied {
+		versions[migration.Version] = true
+	}
+	for _, migration := range migrations {
+		if !versions[migration.Version] {
+			ret = append(ret, migration)
+		}
+	}
+
+	return
+}
+
+// Up applies all pending migrations, each one in its own transaction.
+func Up(db *gorm.DB) error {
+	pending, err := Pending(db)
+	if nil != err {
+		return err
+	}
+
+	for _, migration := range pending {
+		logger.Infof("applying migration [%d %s]....", migration.Version, migration.Name)
+		tx := db.Begin()
+		if err := migration.Up(tx); nil != err {
+			tx.Rollback()
+
+			return errors.New("apply migration [" + name(migration) + "] failed: " + err.Error())
+		}
+		if err := tx.Create(&model.Migration{Version: migration.Version, Name: migration.Name}).Error; nil != err {
+			tx.Rollback()
+
+			return errors.New("record migration [" + name(migration) + "] failed: " + err.Error())
+		}
+		tx.Commit()
+		logger.Infof("applied migration [%d %s]", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// Down reverts the applied migrations newer than the specified version in reverse order.
+func Down(db *gorm.DB, version uint64) error {
+	applied, err := Applied(db)
+	if nil != err {
+		return err
+	}
+
+	registered := map[uint64]*Migration{}
+	for _, migration := range migrations {
+		registered[migration.Version] = migration
+	}
+	for i := len(applied) - 1; 0 <= i; i-- {
+		if applied[i].Version <= version {
+			break
+		}
+
+		migration := registered[applied[i].Version]
+		if nil == migration {
+			return errors.New("migration [" + strconv.FormatUint(applied[i].Version, 10) + "] is unknown")
+		}
+		if nil == migration.Down {
+			return errors.New("migration [" + name(migration) + "] is irreversible")
+		}
+
+		logger.Infof("reverting migration [%d %s]....", migration.Version, migration.Name)
+		tx := db.Begin()
+		if err := migration.Down(tx); nil != err {
+			tx.Rollback()
+
+			return errors.New("revert migration [" + name(migration) + "] failed: " + err.Error())
+		}
+		if err := tx.Unscoped().Where("`version` = ?", migration.Version).Delete(&model.Migration{}).Error; nil != err {
+			tx.Rollback()
+
+			return errors.New("remove migration record [" + name(migration) + "] failed: " + err.Error())
+		}
+		tx.Commit()
+		logger.Infof("reverted migration [%d %s]", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+func name(migration *Migration) string {
+	return strconv.FormatUint(migration.Version, 10) + " " + migration.Name
+}
@@ -0,0 +1,47 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// Inserts the editor role between blog admin and blog user (author), roles of existing authors and pending
+// invitations are shifted from 3 to 4.
+func init() {
+	register(&Migration{
+		Version: 11,
+		Name:    "editor role",
+		Up: func(tx *gorm.DB) error {
+			return shiftRole(tx, 3, 4)
+		},
+		Down: func(tx *gorm.DB) error {
+			// editors fall back to authors
+			return shiftRole(tx, 4, 3)
+		},
+	})
+}
+
+func shiftRole(tx *gorm.DB, from, to int) error {
+	if err := tx.Model(&model.Correlation{}).Where("`type` = ? AND `int1` >= ?", model.CorrelationBlogUser, from).
+		Update("int1", to).Error; nil != err {
+		return err
+	}
+
+	return tx.Model(&model.Invitation{}).Where("`role` >= ?", from).Update("role", to).Error
+}
@@ -0,0 +1,42 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// Adds the WebP and AVIF variant columns of images.
+func init() {
+	register(&Migration{
+		Version: 17,
+		Name:    "image variants",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.Image{}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"webp_url", "avif_url"} {
+				if err := tx.Model(&model.Image{}).DropColumn(column).Error; nil != err {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
 )
@@ -50,6 +51,7 @@ func (srv *navigationService) AddNavigation(navigation *model.Navigation) error
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(navigation.BlogID)
 
 	return nil
 }
@@ -72,6 +74,7 @@ func (srv *navigationService) RemoveNavigation(id, blogID uint64) error {
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(blogID)
 
 	return nil
 }
@@ -97,6 +100,7 @@ func (srv *navigationService) UpdateNavigation(navigation *model.Navigation) err
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(navigation.BlogID)
 
 	return nil
 }
@@ -107,7 +111,7 @@ func (srv *navigationService) ConsoleGetNavigations(page int, blogID uint64) (re
 	if err := db.Model(&model.Navigation{}).Order("`number` ASC, `id` DESC").
 		Where("`blog_id` = ?", blogID).
 		Count(&count).Offset(offset).Limit(adminConsoleNavigationListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get navigations failed: " + err.Error())
+		logger.Errorf("get navigations failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, adminConsoleNavigationListPageSize, adminConsoleNavigationListWindowSize, count)
@@ -118,7 +122,7 @@ func (srv *navigationService) ConsoleGetNavigations(page int, blogID uint64) (re
 func (srv *navigationService) GetNavigations(blogID uint64) (ret []*model.Navigation) {
 	if err := db.Model(&model.Navigation{}).Order("`number` ASC, `id` DESC").
 		Where("`blog_id` = ?", blogID).Find(&ret).Error; nil != err {
-		logger.Errorf("get navigations failed: " + err.Error())
+		logger.Errorf("get navigations failed: %s", err)
 	}
 
 	return
@@ -0,0 +1,243 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"html"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/i18n"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Newsletter service, manages email subscribers and mails new articles to them.
+var Newsletter = &newsletterService{
+	mutex: &sync.Mutex{},
+}
+
+type newsletterService struct {
+	mutex *sync.Mutex
+}
+
+// Errors of subscribing.
+var (
+	ErrInvalidEmail        = errors.New("invalid email")
+	ErrInvalidSubscription = errors.New("subscription is invalid or has been cancelled")
+)
+
+// newsletterWeeklyInterval is the interval of weekly digests.
+const newsletterWeeklyInterval = 7 * 24 * time.Hour
+
+// GetMode gets the newsletter mode of the specified blog.
+func (srv *newsletterService) GetMode(blogID uint64) string {
+	modeSetting := Setting.GetSetting(model.SettingCategoryNewsletter, model.SettingNameNewsletterMode, blogID)
+	if nil == modeSetting || "" == modeSetting.Value {
+		return model.SettingNewsletterModeOff
+	}
+
+	return modeSetting.Value
+}
+
+// Enabled checks whether the specified blog accepts subscribers, which requires the SMTP server to be configured.
+func (srv *newsletterService) Enabled(blogID uint64) bool {
+	return Mail.Enabled() && model.SettingNewsletterModeOff != srv.GetMode(blogID)
+}
+
+// GetNewsletterBlogIDs gets IDs of blogs which enabled newsletter.
+func (srv *newsletterService) GetNewsletterBlogIDs() (ret []uint64) {
+	var settings []*model.Setting
+	if err := db.Where("`category` = ? AND `name` = ? AND `value` IN (?)", model.SettingCategoryNewsletter,
+		model.SettingNameNewsletterMode, []string{model.SettingNewsletterModeNew, model.SettingNewsletterModeWeekly}).
+		Find(&settings).Error; nil != err {
+		logger.Errorf("get newsletter blogs failed: %s", err)
+
+		return
+	}
+
+	for _, setting := range settings {
+		ret = append(ret, setting.BlogID)
+	}
+
+	return
+}
+
+// Subscribe adds an unconfirmed subscriber with the specified email, the existing one is returned if the email has
+// subscribed the specified blog.
+func (srv *newsletterService) Subscribe(email string, blogID uint64) (*model.Subscriber, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if nil != err {
+		return nil, ErrInvalidEmail
+	}
+	email = strings.ToLower(address.Address)
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ret := &model.Subscriber{}
+	if err := db.Where("`email` = ? AND `blog_id` = ?", email, blogID).First(ret).Error; nil == err {
+		return ret, nil
+	}
+
+	ret = &model.Subscriber{
+		Email:  email,
+		Token:  gulu.Rand.String(32),
+		SentAt: time.Now(),
+		BlogID: blogID,
+	}
+	if err := db.Create(ret).Error; nil != err {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Confirm confirms the subscriber with the specified token, articles will be mailed since now.
+func (srv *newsletterService) Confirm(token string) (*model.Subscriber, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ret := &model.Subscriber{}
+	if err := db.Where("`token` = ?", token).First(ret).Error; nil != err {
+		return nil, ErrInvalidSubscription
+	}
+	if ret.Confirmed {
+		return ret, nil
+	}
+	if err := db.Model(ret).Updates(map[string]interface{}{"confirmed": true, "sent_at": time.Now()}).Error; nil != err {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Unsubscribe removes the subscriber with the specified token.
+func (srv *newsletterService) Unsubscribe(token string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	subscriber := &model.Subscriber{}
+	if err := db.Where("`token` = ?", token).First(subscriber).Error; nil != err {
+		return ErrInvalidSubscription
+	}
+
+	return db.Delete(subscriber).Error
+}
+
+// GetSubscriberCount gets the count of confirmed subscribers of the specified blog.
+func (srv *newsletterService) GetSubscriberCount(blogID uint64) (ret int) {
+	if err := db.Model(&model.Subscriber{}).Where("`blog_id` = ? AND `confirmed` = ?", blogID, true).
+		Count(&ret).Error; nil != err {
+		logger.Errorf("count subscribers failed: %s", err)
+	}
+
+	return
+}
+
+// SendConfirmation mails the double opt-in confirmation link to the specified subscriber.
+func (srv *newsletterService) SendConfirmation(subscriber *model.Subscriber) error {
+	locale, blogTitle, blogURL := srv.getBlogInfo(subscriber.BlogID)
+	confirmURL := blogURL + util.PathSubscribe + "/confirm?token=" + subscriber.Token
+	subject := i18n.GetMessagef(locale, "subscribeMailSubject", blogTitle)
+	body := i18n.GetMessagef(locale, "subscribeMailBody", html.EscapeString(blogTitle), confirmURL, confirmURL)
+
+	return Mail.Send([]string{subscriber.Email}, subject, body)
+}
+
+// SendNewsletter mails new articles of the specified blog to its confirmed subscribers. In mode "new" articles are
+// mailed once they are published, in mode "weekly" subscribers get at most one digest every week.
+func (srv *newsletterService) SendNewsletter(blogID uint64) {
+	mode := srv.GetMode(blogID)
+	if model.SettingNewsletterModeOff == mode || !Mail.Enabled() {
+		return
+	}
+
+	now := time.Now()
+	var subscribers []*model.Subscriber
+	where := "`blog_id` = ? AND `confirmed` = ?"
+	whereArgs := []interface{}{blogID, true}
+	if model.SettingNewsletterModeWeekly == mode {
+		where += " AND `sent_at` <= ?"
+		whereArgs = append(whereArgs, now.Add(-newsletterWeeklyInterval))
+	}
+	if err := db.Where(where, whereArgs...).Order("`sent_at` ASC").Find(&subscribers).Error; nil != err {
+		logger.Errorf("get subscribers of blog [%d] failed: %s", blogID, err)
+
+		return
+	}
+	if 1 > len(subscribers) {
+		return
+	}
+
+	var articles []*model.Article
+	if err := db.Where("`blog_id` = ? AND `status` = ? AND `visibility` = ? AND `created_at` > ? AND `created_at` <= ?",
+		blogID, model.ArticleStatusOK, model.ArticleVisibilityPublic, subscribers[0].SentAt, now).
+		Order("`created_at` ASC").Find(&articles).Error; nil != err {
+		logger.Errorf("get newsletter articles of blog [%d] failed: %s", blogID, err)
+
+		return
+	}
+	if 1 > len(articles) {
+		return
+	}
+
+	locale, blogTitle, blogURL := srv.getBlogInfo(blogID)
+	subjectKey := "newsletterMailSubject"
+	if model.SettingNewsletterModeWeekly == mode {
+		subjectKey = "newsletterDigestMailSubject"
+	}
+	for _, subscriber := range subscribers {
+		items := ""
+		var mailed []*model.Article
+		for _, article := range articles {
+			if !article.CreatedAt.After(subscriber.SentAt) {
+				continue
+			}
+			mailed = append(mailed, article)
+			items += "<li><a href=\"" + blogURL + article.Path + "\">" + html.EscapeString(article.Title) + "</a></li>"
+		}
+		if 1 > len(mailed) {
+			continue
+		}
+
+		subject := i18n.GetMessagef(locale, subjectKey, blogTitle)
+		if model.SettingNewsletterModeNew == mode && 1 == len(mailed) {
+			subject = mailed[0].Title + " - " + blogTitle
+		}
+		unsubscribeURL := blogURL + util.PathUnsubscribe + "?token=" + subscriber.Token
+		body := "<ul>" + items + "</ul>" + i18n.GetMessagef(locale, "newsletterMailFooter", unsubscribeURL)
+		if err := Mail.Send([]string{subscriber.Email}, subject, body); nil != err {
+			continue
+		}
+		if err := db.Model(subscriber).Update("sent_at", now).Error; nil != err {
+			logger.Errorf("update sent at of subscriber [id=%d] failed: %s", subscriber.ID, err)
+		}
+	}
+}
+
+func (srv *newsletterService) getBlogInfo(blogID uint64) (locale, blogTitle, blogURL string) {
+	locale = Setting.GetSetting(model.SettingCategoryI18n, model.SettingNameI18nLocale, blogID).Value
+	blogTitle = Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, blogID).Value
+	blogURL = Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID).Value
+
+	return
+}
@@ -0,0 +1,63 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	if _, err := Newsletter.Subscribe("invalid", 1); ErrInvalidEmail != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidEmail, err)
+	}
+
+	subscriber, err := Newsletter.Subscribe(" Reader@Example.com ", 1)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "reader@example.com" != subscriber.Email || subscriber.Confirmed {
+		t.Errorf("unexpected subscriber [%+v]", subscriber)
+	}
+	again, _ := Newsletter.Subscribe("reader@example.com", 1)
+	if nil == again || subscriber.ID != again.ID {
+		t.Error("subscribe twice should return the existing subscriber")
+	}
+	if 0 != Newsletter.GetSubscriberCount(1) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, Newsletter.GetSubscriberCount(1))
+	}
+
+	if _, err := Newsletter.Confirm(subscriber.Token); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if 1 != Newsletter.GetSubscriberCount(1) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, Newsletter.GetSubscriberCount(1))
+	}
+
+	if err := Newsletter.Unsubscribe(subscriber.Token); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := Newsletter.Unsubscribe(subscriber.Token); ErrInvalidSubscription != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidSubscription, err)
+	}
+	if 0 != Newsletter.GetSubscriberCount(1) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, Newsletter.GetSubscriberCount(1))
+	}
+}
@@ -0,0 +1,137 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/b3log/pipe/model"
+)
+
+// notificationBufferSize is the count of notifications buffered for a subscriber, notifications are dropped if the
+// subscriber falls behind.
+const notificationBufferSize = 16
+
+// Notification service.
+var Notification = &notificationService{
+	subscribers: map[uint64]map[chan *model.Notification]uint64{},
+	mutex:       &sync.RWMutex{},
+}
+
+type notificationService struct {
+	subscribers map[uint64]map[chan *model.Notification]uint64 // blog ID -> channel -> user ID
+	mutex       *sync.RWMutex
+}
+
+// Subscribe subscribes notifications of the specified blog for the specified user. The returned channel must be
+// unsubscribed once the subscriber leaves.
+func (srv *notificationService) Subscribe(userID, blogID uint64) chan *model.Notification {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ret := make(chan *model.Notification, notificationBufferSize)
+	if nil == srv.subscribers[blogID] {
+		srv.subscribers[blogID] = map[chan *model.Notification]uint64{}
+	}
+	srv.subscribers[blogID][ret] = userID
+
+	return ret
+}
+
+// Unsubscribe unsubscribes the specified channel from notifications of the specified blog and closes it.
+func (srv *notificationService) Unsubscribe(ch chan *model.Notification, blogID uint64) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if _, ok := srv.subscribers[blogID][ch]; !ok {
+		return
+	}
+	delete(srv.subscribers[blogID], ch)
+	if 0 == len(srv.subscribers[blogID]) {
+		delete(srv.subscribers, blogID)
+	}
+	close(ch)
+}
+
+// Publish sends the specified notification to subscribers of its blog without blocking.
+func (srv *notificationService) Publish(notification *model.Notification) {
+	srv.mutex.RLock()
+	defer srv.mutex.RUnlock()
+
+	for ch, userID := range srv.subscribers[notification.BlogID] {
+		if 0 != notification.UserID && userID != notification.UserID {
+			continue
+		}
+
+		select {
+		case ch <- notification:
+		default:
+			logger.Warnf("notification [%s] to user [%d] is dropped", notification.Type, userID)
+		}
+	}
+}
+
+// NotifyComment notifies moderators of the blog if the specified comment is held for moderation, notifies the article
+// author and the replied comment author otherwise.
+func (srv *notificationService) NotifyComment(comment *model.Comment) {
+	article := Article.ConsoleGetArticle(comment.ArticleID)
+	if nil == article {
+		return
+	}
+
+	data := map[string]interface{}{
+		"articleID":    article.ID,
+		"articleTitle": article.Title,
+		"commentID":    comment.ID,
+		"authorName":   comment.AuthorName,
+	}
+	if "" == comment.AuthorName {
+		if author := User.GetUser(comment.AuthorID); nil != author {
+			data["authorName"] = author.Name
+		}
+	}
+
+	if model.CommentStatusOK != comment.Status {
+		srv.Publish(&model.Notification{Type: model.NotificationTypeCommentPending, BlogID: comment.BlogID, Data: data})
+
+		return
+	}
+
+	if article.AuthorID != comment.AuthorID {
+		srv.Publish(&model.Notification{Type: model.NotificationTypeCommentNew, BlogID: comment.BlogID,
+			UserID: article.AuthorID, Data: data})
+	}
+	if 0 == comment.ParentCommentID {
+		return
+	}
+	parent := Comment.GetComment(comment.ParentCommentID)
+	if nil == parent || model.SyncCommentAuthorID == parent.AuthorID || comment.AuthorID == parent.AuthorID ||
+		article.AuthorID == parent.AuthorID {
+		return
+	}
+	srv.Publish(&model.Notification{Type: model.NotificationTypeCommentReply, BlogID: comment.BlogID,
+		UserID: parent.AuthorID, Data: data})
+}
+
+// NotifyImportFinished notifies the specified user who started an import that it's finished.
+func (srv *notificationService) NotifyImportFinished(succeeded, failed int, userID, blogID uint64) {
+	srv.Publish(&model.Notification{Type: model.NotificationTypeImportFinished, BlogID: blogID, UserID: userID,
+		Data: map[string]interface{}{
+			"succeeded": succeeded,
+			"failed":    failed,
+		}})
+}
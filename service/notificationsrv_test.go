@@ -0,0 +1,51 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestPublishNotification(t *testing.T) {
+	all := Notification.Subscribe(1, 7)
+	other := Notification.Subscribe(2, 7)
+	defer Notification.Unsubscribe(all, 7)
+	defer Notification.Unsubscribe(other, 7)
+
+	Notification.Publish(&model.Notification{Type: model.NotificationTypeCommentPending, BlogID: 7})
+	Notification.NotifyImportFinished(3, 1, 1, 7)
+
+	if n := len(all); 2 != n {
+		t.Errorf("expected is [%d], actual is [%d]", 2, n)
+	}
+	if n := len(other); 1 != n {
+		t.Errorf("expected is [%d], actual is [%d]", 1, n)
+	}
+	<-all
+	if notification := <-all; model.NotificationTypeImportFinished != notification.Type || 3 != notification.Data["succeeded"] {
+		t.Errorf("unexpected notification [%+v]", notification)
+	}
+
+	for i := 0; i < notificationBufferSize+1; i++ {
+		Notification.Publish(&model.Notification{Type: model.NotificationTypeCommentPending, BlogID: 7})
+	}
+	if n := len(other); notificationBufferSize != n {
+		t.Errorf("expected is [%d], actual is [%d]", notificationBufferSize, n)
+	}
+}
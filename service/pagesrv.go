@@ -0,0 +1,176 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Page service, manages static pages served at top-level paths of blogs.
+var Page = &pageService{
+	mutex: &sync.Mutex{},
+}
+
+type pageService struct {
+	mutex *sync.Mutex
+}
+
+// Page pagination arguments of admin console.
+const (
+	adminConsolePageListPageSize   = 15
+	adminConsolePageListWindowSize = 20
+)
+
+// AddPage adds the specified page.
+func (srv *pageService) AddPage(page *model.Page) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if err := normalizePage(page); nil != err {
+		return err
+	}
+	if err := db.Create(page).Error; nil != err {
+		return err
+	}
+	cache.Page.Purge(page.BlogID)
+
+	return nil
+}
+
+// UpdatePage updates the title, content, path and status of the specified page.
+func (srv *pageService) UpdatePage(page *model.Page) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	count := 0
+	if db.Model(&model.Page{}).Where("`id` = ? AND `blog_id` = ?", page.ID, page.BlogID).
+		Count(&count); 1 > count {
+		return fmt.Errorf("not found page [id=%d] to update", page.ID)
+	}
+	if err := normalizePage(page); nil != err {
+		return err
+	}
+
+	if err := db.Model(page).Updates(map[string]interface{}{
+		"Title":   page.Title,
+		"Content": page.Content,
+		"Path":    page.Path,
+		"Status":  page.Status}).Error; nil != err {
+		return err
+	}
+	cache.Page.Purge(page.BlogID)
+
+	return nil
+}
+
+// RemovePage removes the page of the specified ID.
+func (srv *pageService) RemovePage(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if err := db.Where("`id` = ? AND `blog_id` = ?", id, blogID).Delete(&model.Page{}).Error; nil != err {
+		return err
+	}
+	cache.Page.Purge(blogID)
+
+	return nil
+}
+
+// ConsoleGetPages returns the pages of the specified blog ordered by path.
+func (srv *pageService) ConsoleGetPages(page int, blogID uint64) (ret []*model.Page, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsolePageListPageSize
+	count := 0
+	if err := db.Model(&model.Page{}).Select("`id`, `created_at`, `author_id`, `title`, `path`, `status`, `blog_id`").
+		Where("`blog_id` = ?", blogID).Order("`path` ASC").
+		Count(&count).Offset(offset).Limit(adminConsolePageListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get pages failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsolePageListPageSize, adminConsolePageListWindowSize, count)
+
+	return
+}
+
+// ConsoleGetPage returns the page of the specified ID, returns nil if not found.
+func (srv *pageService) ConsoleGetPage(id uint64) *model.Page {
+	ret := &model.Page{}
+	if err := db.First(ret, id).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// GetPageByPath returns the published page of the specified path, returns nil if not found.
+func (srv *pageService) GetPageByPath(path string, blogID uint64) *model.Page {
+	ret := &model.Page{}
+	if err := db.Where("`path` = ? AND `status` = ? AND `blog_id` = ?", path, model.ArticleStatusOK, blogID).
+		First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// normalizePage trims the title and content of the specified page and checks its path, which has to be a top-level
+// path not reserved by the router nor used by an article or another page. The path is generated from the title if
+// not specified.
+func normalizePage(page *model.Page) error {
+	page.Title = strings.TrimSpace(page.Title)
+	if "" == page.Title {
+		return errors.New("title can not be empty")
+	}
+	page.Content = strings.TrimSpace(page.Content)
+	if "" == page.Content {
+		return errors.New("content can not be empty")
+	}
+	if model.ArticleStatusOK != page.Status {
+		page.Status = model.ArticleStatusDraft
+	}
+
+	path := strings.Trim(strings.TrimSpace(page.Path), "/")
+	if "" == path {
+		if slug := articleSlug(&model.Article{Title: page.Title}); "0" != slug { // "0" if no ASCII word in the title
+			path = slug
+		}
+	}
+	if "" == path || strings.Contains(path, "/") {
+		return errors.New("path of a page should be a top-level path such as /about")
+	}
+	path = "/" + path
+	if util.IsReservedPath(path) {
+		return ErrReservedPath
+	}
+
+	count := 0
+	if db.Model(&model.Page{}).Where("`path` = ? AND `id` != ? AND `blog_id` = ?", path, page.ID, page.BlogID).Count(&count); 0 < count {
+		return errors.New("path [" + path + "] is reduplicated")
+	}
+	if db.Model(&model.Article{}).Where("`path` = ? AND `blog_id` = ?", path, page.BlogID).Count(&count); 0 < count {
+		return errors.New("path [" + path + "] is used by an article")
+	}
+	page.Path = path
+
+	return nil
+}
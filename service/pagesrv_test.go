@@ -0,0 +1,67 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestAddPage(t *testing.T) {
+	page := &model.Page{
+		AuthorID: 1,
+		Title:    "About Me",
+		Content:  "Hello",
+		Status:   model.ArticleStatusOK,
+		BlogID:   1,
+	}
+	if err := Page.AddPage(page); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "/about-me" != page.Path {
+		t.Errorf("expected is [%s], actual is [%s]", "/about-me", page.Path)
+	}
+	if p := Page.GetPageByPath("/about-me", 1); nil == p || page.ID != p.ID {
+		t.Error("should get the page by its path")
+	}
+
+	dup := &model.Page{Title: "About", Content: "Dup", Path: "about-me/", BlogID: 1}
+	if err := Page.AddPage(dup); nil == err {
+		t.Error("path of a page should not be reduplicated")
+	}
+	reserved := &model.Page{Title: "Admin", Content: "Reserved", Path: "/admin", BlogID: 1}
+	if err := Page.AddPage(reserved); ErrReservedPath != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrReservedPath, err)
+	}
+
+	page.Status = model.ArticleStatusDraft
+	if err := Page.UpdatePage(page); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if nil != Page.GetPageByPath("/about-me", 1) {
+		t.Error("draft page should not be got by its path")
+	}
+
+	if err := Page.RemovePage(page.ID, 1); nil != err {
+		t.Error(err)
+	}
+}
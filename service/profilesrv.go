@@ -0,0 +1,170 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
+	"github.com/jinzhu/gorm"
+)
+
+// Profile service.
+var Profile = &profileService{
+	mutex: &sync.Mutex{},
+}
+
+type profileService struct {
+	mutex *sync.Mutex
+}
+
+// SettingsProfileVersion is the format version of settings profiles.
+const SettingsProfileVersion = 1
+
+// ErrProfileVersion is returned when importing a settings profile of an unsupported format version.
+var ErrProfileVersion = errors.New("unsupported settings profile version")
+
+// SettingsProfile is a portable snapshot of settings and navigations of a blog, could be imported into another blog
+// or instance.
+type SettingsProfile struct {
+	Version     int                          `json:"version"`
+	PipeVersion string                       `json:"pipeVersion"`
+	Settings    map[string]map[string]string `json:"settings"` // category -> name -> value
+	Navigations []*model.Navigation          `json:"navigations"`
+}
+
+// profileCategories are setting categories included in settings profiles.
+var profileCategories = []string{
+	model.SettingCategoryBasic, model.SettingCategoryPreference, model.SettingCategorySign, model.SettingCategoryI18n,
+	model.SettingCategoryFeed, model.SettingCategoryTheme,
+}
+
+// isProfileSetting checks whether the specified setting is portable. The blog URL and per-author signs are bound to
+// the blog and its users.
+func isProfileSetting(category, name string) bool {
+	if !contains(profileCategories, category) {
+		return false
+	}
+	if model.SettingNameBasicBlogURL == name {
+		return false
+	}
+	if model.SettingCategorySign == category && model.SettingNameArticleSign != name {
+		return false
+	}
+
+	return true
+}
+
+// ExportProfile exports settings and navigations of the specified blog.
+func (srv *profileService) ExportProfile(blogID uint64) *SettingsProfile {
+	ret := &SettingsProfile{
+		Version:     SettingsProfileVersion,
+		PipeVersion: model.Version,
+		Settings:    map[string]map[string]string{},
+	}
+	for _, setting := range Setting.GetAllSettings(blogID) {
+		if !isProfileSetting(setting.Category, setting.Name) {
+			continue
+		}
+		if nil == ret.Settings[setting.Category] {
+			ret.Settings[setting.Category] = map[string]string{}
+		}
+		ret.Settings[setting.Category][setting.Name] = setting.Value
+	}
+	for _, navigation := range Navigation.GetNavigations(blogID) {
+		ret.Navigations = append(ret.Navigations, &model.Navigation{
+			Title:      navigation.Title,
+			URL:        navigation.URL,
+			IconURL:    navigation.IconURL,
+			OpenMethod: navigation.OpenMethod,
+			Number:     navigation.Number,
+		})
+	}
+
+	return ret
+}
+
+// ImportProfile imports the specified settings profile into the specified blog. Settings not portable are ignored,
+// the theme is kept if the one of the profile is not available, navigations of the blog are replaced if the profile
+// has navigations.
+func (srv *profileService) ImportProfile(profile *SettingsProfile, blogID uint64) (err error) {
+	if SettingsProfileVersion != profile.Version {
+		return ErrProfileVersion
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	var imported []*model.Setting
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			for _, setting := range imported {
+				cache.Setting.Put(setting)
+			}
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	for category, settings := range profile.Settings {
+		for name, value := range settings {
+			if !isProfileSetting(category, name) {
+				continue
+			}
+			if model.SettingNameThemeName == name && !theme.IsCompatible(value) {
+				logger.Warnf("theme [%s] of the imported settings profile is not available", value)
+
+				continue
+			}
+
+			setting := &model.Setting{}
+			if err = tx.Where("`category` = ? AND `name` = ? AND `blog_id` = ?", category, name, blogID).First(setting).Error; nil != err {
+				if gorm.ErrRecordNotFound != err {
+					return
+				}
+				setting = &model.Setting{Category: category, Name: name, Value: value, BlogID: blogID}
+				if err = tx.Create(setting).Error; nil != err {
+					return
+				}
+			} else if err = tx.Model(setting).Update("value", value).Error; nil != err {
+				return
+			}
+			imported = append(imported, setting)
+		}
+	}
+
+	if 0 < len(profile.Navigations) {
+		if err = tx.Where("`blog_id` = ?", blogID).Delete(&model.Navigation{}).Error; nil != err {
+			return
+		}
+		for _, navigation := range profile.Navigations {
+			navigation.ID = 0
+			navigation.BlogID = blogID
+			if err = tx.Create(navigation).Error; nil != err {
+				return
+			}
+		}
+	}
+
+	return nil // trigger commit in the defer
+}
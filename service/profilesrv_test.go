@@ -0,0 +1,56 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestImportProfile(t *testing.T) {
+	navigation := &model.Navigation{Title: "Profile", URL: "https://b3log.org", BlogID: 1}
+	if err := Navigation.AddNavigation(navigation); nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer Navigation.RemoveNavigation(navigation.ID, 1)
+
+	profile := Profile.ExportProfile(1)
+	if _, ok := profile.Settings[model.SettingCategoryBasic][model.SettingNameBasicBlogURL]; ok {
+		t.Error("blog URL should not be exported")
+	}
+	if err := Profile.ImportProfile(profile, 3); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	title := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, 1).Value
+	if setting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, 3); nil == setting || title != setting.Value {
+		t.Error("blog title should be imported")
+	}
+	if len(Navigation.GetNavigations(1)) != len(Navigation.GetNavigations(3)) {
+		t.Errorf("expected is [%d], actual is [%d]", len(Navigation.GetNavigations(1)), len(Navigation.GetNavigations(3)))
+	}
+
+	profile.Version = 0
+	if err := Profile.ImportProfile(profile, 3); ErrProfileVersion != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrProfileVersion, err)
+	}
+}
@@ -0,0 +1,172 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Reader service, manages blogs followed by users and the timeline of their new articles.
+var Reader = &readerService{
+	mutex: &sync.Mutex{},
+}
+
+type readerService struct {
+	mutex *sync.Mutex
+}
+
+// Timeline pagination arguments.
+const (
+	readerTimelinePageSize   = 20
+	readerTimelineWindowSize = 20
+)
+
+// Follow makes the specified user follow the specified blog. It does nothing if followed already.
+func (srv *readerService) Follow(userID, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if srv.IsFollowing(userID, blogID) {
+		return nil
+	}
+
+	rel := &model.Correlation{
+		ID1:    blogID,
+		ID2:    userID,
+		Type:   model.CorrelationBlogReader,
+		BlogID: blogID,
+	}
+
+	return db.Create(rel).Error
+}
+
+// Unfollow makes the specified user unfollow the specified blog.
+func (srv *readerService) Unfollow(userID, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Where("`id1` = ? AND `id2` = ? AND `type` = ?", blogID, userID, model.CorrelationBlogReader).
+		Delete(&model.Correlation{}).Error
+}
+
+// IsFollowing checks whether the specified user follows the specified blog.
+func (srv *readerService) IsFollowing(userID, blogID uint64) bool {
+	count := 0
+	if err := db.Model(&model.Correlation{}).Where("`id1` = ? AND `id2` = ? AND `type` = ?",
+		blogID, userID, model.CorrelationBlogReader).Count(&count).Error; nil != err {
+		logger.Errorf("check following of user [%d] failed: %s", userID, err)
+
+		return false
+	}
+
+	return 0 < count
+}
+
+// GetFollowingBlogs returns the blogs followed by the specified user.
+func (srv *readerService) GetFollowingBlogs(userID uint64) (ret []*UserBlog) {
+	for _, blogID := range srv.getFollowingBlogIDs(userID) {
+		blogTitleSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, blogID)
+		blogURLSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+		if nil == blogTitleSetting || nil == blogURLSetting {
+			continue
+		}
+
+		ret = append(ret, &UserBlog{
+			ID:     blogID,
+			Title:  blogTitleSetting.Value,
+			URL:    blogURLSetting.Value,
+			UserID: userID,
+		})
+	}
+
+	return
+}
+
+// GetTimeline returns the published articles of the blogs followed by the specified user, the latest first.
+func (srv *readerService) GetTimeline(userID uint64, page int) (ret []*model.Article, pagination *util.Pagination) {
+	offset := (page - 1) * readerTimelinePageSize
+	count := 0
+
+	blogIDs := srv.getFollowingBlogIDs(userID)
+	if 0 < len(blogIDs) {
+		if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `updated_at`, `author_id`, `title`, `abstract`, `content`, `tags`, `path`, `view_count`, `comment_count`, `blog_id`").
+			Where("`status` = ? AND `visibility` = ? AND `blog_id` IN (?)", model.ArticleStatusOK, model.ArticleVisibilityPublic, blogIDs).
+			Order("`created_at` DESC").Count(&count).
+			Offset(offset).Limit(readerTimelinePageSize).Find(&ret).Error; nil != err {
+			logger.Errorf("get timeline of user [%d] failed: %s", userID, err)
+		}
+	}
+
+	pagination = util.NewPagination(page, readerTimelinePageSize, readerTimelineWindowSize, count)
+
+	return
+}
+
+// GetFeedToken returns the token of the timeline feed of the specified user, generates one if not exists.
+func (srv *readerService) GetFeedToken(userID uint64) (string, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user := &model.User{}
+	if err := db.First(user, userID).Error; nil != err {
+		return "", err
+	}
+	if "" != user.FeedToken {
+		return user.FeedToken, nil
+	}
+
+	token := gulu.Rand.String(32)
+	if err := db.Model(user).UpdateColumn("FeedToken", token).Error; nil != err {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetUserByFeedToken returns the user of the specified timeline feed token, returns nil if not found.
+func (srv *readerService) GetUserByFeedToken(token string) *model.User {
+	if "" == token {
+		return nil
+	}
+
+	ret := &model.User{}
+	if err := db.Where("`feed_token` = ?", token).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+func (srv *readerService) getFollowingBlogIDs(userID uint64) (ret []uint64) {
+	var correlations []*model.Correlation
+	if err := db.Where("`id2` = ? AND `type` = ?", userID, model.CorrelationBlogReader).
+		Order("`id` DESC").Find(&correlations).Error; nil != err {
+		logger.Errorf("get following blogs of user [%d] failed: %s", userID, err)
+
+		return
+	}
+
+	for _, rel := range correlations {
+		ret = append(ret, rel.ID1)
+	}
+
+	return
+}
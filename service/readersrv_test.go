@@ -0,0 +1,72 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+)
+
+func TestFollow(t *testing.T) {
+	const readerID = uint64(4242)
+	if err := Reader.Follow(readerID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := Reader.Follow(readerID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if !Reader.IsFollowing(readerID, 1) {
+		t.Error("reader should follow the blog")
+	}
+	if blogs := Reader.GetFollowingBlogs(readerID); 1 != len(blogs) || 1 != blogs[0].ID {
+		t.Errorf("unexpected following blogs [%+v]", blogs)
+	}
+	_, blogPagination := Article.GetArticles("", 1, 1)
+	if _, pagination := Reader.GetTimeline(readerID, 1); blogPagination.RecordCount != pagination.RecordCount {
+		t.Errorf("expected is [%d], actual is [%d]", blogPagination.RecordCount, pagination.RecordCount)
+	}
+
+	if err := Reader.Unfollow(readerID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if articles, _ := Reader.GetTimeline(readerID, 1); 0 != len(articles) {
+		t.Errorf("expected is [%d], actual is [%d]", 0, len(articles))
+	}
+}
+
+func TestGetFeedToken(t *testing.T) {
+	token, err := Reader.GetFeedToken(1)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if again, _ := Reader.GetFeedToken(1); token != again {
+		t.Errorf("expected is [%s], actual is [%s]", token, again)
+	}
+	if user := Reader.GetUserByFeedToken(token); nil == user || 1 != user.ID {
+		t.Errorf("unexpected user [%+v]", user)
+	}
+	if nil != Reader.GetUserByFeedToken("") {
+		t.Error("empty token should not match any user")
+	}
+}
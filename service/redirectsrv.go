@@ -0,0 +1,206 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// Redirect service.
+var Redirect = &redirectService{
+	mutex:        &sync.Mutex{},
+	unknownPaths: map[uint64]map[string]*unknownPathHits{},
+}
+
+type redirectService struct {
+	mutex *sync.Mutex
+
+	unknownPaths map[uint64]map[string]*unknownPathHits // blog ID -> unknown path -> hits
+}
+
+// unknownPathHits represents hits of an unknown path.
+type unknownPathHits struct {
+	count   int
+	lastHit time.Time
+}
+
+// maxUnknownPaths is the max count of unknown paths counted for each blog.
+const maxUnknownPaths = 1024
+
+// UnknownPath represents a requested path which can't be handled.
+type UnknownPath struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// AddUnknownPath counts a hit of the specified unknown path.
+func (srv *redirectService) AddUnknownPath(path string, blogID uint64) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	paths := srv.unknownPaths[blogID]
+	if nil == paths {
+		paths = map[string]*unknownPathHits{}
+		srv.unknownPaths[blogID] = paths
+	}
+	hits := paths[path]
+	if nil == hits {
+		if maxUnknownPaths <= len(paths) {
+			return
+		}
+
+		hits = &unknownPathHits{}
+		paths[path] = hits
+	}
+	hits.count++
+	hits.lastHit = time.Now()
+}
+
+// ExpireUnknownPaths forgets unknown paths which are not hit since the specified time.
+func (srv *redirectService) ExpireUnknownPaths(before time.Time) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for blogID, paths := range srv.unknownPaths {
+		for path, hits := range paths {
+			if hits.lastHit.Before(before) {
+				delete(paths, path)
+			}
+		}
+		if 1 > len(paths) {
+			delete(srv.unknownPaths, blogID)
+		}
+	}
+}
+
+// GetTopUnknownPaths gets the most requested unknown paths.
+func (srv *redirectService) GetTopUnknownPaths(size int, blogID uint64) (ret []*UnknownPath) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for path, hits := range srv.unknownPaths[blogID] {
+		ret = append(ret, &UnknownPath{Path: path, Count: hits.count})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count == ret[j].Count {
+			return ret[i].Path < ret[j].Path
+		}
+
+		return ret[i].Count > ret[j].Count
+	})
+	if size < len(ret) {
+		ret = ret[:size]
+	}
+
+	return
+}
+
+// GetRedirect gets the redirect of the specified path, returns nil if not found.
+func (srv *redirectService) GetRedirect(path string, blogID uint64) *model.Redirect {
+	ret := &model.Redirect{}
+	if err := db.Where("`path` = ? AND `blog_id` = ?", path, blogID).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// GetRedirects gets all redirects of the specified blog.
+func (srv *redirectService) GetRedirects(blogID uint64) (ret []*model.Redirect) {
+	if err := db.Where("`blog_id` = ?", blogID).Order("`path` ASC").Find(&ret).Error; nil != err {
+		logger.Errorf("get redirects failed: %s", err)
+	}
+
+	return
+}
+
+// AddRedirect adds a redirect and stops counting its path as unknown.
+func (srv *redirectService) AddRedirect(redirect *model.Redirect) error {
+	redirect.Path = strings.TrimSpace(redirect.Path)
+	redirect.TargetURL = strings.TrimSpace(redirect.TargetURL)
+	if !strings.HasPrefix(redirect.Path, "/") {
+		return errors.New("path must start with /")
+	}
+	if "" == redirect.TargetURL {
+		return errors.New("target URL is required")
+	}
+	if nil != srv.GetRedirect(redirect.Path, redirect.BlogID) {
+		return errors.New("redirect of path [" + redirect.Path + "] exists")
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	tx := db.Begin()
+	if err := tx.Create(redirect).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+	delete(srv.unknownPaths[redirect.BlogID], redirect.Path)
+
+	return nil
+}
+
+// addRedirectWithoutTx adds the specified redirect in the specified transaction, the redirect is skipped if its path
+// has been redirected.
+func addRedirectWithoutTx(tx *gorm.DB, redirect *model.Redirect) error {
+	count := 0
+	if err := tx.Model(&model.Redirect{}).Where("`path` = ? AND `blog_id` = ?", redirect.Path, redirect.BlogID).
+		Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return nil
+	}
+
+	return tx.Create(redirect).Error
+}
+
+// forgetUnknownPaths stops counting the specified paths of the specified blog as unknown.
+func (srv *redirectService) forgetUnknownPaths(blogID uint64, paths ...string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for _, path := range paths {
+		delete(srv.unknownPaths[blogID], path)
+	}
+}
+
+// RemoveRedirect removes a redirect.
+func (srv *redirectService) RemoveRedirect(id, blogID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	tx := db.Begin()
+	if err := tx.Where("`id` = ? AND `blog_id` = ?", id, blogID).Delete(&model.Redirect{}).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	tx.Commit()
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestGetTopUnknownPaths(t *testing.T) {
+	Redirect.AddUnknownPath("/a", 99)
+	Redirect.AddUnknownPath("/b", 99)
+	Redirect.AddUnknownPath("/b", 99)
+
+	paths := Redirect.GetTopUnknownPaths(1, 99)
+	if 1 != len(paths) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(paths))
+
+		return
+	}
+	if "/b" != paths[0].Path || 2 != paths[0].Count {
+		t.Errorf("expected is [%s, %d], actual is [%s, %d]", "/b", 2, paths[0].Path, paths[0].Count)
+	}
+
+	if err := Redirect.AddRedirect(&model.Redirect{Path: "/b", TargetURL: "/c", BlogID: 99}); nil != err {
+		t.Error(err)
+
+		return
+	}
+	redirect := Redirect.GetRedirect("/b", 99)
+	if nil == redirect || "/c" != redirect.TargetURL {
+		t.Errorf("get redirect failed")
+
+		return
+	}
+	paths = Redirect.GetTopUnknownPaths(10, 99)
+	if 1 != len(paths) || "/a" != paths[0].Path {
+		t.Errorf("unknown path [/b] should be cleared after adding its redirect")
+	}
+	if err := Redirect.RemoveRedirect(redirect.ID, 99); nil != err {
+		t.Error(err)
+	}
+}
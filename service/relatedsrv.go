@@ -0,0 +1,251 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/b3log/pipe/model"
+)
+
+// Related article service.
+var RelatedArticle = &relatedArticleService{
+	mutex: &sync.Mutex{},
+}
+
+type relatedArticleService struct {
+	mutex *sync.Mutex
+}
+
+// Related article computing arguments.
+const (
+	maxRelatedArticles        = 10   // max count of related articles kept for an article
+	relatedArticleCandidates  = 500  // count of the latest articles compared with an article
+	relatedArticleContentSize = 4096 // max runes of the content used for term similarity
+	relatedArticleTitleWeight = 3    // weight of title terms over content terms
+	relatedArticleTagScore    = 1.0  // score of each shared tag, term similarity scores 0 to 1
+	relatedArticleMinScore    = 0.05 // articles scored less are not related
+)
+
+// GetRelatedArticleListSize returns the count of related articles listed on article pages of the specified blog, 0
+// means related articles are turned off.
+func (srv *relatedArticleService) GetRelatedArticleListSize(blogID uint64) int {
+	setting := Setting.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceRelatedArticleListSize, blogID)
+	if nil == setting {
+		return model.SettingPreferenceRelatedArticleListSizeDefault
+	}
+	ret, err := strconv.Atoi(setting.Value)
+	if nil != err || 0 > ret {
+		return model.SettingPreferenceRelatedArticleListSizeDefault
+	}
+
+	return ret
+}
+
+// RelateArticle computes articles related to the specified published article by shared tags and term similarity of
+// titles and contents, and keeps the most related ones. Does nothing if related articles are turned off.
+func (srv *relatedArticleService) RelateArticle(article *model.Article) {
+	if model.ArticleStatusOK != article.Status || 1 > srv.GetRelatedArticleListSize(article.BlogID) {
+		return
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	var candidates []*model.Article
+	if err := db.Model(&model.Article{}).Select("`id`, `title`, `tags`, `content`").
+		Where("`id` <> ? AND `status` = ? AND `visibility` = ? AND `blog_id` = ?",
+			article.ID, model.ArticleStatusOK, model.ArticleVisibilityPublic, article.BlogID).
+		Order("`created_at` DESC").Limit(relatedArticleCandidates).Find(&candidates).Error; nil != err {
+		logger.Errorf("get related article candidates of article [%d] failed: %s", article.ID, err)
+
+		return
+	}
+
+	type scored struct {
+		id    uint64
+		score float64
+	}
+	var related []*scored
+	tags := splitTags(article.Tags)
+	terms := articleTerms(article)
+	for _, candidate := range candidates {
+		score := 0.0
+		for _, tag := range splitTags(candidate.Tags) {
+			if contains(tags, tag) {
+				score += relatedArticleTagScore
+			}
+		}
+		score += cosineSimilarity(terms, articleTerms(candidate))
+		if relatedArticleMinScore > score {
+			continue
+		}
+
+		related = append(related, &scored{id: candidate.ID, score: score})
+	}
+	sort.SliceStable(related, func(i, j int) bool { return related[i].score > related[j].score })
+	if maxRelatedArticles < len(related) {
+		related = related[:maxRelatedArticles]
+	}
+
+	tx := db.Begin()
+	if err := tx.Where("`id1` = ? AND `type` = ? AND `blog_id` = ?", article.ID, model.CorrelationArticleRelated, article.BlogID).
+		Delete(&model.Correlation{}).Error; nil != err {
+		tx.Rollback()
+		logger.Errorf("remove related articles of article [%d] failed: %s", article.ID, err)
+
+		return
+	}
+	for _, r := range related {
+		rel := &model.Correlation{
+			ID1:    article.ID,
+			ID2:    r.id,
+			Int1:   int(r.score * 1000),
+			Type:   model.CorrelationArticleRelated,
+			BlogID: article.BlogID,
+		}
+		if err := tx.Create(rel).Error; nil != err {
+			tx.Rollback()
+			logger.Errorf("relate articles [%d, %d] failed: %s", article.ID, r.id, err)
+
+			return
+		}
+	}
+	tx.Commit()
+}
+
+// GetRelatedArticles gets at most the specified size of published public articles related to the specified article,
+// the most related first.
+func (srv *relatedArticleService) GetRelatedArticles(articleID uint64, size int, blogID uint64) (ret []*model.Article) {
+	if 1 > size {
+		return
+	}
+
+	var rels []*model.Correlation
+	if err := db.Where("`id1` = ? AND `type` = ? AND `blog_id` = ?", articleID, model.CorrelationArticleRelated, blogID).
+		Order("`int1` DESC").Limit(maxRelatedArticles).Find(&rels).Error; nil != err {
+		logger.Errorf("get related articles of article [%d] failed: %s", articleID, err)
+
+		return
+	}
+	if 1 > len(rels) {
+		return
+	}
+
+	var articleIDs []uint64
+	for _, rel := range rels {
+		articleIDs = append(articleIDs, rel.ID2)
+	}
+	var articles []*model.Article
+	if err := db.Model(&model.Article{}).Select("`id`, `created_at`, `author_id`, `title`, `abstract`, `path`, `view_count`, `comment_count`").
+		Where("`id` IN (?) AND `status` = ? AND `visibility` = ? AND `blog_id` = ?",
+			articleIDs, model.ArticleStatusOK, model.ArticleVisibilityPublic, blogID).Find(&articles).Error; nil != err {
+		logger.Errorf("get related articles of article [%d] failed: %s", articleID, err)
+
+		return
+	}
+	articleMap := map[uint64]*model.Article{}
+	for _, article := range articles {
+		articleMap[article.ID] = article
+	}
+	for _, id := range articleIDs {
+		if article := articleMap[id]; nil != article {
+			ret = append(ret, article)
+		}
+		if size <= len(ret) {
+			break
+		}
+	}
+
+	return
+}
+
+// articleTerms returns term frequencies of the title and the leading content of the specified article. Latin words
+// and digits are terms, Han characters are split into bigrams.
+func articleTerms(article *model.Article) map[string]float64 {
+	ret := map[string]float64{}
+	for _, term := range splitTerms(article.Title) {
+		ret[term] += relatedArticleTitleWeight
+	}
+	content := []rune(article.Content)
+	if relatedArticleContentSize < len(content) {
+		content = content[:relatedArticleContentSize]
+	}
+	for _, term := range splitTerms(string(content)) {
+		ret[term]++
+	}
+
+	return ret
+}
+
+func splitTerms(text string) (ret []string) {
+	var word []rune
+	var han []rune
+	flush := func() {
+		if 1 < len(word) {
+			ret = append(ret, string(word))
+		}
+		word = word[:0]
+		if 1 == len(han) {
+			ret = append(ret, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			ret = append(ret, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if 0 < len(word) {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if 0 < len(han) {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return
+}
+
+func cosineSimilarity(a, b map[string]float64) float64 {
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for term, weight := range a {
+		normA += weight * weight
+		dot += weight * b[term]
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if 0 == normA || 0 == normB {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,84 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestRelateArticle(t *testing.T) {
+	titles := []string{"Golang channel tips", "Golang channel patterns", "Cooking pasta"}
+	tags := []string{"Go,Channel", "Go,Channel", "Food"}
+	var articles []*model.Article
+	for i, title := range titles {
+		article := &model.Article{
+			AuthorID: 1,
+			Title:    title,
+			Tags:     tags[i],
+			Content:  title + " content",
+			Path:     "/related-article-" + strconv.Itoa(i),
+			BlogID:   1,
+		}
+		if err := Article.AddArticle(article); nil != err {
+			t.Error(err)
+
+			return
+		}
+		defer Article.RemoveArticle(article.ID, 1)
+		articles = append(articles, article)
+	}
+
+	RelatedArticle.RelateArticle(articles[0])
+	related := RelatedArticle.GetRelatedArticles(articles[0].ID, 5, 1)
+	if 1 > len(related) || articles[1].ID != related[0].ID {
+		t.Error("the article sharing tags and terms should be the most related")
+	}
+	for _, article := range related {
+		if articles[2].ID == article.ID {
+			t.Error("the article sharing nothing should not be related")
+		}
+	}
+
+	if err := Article.RemoveArticle(articles[1].ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	for _, article := range RelatedArticle.GetRelatedArticles(articles[0].ID, 5, 1) {
+		if articles[1].ID == article.ID {
+			t.Error("removed article should not be related")
+		}
+	}
+}
+
+func TestSplitTerms(t *testing.T) {
+	terms := splitTerms("Pipe 是一款博客")
+	expected := []string{"pipe", "是一", "一款", "款博", "博客"}
+	if len(expected) != len(terms) {
+		t.Errorf("expected is [%v], actual is [%v]", expected, terms)
+
+		return
+	}
+	for i, term := range expected {
+		if term != terms[i] {
+			t.Errorf("expected is [%v], actual is [%v]", expected, terms)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
+)
+
+// Report service.
+var Report = &reportService{
+	mutex: &sync.Mutex{},
+}
+
+type reportService struct {
+	mutex *sync.Mutex
+}
+
+// Report errors.
+var (
+	ErrReportTargetNotFound = errors.New("not found the reported content")
+	ErrReportInvalid        = errors.New("invalid report")
+	ErrIPBanned             = errors.New("IP is banned")
+)
+
+// Report pagination arguments of admin console.
+const (
+	adminConsoleReportListPageSize   = 15
+	adminConsoleReportListWindowSize = 20
+)
+
+// maxReportDetailLength is the max length (in runes) of the description of a report.
+const maxReportDetailLength = 512
+
+// AddReport adds a report of the specified article or comment of the specified blog. Reports of the same content from
+// the same IP are merged while pending.
+func (srv *reportService) AddReport(report *model.Report) error {
+	if !contains(model.ReportReasons, report.Reason) {
+		return ErrReportInvalid
+	}
+	report.Detail = strings.TrimSpace(report.Detail)
+	if maxReportDetailLength < utf8.RuneCountInString(report.Detail) {
+		return ErrReportInvalid
+	}
+	if srv.IsBannedIP(report.IP) {
+		return ErrIPBanned
+	}
+
+	switch report.TargetType {
+	case model.ReportTargetArticle:
+		article := Article.ConsoleGetArticle(report.TargetID)
+		if nil == article || report.BlogID != article.BlogID || model.ArticleStatusOK != article.Status {
+			return ErrReportTargetNotFound
+		}
+	case model.ReportTargetComment:
+		comment := Comment.GetComment(report.TargetID)
+		if nil == comment || report.BlogID != comment.BlogID || model.CommentStatusOK != comment.Status {
+			return ErrReportTargetNotFound
+		}
+	default:
+		return ErrReportInvalid
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	count := 0
+	if err := db.Model(&model.Report{}).Where("`target_type` = ? AND `target_id` = ? AND `ip` = ? AND `status` = ?",
+		report.TargetType, report.TargetID, report.IP, model.ReportStatusPending).Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return nil
+	}
+
+	report.ID = 0
+	report.Status = model.ReportStatusPending
+	report.Resolution = ""
+	report.ResolverID = 0
+
+	return db.Create(report).Error
+}
+
+// ConsoleGetReports gets reports of all blogs with the specified status, the latest first.
+func (srv *reportService) ConsoleGetReports(status, page int) (ret []*model.Report, pagination *util.Pagination) {
+	offset := (page - 1) * adminConsoleReportListPageSize
+	count := 0
+	if err := db.Model(&model.Report{}).Where("`status` = ?", status).Order("`id` DESC").Count(&count).
+		Offset(offset).Limit(adminConsoleReportListPageSize).Find(&ret).Error; nil != err {
+		logger.Errorf("get reports failed: %s", err)
+	}
+
+	pagination = util.NewPagination(page, adminConsoleReportListPageSize, adminConsoleReportListWindowSize, count)
+
+	return
+}
+
+// GetReport gets a report by the specified ID, returns nil if not found.
+func (srv *reportService) GetReport(id uint64) *model.Report {
+	ret := &model.Report{}
+	if err := db.First(ret, id).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// ResolveReport resolves the specified pending report with the specified actions (ReportActionHide and/or
+// ReportActionBanIP), other pending reports of the same content are resolved together. Dismisses the report if no
+// action specified.
+func (srv *reportService) ResolveReport(report *model.Report, actions []string, resolverID uint64) (err error) {
+	if model.ReportStatusPending != report.Status {
+		return ErrReportInvalid
+	}
+	for _, action := range actions {
+		if model.ReportActionHide != action && model.ReportActionBanIP != action {
+			return ErrReportInvalid
+		}
+	}
+
+	if contains(actions, model.ReportActionBanIP) {
+		ip := srv.getTargetIP(report)
+		if "" == ip {
+			return errors.New("IP of the reported content is unknown")
+		}
+		if err = srv.BanIP(ip, "report [id="+strconv.FormatUint(report.ID, 10)+"]: "+report.Reason); nil != err {
+			return
+		}
+	}
+	if contains(actions, model.ReportActionHide) {
+		switch report.TargetType {
+		case model.ReportTargetArticle:
+			err = Article.TrashArticle(report.TargetID, report.BlogID)
+		case model.ReportTargetComment:
+			err = Comment.HideComment(report.TargetID, report.BlogID)
+		}
+		if nil != err && gorm.ErrRecordNotFound != err { // the content may be removed already
+			return
+		}
+	}
+
+	status := model.ReportStatusResolved
+	if 1 > len(actions) {
+		status = model.ReportStatusDismissed
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Model(&model.Report{}).Where("`target_type` = ? AND `target_id` = ? AND `status` = ?",
+		report.TargetType, report.TargetID, model.ReportStatusPending).Updates(map[string]interface{}{
+		"status":      status,
+		"resolution":  strings.Join(actions, ","),
+		"resolver_id": resolverID,
+	}).Error
+}
+
+// BanIP bans the specified IP from commenting and reporting.
+func (srv *reportService) BanIP(ip, reason string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if 255 < len(reason) {
+		reason = reason[:255]
+	}
+	bannedIP := &model.BannedIP{}
+	if err := db.Where("`ip` = ?", ip).First(bannedIP).Error; nil == err {
+		return nil
+	} else if gorm.ErrRecordNotFound != err {
+		return err
+	}
+
+	return db.Create(&model.BannedIP{IP: ip, Reason: reason}).Error
+}
+
+// UnbanIP lifts the ban of the specified IP.
+func (srv *reportService) UnbanIP(ip string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Unscoped().Where("`ip` = ?", ip).Delete(&model.BannedIP{}).Error
+}
+
+// IsBannedIP checks whether the specified IP is banned.
+func (srv *reportService) IsBannedIP(ip string) bool {
+	if "" == ip {
+		return false
+	}
+
+	count := 0
+	db.Model(&model.BannedIP{}).Where("`ip` = ?", ip).Count(&count)
+
+	return 0 < count
+}
+
+// GetBannedIPs gets all banned IPs, the latest first.
+func (srv *reportService) GetBannedIPs() (ret []*model.BannedIP) {
+	if err := db.Order("`id` DESC").Find(&ret).Error; nil != err {
+		logger.Errorf("get banned IPs failed: %s", err)
+	}
+
+	return
+}
+
+// getTargetIP returns the IP of the author of the reported content.
+func (srv *reportService) getTargetIP(report *model.Report) string {
+	switch report.TargetType {
+	case model.ReportTargetArticle:
+		article := &model.Article{}
+		if err := db.Unscoped().Select("`ip`").First(article, report.TargetID).Error; nil == err {
+			return article.IP
+		}
+	case model.ReportTargetComment:
+		comment := &model.Comment{}
+		if err := db.Unscoped().Select("`ip`").First(comment, report.TargetID).Error; nil == err {
+			return comment.IP
+		}
+	}
+
+	return ""
+}
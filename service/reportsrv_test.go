@@ -0,0 +1,89 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestResolveReport(t *testing.T) {
+	articles, _ := Article.GetArticles("", 1, 1)
+	articleID := articles[0].ID
+	comment := &model.Comment{
+		ArticleID: articleID,
+		AuthorID:  1,
+		Content:   "reported",
+		IP:        "192.0.2.1",
+		BlogID:    1,
+	}
+	if err := Comment.AddComment(comment); nil != err {
+		t.Error(err)
+
+		return
+	}
+	commentCount := Article.ConsoleGetArticle(articleID).CommentCount
+
+	report := &model.Report{TargetType: model.ReportTargetComment, TargetID: comment.ID, Reason: "spam", IP: "192.0.2.2", BlogID: 1}
+	if err := Report.AddReport(report); nil != err {
+		t.Error(err)
+
+		return
+	}
+	dup := &model.Report{TargetType: model.ReportTargetComment, TargetID: comment.ID, Reason: "abuse", IP: "192.0.2.2", BlogID: 1}
+	if err := Report.AddReport(dup); nil != err {
+		t.Error(err)
+	}
+	if reports, _ := Report.ConsoleGetReports(model.ReportStatusPending, 1); 1 != len(reports) {
+		t.Errorf("expected is [%d], actual is [%d]", 1, len(reports))
+	}
+	invalid := &model.Report{TargetType: model.ReportTargetComment, TargetID: comment.ID, Reason: "boring", BlogID: 1}
+	if err := Report.AddReport(invalid); ErrReportInvalid != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrReportInvalid, err)
+	}
+
+	if err := Report.ResolveReport(report, []string{model.ReportActionHide, model.ReportActionBanIP}, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if model.CommentStatusSpam != Comment.GetComment(comment.ID).Status {
+		t.Error("reported comment should be hidden")
+	}
+	if commentCount-1 != Article.ConsoleGetArticle(articleID).CommentCount {
+		t.Error("hidden comment should not be counted")
+	}
+	if !Report.IsBannedIP("192.0.2.1") {
+		t.Error("IP of the reported comment should be banned")
+	}
+	if reports, _ := Report.ConsoleGetReports(model.ReportStatusResolved, 1); 1 != len(reports) || "hide,banIP" != reports[0].Resolution {
+		t.Error("report should be resolved")
+	}
+
+	banned := &model.Report{TargetType: model.ReportTargetArticle, TargetID: articleID, Reason: "spam", IP: "192.0.2.1", BlogID: 1}
+	if err := Report.AddReport(banned); ErrIPBanned != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrIPBanned, err)
+	}
+
+	if err := Report.UnbanIP("192.0.2.1"); nil != err {
+		t.Error(err)
+	}
+	if err := Comment.RemoveComment(comment.ID, 1); nil != err {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,143 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/parnurzeal/gorequest"
+)
+
+// Repost service.
+var Repost = &repostService{
+	mutex: &sync.Mutex{},
+}
+
+type repostService struct {
+	mutex *sync.Mutex
+}
+
+// GetRepostBlogIDs gets IDs of blogs which enabled reposting.
+func (srv *repostService) GetRepostBlogIDs() (ret []uint64) {
+	var settings []*model.Setting
+	if err := db.Where("`category` = ? AND `name` = ? AND `value` = ?",
+		model.SettingCategoryRepost, model.SettingNameRepostEnabled, "true").Find(&settings).Error; nil != err {
+		logger.Errorf("get repost blogs failed: %s", err)
+
+		return
+	}
+
+	for _, setting := range settings {
+		ret = append(ret, setting.BlogID)
+	}
+
+	return
+}
+
+// RepostArticle reposts an evergreen article of the specified blog to the configured channels if the repost interval
+// has elapsed. Articles are picked in rotation, the least recently reposted one first.
+func (srv *repostService) RepostArticle(blogID uint64) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	interval := srv.getIntSetting(model.SettingNameRepostInterval, model.SettingRepostIntervalDefault, blogID)
+	minAge := srv.getIntSetting(model.SettingNameRepostMinAge, model.SettingRepostMinAgeDefault, blogID)
+	now := time.Now()
+
+	latest := &model.Article{}
+	if err := db.Where("`blog_id` = ?", blogID).Order("`reposted_at` DESC").First(latest).Error; nil == err &&
+		now.Before(latest.RepostedAt.Add(time.Duration(interval)*time.Hour)) {
+		return
+	}
+
+	article := &model.Article{}
+	if err := db.Where("`blog_id` = ? AND `status` = ? AND `visibility` = ? AND `repost_opt_out` = ? AND `created_at` < ?",
+		blogID, model.ArticleStatusOK, model.ArticleVisibilityPublic, false, now.AddDate(0, 0, -minAge)).
+		Order("`reposted_at` ASC, `view_count` DESC").First(article).Error; nil != err {
+		return
+	}
+
+	blogTitleSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, blogID)
+	blogURLSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+	text := article.Title + " - " + blogTitleSetting.Value + "\n" + blogURLSetting.Value + article.Path
+
+	sent := false
+	if botToken, chatID := srv.getSetting(model.SettingNameRepostTelegramBotToken, blogID),
+		srv.getSetting(model.SettingNameRepostTelegramChatID, blogID); "" != botToken && "" != chatID {
+		sent = repost("Telegram", "https://api.telegram.org/bot"+url.PathEscape(botToken)+"/sendMessage",
+			map[string]interface{}{"chat_id": chatID, "text": text}) || sent
+	}
+	if webhook := srv.getSetting(model.SettingNameRepostTwitterWebhook, blogID); "" != webhook {
+		sent = repost("Twitter", webhook, map[string]interface{}{"text": text, "title": article.Title,
+			"url": blogURLSetting.Value + article.Path}) || sent
+	}
+	if !sent {
+		return
+	}
+
+	if err := db.Model(article).Update("reposted_at", now).Error; nil != err {
+		logger.Errorf("update reposted at of article [id=%d] failed: %s", article.ID, err)
+
+		return
+	}
+
+	logger.Infof("reposted article [id=%d, title=%s] of blog [%d]", article.ID, article.Title, blogID)
+}
+
+func (srv *repostService) getSetting(name string, blogID uint64) string {
+	setting := Setting.GetSetting(model.SettingCategoryRepost, name, blogID)
+	if nil == setting {
+		return ""
+	}
+
+	return setting.Value
+}
+
+func (srv *repostService) getIntSetting(name string, defaultValue int, blogID uint64) int {
+	ret, err := strconv.Atoi(srv.getSetting(name, blogID))
+	if nil != err || 1 > ret {
+		return defaultValue
+	}
+
+	return ret
+}
+
+func repost(channel, channelURL string, requestJSON map[string]interface{}) bool {
+	response, data, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		Post(channelURL).SendMap(requestJSON).
+		Set("user-agent", model.UserAgent).Timeout(30 * time.Second).End()
+	if nil != errs {
+		// the channel URL may contain credentials such as the Telegram bot token
+		logger.Errorf("repost to [%s] failed: %s", channel, strings.Replace(errs[0].Error(), channelURL, channel, -1))
+
+		return false
+	}
+	if http.StatusOK > response.StatusCode || http.StatusMultipleChoices <= response.StatusCode {
+		logger.Errorf("repost to [%s] failed: status code [%d], response [%s]", channel, response.StatusCode, data)
+
+		return false
+	}
+
+	return true
+}
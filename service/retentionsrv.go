@@ -0,0 +1,78 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Retention service, applies data retention policies for privacy.
+var Retention = &retentionService{}
+
+type retentionService struct {
+}
+
+// retentionBatchSize is the count of records anonymized in each batch.
+const retentionBatchSize = 512
+
+// ipRecord represents a record carrying an IP.
+type ipRecord struct {
+	ID uint64
+	IP string
+}
+
+// AnonymizeIPs anonymizes IPs of comments, articles, audit logs, reports and user sessions created before the
+// specified time, returns the count of anonymized records.
+func (srv *retentionService) AnonymizeIPs(before time.Time, mode, salt string) (ret int) {
+	for _, m := range []interface{}{&model.Comment{}, &model.Article{}, &model.AuditLog{}, &model.Report{},
+		&model.UserSession{}} {
+		var lastID uint64
+		for {
+			var records []*ipRecord
+			if err := db.Model(m).Select("`id`, `ip`").
+				Where("`id` > ? AND `created_at` < ? AND `ip` <> ?", lastID, before, "").
+				Order("`id` ASC").Limit(retentionBatchSize).Scan(&records).Error; nil != err {
+				logger.Errorf("get IPs to anonymize failed: %s", err)
+
+				break
+			}
+			if 1 > len(records) {
+				break
+			}
+
+			for _, record := range records {
+				lastID = record.ID
+				ip := util.AnonymizeIP(record.IP, mode, salt)
+				if ip == record.IP {
+					continue
+				}
+
+				if err := db.Model(m).Where("`id` = ?", record.ID).UpdateColumn("ip", ip).Error; nil != err {
+					logger.Errorf("anonymize IP of record [%d] failed: %s", record.ID, err)
+
+					continue
+				}
+				ret++
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,65 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+func TestAnonymizeIPs(t *testing.T) {
+	comment := &model.Comment{Content: "retention", IP: "10.0.0.1", BlogID: 99}
+	comment.CreatedAt = time.Now().AddDate(0, 0, -30)
+	if err := db.Create(comment).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer db.Delete(comment)
+
+	if count := Retention.AnonymizeIPs(time.Now().AddDate(0, 0, -7), util.IPAnonymizationTruncate, ""); 1 > count {
+		t.Errorf("expected anonymized count is at least [%d], actual is [%d]", 1, count)
+	}
+	anonymized := &model.Comment{}
+	if err := db.First(anonymized, comment.ID).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "10.0.0.0" != anonymized.IP {
+		t.Errorf("expected is [%s], actual is [%s]", "10.0.0.0", anonymized.IP)
+	}
+
+	auditLog := &model.AuditLog{UserID: 1, Action: model.AuditActionJobRun, IP: "10.0.0.2", BlogID: 99}
+	auditLog.CreatedAt = time.Now().AddDate(0, 0, -30)
+	if err := db.Create(auditLog).Error; nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer db.Delete(auditLog)
+
+	Retention.AnonymizeIPs(time.Now().AddDate(0, 0, -7), util.IPAnonymizationTruncate, "")
+	anonymizedLog := &model.AuditLog{}
+	db.First(anonymizedLog, auditLog.ID)
+	if "10.0.0.0" != anonymizedLog.IP {
+		t.Errorf("expected is [%s], actual is [%s]", "10.0.0.0", anonymizedLog.IP)
+	}
+}
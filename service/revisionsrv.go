@@ -0,0 +1,70 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// Revision service.
+var Revision = &revisionService{
+	mutex: &sync.Mutex{},
+}
+
+type revisionService struct {
+	mutex *sync.Mutex
+}
+
+// GetRevisions gets revisions (without content) of the specified article, the latest first.
+func (srv *revisionService) GetRevisions(articleID uint64) (ret []*model.Revision) {
+	if err := db.Model(&model.Revision{}).Select("`id`, `created_at`, `article_id`, `version`, `title`, `editor_id`, `blog_id`").
+		Where("`article_id` = ?", articleID).Order("`version` DESC").Find(&ret).Error; nil != err {
+		logger.Errorf("get revisions of article [%d] failed: %s", articleID, err)
+	}
+
+	return
+}
+
+// GetRevision gets the revision of the specified article at the specified version, returns nil if not found.
+func (srv *revisionService) GetRevision(articleID uint64, version int) *model.Revision {
+	ret := &model.Revision{}
+	if err := db.Where("`article_id` = ? AND `version` = ?", articleID, version).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+func addRevisionWithoutTx(tx *gorm.DB, article *model.Article, editorID uint64) error {
+	revision := &model.Revision{
+		ArticleID: article.ID,
+		Version:   article.Version,
+		Title:     article.Title,
+		Content:   article.Content,
+		EditorID:  editorID,
+		BlogID:    article.BlogID,
+	}
+
+	return tx.Create(revision).Error
+}
+
+func removeRevisionsWithoutTx(tx *gorm.DB, articleID uint64) error {
+	return tx.Where("`article_id` = ?", articleID).Delete(&model.Revision{}).Error
+}
@@ -0,0 +1,69 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestGetRevisions(t *testing.T) {
+	article := &model.Article{AuthorID: 1,
+		Title:   "Revision 文章",
+		Tags:    "Revision",
+		Content: "Version zero",
+		BlogID:  1,
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+	article.Content = "Version one"
+	if err := Article.UpdateArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	revisions := Revision.GetRevisions(article.ID)
+	if 2 != len(revisions) {
+		t.Errorf("expected is [%d], actual is [%d]", 2, len(revisions))
+
+		return
+	}
+	if 1 != revisions[0].Version || "" != revisions[0].Content {
+		t.Errorf("revisions should be listed without content, the latest first")
+	}
+	revision := Revision.GetRevision(article.ID, 0)
+	if nil == revision || "Version zero" != revision.Content {
+		t.Errorf("get revision [0] failed")
+	}
+	if nil != Revision.GetRevision(article.ID, 2) {
+		t.Errorf("revision [2] should not exist")
+	}
+
+	if err := Article.RemoveArticle(article.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if 0 != len(Revision.GetRevisions(article.ID)) {
+		t.Errorf("revisions should be removed with the article")
+	}
+}
@@ -17,10 +17,15 @@
 package service
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
+	"github.com/b3log/pipe/util"
 )
 
 // Setting service.
@@ -95,6 +100,7 @@ func (srv *settingService) AddSetting(setting *model.Setting) error {
 		return err
 	}
 	tx.Commit()
+	cache.Page.Purge(setting.BlogID)
 
 	return nil
 }
@@ -105,6 +111,27 @@ func (srv *settingService) UpdateSettings(category string, settings []*model.Set
 
 	tx := db.Begin()
 	for _, setting := range settings {
+		setting.Category = category // settings are cached by category, name and blog
+		setting.BlogID = blogID
+
+		count := 0
+		if err := tx.Model(&model.Setting{}).Where("`category` = ? AND `name` = ? AND `blog_id` = ?",
+			category, setting.Name, blogID).Count(&count).Error; nil != err {
+			tx.Rollback()
+
+			return err
+		}
+		if 1 > count { // settings introduced after the blog was initialized are created lazily
+			if err := tx.Create(setting).Error; nil != err {
+				tx.Rollback()
+
+				return err
+			}
+			cache.Setting.Put(setting)
+
+			continue
+		}
+
 		if err := tx.Model(&model.Setting{}).Where("`category` = ? AND `name` = ? AND `blog_id` = ?",
 			category, setting.Name, blogID).Select("value").Updates(map[string]interface{}{"value": setting.Value}).Error; nil != err {
 			tx.Rollback()
@@ -115,6 +142,101 @@ func (srv *settingService) UpdateSettings(category string, settings []*model.Set
 		cache.Setting.Put(setting)
 	}
 	tx.Commit()
+	cache.Page.Purge(blogID)
 
 	return nil
 }
+
+// GetThemeOptions returns values of options of the specified theme set by the specified blog, defaults are used for
+// options not set yet. Values are typed as the options.
+func (srv *settingService) GetThemeOptions(themeName string, blogID uint64) map[string]interface{} {
+	ret := map[string]interface{}{}
+	for _, option := range theme.GetOptions(themeName) {
+		value := option.DefaultValue()
+		if setting := srv.GetSetting(model.SettingCategoryThemeOption, themeName+"/"+option.Name, blogID); nil != setting {
+			value = setting.Value
+		}
+		v, err := option.Parse(value)
+		if nil != err { // the option type changed with a theme upgrade
+			v, _ = option.Parse(option.DefaultValue())
+		}
+		ret[option.Name] = v
+	}
+
+	return ret
+}
+
+// UpdateThemeOptions updates options of the specified theme with the specified values of the specified blog.
+func (srv *settingService) UpdateThemeOptions(themeName string, values map[string]string, blogID uint64) error {
+	options := map[string]*theme.Option{}
+	for _, option := range theme.GetOptions(themeName) {
+		options[option.Name] = option
+	}
+
+	var settings []*model.Setting
+	for name, value := range values {
+		option := options[name]
+		if nil == option {
+			return errors.New("not found option [" + name + "] of theme [" + themeName + "]")
+		}
+		if _, err := option.Parse(value); nil != err {
+			return errors.New("invalid value of option [" + name + "]: " + err.Error())
+		}
+
+		settings = append(settings, &model.Setting{Category: model.SettingCategoryThemeOption, Name: themeName + "/" + name,
+			Value: value, BlogID: blogID})
+	}
+
+	return srv.UpdateSettings(model.SettingCategoryThemeOption, settings, blogID)
+}
+
+// GetMarkdownOptions returns the markdown rendering options configured by the preferences of the specified blog.
+func (srv *settingService) GetMarkdownOptions(blogID uint64) *util.MarkdownOptions {
+	return &util.MarkdownOptions{
+		Math:            srv.isPreferenceOn(model.SettingNamePreferenceMath, model.SettingPreferenceMathDefault, blogID),
+		PlantUMLServer:  model.Conf.PlantUMLServer,
+		Footnotes:       srv.isPreferenceOn(model.SettingNamePreferenceFootnotes, model.SettingPreferenceFootnotesDefault, blogID),
+		DefinitionLists: srv.isPreferenceOn(model.SettingNamePreferenceDefinitionLists, model.SettingPreferenceDefinitionListsDefault, blogID),
+		TaskLists:       srv.isPreferenceOn(model.SettingNamePreferenceTaskLists, model.SettingPreferenceTaskListsDefault, blogID),
+		Tables:          srv.isPreferenceOn(model.SettingNamePreferenceTables, model.SettingPreferenceTablesDefault, blogID),
+		Embeds:          srv.getEmbedProviders(blogID),
+		Shortcodes:      true,
+		Theme:           srv.getThemeName(blogID),
+	}
+}
+
+// isPreferenceOn checks whether the switch preference of the specified name is turned on by the specified blog, the
+// specified default value is used if the blog has not set it.
+func (srv *settingService) isPreferenceOn(name string, defaultValue int, blogID uint64) bool {
+	value := strconv.Itoa(defaultValue)
+	if setting := srv.GetSetting(model.SettingCategoryPreference, name, blogID); nil != setting {
+		value = setting.Value
+	}
+
+	return strconv.Itoa(model.SettingPreferenceSwitchValueOn) == value
+}
+
+// getEmbedProviders returns names of the embed providers allowed by the specified blog.
+func (srv *settingService) getEmbedProviders(blogID uint64) (ret []string) {
+	value := model.SettingPreferenceEmbedProvidersDefault
+	if setting := srv.GetSetting(model.SettingCategoryPreference, model.SettingNamePreferenceEmbedProviders, blogID); nil != setting {
+		value = setting.Value
+	}
+	for _, provider := range strings.Split(value, ",") {
+		if provider = strings.TrimSpace(provider); "" != provider {
+			ret = append(ret, provider)
+		}
+	}
+
+	return
+}
+
+// getThemeName returns the name of the theme the specified blog is rendered with.
+func (srv *settingService) getThemeName(blogID uint64) string {
+	name := ""
+	if setting := srv.GetSetting(model.SettingCategoryTheme, model.SettingNameThemeName, blogID); nil != setting {
+		name = setting.Value
+	}
+
+	return theme.Resolve(name)
+}
@@ -19,7 +19,9 @@ package service
 import (
 	"testing"
 
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/theme"
 )
 
 func TestGetSetting(t *testing.T) {
@@ -37,7 +39,7 @@ func TestGetSetting(t *testing.T) {
 
 func TestGetAllSettings(t *testing.T) {
 	settings := Setting.GetAllSettings(1)
-	settingsCount := 27
+	settingsCount := 38
 	if settingsCount != len(settings) {
 		t.Errorf("expected is [%d], actual is [%d]", settingsCount, len(settings))
 	}
@@ -89,3 +91,62 @@ func TestUpdateSettings(t *testing.T) {
 		t.Errorf("expected is [%s], actual is [%s]", "更新后的标题", settings[model.SettingNameBasicBlogTitle].Value)
 	}
 }
+
+func TestUpdateSettingsCreatesMissing(t *testing.T) {
+	reposts := []*model.Setting{{Name: model.SettingNameRepostEnabled, Value: "true"}}
+	if err := Setting.UpdateSettings(model.SettingCategoryRepost, reposts, 1); nil != err {
+		t.Errorf("updates settings failed: " + err.Error())
+
+		return
+	}
+
+	setting := Setting.GetSetting(model.SettingCategoryRepost, model.SettingNameRepostEnabled, 1)
+	if nil == setting || "true" != setting.Value {
+		t.Errorf("expected is [%s], actual is [%+v]", "true", setting)
+	}
+	if blogIDs := Repost.GetRepostBlogIDs(); 1 != len(blogIDs) || 1 != blogIDs[0] {
+		t.Errorf("expected is [%v], actual is [%v]", []uint64{1}, blogIDs)
+	}
+}
+
+func TestUpdateThemeOptions(t *testing.T) {
+	theme.Manifests["options-test"] = &theme.Manifest{Name: "options-test", Options: []*theme.Option{
+		{Name: "accentColor", Type: theme.OptionTypeColor, Default: "#4285f4"},
+		{Name: "showAuthors", Type: theme.OptionTypeBool, Default: true},
+	}}
+	defer delete(theme.Manifests, "options-test")
+	defer removeSettings(model.SettingCategoryThemeOption, 1)
+
+	options := Setting.GetThemeOptions("options-test", 1)
+	if "#4285f4" != options["accentColor"] || true != options["showAuthors"] {
+		t.Errorf("unexpected default options [%+v]", options)
+	}
+
+	if err := Setting.UpdateThemeOptions("options-test", map[string]string{"accentColor": "red"}, 1); nil == err {
+		t.Error("invalid color should be rejected")
+	}
+	if err := Setting.UpdateThemeOptions("options-test", map[string]string{"showAuthors": "false"}, 1); nil != err {
+		t.Errorf("updates theme options failed: " + err.Error())
+
+		return
+	}
+	if options = Setting.GetThemeOptions("options-test", 1); false != options["showAuthors"] {
+		t.Errorf("expected is [%v], actual is [%v]", false, options["showAuthors"])
+	}
+
+	if err := Setting.UpdateThemeOptions("options-test", map[string]string{"showAuthors": "true"}, 1); nil != err {
+		t.Errorf("updates theme options failed: " + err.Error())
+
+		return
+	}
+	if options = Setting.GetThemeOptions("options-test", 1); true != options["showAuthors"] {
+		t.Errorf("expected is [%v], actual is [%v]", true, options["showAuthors"])
+	}
+}
+
+// removeSettings removes settings of the specified category created by a test so that settings counted by other tests
+// are the ones inited with the blog only.
+func removeSettings(category string, blogID uint64) {
+	db.Unscoped().Where("`category` = ? AND `blog_id` = ?", category, blogID).Delete(&model.Setting{})
+	cache.Setting.Purge()
+}
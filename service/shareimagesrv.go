@@ -0,0 +1,93 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"sync"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/golang/freetype/truetype"
+)
+
+// ShareImage service, renders share images (og:image) of articles and stores them in media storage.
+var ShareImage = &shareImageService{
+	mutex: &sync.Mutex{},
+	urls:  map[string]string{},
+}
+
+type shareImageService struct {
+	mutex *sync.Mutex
+	font  *truetype.Font
+	urls  map[string]string // media key -> media URL
+}
+
+// Enabled checks whether share images are configured.
+func (srv *shareImageService) Enabled() bool {
+	return "" != model.Conf.ShareImageFont && Media.Enabled()
+}
+
+// GetURL returns the media URL of the share image of the specified article, renders and stores it if not exists.
+// The image is rendered again once the title of the article, the author name or the blog title changed.
+func (srv *shareImageService) GetURL(article *model.Article) (string, error) {
+	if !srv.Enabled() {
+		return "", errors.New("share images are not configured")
+	}
+
+	img := &util.ShareImage{Title: article.Title}
+	if author := User.GetUser(article.AuthorID); nil != author {
+		img.Author = author.Name
+	}
+	if blogTitle := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogTitle, article.BlogID); nil != blogTitle {
+		img.Blog = blogTitle.Value
+	}
+	hash := sha256.Sum256([]byte(img.Title + "\n" + img.Author + "\n" + img.Blog))
+	key := "share/" + strconv.FormatUint(article.ID, 10) + "-" + hex.EncodeToString(hash[:])[:8] + ".png"
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if url, ok := srv.urls[key]; ok {
+		return url, nil
+	}
+	if nil == srv.font {
+		data, err := ioutil.ReadFile(model.Conf.ShareImageFont)
+		if nil != err {
+			return "", err
+		}
+		if srv.font, err = truetype.Parse(data); nil != err {
+			return "", err
+		}
+	}
+
+	data, err := util.RenderShareImage(img, srv.font)
+	if nil != err {
+		return "", err
+	}
+	url, err := Media.Put(key, data)
+	if nil != err {
+		return "", err
+	}
+	srv.urls[key] = url
+
+	return url, nil
+}
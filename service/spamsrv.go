@@ -0,0 +1,188 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/parnurzeal/gorequest"
+)
+
+// SpamContext holds the submission information of a comment for spam checking.
+type SpamContext struct {
+	UserAgent   string
+	Referrer    string
+	Permalink   string
+	AuthorName  string
+	AuthorEmail string
+	AuthorURL   string
+}
+
+// SpamChecker checks whether comments are spam.
+type SpamChecker interface {
+	// IsSpam checks whether the specified comment is spam.
+	IsSpam(comment *model.Comment, ctx *SpamContext) (bool, error)
+
+	// SubmitHam reports the specified comment which has been flagged as spam wrongly.
+	SubmitHam(comment *model.Comment, ctx *SpamContext) error
+}
+
+// SpamCheckerFactory creates a spam checker for the specified blog, returns nil if the checker is not configured for
+// the blog.
+type SpamCheckerFactory func(blogID uint64) SpamChecker
+
+// Spam service.
+var Spam = &spamService{
+	mutex:     &sync.Mutex{},
+	factories: []SpamCheckerFactory{newAkismetChecker},
+}
+
+type spamService struct {
+	mutex     *sync.Mutex
+	factories []SpamCheckerFactory
+}
+
+// RegisterChecker registers the specified spam checker factory.
+func (srv *spamService) RegisterChecker(factory SpamCheckerFactory) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.factories = append(srv.factories, factory)
+}
+
+func (srv *spamService) getCheckers(blogID uint64) (ret []SpamChecker) {
+	srv.mutex.Lock()
+	factories := srv.factories
+	srv.mutex.Unlock()
+
+	for _, factory := range factories {
+		if checker := factory(blogID); nil != checker {
+			ret = append(ret, checker)
+		}
+	}
+
+	return
+}
+
+// HasCheckers checks whether the specified blog has any spam checker configured.
+func (srv *spamService) HasCheckers(blogID uint64) bool {
+	return 0 < len(srv.getCheckers(blogID))
+}
+
+// IsSpam checks the specified comment with the checkers configured for its blog. Checker failures are logged and
+// treated as not spam so that comments will not be lost if a checker is unavailable.
+func (srv *spamService) IsSpam(comment *model.Comment, ctx *SpamContext) bool {
+	for _, checker := range srv.getCheckers(comment.BlogID) {
+		spam, err := checker.IsSpam(comment, ctx)
+		if nil != err {
+			logger.Errorf("check comment spam failed: %s", err)
+
+			continue
+		}
+		if spam {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SubmitHam reports the specified comment which has been flagged as spam wrongly to the checkers configured for its
+// blog.
+func (srv *spamService) SubmitHam(comment *model.Comment, ctx *SpamContext) {
+	for _, checker := range srv.getCheckers(comment.BlogID) {
+		if err := checker.SubmitHam(comment, ctx); nil != err {
+			logger.Errorf("submit ham comment [id=%d] failed: %s", comment.ID, err)
+		}
+	}
+}
+
+// akismetChecker checks comments with Akismet, https://akismet.com/development/api/
+type akismetChecker struct {
+	key     string
+	blogURL string
+}
+
+func newAkismetChecker(blogID uint64) SpamChecker {
+	keySetting := Setting.GetSetting(model.SettingCategorySpam, model.SettingNameSpamAkismetKey, blogID)
+	if nil == keySetting || "" == strings.TrimSpace(keySetting.Value) {
+		return nil
+	}
+	blogURLSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+	if nil == blogURLSetting {
+		return nil
+	}
+
+	return &akismetChecker{key: strings.TrimSpace(keySetting.Value), blogURL: blogURLSetting.Value}
+}
+
+func (checker *akismetChecker) IsSpam(comment *model.Comment, ctx *SpamContext) (bool, error) {
+	data, err := checker.call("comment-check", comment, ctx)
+	if nil != err {
+		return false, err
+	}
+
+	switch data {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, errors.New("unexpected akismet response [" + data + "]")
+	}
+}
+
+func (checker *akismetChecker) SubmitHam(comment *model.Comment, ctx *SpamContext) error {
+	_, err := checker.call("submit-ham", comment, ctx)
+
+	return err
+}
+
+func (checker *akismetChecker) call(method string, comment *model.Comment, ctx *SpamContext) (string, error) {
+	params := map[string]interface{}{
+		"blog":                 checker.blogURL,
+		"user_ip":              comment.IP,
+		"user_agent":           ctx.UserAgent,
+		"referrer":             ctx.Referrer,
+		"permalink":            ctx.Permalink,
+		"comment_type":         "comment",
+		"comment_author":       ctx.AuthorName,
+		"comment_author_email": ctx.AuthorEmail,
+		"comment_author_url":   ctx.AuthorURL,
+		"comment_content":      comment.Content,
+	}
+	response, data, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		Post("https://"+checker.key+".rest.akismet.com/1.1/"+method).Type("form").SendMap(params).
+		Set("user-agent", model.UserAgent).Timeout(10 * time.Second).End()
+	if nil != errs {
+		return "", errs[0]
+	}
+	if http.StatusOK != response.StatusCode {
+		return "", errors.New("akismet responded status code [" + response.Status + "]")
+	}
+	if help := response.Header.Get("X-akismet-debug-help"); "" != help {
+		return "", errors.New("akismet " + method + " failed: " + help)
+	}
+
+	return strings.TrimSpace(data), nil
+}
@@ -37,7 +37,7 @@ func (srv *statisticService) GetAllStatistics(blogID uint64) []*model.Setting {
 	var ret []*model.Setting
 
 	if err := db.Where("`category` = ? AND `blog_id` = ?", model.SettingCategoryStatistic, blogID).Find(&ret).Error; nil != err {
-		logger.Errorf("get all statistics failed: " + err.Error())
+		logger.Errorf("get all statistics failed: %s", err)
 
 		return nil
 	}
@@ -48,7 +48,7 @@ func (srv *statisticService) GetAllStatistics(blogID uint64) []*model.Setting {
 func (srv *statisticService) GetStatistic(statisticName string, blogID uint) *model.Setting {
 	ret := &model.Setting{}
 	if err := db.Where("`name` = ? AND `category` = ? AND `blog_id` = ?", statisticName, model.SettingCategoryStatistic, blogID).Find(ret).Error; nil != err {
-		logger.Errorf("get statistic failed: " + err.Error())
+		logger.Errorf("get statistic failed: %s", err)
 
 		return nil
 	}
@@ -60,7 +60,7 @@ func (srv *statisticService) GetStatistics(blogID uint, statisticNames ...string
 	ret := map[string]*model.Setting{}
 	var settings []*model.Setting
 	if err := db.Where("`name` IN (?) AND `category` = ? AND `blog_id` = ?", statisticNames, model.SettingCategoryStatistic, blogID).Find(&settings).Error; nil != err {
-		logger.Errorf("get statistics failed: " + err.Error())
+		logger.Errorf("get statistics failed: %s", err)
 
 		return nil
 	}
@@ -73,8 +73,13 @@ func (srv *statisticService) GetStatistics(blogID uint, statisticNames ...string
 }
 
 func (srv *statisticService) IncViewCount(blogID uint64) error {
+	return srv.AddViewCount(blogID, 1)
+}
+
+// AddViewCount adds the specified count to the view count of the specified blog.
+func (srv *statisticService) AddViewCount(blogID uint64, count int) error {
 	tx := db.Begin()
-	if err := srv.IncViewCountWithoutTx(tx, blogID); nil != err {
+	if err := srv.addViewCountWithoutTx(tx, blogID, count); nil != err {
 		tx.Rollback()
 
 		return err
@@ -85,6 +90,10 @@ func (srv *statisticService) IncViewCount(blogID uint64) error {
 }
 
 func (srv *statisticService) IncViewCountWithoutTx(tx *gorm.DB, blogID uint64) error {
+	return srv.addViewCountWithoutTx(tx, blogID, 1)
+}
+
+func (srv *statisticService) addViewCountWithoutTx(tx *gorm.DB, blogID uint64, delta int) error {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
 
@@ -98,7 +107,7 @@ func (srv *statisticService) IncViewCountWithoutTx(tx *gorm.DB, blogID uint64) e
 		return err
 	}
 
-	setting.Value = strconv.Itoa(count + 1)
+	setting.Value = strconv.Itoa(count + delta)
 	if err := tx.Model(setting).Updates(setting).Error; nil != err {
 		return err
 	}
@@ -17,6 +17,7 @@
 package service
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/b3log/pipe/model"
@@ -158,3 +159,23 @@ func TestDecCommentCount(t *testing.T) {
 		t.Errorf("expected is [%s], actual is [%s]", "1", setting.Value)
 	}
 }
+
+func TestCountView(t *testing.T) {
+	if !View.CountView("127.0.0.1", "/blogs/pipe/test", 1, 0) {
+		t.Errorf("the first view should be counted")
+
+		return
+	}
+	if View.CountView("127.0.0.1", "/blogs/pipe/test", 1, 0) {
+		t.Errorf("the repeated view should be debounced")
+
+		return
+	}
+
+	oldCount, _ := strconv.Atoi(Statistic.GetStatistic(model.SettingNameStatisticViewCount, 1).Value)
+	View.Flush()
+	count, _ := strconv.Atoi(Statistic.GetStatistic(model.SettingNameStatisticViewCount, 1).Value)
+	if oldCount+1 != count {
+		t.Errorf("expected is [%d], actual is [%d]", oldCount+1, count)
+	}
+}
@@ -18,10 +18,13 @@ package service
 
 import (
 	"errors"
+	"strings"
 	"sync"
 
+	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
 )
 
 // Tag service.
@@ -52,7 +55,7 @@ func (srv *tagService) ConsoleGetTags(keyword string, page int, blogID uint64) (
 	if err := db.Model(&model.Tag{}).Order("`id` DESC").
 		Where(where, whereArgs...).
 		Count(&count).Offset(offset).Limit(adminConsoleTagListPageSize).Find(&ret).Error; nil != err {
-		logger.Errorf("get tags failed: " + err.Error())
+		logger.Errorf("get tags failed: %s", err)
 	}
 
 	pagination = util.NewPagination(page, adminConsoleTagListPageSize, adminConsoleTagListWindowSize, count)
@@ -62,7 +65,7 @@ func (srv *tagService) ConsoleGetTags(keyword string, page int, blogID uint64) (
 
 func (srv *tagService) GetTags(size int, blogID uint64) (ret []*model.Tag) {
 	if err := db.Where("`blog_id` = ?", blogID).Order("`article_count` DESC, `id` DESC").Limit(size).Find(&ret).Error; nil != err {
-		logger.Errorf("get tags failed: " + err.Error())
+		logger.Errorf("get tags failed: %s", err)
 	}
 
 	return
@@ -77,27 +80,225 @@ func (srv *tagService) GetTagByTitle(title string, blogID uint64) *model.Tag {
 	return ret
 }
 
+// RemoveTag removes the specified tag, the tag is removed from its articles as well.
 func (srv *tagService) RemoveTag(id, blogID uint64) (err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
 	tag := &model.Tag{}
 	if err := db.Where("`id` = ? AND `blog_id` = ?", id, blogID).Find(tag).Error; nil != err {
 		return err
 	}
 
-	if 0 < tag.ArticleCount {
-		return errors.New("can not remove tags that have articles")
-	}
-
 	tagTitle := tag.Title
 	categories := Category.GetCategoriesByTag(tagTitle, blogID)
 	if 0 < len(categories) {
 		return errors.New("can not remove tags in a category")
 	}
 
-	if err = db.Delete(&tag).Error; nil != err {
-		logger.Errorf("delete tag [" + tagTitle + "] failed: " + err.Error())
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	if err = retagArticlesWithoutTx(tx, tag, "", true); nil != err {
+		return
+	}
+	if err = tx.Delete(tag).Error; nil != err {
+		logger.Errorf("delete tag [%s] failed: %s", tagTitle, err)
+
+		return
+	}
+
+	return nil // trigger commit in the defer
+}
+
+// Tag errors.
+var (
+	ErrTagExists  = errors.New("tag exists")
+	ErrInvalidTag = errors.New("invalid tag title")
+)
+
+// RenameTag renames the specified tag, tags of its articles and categories are updated accordingly. Returns
+// ErrTagExists if there is another tag with the title, merge them instead.
+func (srv *tagService) RenameTag(id uint64, title string, blogID uint64) (err error) {
+	title = strings.TrimSpace(title)
+	if "" == title || strings.Contains(title, ",") || normalizeTagStr(title) != title {
+		return ErrInvalidTag
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	tag := &model.Tag{}
+	if err = db.Where("`id` = ? AND `blog_id` = ?", id, blogID).First(tag).Error; nil != err {
+		return
+	}
+	if title == tag.Title {
+		return nil
+	}
+	if other := srv.GetTagByTitle(title, blogID); nil != other {
+		return ErrTagExists
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	oldTag := *tag
+	if err = tx.Model(tag).Update("title", title).Error; nil != err {
+		return
+	}
+	if err = retagArticlesWithoutTx(tx, &oldTag, title, false); nil != err {
+		return
+	}
+	if err = retagCategoriesWithoutTx(tx, &oldTag, title); nil != err {
+		return
+	}
+
+	return nil // trigger commit in the defer
+}
+
+// MergeTag moves articles and categories of the specified source tag to the specified target tag, then removes the
+// source tag.
+func (srv *tagService) MergeTag(sourceID, targetID, blogID uint64) (err error) {
+	if sourceID == targetID {
+		return ErrInvalidTag
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
 
+	source := &model.Tag{}
+	if err = db.Where("`id` = ? AND `blog_id` = ?", sourceID, blogID).First(source).Error; nil != err {
+		return
+	}
+	target := &model.Tag{}
+	if err = db.Where("`id` = ? AND `blog_id` = ?", targetID, blogID).First(target).Error; nil != err {
+		return
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if nil == err {
+			tx.Commit()
+			cache.Page.Purge(blogID)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	if err = retagArticlesWithoutTx(tx, source, target.Title, true); nil != err {
+		return
+	}
+	if err = retagCategoriesWithoutTx(tx, source, target.Title); nil != err {
 		return
 	}
+	if err = tx.Delete(source).Error; nil != err {
+		return
+	}
+
+	return nil // trigger commit in the defer
+}
+
+// retagArticlesWithoutTx replaces the specified tag with the specified title in tags of its articles, removes the tag
+// from them if the title is empty. Correlations and article counts of tags are rebuilt if rebuild is true, otherwise
+// only the tags text of articles is updated (renaming).
+func retagArticlesWithoutTx(tx *gorm.DB, tag *model.Tag, title string, rebuild bool) error {
+	var articleIDs []uint64
+	if err := tx.Model(&model.Correlation{}).Where("`id2` = ? AND `type` = ? AND `blog_id` = ?",
+		tag.ID, model.CorrelationArticleTag, tag.BlogID).Pluck("`id1`", &articleIDs).Error; nil != err {
+		return err
+	}
+	if 1 > len(articleIDs) {
+		return nil
+	}
+
+	var articles []*model.Article
+	if err := tx.Where("`id` IN (?) AND `blog_id` = ?", articleIDs, tag.BlogID).Find(&articles).Error; nil != err {
+		return err
+	}
+	for _, article := range articles {
+		var tags []string
+		for _, t := range splitTags(article.Tags) {
+			if t == tag.Title {
+				t = title
+			}
+			if "" != t && !contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		tagStr := normalizeTagStr(strings.Join(tags, ","))
+
+		if rebuild {
+			if err := removeTagArticleRels(tx, article); nil != err {
+				return err
+			}
+			article.Tags = tagStr
+			if err := tagArticle(tx, article); nil != err {
+				return err
+			}
+		}
+		article.Tags = tagStr
+		if err := tx.Model(&model.Article{}).Where("`id` = ?", article.ID).Updates(map[string]interface{}{
+			"tags":    article.Tags,
+			"version": article.Version + 1,
+		}).Error; nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retagCategoriesWithoutTx replaces the specified tag with the specified title in tags of its categories.
+func retagCategoriesWithoutTx(tx *gorm.DB, tag *model.Tag, title string) error {
+	var categoryIDs []uint64
+	if err := tx.Model(&model.Correlation{}).Where("`id2` = ? AND `type` = ? AND `blog_id` = ?",
+		tag.ID, model.CorrelationCategoryTag, tag.BlogID).Pluck("`id1`", &categoryIDs).Error; nil != err {
+		return err
+	}
+	if 1 > len(categoryIDs) {
+		return nil
+	}
+
+	var categories []*model.Category
+	if err := tx.Where("`id` IN (?) AND `blog_id` = ?", categoryIDs, tag.BlogID).Find(&categories).Error; nil != err {
+		return err
+	}
+	for _, category := range categories {
+		var tags []string
+		for _, t := range splitTags(category.Tags) {
+			if t == tag.Title {
+				t = title
+			}
+			if !contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		category.Tags = strings.Join(tags, ",")
+		if err := tx.Where("`id1` = ? AND `type` = ? AND `blog_id` = ?", category.ID, model.CorrelationCategoryTag, tag.BlogID).
+			Delete(&model.Correlation{}).Error; nil != err {
+			return err
+		}
+		if err := tx.Model(category).Update("tags", category.Tags).Error; nil != err {
+			return err
+		}
+		if err := tagCategory(tx, category); nil != err {
+			return err
+		}
+	}
 
 	return nil
 }
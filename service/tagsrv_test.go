@@ -16,7 +16,11 @@
 
 package service
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
 
 func TestGetTags(t *testing.T) {
 	tags := Tag.GetTags(2, 1)
@@ -24,3 +28,49 @@ func TestGetTags(t *testing.T) {
 		t.Errorf("tags is nil")
 	}
 }
+
+func TestRenameAndMergeTag(t *testing.T) {
+	article := &model.Article{
+		AuthorID: 1,
+		Title:    "Tag article",
+		Tags:     "TagA,TagB",
+		Content:  "Tag article content",
+		Path:     "/tag-article",
+		BlogID:   1,
+	}
+	if err := Article.AddArticle(article); nil != err {
+		t.Error(err)
+
+		return
+	}
+	defer Article.RemoveArticle(article.ID, 1)
+
+	tagA := Tag.GetTagByTitle("TagA", 1)
+	if err := Tag.RenameTag(tagA.ID, "TagC", 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "TagC,TagB" != Article.ConsoleGetArticle(article.ID).Tags {
+		t.Errorf("expected is [%s], actual is [%s]", "TagC,TagB", Article.ConsoleGetArticle(article.ID).Tags)
+	}
+	if err := Tag.RenameTag(tagA.ID, "TagB", 1); ErrTagExists != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrTagExists, err)
+	}
+
+	tagB := Tag.GetTagByTitle("TagB", 1)
+	if err := Tag.MergeTag(tagA.ID, tagB.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "TagB" != Article.ConsoleGetArticle(article.ID).Tags {
+		t.Errorf("expected is [%s], actual is [%s]", "TagB", Article.ConsoleGetArticle(article.ID).Tags)
+	}
+	if nil != Tag.GetTagByTitle("TagC", 1) {
+		t.Error("merged tag should be removed")
+	}
+	if 1 != Tag.GetTagByTitle("TagB", 1).ArticleCount {
+		t.Errorf("expected is [%d], actual is [%d]", 1, Tag.GetTagByTitle("TagB", 1).ArticleCount)
+	}
+}
@@ -0,0 +1,294 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/cache"
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// TOTP service.
+var TOTP = &totpService{
+	mutex: &sync.Mutex{},
+}
+
+type totpService struct {
+	mutex *sync.Mutex
+}
+
+// Backup codes arguments.
+const (
+	totpBackupCodeCount  = 10
+	totpBackupCodeLength = 10
+)
+
+// totpIssuer is the issuer shown in the authenticator apps.
+const totpIssuer = "Pipe"
+
+// Errors of two-factor authentication.
+var (
+	ErrTOTPEnabled     = errors.New("two-factor authentication has been enabled")
+	ErrTOTPNotEnrolled = errors.New("two-factor authentication enrollment has not been started")
+	ErrTOTPNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTOTPCode = errors.New("verification code is invalid")
+)
+
+// StartEnrollment generates a new secret for the specified user, the secret takes effect after being confirmed by
+// EnableTOTP. Returns the secret and its otpauth URI.
+func (srv *totpService) StartEnrollment(userID uint64) (secret, uri string, err error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err {
+		return "", "", err
+	}
+	if user.TOTPEnabled {
+		return "", "", ErrTOTPEnabled
+	}
+
+	secret = util.GenerateTOTPSecret()
+	if err = srv.updateUser(user, map[string]interface{}{"TOTPSecret": secret}); nil != err {
+		return "", "", err
+	}
+
+	return secret, util.TOTPURI(secret, user.Name, totpIssuer), nil
+}
+
+// EnableTOTP enables two-factor authentication of the specified user if the code matches the enrolling secret.
+// Returns the generated backup codes, which are only stored hashed.
+func (srv *totpService) EnableTOTP(userID uint64, code string) ([]string, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPEnabled
+	}
+	if "" == user.TOTPSecret {
+		return nil, ErrTOTPNotEnrolled
+	}
+	step, ok := util.MatchTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	codes, hashes := newBackupCodes()
+	if err := srv.updateUser(user, map[string]interface{}{
+		"TOTPEnabled":     true,
+		"TOTPBackupCodes": hashes,
+		"TOTPLastStep":    step,
+	}); nil != err {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP disables two-factor authentication of the specified user, the code could be a TOTP code or a backup
+// code.
+func (srv *totpService) DisableTOTP(userID uint64, code string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if !srv.verify(user, code) {
+		return ErrInvalidTOTPCode
+	}
+
+	return srv.updateUser(user, map[string]interface{}{
+		"TOTPSecret":      "",
+		"TOTPEnabled":     false,
+		"TOTPBackupCodes": "",
+		"TOTPLastStep":    0,
+	})
+}
+
+// ResetTOTP disables two-factor authentication of the specified user without verification, it's used by operators
+// to recover accounts which lost their authenticators and backup codes.
+func (srv *totpService) ResetTOTP(userID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+
+	return srv.updateUser(user, map[string]interface{}{
+		"TOTPSecret":      "",
+		"TOTPEnabled":     false,
+		"TOTPBackupCodes": "",
+		"TOTPLastStep":    0,
+	})
+}
+
+// RegenerateBackupCodes replaces the backup codes of the specified user after verifying the TOTP code.
+func (srv *totpService) RegenerateBackupCodes(userID uint64, code string) ([]string, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+	if !srv.verifyTOTPCode(user, code) {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	codes, hashes := newBackupCodes()
+	if err := srv.updateUser(user, map[string]interface{}{"TOTPBackupCodes": hashes}); nil != err {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify checks the second factor of the specified user, the code could be a TOTP code or a backup code. A backup
+// code can only be used once.
+func (srv *totpService) Verify(userID uint64, code string) bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user, err := srv.getUser(userID)
+	if nil != err || !user.TOTPEnabled {
+		return false
+	}
+
+	return srv.verify(user, code)
+}
+
+// GetBackupCodeCount returns the count of the unused backup codes of the specified user.
+func (srv *totpService) GetBackupCodeCount(user *model.User) int {
+	if "" == user.TOTPBackupCodes {
+		return 0
+	}
+
+	return len(strings.Split(user.TOTPBackupCodes, ","))
+}
+
+// verify verifies the specified code, consumes it if it's a backup code.
+func (srv *totpService) verify(user *model.User, code string) bool {
+	code = strings.TrimSpace(code)
+	if srv.verifyTOTPCode(user, code) {
+		return true
+	}
+
+	hash := hashBackupCode(code)
+	hashes := strings.Split(user.TOTPBackupCodes, ",")
+	for i, h := range hashes {
+		if "" == h || 1 != subtle.ConstantTimeCompare([]byte(h), []byte(hash)) {
+			continue
+		}
+
+		remains := append(hashes[:i:i], hashes[i+1:]...)
+		if err := srv.updateUser(user, map[string]interface{}{"TOTPBackupCodes": strings.Join(remains, ",")}); nil != err {
+			logger.Errorf("consumes backup code of user [%d] failed: %s", user.ID, err)
+
+			return false
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// verifyTOTPCode verifies the specified TOTP code of the specified user, a code is accepted only once: the steps not
+// after the last accepted one are rejected.
+func (srv *totpService) verifyTOTPCode(user *model.User, code string) bool {
+	step, ok := util.MatchTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false
+	}
+
+	if err := srv.updateUser(user, map[string]interface{}{"TOTPLastStep": step}); nil != err {
+		logger.Errorf("updates last TOTP step of user [%d] failed: %s", user.ID, err)
+
+		return false
+	}
+
+	return true
+}
+
+// getUser loads the specified user from database bypassing the cache, so the cached one is not modified before
+// the changes are saved.
+func (srv *totpService) getUser(userID uint64) (*model.User, error) {
+	ret := &model.User{}
+	if err := db.First(ret, userID).Error; nil != err {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (srv *totpService) updateUser(user *model.User, columns map[string]interface{}) error {
+	if err := db.Model(user).UpdateColumns(columns).Error; nil != err {
+		return err
+	}
+
+	cache.User.Put(user)
+
+	return nil
+}
+
+// newBackupCodes generates backup codes, returns the codes and their comma separated hashes.
+func newBackupCodes() (codes []string, hashes string) {
+	var hashList []string
+	for i := 0; i < totpBackupCodeCount; i++ {
+		buf := make([]byte, totpBackupCodeLength/2)
+		if _, err := rand.Read(buf); nil != err {
+			panic(err)
+		}
+		code := hex.EncodeToString(buf)
+		codes = append(codes, code)
+		hashList = append(hashList, hashBackupCode(code))
+	}
+
+	return codes, strings.Join(hashList, ",")
+}
+
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(code)))
+
+	return hex.EncodeToString(sum[:])
+}
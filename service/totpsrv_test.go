@@ -0,0 +1,98 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b3log/pipe/util"
+)
+
+func TestTOTP(t *testing.T) {
+	secret, uri, err := TOTP.StartEnrollment(1)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if "" == uri {
+		t.Errorf("otpauth URI should not be empty")
+	}
+	if _, err := TOTP.EnableTOTP(1, "000000x"); ErrInvalidTOTPCode != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidTOTPCode, err)
+	}
+
+	code, _ := util.TOTPCode(secret, time.Now())
+	backupCodes, err := TOTP.EnableTOTP(1, code)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if totpBackupCodeCount != len(backupCodes) {
+		t.Errorf("expected is [%d], actual is [%d]", totpBackupCodeCount, len(backupCodes))
+	}
+	if !User.GetUser(1).TOTPEnabled {
+		t.Errorf("TOTP should be enabled")
+	}
+	if TOTP.Verify(1, code) {
+		t.Errorf("accepted code should not be replayed")
+	}
+
+	if !TOTP.Verify(1, backupCodes[0]) {
+		t.Errorf("verify backup code failed")
+	}
+	if TOTP.Verify(1, backupCodes[0]) {
+		t.Errorf("backup code should be consumed")
+	}
+	if count := TOTP.GetBackupCodeCount(User.GetUser(1)); totpBackupCodeCount-1 != count {
+		t.Errorf("expected is [%d], actual is [%d]", totpBackupCodeCount-1, count)
+	}
+
+	if err := TOTP.DisableTOTP(1, backupCodes[1]); nil != err {
+		t.Error(err)
+	}
+	if User.GetUser(1).TOTPEnabled {
+		t.Errorf("TOTP should be disabled")
+	}
+}
+
+func TestResetTOTP(t *testing.T) {
+	if err := TOTP.ResetTOTP(1); ErrTOTPNotEnabled != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrTOTPNotEnabled, err)
+	}
+
+	secret, _, err := TOTP.StartEnrollment(1)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	code, _ := util.TOTPCode(secret, time.Now())
+	if _, err = TOTP.EnableTOTP(1, code); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err = TOTP.ResetTOTP(1); nil != err {
+		t.Error(err)
+	}
+	if user := User.GetUser(1); user.TOTPEnabled || "" != user.TOTPSecret {
+		t.Errorf("TOTP should be reset")
+	}
+}
@@ -65,7 +65,7 @@ func (srv *upgradeService) Perform() {
 
 func perform189_190() {
 	fromVer := "1.8.9"
-	logger.Infof("upgrading from version [" + fromVer + "] to version [" + model.Version + "]....")
+	logger.Infof("upgrading from version [%s] to version [%s]....", fromVer, model.Version)
 
 	var verSettings []model.Setting
 	if err := db.Model(&model.Setting{}).Where("`name`= ?", model.SettingNameSystemVer).Find(&verSettings).Error; nil != err {
@@ -83,7 +83,7 @@ func perform189_190() {
 	}
 	tx.Commit()
 
-	logger.Infof("upgraded from version [" + fromVer + "] to version [" + model.Version + "] successfully")
+	logger.Infof("upgraded from version [%s] to version [%s] successfully", fromVer, model.Version)
 }
 
 func perform188_189() {
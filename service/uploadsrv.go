@@ -0,0 +1,112 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+)
+
+// Upload service, applies the upload constraints of blogs and scans uploads before they are persisted.
+var Upload = &uploadService{}
+
+type uploadService struct {
+}
+
+// Errors of checking uploads.
+var (
+	ErrUploadTooLarge = errors.New("file size exceeds the limit")
+	ErrUploadType     = errors.New("file type is not allowed")
+	ErrUploadInfected = errors.New("file is infected")
+	ErrUploadStore    = errors.New("store file failed")
+)
+
+// GetMaxSize gets the max upload size (in byte) of the specified blog.
+func (srv *uploadService) GetMaxSize(blogID uint64) int64 {
+	maxSize := model.SettingUploadMaxSizeDefault
+	if setting := Setting.GetSetting(model.SettingCategoryUpload, model.SettingNameUploadMaxSize, blogID); nil != setting {
+		if v, err := strconv.Atoi(setting.Value); nil == err && 0 < v {
+			maxSize = v
+		}
+	}
+
+	return int64(maxSize) * 1024
+}
+
+// GetMIMETypes gets the allowed upload MIME types of the specified blog.
+func (srv *uploadService) GetMIMETypes(blogID uint64) (ret []string) {
+	mimeTypes := model.SettingUploadMIMETypesDefault
+	if setting := Setting.GetSetting(model.SettingCategoryUpload, model.SettingNameUploadMIMETypes, blogID); nil != setting &&
+		"" != strings.TrimSpace(setting.Value) {
+		mimeTypes = setting.Value
+	}
+	for _, mimeType := range strings.Split(mimeTypes, ",") {
+		if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); "" != mimeType {
+			ret = append(ret, mimeType)
+		}
+	}
+
+	return
+}
+
+// Check checks the specified upload against the constraints of the specified blog, the MIME type is sniffed from the
+// content instead of trusting the client. The upload is scanned with the configured virus scanner at last, uploads
+// are rejected if the scanner is unavailable.
+func (srv *uploadService) Check(data []byte, blogID uint64) (mimeType string, err error) {
+	if srv.GetMaxSize(blogID) < int64(len(data)) {
+		return "", ErrUploadTooLarge
+	}
+
+	mimeType = http.DetectContentType(data)
+	if i := strings.Index(mimeType, ";"); 0 < i {
+		mimeType = mimeType[:i]
+	}
+	allowed := false
+	for _, allowedType := range srv.GetMIMETypes(blogID) {
+		if allowedType == mimeType || (strings.HasSuffix(allowedType, "/*") &&
+			strings.HasPrefix(mimeType, strings.TrimSuffix(allowedType, "*"))) {
+			allowed = true
+
+			break
+		}
+	}
+	if !allowed {
+		return mimeType, ErrUploadType
+	}
+
+	if "" == model.Conf.UploadScanner {
+		return mimeType, nil
+	}
+	infected, signature, err := util.ScanFile(model.Conf.UploadScanner, data)
+	if nil != err {
+		logger.Errorf("scan upload failed: %s", err)
+
+		return mimeType, errors.New("scan file failed")
+	}
+	if infected {
+		logger.Warnf("rejected infected upload [signature=%s] of blog [%d]", signature, blogID)
+
+		return mimeType, ErrUploadInfected
+	}
+
+	return mimeType, nil
+}
@@ -0,0 +1,49 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
+
+func TestCheckUpload(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if mimeType, err := Upload.Check(png, 1); nil != err || "image/png" != mimeType {
+		t.Errorf("expected is [image/png, <nil>], actual is [%s, %v]", mimeType, err)
+	}
+	if _, err := Upload.Check([]byte("<html><script>alert(1)</script></html>"), 1); ErrUploadType != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrUploadType, err)
+	}
+
+	settings := []*model.Setting{
+		{Name: model.SettingNameUploadMaxSize, Value: "1"},
+		{Name: model.SettingNameUploadMIMETypes, Value: "image/*, text/plain"},
+	}
+	if err := Setting.UpdateSettings(model.SettingCategoryUpload, settings, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if _, err := Upload.Check([]byte("plain text"), 1); nil != err {
+		t.Error(err)
+	}
+	if _, err := Upload.Check(make([]byte, 2048), 1); ErrUploadTooLarge != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrUploadTooLarge, err)
+	}
+}
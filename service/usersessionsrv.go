@@ -0,0 +1,170 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+)
+
+// User session service.
+var UserSession = &userSessionService{
+	mutex: &sync.Mutex{},
+}
+
+type userSessionService struct {
+	mutex *sync.Mutex
+}
+
+// ErrSessionNotFound means the session to revoke does not exist.
+var ErrSessionNotFound = errors.New("not found session")
+
+// sessionTouchInterval is the min interval of refreshing the last seen time of a session.
+const sessionTouchInterval = 5 * time.Minute
+
+// AddSession registers a new session of the specified user, returns the session ID to keep in the session cookie.
+// Expired sessions of the user are cleaned up meanwhile.
+func (srv *userSessionService) AddSession(userID uint64, ip, userAgent string) (string, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	now := time.Now()
+	if err := db.Unscoped().Where("`user_id` = ? AND `expired_at` < ?", userID, now).
+		Delete(&model.UserSession{}).Error; nil != err {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); nil != err {
+		return "", err
+	}
+	sid := hex.EncodeToString(buf)
+	if runes := []rune(userAgent); 255 < len(runes) {
+		userAgent = string(runes[:255])
+	}
+	session := &model.UserSession{
+		SIDHash:    hashSessionID(sid),
+		UserID:     userID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiredAt:  now.Add(sessionMaxAge()),
+	}
+	if err := db.Create(session).Error; nil != err {
+		return "", err
+	}
+
+	return sid, nil
+}
+
+// GetSession gets the session specified by the session ID, returns nil if not found.
+func (srv *userSessionService) GetSession(sid string) *model.UserSession {
+	ret := &model.UserSession{}
+	if err := db.Where("`sid_hash` = ?", hashSessionID(sid)).First(ret).Error; nil != err {
+		return nil
+	}
+
+	return ret
+}
+
+// TouchSession checks whether the session specified by the session ID of the specified user is alive, that is neither
+// revoked nor expired. The last seen time and IP of the session are refreshed at most every sessionTouchInterval.
+func (srv *userSessionService) TouchSession(sid string, userID uint64, ip string) bool {
+	session := srv.GetSession(sid)
+	now := time.Now()
+	if nil == session || userID != session.UserID || now.After(session.ExpiredAt) {
+		return false
+	}
+	if sessionTouchInterval > now.Sub(session.LastSeenAt) {
+		return true
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if err := db.Model(session).UpdateColumns(map[string]interface{}{
+		"LastSeenAt": now,
+		"ExpiredAt":  now.Add(sessionMaxAge()),
+		"IP":         ip,
+	}).Error; nil != err {
+		logger.Errorf("touch session [%d] failed: %s", session.ID, err)
+	}
+
+	return true
+}
+
+// GetSessions gets alive sessions of the specified user, the most recently seen first.
+func (srv *userSessionService) GetSessions(userID uint64) (ret []*model.UserSession) {
+	if err := db.Where("`user_id` = ? AND `expired_at` > ?", userID, time.Now()).
+		Order("`last_seen_at` DESC").Find(&ret).Error; nil != err {
+		logger.Errorf("get sessions of user [%d] failed: %s", userID, err)
+	}
+
+	return
+}
+
+// RemoveSession revokes the session specified by the id of the specified user.
+func (srv *userSessionService) RemoveSession(id, userID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	result := db.Unscoped().Where("`id` = ? AND `user_id` = ?", id, userID).Delete(&model.UserSession{})
+	if nil != result.Error {
+		return result.Error
+	}
+	if 1 > result.RowsAffected {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RemoveSessionBySID revokes the session specified by the session ID, it's used in logging out.
+func (srv *userSessionService) RemoveSessionBySID(sid string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Unscoped().Where("`sid_hash` = ?", hashSessionID(sid)).Delete(&model.UserSession{}).Error
+}
+
+// RemoveUserSessions revokes all sessions of the specified user.
+func (srv *userSessionService) RemoveUserSessions(userID uint64) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return db.Unscoped().Where("`user_id` = ?", userID).Delete(&model.UserSession{}).Error
+}
+
+func sessionMaxAge() time.Duration {
+	if 1 > model.Conf.SessionMaxAge {
+		return 30 * 24 * time.Hour
+	}
+
+	return time.Duration(model.Conf.SessionMaxAge) * time.Second
+}
+
+func hashSessionID(sid string) string {
+	sum := sha256.Sum256([]byte(sid))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import "testing"
+
+func TestUserSessions(t *testing.T) {
+	sid, err := UserSession.AddSession(1, "127.0.0.1", "Mozilla/5.0")
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	other, err := UserSession.AddSession(1, "127.0.0.2", "curl/7.58.0")
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if !UserSession.TouchSession(sid, 1, "127.0.0.1") {
+		t.Errorf("session should be alive")
+	}
+	if UserSession.TouchSession(sid, 2, "127.0.0.1") {
+		t.Errorf("session should not be alive for another user")
+	}
+
+	sessions := UserSession.GetSessions(1)
+	if 2 != len(sessions) {
+		t.Errorf("expected is [%d], actual is [%d]", 2, len(sessions))
+
+		return
+	}
+	otherSession := UserSession.GetSession(other)
+	if err := UserSession.RemoveSession(otherSession.ID, 2); ErrSessionNotFound != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrSessionNotFound, err)
+	}
+	if err := UserSession.RemoveSession(otherSession.ID, 1); nil != err {
+		t.Error(err)
+	}
+	if UserSession.TouchSession(other, 1, "127.0.0.2") {
+		t.Errorf("revoked session should not be alive")
+	}
+
+	if err := UserSession.RemoveUserSessions(1); nil != err {
+		t.Error(err)
+	}
+	if 0 != len(UserSession.GetSessions(1)) {
+		t.Errorf("sessions should be revoked")
+	}
+}
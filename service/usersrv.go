@@ -17,11 +17,14 @@
 package service
 
 import (
+	"errors"
+	"regexp"
 	"sync"
 
 	"github.com/b3log/pipe/cache"
 	"github.com/b3log/pipe/model"
 	"github.com/b3log/pipe/util"
+	"github.com/jinzhu/gorm"
 )
 
 // User service.
@@ -33,6 +36,22 @@ type userService struct {
 	mutex *sync.Mutex
 }
 
+// Errors of renaming users.
+var (
+	ErrInvalidUsername = errors.New("username should be 1-32 letters, digits, underscores or hyphens")
+	ErrUsernameTaken   = errors.New("username has been taken")
+	ErrReservedName    = errors.New("username is reserved")
+)
+
+// Errors of changing user roles.
+var (
+	ErrInvalidRole   = errors.New("role should be editor or author")
+	ErrNotBlogMember = errors.New("user is not a member of the blog")
+	ErrBlogAdminRole = errors.New("role of the blog admin can not be changed")
+)
+
+var usernameRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]{1,32}$")
+
 // User pagination arguments of admin console.
 const (
 	adminConsoleUserListPageSize   = 15
@@ -52,7 +71,7 @@ func (srv *userService) GetBlogAdmin(blogID uint64) *model.User {
 	rel := &model.Correlation{}
 	if err := db.Where("`id1` = ? AND `type` = ? AND `blog_id` = ?",
 		blogID, model.UserRoleBlogAdmin, blogID).First(rel).Error; nil != err {
-		logger.Errorf("can't get blog admin: " + err.Error())
+		logger.Errorf("can't get blog admin: %s", err)
 
 		return nil
 	}
@@ -63,7 +82,7 @@ func (srv *userService) GetBlogAdmin(blogID uint64) *model.User {
 func (srv *userService) GetPlatformAdmin() *model.User {
 	rel := &model.Correlation{}
 	if err := db.Where("`id1` = ?", 1).Order("`id2` asc").First(rel).Error; nil != err {
-		logger.Errorf("can't get platform admin: " + err.Error())
+		logger.Errorf("can't get platform admin: %s", err)
 
 		return nil
 	}
@@ -99,6 +118,7 @@ func (srv *userService) UpdateUser(user *model.User) error {
 	tx.Commit()
 
 	cache.User.Put(user)
+	cache.Page.PurgeAll()
 
 	return nil
 }
@@ -128,6 +148,112 @@ func (srv *userService) GetUser(userID uint64) *model.User {
 	return ret
 }
 
+// GetUserByAlias gets the user renamed from the specified old name, returns nil if not found.
+func (srv *userService) GetUserByAlias(name string) *model.User {
+	alias := &model.Alias{}
+	if err := db.Where("`name` = ?", name).First(alias).Error; nil != err {
+		return nil
+	}
+
+	return srv.GetUser(alias.UserID)
+}
+
+// RenameUser renames the specified user. The old name is kept as an alias so that the old blog paths can be
+// redirected, the blog URL and the links to it in articles and navigations are updated if the blog is served under
+// the default path.
+func (srv *userService) RenameUser(userID uint64, name string) error {
+	if !usernameRegexp.MatchString(name) {
+		return ErrInvalidUsername
+	}
+	if util.IsReservedName(name) {
+		return ErrReservedName
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	user := &model.User{}
+	if err := db.First(user, userID).Error; nil != err {
+		return err
+	}
+	oldName := user.Name
+	if oldName == name {
+		return nil
+	}
+
+	count := 0
+	if err := db.Model(&model.User{}).Where("`name` = ?", name).Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return ErrUsernameTaken
+	}
+	if err := db.Model(&model.Alias{}).Where("`name` = ? AND `user_id` <> ?", name, userID).Count(&count).Error; nil != err {
+		return err
+	}
+	if 0 < count {
+		return ErrUsernameTaken
+	}
+
+	blog := srv.GetOwnBlog(userID)
+	tx := db.Begin()
+	if err := tx.Model(user).Update("name", name).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if err := tx.Where("`name` = ? AND `user_id` = ?", name, userID).Delete(&model.Alias{}).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if err := tx.Create(&model.Alias{Name: oldName, UserID: userID}).Error; nil != err {
+		tx.Rollback()
+
+		return err
+	}
+	if nil != blog {
+		if err := renameBlogURLWithoutTx(tx, blog.ID, oldName, name); nil != err {
+			tx.Rollback()
+
+			return err
+		}
+	}
+	tx.Commit()
+
+	cache.User.Put(user)
+	cache.Setting.Purge()
+	cache.Page.PurgeAll()
+
+	return nil
+}
+
+// renameBlogURLWithoutTx updates the blog URL of the specified blog after its admin has been renamed, links to the old
+// blog URL in articles and navigations are replaced too. Blogs not served under the default path are left untouched.
+func renameBlogURLWithoutTx(tx *gorm.DB, blogID uint64, oldName, name string) error {
+	oldURL := model.Conf.Server + util.PathBlogs + "/" + oldName
+	newURL := model.Conf.Server + util.PathBlogs + "/" + name
+	setting := &model.Setting{}
+	if err := tx.Where("`category` = ? AND `name` = ? AND `blog_id` = ?",
+		model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID).First(setting).Error; nil != err {
+		return err
+	}
+	if oldURL != setting.Value {
+		return nil
+	}
+
+	if err := tx.Model(setting).Update("value", newURL).Error; nil != err {
+		return err
+	}
+	if err := tx.Model(&model.Article{}).Where("`blog_id` = ?", blogID).
+		UpdateColumn("content", gorm.Expr("REPLACE(`content`, ?, ?)", oldURL+"/", newURL+"/")).Error; nil != err {
+		return err
+	}
+
+	return tx.Model(&model.Navigation{}).Where("`blog_id` = ? AND `url` LIKE ?", blogID, oldURL+"%").
+		UpdateColumn("url", gorm.Expr("REPLACE(`url`, ?, ?)", oldURL, newURL)).Error
+}
+
 // UserBlog represents user blog.
 type UserBlog struct {
 	ID               uint64 `json:"id,omitempty"` // blog ID
@@ -145,13 +271,13 @@ func (srv *userService) GetBlogUsers(page int, blogID uint64) (ret []*model.User
 	if err := db.Model(&model.Correlation{}).
 		Where("`id1` = ? AND `type` = ? AND `blog_id` = ?", blogID, model.CorrelationBlogUser, blogID).
 		Count(&count).Offset(offset).Limit(adminConsoleUserListPageSize).Find(&correlations).Error; nil != err {
-		logger.Errorf("get users failed: " + err.Error())
+		logger.Errorf("get users failed: %s", err)
 	}
 
 	for _, rel := range correlations {
 		user := &model.User{}
 		if err := db.Where("`id` = ?", rel.ID2).Find(user).Error; nil != err {
-			logger.Errorf("get user failed: " + err.Error())
+			logger.Errorf("get user failed: %s", err)
 
 			continue
 		}
@@ -184,7 +310,9 @@ func (srv *userService) GetOwnBlog(userID uint64) *UserBlog {
 	}
 }
 
-func (srv *userService) GetRole(userID, blogID uint) int {
+// GetRole returns the role of the specified user in the specified blog, returns UserRoleNoLogin if the user is not a
+// member of the blog.
+func (srv *userService) GetRole(userID, blogID uint64) int {
 	rel := &model.Correlation{}
 	if err := db.Where("`id1` = ? AND `id2` = ? AND `type` = ?",
 		blogID, userID, model.CorrelationBlogUser).First(rel).Error; nil != err {
@@ -194,6 +322,13 @@ func (srv *userService) GetRole(userID, blogID uint) int {
 	return rel.Int1
 }
 
+// IsPlatformAdmin checks whether the specified user is an admin of the platform blog.
+func (srv *userService) IsPlatformAdmin(userID uint64) bool {
+	role := srv.GetRole(userID, PlatformBlogID)
+
+	return model.UserRoleNoLogin != role && model.UserRoleBlogAdmin >= role
+}
+
 func (srv *userService) GetUserBlogs(userID uint64) (ret []*UserBlog) {
 	var correlations []*model.Correlation
 	if err := db.Where("`id2` = ? AND `type` = ?", userID, model.CorrelationBlogUser).
@@ -262,6 +397,27 @@ func (srv *userService) AddUserToBlog(userID, blogID uint64) error {
 	return nil
 }
 
+// UpdateRole changes the role of the specified user in the specified blog to editor or author.
+func (srv *userService) UpdateRole(userID, blogID uint64, role int) error {
+	if model.UserRoleBlogEditor != role && model.UserRoleBlogUser != role {
+		return ErrInvalidRole
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	rel := &model.Correlation{}
+	if err := db.Where("`id1` = ? AND `id2` = ? AND `type` = ?",
+		blogID, userID, model.CorrelationBlogUser).First(rel).Error; nil != err {
+		return ErrNotBlogMember
+	}
+	if model.UserRoleBlogAdmin >= rel.Int1 { // a blog has only one admin, who owns it
+		return ErrBlogAdminRole
+	}
+
+	return db.Model(rel).Update("int1", role).Error
+}
+
 func (srv *userService) GetTopBlogs(size int) (ret []*UserBlog) {
 	var users []*model.User
 	if err := db.Model(&model.User{}).Order("`total_article_count` DESC, `id` DESC").Limit(size).
@@ -16,7 +16,12 @@
 
 package service
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/b3log/pipe/model"
+)
 
 func TestGetUserByName(t *testing.T) {
 	user := User.GetUserByName(testPlatformAdminName)
@@ -61,3 +66,77 @@ func TestGetUserBlogs(t *testing.T) {
 		return
 	}
 }
+
+func TestRenameUser(t *testing.T) {
+	if err := User.RenameUser(1, "pipe/"); ErrInvalidUsername != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidUsername, err)
+	}
+	if err := User.RenameUser(1, "admin"); ErrReservedName != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrReservedName, err)
+	}
+
+	if err := User.RenameUser(1, "pipe2"); nil != err {
+		t.Error(err)
+
+		return
+	}
+	user := User.GetUserByAlias(testPlatformAdminName)
+	if nil == user || "pipe2" != user.Name {
+		t.Errorf("get user by alias failed")
+	}
+	blog := User.GetOwnBlog(1)
+	if strings.HasSuffix(blog.URL, "/blogs/"+testPlatformAdminName) {
+		t.Errorf("blog URL [%s] is not renamed", blog.URL)
+	}
+
+	if err := User.RenameUser(1, testPlatformAdminName); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if nil == User.GetUserByName(testPlatformAdminName) {
+		t.Errorf("rename user back failed")
+	}
+	if nil != User.GetUserByAlias(testPlatformAdminName) {
+		t.Errorf("alias of the current name should be removed")
+	}
+}
+
+func TestUpdateRole(t *testing.T) {
+	user := &model.User{Name: "roleuser"}
+	if err := User.AddUser(user); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if err := User.AddUserToBlog(user.ID, 1); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if role := User.GetRole(user.ID, 1); model.UserRoleBlogUser != role {
+		t.Errorf("expected is [%d], actual is [%d]", model.UserRoleBlogUser, role)
+	}
+
+	if err := User.UpdateRole(user.ID, 1, model.UserRoleBlogEditor); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if role := User.GetRole(user.ID, 1); model.UserRoleBlogEditor != role {
+		t.Errorf("expected is [%d], actual is [%d]", model.UserRoleBlogEditor, role)
+	}
+
+	if err := User.UpdateRole(user.ID, 1, model.UserRoleBlogAdmin); ErrInvalidRole != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrInvalidRole, err)
+	}
+	if err := User.UpdateRole(1, 1, model.UserRoleBlogUser); ErrBlogAdminRole != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrBlogAdminRole, err)
+	}
+	if err := User.UpdateRole(user.ID, 2, model.UserRoleBlogUser); ErrNotBlogMember != err {
+		t.Errorf("expected is [%v], actual is [%v]", ErrNotBlogMember, err)
+	}
+	if role := User.GetRole(user.ID, 2); model.UserRoleNoLogin != role {
+		t.Errorf("expected is [%d], actual is [%d]", model.UserRoleNoLogin, role)
+	}
+}
@@ -0,0 +1,98 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/jinzhu/gorm"
+)
+
+// View service, counts views of blogs and articles. Repeated views of a visitor are debounced and counts are
+// buffered in memory then flushed in batches by the cron.
+var View = &viewService{
+	mutex:        &sync.Mutex{},
+	seen:         map[string]time.Time{},
+	blogViews:    map[uint64]int{},
+	articleViews: map[uint64]int{},
+}
+
+type viewService struct {
+	mutex *sync.Mutex
+
+	seen         map[string]time.Time // visitor + page -> last counted time
+	blogViews    map[uint64]int       // blog ID -> pending view count
+	articleViews map[uint64]int       // article ID -> pending view count
+}
+
+// viewDebounceWindow is the window in which repeated views of a visitor on the same page count once.
+const viewDebounceWindow = 30 * time.Minute
+
+// maxSeenViews is the max count of remembered views, views are counted without debouncing when exceeded until the
+// next flush expires the remembered ones.
+const maxSeenViews = 64 * 1024
+
+// CountView counts a view of the specified page by the specified visitor (session user or IP), the article ID is 0
+// if the page is not an article. Returns false if the view is debounced.
+func (srv *viewService) CountView(visitor, page string, blogID, articleID uint64) bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	now := time.Now()
+	key := visitor + " " + page
+	if last, ok := srv.seen[key]; ok && now.Sub(last) < viewDebounceWindow {
+		return false
+	}
+	if maxSeenViews > len(srv.seen) {
+		srv.seen[key] = now
+	}
+
+	srv.blogViews[blogID]++
+	if 0 < articleID {
+		srv.articleViews[articleID]++
+	}
+
+	return true
+}
+
+// Flush writes the pending view counts to the database and expires the remembered views.
+func (srv *viewService) Flush() {
+	srv.mutex.Lock()
+	blogViews, articleViews := srv.blogViews, srv.articleViews
+	srv.blogViews, srv.articleViews = map[uint64]int{}, map[uint64]int{}
+	now := time.Now()
+	for key, last := range srv.seen {
+		if viewDebounceWindow <= now.Sub(last) {
+			delete(srv.seen, key)
+		}
+	}
+	srv.mutex.Unlock()
+
+	for articleID, count := range articleViews {
+		if err := db.Model(&model.Article{}).Where("`id` = ?", articleID).
+			UpdateColumn("view_count", gorm.Expr("`view_count` + ?", count)).Error; nil != err {
+			logger.Errorf("flush view count of article [%d] failed: %s", articleID, err)
+		}
+	}
+	for blogID, count := range blogViews {
+		if err := Statistic.AddViewCount(blogID, count); nil != err {
+			logger.Errorf("flush view count of blog [%d] failed: %s", blogID, err)
+		}
+	}
+}
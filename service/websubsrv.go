@@ -0,0 +1,94 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/b3log/pipe/model"
+	"github.com/b3log/pipe/util"
+	"github.com/parnurzeal/gorequest"
+)
+
+// WebSub service, publishes feed updates to the configured WebSub (PubSubHubbub) hub.
+var WebSub = &webSubService{}
+
+type webSubService struct {
+}
+
+// GetHub gets the WebSub hub URL of the specified blog, returns "" if not configured.
+func (srv *webSubService) GetHub(blogID uint64) string {
+	hubSetting := Setting.GetSetting(model.SettingCategoryFeed, model.SettingNameFeedWebSubHub, blogID)
+	if nil == hubSetting {
+		return ""
+	}
+
+	return hubSetting.Value
+}
+
+// GetTopics gets the WebSub topic URLs, which are the feed URLs of the specified blog.
+func (srv *webSubService) GetTopics(blogID uint64) []string {
+	blogURLSetting := Setting.GetSetting(model.SettingCategoryBasic, model.SettingNameBasicBlogURL, blogID)
+
+	return []string{blogURLSetting.Value + util.PathAtom, blogURLSetting.Value + util.PathRSS}
+}
+
+// Publish pings the configured hub that feeds of the specified blog have been updated, returns the error of the last
+// failed topic.
+func (srv *webSubService) Publish(blogID uint64) (err error) {
+	hub := srv.GetHub(blogID)
+	if "" == hub {
+		return
+	}
+
+	for _, topic := range srv.GetTopics(blogID) {
+		response, data, errs := gorequest.New().TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+			Post(hub).Type("form").SendMap(map[string]interface{}{"hub.mode": "publish", "hub.url": topic}).
+			Set("user-agent", model.UserAgent).Timeout(30 * time.Second).End()
+		if nil != errs {
+			logger.Errorf("publish topic [%s] to hub [%s] failed: %s", topic, hub, errs[0])
+			err = errs[0]
+
+			continue
+		}
+		if http.StatusOK > response.StatusCode || http.StatusMultipleChoices <= response.StatusCode {
+			logger.Errorf("publish topic [%s] to hub [%s] failed: status code [%d], response [%s]",
+				topic, hub, response.StatusCode, data)
+			err = fmt.Errorf("publish topic [%s] to hub [%s] failed: status code [%d]", topic, hub, response.StatusCode)
+
+			continue
+		}
+
+		logger.Debugf("published topic [%s] to hub [%s]", topic, hub)
+	}
+
+	return
+}
+
+// EnqueuePublish pings the configured hub in background, failed pings are retried.
+func (srv *webSubService) EnqueuePublish(blogID uint64) {
+	if "" == srv.GetHub(blogID) {
+		return
+	}
+
+	Job.Enqueue("websub-publish", 2, func() error {
+		return srv.Publish(blogID)
+	})
+}
@@ -0,0 +1,315 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/b3log/pipe/model"
+)
+
+// WordPressImportItem represents the import result of a WordPress item.
+type WordPressImportItem struct {
+	Type   string `json:"type"` // author/category/tag/post/page/comment
+	Title  string `json:"title"`
+	Status string `json:"status"` // imported/skipped/failed
+	Msg    string `json:"msg"`
+}
+
+// WordPress import item statuses.
+const (
+	WordPressImportStatusImported = "imported"
+	WordPressImportStatusSkipped  = "skipped"
+	WordPressImportStatusFailed   = "failed"
+)
+
+// wxrRSS represents a WordPress eXtended RSS (WXR) export file.
+type wxrRSS struct {
+	Channel struct {
+		Authors    []wxrAuthor   `xml:"author"`
+		Categories []wxrCategory `xml:"category"`
+		Tags       []wxrTag      `xml:"tag"`
+		Items      []wxrItem     `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrAuthor struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Name     string `xml:"cat_name"`
+}
+
+type wxrTag struct {
+	Slug string `xml:"tag_slug"`
+	Name string `xml:"tag_name"`
+}
+
+type wxrItem struct {
+	Title       string            `xml:"title"`
+	Creator     string            `xml:"creator"`
+	Encoded     []wxrEncoded      `xml:"encoded"`
+	PostDate    string            `xml:"post_date"`
+	PubDate     string            `xml:"pubDate"`
+	PostName    string            `xml:"post_name"`
+	Status      string            `xml:"status"`
+	PostType    string            `xml:"post_type"`
+	IsSticky    string            `xml:"is_sticky"`
+	Commentable string            `xml:"comment_status"`
+	Categories  []wxrItemCategory `xml:"category"`
+	Comments    []wxrComment      `xml:"comment"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrItemCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID        string `xml:"comment_id"`
+	Author    string `xml:"comment_author"`
+	AuthorURL string `xml:"comment_author_url"`
+	Date      string `xml:"comment_date"`
+	Content   string `xml:"comment_content"`
+	Approved  string `xml:"comment_approved"`
+	Type      string `xml:"comment_type"`
+	Parent    string `xml:"comment_parent"`
+}
+
+func (item *wxrItem) content() string {
+	for _, encoded := range item.Encoded {
+		if strings.Contains(encoded.XMLName.Space, "purl.org/rss/1.0/modules/content") {
+			return encoded.Value
+		}
+	}
+
+	return ""
+}
+
+// ImportWordPress imports posts, pages, categories, tags and comments of the specified WXR data. WordPress authors are
+// all mapped to the specified author since Pipe users can only be created via login.
+func (srv *importService) ImportWordPress(wxrData []byte, authorID, blogID uint64) (ret []*WordPressImportItem) {
+	rss := &wxrRSS{}
+	if err := xml.Unmarshal(wxrData, rss); nil != err {
+		logger.Errorf("parse WXR failed: %s", err)
+		ret = append(ret, &WordPressImportItem{Type: "wxr", Status: WordPressImportStatusFailed, Msg: err.Error()})
+
+		return
+	}
+
+	channel := rss.Channel
+	for _, author := range channel.Authors {
+		name := author.DisplayName
+		if "" == name {
+			name = author.Login
+		}
+		ret = append(ret, &WordPressImportItem{Type: "author", Title: name, Status: WordPressImportStatusSkipped,
+			Msg: "mapped to the current user"})
+	}
+
+	// A WordPress category is mapped to a Pipe category which has a tag with the same name
+	var categoryNames []string
+	for _, wpCategory := range channel.Categories {
+		name := strings.TrimSpace(wpCategory.Name)
+		if "" != name && !contains(categoryNames, name) {
+			categoryNames = append(categoryNames, name)
+		}
+	}
+	for _, name := range categoryNames {
+		item := &WordPressImportItem{Type: "category", Title: name, Status: WordPressImportStatusImported}
+		ret = append(ret, item)
+
+		category := &model.Category{
+			Title:  name,
+			Path:   "/" + name,
+			Tags:   name,
+			BlogID: blogID,
+		}
+		if nil != Category.GetCategoryByPath(category.Path, blogID) {
+			item.Status = WordPressImportStatusSkipped
+			item.Msg = "category exists"
+
+			continue
+		}
+		if err := Category.AddCategory(category); nil != err {
+			item.Status = WordPressImportStatusFailed
+			item.Msg = err.Error()
+		}
+	}
+	for _, tag := range channel.Tags {
+		ret = append(ret, &WordPressImportItem{Type: "tag", Title: tag.Name, Status: WordPressImportStatusImported,
+			Msg: "created along with articles"})
+	}
+
+	for _, wpItem := range channel.Items {
+		if "post" != wpItem.PostType && "page" != wpItem.PostType {
+			continue
+		}
+
+		item := &WordPressImportItem{Type: wpItem.PostType, Title: wpItem.Title, Status: WordPressImportStatusImported}
+		ret = append(ret, item)
+		if "publish" != wpItem.Status {
+			item.Status = WordPressImportStatusSkipped
+			item.Msg = "status is [" + wpItem.Status + "]"
+
+			continue
+		}
+
+		article := wpItem2Article(&wpItem)
+		article.AuthorID = authorID
+		article.BlogID = blogID
+		if err := Article.AddArticle(article); nil != err {
+			item.Status = WordPressImportStatusFailed
+			item.Msg = err.Error()
+			logger.Errorf("import WordPress article [%s] failed: %s", article.Title, err)
+
+			continue
+		}
+
+		ret = append(ret, importWordPressComments(wpItem.Comments, article)...)
+	}
+
+	succeeded, failed := 0, 0
+	for _, item := range ret {
+		switch item.Status {
+		case WordPressImportStatusImported:
+			succeeded++
+		case WordPressImportStatusFailed:
+			failed++
+		}
+	}
+	Notification.NotifyImportFinished(succeeded, failed, authorID, blogID)
+
+	return
+}
+
+func importWordPressComments(wpComments []wxrComment, article *model.Article) (ret []*WordPressImportItem) {
+	commentIDs := map[string]uint64{} // WordPress comment ID -> Pipe comment ID
+	for _, wpComment := range wpComments {
+		item := &WordPressImportItem{Type: "comment", Title: article.Title + " - " + wpComment.Author,
+			Status: WordPressImportStatusImported}
+		ret = append(ret, item)
+		if "1" != wpComment.Approved || ("" != wpComment.Type && "comment" != wpComment.Type) {
+			item.Status = WordPressImportStatusSkipped
+			item.Msg = "unapproved comment, pingback or trackback"
+
+			continue
+		}
+
+		comment := &model.Comment{
+			ArticleID:       article.ID,
+			AuthorID:        model.SyncCommentAuthorID,
+			Content:         wpContent2Markdown(wpComment.Content),
+			AuthorName:      wpComment.Author,
+			AuthorURL:       wpComment.AuthorURL,
+			ParentCommentID: commentIDs[wpComment.Parent],
+			BlogID:          article.BlogID,
+		}
+		if createdAt, err := dateparse.ParseAny(wpComment.Date); nil == err {
+			comment.CreatedAt = createdAt
+		}
+		if err := Comment.AddComment(comment); nil != err {
+			item.Status = WordPressImportStatusFailed
+			item.Msg = err.Error()
+
+			continue
+		}
+		// Imported comments must not be pushed to community
+		comment.UpdatedAt = comment.CreatedAt
+		Comment.UpdatePushedAt(comment)
+		commentIDs[wpComment.ID] = comment.ID
+	}
+
+	return
+}
+
+func wpItem2Article(wpItem *wxrItem) *model.Article {
+	var tags []string
+	for _, category := range wpItem.Categories {
+		if "category" == category.Domain || "post_tag" == category.Domain {
+			tags = append(tags, strings.TrimSpace(category.Name))
+		}
+	}
+
+	ret := &model.Article{
+		Title:       strings.TrimSpace(wpItem.Title),
+		Content:     wpContent2Markdown(wpItem.content()),
+		Tags:        strings.Join(tags, ","),
+		Status:      model.ArticleStatusOK,
+		Topped:      "1" == wpItem.IsSticky,
+		Commentable: "closed" != wpItem.Commentable,
+	}
+	if "" != wpItem.PostName {
+		ret.Path = "/" + wpItem.PostName
+	}
+
+	createdAt, err := dateparse.ParseAny(wpItem.PostDate)
+	if nil != err {
+		createdAt, err = dateparse.ParseAny(wpItem.PubDate)
+	}
+	if nil != err {
+		createdAt = time.Now()
+	}
+	ret.CreatedAt = createdAt
+	ret.UpdatedAt = createdAt
+	ret.PushedAt = createdAt // imported articles must not be pushed to community
+
+	return ret
+}
+
+var (
+	wpCaptionShortcode = regexp.MustCompile(`(?s)\[caption[^\]]*\](.*?)\[/caption\]`)
+	wpCodeShortcode    = regexp.MustCompile(`(?s)\[(?:code|sourcecode)(?:\s+lang(?:uage)?="?([\w+-]*)"?)?[^\]]*\](.*?)\[/(?:code|sourcecode)\]`)
+	wpEmbedShortcode   = regexp.MustCompile(`(?s)\[embed[^\]]*\](.*?)\[/embed\]`)
+	wpAudioShortcode   = regexp.MustCompile(`\[audio[^\]]*\bsrc="([^"]+)"[^\]]*\](?:\[/audio\])?`)
+	wpVideoShortcode   = regexp.MustCompile(`\[video[^\]]*\b(?:src|mp4)="([^"]+)"[^\]]*\](?:\[/video\])?`)
+	wpMoreTag          = regexp.MustCompile(`<!--\s*more\s*-->`)
+	wpUnknownShortcode = regexp.MustCompile(`\[/?(?:gallery|contact-form|playlist)[^\]]*\]`)
+)
+
+// wpContent2Markdown maps WordPress shortcodes to markdown. The remaining HTML is kept as is since markdown allows
+// inline HTML.
+func wpContent2Markdown(content string) string {
+	ret := strings.Replace(content, "\r\n", "\n", -1)
+	ret = wpCaptionShortcode.ReplaceAllString(ret, "$1")
+	ret = wpCodeShortcode.ReplaceAllStringFunc(ret, func(shortcode string) string {
+		groups := wpCodeShortcode.FindStringSubmatch(shortcode)
+
+		return "\n```" + groups[1] + "\n" + strings.Trim(groups[2], "\n") + "\n```\n"
+	})
+	ret = wpEmbedShortcode.ReplaceAllString(ret, "\n$1\n")
+	ret = wpAudioShortcode.ReplaceAllString(ret, `<audio controls src="$1"></audio>`)
+	ret = wpVideoShortcode.ReplaceAllString(ret, `<video controls src="$1"></video>`)
+	ret = wpMoreTag.ReplaceAllString(ret, "")
+	ret = wpUnknownShortcode.ReplaceAllString(ret, "")
+
+	return strings.TrimSpace(ret)
+}
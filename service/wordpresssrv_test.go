@@ -0,0 +1,81 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+)
+
+func TestWpContent2Markdown(t *testing.T) {
+	content := wpContent2Markdown(`[caption id="attachment_1" align="alignnone"]<img src="a.png" /> A[/caption]`)
+	if `<img src="a.png" /> A` != content {
+		t.Errorf("expected is [%s], actual is [%s]", `<img src="a.png" /> A`, content)
+	}
+
+	content = wpContent2Markdown("[code lang=\"go\"]\nfmt.Println()\n[/code]")
+	if "```go\nfmt.Println()\n```" != content {
+		t.Errorf("expected is [%s], actual is [%s]", "```go\nfmt.Println()\n```", content)
+	}
+
+	content = wpContent2Markdown("Hello<!--more-->[gallery ids=\"1,2\"]")
+	if "Hello" != content {
+		t.Errorf("expected is [%s], actual is [%s]", "Hello", content)
+	}
+}
+
+func TestImportWordPress(t *testing.T) {
+	wxr := `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<wp:category><wp:category_nicename>wp</wp:category_nicename><wp:cat_name><![CDATA[WordPress]]></wp:cat_name></wp:category>
+	<item>
+		<title>WordPress 文章</title>
+		<content:encoded><![CDATA[Hello WordPress]]></content:encoded>
+		<wp:post_date>2019-01-02 03:04:05</wp:post_date>
+		<wp:post_name>wordpress-article</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="wp"><![CDATA[WordPress]]></category>
+		<wp:comment>
+			<wp:comment_id>1</wp:comment_id>
+			<wp:comment_author><![CDATA[wper]]></wp:comment_author>
+			<wp:comment_date>2019-01-03 03:04:05</wp:comment_date>
+			<wp:comment_content><![CDATA[Nice]]></wp:comment_content>
+			<wp:comment_approved>1</wp:comment_approved>
+			<wp:comment_parent>0</wp:comment_parent>
+		</wp:comment>
+	</item>
+</channel>
+</rss>`
+
+	items := Import.ImportWordPress([]byte(wxr), 1, 1)
+	for _, item := range items {
+		if WordPressImportStatusImported != item.Status {
+			t.Errorf("import [%s] failed: %s", item.Title, item.Msg)
+		}
+	}
+
+	article := Article.GetArticleByPath("/wordpress-article", 1)
+	if nil == article {
+		t.Errorf("article is nil")
+
+		return
+	}
+	if "WordPress" != article.Tags {
+		t.Errorf("expected is [%s], actual is [%s]", "WordPress", article.Tags)
+	}
+}
@@ -0,0 +1,60 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package theme
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRenderErrors is the max count of recent render errors to keep.
+const maxRenderErrors = 64
+
+// RenderError represents a theme template render error.
+type RenderError struct {
+	Template string    `json:"template"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+}
+
+var renderErrors []*RenderError
+var renderErrorsLock = &sync.Mutex{}
+
+// AddRenderError records a template render error.
+func AddRenderError(template string, err error) {
+	logger.Errorf("render template [%s] failed: %s", template, err)
+
+	renderErrorsLock.Lock()
+	defer renderErrorsLock.Unlock()
+
+	renderErrors = append(renderErrors, &RenderError{Template: template, Error: err.Error(), Time: time.Now()})
+	if maxRenderErrors < len(renderErrors) {
+		renderErrors = renderErrors[len(renderErrors)-maxRenderErrors:]
+	}
+}
+
+// GetRenderErrors returns recent template render errors, the latest first.
+func GetRenderErrors() (ret []*RenderError) {
+	renderErrorsLock.Lock()
+	defer renderErrorsLock.Unlock()
+
+	for i := len(renderErrors) - 1; 0 <= i; i-- {
+		ret = append(ret, renderErrors[i])
+	}
+
+	return
+}
@@ -0,0 +1,230 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package theme
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b3log/pipe/util"
+)
+
+// maxInstallSize is the max size of theme zips and of the files extracted from them.
+const maxInstallSize = 32 * 1024 * 1024
+
+// requiredTemplates holds the templates a theme must include, they are rendered by the controllers.
+var requiredTemplates = []string{"index.html", "article.html", "page.html", "tags.html", "tag-articles.html",
+	"categories.html", "category-articles.html", "archives.html", "archive-articles.html", "authors.html",
+	"author-articles.html"}
+
+// defineRegexp matches template definitions {{define "name"}}.
+var defineRegexp = regexp.MustCompile(`\{\{-?\s*define\s+"([^"]+)"`)
+
+// installClient downloads theme zips, it connects to public IPs only.
+var installClient = util.NewPublicHTTPClient(5 * time.Minute)
+
+// installLock serializes installations, which register themes and reload templates.
+var installLock = &sync.Mutex{}
+
+// ReloadTemplates reloads templates of all themes, it's set by the controller rendering them.
+var ReloadTemplates = func() error { return nil }
+
+// InstallURL installs the theme of the specified URL under the specified themes directory (theme/x) and returns the
+// theme name. Only public HTTPS URLs are accepted, URLs ending with .git are cloned with git, others are downloaded as
+// zips.
+func InstallURL(themesDir, url string) (string, error) {
+	if !util.IsPublicHTTPSURL(url) {
+		return "", errors.New("unsupported theme URL [" + url + "], only public HTTPS URLs are allowed")
+	}
+
+	if strings.HasSuffix(url, ".git") {
+		dir, err := ioutil.TempDir(themesDir, ".install-")
+		if nil != err {
+			return "", err
+		}
+		defer os.RemoveAll(dir)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		// only HTTPS is allowed even on redirects, and -- ends the options so that the URL can't be taken as one
+		cmd := exec.CommandContext(ctx, "git", "-c", "protocol.allow=never", "-c", "protocol.https.allow=always",
+			"clone", "--depth", "1", "--", url, dir)
+		if output, err := cmd.CombinedOutput(); nil != err {
+			return "", errors.New("clone [" + url + "] failed: " + strings.TrimSpace(string(output)))
+		}
+		os.RemoveAll(filepath.Join(dir, ".git"))
+
+		return install(themesDir, dir)
+	}
+
+	resp, err := installClient.Get(url)
+	if nil != err {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if http.StatusOK != resp.StatusCode {
+		return "", errors.New("download [" + url + "] failed: " + resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxInstallSize+1))
+	if nil != err {
+		return "", err
+	}
+	if maxInstallSize < len(data) {
+		return "", errors.New("theme zip is too large")
+	}
+
+	return InstallZip(themesDir, data)
+}
+
+// InstallZip installs the theme of the specified zip under the specified themes directory (theme/x) and returns the
+// theme name. The theme files could be at the root of the zip or in a single directory of it, as GitHub packs them.
+func InstallZip(themesDir string, data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if nil != err {
+		return "", errors.New("invalid theme zip: " + err.Error())
+	}
+
+	dir, err := ioutil.TempDir(themesDir, ".install-")
+	if nil != err {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	var size int64
+	for _, file := range reader.File {
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if rel, err := filepath.Rel(dir, path); nil != err || filepath.IsAbs(file.Name) || ".." == rel ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", errors.New("invalid file path [" + file.Name + "] in theme zip")
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); nil != err {
+				return "", err
+			}
+
+			continue
+		}
+		if !file.Mode().IsRegular() { // skips symbolic links
+			continue
+		}
+
+		size += int64(file.UncompressedSize64)
+		if maxInstallSize < size {
+			return "", errors.New("theme files are too large")
+		}
+		if err := extractZipFile(file, path); nil != err {
+			return "", err
+		}
+	}
+
+	return install(themesDir, dir)
+}
+
+func extractZipFile(file *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); nil != err {
+		return err
+	}
+	src, err := file.Open()
+	if nil != err {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if nil != err {
+		return err
+	}
+	defer dest.Close()
+	_, err = io.Copy(dest, io.LimitReader(src, maxInstallSize))
+
+	return err
+}
+
+// install validates the theme in the specified directory, moves it under the specified themes directory and registers
+// it. The theme is named by theme.json and must define templates prefixed with its name only.
+func install(themesDir, dir string) (string, error) {
+	installLock.Lock()
+	defer installLock.Unlock()
+
+	if _, err := os.Stat(filepath.Join(dir, "theme.json")); os.IsNotExist(err) {
+		entries, _ := ioutil.ReadDir(dir)
+		if 1 == len(entries) && entries[0].IsDir() {
+			dir = filepath.Join(dir, entries[0].Name())
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "theme.json"))
+	if nil != err {
+		return "", errors.New("not found theme.json")
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); nil != err {
+		return "", errors.New("parse theme.json failed: " + err.Error())
+	}
+	name := manifest.Name
+	if !themeNameRegexp.MatchString(name) {
+		return "", errors.New("invalid theme name [" + name + "], only letters, numbers, - and _ are allowed")
+	}
+	for _, template := range requiredTemplates {
+		if _, err := os.Stat(filepath.Join(dir, template)); nil != err {
+			return "", errors.New("not found template [" + template + "]")
+		}
+	}
+	templates, _ := filepath.Glob(filepath.Join(dir, "*.html"))
+	for _, template := range templates {
+		data, err := ioutil.ReadFile(template)
+		if nil != err {
+			return "", err
+		}
+		for _, groups := range defineRegexp.FindAllStringSubmatch(string(data), -1) {
+			if !strings.HasPrefix(groups[1], name+"/") {
+				return "", errors.New("template [" + groups[1] + "] of [" + filepath.Base(template) + "] should be prefixed with [" + name + "/]")
+			}
+		}
+	}
+
+	target := filepath.Join(themesDir, name)
+	if _, err := os.Stat(target); nil == err {
+		return "", errors.New("theme [" + name + "] exists")
+	}
+	if err := os.Rename(dir, target); nil != err {
+		return "", err
+	}
+
+	register(name)
+	if err := ReloadTemplates(); nil != err {
+		unregister(name)
+		os.RemoveAll(target)
+		ReloadTemplates()
+
+		return "", errors.New("load templates failed: " + err.Error())
+	}
+
+	return name, nil
+}
@@ -120,6 +120,7 @@ export const ShowEditor = (reply, id, commentId) => {
     'link',
     'table',
     '|',
+    'upload',
     'both',
     'preview',
     'format',
@@ -139,6 +140,7 @@ export const ShowEditor = (reply, id, commentId) => {
       'ordered-list',
       'check',
       'link',
+      'upload',
       'preview',
       'format',
       'info',
@@ -147,6 +149,18 @@ export const ShowEditor = (reply, id, commentId) => {
     resizeEnable = false
   }
 
+  let upload
+  if ($('#pipeEditorComment').data('upload') === true) {
+    upload = {
+      url: `${$('#pipeEditorComment').data('blogurl')}/api/comments/images`,
+      max: 2 * 1024 * 1024,
+      accept: 'image/png,image/jpeg,image/gif',
+      multiple: false,
+    }
+  } else {
+    toolbar = toolbar.filter((item) => item !== 'upload')
+  }
+
   window.vditor = new Vditor('pipeEditorComment', {
     tab: '\t',
     placeholder: $('#pipeEditorComment').data('placeholder'),
@@ -177,6 +191,7 @@ export const ShowEditor = (reply, id, commentId) => {
     },
     lang: $('#pipeLang').data('lang'),
     toolbar,
+    upload,
   })
 }
 
@@ -305,6 +320,29 @@ ${$it.data('label2')}`).click(function () {
       }
     })
 
+  // report article or comment
+  $('#pipeCommentsWrap').
+    on('click', '.pipe-comment__btn--report', function () {
+      const $it = $(this)
+      const detail = prompt($it.data('label'))
+      if (detail === null) {
+        return
+      }
+      $.ajax({
+        url: `${$('#pipeCommentsWrap').data('blogurl')}/reports`,
+        data: JSON.stringify({
+          targetType: $it.data('type'),
+          targetID: $it.data('id'),
+          reason: 'other',
+          detail: detail,
+        }),
+        type: 'POST',
+        success: (result) => {
+          alert(result.msg)
+        },
+      })
+    })
+
   // comment reply
   $('#pipeCommentsWrap').
     on('click', '#pipeComments .pipe-comment__btn--reply', function () {
@@ -349,50 +387,111 @@ ${$it.data('label2')}`).click(function () {
 
     $editorAdd.addClass('pipe-btn--disabled')
 
-    $.ajax({
-      url: `${$commentContent.data('blogurl')}/comments`,
-      data: JSON.stringify(requestData),
-      type: 'POST',
-      success: (result) => {
-        if (result.code === 0) {
-          _hideEditor()
-          const $commentsCnt = $('#pipeCommentsCnt')
-          const $comments = $('#pipeComments')
-
-          if ($commentsCnt.length === 0) {
-            $comments.removeClass('pipe-comment__null').
-              unbind('click').
-              html(
-                `<div class="pipe-comment__header"><span id="pipeCommentsCnt">1</span>${label}</div><div>${result.data}</div>
+    _getCaptcha($commentContent.data('captcha'),
+      $commentContent.data('blogurl')).then((captcha) => {
+      requestData.captcha = captcha
+      $.ajax({
+        url: `${$commentContent.data('blogurl')}/comments`,
+        data: JSON.stringify(requestData),
+        type: 'POST',
+        success: (result) => {
+          if (result.code === 0 && !result.data) {
+            // held for moderation
+            _hideEditor()
+            vditor.setValue('')
+            alert(result.msg)
+          } else if (result.code === 0) {
+            _hideEditor()
+            const $commentsCnt = $('#pipeCommentsCnt')
+            const $comments = $('#pipeComments')
+
+            if ($commentsCnt.length === 0) {
+              $comments.removeClass('pipe-comment__null').
+                unbind('click').
+                html(
+                  `<div class="pipe-comment__header"><span id="pipeCommentsCnt">1</span>${label}</div><div>${result.data}</div>
 <nav class="pipe-comment__pagination fn__clear">
     <span class="fn__right pipe-comment__btn" data-text="${$comments.data(
-                  'title')}" data-id="${$editor.data('id')}" id="pipeCommentBottomComment">
+                    'title')}" data-id="${$editor.data('id')}" id="pipeCommentBottomComment">
          <svg><use xlink:href="#icon-reply"></use></svg> ${label}
     </span>
 </nav>`)
-          } else {
-            $commentsCnt.text(parseInt($commentsCnt.text()) + 1)
-            $('#pipeComments > div > section').last().after(result.data)
-          }
+            } else {
+              $commentsCnt.text(parseInt($commentsCnt.text()) + 1)
+              $('#pipeComments > div > section').last().after(result.data)
+            }
+
+            if ($(this).data('editable')) {
+              $('#pipeComments > div > section').
+                last().
+                find('.pipe-comment__btn--danger').
+                removeClass('pipe-comment__btn--none')
+            }
 
-          if ($(this).data('editable')) {
-            $('#pipeComments > div > section').
-              last().
-              find('.pipe-comment__btn--danger').
-              removeClass('pipe-comment__btn--none')
+            LazyLoadCSSImage()
+            LazyLoadImage()
+            ParseMarkdown()
+            ParseHljs()
+
+            vditor.setValue('')
+          } else {
+            alert(result.msg)
           }
+          $editorAdd.removeClass('pipe-btn--disabled')
+        },
+      })
+    }, () => {
+      $editorAdd.removeClass('pipe-btn--disabled')
+    })
+  })
+}
 
-          LazyLoadCSSImage()
-          LazyLoadImage()
-          ParseMarkdown()
-          ParseHljs()
+/**
+ * @description 获取评论验证码，工作量证明在浏览器中计算
+ * @param {string} captcha 验证方式
+ * @param {string} blogURL 博客地址
+ * @returns {Promise<string>} 验证码
+ */
+const _getCaptcha = (captcha, blogURL) => {
+  switch (captcha) {
+    case 'hcaptcha':
+      return Promise.resolve(window.hcaptcha ? window.hcaptcha.getResponse() : '')
+    case 'recaptcha':
+      return Promise.resolve(
+        window.grecaptcha ? window.grecaptcha.getResponse() : '')
+    case 'pow':
+      return Promise.resolve($.ajax({
+        url: `${blogURL}/api/comments/challenge`,
+        type: 'GET',
+      })).then((result) => _solveChallenge(result.data.challenge,
+        result.data.difficulty, 0))
+    default:
+      return Promise.resolve('')
+  }
+}
 
-          vditor.setValue('')
-        } else {
-          alert(result.msg)
+/**
+ * @description 计算工作量证明：寻找 answer 使 sha256(challenge:answer) 前 difficulty 位为 0
+ * @param {string} challenge 挑战
+ * @param {number} difficulty 难度
+ * @param {number} answer 本次尝试的解答
+ * @returns {Promise<string>} 解答
+ */
+const _solveChallenge = (challenge, difficulty, answer) => {
+  const solution = `${challenge}:${answer}`
+  return crypto.subtle.digest('SHA-256', new TextEncoder().encode(solution)).
+    then((buffer) => {
+      const hash = new Uint8Array(buffer)
+      let zeros = 0
+      for (let i = 0; i < hash.length; i++) {
+        zeros += hash[i] === 0 ? 8 : Math.clz32(hash[i]) - 24
+        if (hash[i] !== 0) {
+          break
         }
-        $editorAdd.removeClass('pipe-btn--disabled')
-      },
+      }
+      if (zeros >= difficulty) {
+        return solution
+      }
+      return _solveChallenge(challenge, difficulty, answer + 1)
     })
-  })
 }
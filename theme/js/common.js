@@ -320,6 +320,21 @@ export const initPjax = (cb) => {
       ParseHljs()
     },
   })
+  $('body').on('submit', '.pipe-subscribe', function (event) {
+    event.preventDefault()
+    const $form = $(this)
+    $.ajax({
+      url: `${$form.data('blogurl')}/subscribe`,
+      data: JSON.stringify({email: $form.find('.pipe-subscribe__email').val()}),
+      type: 'POST',
+      success: (result) => {
+        if (result.code === 0) {
+          $form.find('.pipe-subscribe__email').val('')
+        }
+        alert(result.msg)
+      },
+    })
+  })
   TrimB3Id()
   LazyLoadCSSImage()
   LazyLoadImage()
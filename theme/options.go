@@ -0,0 +1,121 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Option types.
+const (
+	OptionTypeText   = "text"
+	OptionTypeColor  = "color"  // #rrggbb
+	OptionTypeBool   = "bool"   // true/false
+	OptionTypeNumber = "number" // int or float
+	OptionTypeSelect = "select" // one of the choices
+)
+
+// optionNameRegexp validates option names, an option name is used as a key of .ThemeOptions in templates.
+var optionNameRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,31}$`)
+
+var colorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Option represents a configurable option of a theme declared in the "options" of theme.json.
+type Option struct {
+	Name    string      `json:"name"`
+	Label   string      `json:"label"`
+	Type    string      `json:"type"`
+	Default interface{} `json:"default"`
+	Choices []string    `json:"choices,omitempty"` // choices of type "select"
+}
+
+// GetOptions returns the options of the specified theme.
+func GetOptions(name string) []*Option {
+	manifest := Manifests[name]
+	if nil == manifest {
+		return nil
+	}
+
+	return manifest.Options
+}
+
+// DefaultValue returns the default value of the option in the stored string form.
+func (option *Option) DefaultValue() string {
+	if nil == option.Default {
+		return ""
+	}
+	if f, ok := option.Default.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(option.Default)
+}
+
+// Parse parses the specified stored value of the option, returns bool for type "bool", float64 for type "number" and
+// string for others.
+func (option *Option) Parse(value string) (interface{}, error) {
+	switch option.Type {
+	case OptionTypeBool:
+		return strconv.ParseBool(value)
+	case OptionTypeNumber:
+		return strconv.ParseFloat(value, 64)
+	case OptionTypeColor:
+		if !colorRegexp.MatchString(value) {
+			return nil, errors.New("invalid color [" + value + "]")
+		}
+	case OptionTypeSelect:
+		for _, choice := range option.Choices {
+			if value == choice {
+				return value, nil
+			}
+		}
+
+		return nil, errors.New("invalid choice [" + value + "]")
+	}
+
+	return value, nil
+}
+
+// validateOptions removes the invalid options declared by the specified theme.
+func validateOptions(name string, options []*Option) (ret []*Option) {
+	names := map[string]bool{}
+	for _, option := range options {
+		valid := optionNameRegexp.MatchString(option.Name) && !names[option.Name]
+		switch option.Type {
+		case OptionTypeText, OptionTypeColor, OptionTypeBool, OptionTypeNumber, OptionTypeSelect:
+		default:
+			valid = false
+		}
+		if valid {
+			_, err := option.Parse(option.DefaultValue())
+			valid = nil == err
+		}
+		if !valid {
+			logger.Warnf("option [%s] of theme [%s] is invalid", option.Name, name)
+
+			continue
+		}
+
+		names[option.Name] = true
+		ret = append(ret, option)
+	}
+
+	return
+}
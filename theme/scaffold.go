@@ -0,0 +1,268 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package theme
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/model"
+)
+
+// themeNameRegexp validates theme names, a theme name is used in template names and URLs.
+var themeNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// scaffoldName is replaced with the theme name in scaffold files.
+const scaffoldName = "THEME_NAME"
+
+// Scaffold generates a skeleton theme with the specified name under the specified themes directory (theme/x), returns
+// the generated theme directory.
+func Scaffold(themesDir, name string) (string, error) {
+	if !themeNameRegexp.MatchString(name) {
+		return "", errors.New("invalid theme name [" + name + "], only letters, numbers, - and _ are allowed")
+	}
+	dir := filepath.Join(themesDir, name)
+	if _, err := os.Stat(dir); nil == err {
+		return "", errors.New("theme [" + name + "] exists")
+	}
+
+	for _, sub := range []string{"css", "js", "images"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); nil != err {
+			return "", err
+		}
+	}
+	for path, content := range scaffoldFiles() {
+		content = strings.Replace(content, scaffoldName, name, -1)
+		if err := ioutil.WriteFile(filepath.Join(dir, path), []byte(strings.TrimLeft(content, "\n")), 0644); nil != err {
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+// scaffoldPage wraps the specified page body with the layout shared by all pages.
+func scaffoldPage(page, head, body string) string {
+	return `
+{{define "THEME_NAME/` + page + `"}}
+<!DOCTYPE html>
+<html>
+<head>
+    {{template "head/head" .}}
+    {{template "head/3rdstatistic" .}}` + head + `
+</head>
+<body>
+{{template "THEME_NAME/header" .}}
+<div id="pjax">
+    {{if .pjax}}{{noescape "<!---- pjax {#pjax} start ---->"}}{{end}}
+    <main class="main">
+` + strings.Trim(body, "\n") + `
+    </main>
+    {{if .pjax}}{{noescape "<!---- pjax {#pjax} end ---->"}}{{end}}
+</div>
+{{template "THEME_NAME/footer" .}}
+</body>
+</html>
+{{end}}
+`
+}
+
+func scaffoldListPage(page, title string) string {
+	return scaffoldPage(page, "", `
+        <h1 class="page__title">`+title+`</h1>
+        {{template "THEME_NAME/article-list" .}}
+`)
+}
+
+func scaffoldTermsPage(page, title, terms, name string) string {
+	return scaffoldPage(page, "", `
+        <h1 class="page__title">`+title+`</h1>
+        <ul class="terms">
+            {{range .`+terms+`}}
+            <li><a href="{{.URL}}">{{.`+name+`}}</a> ({{.ArticleCount}})</li>
+            {{end}}
+        </ul>
+`)
+}
+
+func scaffoldFiles() map[string]string {
+	return map[string]string{
+		"theme.json": `
+{
+  "name": "THEME_NAME",
+  "version": "0.1.0",
+  "author": "",
+  "homepage": "",
+  "description": "A Pipe theme generated by pipe theme new",
+  "pipeVersion": ">=` + model.Version + `",
+  "templateAPI": ` + strconv.Itoa(TemplateAPIVersion) + `,
+  "options": [
+    {
+      "name": "accentColor",
+      "label": "Accent color",
+      "type": "color",
+      "default": "#4285f4"
+    },
+    {
+      "name": "showAuthors",
+      "label": "Show authors in article lists",
+      "type": "bool",
+      "default": true
+    }
+  ]
+}
+`,
+		"README.md": `
+# THEME_NAME
+
+A skeleton Pipe theme.
+
+* Templates are Go HTML templates, every template name is prefixed with THEME_NAME/
+* Run Pipe with -runtime_mode dev to reload templates, shortcodes and assets on changes while developing
+* Put shortcode templates in shortcodes/, for example shortcodes/note.html renders {{< note >}}
+* Themes are served once installed or created, choose it in console then
+* Put styles in css/, scripts in js/, images in images/ and a 600x600 preview in thumbnail.jpg
+* theme.json describes the theme, its options are customized by users in console and rendered as .ThemeOptions
+* templateAPI in theme.json is the template API version the theme targets, blogs fall back to the default theme
+  when it mismatches the one of Pipe
+* Users override a template for their blog by putting a file of the same name in theme/custom/BLOG_ID/THEME_NAME/,
+  overrides keep working after the theme is upgraded
+`,
+		"css/common.css": `
+:root {
+    --accent-color: #4285f4;
+}
+
+body {
+    margin: 0;
+    font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif;
+    color: #24292e;
+}
+
+a {
+    color: var(--accent-color);
+    text-decoration: none;
+}
+
+.header, .main, .footer {
+    max-width: 768px;
+    margin: 0 auto;
+    padding: 16px;
+}
+
+.pagination__item--active {
+    font-weight: bold;
+}
+`,
+		"js/common.js": `
+(function () {
+  var script = document.getElementById('script')
+  if (!script) {
+    return
+  }
+  console.log('THEME_NAME is running on ' + script.getAttribute('data-blogurl'))
+})()
+`,
+		"images/.gitkeep": "",
+		"define-header.html": `
+{{define "THEME_NAME/header"}}
+{{template "head/announcement" .}}
+<style>:root { --accent-color: {{.ThemeOptions.accentColor}}; }</style>
+<header class="header">
+    <h1><a href="{{.BlogURL}}">{{.Setting.BasicBlogTitle}}</a></h1>
+    <p>{{.Setting.BasicBlogSubtitle}}</p>
+    <nav>
+        <a href="{{.BlogURL}}">{{.I18n.Index}}</a>
+        <a href="{{.BlogURL}}/categories">{{.I18n.Categories}}</a>
+        <a href="{{.BlogURL}}/tags">{{.I18n.Tags}}</a>
+        <a href="{{.BlogURL}}/archives">{{.I18n.Archives}}</a>
+        <a href="{{.BlogURL}}/authors">{{.I18n.TeamMember2}}</a>
+    </nav>
+</header>
+{{end}}
+`,
+		"define-footer.html": `
+{{define "THEME_NAME/footer"}}
+<footer class="footer">
+    <a href="{{.BlogURL}}">{{.Setting.BasicBlogTitle}}</a> &copy; {{.Year}} {{.Setting.BasicFooter}}
+    • Powered by <a href="https://b3log.org/pipe" target="_blank">Pipe</a>
+</footer>
+<link rel="stylesheet" href="{{.Conf.StaticServer}}/theme/x/{{.Setting.ThemeName}}/css/common.css?{{.Conf.StaticResourceVersion}}">
+<script type="text/javascript"
+        id="script"
+        data-blogurl="{{.BlogURL}}"
+        src="{{.Conf.StaticServer}}/theme/x/{{.Setting.ThemeName}}/js/common.js?{{.Conf.StaticResourceVersion}}"></script>
+{{template "head/footer" .}}
+{{end}}
+`,
+		"define-article-list.html": `
+{{define "THEME_NAME/article-list"}}
+{{if eq (len .Articles) 0}}{{.I18n.NoData}}{{end}}
+{{range .Articles}}
+<article>
+    <h2><a rel="bookmark" href="{{.URL}}">{{.Title}}</a></h2>
+    <div>
+        <time>{{.CreatedAt}}</time>{{if $.ThemeOptions.showAuthors}} · {{.Author.Name}}{{end}} · {{.ViewCount}} {{$.I18n.View}} · {{.CommentCount}} {{$.I18n.Comment}}
+    </div>
+    {{if .Abstract}}<section class="vditor-reset">{{.Abstract}}</section>{{end}}
+    <div>{{range .Tags}}<a class="tag" rel="tag" href="{{.URL}}">{{.Title}}</a> {{end}}</div>
+</article>
+{{end}}
+{{if gt (len .Pagination.PageNums) 1}}
+<nav class="pagination">
+    {{range .Pagination.PageNums}}
+    <a href="?p={{.}}" class="pagination__item {{if eq . $.Pagination.CurrentPageNum}}pagination__item--active{{end}}">{{.}}</a>
+    {{end}}
+</nav>
+{{end}}
+{{end}}
+`,
+		"index.html":             scaffoldPage("index.html", "", `        {{template "THEME_NAME/article-list" .}}`),
+		"tag-articles.html":      scaffoldListPage("tag-articles.html", "{{.Tag.Title}}"),
+		"category-articles.html": scaffoldListPage("category-articles.html", "{{.Category.Title}}"),
+		"archive-articles.html":  scaffoldListPage("archive-articles.html", "{{.Archive.Title}}"),
+		"author-articles.html":   scaffoldListPage("author-articles.html", "{{.Author.Name}}"),
+		"tags.html":              scaffoldTermsPage("tags.html", "{{.I18n.Tags}}", "Tags", "Title"),
+		"categories.html":        scaffoldTermsPage("categories.html", "{{.I18n.Categories}}", "Categories", "Title"),
+		"archives.html":          scaffoldTermsPage("archives.html", "{{.I18n.Archives}}", "Archives", "Title"),
+		"authors.html":           scaffoldTermsPage("authors.html", "{{.I18n.TeamMember2}}", "Authors", "Name"),
+		"article.html": scaffoldPage("article.html", `
+    {{template "head/article" .}}`, `
+        <article>
+            <h1>{{.Article.Title}}</h1>
+            <div>
+                <time>{{.Article.CreatedAt}}</time> · {{.Article.Author.Name}} · {{.Article.ViewCount}} {{.I18n.View}}
+            </div>
+            <section class="vditor-reset" id="articleContent">{{.Article.Content}}</section>
+            <div>{{range .Article.Tags}}<a class="tag" rel="tag" href="{{.URL}}">{{.Title}}</a> {{end}}</div>
+        </article>
+        {{template "comment/comments" .}}
+        {{template "comment/editor" .}}
+`),
+		"page.html": scaffoldPage("page.html", "", `
+        <article>
+            <h1>{{.Page.Title}}</h1>
+            <section class="vditor-reset" id="articleContent">{{.Page.Content}}</section>
+        </article>
+`),
+	}
+}
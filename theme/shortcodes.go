@@ -0,0 +1,61 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package theme
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/b3log/pipe/util"
+)
+
+// LoadShortcodes reloads shortcodes of all themes.
+func LoadShortcodes() {
+	for _, name := range Themes {
+		loadShortcodes(name)
+	}
+}
+
+// loadShortcodes registers shortcodes of the specified theme, each template theme/x/{theme}/shortcodes/{name}.html
+// is a shortcode named by its file name. Templates are rendered with the params of the shortcode as .Params and the
+// rendered inner content of paired shortcodes as .Inner.
+func loadShortcodes(name string) {
+	util.UnregisterThemeShortcodes(name)
+	files, _ := filepath.Glob(filepath.Join("theme", "x", name, "shortcodes", "*.html"))
+	for _, file := range files {
+		tpl, err := template.ParseFiles(file)
+		if nil != err {
+			logger.Errorf("parse shortcode [%s] of theme [%s] failed: %s", file, name, err)
+
+			continue
+		}
+
+		shortcodeName := strings.TrimSuffix(filepath.Base(file), ".html")
+		util.RegisterThemeShortcode(name, shortcodeName, func(params map[string]string, inner string) (string, error) {
+			buf := &bytes.Buffer{}
+			if err := tpl.Execute(buf, map[string]interface{}{"Params": params, "Inner": template.HTML(inner)}); nil != err {
+				AddRenderError("shortcodes/"+shortcodeName, err)
+
+				return "", err
+			}
+
+			return buf.String(), nil
+		})
+	}
+}
@@ -18,20 +18,45 @@
 package theme
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
+	"github.com/b3log/pipe/util"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 // DefaultTheme represents the default theme name.
 const DefaultTheme = "Littlewin"
 
+// TemplateAPIVersion is the version of the template API (data model, shared templates and functions) themes are
+// rendered with, it's bumped on changes which may break existing themes.
+const TemplateAPIVersion = 1
+
 // Themes saves all theme names.
 var Themes []string
 
+// Manifest represents theme.json of a theme.
+type Manifest struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Author      string    `json:"author"`
+	Homepage    string    `json:"homepage"`
+	Description string    `json:"description"`
+	PipeVersion string    `json:"pipeVersion"`
+	TemplateAPI int       `json:"templateAPI"` // the TemplateAPIVersion the theme targets
+	Options     []*Option `json:"options"`     // options users could customize
+}
+
+// Manifests saves manifests of all themes, keyed by theme name.
+var Manifests = map[string]*Manifest{}
+
 // Load loads themes.
 func Load() {
 	f, _ := os.Open("theme/x")
@@ -43,8 +68,77 @@ func Load() {
 			continue
 		}
 
-		Themes = append(Themes, name)
+		register(name)
 	}
 
 	logger.Debugf("loaded [%d] themes", len(Themes))
 }
+
+// register registers the theme of the specified name, which is under theme/x.
+func register(name string) {
+	Themes = append(Themes, name)
+	Manifests[name] = loadManifest(name)
+	loadShortcodes(name)
+	if !IsCompatible(name) {
+		logger.Warnf("theme [%s] targets template API [%d] but the current is [%d], blogs using it will fall back to theme [%s]",
+			name, Manifests[name].TemplateAPI, TemplateAPIVersion, DefaultTheme)
+	}
+}
+
+// unregister unregisters the theme of the specified name.
+func unregister(name string) {
+	for i, themeName := range Themes {
+		if name == themeName {
+			Themes = append(Themes[:i], Themes[i+1:]...)
+
+			break
+		}
+	}
+	delete(Manifests, name)
+	util.UnregisterThemeShortcodes(name)
+}
+
+// IsCompatible checks whether the specified theme exists and targets the current template API.
+func IsCompatible(name string) bool {
+	manifest := Manifests[name]
+
+	return nil != manifest && TemplateAPIVersion == manifest.TemplateAPI
+}
+
+// Resolve returns the specified theme if it's compatible, returns the default theme otherwise to avoid rendering
+// broken pages.
+func Resolve(name string) string {
+	if IsCompatible(name) {
+		return name
+	}
+
+	return DefaultTheme
+}
+
+// CustomDir returns the directory of theme template overrides of the specified blog, templates under its {theme}/
+// override the templates of the same name of the theme for the blog only.
+func CustomDir(blogID uint64) string {
+	return filepath.Join("theme", "custom", strconv.FormatUint(blogID, 10))
+}
+
+// loadManifest loads theme.json of the specified theme. Themes without theme.json or templateAPI in it predate the
+// template API versioning and are considered targeting version 1.
+func loadManifest(name string) *Manifest {
+	ret := &Manifest{Name: name, TemplateAPI: 1}
+	data, err := ioutil.ReadFile(filepath.Join("theme", "x", name, "theme.json"))
+	if nil != err {
+		if !os.IsNotExist(err) {
+			logger.Errorf("read manifest of theme [%s] failed: %s", name, err)
+		}
+
+		return ret
+	}
+
+	if err = json.Unmarshal(data, ret); nil != err {
+		logger.Errorf("parse manifest of theme [%s] failed: %s", name, err)
+		ret.TemplateAPI = 0
+	}
+	ret.Options = validateOptions(name, ret.Options)
+
+	return ret
+}
@@ -0,0 +1,109 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// definitionListPlaceholderRegexp matches placeholders of definition lists in rendered HTML.
+var definitionListPlaceholderRegexp = regexp.MustCompile(`(<p>)?pipedl(\d+)x(</p>)?`)
+
+// definitionItem represents a term and its definitions of a definition list.
+type definitionItem struct {
+	term        string
+	definitions []string
+}
+
+// protectDefinitionLists replaces definition lists in the specified markdown text with placeholders. A definition
+// list is made of terms, each of them is on a line of its own and followed by definitions starting with ": ".
+func protectDefinitionLists(mdText string) (string, [][]*definitionItem) {
+	lines := strings.Split(mdText, "\n")
+	inCode := fencedCodeLines(lines)
+	isTerm := func(i int) bool {
+		return i+1 < len(lines) && !inCode[i] && "" != strings.TrimSpace(lines[i]) && !isDefinition(lines[i]) &&
+			isDefinition(lines[i+1]) && (0 == i || "" == strings.TrimSpace(lines[i-1]))
+	}
+
+	var lists [][]*definitionItem
+	var ret []string
+	for i := 0; i < len(lines); i++ {
+		if !isTerm(i) {
+			ret = append(ret, lines[i])
+
+			continue
+		}
+
+		var list []*definitionItem
+		for {
+			item := &definitionItem{term: strings.TrimSpace(lines[i])}
+			for i+1 < len(lines) && isDefinition(lines[i+1]) {
+				i++
+				item.definitions = append(item.definitions, strings.TrimSpace(strings.TrimSpace(lines[i])[1:]))
+			}
+			list = append(list, item)
+
+			next := i + 1
+			for next < len(lines) && "" == strings.TrimSpace(lines[next]) {
+				next++
+			}
+			if !isTerm(next) {
+				break
+			}
+			i = next
+		}
+		lists = append(lists, list)
+		ret = append(ret, "", "pipedl"+strconv.Itoa(len(lists)-1)+"x", "")
+	}
+
+	return strings.Join(ret, "\n"), lists
+}
+
+// isDefinition checks whether the specified line is a definition of a definition list.
+func isDefinition(line string) bool {
+	line = strings.TrimSpace(line)
+
+	return strings.HasPrefix(line, ": ") && "" != strings.TrimSpace(line[1:])
+}
+
+// restoreDefinitionLists replaces placeholders in the specified rendered HTML with definition lists.
+func restoreDefinitionLists(contentHTML string, lists [][]*definitionItem) string {
+	if 1 > len(lists) {
+		return contentHTML
+	}
+
+	return definitionListPlaceholderRegexp.ReplaceAllStringFunc(contentHTML, func(placeholder string) string {
+		index, _ := strconv.Atoi(definitionListPlaceholderRegexp.FindStringSubmatch(placeholder)[2])
+		if index >= len(lists) {
+			return placeholder
+		}
+
+		buf := &strings.Builder{}
+		buf.WriteString("<dl>")
+		for _, item := range lists[index] {
+			buf.WriteString("<dt>" + renderMarkdownInline(item.term) + "</dt>")
+			for _, definition := range item.definitions {
+				buf.WriteString("<dd>" + renderMarkdownInline(definition) + "</dd>")
+			}
+		}
+		buf.WriteString("</dl>")
+
+		return buf.String()
+	})
+}
@@ -0,0 +1,57 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"html"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// plantUMLEncoding is the base64 variant used by PlantUML servers to encode diagram sources in URLs.
+var plantUMLEncoding = base64.NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_").WithPadding(base64.NoPadding)
+
+// PlantUMLURL returns the SVG URL of the specified PlantUML diagram source rendered by the specified PlantUML server.
+func PlantUMLURL(server, source string) string {
+	buf := &bytes.Buffer{}
+	writer, _ := flate.NewWriter(buf, flate.BestCompression)
+	writer.Write([]byte(source))
+	writer.Close()
+
+	return strings.TrimSuffix(server, "/") + "/svg/" + plantUMLEncoding.EncodeToString(buf.Bytes())
+}
+
+// renderDiagrams replaces plantuml code blocks of the specified document with SVG images rendered by the specified
+// PlantUML server. Mermaid code blocks are kept and rendered by the client as there is no renderer in Go.
+func renderDiagrams(doc *goquery.Document, plantUMLServer string) {
+	if "" == plantUMLServer {
+		return
+	}
+
+	doc.Find("pre > code.language-plantuml").Each(func(i int, code *goquery.Selection) {
+		source := strings.TrimSpace(code.Text())
+		if "" == source {
+			return
+		}
+
+		code.Parent().ReplaceWithHtml(`<div class="pipe-diagram"><img src="` + html.EscapeString(PlantUMLURL(plantUMLServer, source)) + `" alt="PlantUML"></div>`)
+	})
+}
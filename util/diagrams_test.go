@@ -0,0 +1,54 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestPlantUMLURL(t *testing.T) {
+	source := "Bob -> Alice : hello"
+	url := PlantUMLURL("https://www.plantuml.com/plantuml/", source)
+	prefix := "https://www.plantuml.com/plantuml/svg/"
+	if !strings.HasPrefix(url, prefix) {
+		t.Fatalf("unexpected URL [%s]", url)
+	}
+
+	data, err := plantUMLEncoding.DecodeString(strings.TrimPrefix(url, prefix))
+	if nil != err {
+		t.Fatalf("decode failed: %s", err)
+	}
+	decoded, _ := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if source != string(decoded) {
+		t.Errorf("expected [%s], got [%s]", source, decoded)
+	}
+}
+
+func TestMarkdownDiagrams(t *testing.T) {
+	mdText := "```plantuml\nBob -> Alice : hello\n```\n\n```mermaid\ngraph TD; A-->B;\n```\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{PlantUMLServer: "https://www.plantuml.com/plantuml"}).ContentHTML
+	if !strings.Contains(html, `class="pipe-diagram"`) || !strings.Contains(html, `data-src="https://www.plantuml.com/plantuml/svg/`) {
+		t.Errorf("plantuml block should be rendered to an SVG image [%s]", html)
+	}
+	if !strings.Contains(html, `class="language-mermaid"`) {
+		t.Errorf("mermaid block should be kept for the client [%s]", html)
+	}
+}
@@ -0,0 +1,201 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Diff operation types.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffEditDistance is the max edit distance (in words) computed by DiffWords, texts differ more are diffed as a
+// whole deletion and a whole insertion.
+const maxDiffEditDistance = 2048
+
+// DiffOp represents an operation of transforming a text to another.
+type DiffOp struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// DiffWords returns the word-level diff transforming the specified text from to the specified text to. Words are runs
+// of letters and digits, each CJK character, punctuation and whitespace run is treated as a word as well.
+func DiffWords(from, to string) (ret []*DiffOp) {
+	a, b := splitWords(from), splitWords(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ret = appendDiffOp(ret, DiffEqual, a[:prefix]...)
+	ret = append(ret, diffWords(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	ret = appendDiffOp(ret, DiffEqual, a[len(a)-suffix:]...)
+
+	return
+}
+
+// diffWords diffs the specified words with Myers' algorithm.
+func diffWords(a, b []string) (ret []*DiffOp) {
+	n, m := len(a), len(b)
+	if 0 == n || 0 == m || maxDiffEditDistance < abs(n-m) {
+		ret = appendDiffOp(ret, DiffDelete, a...)
+
+		return appendDiffOp(ret, DiffInsert, b...)
+	}
+
+	max := n + m
+	if maxDiffEditDistance < max {
+		max = maxDiffEditDistance
+	}
+	v := make([]int, 2*max+3)
+	offset := max + 1
+	var trace [][]int // trace[d][k+d] is the furthest x on diagonal k before round d
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace)
+			}
+		}
+	}
+
+	ret = appendDiffOp(ret, DiffDelete, a...)
+
+	return appendDiffOp(ret, DiffInsert, b...)
+}
+
+func backtrackDiff(a, b []string, trace [][]int) (ret []*DiffOp) {
+	var reversed []*DiffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; 0 < d; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, &DiffOp{Type: DiffEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, &DiffOp{Type: DiffInsert, Text: b[y-1]})
+		} else {
+			reversed = append(reversed, &DiffOp{Type: DiffDelete, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for ; 0 < x; x-- { // the leading snake
+		reversed = append(reversed, &DiffOp{Type: DiffEqual, Text: a[x-1]})
+	}
+
+	for i := len(reversed) - 1; 0 <= i; i-- {
+		ret = appendDiffOp(ret, reversed[i].Type, reversed[i].Text)
+	}
+
+	return
+}
+
+// appendDiffOp appends the specified words to the specified diff, merges them into the last operation if it is of the
+// same type.
+func appendDiffOp(ops []*DiffOp, typ string, words ...string) []*DiffOp {
+	if 1 > len(words) {
+		return ops
+	}
+
+	text := strings.Join(words, "")
+	if 0 < len(ops) && typ == ops[len(ops)-1].Type {
+		ops[len(ops)-1].Text += text
+
+		return ops
+	}
+
+	return append(ops, &DiffOp{Type: typ, Text: text})
+}
+
+func splitWords(text string) (ret []string) {
+	start := -1 // start of the current word or whitespace run
+	wordRun := false
+	for i, r := range text {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		word := isDiffWordRune(r)
+		space := unicode.IsSpace(r)
+		if -1 < start && ((word && wordRun) || (space && !wordRun)) {
+			continue
+		}
+		if -1 < start {
+			ret = append(ret, text[start:i])
+			start = -1
+		}
+		if word || space {
+			start = i
+			wordRun = word
+
+			continue
+		}
+		ret = append(ret, text[i:i+size])
+	}
+	if -1 < start {
+		ret = append(ret, text[start:])
+	}
+
+	return
+}
+
+func isDiffWordRune(r rune) bool {
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) {
+		return false
+	}
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || '_' == r
+}
+
+func abs(n int) int {
+	if 0 > n {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,70 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	ops := DiffWords("The quick brown fox jumps.", "The quick red fox leaps, quickly.")
+	expected := "=The quick |-brown|+red|= fox |-jumps|+leaps, quickly|=."
+	if actual := diffString(ops); expected != actual {
+		t.Errorf("expected is [%s], actual is [%s]", expected, actual)
+	}
+
+	ops = DiffWords("你好世界", "你好，世界")
+	expected = "=你好|+，|=世界"
+	if actual := diffString(ops); expected != actual {
+		t.Errorf("expected is [%s], actual is [%s]", expected, actual)
+	}
+
+	if ops = DiffWords("same text", "same text"); 1 != len(ops) || DiffEqual != ops[0].Type {
+		t.Errorf("identical texts should be diffed as one equal operation")
+	}
+	if ops = DiffWords("", ""); 0 != len(ops) {
+		t.Errorf("empty texts should have no operation")
+	}
+}
+
+func TestDiffWordsRestore(t *testing.T) {
+	from := strings.Repeat("alpha beta gamma delta\n", 50)
+	to := strings.Replace(from, "beta", "BETA", 7)
+	to = strings.Replace(to, "delta\n", "", 3) + "omega"
+	var fromBuf, toBuf strings.Builder
+	for _, op := range DiffWords(from, to) {
+		if DiffInsert != op.Type {
+			fromBuf.WriteString(op.Text)
+		}
+		if DiffDelete != op.Type {
+			toBuf.WriteString(op.Text)
+		}
+	}
+	if from != fromBuf.String() || to != toBuf.String() {
+		t.Errorf("texts can not be restored from the diff")
+	}
+}
+
+func diffString(ops []*DiffOp) string {
+	var parts []string
+	for _, op := range ops {
+		parts = append(parts, map[string]string{DiffEqual: "=", DiffInsert: "+", DiffDelete: "-"}[op.Type]+op.Text)
+	}
+
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,201 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/bluele/gcache"
+)
+
+var embedClient = &http.Client{Timeout: 5 * time.Second}
+
+// embedCache caches embed HTML of links, empty for links failed to embed.
+var embedCache = gcache.New(1024).LRU().Build()
+
+// embedProvider represents a site whose links could be expanded to embeds.
+type embedProvider struct {
+	pattern  *regexp.Regexp                                     // matches links of the site
+	endpoint string                                             // oEmbed endpoint of the site
+	jsonHTML string                                             // field holding the embed HTML of link.json, used if the site has no oEmbed endpoint
+	embed    func(link string, groups []string) (string, error) // builds the embed HTML if the site has neither of them
+	video    bool                                               // whether embeds are players keeping a 16:9 ratio
+}
+
+// embedProviders holds the supported embed providers by name.
+var embedProviders = map[string]*embedProvider{
+	"youtube": {
+		pattern:  regexp.MustCompile(`^https?://(?:www\.|m\.)?(?:youtube\.com/watch\?(?:[^#\s]*&)?v=|youtu\.be/)[\w-]{11}`),
+		endpoint: "https://www.youtube.com/oembed",
+		video:    true,
+	},
+	"vimeo": {
+		pattern:  regexp.MustCompile(`^https?://(?:www\.)?vimeo\.com/\d+`),
+		endpoint: "https://vimeo.com/api/oembed.json",
+		video:    true,
+	},
+	"bilibili": {
+		pattern: regexp.MustCompile(`^https?://(?:www\.|m\.)?bilibili\.com/video/(?:(BV[0-9A-Za-z]{10})|av(\d+))`),
+		embed: func(link string, groups []string) (string, error) {
+			src := "https://player.bilibili.com/player.html?high_quality=1&"
+			if "" != groups[1] {
+				src += "bvid=" + groups[1]
+			} else {
+				src += "aid=" + groups[2]
+			}
+
+			return `<iframe src="` + src + `" frameborder="0" scrolling="no" allowfullscreen></iframe>`, nil
+		},
+		video: true,
+	},
+	"twitter": {
+		pattern:  regexp.MustCompile(`^https?://(?:www\.|mobile\.)?twitter\.com/\w+/status/\d+`),
+		endpoint: "https://publish.twitter.com/oembed",
+	},
+	"gist": {
+		pattern:  regexp.MustCompile(`^https://gist\.github\.com/[\w-]+/[0-9a-f]+$`),
+		jsonHTML: "div",
+	},
+}
+
+// EmbedProviders returns names of the supported embed providers.
+func EmbedProviders() (ret []string) {
+	for name := range embedProviders {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return
+}
+
+// IsEmbedProvider checks whether the specified name is a supported embed provider.
+func IsEmbedProvider(name string) bool {
+	_, ok := embedProviders[name]
+
+	return ok
+}
+
+// Embed returns the responsive embed HTML of the specified link if it belongs to one of the specified providers, returns
+// "" if not or the embed could not be fetched. Embeds are cached, so are failures.
+func Embed(link string, providers []string) string {
+	for _, name := range providers {
+		provider := embedProviders[name]
+		if nil == provider {
+			continue
+		}
+		groups := provider.pattern.FindStringSubmatch(link)
+		if nil == groups {
+			continue
+		}
+
+		if cached, err := embedCache.Get(link); nil == err {
+			return cached.(string)
+		}
+
+		embedHTML, err := provider.fetch(link, groups)
+		if nil != err || "" == embedHTML {
+			logger.Warnf("embeds link [%s] failed: %v", link, err)
+			embedCache.SetWithExpire(link, "", time.Hour)
+
+			return ""
+		}
+
+		class := "pipe-embed"
+		if provider.video {
+			class += " pipe-embed--video"
+		}
+		ret := `<div class="` + class + `">` + embedHTML + `</div>`
+		embedCache.SetWithExpire(link, ret, 24*time.Hour)
+
+		return ret
+	}
+
+	return ""
+}
+
+// fetch fetches the embed HTML of the specified link.
+func (provider *embedProvider) fetch(link string, groups []string) (string, error) {
+	if nil != provider.embed {
+		return provider.embed(link, groups)
+	}
+
+	requestURL := link + ".json"
+	if "" != provider.endpoint {
+		requestURL = provider.endpoint + "?format=json&url=" + url.QueryEscape(link)
+	}
+	resp, err := embedClient.Get(requestURL)
+	if nil != err {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if http.StatusOK != resp.StatusCode {
+		return "", errors.New("responded status code [" + strconv.Itoa(resp.StatusCode) + "]")
+	}
+
+	data := map[string]interface{}{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&data); nil != err {
+		return "", err
+	}
+	if "" != provider.jsonHTML {
+		ret, _ := data[provider.jsonHTML].(string)
+
+		return ret, nil
+	}
+	if "photo" == data["type"] {
+		src, _ := data["url"].(string)
+		title, _ := data["title"].(string)
+
+		return `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(title) + `">`, nil
+	}
+	ret, _ := data["html"].(string)
+
+	return ret, nil
+}
+
+// renderEmbeds replaces paragraphs holding nothing but a bare link of the specified providers in the specified
+// document with embeds.
+func renderEmbeds(doc *goquery.Document, providers []string) {
+	if 1 > len(providers) {
+		return
+	}
+
+	doc.Find("p").Each(func(i int, p *goquery.Selection) {
+		link := strings.TrimSpace(p.Text())
+		if !strings.HasPrefix(link, "http") || strings.ContainsAny(link, " \t\n") {
+			return
+		}
+		children := p.Children()
+		if 1 < children.Length() || (1 == children.Length() && ("a" != goquery.NodeName(children) || link != children.AttrOr("href", ""))) {
+			return
+		}
+
+		if embedHTML := Embed(link, providers); "" != embedHTML {
+			p.ReplaceWithHtml(embedHTML)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownEmbeds(t *testing.T) {
+	mdText := "https://www.bilibili.com/video/BV1GJ411x7h7\n\nSee https://www.bilibili.com/video/av170001 too.\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{Embeds: []string{"bilibili"}}).ContentHTML
+	if 1 != strings.Count(html, `<div class="pipe-embed pipe-embed--video"><iframe src="https://player.bilibili.com/player.html?high_quality=1&amp;bvid=BV1GJ411x7h7"`) {
+		t.Errorf("bare link should be embedded [%s]", html)
+	}
+	if !strings.Contains(html, "See ") || 1 != strings.Count(html, "<iframe") {
+		t.Errorf("link in text should be kept [%s]", html)
+	}
+
+	html = MarkdownWithOptions(mdText, &MarkdownOptions{}).ContentHTML
+	if strings.Contains(html, "<iframe") {
+		t.Errorf("links should not be embedded if the provider is not allowed [%s]", html)
+	}
+}
+
+func TestIsEmbedProvider(t *testing.T) {
+	if !IsEmbedProvider("youtube") || IsEmbedProvider("myspace") {
+		t.Error("unexpected embed providers")
+	}
+}
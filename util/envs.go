@@ -0,0 +1,120 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// envWords are words of configuration fields which should not be split when naming environment variables.
+var envWords = strings.NewReplacer("SQLite", "Sqlite", "MySQL", "Mysql", "WebP", "Webp")
+
+// EnvName returns the environment variable name of the specified configuration field, e.g. PIPE_LOG_LEVEL of field
+// LogLevel and prefix PIPE, PIPE_TLS_DOMAINS of field TLSDomains.
+func EnvName(prefix, field string) string {
+	runes := []rune(envWords.Replace(field))
+	buf := &strings.Builder{}
+	buf.WriteString(prefix)
+	for i, r := range runes {
+		if 0 == i || unicode.IsUpper(r) && (!unicode.IsUpper(runes[i-1]) && !unicode.IsDigit(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			buf.WriteByte('_')
+		}
+		buf.WriteRune(unicode.ToUpper(r))
+	}
+
+	return buf.String()
+}
+
+// ApplyEnv sets fields of the specified struct pointer with the environment variables named by EnvName and returns
+// names of the applied variables. Slices of strings and numbers are comma separated, other structured fields are
+// in JSON.
+func ApplyEnv(prefix string, v interface{}) (ret []string, err error) {
+	value := reflect.ValueOf(v)
+	if reflect.Ptr != value.Kind() || reflect.Struct != value.Elem().Kind() {
+		return nil, errors.New("applies environment variables to a non-struct pointer")
+	}
+
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if "" != field.PkgPath {
+			continue
+		}
+
+		name := EnvName(prefix, field.Name)
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err = setEnvField(value.Field(i), env); nil != err {
+			return ret, errors.New("parses environment variable [" + name + "] failed: " + err.Error())
+		}
+		ret = append(ret, name)
+	}
+
+	return
+}
+
+func setEnvField(field reflect.Value, env string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(env)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(env)
+		if nil != err {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(env, 10, 64)
+		if nil != err {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Slice:
+		elemKind := field.Type().Elem().Kind()
+		if (reflect.String != elemKind && reflect.Int != elemKind) || strings.HasPrefix(strings.TrimSpace(env), "[") {
+			return json.Unmarshal([]byte(env), field.Addr().Interface())
+		}
+
+		items := reflect.MakeSlice(field.Type(), 0, 4)
+		for _, item := range strings.Split(env, ",") {
+			if item = strings.TrimSpace(item); "" == item {
+				continue
+			}
+
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setEnvField(elem, item); nil != err {
+				return err
+			}
+			items = reflect.Append(items, elem)
+		}
+		field.Set(items)
+	default:
+		return json.Unmarshal([]byte(env), field.Addr().Interface())
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	names := map[string]string{
+		"Server":         "PIPE_SERVER",
+		"LogLevel":       "PIPE_LOG_LEVEL",
+		"SQLite":         "PIPE_SQLITE",
+		"MySQL":          "PIPE_MYSQL",
+		"ShowSQL":        "PIPE_SHOW_SQL",
+		"TLSDomains":     "PIPE_TLS_DOMAINS",
+		"HSTSMaxAge":     "PIPE_HSTS_MAX_AGE",
+		"BackupS3":       "PIPE_BACKUP_S3",
+		"PlantUMLServer": "PIPE_PLANT_UML_SERVER",
+		"WebPEncoder":    "PIPE_WEBP_ENCODER",
+	}
+	for field, expected := range names {
+		if name := EnvName("PIPE", field); expected != name {
+			t.Errorf("expected is [%s], actual is [%s]", expected, name)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	type conf struct {
+		Server      string
+		Port        string
+		ShowSQL     bool
+		MaxAge      int
+		TLSDomains  []string
+		Sizes       []int
+		Policies    []map[string]string
+		notExported string
+	}
+
+	envs := map[string]string{
+		"TEST_SERVER":      "https://pipe.example.com",
+		"TEST_SHOW_SQL":    "true",
+		"TEST_MAX_AGE":     "60",
+		"TEST_TLS_DOMAINS": "a.example.com, b.example.com",
+		"TEST_SIZES":       "[320, 768]",
+		"TEST_POLICIES":    `[{"Path": "/content"}]`,
+	}
+	for name, value := range envs {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	c := &conf{Port: "5897"}
+	names, err := ApplyEnv("TEST", c)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if len(envs) != len(names) {
+		t.Errorf("expected is [%d], actual is [%d]", len(envs), len(names))
+	}
+	expected := &conf{
+		Server:     "https://pipe.example.com",
+		Port:       "5897",
+		ShowSQL:    true,
+		MaxAge:     60,
+		TLSDomains: []string{"a.example.com", "b.example.com"},
+		Sizes:      []int{320, 768},
+		Policies:   []map[string]string{{"Path": "/content"}},
+	}
+	if !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected is [%+v], actual is [%+v]", expected, c)
+	}
+
+	os.Setenv("TEST_MAX_AGE", "a minute")
+	if _, err = ApplyEnv("TEST", c); nil == err {
+		t.Error("invalid int should fail")
+	}
+}
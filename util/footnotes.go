@@ -0,0 +1,133 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// footnoteDefRegexp matches footnote definitions like [^1]: text.
+var footnoteDefRegexp = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s?(.*)$`)
+
+// footnoteRefRegexp matches footnote references like [^1].
+var footnoteRefRegexp = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// footnoteRefPlaceholderRegexp matches placeholders of footnote references in rendered HTML.
+var footnoteRefPlaceholderRegexp = regexp.MustCompile(`pipefnref(\d+)r(\d+)x`)
+
+// footnote represents a footnote extracted from markdown text.
+type footnote struct {
+	text string // markdown text of the definition
+	refs int    // count of references
+}
+
+// protectFootnotes removes footnote definitions from the specified markdown text and replaces references to them with
+// placeholders. Footnotes are numbered in order of their first references, unreferenced ones are dropped.
+func protectFootnotes(mdText string) (string, []*footnote) {
+	lines := strings.Split(mdText, "\n")
+	inCode := fencedCodeLines(lines)
+	defs := map[string]string{}
+	var rest []string
+	var restInCode []bool
+	for i := 0; i < len(lines); i++ {
+		groups := footnoteDefRegexp.FindStringSubmatch(lines[i])
+		if inCode[i] || nil == groups {
+			rest = append(rest, lines[i])
+			restInCode = append(restInCode, inCode[i])
+
+			continue
+		}
+
+		text := []string{groups[2]}
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], "    ") || strings.HasPrefix(lines[i+1], "\t")) {
+			i++
+			text = append(text, strings.TrimSpace(lines[i]))
+		}
+		if _, ok := defs[groups[1]]; !ok {
+			defs[groups[1]] = strings.Join(text, "\n")
+		}
+	}
+	if 1 > len(defs) {
+		return mdText, nil
+	}
+
+	var notes []*footnote
+	numbers := map[string]int{}
+	for i, line := range rest {
+		if restInCode[i] {
+			continue
+		}
+
+		rest[i] = replaceOutsideCodeSpans(line, footnoteRefRegexp, func(ref string) string {
+			label := footnoteRefRegexp.FindStringSubmatch(ref)[1]
+			text, ok := defs[label]
+			if !ok {
+				return ref
+			}
+			number, ok := numbers[label]
+			if !ok {
+				notes = append(notes, &footnote{text: text})
+				number = len(notes)
+				numbers[label] = number
+			}
+			note := notes[number-1]
+			note.refs++
+
+			return "pipefnref" + strconv.Itoa(number) + "r" + strconv.Itoa(note.refs) + "x"
+		})
+	}
+
+	return strings.Join(rest, "\n"), notes
+}
+
+// restoreFootnotes replaces placeholders in the specified rendered HTML with footnote references and appends the
+// footnotes, each of them links back to its first reference.
+func restoreFootnotes(contentHTML string, notes []*footnote) string {
+	if 1 > len(notes) {
+		return contentHTML
+	}
+
+	contentHTML = footnoteRefPlaceholderRegexp.ReplaceAllStringFunc(contentHTML, func(placeholder string) string {
+		groups := footnoteRefPlaceholderRegexp.FindStringSubmatch(placeholder)
+		number, ref := groups[1], groups[2]
+		id := "fnref-" + number
+		if "1" != ref {
+			id += "-" + ref
+		}
+
+		return `<sup class="footnotes-ref" id="` + id + `"><a href="#fn-` + number + `">` + number + `</a></sup>`
+	})
+
+	buf := &strings.Builder{}
+	buf.WriteString(`<div class="footnotes"><hr><ol>`)
+	for i, note := range notes {
+		number := strconv.Itoa(i + 1)
+		backref := ` <a href="#fnref-` + number + `" class="footnotes-backref">↩</a>`
+		noteHTML := renderMarkdownFragment(note.text)
+		if strings.HasSuffix(noteHTML, "</p>") {
+			noteHTML = strings.TrimSuffix(noteHTML, "</p>") + backref + "</p>"
+		} else {
+			noteHTML += backref
+		}
+		buf.WriteString(`<li id="fn-` + number + `">` + noteHTML + `</li>`)
+	}
+	buf.WriteString(`</ol></div>`)
+
+	return contentHTML + buf.String()
+}
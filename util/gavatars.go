@@ -20,14 +20,14 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/b3log/gulu"
+	"github.com/b3log/pipe/log"
 )
 
 // Logger
-var logger = gulu.Log.NewLogger(os.Stdout)
+var logger = gulu.Log.NewLogger(log.Writer)
 
 // RandAvatarData returns random avatar image byte array data from Gravatar (http://www.gravatar.com).
 // Sees https://github.com/b3log/pipe/issues/131 for more details.
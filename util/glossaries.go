@@ -0,0 +1,174 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// GlossaryTerm represents a glossary term.
+type GlossaryTerm struct {
+	Term       string
+	Definition string
+	URL        string
+}
+
+// glossarySkipTags holds the elements whose text should not be linked.
+var glossarySkipTags = map[string]bool{
+	"a": true, "abbr": true, "code": true, "pre": true, "script": true, "style": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// LinkGlossary wraps the first occurrence of each of the specified terms in the specified content HTML with a link
+// (if the term has a valid URL) or an abbreviation, both using the term definition as the tooltip. Terms are matched
+// as whole words.
+func LinkGlossary(contentHTML string, terms []*GlossaryTerm) string {
+	if 1 > len(terms) {
+		return contentHTML
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	if nil != err {
+		logger.Errorf("parse content HTML failed: %s", err)
+
+		return contentHTML
+	}
+
+	linked := map[string]bool{}
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; nil != child; child = child.NextSibling {
+			switch child.Type {
+			case html.TextNode:
+				child = linkGlossaryText(child, terms, linked)
+			case html.ElementNode:
+				if !glossarySkipTags[child.Data] {
+					walk(child)
+				}
+			}
+		}
+	}
+	body := doc.Find("body")
+	walk(body.Get(0))
+
+	ret, err := body.Html()
+	if nil != err {
+		logger.Errorf("render content HTML failed: %s", err)
+
+		return contentHTML
+	}
+
+	return ret
+}
+
+// linkGlossaryText links terms in the specified text node, returns the last node split from it.
+func linkGlossaryText(node *html.Node, terms []*GlossaryTerm, linked map[string]bool) *html.Node {
+	for {
+		text := node.Data
+		idx := -1
+		var term *GlossaryTerm
+		for _, t := range terms {
+			if "" == t.Term || linked[t.Term] {
+				continue
+			}
+			i := indexGlossaryTerm(text, t.Term)
+			if 0 > i {
+				continue
+			}
+			if 0 > idx || i < idx || (i == idx && len(t.Term) > len(term.Term)) {
+				idx, term = i, t
+			}
+		}
+		if nil == term {
+			return node
+		}
+		linked[term.Term] = true
+
+		tag := "abbr"
+		attrs := []html.Attribute{{Key: "class", Val: "glossary"}, {Key: "title", Val: term.Definition}}
+		if href := glossaryHref(term.URL); "" != href {
+			tag = "a"
+			attrs = append(attrs, html.Attribute{Key: "href", Val: href})
+		}
+		element := &html.Node{Type: html.ElementNode, Data: tag, Attr: attrs}
+		element.AppendChild(&html.Node{Type: html.TextNode, Data: term.Term})
+		rest := &html.Node{Type: html.TextNode, Data: text[idx+len(term.Term):]}
+
+		node.Data = text[:idx]
+		node.Parent.InsertBefore(element, node.NextSibling)
+		node.Parent.InsertBefore(rest, element.NextSibling)
+		node = rest
+	}
+}
+
+// indexGlossaryTerm returns the index of the first occurrence of the specified term as a whole word in the specified
+// text, returns -1 if not found. "Go" doesn't occur in "Google" for example.
+func indexGlossaryTerm(text, term string) int {
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if 0 > i {
+			return -1
+		}
+		i += offset
+
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[i+len(term):])
+		if !(isGlossaryWordRune(before) && isGlossaryWordRune(first)) && !(isGlossaryWordRune(after) && isGlossaryWordRune(last)) {
+			return i
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		offset = i + size
+	}
+
+	return -1
+}
+
+// isGlossaryWordRune checks whether the specified rune is a part of a word. CJK characters are not since CJK words
+// are not separated.
+func isGlossaryWordRune(r rune) bool {
+	return ('_' == r || unicode.IsLetter(r) || unicode.IsDigit(r)) &&
+		!unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// IsGlossaryURL checks whether the specified URL of a glossary term is an HTTP(S) URL or a relative URL.
+func IsGlossaryURL(rawURL string) bool {
+	return "" != glossaryHref(rawURL)
+}
+
+// glossaryHref returns the escaped href of the specified URL of a glossary term, returns "" if it's neither an HTTP(S)
+// URL nor a relative URL.
+func glossaryHref(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if "" == rawURL {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if nil != err || ("" != u.Scheme && "http" != u.Scheme && "https" != u.Scheme) || nil != u.User {
+		return ""
+	}
+
+	return u.String()
+}
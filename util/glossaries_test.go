@@ -0,0 +1,79 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import "testing"
+
+func TestLinkGlossary(t *testing.T) {
+	terms := []*GlossaryTerm{
+		{Term: "Pipe", Definition: "小而美的博客平台", URL: "https://github.com/b3log/pipe"},
+		{Term: "Go", Definition: "Go 语言"},
+	}
+
+	contentHTML := LinkGlossary("<p>Pipe is written in Go, Pipe <code>Go</code></p>", terms)
+	expected := `<p><a class="glossary" title="小而美的博客平台" href="https://github.com/b3log/pipe">Pipe</a> is written in <abbr class="glossary" title="Go 语言">Go</abbr>, Pipe <code>Go</code></p>`
+	if expected != contentHTML {
+		t.Errorf("expected is [%s], actual is [%s]", expected, contentHTML)
+	}
+
+	contentHTML = LinkGlossary("<p><code>Go</code> <a href=\"#\">Pipe</a></p>", terms)
+	expected = "<p><code>Go</code> <a href=\"#\">Pipe</a></p>"
+	if expected != contentHTML {
+		t.Errorf("expected is [%s], actual is [%s]", expected, contentHTML)
+	}
+}
+
+func TestLinkGlossaryWordBoundary(t *testing.T) {
+	terms := []*GlossaryTerm{
+		{Term: "Go", Definition: "Go 语言"},
+		{Term: "博客", Definition: "Blog"},
+	}
+
+	contentHTML := LinkGlossary("<p>Google and Golang, Gopher_Go, Go!</p><p>小而美的博客平台</p>", terms)
+	expected := `<p>Google and Golang, Gopher_Go, <abbr class="glossary" title="Go 语言">Go</abbr>!</p><p>小而美的<abbr class="glossary" title="Blog">博客</abbr>平台</p>`
+	if expected != contentHTML {
+		t.Errorf("expected is [%s], actual is [%s]", expected, contentHTML)
+	}
+
+	contentHTML = LinkGlossary("<p>Google</p>", terms)
+	expected = "<p>Google</p>"
+	if expected != contentHTML {
+		t.Errorf("expected is [%s], actual is [%s]", expected, contentHTML)
+	}
+}
+
+func TestLinkGlossaryURL(t *testing.T) {
+	urls := map[string]string{
+		"https://github.com/b3log/pipe":     `<a class="glossary" title="Pipe" href="https://github.com/b3log/pipe">Pipe</a>`,
+		"/tags/pipe":                        `<a class="glossary" title="Pipe" href="/tags/pipe">Pipe</a>`,
+		`/tags/pipe"><script>x</script>`:    `<a class="glossary" title="Pipe" href="/tags/pipe%22%3E%3Cscript%3Ex%3C/script%3E">Pipe</a>`,
+		"javascript:alert(1)":               `<abbr class="glossary" title="Pipe">Pipe</abbr>`,
+		"JavaScript:alert(1)":               `<abbr class="glossary" title="Pipe">Pipe</abbr>`,
+		"data:text/html,<script>x</script>": `<abbr class="glossary" title="Pipe">Pipe</abbr>`,
+	}
+	for url, expected := range urls {
+		terms := []*GlossaryTerm{{Term: "Pipe", Definition: "Pipe", URL: url}}
+		expected = "<p>" + expected + "</p>"
+		if contentHTML := LinkGlossary("<p>Pipe</p>", terms); expected != contentHTML {
+			t.Errorf("URL [%s] expected is [%s], actual is [%s]", url, expected, contentHTML)
+		}
+	}
+
+	if IsGlossaryURL("javascript:alert(1)") || !IsGlossaryURL("https://b3log.org") || !IsGlossaryURL("pipe") {
+		t.Error("check glossary URL failed")
+	}
+}
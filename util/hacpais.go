@@ -17,11 +17,16 @@
 package util
 
 import (
-	"crypto/tls"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,48 +34,268 @@ import (
 // HacPaiURL is the URL of HacPai community.
 const HacPaiURL = "https://hacpai.com"
 
-// HacPaiAPI is a reverse proxy for https://hacpai.com.
+// HacPai API proxy arguments.
+const (
+	hacpaiTimeout          = 10 * time.Second // upstream request timeout
+	hacpaiCacheTTL         = 5 * time.Minute  // cached GET responses are refreshed asynchronously after this duration
+	hacpaiCacheStaleTTL    = time.Hour        // cached GET responses are discarded after this duration
+	hacpaiCacheMaxEntries  = 256
+	hacpaiMaxResponseSize  = 4 * 1024 * 1024
+	hacpaiBreakerThreshold = 5 // consecutive failures to open the circuit
+	hacpaiBreakerCooldown  = 30 * time.Second
+)
+
+// hacpaiUpstream is the upstream of the HacPai API proxy.
+var hacpaiUpstream = HacPaiURL
+
+var hacpaiTransport = &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	DialContext:           (&net.Dialer{Timeout: hacpaiTimeout}).DialContext,
+	TLSHandshakeTimeout:   hacpaiTimeout,
+	ResponseHeaderTimeout: hacpaiTimeout,
+	IdleConnTimeout:       90 * time.Second,
+	MaxIdleConnsPerHost:   8,
+}
+
+var hacpaiClient = &http.Client{Transport: hacpaiTransport, Timeout: hacpaiTimeout}
+
+var hacpaiBreaker = &circuitBreaker{threshold: hacpaiBreakerThreshold, cooldown: hacpaiBreakerCooldown}
+
+var hacpaiCache = &hacpaiResponseCache{
+	entries:    map[string]*hacpaiResponse{},
+	refreshing: map[string]bool{},
+}
+
+var errHacPaiUnavailable = errors.New("HacPai is unavailable")
+
+// HacPaiAPI is a reverse proxy for https://hacpai.com. GET responses are cached and refreshed asynchronously, the
+// upstream is skipped for a while after consecutive failures, and a stale cached response or an error result is
+// returned if the upstream is unavailable.
 func HacPaiAPI() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		proxy := httputil.NewSingleHostReverseProxy(&url.URL{
-			Scheme: "https",
-			Host:   "hacpai.com",
-		})
+		if http.MethodGet == c.Request.Method {
+			serveHacPaiGet(c)
 
-		proxy.Transport = &http.Transport{DialTLS: dialTLS}
+			return
+		}
+
+		if !hacpaiBreaker.allow() {
+			writeHacPaiUnavailable(c)
+
+			return
+		}
+
+		upstream, _ := url.Parse(hacpaiUpstream)
+		proxy := httputil.NewSingleHostReverseProxy(upstream)
+		proxy.Transport = hacpaiTransport
 		director := proxy.Director
 		proxy.Director = func(req *http.Request) {
 			director(req)
 			req.Host = req.URL.Host
-			req.URL.Path = req.URL.Path[len("api/hp/"):]
+			req.URL.Path = hacpaiPath(c.Request.URL.Path)
+			req.Header.Del("Cookie")
 		}
+		proxy.ModifyResponse = func(res *http.Response) error {
+			hacpaiBreaker.done(http.StatusInternalServerError > res.StatusCode)
 
-		proxy.ServeHTTP(c.Writer, c.Request)
+			return nil
+		}
+		proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+			hacpaiBreaker.done(false)
+			Log(c).Warnf("proxy HacPai request [%s] failed: %s", req.URL, err)
+			writeHacPaiUnavailable(c)
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), hacpaiTimeout)
+		defer cancel()
+		proxy.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 	}
 }
 
-func dialTLS(network, addr string) (net.Conn, error) {
-	conn, err := net.Dial(network, addr)
-	if err != nil {
+func serveHacPaiGet(c *gin.Context) {
+	key := hacpaiPath(c.Request.URL.Path)
+	if "" != c.Request.URL.RawQuery {
+		key += "?" + c.Request.URL.RawQuery
+	}
+
+	if cached := hacpaiCache.get(key); nil != cached {
+		if time.Since(cached.time) > hacpaiCacheTTL {
+			go hacpaiCache.refresh(key)
+		}
+		writeHacPaiResponse(c, cached)
+
+		return
+	}
+
+	res, err := fetchHacPai(c.Request.Context(), key)
+	if nil != err {
+		Log(c).Warnf("proxy HacPai request [%s] failed: %s", key, err)
+		writeHacPaiUnavailable(c)
+
+		return
+	}
+	writeHacPaiResponse(c, res)
+}
+
+// hacpaiPath trims the proxy prefix, for example /api/hp/apis/articles to /apis/articles.
+func hacpaiPath(path string) string {
+	return path[len("api/hp/"):]
+}
+
+func writeHacPaiResponse(c *gin.Context, res *hacpaiResponse) {
+	c.Data(res.status, res.contentType, res.body)
+}
+
+func writeHacPaiUnavailable(c *gin.Context) {
+	result := NewResult(c)
+	result.Code = CodeErr
+	result.ErrCode = ErrCodeUnavailable
+	result.Msg = errHacPaiUnavailable.Error()
+	c.AbortWithStatusJSON(http.StatusOK, result)
+}
+
+// hacpaiResponse represents a response of HacPai.
+type hacpaiResponse struct {
+	status      int
+	contentType string
+	body        []byte
+	time        time.Time
+}
+
+// fetchHacPai requests the specified path of HacPai with GET, caches the response if succeeded.
+func fetchHacPai(ctx context.Context, key string) (*hacpaiResponse, error) {
+	if !hacpaiBreaker.allow() {
+		return nil, errHacPaiUnavailable
+	}
+
+	req, err := http.NewRequest(http.MethodGet, hacpaiUpstream+key, nil)
+	if nil != err {
 		return nil, err
 	}
+	res, err := hacpaiClient.Do(req.WithContext(ctx))
+	if nil != err {
+		hacpaiBreaker.done(false)
 
-	host, _, err := net.SplitHostPort(addr)
-	if err != nil {
 		return nil, err
 	}
-	cfg := &tls.Config{ServerName: host}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(&io.LimitedReader{R: res.Body, N: hacpaiMaxResponseSize})
+	if nil != err {
+		hacpaiBreaker.done(false)
 
-	tlsConn := tls.Client(conn, cfg)
-	if err := tlsConn.Handshake(); err != nil {
-		conn.Close()
 		return nil, err
 	}
+	if http.StatusInternalServerError <= res.StatusCode {
+		hacpaiBreaker.done(false)
+
+		return nil, errors.New("HacPai responded " + res.Status)
+	}
+	hacpaiBreaker.done(true)
+
+	ret := &hacpaiResponse{status: res.StatusCode, contentType: res.Header.Get("Content-Type"), body: body, time: time.Now()}
+	if http.StatusOK == res.StatusCode {
+		hacpaiCache.put(key, ret)
+	}
+
+	return ret, nil
+}
+
+// hacpaiResponseCache caches GET responses of HacPai.
+type hacpaiResponseCache struct {
+	mutex      sync.Mutex
+	entries    map[string]*hacpaiResponse
+	refreshing map[string]bool
+}
 
-	cs := tlsConn.ConnectionState()
-	cert := cs.PeerCertificates[0]
+func (cache *hacpaiResponseCache) get(key string) *hacpaiResponse {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
 
-	cert.VerifyHostname(host)
+	ret := cache.entries[key]
+	if nil == ret || time.Since(ret.time) > hacpaiCacheStaleTTL {
+		return nil
+	}
+
+	return ret
+}
+
+func (cache *hacpaiResponseCache) put(key string, res *hacpaiResponse) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, ok := cache.entries[key]; !ok && hacpaiCacheMaxEntries <= len(cache.entries) {
+		for k, entry := range cache.entries {
+			if time.Since(entry.time) > hacpaiCacheStaleTTL {
+				delete(cache.entries, k)
+			}
+		}
+		for k := range cache.entries {
+			if hacpaiCacheMaxEntries > len(cache.entries) {
+				break
+			}
+			delete(cache.entries, k)
+		}
+	}
+	cache.entries[key] = res
+}
 
-	return tlsConn, nil
+// refresh refreshes the cached response of the specified key, the stale one is kept if failed.
+func (cache *hacpaiResponseCache) refresh(key string) {
+	cache.mutex.Lock()
+	if cache.refreshing[key] {
+		cache.mutex.Unlock()
+
+		return
+	}
+	cache.refreshing[key] = true
+	cache.mutex.Unlock()
+
+	defer func() {
+		cache.mutex.Lock()
+		delete(cache.refreshing, key)
+		cache.mutex.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), hacpaiTimeout)
+	defer cancel()
+	if _, err := fetchHacPai(ctx, key); nil != err {
+		logger.Warnf("refresh HacPai response [%s] failed: %s", key, err)
+	}
+}
+
+// circuitBreaker stops calling a failing service for a cooldown duration after consecutive failures.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow checks whether the service could be called.
+func (breaker *circuitBreaker) allow() bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	return !time.Now().Before(breaker.openUntil)
+}
+
+// done records the result of a call. A failure right after the cooldown opens the circuit again.
+func (breaker *circuitBreaker) done(succeeded bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if succeeded {
+		breaker.failures = 0
+
+		return
+	}
+
+	breaker.failures++
+	if breaker.threshold <= breaker.failures {
+		breaker.openUntil = time.Now().Add(breaker.cooldown)
+		breaker.failures = breaker.threshold - 1
+	}
 }
@@ -0,0 +1,96 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchHacPai(t *testing.T) {
+	var failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if 1 == atomic.LoadInt32(&failing) {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	upstream, breaker := hacpaiUpstream, hacpaiBreaker
+	hacpaiUpstream = server.URL
+	hacpaiBreaker = &circuitBreaker{threshold: 2, cooldown: time.Minute}
+	defer func() {
+		hacpaiUpstream, hacpaiBreaker = upstream, breaker
+	}()
+
+	res, err := fetchHacPai(context.Background(), "/apis/sponsors")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if `{"path":"/apis/sponsors"}` != string(res.body) {
+		t.Errorf("unexpected body [%s]", res.body)
+	}
+	if nil == hacpaiCache.get("/apis/sponsors") {
+		t.Errorf("response should be cached")
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	for i := 0; i < 2; i++ {
+		if _, err := fetchHacPai(context.Background(), "/apis/articles"); nil == err {
+			t.Errorf("fetch should fail")
+		}
+	}
+	if hacpaiBreaker.allow() {
+		t.Errorf("circuit should be open after consecutive failures")
+	}
+	if _, err := fetchHacPai(context.Background(), "/apis/articles"); errHacPaiUnavailable != err {
+		t.Errorf("expected is [%v], actual is [%v]", errHacPaiUnavailable, err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := &circuitBreaker{threshold: 3, cooldown: time.Millisecond}
+	breaker.done(false)
+	breaker.done(false)
+	breaker.done(true)
+	breaker.done(false)
+	if !breaker.allow() {
+		t.Errorf("circuit should be closed")
+	}
+
+	breaker.done(false)
+	breaker.done(false)
+	if breaker.allow() {
+		t.Errorf("circuit should be open")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if !breaker.allow() {
+		t.Errorf("circuit should be half-open after cooldown")
+	}
+	breaker.done(false)
+	if breaker.allow() {
+		t.Errorf("circuit should be open again after a failure in half-open state")
+	}
+}
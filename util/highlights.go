@@ -0,0 +1,132 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alecthomas/chroma"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/bluele/gcache"
+)
+
+var highlightCache = gcache.New(1024).LRU().Build()
+
+// highlightSkipLanguages holds the languages of code blocks rendered by the client as diagrams, formulas or scores.
+var highlightSkipLanguages = map[string]bool{
+	"math": true, "mermaid": true, "echarts": true, "abc": true, "mindmap": true, "graphviz": true, "flowchart": true,
+}
+
+// HighlightStyles returns names of the available code highlighting styles, sorted.
+func HighlightStyles() []string {
+	ret := styles.Names()
+	sort.Strings(ret)
+
+	return ret
+}
+
+// IsHighlightStyle checks whether the specified name is an available code highlighting style.
+func IsHighlightStyle(name string) bool {
+	_, ok := styles.Registry[name]
+
+	return ok
+}
+
+// HighlightCode highlights code blocks in the specified content HTML with inline styles of the specified style, so
+// the code renders highlighted without scripts or stylesheets, e.g. in feed readers. The language of a code block is
+// taken from its language-* class, or guessed from the code. Returns the content HTML as is if the style is empty.
+func HighlightCode(contentHTML, style string) string {
+	if "" == style || !strings.Contains(contentHTML, "<pre") {
+		return contentHTML
+	}
+
+	digest := md5.New()
+	digest.Write([]byte(style + "\n" + contentHTML))
+	key := string(digest.Sum(nil))
+	if cached, err := highlightCache.Get(key); nil == err {
+		return cached.(string)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	if nil != err {
+		logger.Errorf("parse content HTML failed: %s", err)
+
+		return contentHTML
+	}
+
+	chromaStyle := styles.Get(style)
+	formatter := chromahtml.New(chromahtml.PreventSurroundingPre())
+	highlighted := false
+	doc.Find("pre > code").Each(func(i int, code *goquery.Selection) {
+		language := ""
+		for _, class := range strings.Fields(code.AttrOr("class", "")) {
+			if strings.HasPrefix(class, "language-") {
+				language = strings.TrimPrefix(class, "language-")
+			}
+		}
+		if highlightSkipLanguages[language] {
+			return
+		}
+
+		lexer := lexers.Get(language)
+		if nil == lexer {
+			lexer = lexers.Analyse(code.Text())
+		}
+		if nil == lexer {
+			return
+		}
+		iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.Text())
+		if nil != err {
+			return
+		}
+		buf := &bytes.Buffer{}
+		if err = formatter.Format(buf, chromaStyle, iterator); nil != err {
+			return
+		}
+
+		code.SetHtml(buf.String())
+		background := chromaStyle.Get(chroma.Background)
+
+		preStyle := ""
+		if background.Colour.IsSet() {
+			preStyle += "color:" + background.Colour.String() + ";"
+		}
+		if background.Background.IsSet() {
+			preStyle += "background-color:" + background.Background.String() + ";"
+		}
+		if "" != preStyle {
+			code.Parent().SetAttr("style", preStyle)
+		}
+		highlighted = true
+	})
+	if !highlighted {
+		highlightCache.Set(key, contentHTML)
+
+		return contentHTML
+	}
+
+	ret, _ := doc.Find("body").Html()
+	highlightCache.Set(key, ret)
+
+	return ret
+}
@@ -0,0 +1,43 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightCode(t *testing.T) {
+	contentHTML := Markdown("```go\nfunc main() {}\n```\n\n```mermaid\ngraph TD\n```\n").ContentHTML
+	if HighlightCode(contentHTML, "") != contentHTML {
+		t.Error("code should not be highlighted with an empty style")
+	}
+
+	highlighted := HighlightCode(contentHTML, "github")
+	if !strings.Contains(highlighted, "<span style=") {
+		t.Errorf("code should be highlighted with inline styles [%s]", highlighted)
+	}
+	if !strings.Contains(highlighted, `<code class="language-mermaid">graph TD`) {
+		t.Errorf("diagrams should be left to the client [%s]", highlighted)
+	}
+}
+
+func TestIsHighlightStyle(t *testing.T) {
+	if !IsHighlightStyle("github") || IsHighlightStyle("not-a-style") {
+		t.Error("check highlight style failed")
+	}
+}
@@ -0,0 +1,184 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signedHeaders are the headers covered by HTTP signatures of outgoing requests.
+const signedHeaders = "(request-target) host date digest"
+
+// maxSignatureAge is the max clock skew accepted when verifying HTTP signatures.
+const maxSignatureAge = 12 * time.Hour
+
+// GenerateKeyPair generates a RSA key pair, returns the PEM encoded private key and public key.
+func GenerateKeyPair() (privateKeyPEM, publicKeyPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if nil != err {
+		return
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if nil != err {
+		return
+	}
+
+	privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	publicKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	return
+}
+
+// ParsePrivateKey parses the specified PEM encoded RSA private key.
+func ParsePrivateKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if nil == block {
+		return nil, errors.New("invalid private key")
+	}
+
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// ParsePublicKey parses the specified PEM encoded RSA public key.
+func ParsePublicKey(publicKeyPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if nil == block {
+		return nil, errors.New("invalid public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if nil != err {
+		return nil, err
+	}
+	ret, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not a RSA key")
+	}
+
+	return ret, nil
+}
+
+// SignRequest signs the specified request with the specified key as described in
+// https://tools.ietf.org/html/draft-cavage-http-signatures-10, the Date, Digest and Signature headers are set.
+func SignRequest(req *http.Request, body []byte, keyID string, key *rsa.PrivateKey) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Digest", bodyDigest(body))
+	if "" == req.Header.Get("Host") {
+		req.Header.Set("Host", req.URL.Host)
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, strings.Fields(signedHeaders))))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if nil != err {
+		return err
+	}
+
+	req.Header.Set("Signature", `keyId="`+keyID+`",algorithm="rsa-sha256",headers="`+signedHeaders+
+		`",signature="`+base64.StdEncoding.EncodeToString(signature)+`"`)
+
+	return nil
+}
+
+// GetSignatureKeyID gets the key ID of the HTTP signature of the specified request.
+func GetSignatureKeyID(req *http.Request) string {
+	return parseSignature(req.Header.Get("Signature"))["keyId"]
+}
+
+// VerifyRequest verifies the HTTP signature of the specified request with the specified public key.
+func VerifyRequest(req *http.Request, body []byte, key *rsa.PublicKey) error {
+	params := parseSignature(req.Header.Get("Signature"))
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if nil != err || 1 > len(signature) {
+		return errors.New("invalid signature")
+	}
+
+	headers := strings.Fields(strings.ToLower(params["headers"]))
+	if 1 > len(headers) {
+		headers = []string{"date"}
+	}
+	covered := strings.Join(headers, " ")
+	if !strings.Contains(covered, "(request-target)") || !strings.Contains(covered, "date") {
+		return errors.New("signature must cover (request-target) and date")
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if nil != err {
+		return errors.New("invalid date")
+	}
+	if age := time.Since(date); maxSignatureAge < age || -maxSignatureAge > age {
+		return errors.New("signature expired")
+	}
+	if 0 < len(body) {
+		if !strings.Contains(covered, "digest") {
+			return errors.New("signature must cover digest")
+		}
+		if bodyDigest(body) != req.Header.Get("Digest") {
+			return errors.New("digest mismatch")
+		}
+	}
+
+	if "" == req.Header.Get("Host") {
+		req.Header.Set("Host", req.Host)
+	}
+	hashed := sha256.Sum256([]byte(signingString(req, headers)))
+
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature)
+}
+
+func signingString(req *http.Request, headers []string) string {
+	var lines []string
+	for _, header := range headers {
+		if "(request-target)" == header {
+			lines = append(lines, header+": "+strings.ToLower(req.Method)+" "+req.URL.RequestURI())
+
+			continue
+		}
+
+		lines = append(lines, header+": "+req.Header.Get(header))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func parseSignature(signature string) map[string]string {
+	ret := map[string]string{}
+	for _, param := range strings.Split(signature, ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if 2 != len(kv) {
+			continue
+		}
+
+		ret[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+
+	return ret
+}
+
+func bodyDigest(body []byte) string {
+	digest := sha256.Sum256(body)
+
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,63 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestSignRequest(t *testing.T) {
+	privateKeyPEM, publicKeyPEM, err := GenerateKeyPair()
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	privateKey, err := ParsePrivateKey(privateKeyPEM)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+
+	body := []byte(`{"type":"Follow"}`)
+	req, _ := http.NewRequest(http.MethodPost, "https://pipe.b3log.org/blogs/pipe/activitypub/inbox", bytes.NewReader(body))
+	keyID := "https://pipe.b3log.org/blogs/pipe/activitypub/actor#main-key"
+	if err = SignRequest(req, body, keyID, privateKey); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if keyID != GetSignatureKeyID(req) {
+		t.Errorf("expected is [%s], actual is [%s]", keyID, GetSignatureKeyID(req))
+	}
+	if err = VerifyRequest(req, body, publicKey); nil != err {
+		t.Errorf("verify request failed: " + err.Error())
+	}
+
+	if err = VerifyRequest(req, []byte(`{"type":"Undo"}`), publicKey); nil == err {
+		t.Error("tampered body should not be verified")
+	}
+}
@@ -17,10 +17,18 @@
 package util
 
 import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // registers GIF decoder
+	"image/jpeg"
+	"image/png"
 	"math/rand"
 	"strconv"
-	"time"
 	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
 )
 
 // ImageSize returns image URL of Qiniu image processing style with the specified width and height.
@@ -77,3 +85,89 @@ func contains(str string, slice []string) bool {
 
 	return false
 }
+
+// ReencodeImage validates the specified image data by decoding it then re-encodes it, which strips metadata such as
+// EXIF and anything else embedded in the file. PNG and GIF images are encoded as PNG, others are encoded as JPEG.
+func ReencodeImage(data []byte, maxDimension int) (ret []byte, ext string, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if nil != err {
+		return nil, "", errors.New("unsupported image")
+	}
+	if maxDimension < config.Width || maxDimension < config.Height {
+		return nil, "", errors.New("image dimension exceeds " + strconv.Itoa(maxDimension) + " pixels")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if nil != err {
+		return nil, "", errors.New("corrupted image")
+	}
+
+	buf := &bytes.Buffer{}
+	if "png" == format || "gif" == format {
+		err = png.Encode(buf, img)
+		ext = ".png"
+	} else {
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 85})
+		ext = ".jpg"
+	}
+	if nil != err {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), ext, nil
+}
+
+// ResizeImage scales the specified image data to the specified width. The image is cropped around its center to the
+// specified height if it's positive, otherwise the aspect ratio is kept. PNG and GIF images are encoded as PNG, others
+// are encoded as JPEG.
+func ResizeImage(data []byte, width, height int) (ret []byte, ext string, err error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if nil != err {
+		return nil, "", errors.New("unsupported image")
+	}
+
+	src := coverRect(img.Bounds(), width, height)
+	if 1 > height {
+		height = src.Dy() * width / src.Dx()
+		if 1 > height {
+			height = 1
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+
+	buf := &bytes.Buffer{}
+	if "png" == format || "gif" == format {
+		err = png.Encode(buf, dst)
+		ext = ".png"
+	} else {
+		err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85})
+		ext = ".jpg"
+	}
+	if nil != err {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), ext, nil
+}
+
+// coverRect returns the largest rectangle in the center of the specified bounds which has the aspect ratio of the
+// specified width and height, returns the bounds if the height is not positive.
+func coverRect(bounds image.Rectangle, width, height int) image.Rectangle {
+	if 1 > width || 1 > height {
+		return bounds
+	}
+
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*height > h*width { // wider than the target, crops the left and right
+		cropped := h * width / height
+		x := bounds.Min.X + (w-cropped)/2
+
+		return image.Rect(x, bounds.Min.Y, x+cropped, bounds.Max.Y)
+	}
+
+	cropped := w * height / width
+	y := bounds.Min.Y + (h-cropped)/2
+
+	return image.Rect(bounds.Min.X, y, bounds.Max.X, y+cropped)
+}
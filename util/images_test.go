@@ -17,6 +17,9 @@
 package util
 
 import (
+	"bytes"
+	"image"
+	"image/png"
 	"strings"
 	"testing"
 )
@@ -34,3 +37,43 @@ func TestRandImages(t *testing.T) {
 		t.Errorf("expected is [%d], actual is [%d]", 4, len(urls))
 	}
 }
+
+func TestReencodeImage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 16, 16))); nil != err {
+		t.Error(err)
+
+		return
+	}
+	data := append(buf.Bytes(), []byte("<script>alert(1)</script>")...)
+
+	ret, ext, err := ReencodeImage(data, 1024)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if ".png" != ext || bytes.Contains(ret, []byte("<script>")) {
+		t.Errorf("re-encode image failed")
+	}
+
+	if _, _, err = ReencodeImage(data, 8); nil == err {
+		t.Errorf("image exceeds the max dimension should be rejected")
+	}
+	if _, _, err = ReencodeImage([]byte("GIF89a"), 1024); nil == err {
+		t.Errorf("invalid image should be rejected")
+	}
+}
+
+func TestCoverRect(t *testing.T) {
+	bounds := image.Rect(0, 0, 1920, 1080)
+	if rect := coverRect(bounds, 800, 0); bounds != rect {
+		t.Errorf("expected is [%v], actual is [%v]", bounds, rect)
+	}
+	if rect := coverRect(bounds, 400, 400); image.Rect(420, 0, 1500, 1080) != rect {
+		t.Errorf("expected is [%v], actual is [%v]", image.Rect(420, 0, 1500, 1080), rect)
+	}
+	if rect := coverRect(bounds, 1920, 540); image.Rect(0, 270, 1920, 810) != rect {
+		t.Errorf("expected is [%v], actual is [%v]", image.Rect(0, 270, 1920, 810), rect)
+	}
+}
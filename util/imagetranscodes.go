@@ -0,0 +1,112 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Image formats of the variants transcoded from uploaded images.
+const (
+	ImageFormatWebP = "webp"
+	ImageFormatAVIF = "avif"
+)
+
+// TranscodeImage transcodes the specified JPEG or PNG image data into the specified format (webp/avif) with the
+// specified encoder command, which is cwebp (https://developers.google.com/speed/webp/docs/cwebp) for WebP and avifenc
+// (https://github.com/AOMediaCodec/libavif) for AVIF.
+func TranscodeImage(command, format string, data []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "pipe-transcode")
+	if nil != err {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out."+format)
+	if err = ioutil.WriteFile(in, data, 0600); nil != err {
+		return nil, err
+	}
+
+	var args []string
+	switch format {
+	case ImageFormatWebP:
+		args = []string{"-quiet", "-q", "80", in, "-o", out}
+	case ImageFormatAVIF:
+		args = []string{in, out}
+	default:
+		return nil, errors.New("unsupported image format [" + format + "]")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, command, args...).CombinedOutput(); nil != err {
+		return nil, errors.New("transcode image failed: " + err.Error() + ": " + strings.TrimSpace(string(output)))
+	}
+
+	return ioutil.ReadFile(out)
+}
+
+var imgTagRegexp = regexp.MustCompile(`<img\s[^>]*>`)
+var imgSrcRegexp = regexp.MustCompile(`\ssrc="([^"]+)"`)
+
+// ImageSources returns src of all <img> tags in the specified HTML.
+func ImageSources(content string) (ret []string) {
+	for _, tag := range imgTagRegexp.FindAllString(content, -1) {
+		if match := imgSrcRegexp.FindStringSubmatch(tag); nil != match {
+			ret = append(ret, match[1])
+		}
+	}
+
+	return
+}
+
+// RewritePictures wraps <img> tags in the specified HTML with <picture> offering the variants returned by the
+// specified function, which returns the URLs of the variants of the specified src keyed by image formats (avif/webp).
+// Browsers pick the first format they support and fall back to the original image.
+func RewritePictures(content string, variants func(src string) map[string]string) string {
+	return imgTagRegexp.ReplaceAllStringFunc(content, func(tag string) string {
+		match := imgSrcRegexp.FindStringSubmatch(tag)
+		if nil == match {
+			return tag
+		}
+		urls := variants(match[1])
+		if 1 > len(urls) {
+			return tag
+		}
+
+		builder := &strings.Builder{}
+		builder.WriteString("<picture>")
+		for _, format := range []string{ImageFormatAVIF, ImageFormatWebP} { // smaller first
+			if url := urls[format]; "" != url {
+				builder.WriteString(`<source type="image/` + format + `" srcset="` + html.EscapeString(url) + `">`)
+			}
+		}
+		builder.WriteString(tag)
+		builder.WriteString("</picture>")
+
+		return builder.String()
+	})
+}
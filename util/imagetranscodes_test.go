@@ -0,0 +1,40 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import "testing"
+
+func TestRewritePictures(t *testing.T) {
+	html := `<p><img src="https://pipe.b3log.org/media/a.png" alt="a"/><img alt="b" src="https://pipe.b3log.org/media/b.png"></p>`
+	if srcs := ImageSources(html); 2 != len(srcs) || "https://pipe.b3log.org/media/b.png" != srcs[1] {
+		t.Errorf("unexpected sources %v", srcs)
+	}
+
+	ret := RewritePictures(html, func(src string) map[string]string {
+		if "https://pipe.b3log.org/media/a.png" != src {
+			return nil
+		}
+
+		return map[string]string{ImageFormatWebP: "https://pipe.b3log.org/media/a.webp", ImageFormatAVIF: "https://pipe.b3log.org/media/a.avif"}
+	})
+	expected := `<p><picture><source type="image/avif" srcset="https://pipe.b3log.org/media/a.avif">` +
+		`<source type="image/webp" srcset="https://pipe.b3log.org/media/a.webp">` +
+		`<img src="https://pipe.b3log.org/media/a.png" alt="a"/></picture><img alt="b" src="https://pipe.b3log.org/media/b.png"></p>`
+	if expected != ret {
+		t.Errorf("expected is [%s], actual is [%s]", expected, ret)
+	}
+}
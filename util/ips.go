@@ -0,0 +1,51 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+// IP anonymization modes.
+const (
+	IPAnonymizationTruncate = "truncate" // zeroes the host part, the last octet of IPv4 or the last 80 bits of IPv6
+	IPAnonymizationHash     = "hash"     // replaces with a salted hash
+)
+
+// AnonymizeIP anonymizes the specified IP with the specified mode, values which are not IPs (including anonymized
+// ones) are returned as is.
+func AnonymizeIP(ip, mode, salt string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if nil == parsed {
+		return ip
+	}
+
+	if IPAnonymizationHash == mode {
+		hash := sha256.Sum256([]byte(salt + parsed.String()))
+
+		return "#" + hex.EncodeToString(hash[:8])
+	}
+
+	if ipv4 := parsed.To4(); nil != ipv4 {
+		return ipv4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,36 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import "testing"
+
+func TestAnonymizeIP(t *testing.T) {
+	if ip := AnonymizeIP("192.168.1.100", IPAnonymizationTruncate, ""); "192.168.1.0" != ip {
+		t.Errorf("expected is [%s], actual is [%s]", "192.168.1.0", ip)
+	}
+	if ip := AnonymizeIP("2001:db8:85a3::8a2e:370:7334", IPAnonymizationTruncate, ""); "2001:db8:85a3::" != ip {
+		t.Errorf("expected is [%s], actual is [%s]", "2001:db8:85a3::", ip)
+	}
+
+	hashed := AnonymizeIP("192.168.1.100", IPAnonymizationHash, "salt")
+	if "#" != hashed[:1] || 17 != len(hashed) {
+		t.Errorf("unexpected hashed IP [%s]", hashed)
+	}
+	if hashed != AnonymizeIP(hashed, IPAnonymizationHash, "salt") {
+		t.Errorf("anonymized IP should not be anonymized again")
+	}
+}
@@ -0,0 +1,85 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var linkArchiveClient = &http.Client{Timeout: time.Minute}
+
+var linkRegexp = regexp.MustCompile(`https?://[^\s<>"'\x60\[\]{}]+`)
+
+// ExtractLinks extracts distinct http(s) links from the specified text (Markdown or HTML).
+func ExtractLinks(text string) (ret []string) {
+	links := map[string]bool{}
+	for _, link := range linkRegexp.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?*")
+		for strings.HasSuffix(link, ")") && strings.Count(link, "(") < strings.Count(link, ")") {
+			link = strings.TrimSuffix(link, ")") // Markdown link [text](url)
+		}
+		if links[link] {
+			continue
+		}
+		if u, err := url.Parse(link); nil != err || "" == u.Host {
+			continue
+		}
+
+		links[link] = true
+		ret = append(ret, link)
+	}
+
+	return
+}
+
+// ArchiveLink submits the specified link to the specified archiver and returns the URL of the archived snapshot. The
+// archiver is requested with GET archiver+link, the snapshot URL is resolved from the Content-Location header or the
+// final URL after redirects, which is how the Wayback Machine (https://web.archive.org/save/) responds.
+func ArchiveLink(archiver, link string) (string, error) {
+	saveURL := archiver + link
+	resp, err := linkArchiveClient.Get(saveURL)
+	if nil != err {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1024*1024))
+
+	if http.StatusOK > resp.StatusCode || http.StatusMultipleChoices <= resp.StatusCode {
+		return "", errors.New("archiver responded status code [" + strconv.Itoa(resp.StatusCode) + "]")
+	}
+	if location := resp.Header.Get("Content-Location"); "" != location {
+		ref, err := url.Parse(location)
+		if nil != err {
+			return "", err
+		}
+
+		return resp.Request.URL.ResolveReference(ref).String(), nil
+	}
+	if snapshotURL := resp.Request.URL.String(); saveURL != snapshotURL {
+		return snapshotURL, nil
+	}
+
+	return "", errors.New("archiver responded without snapshot URL")
+}
@@ -0,0 +1,62 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractLinks(t *testing.T) {
+	links := ExtractLinks("See [Pipe](https://github.com/b3log/pipe), https://example.com/a_(b). " +
+		"<a href=\"http://example.com/x?y=1\">x</a> https://github.com/b3log/pipe ftp://example.com")
+	expected := []string{"https://github.com/b3log/pipe", "https://example.com/a_(b)", "http://example.com/x?y=1"}
+	if strings.Join(expected, " ") != strings.Join(links, " ") {
+		t.Errorf("expected is %v, actual is %v", expected, links)
+	}
+}
+
+func TestArchiveLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/save/https://located.com"):
+			w.Header().Set("Content-Location", "/web/20200101000000/https://located.com")
+		case strings.HasPrefix(r.URL.Path, "/save/https://redirected.com"):
+			w.Header().Set("Location", "/web/20200102000000/https://redirected.com")
+			w.WriteHeader(http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/web/"):
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	archiver := server.URL + "/save/"
+	archived, err := ArchiveLink(archiver, "https://located.com")
+	if nil != err || server.URL+"/web/20200101000000/https://located.com" != archived {
+		t.Errorf("unexpected archived URL [%s], error [%v]", archived, err)
+	}
+	archived, err = ArchiveLink(archiver, "https://redirected.com")
+	if nil != err || server.URL+"/web/20200102000000/https://redirected.com" != archived {
+		t.Errorf("unexpected archived URL [%s], error [%v]", archived, err)
+	}
+	if _, err = ArchiveLink(archiver, "https://limited.com"); nil == err {
+		t.Errorf("archiving should fail")
+	}
+}
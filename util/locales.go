@@ -0,0 +1,106 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/b3log/pipe/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLocale is used if neither the user preference nor the Accept-Language header matches an available locale.
+const DefaultLocale = "en_US"
+
+// NegotiateLocale picks the best of the specified available locales (e.g. ["en_US", "zh_CN"]) for the specified
+// Accept-Language header (e.g. "zh-TW,zh;q=0.9,en;q=0.8"), returns "" if none of them is acceptable.
+func NegotiateLocale(acceptLanguage string, locales []string) string {
+	type langRange struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []*langRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if "" == tag || "*" == tag {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); nil == err {
+					q = v
+				}
+			}
+		}
+		if 0 >= q {
+			continue
+		}
+		ranges = append(ranges, &langRange{tag: strings.Replace(tag, "_", "-", -1), q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		for _, locale := range locales {
+			if r.tag == strings.ToLower(strings.Replace(locale, "_", "-", -1)) {
+				return locale
+			}
+		}
+		lang := strings.Split(r.tag, "-")[0]
+		for _, locale := range locales {
+			if lang == strings.ToLower(strings.Split(locale, "_")[0]) {
+				return locale
+			}
+		}
+	}
+
+	return ""
+}
+
+// IsLocale checks whether the specified locale is available.
+func IsLocale(locale string) bool {
+	for _, name := range i18n.GetLocalesNames() {
+		if name == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequestLocale returns the locale of the specified request, the locale preferred by the logged in user comes first,
+// then the one negotiated with the Accept-Language header.
+func RequestLocale(c *gin.Context) string {
+	if nil == c {
+		return DefaultLocale
+	}
+	if s, ok := c.Get("session"); ok {
+		if session := s.(*SessionData); IsLocale(session.ULocale) {
+			return session.ULocale
+		}
+	}
+	if ret := NegotiateLocale(c.GetHeader("Accept-Language"), i18n.GetLocalesNames()); "" != ret {
+		return ret
+	}
+
+	return DefaultLocale
+}
@@ -0,0 +1,40 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import "testing"
+
+func TestNegotiateLocale(t *testing.T) {
+	locales := []string{"en_US", "zh_CN"}
+	cases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh_CN"},
+		{"zh-TW,zh;q=0.9", "zh_CN"},
+		{"en-GB;q=0.5,zh;q=0.8", "zh_CN"},
+		{"fr-FR,en;q=0.5", "en_US"},
+		{"fr-FR,de", ""},
+		{"zh;q=0,en", "en_US"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if actual := NegotiateLocale(c.acceptLanguage, locales); c.expected != actual {
+			t.Errorf("expected [%s] for [%s], got [%s]", c.expected, c.acceptLanguage, actual)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestNewMail(t *testing.T) {
+	body := strings.Repeat("<p>Pipe 小而美的博客平台</p>", 10)
+	mail := string(newMail("pipe@example.com", []string{"a@example.com", "b@example.com"}, "邀请", body))
+
+	if !strings.Contains(mail, "To: a@example.com, b@example.com\r\n") {
+		t.Errorf("recipients are not in the mail [%s]", mail)
+	}
+	if !strings.Contains(mail, "Subject: =?UTF-8?b?6YKA6K+3?=\r\n") {
+		t.Errorf("subject is not encoded [%s]", mail)
+	}
+
+	encoded := strings.Replace(mail[strings.Index(mail, "\r\n\r\n")+4:], "\r\n", "", -1)
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if body != string(decoded) {
+		t.Errorf("expected is [%s], actual is [%s]", body, decoded)
+	}
+}
@@ -19,6 +19,7 @@ package util
 import (
 	"crypto/md5"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -36,21 +37,88 @@ type MarkdownResult struct {
 	ContentHTML  string
 	AbstractText string
 	ThumbURL     string
+	TOC          []*TOCItem // headings of the content
+}
+
+// TOCItem represents a heading in the table of contents, headings of lower levels following it are its children.
+type TOCItem struct {
+	ID       string // anchor ID of the heading
+	Title    string
+	Level    int // 1 to 5 for h1 to h5
+	Children []*TOCItem
+}
+
+// MarkdownOptions represents options of markdown rendering, which are configured per blog.
+type MarkdownOptions struct {
+	Math            bool     // renders $...$ and $$...$$ as math markup typeset by the client
+	PlantUMLServer  string   // PlantUML server rendering plantuml code blocks to SVG, empty to keep them as code
+	Footnotes       bool     // renders [^label] references and [^label]: definitions as footnotes
+	DefinitionLists bool     // renders terms followed by ": definition" lines as definition lists
+	TaskLists       bool     // renders list items starting with [ ] or [x] as checkboxes
+	Tables          bool     // renders GFM tables, column alignment included
+	Embeds          []string // providers whose bare links are expanded to embeds, such as youtube
+	Shortcodes      bool     // expands shortcodes like {{< button url=... >}}
+	Theme           string   // theme whose shortcodes override the built-in ones
+}
+
+// key returns the part of markdown cache keys for the options.
+func (opts *MarkdownOptions) key() string {
+	ret := ""
+	for i, on := range []bool{opts.Math, opts.Footnotes, opts.DefinitionLists, opts.TaskLists, opts.Tables, opts.Shortcodes} {
+		if on {
+			ret += strconv.Itoa(i)
+		}
+	}
+	ret += "|" + opts.PlantUMLServer + "|" + strings.Join(opts.Embeds, ",") + "|" + opts.Theme
+
+	return ret
+}
+
+// PurgeMarkdownCache purges the rendered markdown cache, for example after shortcodes changed.
+func PurgeMarkdownCache() {
+	markdownCache.Purge()
 }
 
 // Markdown process the specified markdown text to HTML.
 func Markdown(mdText string) *MarkdownResult {
+	return MarkdownWithOptions(mdText, nil)
+}
+
+// MarkdownWithOptions process the specified markdown text to HTML with the specified options, nil options turn all
+// options but tables off.
+func MarkdownWithOptions(mdText string, opts *MarkdownOptions) *MarkdownResult {
 	mdText = strings.Replace(mdText, "\r\n", "\n", -1)
+	if nil == opts {
+		opts = &MarkdownOptions{Tables: true}
+	}
 
 	digest := md5.New()
 	digest.Write([]byte(mdText))
-	key := string(digest.Sum(nil))
+	key := string(digest.Sum(nil)) + opts.key()
 
 	cached, err := markdownCache.Get(key)
 	if nil == err {
 		return cached.(*MarkdownResult)
 	}
 
+	var shortcodeOutputs []string
+	if opts.Shortcodes {
+		mdText, shortcodeOutputs = protectShortcodes(mdText, opts)
+	}
+	var maths []*mathExpr
+	if opts.Math {
+		mdText, maths = protectMath(mdText)
+	}
+	var notes []*footnote
+	if opts.Footnotes {
+		mdText, notes = protectFootnotes(mdText)
+	}
+	var definitionLists [][]*definitionItem
+	if opts.DefinitionLists {
+		mdText, definitionLists = protectDefinitionLists(mdText)
+	}
+	mdText = escapeDisabledSyntax(mdText, opts)
+
 	luteEngine := lute.New()
 	unsafe, err := luteEngine.MarkdownStr("", mdText)
 	if nil != err {
@@ -60,19 +128,28 @@ func Markdown(mdText string) *MarkdownResult {
 			ThumbURL:     "",
 		}
 	}
-	contentHTML := unsafe
+	contentHTML := restoreShortcodes(unsafe, shortcodeOutputs)
+	contentHTML = restoreDefinitionLists(contentHTML, definitionLists)
+	contentHTML = restoreFootnotes(contentHTML, notes)
+	contentHTML = restoreMath(contentHTML, maths)
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	renderDiagrams(doc, opts.PlantUMLServer)
+	renderEmbeds(doc, opts.Embeds)
+	if opts.TaskLists {
+		renderTaskLists(doc)
+	}
 	doc.Find("img").Each(func(i int, ele *goquery.Selection) {
 		src, _ := ele.Attr("src")
 		ele.SetAttr("data-src", src)
 		ele.RemoveAttr("src")
 	})
+	toc := buildTOC(doc)
 
 	contentHTML, _ = doc.Find("body").Html()
 	contentHTML = bluemonday.UGCPolicy().AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$")).OnElements("code").
 		AllowAttrs("data-src").OnElements("img").
 		AllowAttrs("class", "target", "id", "style", "align").Globally().
-		AllowAttrs("src", "width", "height", "border", "marginwidth", "marginheight").OnElements("iframe").
+		AllowAttrs("src", "width", "height", "border", "marginwidth", "marginheight", "frameborder", "scrolling", "allowfullscreen").OnElements("iframe").
 		AllowAttrs("controls", "src").OnElements("audio").
 		AllowAttrs("color").OnElements("font").
 		AllowAttrs("controls", "src", "width", "height").OnElements("video").
@@ -80,6 +157,8 @@ func Markdown(mdText string) *MarkdownResult {
 		AllowAttrs("width", "height", "data", "type").OnElements("object").
 		AllowAttrs("name", "value").OnElements("param").
 		AllowAttrs("src", "type", "width", "height", "wmode", "allowNetworking").OnElements("embed").
+		AllowAttrs("type").Matching(regexp.MustCompile("^checkbox$")).OnElements("input").
+		AllowAttrs("checked", "disabled").OnElements("input").
 		Sanitize(contentHTML)
 
 	text := doc.Text()
@@ -112,12 +191,144 @@ func Markdown(mdText string) *MarkdownResult {
 		ContentHTML:  contentHTML,
 		AbstractText: abstractText,
 		ThumbURL:     thumbnailURL,
+		TOC:          toc,
 	}
 	markdownCache.Set(key, ret)
 
 	return ret
 }
 
+// buildTOC injects anchor IDs into headings h1 to h5 of the specified document and nests them into a table of contents.
+func buildTOC(doc *goquery.Document) (ret []*TOCItem) {
+	var parents []*TOCItem // the last item of each level on the path to the current heading
+	doc.Find("h1, h2, h3, h4, h5").Each(func(i int, element *goquery.Selection) {
+		tagName := goquery.NodeName(element)
+		id := "toc_" + tagName + "_" + strconv.Itoa(i)
+		element.SetAttr("id", id)
+		level, _ := strconv.Atoi(tagName[1:])
+		item := &TOCItem{ID: id, Title: strings.TrimSpace(element.Text()), Level: level}
+
+		for 0 < len(parents) && parents[len(parents)-1].Level >= level {
+			parents = parents[:len(parents)-1]
+		}
+		if 0 == len(parents) {
+			ret = append(ret, item)
+		} else {
+			parent := parents[len(parents)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		parents = append(parents, item)
+	})
+
+	return
+}
+
+// taskListItemRegexp matches list items starting with a task checkbox [ ] or [x].
+var taskListItemRegexp = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)\[([ xX])\]`)
+
+// tableDelimiterRowRegexp matches delimiter rows of GFM tables like | --- | :---: |.
+var tableDelimiterRowRegexp = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// escapeDisabledSyntax escapes task checkboxes and table delimiter rows of the specified markdown text if task lists
+// or tables are disabled by the specified options, so that they are rendered as text.
+func escapeDisabledSyntax(mdText string, opts *MarkdownOptions) string {
+	if opts.TaskLists && opts.Tables {
+		return mdText
+	}
+
+	lines := strings.Split(mdText, "\n")
+	inCode := fencedCodeLines(lines)
+	for i, line := range lines {
+		if inCode[i] {
+			continue
+		}
+
+		if !opts.TaskLists {
+			line = taskListItemRegexp.ReplaceAllString(line, `${1}\[${2}]`)
+		}
+		if !opts.Tables && strings.Contains(line, "|") && tableDelimiterRowRegexp.MatchString(line) {
+			line = strings.Replace(line, "|", `\|`, -1)
+		}
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderTaskLists replaces leading [ ] and [x] of list items of the specified document with disabled checkboxes.
+func renderTaskLists(doc *goquery.Document) {
+	doc.Find("li").Each(func(i int, li *goquery.Selection) {
+		text := li.Contents().First()
+		if "p" == goquery.NodeName(text) {
+			text = text.Contents().First()
+		}
+		if "#text" != goquery.NodeName(text) {
+			return
+		}
+
+		node := text.Nodes[0]
+		checkbox := ""
+		switch {
+		case strings.HasPrefix(node.Data, "[ ] "):
+			checkbox = `<input type="checkbox" disabled>`
+		case strings.HasPrefix(node.Data, "[x] "), strings.HasPrefix(node.Data, "[X] "):
+			checkbox = `<input type="checkbox" checked disabled>`
+		default:
+			return
+		}
+		node.Data = node.Data[3:]
+		text.BeforeHtml(checkbox)
+	})
+}
+
+// fencedCodeLines returns whether each of the specified markdown lines belongs to a fenced code block.
+func fencedCodeLines(lines []string) []bool {
+	ret := make([]bool, len(lines))
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if "" != fence {
+			ret[i] = true
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			ret[i] = true
+		}
+	}
+
+	return ret
+}
+
+// replaceOutsideCodeSpans replaces matches of the specified regexp in the specified line except those in code spans.
+func replaceOutsideCodeSpans(line string, re *regexp.Regexp, repl func(string) string) string {
+	segments := strings.Split(line, "`")
+	for i := 0; i < len(segments); i += 2 {
+		segments[i] = re.ReplaceAllStringFunc(segments[i], repl)
+	}
+
+	return strings.Join(segments, "`")
+}
+
+// renderMarkdownFragment renders the specified markdown fragment, such as a footnote, to HTML.
+func renderMarkdownFragment(mdText string) string {
+	ret, err := lute.New().MarkdownStr("", mdText)
+	if nil != err {
+		return mdText
+	}
+
+	return strings.TrimSpace(ret)
+}
+
+// renderMarkdownInline renders the specified single line markdown text, such as a term, to HTML without paragraphs.
+func renderMarkdownInline(mdText string) string {
+	return trimParagraph(renderMarkdownFragment(mdText))
+}
+
 func runesToString(runes []rune) (ret string) {
 	for _, v := range runes {
 		ret += string(v)
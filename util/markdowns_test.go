@@ -141,3 +141,71 @@ https://github.com/88250 ，欢迎关注。`
 		t.Fatalf("markdown abstract failed: " + abstract)
 	}
 }
+
+func TestMarkdownTOC(t *testing.T) {
+	result := Markdown("# Pipe\n\n## Install\n\n### Docker\n\n## Usage\n")
+	toc := result.TOC
+	if 1 != len(toc) || 2 != len(toc[0].Children) || 1 != len(toc[0].Children[0].Children) {
+		t.Errorf("unexpected table of contents [%+v]", toc)
+
+		return
+	}
+	docker := toc[0].Children[0].Children[0]
+	if "Docker" != docker.Title || 3 != docker.Level {
+		t.Errorf("unexpected heading [%+v]", docker)
+	}
+	if !strings.Contains(result.ContentHTML, `id="`+docker.ID+`"`) {
+		t.Error("anchor ID should be injected into the heading")
+	}
+}
+
+func TestMarkdownMath(t *testing.T) {
+	mdText := "Euler $e^{i\\pi} + 1 = 0$ costs $5 and $10.\n\n$$\na_1 * b_1\n$$\n\n`$x$`\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{Math: true}).ContentHTML
+	if !strings.Contains(html, `<span class="language-math">e^{i\pi} + 1 = 0</span>`) {
+		t.Errorf("inline math should be protected [%s]", html)
+	}
+	if !strings.Contains(html, `<div class="language-math">a_1 * b_1</div>`) {
+		t.Errorf("display math should be protected [%s]", html)
+	}
+	if !strings.Contains(html, "costs $5 and $10.") || !strings.Contains(html, "<code>$x$</code>") {
+		t.Errorf("prices and code spans should be kept [%s]", html)
+	}
+
+	html = Markdown(mdText).ContentHTML
+	if strings.Contains(html, "language-math") {
+		t.Errorf("math should be off by default [%s]", html)
+	}
+}
+
+func TestMarkdownFootnotes(t *testing.T) {
+	mdText := "Pipe[^note] is small.\n\n[^note]: A blogging platform.\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{Footnotes: true}).ContentHTML
+	if !strings.Contains(html, `<sup class="footnotes-ref" id="fnref-1"><a href="#fn-1">1</a></sup>`) {
+		t.Errorf("reference should link to the footnote [%s]", html)
+	}
+	if !strings.Contains(html, `<li id="fn-1">`) || !strings.Contains(html, `href="#fnref-1" class="footnotes-backref"`) {
+		t.Errorf("footnote should link back to the reference [%s]", html)
+	}
+}
+
+func TestMarkdownDefinitionLists(t *testing.T) {
+	mdText := "Pipe\n: A blogging platform.\n: Written in Go.\n\nSolo\n: Another one.\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{DefinitionLists: true}).ContentHTML
+	if !strings.Contains(html, "<dl><dt>Pipe</dt><dd>A blogging platform.</dd><dd>Written in Go.</dd><dt>Solo</dt><dd>Another one.</dd></dl>") {
+		t.Errorf("unexpected definition list [%s]", html)
+	}
+}
+
+func TestMarkdownTaskListsAndTables(t *testing.T) {
+	mdText := "- [x] done\n- [ ] todo\n\n| a | b |\n| --- | :-: |\n| 1 | 2 |\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{TaskLists: true, Tables: true}).ContentHTML
+	if 2 != strings.Count(html, `type="checkbox"`) || !strings.Contains(html, "<table") {
+		t.Errorf("task lists and tables should be rendered [%s]", html)
+	}
+
+	html = MarkdownWithOptions(mdText, &MarkdownOptions{}).ContentHTML
+	if strings.Contains(html, "checkbox") || strings.Contains(html, "<table") {
+		t.Errorf("task lists and tables should be kept as text [%s]", html)
+	}
+}
@@ -0,0 +1,176 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mathPlaceholderPrefix prefixes placeholders of math expressions, which pass through markdown rendering untouched.
+const mathPlaceholderPrefix = "pipemath"
+
+// mathPlaceholderRegexp matches math placeholders in rendered HTML, block placeholders are wrapped in paragraphs.
+var mathPlaceholderRegexp = regexp.MustCompile(`(<p>)?` + mathPlaceholderPrefix + `(\d+)x(</p>)?`)
+
+// mathExpr represents a math expression extracted from markdown text.
+type mathExpr struct {
+	tex     string
+	display bool // $$...$$ block
+}
+
+// protectMath replaces math expressions $...$ and $$...$$ in the specified markdown text with placeholders so that
+// markdown rendering does not take TeX syntax like _ and * as emphasis. Code blocks and code spans are left as is.
+// Dollars followed by a space or a digit close no expression, so prices like $5 and $10 stay text.
+func protectMath(mdText string) (string, []*mathExpr) {
+	var exprs []*mathExpr
+	placeholder := func(expr *mathExpr) string {
+		exprs = append(exprs, expr)
+
+		return mathPlaceholderPrefix + strconv.Itoa(len(exprs)-1) + "x"
+	}
+
+	lines := strings.Split(mdText, "\n")
+	var ret []string
+	fence := ""
+	var block []string
+	inBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inBlock {
+			if strings.HasSuffix(trimmed, "$$") {
+				block = append(block, strings.TrimSuffix(trimmed, "$$"))
+				ret = append(ret, "", placeholder(&mathExpr{tex: strings.TrimSpace(strings.Join(block, "\n")), display: true}), "")
+				inBlock = false
+				block = nil
+
+				continue
+			}
+			block = append(block, line)
+
+			continue
+		}
+		if "" != fence {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			ret = append(ret, line)
+
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			ret = append(ret, line)
+
+			continue
+		}
+		if strings.HasPrefix(trimmed, "$$") {
+			rest := strings.TrimPrefix(trimmed, "$$")
+			if 2 <= len(rest) && strings.HasSuffix(rest, "$$") {
+				ret = append(ret, "", placeholder(&mathExpr{tex: strings.TrimSpace(strings.TrimSuffix(rest, "$$")), display: true}), "")
+
+				continue
+			}
+			inBlock = true
+			block = []string{rest}
+
+			continue
+		}
+
+		ret = append(ret, protectInlineMath(line, placeholder))
+	}
+	if inBlock { // unclosed block, keeps the text
+		ret = append(ret, "$$"+strings.Join(block, "\n"))
+	}
+
+	return strings.Join(ret, "\n"), exprs
+}
+
+// protectInlineMath replaces inline math expressions $...$ of the specified line with placeholders.
+func protectInlineMath(line string, placeholder func(expr *mathExpr) string) string {
+	if !strings.Contains(line, "$") {
+		return line
+	}
+
+	buf := &strings.Builder{}
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case '\\' == r && i+1 < len(runes):
+			buf.WriteRune(r)
+			buf.WriteRune(runes[i+1])
+			i++
+		case '`' == r: // code span
+			end := i + 1
+			for end < len(runes) && '`' != runes[end] {
+				end++
+			}
+			if end == len(runes) {
+				buf.WriteRune(r)
+
+				continue
+			}
+			buf.WriteString(string(runes[i : end+1]))
+			i = end
+		case '$' == r && i+1 < len(runes) && ' ' != runes[i+1] && '$' != runes[i+1]:
+			end := i + 1
+			for end < len(runes) && ('$' != runes[end] || '\\' == runes[end-1]) {
+				end++
+			}
+			if end == len(runes) || ' ' == runes[end-1] || (end+1 < len(runes) && isDigit(runes[end+1])) {
+				buf.WriteRune(r)
+
+				continue
+			}
+			buf.WriteString(placeholder(&mathExpr{tex: string(runes[i+1 : end])}))
+			i = end
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	return buf.String()
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && '9' >= r
+}
+
+// restoreMath replaces placeholders in the specified rendered HTML with math markup, which is typeset by the client.
+func restoreMath(contentHTML string, exprs []*mathExpr) string {
+	if 1 > len(exprs) {
+		return contentHTML
+	}
+
+	return mathPlaceholderRegexp.ReplaceAllStringFunc(contentHTML, func(placeholder string) string {
+		groups := mathPlaceholderRegexp.FindStringSubmatch(placeholder)
+		index, _ := strconv.Atoi(groups[2])
+		if index >= len(exprs) {
+			return placeholder
+		}
+
+		expr := exprs[index]
+		if expr.display {
+			return `<div class="language-math">` + html.EscapeString(expr.tex) + `</div>`
+		}
+
+		return groups[1] + `<span class="language-math">` + html.EscapeString(expr.tex) + `</span>` + groups[3]
+	})
+}
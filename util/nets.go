@@ -34,24 +34,59 @@ func IsIP(s string) bool {
 	return nil != net.ParseIP(s)
 }
 
-// GetRemoteAddr returns remote address of the context.
+// GetRemoteAddr returns IP of the client of the context. Forwarded headers are only respected if the request comes from
+// a trusted reverse proxy, X-Forwarded-For is walked from right to left skipping trusted proxies, which prevents
+// clients from spoofing their IPs.
 func GetRemoteAddr(c *gin.Context) string {
-	ret := c.GetHeader("X-forwarded-for")
-	ret = strings.TrimSpace(ret)
-	if "" == ret {
-		ret = c.GetHeader("X-Real-IP")
+	ret := peerIP(c.Request)
+	if !isTrustedProxy(ret) {
+		return ret
 	}
-	ret = strings.TrimSpace(ret)
-	if "" == ret {
-		return c.Request.RemoteAddr
+
+	if forwardedFor := strings.TrimSpace(c.GetHeader("X-Forwarded-For")); "" != forwardedFor {
+		ips := strings.Split(forwardedFor, ",")
+		for i := len(ips) - 1; 0 <= i; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if !IsIP(ip) {
+				break
+			}
+			ret = ip
+			if !isTrustedProxy(ip) {
+				break
+			}
+		}
+
+		return ret
+	}
+	if realIP := strings.TrimSpace(c.GetHeader("X-Real-IP")); IsIP(realIP) {
+		return realIP
 	}
 
-	return strings.Split(ret, ",")[0]
+	return ret
 }
 
+// botKeywords are lowercase keywords of user-agents of crawlers, feed readers, HTTP libraries and fediverse servers
+// which are not detected as bots by the user-agent parser.
+var botKeywords = []string{"bot", "spider", "crawl", "slurp", "curl", "wget", "python", "go-http-client", "java/",
+	"okhttp", "headless", "feed", "rss", "mastodon", "pleroma", "http.rb"}
+
 // IsBot checks the specified user-agent is a bot.
 func IsBot(uaStr string) bool {
+	if "" == strings.TrimSpace(uaStr) {
+		return true
+	}
+
 	var ua = user_agent.New(uaStr)
+	if ua.Bot() || strings.HasPrefix(uaStr, "Sym") {
+		return true
+	}
+
+	lowerUA := strings.ToLower(uaStr)
+	for _, keyword := range botKeywords {
+		if strings.Contains(lowerUA, keyword) {
+			return true
+		}
+	}
 
-	return ua.Bot() || strings.HasPrefix(uaStr, "Sym")
+	return false
 }
\ No newline at end of file
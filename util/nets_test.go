@@ -68,4 +68,10 @@ func TestIsBot(t *testing.T) {
 
 		return
 	}
+	if !IsBot("Feedly/1.0 (+http://www.feedly.com/fetcher.html; 5 subscribers)") {
+		t.Errorf("[Feedly] is not a bot")
+	}
+	if IsBot("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.102 Safari/537.36") {
+		t.Errorf("[Chrome] is a bot")
+	}
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuthEndpoints represents the endpoints of an OAuth2 provider.
+type OAuthEndpoints struct {
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+}
+
+var oauthClient = &http.Client{Timeout: 10 * time.Second}
+
+// DiscoverOIDC discovers the endpoints of the OpenID Connect provider specified by the issuer URL.
+func DiscoverOIDC(issuer string) (*OAuthEndpoints, error) {
+	ret := &OAuthEndpoints{}
+	if err := getOAuthJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", "", ret); nil != err {
+		return nil, err
+	}
+	if "" == ret.AuthURL || "" == ret.TokenURL || "" == ret.UserInfoURL {
+		return nil, errors.New("incomplete OpenID configuration of issuer [" + issuer + "]")
+	}
+
+	return ret, nil
+}
+
+// OAuthAuthCodeURL returns the URL to redirect users to for authorization.
+func OAuthAuthCodeURL(endpoints *OAuthEndpoints, clientID, redirectURL, scopes, state string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("scope", scopes)
+	params.Set("state", state)
+
+	sep := "?"
+	if strings.Contains(endpoints.AuthURL, "?") {
+		sep = "&"
+	}
+
+	return endpoints.AuthURL + sep + params.Encode()
+}
+
+// OAuthExchange exchanges the authorization code for an access token.
+func OAuthExchange(endpoints *OAuthEndpoints, clientID, clientSecret, redirectURL, code string) (string, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("client_id", clientID)
+	params.Set("client_secret", clientSecret)
+	req, err := http.NewRequest(http.MethodPost, endpoints.TokenURL, strings.NewReader(params.Encode()))
+	if nil != err {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token := struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := doOAuthJSON(req, &token); nil != err {
+		return "", err
+	}
+	if "" != token.Error {
+		return "", errors.New("exchange token failed: " + token.Error + " " + token.ErrorDescription)
+	}
+	if "" == token.AccessToken {
+		return "", errors.New("exchange token failed: empty access token")
+	}
+
+	return token.AccessToken, nil
+}
+
+// OAuthUserInfo gets the claims of the user authorized the specified access token.
+func OAuthUserInfo(endpoints *OAuthEndpoints, accessToken string) (map[string]interface{}, error) {
+	ret := map[string]interface{}{}
+	if err := getOAuthJSON(endpoints.UserInfoURL, accessToken, &ret); nil != err {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func getOAuthJSON(url, accessToken string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if nil != err {
+		return err
+	}
+	if "" != accessToken {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return doOAuthJSON(req, v)
+}
+
+func doOAuthJSON(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Pipe; +https://github.com/b3log/pipe")
+	res, err := oauthClient.Do(req)
+	if nil != err {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if nil != err {
+		return err
+	}
+	if http.StatusOK != res.StatusCode {
+		return errors.New("request [" + req.URL.String() + "] failed: " + res.Status)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
@@ -0,0 +1,79 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOAuth(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			w.Write([]byte(`{"authorization_endpoint":"` + server.URL + `/authorize","token_endpoint":"` + server.URL +
+				`/token","userinfo_endpoint":"` + server.URL + `/userinfo"}`))
+		case "/token":
+			if "code" != r.PostFormValue("code") || "secret" != r.PostFormValue("client_secret") {
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+
+				return
+			}
+			w.Write([]byte(`{"access_token":"token","token_type":"Bearer"}`))
+		case "/userinfo":
+			if "Bearer token" != r.Header.Get("Authorization") {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+			w.Write([]byte(`{"sub":"12345678901234567890","preferred_username":"pipe"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	endpoints, err := DiscoverOIDC(server.URL + "/")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	authURL := OAuthAuthCodeURL(endpoints, "client", "http://localhost/callback", "openid profile", "state")
+	if !strings.HasPrefix(authURL, server.URL+"/authorize?") || !strings.Contains(authURL, "state=state") {
+		t.Errorf("unexpected auth URL [%s]", authURL)
+	}
+
+	if _, err := OAuthExchange(endpoints, "client", "wrong", "http://localhost/callback", "code"); nil == err {
+		t.Errorf("exchange with wrong secret should fail")
+	}
+	token, err := OAuthExchange(endpoints, "client", "secret", "http://localhost/callback", "code")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	claims, err := OAuthUserInfo(endpoints, token)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "pipe" != claims["preferred_username"] {
+		t.Errorf("unexpected claims %v", claims)
+	}
+}
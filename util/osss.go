@@ -0,0 +1,98 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OSSPutObject puts an object with the specified key into an Aliyun OSS bucket. The specified endpoint is in format of
+// "https://oss-cn-hangzhou.aliyuncs.com", requests are signed with the OSS header signature (V1).
+func OSSPutObject(endpoint, bucket, accessKey, secretKey, key string, data []byte) error {
+	return ossRequest(http.MethodPut, endpoint, bucket, accessKey, secretKey, key, data)
+}
+
+// OSSDeleteObject deletes the object with the specified key from an Aliyun OSS bucket.
+func OSSDeleteObject(endpoint, bucket, accessKey, secretKey, key string) error {
+	return ossRequest(http.MethodDelete, endpoint, bucket, accessKey, secretKey, key, nil)
+}
+
+func ossRequest(method, endpoint, bucket, accessKey, secretKey, key string, data []byte) error {
+	u, err := url.Parse(endpoint)
+	if nil != err {
+		return err
+	}
+	if "" == u.Host {
+		return errors.New("invalid OSS endpoint [" + endpoint + "]")
+	}
+	scheme := "https"
+	if "http" == u.Scheme {
+		scheme = "http"
+	}
+
+	path := "/" + (&url.URL{Path: key}).EscapedPath()
+	contentMD5, contentType := "", ""
+	if 0 < len(data) {
+		md5Sum := md5.Sum(data)
+		contentMD5 = base64.StdEncoding.EncodeToString(md5Sum[:])
+		contentType = http.DetectContentType(data)
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	stringToSign := method + "\n" + contentMD5 + "\n" + contentType + "\n" + date + "\n/" + bucket + "/" + key
+
+	request, err := http.NewRequest(method, scheme+"://"+bucket+"."+u.Host+path, bytes.NewReader(data))
+	if nil != err {
+		return err
+	}
+	if "" != contentMD5 {
+		request.Header.Set("Content-MD5", contentMD5)
+		request.Header.Set("Content-Type", contentType)
+	}
+	request.Header.Set("Date", date)
+	request.Header.Set("Authorization", "OSS "+accessKey+":"+ossSignature(secretKey, stringToSign))
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	response, err := client.Do(request)
+	if nil != err {
+		return err
+	}
+	defer response.Body.Close()
+	if http.StatusOK != response.StatusCode && http.StatusNoContent != response.StatusCode {
+		body, _ := ioutil.ReadAll(response.Body)
+
+		return errors.New(strings.ToLower(method) + " object failed: " + response.Status + ": " + strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func ossSignature(secretKey, stringToSign string) string {
+	h := hmac.New(sha1.New, []byte(secretKey))
+	h.Write([]byte(stringToSign))
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,26 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import "testing"
+
+func TestOSSSignature(t *testing.T) {
+	// HMAC-SHA1 of the well-known "quick brown fox" vector (de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9) in base64
+	if signature := ossSignature("key", "The quick brown fox jumps over the lazy dog"); "3nybhbi3iqa8ino29wqQcBydtNk=" != signature {
+		t.Errorf("unexpected signature [%s]", signature)
+	}
+}
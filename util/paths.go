@@ -41,6 +41,7 @@ const (
 	PathComments       = "/comments"
 	PathAtom           = "/atom"
 	PathRSS            = "/rss"
+	PathJSONFeed       = "/feed.json"
 	PathSitemap        = "/sitemap.xml"
 	PathChangelogs     = "/changelogs"
 	PathRobots         = "/robots.txt"
@@ -48,27 +49,63 @@ const (
 	PathAPIsSymComment = "/apis/symphony/comment"
 	PathPlatInfo       = "/plat/info"
 	PathManifest       = "/manifest.json"
+	PathActivityPub    = "/activitypub"
+	PathMedia          = "/media"
+	PathWebFinger      = "/.well-known/webfinger"
+	PathInvitations    = "/invitations"
+	PathSubscribe      = "/subscribe"
+	PathUnsubscribe    = "/unsubscribe"
+	PathShareImages    = "/share-images"
+	PathImages         = "/images"
+	PathReports        = "/reports"
 )
 
+// reservedPaths are paths handled by the router, content (articles, categories and blogs of users) under them would be
+// unreachable.
 var reservedPaths = []string{
-	PathSearch, PathOpensearch, PathBlogs, PathConsoleDist, PathAdmin, PathAPI, PathFavicon, PathTheme,
+	PathInit, PathSearch, PathOpensearch, PathBlogs, PathConsoleDist, PathAdmin, PathAPI, PathFavicon, PathTheme,
 	PathActivities, PathArchives, PathAuthors, PathCategories, PathTags, PathComments, PathAtom, PathRSS,
-	PathSitemap, PathChangelogs, PathRobots, PathAPIsSymArticle,
-	PathAPIsSymComment, PathPlatInfo,
+	PathJSONFeed, PathSitemap, PathChangelogs, PathRobots, PathAPIsSymArticle,
+	PathAPIsSymComment, PathPlatInfo, PathManifest, PathActivityPub, PathMedia, PathWebFinger, PathInvitations,
+	PathSubscribe, PathUnsubscribe, PathShareImages, PathImages, PathReports,
 }
 
-// IsReservedPath checks the specified path is a reserved path or not.
+// ReservePaths reserves the specified paths (e.g. /assets served by a reverse proxy) in addition to the paths of the
+// router.
+func ReservePaths(paths ...string) {
+	for _, path := range paths {
+		path = strings.TrimSuffix(strings.TrimSpace(path), "/")
+		if "" == path {
+			continue
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		reservedPaths = append(reservedPaths, path)
+	}
+}
+
+// IsReservedPath checks the specified path is a reserved path or under a reserved path.
 func IsReservedPath(path string) bool {
-	path = strings.TrimSpace(path)
+	path = strings.ToLower(strings.TrimSpace(path))
 	if PathRoot == path {
 		return true
 	}
 
 	for _, reservedPath := range reservedPaths {
-		if strings.HasPrefix(path, reservedPath) {
+		reservedPath = strings.ToLower(reservedPath)
+		if path == reservedPath || strings.HasPrefix(path, reservedPath+"/") {
 			return true
 		}
 	}
 
 	return false
 }
+
+// IsReservedName checks whether the specified name (e.g. username) collides with a reserved top-level path.
+func IsReservedName(name string) bool {
+	name = strings.TrimSpace(name)
+
+	return "" == name || IsReservedPath("/"+name)
+}
@@ -27,4 +27,24 @@ func TestIsReservedPath(t *testing.T) {
 	if IsReservedPath("/test") {
 		t.Errorf("[/test] is not a reserved path")
 	}
+	if !IsReservedPath("/Tags/pipe") {
+		t.Errorf("[/Tags/pipe] is under a reserved path")
+	}
+	if IsReservedPath("/apiary") {
+		t.Errorf("[/apiary] is not a reserved path")
+	}
+
+	ReservePaths("assets/")
+	if !IsReservedPath("/assets/logo.png") {
+		t.Errorf("[/assets/logo.png] is under a reserved path")
+	}
+}
+
+func TestIsReservedName(t *testing.T) {
+	if !IsReservedName("admin") || !IsReservedName("API") {
+		t.Errorf("[admin] and [API] are reserved names")
+	}
+	if IsReservedName("daniel") {
+		t.Errorf("[daniel] is not a reserved name")
+	}
 }
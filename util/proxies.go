@@ -0,0 +1,109 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustedProxies are networks of the reverse proxies whose X-Forwarded-* headers are trusted.
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets IPs or CIDRs of the trusted reverse proxies, such as 127.0.0.1 or 173.245.48.0/20.
+func SetTrustedProxies(proxies []string) error {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); nil != ip && nil != ip.To4() {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if nil != err {
+			return errors.New("invalid trusted proxy [" + proxy + "]")
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+
+	return nil
+}
+
+// isTrustedProxy checks whether the specified IP is of a trusted reverse proxy.
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if nil == parsed {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peerIP returns the IP of the peer of the connection of the specified request.
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if nil != err {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// fromTrustedProxy checks whether the specified request is forwarded by a trusted reverse proxy.
+func fromTrustedProxy(r *http.Request) bool {
+	return isTrustedProxy(peerIP(r))
+}
+
+// GetScheme returns the scheme (http/https) the client requested with, X-Forwarded-Proto is respected if the request
+// is forwarded by a trusted reverse proxy.
+func GetScheme(c *gin.Context) string {
+	if fromTrustedProxy(c.Request) {
+		if proto := strings.ToLower(strings.TrimSpace(strings.Split(c.GetHeader("X-Forwarded-Proto"), ",")[0])); "" != proto {
+			return proto
+		}
+	}
+	if nil != c.Request.TLS {
+		return "https"
+	}
+
+	return "http"
+}
+
+// GetHost returns the host the client requested, X-Forwarded-Host is respected if the request is forwarded by a
+// trusted reverse proxy.
+func GetHost(c *gin.Context) string {
+	if fromTrustedProxy(c.Request) {
+		if host := strings.TrimSpace(strings.Split(c.GetHeader("X-Forwarded-Host"), ",")[0]); "" != host {
+			return host
+		}
+	}
+
+	return c.Request.Host
+}
@@ -0,0 +1,74 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newProxiedContext(remoteAddr string, headers map[string]string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		c.Request.Header.Set(name, value)
+	}
+
+	return c
+}
+
+func TestGetRemoteAddr(t *testing.T) {
+	if err := SetTrustedProxies([]string{"127.0.0.1", "10.0.0.0/8"}); nil != err {
+		t.Fatal(err)
+	}
+	defer SetTrustedProxies(nil)
+
+	forwarded := map[string]string{"X-Forwarded-For": "1.1.1.1, 2.2.2.2, 10.0.0.2"}
+	if ip := GetRemoteAddr(newProxiedContext("127.0.0.1:5897", forwarded)); "2.2.2.2" != ip {
+		t.Errorf("expected is [%s], actual is [%s]", "2.2.2.2", ip)
+	}
+	if ip := GetRemoteAddr(newProxiedContext("3.3.3.3:5897", forwarded)); "3.3.3.3" != ip {
+		t.Errorf("forwarded headers of untrusted peers should be ignored, actual is [%s]", ip)
+	}
+	if ip := GetRemoteAddr(newProxiedContext("10.1.1.1:5897", map[string]string{"X-Real-IP": "4.4.4.4"})); "4.4.4.4" != ip {
+		t.Errorf("expected is [%s], actual is [%s]", "4.4.4.4", ip)
+	}
+
+	if err := SetTrustedProxies([]string{"not an IP"}); nil == err {
+		t.Error("invalid trusted proxy should be rejected")
+	}
+}
+
+func TestGetSchemeAndHost(t *testing.T) {
+	if err := SetTrustedProxies([]string{"127.0.0.1"}); nil != err {
+		t.Fatal(err)
+	}
+	defer SetTrustedProxies(nil)
+
+	headers := map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "blog.example.com"}
+	c := newProxiedContext("127.0.0.1:5897", headers)
+	if "https" != GetScheme(c) || "blog.example.com" != GetHost(c) {
+		t.Errorf("unexpected scheme [%s] or host [%s]", GetScheme(c), GetHost(c))
+	}
+	c = newProxiedContext("3.3.3.3:5897", headers)
+	if "http" != GetScheme(c) || "example.com" != GetHost(c) {
+		t.Errorf("unexpected scheme [%s] or host [%s]", GetScheme(c), GetHost(c))
+	}
+}
@@ -0,0 +1,111 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned when dialing a loopback, private, link-local or other non-public address.
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// nonPublicNetworks are the private and shared networks not covered by the net.IP predicates.
+var nonPublicNetworks = parseCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16",
+	"198.18.0.0/15", "fc00::/7")
+
+func parseCIDRs(cidrs ...string) (ret []*net.IPNet) {
+	for _, cidr := range cidrs {
+		_, network, _ := net.ParseCIDR(cidr)
+		ret = append(ret, network)
+	}
+
+	return
+}
+
+// IsPublicIP checks whether the specified IP is a public unicast address.
+func IsPublicIP(ip net.IP) bool {
+	if nil == ip || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsPublicHTTPSURL checks whether the specified URL is an HTTPS URL whose host is not a literal non-public IP. Hosts
+// resolving to non-public IPs are rejected by the clients of NewPublicHTTPClient when dialing.
+func IsPublicHTTPSURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if nil != err || "https" != u.Scheme || "" == u.Hostname() || nil != u.User {
+		return false
+	}
+	if ip := net.ParseIP(u.Hostname()); nil != ip {
+		return IsPublicIP(ip)
+	}
+
+	return "localhost" != u.Hostname()
+}
+
+// NewPublicHTTPClient returns an HTTP client which only connects to public IPs and follows HTTPS redirects only, it's
+// used to fetch URLs specified by remote parties without exposing the internal network.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if nil != err {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrNonPublicAddress
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if 10 <= len(via) {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !IsPublicHTTPSURL(req.URL.String()) {
+				return errors.New("redirected to a non-public URL [" + req.URL.String() + "]")
+			}
+
+			return nil
+		},
+	}
+}
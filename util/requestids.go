@@ -0,0 +1,164 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/b3log/pipe/log"
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID is the HTTP header carrying the request ID.
+const HeaderRequestID = "X-Request-ID"
+
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID returns a middleware which accepts the X-Request-ID of the request or generates one, attaches it to the
+// context and the goroutine serving the request, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !requestIDRegexp.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set("requestID", requestID)
+		c.Header(HeaderRequestID, requestID)
+		// services and caches log through package loggers, their lines carry the request ID bound to the goroutine
+		log.BindRequestID(requestID)
+		defer log.UnbindRequestID()
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID of the specified context.
+func GetRequestID(c *gin.Context) string {
+	if nil == c {
+		return ""
+	}
+
+	return c.GetString("requestID")
+}
+
+// RedactedURI returns the path and query of the specified URL for logging, values of the query are redacted since
+// they may carry tokens, for example ?token=xxx.
+func RedactedURI(u *url.URL) string {
+	query := u.Query()
+	if 1 > len(query) {
+		return u.Path
+	}
+
+	var keys []string
+	for key := range query {
+		keys = append(keys, url.QueryEscape(key)+"=redacted")
+	}
+	sort.Strings(keys)
+
+	return u.Path + "?" + strings.Join(keys, "&")
+}
+
+func newRequestID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); nil != err {
+		logger.Errorf("generate request ID failed: %s", err)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+// RequestLogger logs with the request ID prefixed so that log lines of a request could be correlated. The request ID
+// and fields are logged as fields of the JSON object if the log output is in JSON format.
+type RequestLogger struct {
+	requestID string
+	fields    map[string]interface{}
+}
+
+// Log returns a logger of the specified context.
+func Log(c *gin.Context) *RequestLogger {
+	return &RequestLogger{requestID: GetRequestID(c)}
+}
+
+// WithField returns a logger which logs the specified field additionally.
+func (l *RequestLogger) WithField(name string, value interface{}) *RequestLogger {
+	fields := map[string]interface{}{name: value}
+	for k, v := range l.fields {
+		if k != name {
+			fields[k] = v
+		}
+	}
+
+	return &RequestLogger{requestID: l.requestID, fields: fields}
+}
+
+func (l *RequestLogger) args(v []interface{}) []interface{} {
+	return append([]interface{}{l.requestID}, v...)
+}
+
+func (l *RequestLogger) format(format string) string {
+	return "[%s] " + format + strings.Replace(log.FormatFields(l.fields), "%", "%%", -1)
+}
+
+func (l *RequestLogger) log(level int, format string, v []interface{}) bool {
+	fields := map[string]interface{}{"requestID": l.requestID}
+	for name, value := range l.fields {
+		fields[name] = value
+	}
+
+	return log.Output(2, level, fields, fmt.Sprintf(format, v...))
+}
+
+// Tracef logs at trace level.
+func (l *RequestLogger) Tracef(format string, v ...interface{}) {
+	if !l.log(log.LevelTrace, format, v) {
+		logger.Tracef(l.format(format), l.args(v)...)
+	}
+}
+
+// Debugf logs at debug level.
+func (l *RequestLogger) Debugf(format string, v ...interface{}) {
+	if !l.log(log.LevelDebug, format, v) {
+		logger.Debugf(l.format(format), l.args(v)...)
+	}
+}
+
+// Infof logs at info level.
+func (l *RequestLogger) Infof(format string, v ...interface{}) {
+	if !l.log(log.LevelInfo, format, v) {
+		logger.Infof(l.format(format), l.args(v)...)
+	}
+}
+
+// Warnf logs at warn level.
+func (l *RequestLogger) Warnf(format string, v ...interface{}) {
+	if !l.log(log.LevelWarn, format, v) {
+		logger.Warnf(l.format(format), l.args(v)...)
+	}
+}
+
+// Errorf logs at error level.
+func (l *RequestLogger) Errorf(format string, v ...interface{}) {
+	if !l.log(log.LevelError, format, v) {
+		logger.Errorf(l.format(format), l.args(v)...)
+	}
+}
@@ -0,0 +1,35 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRedactedURI(t *testing.T) {
+	for rawURL, expected := range map[string]string{
+		"/blogs/pipe":                         "/blogs/pipe",
+		"/api/unsubscribe?token=secret&p=2":   "/api/unsubscribe?p=redacted&token=redacted",
+		"/api/oauth/callback?code=secret&a=b": "/api/oauth/callback?a=redacted&code=redacted",
+	} {
+		u, _ := url.Parse(rawURL)
+		if redacted := RedactedURI(u); expected != redacted {
+			t.Errorf("expected is [%s], actual is [%s]", expected, redacted)
+		}
+	}
+}
@@ -16,9 +16,102 @@
 
 package util
 
+import (
+	"encoding/json"
+
+	"github.com/b3log/pipe/i18n"
+	"github.com/gin-gonic/gin"
+)
+
 // Result codes.
 const (
 	CodeOk      = 0  // OK
 	CodeErr     = -1 // general error
 	CodeAuthErr = 2  // unauthenticated request
 )
+
+// Machine-readable error codes.
+const (
+	ErrCodeGeneral         = "error"
+	ErrCodeUnauthenticated = "unauthenticated"
+	ErrCodeForbidden       = "forbidden"
+	ErrCodeBadRequest      = "badRequest"
+	ErrCodeNotFound        = "notFound"
+	ErrCodeInternal        = "internal"
+	ErrCodeRateLimited     = "rateLimited"
+	ErrCodeConflict        = "conflict"
+	ErrCodeTOTPRequired    = "totpRequired"
+	ErrCodeUnavailable     = "unavailable"
+)
+
+// errCodeMessageKeys maps error codes to their i18n message keys.
+var errCodeMessageKeys = map[string]string{
+	ErrCodeGeneral:         "errGeneral",
+	ErrCodeUnauthenticated: "errUnauthenticated",
+	ErrCodeForbidden:       "errForbidden",
+	ErrCodeBadRequest:      "errBadRequest",
+	ErrCodeNotFound:        "errNotFound",
+	ErrCodeInternal:        "errInternal",
+	ErrCodeRateLimited:     "errRateLimited",
+	ErrCodeConflict:        "errConflict",
+	ErrCodeTOTPRequired:    "errTOTPRequired",
+	ErrCodeUnavailable:     "errUnavailable",
+}
+
+// Result represents an API response. A failed result carries an error envelope.
+type Result struct {
+	Code    int         `json:"code"`
+	Msg     string      `json:"msg"`
+	Data    interface{} `json:"data"`
+	ErrCode string      `json:"-"` // machine-readable error code, derived from Code if not specified
+
+	c *gin.Context
+}
+
+// ErrorEnvelope describes an API error.
+type ErrorEnvelope struct {
+	Code             string `json:"code"`
+	Message          string `json:"message"`
+	LocalizedMessage string `json:"localizedMessage"`
+	RequestID        string `json:"requestID"`
+}
+
+// NewResult creates a succeeded result of the specified context.
+func NewResult(c *gin.Context) *Result {
+	return &Result{Code: CodeOk, c: c}
+}
+
+// MarshalJSON marshals the result with the error envelope filled if failed.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	ret := struct {
+		Code  int            `json:"code"`
+		Msg   string         `json:"msg"`
+		Data  interface{}    `json:"data"`
+		Error *ErrorEnvelope `json:"error,omitempty"`
+	}{Code: r.Code, Msg: r.Msg, Data: r.Data}
+	if CodeOk != r.Code {
+		ret.Error = r.errorEnvelope()
+	}
+
+	return json.Marshal(ret)
+}
+
+func (r *Result) errorEnvelope() *ErrorEnvelope {
+	code := r.ErrCode
+	if "" == code {
+		code = ErrCodeGeneral
+		if CodeAuthErr == r.Code {
+			code = ErrCodeUnauthenticated
+		}
+	}
+
+	ret := &ErrorEnvelope{Code: code, Message: r.Msg, RequestID: GetRequestID(r.c)}
+	if msg, ok := i18n.GetMessages(RequestLocale(r.c))[errCodeMessageKeys[code]].(string); ok {
+		ret.LocalizedMessage = msg
+	}
+	if "" == ret.Message {
+		ret.Message = ret.LocalizedMessage
+	}
+
+	return ret
+}
@@ -0,0 +1,57 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResultMarshalJSON(t *testing.T) {
+	result := NewResult(nil)
+	data, err := json.Marshal(result)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if strings.Contains(string(data), `"error"`) {
+		t.Errorf("succeeded result should not carry an error: %s", data)
+	}
+
+	result.Code = CodeAuthErr
+	result.Msg = "unauthenticated request"
+	data, err = json.Marshal(result)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	ret := struct {
+		Code  int            `json:"code"`
+		Error *ErrorEnvelope `json:"error"`
+	}{}
+	if err = json.Unmarshal(data, &ret); nil != err {
+		t.Error(err)
+
+		return
+	}
+	if CodeAuthErr != ret.Code || nil == ret.Error || ErrCodeUnauthenticated != ret.Error.Code || "unauthenticated request" != ret.Error.Message {
+		t.Errorf("unexpected result: %s", data)
+	}
+}
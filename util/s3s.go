@@ -0,0 +1,113 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3PutObject puts an object with the specified key into an S3 compatible bucket. The specified bucket URL is in
+// format of "s3://accessKey:secretKey@endpoint/bucket?region=us-east-1", requests are signed with AWS Signature V4.
+func S3PutObject(bucketURL, key string, data []byte) error {
+	return s3Request(http.MethodPut, bucketURL, key, data)
+}
+
+// S3DeleteObject deletes the object with the specified key from an S3 compatible bucket, see S3PutObject for the
+// format of the specified bucket URL.
+func S3DeleteObject(bucketURL, key string) error {
+	return s3Request(http.MethodDelete, bucketURL, key, nil)
+}
+
+func s3Request(method, bucketURL, key string, data []byte) error {
+	u, err := url.Parse(bucketURL)
+	if nil != err {
+		return err
+	}
+	if nil == u.User {
+		return errors.New("access key and secret key are required")
+	}
+	accessKey := u.User.Username()
+	secretKey, _ := u.User.Password()
+	region := u.Query().Get("region")
+	if "" == region {
+		region = "us-east-1"
+	}
+	scheme := "https"
+	if "http" == u.Scheme {
+		scheme = "http"
+	}
+
+	path := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + (&url.URL{Path: key}).EscapedPath()
+	payloadHash := sha256Hex(data)
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := method + "\n" + path + "\n\n" +
+		"host:" + u.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n\n" +
+		signedHeaders + "\n" + payloadHash
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signingKey := hmacSHA256(hmacSHA256(hmacSHA256(hmacSHA256([]byte("AWS4"+secretKey), date), region), "s3"), "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request, err := http.NewRequest(method, scheme+"://"+u.Host+path, bytes.NewReader(data))
+	if nil != err {
+		return err
+	}
+	request.Header.Set("x-amz-content-sha256", payloadHash)
+	request.Header.Set("x-amz-date", amzDate)
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	response, err := client.Do(request)
+	if nil != err {
+		return err
+	}
+	defer response.Body.Close()
+	if http.StatusOK != response.StatusCode && http.StatusNoContent != response.StatusCode {
+		body, _ := ioutil.ReadAll(response.Body)
+
+		return errors.New(strings.ToLower(method) + " object failed: " + response.Status + ": " + string(body))
+	}
+
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
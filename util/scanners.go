@@ -0,0 +1,162 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scanChunkSize is the size of data chunks streamed to the scanner.
+const scanChunkSize = 64 * 1024
+
+// ScanFile scans the specified data for viruses with the scanner specified by the URL, which is a ClamAV daemon
+// (clamav://host:3310) or an ICAP service (icap://host:1344/avscan). Returns the detected signature if infected.
+func ScanFile(scannerURL string, data []byte) (infected bool, signature string, err error) {
+	u, err := url.Parse(scannerURL)
+	if nil != err {
+		return false, "", err
+	}
+
+	switch u.Scheme {
+	case "clamav":
+		return scanClamAV(u.Host, data)
+	case "icap":
+		return scanICAP(u, data)
+	default:
+		return false, "", errors.New("unsupported scanner [" + scannerURL + "]")
+	}
+}
+
+// scanClamAV scans the data with the INSTREAM command of clamd.
+func scanClamAV(addr string, data []byte) (bool, string, error) {
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if nil != err {
+		return false, "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Minute))
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); nil != err {
+		return false, "", err
+	}
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += scanChunkSize {
+		end := start + scanChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		binary.BigEndian.PutUint32(size, uint32(end-start))
+		if _, err = conn.Write(size); nil != err {
+			return false, "", err
+		}
+		if _, err = conn.Write(data[start:end]); nil != err {
+			return false, "", err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = conn.Write(size); nil != err {
+		return false, "", err
+	}
+
+	reply, err := ioutil.ReadAll(conn)
+	if nil != err {
+		return false, "", err
+	}
+	result := strings.TrimSpace(strings.TrimRight(string(reply), "\x00"))
+	result = strings.TrimPrefix(result, "stream: ")
+	switch {
+	case "OK" == result:
+		return false, "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return true, strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return false, "", errors.New("clamd replied [" + result + "]")
+	}
+}
+
+// scanICAP scans the data with an ICAP RESPMOD request (RFC 3507). The service replies 204 if the data is clean,
+// infections are reported in the X-Infection-Found or X-Virus-ID header.
+func scanICAP(u *url.URL, data []byte) (bool, string, error) {
+	host := u.Host
+	if "" == u.Port() {
+		host = net.JoinHostPort(u.Hostname(), "1344")
+	}
+	conn, err := net.DialTimeout("tcp", host, 10*time.Second)
+	if nil != err {
+		return false, "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Minute))
+
+	resHeader := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: " +
+		strconv.Itoa(len(data)) + "\r\n\r\n"
+	request := &bytes.Buffer{}
+	request.WriteString("RESPMOD " + u.String() + " ICAP/1.0\r\n")
+	request.WriteString("Host: " + u.Host + "\r\n")
+	request.WriteString("Allow: 204\r\n")
+	request.WriteString("Encapsulated: res-hdr=0, res-body=" + strconv.Itoa(len(resHeader)) + "\r\n\r\n")
+	request.WriteString(resHeader)
+	if 0 < len(data) {
+		request.WriteString(strconv.FormatInt(int64(len(data)), 16) + "\r\n")
+		request.Write(data)
+		request.WriteString("\r\n")
+	}
+	request.WriteString("0\r\n\r\n")
+	if _, err = conn.Write(request.Bytes()); nil != err {
+		return false, "", err
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	statusLine, err := reader.ReadLine()
+	if nil != err {
+		return false, "", err
+	}
+	parts := strings.SplitN(statusLine, " ", 3)
+	if 2 > len(parts) || !strings.HasPrefix(parts[0], "ICAP/") {
+		return false, "", errors.New("invalid ICAP reply [" + statusLine + "]")
+	}
+	header, err := reader.ReadMIMEHeader()
+	if nil != err {
+		return false, "", err
+	}
+	if signature := header.Get("X-Infection-Found"); "" != signature {
+		return true, signature, nil
+	}
+	if signature := header.Get("X-Virus-ID"); "" != signature {
+		return true, signature, nil
+	}
+
+	switch parts[1] {
+	case strconv.Itoa(http.StatusNoContent), strconv.Itoa(http.StatusOK):
+		return false, "", nil
+	case strconv.Itoa(http.StatusForbidden):
+		return true, "", nil
+	default:
+		return false, "", errors.New("ICAP replied [" + statusLine + "]")
+	}
+}
@@ -0,0 +1,118 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveOnce accepts one connection and replies with the specified handler.
+func serveOnce(t *testing.T, handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if nil != err {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestScanFileClamAV(t *testing.T) {
+	eicar := []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+	addr := serveOnce(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		command, _ := reader.ReadString(0)
+		if "zINSTREAM\x00" != command {
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+
+			return
+		}
+		received := &bytes.Buffer{}
+		size := make([]byte, 4)
+		for {
+			io.ReadFull(reader, size)
+			n := binary.BigEndian.Uint32(size)
+			if 0 == n {
+				break
+			}
+			io.CopyN(received, reader, int64(n))
+		}
+		if bytes.Contains(received.Bytes(), []byte("EICAR")) {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	})
+
+	infected, signature, err := ScanFile("clamav://"+addr, eicar)
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if !infected || "Eicar-Test-Signature" != signature {
+		t.Errorf("expected infected with [Eicar-Test-Signature], actual is [%v, %s]", infected, signature)
+	}
+}
+
+func TestScanFileICAP(t *testing.T) {
+	addr := serveOnce(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		line, _ := reader.ReadString('\n')
+		if !strings.HasPrefix(line, "RESPMOD icap://") {
+			conn.Write([]byte("ICAP/1.0 400 Bad Request\r\n\r\n"))
+
+			return
+		}
+		for { // drains the request until the last chunk
+			line, err := reader.ReadString('\n')
+			if nil != err || "0\r\n" == line {
+				break
+			}
+		}
+		conn.Write([]byte("ICAP/1.0 204 No Content\r\nISTag: \"test\"\r\n\r\n"))
+	})
+
+	infected, _, err := ScanFile("icap://"+addr+"/avscan", []byte("clean"))
+	if nil != err {
+		t.Error(err)
+
+		return
+	}
+	if infected {
+		t.Error("clean data should not be infected")
+	}
+
+	if _, _, err := ScanFile("ftp://"+addr, nil); nil == err {
+		t.Error("unsupported scanner should fail")
+	}
+}
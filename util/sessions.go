@@ -32,6 +32,9 @@ type SessionData struct {
 	UAvatar string // user avatar URL
 	BID     uint64 // blog ID
 	BURL    string // blog url
+	UTOTP   bool   // second factor verified
+	SID     string // ID of the session in the session registry
+	ULocale string // user preferred locale of the console
 }
 
 // AvatarURLWithSize returns avatar URL with the specified size.
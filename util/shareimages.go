@@ -0,0 +1,149 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of share images, the recommended size of Open Graph images.
+const (
+	ShareImageWidth  = 1200
+	ShareImageHeight = 630
+)
+
+// shareImagePadding is the padding (in pixel) around texts of share images.
+const shareImagePadding = 80
+
+// Colors of share images.
+var (
+	shareImageBackground = color.RGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0xff}
+	shareImageAccent     = color.RGBA{R: 0x4f, G: 0x9c, B: 0xe8, A: 0xff}
+	shareImageTitle      = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	shareImageMeta       = color.RGBA{R: 0xa0, G: 0xa8, B: 0xb2, A: 0xff}
+)
+
+// ShareImage holds texts of a social share image.
+type ShareImage struct {
+	Title  string
+	Author string
+	Blog   string
+}
+
+// RenderShareImage renders the specified share image to PNG with the specified TrueType font. The title is wrapped
+// into at most 4 lines, the author and blog are placed at the bottom.
+func RenderShareImage(img *ShareImage, ttf *truetype.Font) ([]byte, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, ShareImageWidth, ShareImageHeight))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(shareImageBackground), image.ZP, draw.Src)
+	draw.Draw(dst, image.Rect(0, 0, 16, ShareImageHeight), image.NewUniform(shareImageAccent), image.ZP, draw.Src)
+	maxWidth := ShareImageWidth - 2*shareImagePadding
+
+	titleFace := truetype.NewFace(ttf, &truetype.Options{Size: 64, Hinting: font.HintingFull})
+	defer titleFace.Close()
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(shareImageTitle), Face: titleFace}
+	y := fixed.I(shareImagePadding) + titleFace.Metrics().Ascent
+	for _, line := range wrapLines(img.Title, maxWidth, 4, measurer(titleFace)) {
+		drawer.Dot = fixed.Point26_6{X: fixed.I(shareImagePadding), Y: y}
+		drawer.DrawString(line)
+		y += titleFace.Metrics().Height * 5 / 4
+	}
+
+	var metas []string
+	for _, meta := range []string{img.Author, img.Blog} {
+		if meta = strings.TrimSpace(meta); "" != meta {
+			metas = append(metas, meta)
+		}
+	}
+	metaFace := truetype.NewFace(ttf, &truetype.Options{Size: 32, Hinting: font.HintingFull})
+	defer metaFace.Close()
+	drawer = &font.Drawer{Dst: dst, Src: image.NewUniform(shareImageMeta), Face: metaFace}
+	for _, line := range wrapLines(strings.Join(metas, " · "), maxWidth, 1, measurer(metaFace)) {
+		drawer.Dot = fixed.Point26_6{X: fixed.I(shareImagePadding), Y: fixed.I(ShareImageHeight - shareImagePadding)}
+		drawer.DrawString(line)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, dst); nil != err {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// measurer returns a function which measures the width (in pixel) of texts drawn with the specified face.
+func measurer(face font.Face) func(string) int {
+	return func(text string) int {
+		return font.MeasureString(face, text).Ceil()
+	}
+}
+
+// wrapLines wraps the specified text into at most maxLines lines not wider than maxWidth measured by measure. Lines
+// are broken between words or CJK characters, words too long for a line are broken between characters. The last
+// line ends with an ellipsis if the text is truncated.
+func wrapLines(text string, maxWidth, maxLines int, measure func(string) int) (ret []string) {
+	var words []string
+	for _, word := range splitWords(strings.TrimSpace(text)) {
+		if maxWidth < measure(word) {
+			for _, r := range word {
+				words = append(words, string(r))
+			}
+
+			continue
+		}
+		words = append(words, word)
+	}
+
+	line := ""
+	for i, word := range words {
+		if "" != line && maxWidth < measure(line+word) {
+			if maxLines == len(ret)+1 {
+				return append(ret, ellipsis(line, maxWidth, measure))
+			}
+			ret = append(ret, strings.TrimSpace(line))
+			line = ""
+		}
+		if "" == line && "" == strings.TrimSpace(word) {
+			continue
+		}
+		line += word
+		if len(words)-1 == i {
+			ret = append(ret, strings.TrimSpace(line))
+		}
+	}
+
+	return
+}
+
+// ellipsis appends an ellipsis to the specified line, characters at the end are removed to keep the line not wider
+// than maxWidth.
+func ellipsis(line string, maxWidth int, measure func(string) int) string {
+	runes := []rune(strings.TrimSpace(line))
+	for 0 < len(runes) && maxWidth < measure(string(runes)+"…") {
+		runes = runes[:len(runes)-1]
+	}
+
+	return strings.TrimSpace(string(runes)) + "…"
+}
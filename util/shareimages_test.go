@@ -0,0 +1,46 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapLines(t *testing.T) {
+	measure := func(text string) int {
+		return utf8.RuneCountInString(text)
+	}
+
+	cases := []struct {
+		text     string
+		maxLines int
+		expected []string
+	}{
+		{"Hello Pipe", 3, []string{"Hello Pipe"}},
+		{"Hello small and beautiful Pipe", 3, []string{"Hello", "small and", "beautiful…"}},
+		{"一个小而美的博客平台系统", 2, []string{"一个小而美的博客平台", "系统"}},
+		{"一个小而美的博客平台系统", 1, []string{"一个小而美的博客平…"}},
+		{"Supercalifragilistic", 3, []string{"Supercalif", "ragilistic"}},
+	}
+	for _, c := range cases {
+		if lines := wrapLines(c.text, 10, c.maxLines, measure); !reflect.DeepEqual(c.expected, lines) {
+			t.Errorf("expected is %q, actual is %q", c.expected, lines)
+		}
+	}
+}
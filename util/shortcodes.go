@@ -0,0 +1,264 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Shortcode renders a shortcode like {{< name key=value >}} to HTML with the specified params. Inner is the rendered
+// HTML between a paired shortcode and its closing {{< /name >}}, empty for single shortcodes.
+type Shortcode func(params map[string]string, inner string) (string, error)
+
+var shortcodes = map[string]Shortcode{}
+var themeShortcodes = map[string]map[string]Shortcode{}
+var shortcodesLock = &sync.RWMutex{}
+
+// shortcodeRegexp matches shortcode tags {{< name key=value key="quoted value" >}} and closing tags {{< /name >}}.
+var shortcodeRegexp = regexp.MustCompile(`\{\{<\s*(/?)([\w-]+)((?:\s+[\w-]+=(?:"[^"]*"|[^\s"]+))*)\s*>\}\}`)
+
+// shortcodeEscapeRegexp matches escaped shortcodes {{</* name */>}}, which are kept as text.
+var shortcodeEscapeRegexp = regexp.MustCompile(`\{\{</\*(.*?)\*/>\}\}`)
+
+// shortcodeParamRegexp matches a shortcode param.
+var shortcodeParamRegexp = regexp.MustCompile(`([\w-]+)=(?:"([^"]*)"|([^\s"]+))`)
+
+// shortcodePlaceholderRegexp matches placeholders of shortcodes in rendered HTML.
+var shortcodePlaceholderRegexp = regexp.MustCompile(`(<p>)?pipesc(\d+)x(</p>)?`)
+
+func init() {
+	RegisterShortcode("button", buttonShortcode)
+	RegisterShortcode("figure", figureShortcode)
+	RegisterShortcode("gallery", galleryShortcode)
+	RegisterShortcode("notice", noticeShortcode)
+	RegisterShortcode("video", videoShortcode)
+}
+
+// RegisterShortcode registers the specified shortcode with the specified name for all themes, it replaces the one
+// registered with the same name.
+func RegisterShortcode(name string, shortcode Shortcode) {
+	shortcodesLock.Lock()
+	defer shortcodesLock.Unlock()
+
+	shortcodes[name] = shortcode
+}
+
+// RegisterThemeShortcode registers the specified shortcode with the specified name for the specified theme, it
+// overrides the shortcode registered for all themes with the same name.
+func RegisterThemeShortcode(theme, name string, shortcode Shortcode) {
+	shortcodesLock.Lock()
+	defer shortcodesLock.Unlock()
+
+	if nil == themeShortcodes[theme] {
+		themeShortcodes[theme] = map[string]Shortcode{}
+	}
+	themeShortcodes[theme][name] = shortcode
+}
+
+// UnregisterThemeShortcodes unregisters all shortcodes registered for the specified theme.
+func UnregisterThemeShortcodes(theme string) {
+	shortcodesLock.Lock()
+	defer shortcodesLock.Unlock()
+
+	delete(themeShortcodes, theme)
+}
+
+// getShortcode returns the shortcode of the specified name for the specified theme, returns nil if not found.
+func getShortcode(theme, name string) Shortcode {
+	shortcodesLock.RLock()
+	defer shortcodesLock.RUnlock()
+
+	if ret := themeShortcodes[theme][name]; nil != ret {
+		return ret
+	}
+
+	return shortcodes[name]
+}
+
+// protectShortcodes renders shortcodes of the specified markdown text and replaces them with placeholders, returns
+// the text and the rendered shortcodes. Inner text of paired shortcodes is rendered as markdown with the specified
+// options. Shortcodes in code blocks and unknown shortcodes are left as is.
+func protectShortcodes(mdText string, opts *MarkdownOptions) (string, []string) {
+	if !strings.Contains(mdText, "{{<") {
+		return mdText, nil
+	}
+
+	var outputs []string
+	placeholder := func(output string) string {
+		outputs = append(outputs, output)
+
+		return "pipesc" + strconv.Itoa(len(outputs)-1) + "x"
+	}
+
+	lines := strings.Split(mdText, "\n")
+	inCode := fencedCodeLines(lines)
+	var ret, chunk []string
+	flush := func() {
+		if 0 < len(chunk) {
+			ret = append(ret, expandShortcodes(strings.Join(chunk, "\n"), opts, placeholder))
+			chunk = nil
+		}
+	}
+	for i, line := range lines {
+		if inCode[i] {
+			flush()
+			ret = append(ret, line)
+
+			continue
+		}
+		chunk = append(chunk, line)
+	}
+	flush()
+
+	return strings.Join(ret, "\n"), outputs
+}
+
+// expandShortcodes replaces shortcodes of the specified text, which includes no code block, with placeholders.
+func expandShortcodes(text string, opts *MarkdownOptions, placeholder func(output string) string) string {
+	text = shortcodeEscapeRegexp.ReplaceAllStringFunc(text, func(escaped string) string {
+		return placeholder(html.EscapeString("{{<" + shortcodeEscapeRegexp.FindStringSubmatch(escaped)[1] + ">}}"))
+	})
+
+	buf := &strings.Builder{}
+	for {
+		loc := shortcodeRegexp.FindStringSubmatchIndex(text)
+		if nil == loc {
+			buf.WriteString(text)
+
+			break
+		}
+
+		buf.WriteString(text[:loc[0]])
+		tag, rest := text[loc[0]:loc[1]], text[loc[1]:]
+		closing, name := "/" == text[loc[2]:loc[3]], text[loc[4]:loc[5]]
+		shortcode := getShortcode(opts.Theme, name)
+		if closing || nil == shortcode {
+			buf.WriteString(tag)
+			text = rest
+
+			continue
+		}
+
+		inner := ""
+		closingRegexp := regexp.MustCompile(`\{\{<\s*/` + regexp.QuoteMeta(name) + `\s*>\}\}`)
+		if closingLoc := closingRegexp.FindStringIndex(rest); nil != closingLoc {
+			if innerText := strings.TrimSpace(rest[:closingLoc[0]]); "" != innerText {
+				inner = strings.TrimSpace(MarkdownWithOptions(innerText, opts).ContentHTML)
+			}
+			rest = rest[closingLoc[1]:]
+		}
+
+		params := map[string]string{}
+		for _, groups := range shortcodeParamRegexp.FindAllStringSubmatch(text[loc[6]:loc[7]], -1) {
+			params[groups[1]] = groups[2] + groups[3]
+		}
+		output, err := shortcode(params, inner)
+		if nil != err {
+			logger.Warnf("renders shortcode [%s] failed: %s", tag, err)
+			buf.WriteString(tag)
+			text = text[loc[1]:]
+
+			continue
+		}
+		buf.WriteString(placeholder(output))
+		text = rest
+	}
+
+	return buf.String()
+}
+
+// restoreShortcodes replaces placeholders in the specified rendered HTML with the rendered shortcodes.
+func restoreShortcodes(contentHTML string, outputs []string) string {
+	if 1 > len(outputs) {
+		return contentHTML
+	}
+
+	return shortcodePlaceholderRegexp.ReplaceAllStringFunc(contentHTML, func(placeholder string) string {
+		groups := shortcodePlaceholderRegexp.FindStringSubmatch(placeholder)
+		index, _ := strconv.Atoi(groups[2])
+		if index >= len(outputs) {
+			return placeholder
+		}
+		if "" != groups[1] && "" != groups[3] { // the shortcode is a paragraph of its own
+			return outputs[index]
+		}
+
+		return groups[1] + outputs[index] + groups[3]
+	})
+}
+
+// buttonShortcode renders {{< button url="https://b3log.org" text="B3log" >}} or its paired form to a link button.
+func buttonShortcode(params map[string]string, inner string) (string, error) {
+	text := html.EscapeString(params["text"])
+	if "" != inner {
+		text = trimParagraph(inner)
+	}
+
+	return `<a class="pipe-shortcode-button" href="` + html.EscapeString(params["url"]) + `">` + text + `</a>`, nil
+}
+
+// figureShortcode renders {{< figure src="/a.png" caption="A" >}} to a figure with a caption.
+func figureShortcode(params map[string]string, inner string) (string, error) {
+	ret := `<figure><img src="` + html.EscapeString(params["src"]) + `" alt="` + html.EscapeString(params["alt"]) + `">`
+	if caption := params["caption"]; "" != caption {
+		ret += "<figcaption>" + html.EscapeString(caption) + "</figcaption>"
+	}
+
+	return ret + "</figure>", nil
+}
+
+// galleryShortcode renders the images of {{< gallery columns=3 >}} ![](a.png) ![](b.png) {{< /gallery >}} to a grid.
+func galleryShortcode(params map[string]string, inner string) (string, error) {
+	columns, err := strconv.Atoi(params["columns"])
+	if nil != err || 1 > columns || 6 < columns {
+		columns = 3
+	}
+
+	images := strings.NewReplacer("<p>", "", "</p>", "", "<br>", "", "<br/>", "").Replace(inner) // images are grid items
+
+	return `<div class="pipe-gallery" style="grid-template-columns: repeat(` + strconv.Itoa(columns) + `, 1fr)">` + images + `</div>`, nil
+}
+
+// noticeShortcode renders {{< notice type=warning >}} text {{< /notice >}} to a notice box of type info, warning or
+// error.
+func noticeShortcode(params map[string]string, inner string) (string, error) {
+	typ := params["type"]
+	if "warning" != typ && "error" != typ {
+		typ = "info"
+	}
+
+	return `<div class="pipe-notice pipe-notice--` + typ + `">` + inner + `</div>`, nil
+}
+
+// videoShortcode renders {{< video src="/a.mp4" >}} to a video player.
+func videoShortcode(params map[string]string, inner string) (string, error) {
+	return `<video controls src="` + html.EscapeString(params["src"]) + `"></video>`, nil
+}
+
+// trimParagraph removes the paragraph wrapping the specified HTML of a single paragraph.
+func trimParagraph(contentHTML string) string {
+	contentHTML = strings.TrimSpace(contentHTML)
+	if strings.HasPrefix(contentHTML, "<p>") && strings.HasSuffix(contentHTML, "</p>") && 1 == strings.Count(contentHTML, "<p>") {
+		contentHTML = strings.TrimSuffix(strings.TrimPrefix(contentHTML, "<p>"), "</p>")
+	}
+
+	return contentHTML
+}
@@ -0,0 +1,56 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownShortcodes(t *testing.T) {
+	mdText := "{{< button url=\"https://b3log.org\" text=\"B3log\" >}}\n\n{{< notice type=warning >}}\n**Careful**\n{{< /notice >}}\n\n" +
+		"`{{</* button */>}}` {{< unknown >}}\n\n```\n{{< button url=/ >}}\n```\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{Shortcodes: true}).ContentHTML
+	if !strings.Contains(html, `<a class="pipe-shortcode-button" href="https://b3log.org"`) {
+		t.Errorf("button should be rendered [%s]", html)
+	}
+	if !strings.Contains(html, `<div class="pipe-notice pipe-notice--warning"><p><strong>Careful</strong></p></div>`) {
+		t.Errorf("paired notice should be rendered [%s]", html)
+	}
+	if !strings.Contains(html, "{{&lt; unknown &gt;}}") || !strings.Contains(html, "{{&lt; button url=/ &gt;}}") {
+		t.Errorf("unknown shortcodes and shortcodes in code should be kept [%s]", html)
+	}
+	if 1 != strings.Count(html, "pipe-shortcode-button") {
+		t.Errorf("escaped shortcode should be kept as text [%s]", html)
+	}
+}
+
+func TestThemeShortcode(t *testing.T) {
+	RegisterThemeShortcode("test", "button", func(params map[string]string, inner string) (string, error) {
+		return `<span class="test-button">` + params["text"] + `</span>`, nil
+	})
+
+	mdText := "{{< button text=Go >}}\n"
+	html := MarkdownWithOptions(mdText, &MarkdownOptions{Shortcodes: true, Theme: "test"}).ContentHTML
+	if !strings.Contains(html, `<span class="test-button">Go</span>`) {
+		t.Errorf("theme shortcode should override the built-in one [%s]", html)
+	}
+	html = MarkdownWithOptions(mdText, &MarkdownOptions{Shortcodes: true}).ContentHTML
+	if !strings.Contains(html, "pipe-shortcode-button") {
+		t.Errorf("built-in shortcode should be used by other themes [%s]", html)
+	}
+}
@@ -0,0 +1,120 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters, compatible with the common authenticator apps.
+const (
+	totpPeriod = 30 // seconds
+	totpDigits = 6
+	totpSkew   = 1 // accepted steps before and after the current one
+)
+
+// GenerateTOTPSecret generates a random base32 encoded TOTP secret.
+func GenerateTOTPSecret() string {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); nil != err {
+		panic(err)
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)
+}
+
+// TOTPCode returns the TOTP (RFC 6238) code of the specified base32 encoded secret at the specified time.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if nil != err {
+		return "", err
+	}
+
+	return hotpCode(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+// VerifyTOTP checks whether the specified code is valid for the specified secret at the specified time, allowing
+// one step of clock drift.
+func VerifyTOTP(secret, code string, t time.Time) bool {
+	_, ok := MatchTOTP(secret, code, t)
+
+	return ok
+}
+
+// MatchTOTP checks the specified code like VerifyTOTP and returns the time step it matches, callers could reject the
+// steps not after the last accepted one to prevent replaying.
+func MatchTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if totpDigits != len(code) {
+		return 0, false
+	}
+
+	key, err := decodeTOTPSecret(secret)
+	if nil != err {
+		return 0, false
+	}
+
+	counter := t.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := counter + int64(i)
+		if 1 == subtle.ConstantTimeCompare([]byte(code), []byte(hotpCode(key, uint64(step)))) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPURI returns the otpauth URI of the specified secret, which is usually rendered as a QR code.
+func TOTPURI(secret, account, issuer string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	secret = strings.TrimRight(secret, "=")
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+// hotpCode returns the HOTP (RFC 4226) code of the specified key and counter.
+func hotpCode(key []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
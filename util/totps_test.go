@@ -0,0 +1,82 @@
+// Pipe - A small and beautiful blogging platform written in golang.
+// Copyright (C) 2017-present, b3log.org
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the base32 encoded secret "12345678901234567890" of the RFC 6238 test vectors.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+	}
+	for unix, expected := range cases {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		if nil != err {
+			t.Fatal(err)
+		}
+		if expected != code {
+			t.Errorf("expected is [%s], actual is [%s] at [%d]", expected, code, unix)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	if !VerifyTOTP(rfc6238Secret, "081804", now) {
+		t.Error("current code should be valid")
+	}
+	if !VerifyTOTP(rfc6238Secret, "081804", now.Add(30*time.Second)) {
+		t.Error("previous step code should be valid")
+	}
+	if VerifyTOTP(rfc6238Secret, "081804", now.Add(2*time.Minute)) {
+		t.Error("stale code should be invalid")
+	}
+	if VerifyTOTP(rfc6238Secret, "81804", now) {
+		t.Error("short code should be invalid")
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step, ok := MatchTOTP(rfc6238Secret, "081804", now.Add(30*time.Second))
+	if !ok || now.Unix()/30 != step {
+		t.Errorf("expected is [%d], actual is [%d]", now.Unix()/30, step)
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret := GenerateTOTPSecret()
+	if 32 != len(secret) {
+		t.Errorf("expected length is [32], actual is [%d]", len(secret))
+	}
+	if _, err := TOTPCode(secret, time.Now()); nil != err {
+		t.Error(err)
+	}
+
+	uri := TOTPURI(secret, "pipe", "Pipe")
+	if !strings.HasPrefix(uri, "otpauth://totp/Pipe:pipe?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected URI [%s]", uri)
+	}
+}